@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func (a *app) learningReassign(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New("expected <learning-id> <mentor-id>")
+	}
+
+	learning, err := a.learnings.AssignMentor(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	a.out.result(dto.ToLearningResponseDTO(learning), func(w io.Writer) {
+		fmt.Fprintf(w, "learning:\t%s\n", learning.ID)
+		fmt.Fprintf(w, "topic:\t%s\n", learning.RequestTopic)
+		fmt.Fprintf(w, "learner:\t%s\n", learning.UserName)
+		fmt.Fprintf(w, "mentor:\t%s (%s)\n", learning.MentorName, learning.MentorID)
+	})
+	return nil
+}
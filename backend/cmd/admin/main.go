@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/config"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/mail"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/netguard"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/postgres"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+const usage = `Usage: admin [-config path] [-json] [-tenant slug|id] <command> [args]
+
+Users:
+  user list
+  user create -name NAME -email EMAIL [-role ROLE] [-password P]
+              [-department D] [-job-title T] [-telegram @T]
+  user promote <email|id>            Grant the admin role
+  user demote <email|id>             Make the user an employee
+  user set-role <email|id> <role>    Assign any role, see role list
+  user reset-password <email|id> [-password P]
+  user deactivate <email|id>         Block sign-in, keeping history
+  user reactivate <email|id>
+  user set-manager <email|id> <manager email|id|none>
+  user import <file.csv|file.json> [-dry-run] [-invite]
+                                     Create or update users keyed by email
+  user hris-sync                     Import the tenant's HRIS export now
+
+Roles:
+  role list                          Roles and the permissions they grant
+
+Mentors:
+  mentor list                        Includes deactivated mentors
+  mentor import <file.csv|file.json> [-dry-run]
+  mentor recalc-workload [-dry-run]  Fix workload drift from active learnings
+  mentor handoff <id> [-dry-run] [-note N] [-deactivate] [-assign LEARNING_ID=MENTOR_ID]
+                                     Move all active learnings to other mentors
+  mentor deactivate <id>             Hide from assignment; hand off learnings first
+  mentor reactivate <id>
+
+Learnings:
+  learning reassign <learning-id> <mentor-id>
+
+Queue:
+  queue list                         Requests waiting for a mentor, in order
+  queue dispatch                     Assign queued requests to mentors with free slots
+
+Data:
+  seed demo                          Create demo employees, requests and learnings
+
+Commands work in the tenant -tenant names, the default tenant when omitted.
+Passwords are generated and printed when -password is omitted.
+With -json every command prints a single JSON document to stdout.
+`
+
+// repositories are the stores the commands work with: Postgres in
+// production, the in-memory ones in tests
+type repositories struct {
+	tenants       domain.TenantRepository
+	users         domain.UserRepository
+	roles         domain.RoleRepository
+	departments   domain.DepartmentRepository
+	requests      domain.RequestRepository
+	mentors       domain.MentorRepository
+	learnings     domain.LearningRepository
+	availability  domain.AvailabilityRepository
+	notifications domain.NotificationRepository
+	outbox        domain.OutboxRepository
+	approvals     domain.ApprovalRepository
+	enrollments   domain.EnrollmentRepository
+	skills        domain.SkillRepository
+	competencies  domain.CompetencyRepository
+	jobs          domain.JobRepository
+	tx            domain.TxManager
+}
+
+// services are shared by all commands
+type services struct {
+	tenants   domain.TenantRepository
+	users     *service.UserService
+	roles     *service.RoleService
+	requests  *service.RequestService
+	mentors   *service.MentorService
+	learnings *service.LearningService
+	handoffs  *service.HandoffService
+	queue     *service.QueueService
+	imports   *service.UserImportService
+	hris      service.HRISSource // of the configuration, for the default tenant
+}
+
+// app is a command invocation: the services and where results go
+type app struct {
+	*services
+	out *printer
+}
+
+// options are the global flags and the command after them
+type options struct {
+	configPath string
+	json       bool
+	tenant     string
+	args       []string // group, command and the command's arguments
+}
+
+func main() {
+	opts, err := parseOptions(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(opts.configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := postgres.NewPool(ctx, postgres.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.DBName,
+		SSLMode:  cfg.Database.SSLMode,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer postgres.Close(pool)
+
+	svc, err := newServices(cfg, repositories{
+		tenants:       postgres.NewTenantRepository(pool),
+		users:         postgres.NewUserRepository(pool),
+		roles:         postgres.NewRoleRepository(pool),
+		departments:   postgres.NewDepartmentRepository(pool),
+		requests:      postgres.NewRequestRepository(pool),
+		mentors:       postgres.NewMentorRepository(pool),
+		learnings:     postgres.NewLearningRepository(pool),
+		availability:  postgres.NewAvailabilityRepository(pool),
+		notifications: postgres.NewNotificationRepository(pool),
+		outbox:        postgres.NewOutboxRepository(pool),
+		approvals:     postgres.NewApprovalRepository(pool),
+		enrollments:   postgres.NewEnrollmentRepository(pool),
+		skills:        postgres.NewSkillRepository(pool),
+		competencies:  postgres.NewCompetencyRepository(pool),
+		jobs:          postgres.NewJobRepository(pool),
+		tx:            postgres.NewTxManager(pool),
+	})
+	if err != nil {
+		log.Fatalf("Failed to set up services: %v", err)
+	}
+
+	if err := run(ctx, opts, os.Stdout, svc); err != nil {
+		(&printer{w: os.Stdout, json: opts.json}).fail(err)
+		os.Exit(1)
+	}
+}
+
+// parseOptions parses the global flags. It prints the usage to stderr and
+// returns an error when they are invalid or no command follows them.
+func parseOptions(args []string, stderr io.Writer) (options, error) {
+	defaultConfig := os.Getenv("CONFIG_PATH")
+	if defaultConfig == "" {
+		defaultConfig = "config/config.yaml"
+	}
+
+	var opts options
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.configPath, "config", defaultConfig, "path to the YAML config file")
+	fs.BoolVar(&opts.json, "json", false, "print results as JSON")
+	fs.StringVar(&opts.tenant, "tenant", "", "slug or ID of the tenant to work in")
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return options{}, errors.New("missing command")
+	}
+	opts.args = fs.Args()
+	return opts, nil
+}
+
+// newServices wires the services the commands use
+func newServices(cfg *config.Config, r repositories) (*services, error) {
+	notificationService := service.NewNotificationService(r.notifications)
+	outboxService := service.NewOutboxService(r.outbox)
+	queueService := service.NewQueueService(r.tx, r.requests, r.mentors, r.learnings, r.availability, notificationService, outboxService)
+
+	approvalChain, err := domain.ParseApprovalChain(cfg.Approval.Stages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid approval chain: %w", err)
+	}
+	approvalService := service.NewApprovalService(r.tx, r.requests, r.users, r.departments, r.approvals, r.enrollments, queueService, notificationService, outboxService, approvalChain)
+
+	competencyService := service.NewCompetencyService(r.tx, r.skills, r.competencies, r.users, r.departments)
+
+	// Invitations are queued as jobs and emailed by the application's job
+	// runner, so the CLI needs neither a mailer nor a worker of its own
+	jobService := service.NewJobService(r.tx, r.jobs, r.tenants, "admin-cli", cfg.Jobs.Lease)
+	authService := service.NewAuthService(r.users, r.departments, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
+	importService := service.NewUserImportService(r.users, r.roles, r.departments, r.tenants, authService, jobService, mail.LogMailer{}, cfg.Mail.InviteURL)
+	jobService.Register(service.JobUserInvite, importService.RunInviteJob)
+
+	return &services{
+		tenants:   r.tenants,
+		users:     service.NewUserService(r.users, r.roles, r.departments),
+		roles:     service.NewRoleService(r.tx, r.roles, r.users),
+		requests:  service.NewRequestService(r.tx, r.requests, r.users, r.mentors, r.learnings, r.availability, approvalService, outboxService),
+		mentors:   service.NewMentorService(r.mentors, r.learnings, r.availability, r.tenants, queueService),
+		learnings: service.NewLearningService(r.tx, r.learnings, r.mentors, r.users, r.requests, r.availability, queueService, approvalService, competencyService, outboxService, nil),
+		handoffs:  service.NewHandoffService(r.tx, r.mentors, r.learnings, r.availability, notificationService, outboxService),
+		queue:     queueService,
+		imports:   importService,
+		hris: service.HRISSource{
+			URL:        cfg.HRIS.URL,
+			Token:      cfg.HRIS.Token,
+			RecordsKey: cfg.HRIS.RecordsKey,
+			Fields:     cfg.HRIS.Fields,
+			Invite:     cfg.HRIS.Invite,
+			Client:     &http.Client{Timeout: cfg.HRIS.Timeout},
+
+			TenantClient: netguard.NewClient(cfg.HRIS.Timeout),
+		},
+	}, nil
+}
+
+// run executes the command in opts in the selected tenant, printing results
+// to stdout
+func run(ctx context.Context, opts options, stdout io.Writer, svc *services) error {
+	if len(opts.args) < 2 {
+		return errors.New("missing command, run with -h for usage")
+	}
+
+	tenantID, err := resolveTenant(ctx, svc.tenants, opts.tenant)
+	if err != nil {
+		return fmt.Errorf("failed to resolve tenant: %w", err)
+	}
+	ctx = domain.WithTenant(ctx, tenantID)
+
+	a := &app{services: svc, out: &printer{w: stdout, json: opts.json}}
+	return a.dispatch(ctx, opts.args[0], opts.args[1], opts.args[2:])
+}
+
+// resolveTenant finds the tenant a slug or ID names; an empty ref means the
+// default tenant
+func resolveTenant(ctx context.Context, tenants domain.TenantRepository, ref string) (string, error) {
+	if ref == "" {
+		return domain.DefaultTenantID, nil
+	}
+	all, err := tenants.GetAll(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range all {
+		if t.ID == ref || t.Slug == ref {
+			return t.ID, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", domain.ErrTenantNotFound, ref)
+}
+
+// dispatch runs "<group> <command>" with its implementation
+func (a *app) dispatch(ctx context.Context, group, command string, args []string) error {
+	commands := map[string]map[string]func(context.Context, []string) error{
+		"user": {
+			"list":           a.userList,
+			"create":         a.userCreate,
+			"promote":        a.userPromote,
+			"demote":         a.userDemote,
+			"reset-password": a.userResetPassword,
+			"deactivate":     a.userDeactivate,
+			"reactivate":     a.userReactivate,
+			"set-role":       a.userSetRole,
+			"set-manager":    a.userSetManager,
+			"import":         a.userImport,
+			"hris-sync":      a.userSyncHRIS,
+		},
+		"role": {
+			"list": a.roleList,
+		},
+		"mentor": {
+			"list":            a.mentorList,
+			"import":          a.mentorImport,
+			"recalc-workload": a.mentorRecalcWorkload,
+			"handoff":         a.mentorHandoff,
+			"deactivate":      a.mentorDeactivate,
+			"reactivate":      a.mentorReactivate,
+		},
+		"learning": {
+			"reassign": a.learningReassign,
+		},
+		"queue": {
+			"list":     a.queueList,
+			"dispatch": a.queueDispatch,
+		},
+		"seed": {
+			"demo": a.seedDemo,
+		},
+	}
+
+	cmd, ok := commands[group][command]
+	if !ok {
+		return fmt.Errorf("unknown command %q, run with -h for usage", group+" "+command)
+	}
+
+	return cmd(ctx, args)
+}
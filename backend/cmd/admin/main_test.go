@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/config"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/memory"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+const password = "s3cret-pass"
+
+// testCLI runs commands against services over an in-memory store
+type testCLI struct {
+	svc     *services
+	tenants *service.TenantService
+}
+
+func newTestCLI(t *testing.T) *testCLI {
+	t.Helper()
+
+	store := memory.NewStore()
+	r := repositories{
+		tenants:       memory.NewTenantRepository(store),
+		users:         memory.NewUserRepository(store),
+		roles:         memory.NewRoleRepository(store),
+		departments:   memory.NewDepartmentRepository(store),
+		requests:      memory.NewRequestRepository(store),
+		mentors:       memory.NewMentorRepository(store),
+		learnings:     memory.NewLearningRepository(store),
+		availability:  memory.NewAvailabilityRepository(store),
+		notifications: memory.NewNotificationRepository(store),
+		outbox:        memory.NewOutboxRepository(store),
+		approvals:     memory.NewApprovalRepository(store),
+		enrollments:   memory.NewEnrollmentRepository(store),
+		skills:        memory.NewSkillRepository(store),
+		competencies:  memory.NewCompetencyRepository(store),
+		jobs:          memory.NewJobRepository(store),
+		tx:            memory.NewTxManager(store),
+	}
+	cfg := &config.Config{}
+	cfg.Auth.JWTSecret = "test-secret"
+	cfg.Auth.TokenTTL = time.Hour
+	cfg.Approval.Stages = []string{"admin"}
+	cfg.Jobs.Lease = time.Minute
+
+	svc, err := newServices(cfg, r)
+	if err != nil {
+		t.Fatalf("newServices: %v", err)
+	}
+	return &testCLI{
+		svc:     svc,
+		tenants: service.NewTenantService(r.tx, r.tenants, r.roles, r.users, memory.NewQuestionnaireRepository(store)),
+	}
+}
+
+// exec runs a command line the way main does and returns what it printed
+func (c *testCLI) exec(args ...string) (string, error) {
+	opts, err := parseOptions(args, io.Discard)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	err = run(context.Background(), opts, &out, c.svc)
+	return out.String(), err
+}
+
+// mustExec runs a command that must succeed
+func (c *testCLI) mustExec(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := c.exec(args...)
+	if err != nil {
+		t.Fatalf("%s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+// emails lists the users "-json user list" prints
+func (c *testCLI) emails(t *testing.T, global ...string) []string {
+	t.Helper()
+	var users []domain.User
+	out := c.mustExec(t, append(global, "-json", "user", "list")...)
+	if err := json.Unmarshal([]byte(out), &users); err != nil {
+		t.Fatalf("decode user list %q: %v", out, err)
+	}
+	emails := make([]string, len(users))
+	for i, u := range users {
+		emails[i] = u.Email
+	}
+	slices.Sort(emails)
+	return emails
+}
+
+func TestParseOptions(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/etc/learning/config.yaml")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    options
+		wantErr bool
+	}{
+		{
+			name: "command only",
+			args: []string{"user", "list"},
+			want: options{configPath: "/etc/learning/config.yaml", args: []string{"user", "list"}},
+		},
+		{
+			name: "global flags",
+			args: []string{"-config", "local.yaml", "-json", "-tenant", "acme", "role", "list"},
+			want: options{configPath: "local.yaml", json: true, tenant: "acme", args: []string{"role", "list"}},
+		},
+		{
+			name: "command flags stay with the command",
+			args: []string{"-json", "user", "create", "-name", "Jane", "-json"},
+			want: options{configPath: "/etc/learning/config.yaml", json: true, args: []string{"user", "create", "-name", "Jane", "-json"}},
+		},
+		{name: "no command", args: nil, wantErr: true},
+		{name: "group without command", args: []string{"-json", "user"}, wantErr: true},
+		{name: "unknown flag", args: []string{"-verbose", "user", "list"}, wantErr: true},
+		{name: "flag without value", args: []string{"-tenant"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			got, err := parseOptions(tt.args, &stderr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseOptions() = %+v, want an error", got)
+				}
+				if !strings.Contains(stderr.String(), "Usage: admin") {
+					t.Errorf("stderr = %q, want the usage", stderr.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOptions() error = %v", err)
+			}
+			if got.configPath != tt.want.configPath || got.json != tt.want.json ||
+				got.tenant != tt.want.tenant || !slices.Equal(got.args, tt.want.args) {
+				t.Errorf("parseOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	c := newTestCLI(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"user", "frobnicate"}, want: `unknown command "user frobnicate"`},
+		{args: []string{"widget", "list"}, want: `unknown command "widget list"`},
+		{args: []string{"list", "user"}, want: `unknown command "list user"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			out, err := c.exec(tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %s", err, tt.want)
+			}
+			if out != "" {
+				t.Errorf("printed %q for an unknown command", out)
+			}
+		})
+	}
+}
+
+func TestRun_Arguments(t *testing.T) {
+	c := newTestCLI(t)
+	c.mustExec(t, "user", "create", "-name", "Jane Doe", "-email", "jane@example.com", "-password", password)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown command flag", args: []string{"user", "create", "-nickname", "JD"}},
+		{name: "missing user", args: []string{"user", "promote"}},
+		{name: "too many users", args: []string{"user", "promote", "jane@example.com", "john@example.com"}},
+		{name: "missing role", args: []string{"user", "set-role", "jane@example.com"}},
+		{name: "missing mentor", args: []string{"learning", "reassign", "some-learning"}},
+		{name: "unknown role", args: []string{"user", "create", "-name", "John", "-email", "john@example.com", "-role", "wizard"}},
+		{name: "unknown user", args: []string{"user", "promote", "john@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.exec(tt.args...); err == nil {
+				t.Errorf("%s succeeded, want an error", strings.Join(tt.args, " "))
+			}
+		})
+	}
+
+	// The same command with valid arguments works
+	out := c.mustExec(t, "-json", "user", "promote", "jane@example.com")
+	var user domain.User
+	if err := json.Unmarshal([]byte(out), &user); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if user.Role != domain.RoleAdmin {
+		t.Errorf("role after promote = %s, want %s", user.Role, domain.RoleAdmin)
+	}
+}
+
+func TestRun_Output(t *testing.T) {
+	c := newTestCLI(t)
+
+	out := c.mustExec(t, "user", "create", "-name", "Jane Doe", "-email", "jane@example.com")
+	if !strings.Contains(out, "jane@example.com") || !strings.Contains(out, "password") {
+		t.Errorf("text output %q, want the user and the generated password", out)
+	}
+
+	out = c.mustExec(t, "-json", "user", "create", "-email", "john@example.com", "-name", "John", "-password", password)
+	var created userResult
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if created.Email != "john@example.com" || created.Password != "" {
+		t.Errorf("created = %+v, want john without a generated password", created)
+	}
+
+	out = c.mustExec(t, "user", "list")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") {
+		t.Errorf("user list = %q, want a header and two users", out)
+	}
+}
+
+func TestRun_Tenant(t *testing.T) {
+	c := newTestCLI(t)
+	acme, _, err := c.tenants.CreateTenant(context.Background(), &domain.Tenant{
+		Slug:     "acme",
+		Name:     "Acme Corp",
+		Settings: domain.TenantSettings{MentorCapacity: 3},
+	}, service.TenantAdmin{Name: "Ann", Email: "ann@acme.example", Password: password})
+	if err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	c.mustExec(t, "user", "create", "-name", "Jane", "-email", "jane@example.com", "-password", password)
+	c.mustExec(t, "-tenant", "acme", "user", "create", "-name", "Bob", "-email", "bob@acme.example", "-password", password)
+
+	if got, want := c.emails(t), []string{"jane@example.com"}; !slices.Equal(got, want) {
+		t.Errorf("default tenant users = %v, want %v", got, want)
+	}
+	want := []string{"ann@acme.example", "bob@acme.example"}
+	if got := c.emails(t, "-tenant", "acme"); !slices.Equal(got, want) {
+		t.Errorf("users of -tenant acme = %v, want %v", got, want)
+	}
+	if got := c.emails(t, "-tenant", acme.ID); !slices.Equal(got, want) {
+		t.Errorf("users of -tenant %s = %v, want %v", acme.ID, got, want)
+	}
+
+	// Users of another tenant cannot be named
+	if _, err := c.exec("user", "promote", "bob@acme.example"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("promote in the default tenant error = %v, want %v", err, domain.ErrUserNotFound)
+	}
+
+	if _, err := c.exec("-tenant", "globex", "user", "list"); !errors.Is(err, domain.ErrTenantNotFound) {
+		t.Errorf("-tenant globex error = %v, want %v", err, domain.ErrTenantNotFound)
+	}
+}
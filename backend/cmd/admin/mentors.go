@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// mentorRow is a single mentor in an import file
+type mentorRow struct {
+	Name       string `json:"name"`
+	JobTitle   string `json:"jobTitle"`
+	Experience string `json:"experience"`
+	Email      string `json:"email"`
+	Telegram   string `json:"telegram"`
+}
+
+// importResult reports the outcome of one imported row
+type importResult struct {
+	Row    int    `json:"row"`
+	Email  string `json:"email"`
+	Status string `json:"status"` // created | valid | failed
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (a *app) mentorList(ctx context.Context, args []string) error {
+	mentors, err := a.mentors.GetAllMentors(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	a.out.result(mentors, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tEMAIL\tJOB TITLE\tWORKLOAD\tSTATUS")
+		for _, m := range mentors {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", m.ID, m.Name, m.Email, m.JobTitle, m.Workload, activeStatus(m.DeactivatedAt))
+		}
+	})
+	return nil
+}
+
+func (a *app) mentorImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("mentor import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the file without creating mentors")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected exactly one CSV or JSON file")
+	}
+
+	rows, err := readMentorRows(positional[0])
+	if err != nil {
+		return err
+	}
+
+	results := make([]importResult, 0, len(rows))
+	failed := 0
+	for i, row := range rows {
+		result := importResult{Row: i + 1, Email: row.Email}
+
+		switch {
+		case row.Name == "" || row.JobTitle == "" || row.Email == "":
+			result.Status = "failed"
+			result.Error = "name, jobTitle and email are required"
+		case *dryRun:
+			result.Status = "valid"
+		default:
+			mentor, err := a.mentors.CreateMentor(ctx, row.Name, row.JobTitle, row.Experience, row.Email, row.Telegram, nil, 0)
+			if err != nil {
+				result.Status = "failed"
+				result.Error = err.Error()
+			} else {
+				result.Status = "created"
+				result.ID = mentor.ID
+			}
+		}
+
+		if result.Status == "failed" {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	a.out.result(results, func(w io.Writer) {
+		fmt.Fprintln(w, "ROW\tEMAIL\tSTATUS\tDETAILS")
+		for _, r := range results {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.Row, r.Email, r.Status, r.ID+r.Error)
+		}
+	})
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(rows))
+	}
+	return nil
+}
+
+func (a *app) mentorRecalcWorkload(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("mentor recalc-workload", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report drift without fixing it")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	corrections, err := a.mentors.RecalculateWorkloads(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	a.out.result(corrections, func(w io.Writer) {
+		if len(corrections) == 0 {
+			fmt.Fprintln(w, "all mentor workloads are consistent")
+			return
+		}
+		fmt.Fprintln(w, "MENTOR\tNAME\tSTORED\tACTUAL")
+		for _, c := range corrections {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", c.MentorID, c.MentorName, c.Stored, c.Actual)
+		}
+	})
+	return nil
+}
+
+func (a *app) mentorHandoff(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("mentor handoff", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the proposed replacements without moving anything")
+	note := fs.String("note", "", "note appended to every handed-off learning")
+	deactivate := fs.Bool("deactivate", false, "deactivate the mentor afterwards")
+	overrides := map[string]string{}
+	fs.Func("assign", "override a replacement as LEARNING_ID=MENTOR_ID (repeatable)", func(v string) error {
+		learningID, mentorID, ok := strings.Cut(v, "=")
+		if !ok || learningID == "" || mentorID == "" {
+			return errors.New("expected LEARNING_ID=MENTOR_ID")
+		}
+		overrides[learningID] = mentorID
+		return nil
+	})
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected exactly one mentor id")
+	}
+
+	var plan *service.HandoffPlan
+	if *dryRun {
+		plan, err = a.handoffs.PlanHandoff(ctx, positional[0])
+	} else {
+		plan, err = a.handoffs.ExecuteHandoff(ctx, positional[0], service.HandoffOptions{
+			Overrides:  overrides,
+			Note:       *note,
+			Deactivate: *deactivate,
+		})
+	}
+	if plan != nil {
+		a.out.result(plan, func(w io.Writer) {
+			if len(plan.Assignments) == 0 {
+				fmt.Fprintf(w, "%s has no active learnings\n", plan.MentorName)
+				return
+			}
+			fmt.Fprintln(w, "LEARNING\tLEARNER\tTOPIC\tNEW MENTOR")
+			for _, assignment := range plan.Assignments {
+				mentor := assignment.MentorName
+				if assignment.MentorID == "" {
+					mentor = "(no capacity)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", assignment.LearningID, assignment.UserName, assignment.Topic, mentor)
+			}
+		})
+	}
+	return err
+}
+
+func (a *app) mentorDeactivate(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one mentor id")
+	}
+
+	mentor, err := a.mentors.DeactivateMentor(ctx, args[0])
+	if err != nil {
+		var activeErr *service.ActiveLearningsError
+		if errors.As(err, &activeErr) {
+			return fmt.Errorf("%w; move them with 'mentor handoff': %s", err, strings.Join(activeErr.LearningIDs, ", "))
+		}
+		return err
+	}
+
+	a.printMentor(mentor)
+	return nil
+}
+
+func (a *app) mentorReactivate(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one mentor id")
+	}
+
+	mentor, err := a.mentors.ReactivateMentor(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	a.printMentor(mentor)
+	return nil
+}
+
+// printMentor prints a single mentor
+func (a *app) printMentor(mentor *domain.Mentor) {
+	a.out.result(mentor, func(w io.Writer) {
+		fmt.Fprintf(w, "id:\t%s\n", mentor.ID)
+		fmt.Fprintf(w, "name:\t%s\n", mentor.Name)
+		fmt.Fprintf(w, "email:\t%s\n", mentor.Email)
+		fmt.Fprintf(w, "workload:\t%d\n", mentor.Workload)
+		fmt.Fprintf(w, "status:\t%s\n", activeStatus(mentor.DeactivatedAt))
+	})
+}
+
+// readMentorRows reads mentors from a CSV (with header) or JSON array file
+func readMentorRows(path string) ([]mentorRow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var rows []mentorRow
+		if err := json.NewDecoder(f).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return rows, nil
+	}
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	// Map header names to column indexes
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		key := strings.ToLower(strings.NewReplacer("_", "", " ", "").Replace(strings.TrimSpace(name)))
+		columns[key] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	rows := make([]mentorRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		rows = append(rows, mentorRow{
+			Name:       field(record, "name"),
+			JobTitle:   field(record, "jobtitle"),
+			Experience: field(record, "experience"),
+			Email:      field(record, "email"),
+			Telegram:   field(record, "telegram"),
+		})
+	}
+
+	return rows, nil
+}
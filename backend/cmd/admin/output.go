@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// printer writes command results either as text or as JSON
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// result prints v as JSON, or calls text to render it for humans
+func (p *printer) result(v any, text func(w io.Writer)) {
+	if p.json {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(v)
+		return
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	text(tw)
+	_ = tw.Flush()
+}
+
+// fail prints an error in the selected format
+func (p *printer) fail(err error) {
+	if p.json {
+		_ = json.NewEncoder(p.w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	fmt.Fprintln(os.Stderr, "error:", err)
+}
+
+// parseFlags parses command flags that may appear before or after positional args
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// generatePassword returns a random URL-safe password
+func generatePassword() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// optional converts an empty flag value to nil
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// deref returns the value of an optional string or "-"
+func deref(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
+
+// activeStatus renders a deactivation timestamp as a status column
+func activeStatus(deactivatedAt *time.Time) string {
+	if deactivatedAt == nil {
+		return "active"
+	}
+	return "deactivated " + deactivatedAt.Format("2006-01-02")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func (a *app) queueList(ctx context.Context, args []string) error {
+	queue, err := a.queue.GetQueue(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.out.result(dto.ToRequestResponseDTOs(queue), func(w io.Writer) {
+		fmt.Fprintln(w, "POS\tID\tPRIORITY\tQUEUED AT\tLEARNER\tTOPIC")
+		for _, r := range queue {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\n", r.QueuePosition, r.ID, r.Priority, r.QueuedAt.Format("2006-01-02 15:04"), r.UserName, r.Topic)
+		}
+	})
+	return nil
+}
+
+func (a *app) queueDispatch(ctx context.Context, args []string) error {
+	started, err := a.queue.Dispatch(ctx)
+	if err != nil {
+		return fmt.Errorf("%w (%d requests assigned before the failure)", err, len(started))
+	}
+
+	a.out.result(dto.ToLearningResponseDTOs(started), func(w io.Writer) {
+		fmt.Fprintf(w, "assigned:\t%d\n", len(started))
+		for _, l := range started {
+			fmt.Fprintf(w, "  %s\t%s -> %s\n", l.RequestTopic, l.UserName, l.MentorName)
+		}
+	})
+	return nil
+}
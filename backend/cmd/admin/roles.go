@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (a *app) roleList(ctx context.Context, args []string) error {
+	roles, err := a.roles.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.out.result(roles, func(w io.Writer) {
+		fmt.Fprintln(w, "NAME\tBUILT-IN\tPERMISSIONS")
+		for _, r := range roles {
+			permissions := make([]string, len(r.Permissions))
+			for i, p := range r.Permissions {
+				permissions[i] = string(p)
+			}
+			fmt.Fprintf(w, "%s\t%t\t%s\n", r.Name, r.BuiltIn, strings.Join(permissions, ","))
+		}
+	})
+	return nil
+}
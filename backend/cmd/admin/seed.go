@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// demoPassword is shared by all demo accounts
+const demoPassword = "demo-password"
+
+// demoEmployee describes a demo account and its training request
+type demoEmployee struct {
+	name, email, department, jobTitle, telegram string
+	topic, description                          string
+	assign                                      bool
+}
+
+var demoEmployees = []demoEmployee{
+	{
+		name: "Alice Johnson", email: "alice.demo@example.com",
+		department: "Engineering", jobTitle: "Backend Developer", telegram: "@alice_demo",
+		topic: "Go concurrency", description: "Goroutines, channels and context cancellation in production services",
+		assign: true,
+	},
+	{
+		name: "Bob Smith", email: "bob.demo@example.com",
+		department: "Design", jobTitle: "UX Designer", telegram: "@bob_demo",
+		topic: "Design systems", description: "Building and maintaining a component library with developers",
+		assign: true,
+	},
+	{
+		name: "Carol White", email: "carol.demo@example.com",
+		department: "Sales", jobTitle: "Account Manager", telegram: "@carol_demo",
+		topic: "Negotiation", description: "Preparing for enterprise contract renewals",
+	},
+}
+
+// seedResult reports what was created for one demo employee
+type seedResult struct {
+	Email      string `json:"email"`
+	Status     string `json:"status"` // created | skipped
+	UserID     string `json:"userId,omitempty"`
+	RequestID  string `json:"requestId,omitempty"`
+	LearningID string `json:"learningId,omitempty"`
+	MentorName string `json:"mentorName,omitempty"`
+}
+
+func (a *app) seedDemo(ctx context.Context, args []string) error {
+	results := make([]seedResult, 0, len(demoEmployees))
+
+	for _, demo := range demoEmployees {
+		result := seedResult{Email: demo.email}
+
+		user, err := a.users.CreateUser(
+			ctx, demo.name, demo.email, demoPassword, domain.RoleEmployee,
+			optional(demo.department), optional(demo.jobTitle), optional(demo.telegram),
+		)
+		if errors.Is(err, domain.ErrUserAlreadyExists) {
+			result.Status = "skipped"
+			results = append(results, result)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", demo.email, err)
+		}
+		result.Status = "created"
+		result.UserID = user.ID
+
+		request, err := a.requests.CreateRequest(ctx, user.ID, demo.topic, demo.description, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request for %s: %w", demo.email, err)
+		}
+		result.RequestID = request.ID
+
+		if demo.assign {
+			mentors, err := a.mentors.GetAvailableMentors(ctx, time.Time{}, time.Time{})
+			if err != nil {
+				return err
+			}
+			if len(mentors) > 0 {
+				learning, err := a.requests.AssignMentor(ctx, request.ID, mentors[0].ID)
+				if err != nil {
+					return fmt.Errorf("failed to assign mentor for %s: %w", demo.email, err)
+				}
+				result.LearningID = learning.ID
+				result.MentorName = learning.MentorName
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	a.out.result(results, func(w io.Writer) {
+		fmt.Fprintln(w, "EMAIL\tSTATUS\tREQUEST\tMENTOR")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Email, r.Status, r.RequestID, r.MentorName)
+		}
+		fmt.Fprintf(w, "\ndemo password:\t%s\n", demoPassword)
+	})
+	return nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// userResult is the JSON shape of a user, with a generated password if any
+type userResult struct {
+	*domain.User
+	Password string `json:"password,omitempty"`
+}
+
+func (a *app) userList(ctx context.Context, args []string) error {
+	users, err := a.users.GetAllUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.out.result(users, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tEMAIL\tROLE\tDEPARTMENT\tSTATUS")
+		for _, u := range users {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", u.ID, u.Name, u.Email, u.Role, deref(u.Department), activeStatus(u.DeactivatedAt))
+		}
+	})
+	return nil
+}
+
+func (a *app) userCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	name := fs.String("name", "", "full name")
+	email := fs.String("email", "", "email (login)")
+	role := fs.String("role", string(domain.RoleEmployee), "role name, see role list")
+	password := fs.String("password", "", "password, generated if empty")
+	department := fs.String("department", "", "department")
+	jobTitle := fs.String("job-title", "", "job title")
+	telegram := fs.String("telegram", "", "telegram handle")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	generated := ""
+	if *password == "" {
+		var err error
+		if generated, err = generatePassword(); err != nil {
+			return err
+		}
+		*password = generated
+	}
+
+	user, err := a.users.CreateUser(
+		ctx, *name, *email, *password, domain.UserRole(*role),
+		optional(*department), optional(*jobTitle), optional(*telegram),
+	)
+	if err != nil {
+		return err
+	}
+
+	a.printUser(user, generated)
+	return nil
+}
+
+func (a *app) userPromote(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one user email or id")
+	}
+	return a.changeRole(ctx, args[0], domain.RoleAdmin)
+}
+
+func (a *app) userDemote(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one user email or id")
+	}
+	return a.changeRole(ctx, args[0], domain.RoleEmployee)
+}
+
+func (a *app) userSetRole(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New("expected a user email or id and a role")
+	}
+	return a.changeRole(ctx, args[0], domain.UserRole(args[1]))
+}
+
+func (a *app) changeRole(ctx context.Context, ref string, role domain.UserRole) error {
+	user, err := a.resolveUser(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	user, err = a.users.ChangeRole(ctx, user.ID, role)
+	if err != nil {
+		return err
+	}
+
+	a.printUser(user, "")
+	return nil
+}
+
+func (a *app) userResetPassword(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	password := fs.String("password", "", "new password, generated if empty")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected exactly one user email or id")
+	}
+
+	user, err := a.resolveUser(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+
+	generated := ""
+	if *password == "" {
+		if generated, err = generatePassword(); err != nil {
+			return err
+		}
+		*password = generated
+	}
+
+	user, err = a.users.ResetPassword(ctx, user.ID, *password)
+	if err != nil {
+		return err
+	}
+
+	a.printUser(user, generated)
+	return nil
+}
+
+func (a *app) userDeactivate(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one user email or id")
+	}
+
+	user, err := a.resolveUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	// The CLI acts as no particular user, so there is no self to protect
+	user, err = a.users.DeactivateUser(ctx, user.ID, "")
+	if err != nil {
+		return err
+	}
+
+	a.printUser(user, "")
+	return nil
+}
+
+func (a *app) userReactivate(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected exactly one user email or id")
+	}
+
+	user, err := a.resolveUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	user, err = a.users.ReactivateUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	a.printUser(user, "")
+	return nil
+}
+
+func (a *app) userSetManager(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New("expected a user and a manager email or id, or none")
+	}
+
+	user, err := a.resolveUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	var managerID *string
+	if args[1] != "none" {
+		manager, err := a.resolveUser(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		managerID = &manager.ID
+	}
+
+	user, err = a.users.SetManager(ctx, user.ID, managerID)
+	if err != nil {
+		return err
+	}
+
+	a.printUser(user, "")
+	return nil
+}
+
+func (a *app) userImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show the changes without writing them")
+	invite := fs.Bool("invite", false, "email created users an invitation")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("expected exactly one CSV or JSON file")
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var rows []domain.UserImportRow
+	if strings.EqualFold(filepath.Ext(positional[0]), ".json") {
+		rows, err = service.ParseUserJSON(f, "", nil)
+	} else {
+		rows, err = service.ParseUserCSV(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := a.imports.Import(ctx, rows, domain.UserImportOptions{DryRun: *dryRun, Invite: *invite})
+	if err != nil {
+		return err
+	}
+	return a.printImport(report)
+}
+
+func (a *app) userSyncHRIS(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return errors.New("hris-sync takes no arguments")
+	}
+	source, err := a.imports.TenantHRISSource(ctx, a.hris)
+	if err != nil {
+		return err
+	}
+
+	report, err := a.imports.Sync(ctx, source)
+	if err != nil {
+		return err
+	}
+	return a.printImport(report)
+}
+
+// printImport prints the outcome of every row and fails if any row did
+func (a *app) printImport(report *domain.UserImportReport) error {
+	a.out.result(report, func(w io.Writer) {
+		fmt.Fprintln(w, "ROW\tEMAIL\tACTION\tDETAILS")
+		for _, r := range report.Rows {
+			details := r.Error
+			if len(r.Changes) > 0 {
+				details = strings.Join(r.Changes, ", ")
+			}
+			if r.Invited {
+				details = "invited"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.Row, r.Email, r.Action, details)
+		}
+		fmt.Fprintf(w, "\ncreated %d, updated %d, unchanged %d, failed %d", report.Created, report.Updated, report.Unchanged, report.Failed)
+		if report.DryRun {
+			fmt.Fprint(w, " (dry run, nothing written)")
+		}
+		fmt.Fprintln(w)
+	})
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", report.Failed, len(report.Rows))
+	}
+	return nil
+}
+
+// resolveUser finds a user by email or ID
+func (a *app) resolveUser(ctx context.Context, ref string) (*domain.User, error) {
+	if strings.Contains(ref, "@") {
+		return a.users.GetUserByEmail(ctx, ref)
+	}
+	return a.users.GetUserByID(ctx, ref)
+}
+
+// printUser prints a single user and the generated password, if any
+func (a *app) printUser(user *domain.User, password string) {
+	a.out.result(userResult{User: user, Password: password}, func(w io.Writer) {
+		fmt.Fprintf(w, "id:\t%s\n", user.ID)
+		fmt.Fprintf(w, "name:\t%s\n", user.Name)
+		fmt.Fprintf(w, "email:\t%s\n", user.Email)
+		fmt.Fprintf(w, "role:\t%s\n", user.Role)
+		fmt.Fprintf(w, "status:\t%s\n", activeStatus(user.DeactivatedAt))
+		if password != "" {
+			fmt.Fprintf(w, "password:\t%s\n", password)
+		}
+	})
+}
@@ -2,35 +2,112 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
+	nethttp "net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 
 	"github.com/mnkhmtv/corporate-learning-module/backend/config"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/certificate"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/clamav"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/health"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/lifecycle"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/mail"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/netguard"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/blob"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/migrations"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/postgres"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http"
 )
 
+const usage = `Usage: app [-config path] [command]
+
+Commands:
+  serve                 Start the HTTP server (default)
+  migrate up            Apply all pending migrations
+  migrate down N        Revert the last N migrations
+  migrate goto V        Migrate up or down to version V
+  migrate version       Print the applied schema version
+  migrate force V       Set the schema version without running migrations
+
+Migrate flags:
+  --dry-run             Print the migrations that would run and exit
+`
+
 func main() {
+	defaultConfig := os.Getenv("CONFIG_PATH")
+	if defaultConfig == "" {
+		defaultConfig = "config/config.yaml"
+	}
+	configPath := flag.String("config", defaultConfig, "path to the YAML config file")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
 	// Load configuration
-	cfg, err := config.Load("config/config.yaml")
+	cfg, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	args := flag.Args()
+	command := "serve"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	switch command {
+	case "serve":
+		if err := serve(cfg); err != nil {
+			log.Fatalf("serve: %v", err)
+		}
+	case "migrate":
+		if err := runMigrateCommand(cfg.Database, args, os.Stdout); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// serve runs the HTTP server until SIGINT/SIGTERM. It returns an error
+// when setup failed, the server could not listen or it did not shut down
+// cleanly, so that supervisors see a failed start as a failure rather
+// than a clean stop.
+func serve(cfg *config.Config) error {
 	// Setup logger
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
+	// Cancelled on SIGINT/SIGTERM to start graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers and resources are released in reverse order on exit
+	lc := lifecycle.New(logger)
+	// A failed start still stops the workers and closes what was opened so
+	// far; after a graceful shutdown this is a no-op
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := lc.Shutdown(ctx); err != nil {
+			logger.Error("Shutdown after failed start", "error", err)
+		}
+	}()
+
 	// Initialize database connection
-	ctx := context.Background()
 	dbConfig := postgres.Config{
 		Host:     cfg.Database.Host,
 		Port:     cfg.Database.Port,
@@ -42,36 +119,194 @@ func main() {
 
 	pool, err := postgres.NewPool(ctx, dbConfig)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
-	defer pool.Close()
+	lc.OnShutdown("database pool", func(context.Context) error {
+		postgres.Close(pool)
+		return nil
+	})
 
 	logger.Info("Connected to database successfully")
 
+	lc.Go("db connection metrics", func(ctx context.Context) error {
+		postgres.CollectConnectionMetrics(ctx, pool, 10*time.Second)
+		return nil
+	})
+
 	// Run database migrations
 	if cfg.Database.AutoMigrate {
 		logger.Info("Auto-migration enabled, running migrations...")
 
 		if err := runMigrations(cfg.Database); err != nil {
-			logger.Error("Migration failed", "error", err)
-			log.Fatalf("Migration failed: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("migration failed: %w", err)
 		}
 		logger.Info("Migrations completed successfully")
 	}
 
+	// Readiness checks
+	expectedVersion, err := migrations.Latest()
+	if err != nil {
+		return fmt.Errorf("failed to determine expected schema version: %w", err)
+	}
+
+	monitor := health.NewMonitor(cfg.Server.HealthCheckTimeout)
+	monitor.Register(postgres.NewPingCheck(pool))
+	monitor.Register(postgres.NewSchemaCheck(pool, expectedVersion))
+
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(pool)
+	roleRepo := postgres.NewRoleRepository(pool)
+	departmentRepo := postgres.NewDepartmentRepository(pool)
 	requestRepo := postgres.NewRequestRepository(pool)
 	mentorRepo := postgres.NewMentorRepository(pool)
 	learningRepo := postgres.NewLearningRepository(pool)
+	notificationRepo := postgres.NewNotificationRepository(pool)
+	availabilityRepo := postgres.NewAvailabilityRepository(pool)
+	approvalRepo := postgres.NewApprovalRepository(pool)
+	commentRepo := postgres.NewCommentRepository(pool)
+	attachmentRepo := postgres.NewAttachmentRepository(pool)
+	courseRepo := postgres.NewCourseRepository(pool)
+	enrollmentRepo := postgres.NewEnrollmentRepository(pool)
+	skillRepo := postgres.NewSkillRepository(pool)
+	competencyRepo := postgres.NewCompetencyRepository(pool)
+	certificateRepo := postgres.NewCertificateRepository(pool)
+	questionnaireRepo := postgres.NewQuestionnaireRepository(pool)
+	feedbackRepo := postgres.NewFeedbackRepository(pool)
+	checkInRepo := postgres.NewCheckInRepository(pool)
+	jobRepo := postgres.NewJobRepository(pool)
+	outboxRepo := postgres.NewOutboxRepository(pool)
+	webhookRepo := postgres.NewWebhookRepository(pool)
+	tenantRepo := postgres.NewTenantRepository(pool)
+	txManager := postgres.NewTxManager(pool)
+
+	blobStore, err := newBlobStore(cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize attachment storage: %w", err)
+	}
+
+	approvalChain, err := domain.ParseApprovalChain(cfg.Approval.Stages)
+	if err != nil {
+		return fmt.Errorf("invalid approval chain: %w", err)
+	}
+
+	certificateRenderer, err := certificate.NewRenderer(cfg.Certificates.Template, cfg.Certificates.PublicURL)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate template: %w", err)
+	}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
-	userService := service.NewUserService(userRepo)
-	requestService := service.NewRequestService(requestRepo, userRepo, mentorRepo, learningRepo)
-	mentorService := service.NewMentorService(mentorRepo)
-	learningService := service.NewLearningService(learningRepo, mentorRepo, requestRepo)
+	authService := service.NewAuthService(userRepo, departmentRepo, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
+	userService := service.NewUserService(userRepo, roleRepo, departmentRepo)
+	notificationService := service.NewNotificationService(notificationRepo)
+	outboxService := service.NewOutboxService(outboxRepo)
+	queueService := service.NewQueueService(txManager, requestRepo, mentorRepo, learningRepo, availabilityRepo, notificationService, outboxService)
+	approvalService := service.NewApprovalService(txManager, requestRepo, userRepo, departmentRepo, approvalRepo, enrollmentRepo, queueService, notificationService, outboxService, approvalChain)
+	requestService := service.NewRequestService(txManager, requestRepo, userRepo, mentorRepo, learningRepo, availabilityRepo, approvalService, outboxService)
+	mentorService := service.NewMentorService(mentorRepo, learningRepo, availabilityRepo, tenantRepo, queueService)
+	competencyService := service.NewCompetencyService(txManager, skillRepo, competencyRepo, userRepo, departmentRepo)
+	certificateService := service.NewCertificateService(certificateRepo, learningRepo, userRepo, blobStore, certificateRenderer)
+	learningService := service.NewLearningService(txManager, learningRepo, mentorRepo, userRepo, requestRepo, availabilityRepo, queueService, approvalService, competencyService, outboxService, certificateService)
+	availabilityService := service.NewAvailabilityService(availabilityRepo, mentorRepo, queueService)
+	handoffService := service.NewHandoffService(txManager, mentorRepo, learningRepo, availabilityRepo, notificationService, outboxService)
+	commentService := service.NewCommentService(txManager, commentRepo, requestRepo, learningRepo, mentorRepo, userRepo, notificationService)
+	var virusScanner domain.VirusScanner
+	if cfg.Storage.ClamAVAddress != "" {
+		virusScanner = clamav.New(cfg.Storage.ClamAVAddress, cfg.Storage.ClamAVTimeout)
+	} else {
+		logger.Warn("Attachments are stored without a virus scan, set storage.clamav_address to enable it")
+	}
+	attachmentService := service.NewAttachmentService(attachmentRepo, learningRepo, blobStore, virusScanner, commentService, cfg.Storage.MaxUploadSize, cfg.Storage.AllowedTypes)
+	courseService := service.NewCourseService(courseRepo, enrollmentRepo, requestRepo, userRepo, approvalService, competencyService)
+	feedbackService := service.NewFeedbackService(txManager, questionnaireRepo, feedbackRepo, learningRepo, mentorRepo, userRepo)
+	checkInService := service.NewCheckInService(
+		txManager, checkInRepo, learningRepo, mentorRepo, userRepo, notificationService,
+		time.Duration(cfg.CheckIns.IntervalDays)*24*time.Hour,
+		time.Duration(cfg.CheckIns.StalledAfterDays)*24*time.Hour,
+		cfg.CheckIns.NotifyMentor,
+	)
+	jobService := service.NewJobService(txManager, jobRepo, tenantRepo, workerID(cfg.Jobs), cfg.Jobs.Lease)
+	// Tenant admins choose the endpoints, so deliveries only go to public
+	// addresses
+	webhookClient := netguard.NewClient(cfg.Webhooks.Timeout)
+	if cfg.Webhooks.AllowPrivateNetworks {
+		logger.Warn("Webhook deliveries may reach private networks")
+		webhookClient = &nethttp.Client{Timeout: cfg.Webhooks.Timeout}
+	}
+	webhookService := service.NewWebhookService(
+		txManager, outboxRepo, webhookRepo,
+		webhookClient,
+		cfg.Webhooks.MaxAttempts,
+	)
+	mailer, err := newMailer(cfg.Mail)
+	if err != nil {
+		return fmt.Errorf("invalid mail settings: %w", err)
+	}
+	userImportService := service.NewUserImportService(userRepo, roleRepo, departmentRepo, tenantRepo, authService, jobService, mailer, cfg.Mail.InviteURL)
+	scimService := service.NewSCIMService(txManager, userRepo, roleRepo, departmentRepo, userService)
+	roleService := service.NewRoleService(txManager, roleRepo, userRepo)
+	departmentService := service.NewDepartmentService(txManager, departmentRepo, userRepo)
+	tenantService := service.NewTenantService(txManager, tenantRepo, roleRepo, userRepo, questionnaireRepo)
+
+	// Background jobs
+	jobService.Register(service.JobCheckIns, checkInService.RunJob)
+	if err := jobService.Schedule("check-ins", cfg.CheckIns.Schedule, service.JobCheckIns, nil); err != nil {
+		return fmt.Errorf("invalid check-in schedule: %w", err)
+	}
+	if err := jobService.RegisterCleanup(cfg.Jobs.CleanupSchedule, time.Duration(cfg.Jobs.RetentionDays)*24*time.Hour); err != nil {
+		return fmt.Errorf("invalid job cleanup schedule: %w", err)
+	}
+	if err := webhookService.RegisterJobs(
+		jobService, cfg.Webhooks.Schedule, cfg.Jobs.CleanupSchedule,
+		time.Duration(cfg.Webhooks.RetentionDays)*24*time.Hour,
+	); err != nil {
+		return fmt.Errorf("invalid webhook schedule: %w", err)
+	}
+	jobService.Register(service.JobUserInvite, userImportService.RunInviteJob)
+
+	// Integrations show up in readiness without failing it, since the API
+	// works without them
+	if smtpMailer, ok := mailer.(*mail.SMTPMailer); ok {
+		monitor.RegisterOptional(mail.NewCheck(smtpMailer))
+	}
+	if scanner, ok := virusScanner.(*clamav.Scanner); ok {
+		monitor.RegisterOptional(clamav.NewCheck(scanner))
+	}
+	monitor.RegisterOptional(health.CheckFunc{
+		CheckName: "webhooks",
+		Fn: func(ctx context.Context) error {
+			return jobService.CheckLastRun(ctx, service.JobWebhooks)
+		},
+	})
+	// The HRIS of the configuration fills the default tenant; the others
+	// configure theirs in their settings
+	hris := service.HRISSource{
+		URL:        cfg.HRIS.URL,
+		Token:      cfg.HRIS.Token,
+		RecordsKey: cfg.HRIS.RecordsKey,
+		Fields:     cfg.HRIS.Fields,
+		Invite:     cfg.HRIS.Invite,
+		Client:     &nethttp.Client{Timeout: cfg.HRIS.Timeout},
+
+		TenantClient: netguard.NewClient(cfg.HRIS.Timeout),
+	}
+	if err := userImportService.RegisterSync(jobService, cfg.HRIS.Schedule, hris); err != nil {
+		return fmt.Errorf("invalid HRIS sync schedule: %w", err)
+	}
+	monitor.RegisterOptional(health.CheckFunc{
+		CheckName: "hris",
+		Fn: func(ctx context.Context) error {
+			return jobService.CheckLastRun(ctx, service.JobHRISSync)
+		},
+	})
+
+	lc.Go("job runner", func(ctx context.Context) error {
+		jobService.Run(ctx, cfg.Jobs.PollInterval, cfg.Jobs.Concurrency)
+		return nil
+	})
+	lc.Go("job metrics", func(ctx context.Context) error {
+		postgres.CollectJobMetrics(ctx, pool, 10*time.Second)
+		return nil
+	})
 
 	// Initialize HTTP handler
 	handler := http.NewHandler(
@@ -80,6 +315,26 @@ func main() {
 		requestService,
 		learningService,
 		mentorService,
+		availabilityService,
+		handoffService,
+		notificationService,
+		queueService,
+		approvalService,
+		commentService,
+		attachmentService,
+		courseService,
+		competencyService,
+		certificateService,
+		feedbackService,
+		checkInService,
+		jobService,
+		webhookService,
+		userImportService,
+		scimService,
+		roleService,
+		departmentService,
+		tenantService,
+		monitor,
 	)
 
 	// Setup Gin router
@@ -88,44 +343,144 @@ func main() {
 	}
 
 	router := gin.Default()
-	handler.InitRoutes(router, logger, cfg.Auth.JWTSecret)
+	handler.InitRoutes(router, logger, cfg.Auth.JWTSecret, cfg.SCIM.Token, cfg.Tenancy.SuperAdminToken)
 
 	// Start server
-	addr := ":" + cfg.Server.Port
-	logger.Info("Starting server", "address", addr, "environment", cfg.Env)
+	server := &nethttp.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting server", "address", server.Addr, "environment", cfg.Env)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	var listenErr error
+	select {
+	case listenErr = <-serverErr:
+		if listenErr != nil {
+			logger.Error("Server failed", "error", listenErr)
+		}
+	case <-lc.Failed():
+		logger.Error("Background worker failed, shutting down", "timeout", cfg.Server.ShutdownTimeout)
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received, draining requests", "timeout", cfg.Server.ShutdownTimeout)
+	}
+	stop()
+
+	// Fail readiness first so traffic is routed away before we stop
+	// listening; a server that never listened has no traffic to drain
+	monitor.SetDraining()
+	if listenErr == nil && cfg.Server.DrainDelay > 0 {
+		time.Sleep(cfg.Server.DrainDelay)
+	}
 
-	if err := router.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if err := shutdown(server, lc, cfg.Server.ShutdownTimeout); err != nil {
+		logger.Error("Graceful shutdown failed", "error", err)
+		return errors.Join(listenErr, err)
 	}
+	if listenErr != nil {
+		return fmt.Errorf("http server: %w", listenErr)
+	}
+
+	logger.Info("Server stopped")
+	return nil
 }
 
-// runMigrations applies all pending migrations from file system
-func runMigrations(dbCfg config.DatabaseConfig) error {
-	// Construct database URL
-	dbURL := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		dbCfg.User, dbCfg.Password, dbCfg.Host, dbCfg.Port, dbCfg.DBName, dbCfg.SSLMode,
-	)
+// shutdown drains in-flight HTTP requests, then stops background workers
+// and closes resources, all within the configured grace period
+func shutdown(server *nethttp.Server, lc *lifecycle.Manager, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// Create migrate instance with file:// source
-	m, err := migrate.New(
-		"file://internal/repository/migrations",
-		dbURL,
-	)
+	var errs []error
+	if err := server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+	}
+	if err := lc.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// workerID names this instance in job locks: the configured ID, or the host
+// name and process ID
+func workerID(cfg config.JobsConfig) string {
+	if cfg.WorkerID != "" {
+		return cfg.WorkerID
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "app"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// newBlobStore opens the configured attachment storage
+func newBlobStore(cfg config.StorageConfig) (domain.BlobStore, error) {
+	switch cfg.Driver {
+	case "local":
+		return blob.NewLocalStore(cfg.LocalDir)
+	case "s3":
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}, &nethttp.Client{Timeout: time.Minute})
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+// newMailer sends emails through the configured SMTP relay, or logs them
+// when there is none
+func newMailer(cfg config.MailConfig) (domain.Mailer, error) {
+	if cfg.SMTPHost == "" {
+		return mail.LogMailer{}, nil
+	}
+	return mail.NewSMTPMailer(mail.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.From,
+	})
+}
+
+// runMigrations applies all pending embedded migrations
+func runMigrations(dbCfg config.DatabaseConfig) error {
+	m, err := migrations.New(databaseURL(dbCfg))
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
 	defer m.Close()
 
-	// Run migrations
 	if err := m.Up(); err != nil {
-		if err == migrate.ErrNoChange {
-			log.Println("No new migrations to apply")
-			return nil
-		}
 		return fmt.Errorf("migration up failed: %w", err)
 	}
 
-	log.Println("All migrations applied successfully")
 	return nil
 }
+
+// databaseURL builds the postgres:// URL expected by golang-migrate
+func databaseURL(dbCfg config.DatabaseConfig) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(dbCfg.User, dbCfg.Password),
+		Host:     fmt.Sprintf("%s:%d", dbCfg.Host, dbCfg.Port),
+		Path:     dbCfg.DBName,
+		RawQuery: "sslmode=" + url.QueryEscape(dbCfg.SSLMode),
+	}
+	return u.String()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/config"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/migrations"
+)
+
+// runMigrateCommand handles `app migrate <up|down N|goto V|version|force V> [--dry-run]`
+func runMigrateCommand(dbCfg config.DatabaseConfig, args []string, out io.Writer) error {
+	dryRun := false
+	var positional []string
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run", "-dry-run":
+			dryRun = true
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) == 0 {
+		return errors.New("missing subcommand, expected up, down, goto, version or force")
+	}
+	sub, params := positional[0], positional[1:]
+
+	m, err := migrations.New(databaseURL(dbCfg))
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch sub {
+	case "up":
+		if err := expectParams(sub, params, 0); err != nil {
+			return err
+		}
+		if dryRun {
+			return printPlan(out, m.PlanUp)
+		}
+		if err := m.Up(); err != nil {
+			return err
+		}
+
+	case "down":
+		if err := expectParams(sub, params, 1); err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(params[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("down expects a positive number of migrations, got %q", params[0])
+		}
+		if dryRun {
+			return printPlan(out, func() ([]migrations.Step, error) { return m.PlanDown(n) })
+		}
+		if err := m.Down(n); err != nil {
+			return err
+		}
+
+	case "goto":
+		if err := expectParams(sub, params, 1); err != nil {
+			return err
+		}
+		version, err := strconv.ParseUint(params[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("goto expects a version number, got %q", params[0])
+		}
+		if dryRun {
+			return printPlan(out, func() ([]migrations.Step, error) { return m.PlanGoto(uint(version)) })
+		}
+		if err := m.Goto(uint(version)); err != nil {
+			return err
+		}
+
+	case "force":
+		if err := expectParams(sub, params, 1); err != nil {
+			return err
+		}
+		version, err := strconv.Atoi(params[0])
+		if err != nil || version < -1 {
+			return fmt.Errorf("force expects a version number or -1, got %q", params[0])
+		}
+		if dryRun {
+			fmt.Fprintf(out, "would force schema version to %d\n", version)
+			return nil
+		}
+		if err := m.Force(version); err != nil {
+			return err
+		}
+
+	case "version":
+		if err := expectParams(sub, params, 0); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown subcommand %q", sub)
+	}
+
+	return printVersion(out, m)
+}
+
+// expectParams validates the number of positional arguments
+func expectParams(sub string, params []string, n int) error {
+	if len(params) != n {
+		return fmt.Errorf("%s expects %d argument(s), got %d", sub, n, len(params))
+	}
+	return nil
+}
+
+// printPlan prints the migrations a command would run without applying them
+func printPlan(out io.Writer, plan func() ([]migrations.Step, error)) error {
+	steps, err := plan()
+	if err != nil {
+		return err
+	}
+
+	if len(steps) == 0 {
+		fmt.Fprintln(out, "no migrations to run")
+		return nil
+	}
+
+	for _, step := range steps {
+		fmt.Fprintf(out, "%-4s %03d_%s\n", step.Direction, step.Version, step.Identifier)
+	}
+	return nil
+}
+
+// printVersion prints the applied and latest available schema versions
+func printVersion(out io.Writer, m *migrations.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	latest, err := migrations.Latest()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "version: %d (latest %d)", version, latest)
+	if dirty {
+		fmt.Fprint(out, " dirty")
+	}
+	fmt.Fprintln(out)
+	return nil
+}
@@ -7,16 +7,36 @@ import (
 )
 
 type Config struct {
-	Env      string         `yaml:"env" env:"ENV" env-default:"development"`
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	Auth     AuthConfig     `yaml:"auth"`
+	Env          string            `yaml:"env" env:"ENV" env-default:"development"`
+	Server       ServerConfig      `yaml:"server"`
+	Database     DatabaseConfig    `yaml:"database"`
+	Auth         AuthConfig        `yaml:"auth"`
+	Approval     ApprovalConfig    `yaml:"approval"`
+	Storage      StorageConfig     `yaml:"storage"`
+	Certificates CertificateConfig `yaml:"certificates"`
+	CheckIns     CheckInConfig     `yaml:"check_ins"`
+	Jobs         JobsConfig        `yaml:"jobs"`
+	Webhooks     WebhooksConfig    `yaml:"webhooks"`
+	Mail         MailConfig        `yaml:"mail"`
+	HRIS         HRISConfig        `yaml:"hris"`
+	SCIM         SCIMConfig        `yaml:"scim"`
+	Tenancy      TenancyConfig     `yaml:"tenancy"`
 }
 
 type ServerConfig struct {
-	Port         string        `yaml:"port" env:"SERVER_PORT" env-default:"8080"`
-	ReadTimeout  time.Duration `yaml:"read_timeout" env-default:"10s"`
-	WriteTimeout time.Duration `yaml:"write_timeout" env-default:"10s"`
+	Port              string        `yaml:"port" env:"SERVER_PORT" env-default:"8080"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" env-default:"10s"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" env-default:"5s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" env-default:"10s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" env-default:"60s"`
+	// ShutdownTimeout is the grace period for draining in-flight requests
+	// and closing resources after SIGINT/SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"20s"`
+	// DrainDelay keeps serving while /health/ready reports 503, giving load
+	// balancers time to stop routing before connections are closed
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY" env-default:"0s"`
+	// HealthCheckTimeout bounds each readiness dependency check
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout" env:"SERVER_HEALTH_CHECK_TIMEOUT" env-default:"2s"`
 }
 
 type DatabaseConfig struct {
@@ -34,6 +54,138 @@ type AuthConfig struct {
 	TokenTTL  time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"24h"`
 }
 
+type ApprovalConfig struct {
+	// Stages is the approval chain of new training requests, in order:
+	// "manager" (the employee's line manager) and/or "admin" (L&D). With no
+	// stages requests go straight to the queue.
+	Stages []string `yaml:"stages" env:"APPROVAL_STAGES" env-separator:"," env-default:"admin"`
+}
+
+type StorageConfig struct {
+	// Driver selects where attachment content is kept: "local" (a
+	// directory) or "s3" (an S3-compatible bucket such as MinIO)
+	Driver   string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"local"`
+	LocalDir string `yaml:"local_dir" env:"STORAGE_LOCAL_DIR" env-default:"data/attachments"`
+
+	S3Endpoint  string `yaml:"s3_endpoint" env:"STORAGE_S3_ENDPOINT"`
+	S3Region    string `yaml:"s3_region" env:"STORAGE_S3_REGION" env-default:"us-east-1"`
+	S3Bucket    string `yaml:"s3_bucket" env:"STORAGE_S3_BUCKET"`
+	S3AccessKey string `yaml:"s3_access_key" env:"STORAGE_S3_ACCESS_KEY"`
+	S3SecretKey string `yaml:"s3_secret_key" env:"STORAGE_S3_SECRET_KEY"`
+
+	// MaxUploadSize is the largest attachment accepted, in bytes
+	MaxUploadSize int64 `yaml:"max_upload_size" env:"STORAGE_MAX_UPLOAD_SIZE" env-default:"10485760"`
+	// AllowedTypes lists the MIME types accepted, matched against the type
+	// sniffed from the content
+	AllowedTypes []string `yaml:"allowed_types" env:"STORAGE_ALLOWED_TYPES" env-separator:"," env-default:"application/pdf,image/png,image/jpeg,text/plain,application/zip"`
+
+	// ClamAVAddress is the clamd uploads are scanned with, "host:port" or
+	// the path of its Unix socket; uploads are not scanned when empty
+	ClamAVAddress string        `yaml:"clamav_address" env:"STORAGE_CLAMAV_ADDRESS"`
+	ClamAVTimeout time.Duration `yaml:"clamav_timeout" env:"STORAGE_CLAMAV_TIMEOUT" env-default:"30s"`
+}
+
+type CertificateConfig struct {
+	// Template is the path of the certificate template; empty uses the
+	// built-in one
+	Template string `yaml:"template" env:"CERTIFICATE_TEMPLATE"`
+	// PublicURL is the address third parties reach the API at, printed on
+	// certificates as the verification link
+	PublicURL string `yaml:"public_url" env:"CERTIFICATE_PUBLIC_URL" env-default:"http://localhost:8080"`
+}
+
+type CheckInConfig struct {
+	// IntervalDays is the time between check-ins sent to the learner and
+	// the mentor of an active learning; 0 sends none
+	IntervalDays int `yaml:"interval_days" env:"CHECK_IN_INTERVAL_DAYS" env-default:"14"`
+	// StalledAfterDays is how long a learning may go without plan progress
+	// or activity before it is at risk
+	StalledAfterDays int `yaml:"stalled_after_days" env:"CHECK_IN_STALLED_AFTER_DAYS" env-default:"14"`
+	// NotifyMentor tells the mentor when a learning stalls
+	NotifyMentor bool `yaml:"notify_mentor" env:"CHECK_IN_NOTIFY_MENTOR" env-default:"true"`
+	// Schedule is the cron spec of the job that looks for due check-ins and
+	// stalls
+	Schedule string `yaml:"schedule" env:"CHECK_IN_SCHEDULE" env-default:"@hourly"`
+}
+
+type JobsConfig struct {
+	// PollInterval is how often an instance looks for due jobs
+	PollInterval time.Duration `yaml:"poll_interval" env:"JOBS_POLL_INTERVAL" env-default:"5s"`
+	// Concurrency is how many jobs an instance runs at a time
+	Concurrency int `yaml:"concurrency" env:"JOBS_CONCURRENCY" env-default:"4"`
+	// Lease is how long a job attempt may run; after that another instance
+	// may take the job over
+	Lease time.Duration `yaml:"lease" env:"JOBS_LEASE" env-default:"5m"`
+	// WorkerID identifies the instance in job locks; defaults to host-pid
+	WorkerID string `yaml:"worker_id" env:"JOBS_WORKER_ID"`
+	// RetentionDays is how long finished jobs are kept
+	RetentionDays int `yaml:"retention_days" env:"JOBS_RETENTION_DAYS" env-default:"30"`
+	// CleanupSchedule is the cron spec of the job deleting old finished jobs
+	CleanupSchedule string `yaml:"cleanup_schedule" env:"JOBS_CLEANUP_SCHEDULE" env-default:"@daily"`
+}
+
+type WebhooksConfig struct {
+	// Schedule is the cron spec of the job delivering outbox events
+	Schedule string `yaml:"schedule" env:"WEBHOOKS_SCHEDULE" env-default:"@every 10s"`
+	// Timeout bounds each delivery request
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" env-default:"10s"`
+	// MaxAttempts is how often a delivery is tried before it is dead
+	MaxAttempts int `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+	// RetentionDays is how long fully delivered events are kept
+	RetentionDays int `yaml:"retention_days" env:"WEBHOOKS_RETENTION_DAYS" env-default:"7"`
+	// AllowPrivateNetworks lets deliveries reach loopback, private and
+	// link-local addresses; only for development, since tenant admins
+	// choose the endpoints
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" env:"WEBHOOKS_ALLOW_PRIVATE_NETWORKS"`
+}
+
+type MailConfig struct {
+	// SMTPHost is the relay emails are sent through; emails are only logged
+	// when empty
+	SMTPHost     string `yaml:"smtp_host" env:"MAIL_SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"MAIL_SMTP_PORT" env-default:"587"`
+	SMTPUsername string `yaml:"smtp_username" env:"MAIL_SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"MAIL_SMTP_PASSWORD"`
+	From         string `yaml:"from" env:"MAIL_FROM" env-default:"Corporate Learning <no-reply@localhost>"`
+	// InviteURL is the page of the frontend where invited users choose
+	// their password; the invitation token is added as ?token=
+	InviteURL string `yaml:"invite_url" env:"MAIL_INVITE_URL" env-default:"http://localhost:3000/invite"`
+}
+
+// HRISConfig is the HRIS of the default tenant; the other tenants configure
+// theirs in their settings
+type HRISConfig struct {
+	// URL is the http(s) URL or file path of the JSON employee export; the
+	// default tenant is not synced when empty
+	URL string `yaml:"url" env:"HRIS_URL"`
+	// Token is sent as a bearer token when fetching the export
+	Token string `yaml:"token" env:"HRIS_TOKEN"`
+	// Schedule is the cron spec of the sync job, for every tenant
+	Schedule string `yaml:"schedule" env:"HRIS_SCHEDULE" env-default:"@daily"`
+	// RecordsKey is the key of the employee array when the export is an
+	// object rather than an array
+	RecordsKey string `yaml:"records_key" env:"HRIS_RECORDS_KEY"`
+	// Fields maps import fields to the keys of the export, e.g.
+	// email: workEmail
+	Fields map[string]string `yaml:"fields" env:"HRIS_FIELDS"`
+	// Invite emails an invitation to users created by the sync
+	Invite bool `yaml:"invite" env:"HRIS_INVITE" env-default:"false"`
+	// Timeout bounds the requests for the exports of every tenant
+	Timeout time.Duration `yaml:"timeout" env:"HRIS_TIMEOUT" env-default:"30s"`
+}
+
+type SCIMConfig struct {
+	// Token is a bearer token identity providers provision the default
+	// tenant with, besides the tokens tenant admins issue; unused when empty
+	Token string `yaml:"token" env:"SCIM_TOKEN"`
+}
+
+type TenancyConfig struct {
+	// SuperAdminToken is the bearer token operators create and update
+	// tenants with; the /api/super endpoints are off when empty
+	SuperAdminToken string `yaml:"super_admin_token" env:"SUPER_ADMIN_TOKEN"`
+}
+
 // Load reads configuration from YAML file and environment variables
 func Load(configPath string) (*Config, error) {
 	var cfg Config
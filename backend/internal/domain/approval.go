@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ApprovalStage is one step of the approval chain a training request passes
+// before a mentor is assigned
+type ApprovalStage string
+
+const (
+	StageManager ApprovalStage = "manager" // the employee's line manager
+	StageAdmin   ApprovalStage = "admin"   // an L&D admin
+)
+
+// approvalOrder lists the stages in the order they may appear in a chain
+var approvalOrder = []ApprovalStage{StageManager, StageAdmin}
+
+// IsValid checks if the stage is known
+func (s ApprovalStage) IsValid() bool {
+	switch s {
+	case StageManager, StageAdmin:
+		return true
+	}
+	return false
+}
+
+// Status returns the status of a request waiting at the stage
+func (s ApprovalStage) Status() RequestStatus {
+	if s == StageManager {
+		return RequestAwaitingManager
+	}
+	return RequestPending
+}
+
+// ParseApprovalChain validates a configured approval chain: stages must be
+// known, unique and ordered manager → admin. An empty chain sends new
+// requests straight to the queue.
+func ParseApprovalChain(names []string) ([]ApprovalStage, error) {
+	chain := make([]ApprovalStage, 0, len(names))
+	next := 0
+	for _, name := range names {
+		stage := ApprovalStage(name)
+		if !stage.IsValid() {
+			return nil, fmt.Errorf("%w: unknown approval stage %q", ErrInvalidInput, name)
+		}
+
+		i := next
+		for i < len(approvalOrder) && approvalOrder[i] != stage {
+			i++
+		}
+		if i == len(approvalOrder) {
+			return nil, fmt.Errorf("%w: approval stage %q is repeated or out of order", ErrInvalidInput, name)
+		}
+		next = i + 1
+		chain = append(chain, stage)
+	}
+	return chain, nil
+}
+
+// Decision is the outcome of an approval stage
+type Decision string
+
+const (
+	DecisionApproved Decision = "approved"
+	DecisionRejected Decision = "rejected"
+)
+
+// IsValid checks if the decision is known
+func (d Decision) IsValid() bool {
+	return d == DecisionApproved || d == DecisionRejected
+}
+
+// ApprovalDecision records the decision taken on a training request at one
+// stage of its approval chain
+type ApprovalDecision struct {
+	ID        string        `json:"id"`
+	RequestID string        `json:"requestId"`
+	Stage     ApprovalStage `json:"stage"`
+	DeciderID *string       `json:"deciderId"` // nil once the decider's account is deleted
+	Decision  Decision      `json:"decision"`
+	Comment   *string       `json:"comment,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+
+	// DeciderName is joined from users; empty once the account is deleted
+	DeciderName string `json:"deciderName,omitempty"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Attachment is a file uploaded to a learning, one of its plan items or a
+// comment. The content lives in a BlobStore under StorageKey; Postgres only
+// keeps the metadata.
+type Attachment struct {
+	ID          string    `json:"id"`
+	LearningID  *string   `json:"learningId,omitempty"`
+	PlanItemID  *string   `json:"planItemId,omitempty"` // set together with LearningID
+	CommentID   *string   `json:"commentId,omitempty"`
+	UploaderID  *string   `json:"uploaderId"` // nil once the uploader's account is deleted
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"sha256"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+
+	// UploaderName is joined from users; empty once the account is deleted
+	UploaderName string `json:"uploaderName,omitempty"`
+}
+
+// IsUploader checks if the user uploaded the attachment
+func (a *Attachment) IsUploader(userID string) bool {
+	return a.UploaderID != nil && *a.UploaderID == userID
+}
+
+// maxFileNameLength limits stored file names
+const maxFileNameLength = 255
+
+// CleanFileName reduces a client supplied file name to its base name without
+// control characters, so it is safe to echo in a Content-Disposition header
+func CleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		name = string(runes[:maxFileNameLength])
+	}
+	return name
+}
@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// AbsenceKind classifies an out-of-office period
+type AbsenceKind string
+
+const (
+	AbsenceVacation  AbsenceKind = "vacation"
+	AbsenceSickLeave AbsenceKind = "sick_leave"
+	AbsenceOther     AbsenceKind = "other"
+)
+
+// IsValid checks if the absence kind is known
+func (k AbsenceKind) IsValid() bool {
+	switch k {
+	case AbsenceVacation, AbsenceSickLeave, AbsenceOther:
+		return true
+	}
+	return false
+}
+
+// AvailabilityWindow is a recurring weekly period in which a mentor takes
+// sessions. Times are "HH:MM" in UTC; EndTime may be "24:00".
+type AvailabilityWindow struct {
+	ID        string       `json:"id"`
+	MentorID  string       `json:"mentorId"`
+	Weekday   time.Weekday `json:"weekday"` // 0 = Sunday
+	StartTime string       `json:"startTime"`
+	EndTime   string       `json:"endTime"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+// Validate checks the weekday and that the window ends after it starts
+func (w *AvailabilityWindow) Validate() error {
+	if w.Weekday < time.Sunday || w.Weekday > time.Saturday {
+		return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6", ErrInvalidInput)
+	}
+	start, err := clockMinutes(w.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := clockMinutes(w.EndTime)
+	if err != nil {
+		return err
+	}
+	if end <= start {
+		return fmt.Errorf("%w: window must end after it starts", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Overlaps checks if two windows share any time on the same weekday
+func (w *AvailabilityWindow) Overlaps(other *AvailabilityWindow) bool {
+	return w.Weekday == other.Weekday && w.StartTime < other.EndTime && other.StartTime < w.EndTime
+}
+
+// on returns the concrete period of the window on the given UTC day
+func (w *AvailabilityWindow) on(day time.Time) TimeSlot {
+	start, _ := clockMinutes(w.StartTime)
+	end, _ := clockMinutes(w.EndTime)
+	return TimeSlot{
+		Start: day.Add(time.Duration(start) * time.Minute),
+		End:   day.Add(time.Duration(end) * time.Minute),
+	}
+}
+
+// clockMinutes parses "HH:MM" into minutes since midnight
+func clockMinutes(clock string) (int, error) {
+	if len(clock) != 5 || clock[2] != ':' {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidInput, clock)
+	}
+	hours, err := strconv.Atoi(clock[:2])
+	if err != nil {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidInput, clock)
+	}
+	minutes, err := strconv.Atoi(clock[3:])
+	if err != nil {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidInput, clock)
+	}
+	total := hours*60 + minutes
+	if hours < 0 || minutes < 0 || minutes > 59 || total > 24*60 {
+		return 0, fmt.Errorf("%w: time %q is out of range", ErrInvalidInput, clock)
+	}
+	return total, nil
+}
+
+// Absence is a period during which a mentor is out of office, such as a
+// vacation or sick leave. EndsAt is exclusive.
+type Absence struct {
+	ID        string      `json:"id"`
+	MentorID  string      `json:"mentorId"`
+	Kind      AbsenceKind `json:"kind"`
+	StartsAt  time.Time   `json:"startsAt"`
+	EndsAt    time.Time   `json:"endsAt"`
+	Note      *string     `json:"note,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// Validate checks the kind and that the absence ends after it starts
+func (a *Absence) Validate() error {
+	if !a.Kind.IsValid() {
+		return fmt.Errorf("%w: unknown absence kind %q", ErrInvalidInput, a.Kind)
+	}
+	if !a.EndsAt.After(a.StartsAt) {
+		return fmt.Errorf("%w: absence must end after it starts", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Covers checks if the mentor is away at the given moment
+func (a *Absence) Covers(t time.Time) bool {
+	return !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
+
+// AbsenceFilter narrows down absence listings
+type AbsenceFilter struct {
+	MentorID string     // empty for every mentor
+	From     *time.Time // only absences ending after From
+	To       *time.Time // only absences starting at or before To
+}
+
+// TimeSlot is a concrete period of time; End is exclusive
+type TimeSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// MentorSchedule combines a mentor's weekly windows with their absences
+type MentorSchedule struct {
+	MentorID string                `json:"mentorId"`
+	Windows  []*AvailabilityWindow `json:"windows"`
+	Absences []*Absence            `json:"absences"`
+}
+
+// AbsentAt checks if any absence covers the given moment
+func (s *MentorSchedule) AbsentAt(t time.Time) bool {
+	for _, absence := range s.Absences {
+		if absence.Covers(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// FreeSlots lists the periods in [from, to) that fall into a weekly window
+// and are not covered by an absence, in chronological order
+func (s *MentorSchedule) FreeSlots(from, to time.Time) []TimeSlot {
+	from, to = from.UTC(), to.UTC()
+	slots := make([]TimeSlot, 0)
+	for day := from.Truncate(24 * time.Hour); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, window := range s.Windows {
+			if window.Weekday != day.Weekday() {
+				continue
+			}
+			slot := window.on(day).clip(from, to)
+			if slot.Start.Before(slot.End) {
+				slots = append(slots, s.subtractAbsences(slot)...)
+			}
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Start.Before(slots[j].Start) })
+	return slots
+}
+
+// AvailableBetween checks if the mentor can be reached at some point in
+// [from, to). Without weekly windows a mentor counts as available whenever
+// they are not absent. An empty range checks the single moment from.
+func (s *MentorSchedule) AvailableBetween(from, to time.Time) bool {
+	if !to.After(from) {
+		return !s.AbsentAt(from)
+	}
+	if len(s.Windows) == 0 {
+		return len(s.subtractAbsences(TimeSlot{Start: from, End: to})) > 0
+	}
+	return len(s.FreeSlots(from, to)) > 0
+}
+
+// subtractAbsences cuts the absences out of a slot
+func (s *MentorSchedule) subtractAbsences(slot TimeSlot) []TimeSlot {
+	free := []TimeSlot{slot}
+	for _, absence := range s.Absences {
+		var rest []TimeSlot
+		for _, part := range free {
+			if !absence.StartsAt.Before(part.End) || !absence.EndsAt.After(part.Start) {
+				rest = append(rest, part)
+				continue
+			}
+			if part.Start.Before(absence.StartsAt) {
+				rest = append(rest, TimeSlot{Start: part.Start, End: absence.StartsAt})
+			}
+			if absence.EndsAt.Before(part.End) {
+				rest = append(rest, TimeSlot{Start: absence.EndsAt, End: part.End})
+			}
+		}
+		free = rest
+	}
+	return free
+}
+
+// clip limits the slot to [from, to)
+func (t TimeSlot) clip(from, to time.Time) TimeSlot {
+	if t.Start.Before(from) {
+		t.Start = from
+	}
+	if t.End.After(to) {
+		t.End = to
+	}
+	return t
+}
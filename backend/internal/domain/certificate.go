@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Certificate attests that a learner completed a learning. It keeps a copy
+// of the names and dates printed on the PDF so the verification answers
+// with what the certificate says even after the learning changes.
+type Certificate struct {
+	ID             string    `json:"id"`
+	Code           string    `json:"code"` // public verification code
+	LearningID     string    `json:"learningId"`
+	LearnerName    string    `json:"learnerName"`
+	Topic          string    `json:"topic"`
+	MentorName     string    `json:"mentorName"`
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	CompletedItems []string  `json:"completedItems"`
+	StorageKey     string    `json:"-"`
+	IssuedAt       time.Time `json:"issuedAt"`
+}
+
+// NewCertificate fills a certificate from a completed learning
+func NewCertificate(learning *LearningProcess, code string, issuedAt time.Time) (*Certificate, error) {
+	if !learning.IsCompleted() || learning.EndDate == nil {
+		return nil, ErrLearningNotCompleted
+	}
+
+	items := make([]string, 0, len(learning.Plan))
+	for _, item := range learning.Plan {
+		if item.Completed {
+			items = append(items, item.Text)
+		}
+	}
+
+	return &Certificate{
+		Code:           code,
+		LearningID:     learning.ID,
+		LearnerName:    learning.UserName,
+		Topic:          learning.RequestTopic,
+		MentorName:     learning.MentorName,
+		StartDate:      learning.StartDate,
+		EndDate:        *learning.EndDate,
+		CompletedItems: items,
+		IssuedAt:       issuedAt,
+	}, nil
+}
+
+// NormalizeCertificateCode uppercases a code typed by hand and drops the
+// surrounding spaces
+func NormalizeCertificateCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,155 @@
+package domain
+
+import "time"
+
+// CheckInRole says which side of a learning a check-in asks
+type CheckInRole string
+
+const (
+	CheckInLearner CheckInRole = "learner"
+	CheckInMentor  CheckInRole = "mentor"
+)
+
+// CheckInPulse is how a participant rates the pace of a learning
+type CheckInPulse string
+
+const (
+	PulseOnTrack CheckInPulse = "on_track"
+	PulseBehind  CheckInPulse = "behind"
+	PulseBlocked CheckInPulse = "blocked"
+)
+
+// IsValid checks if the pulse is a known answer
+func (p CheckInPulse) IsValid() bool {
+	switch p {
+	case PulseOnTrack, PulseBehind, PulseBlocked:
+		return true
+	}
+	return false
+}
+
+// CheckIn is a periodic pulse survey sent to the learner or the mentor of an
+// active learning. Round n is due n check-in intervals after the learning
+// started; each side gets at most one check-in per round.
+type CheckIn struct {
+	ID         string       `json:"id"`
+	LearningID string       `json:"learningId"`
+	UserID     string       `json:"userId"`
+	Role       CheckInRole  `json:"role"`
+	Round      int          `json:"round"`
+	Pulse      CheckInPulse `json:"pulse,omitempty"`
+	Comment    string       `json:"comment"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	AnsweredAt *time.Time   `json:"answeredAt,omitempty"`
+
+	Topic string `json:"topic"` // from JOIN with training_requests
+}
+
+// IsAnswered checks if the participant has replied
+func (c *CheckIn) IsAnswered() bool {
+	return c.AnsweredAt != nil
+}
+
+// CheckInRound returns the check-in round due at now for a learning started
+// at start; 0 means the first round is not due yet
+func CheckInRound(start, now time.Time, interval time.Duration) int {
+	if interval <= 0 || now.Before(start) {
+		return 0
+	}
+	return int(now.Sub(start) / interval)
+}
+
+// AtRiskReason explains why a learning is flagged
+type AtRiskReason string
+
+const (
+	// AtRiskNoProgress: the plan has not changed for the stall period
+	AtRiskNoProgress AtRiskReason = "no_progress"
+	// AtRiskInactive: nothing at all happened for the stall period
+	AtRiskInactive AtRiskReason = "inactive"
+	// AtRiskBehind and AtRiskBlocked: the latest answered check-in said so
+	AtRiskBehind  AtRiskReason = "behind"
+	AtRiskBlocked AtRiskReason = "blocked"
+)
+
+// AtRiskLearning is an active learning that needs attention
+type AtRiskLearning struct {
+	LearningID       string         `json:"learningId"`
+	Topic            string         `json:"topic"`
+	UserID           string         `json:"userId"`
+	UserName         string         `json:"userName"`
+	MentorID         string         `json:"mentorId"`
+	MentorName       string         `json:"mentorName"`
+	StartDate        time.Time      `json:"startDate"`
+	Progress         float64        `json:"progress"`
+	LastPlanChangeAt time.Time      `json:"lastPlanChangeAt"`
+	LastActivityAt   time.Time      `json:"lastActivityAt"`
+	IdleDays         int            `json:"idleDays"`
+	Pulse            CheckInPulse   `json:"pulse,omitempty"`
+	Reasons          []AtRiskReason `json:"reasons"`
+}
+
+// AssessRisk flags an active learning with no plan progress or no activity
+// since before stalledSince, or whose latest answered check-in reports
+// trouble. checkIns may come in any order. It returns nil for learnings on
+// track.
+func AssessRisk(lp *LearningProcess, checkIns []*CheckIn, stalledSince, now time.Time) *AtRiskLearning {
+	if !lp.IsActive() {
+		return nil
+	}
+
+	planChange := lp.LastPlanChange()
+	activity := lp.UpdatedAt
+	if planChange.After(activity) {
+		activity = planChange
+	}
+	var latest *CheckIn
+	for _, c := range checkIns {
+		if !c.IsAnswered() {
+			continue
+		}
+		if c.AnsweredAt.After(activity) {
+			activity = *c.AnsweredAt
+		}
+		if latest == nil || c.AnsweredAt.After(*latest.AnsweredAt) {
+			latest = c
+		}
+	}
+
+	var reasons []AtRiskReason
+	if planChange.Before(stalledSince) {
+		reasons = append(reasons, AtRiskNoProgress)
+	}
+	if activity.Before(stalledSince) {
+		reasons = append(reasons, AtRiskInactive)
+	}
+	var pulse CheckInPulse
+	if latest != nil {
+		pulse = latest.Pulse
+		switch pulse {
+		case PulseBehind:
+			reasons = append(reasons, AtRiskBehind)
+		case PulseBlocked:
+			reasons = append(reasons, AtRiskBlocked)
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	return &AtRiskLearning{
+		LearningID:       lp.ID,
+		Topic:            lp.RequestTopic,
+		UserID:           lp.UserID,
+		UserName:         lp.UserName,
+		MentorID:         lp.MentorID,
+		MentorName:       lp.MentorName,
+		StartDate:        lp.StartDate,
+		Progress:         lp.GetProgress(),
+		LastPlanChangeAt: planChange,
+		LastActivityAt:   activity,
+		IdleDays:         int(now.Sub(activity).Hours() / 24),
+		Pulse:            pulse,
+		Reasons:          reasons,
+	}
+}
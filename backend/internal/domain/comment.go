@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CommentTarget is the kind of record a comment thread belongs to
+type CommentTarget string
+
+const (
+	CommentOnRequest  CommentTarget = "request"
+	CommentOnLearning CommentTarget = "learning"
+)
+
+// IsValid checks if the target kind is known
+func (t CommentTarget) IsValid() bool {
+	return t == CommentOnRequest || t == CommentOnLearning
+}
+
+// CommentVisibility controls who can read a comment
+type CommentVisibility string
+
+const (
+	VisibilityPublic   CommentVisibility = "public"   // every participant of the thread
+	VisibilityInternal CommentVisibility = "internal" // admins only
+)
+
+// IsValid checks if the visibility is known
+func (v CommentVisibility) IsValid() bool {
+	return v == VisibilityPublic || v == VisibilityInternal
+}
+
+// MaxCommentLength limits the body of a comment
+const MaxCommentLength = 4000
+
+// Comment is a message in the thread of a training request or a learning
+// process. Replies point to the comment they answer through ParentID.
+type Comment struct {
+	ID         string            `json:"id"`
+	TargetType CommentTarget     `json:"targetType"`
+	TargetID   string            `json:"targetId"`
+	ParentID   *string           `json:"parentId,omitempty"`
+	AuthorID   *string           `json:"authorId"` // nil once the author's account is deleted
+	Body       string            `json:"body"`     // empty once deleted
+	Visibility CommentVisibility `json:"visibility"`
+	EditedAt   *time.Time        `json:"editedAt,omitempty"`
+	DeletedAt  *time.Time        `json:"deletedAt,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+
+	// AuthorName is joined from users; empty once the account is deleted
+	AuthorName string `json:"authorName,omitempty"`
+}
+
+// IsInternal checks if only admins can read the comment
+func (c *Comment) IsInternal() bool {
+	return c.Visibility == VisibilityInternal
+}
+
+// IsDeleted checks if the author removed the comment; it stays in the thread
+// so replies keep their parent
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// IsAuthor checks if the user wrote the comment
+func (c *Comment) IsAuthor(userID string) bool {
+	return c.AuthorID != nil && *c.AuthorID == userID
+}
+
+// mentionPattern matches "@" followed by an email address at the start of the
+// text or after a space or an opening bracket
+var mentionPattern = regexp.MustCompile(`(?:^|[\s(\[])@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// Mentions returns the email addresses mentioned in a comment body as
+// "@jane@example.com", in order of first appearance
+func Mentions(body string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		key := strings.ToLower(m[1])
+		if !seen[key] {
+			seen[key] = true
+			emails = append(emails, m[1])
+		}
+	}
+	return emails
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Skill levels run from 1 (aware) to 5 (expert); a user without a recorded
+// level for a skill counts as 0
+const (
+	MinSkillLevel = 1
+	MaxSkillLevel = 5
+)
+
+// PositiveRating is the lowest feedback rating of a completed learning that
+// raises the learner's level in the skills the request was tagged with
+const PositiveRating = 4
+
+// ValidSkillLevel checks if the level is on the competency scale
+func ValidSkillLevel(level int) bool {
+	return level >= MinSkillLevel && level <= MaxSkillLevel
+}
+
+// Skill is an entry of the competency model. Training requests and courses
+// are tagged with free-text skills; tags match skills by name, ignoring case.
+type Skill struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Validate checks the skill has a name and trims it
+func (s *Skill) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return fmt.Errorf("%w: skill name is required", ErrInvalidInput)
+	}
+	return nil
+}
+
+// SkillTarget is the level expected in a skill from everyone in a
+// department, with a job title, or with a job title in a department
+type SkillTarget struct {
+	ID         string    `json:"id"`
+	SkillID    string    `json:"skillId"`
+	Department *string   `json:"department,omitempty"`
+	JobTitle   *string   `json:"jobTitle,omitempty"`
+	Level      int       `json:"level"`
+	CreatedAt  time.Time `json:"createdAt"`
+
+	SkillName string `json:"skillName"` // from JOIN with skills
+}
+
+// Validate checks the level and that the target is scoped to a department
+// or a job title; blank scopes are dropped
+func (t *SkillTarget) Validate() error {
+	t.Department = trimmedOrNil(t.Department)
+	t.JobTitle = trimmedOrNil(t.JobTitle)
+	if t.Department == nil && t.JobTitle == nil {
+		return fmt.Errorf("%w: a target needs a department, a job title or both", ErrInvalidInput)
+	}
+	if !ValidSkillLevel(t.Level) {
+		return ErrInvalidSkillLevel
+	}
+	return nil
+}
+
+// Applies checks if the target covers the user; departments and job titles
+// are compared ignoring case
+func (t *SkillTarget) Applies(user *User) bool {
+	return sameScope(t.Department, user.Department) && sameScope(t.JobTitle, user.JobTitle)
+}
+
+// sameScope checks a user attribute against a target scope; an unset scope
+// matches everything
+func sameScope(scope, value *string) bool {
+	if scope == nil {
+		return true
+	}
+	return value != nil && strings.EqualFold(strings.TrimSpace(*value), *scope)
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// UserSkill is a user's standing in one skill. Level is the recorded level
+// gaps are measured against: the manager's assessment once there is one,
+// the user's own until then, raised by one for every positively rated
+// learning on the skill.
+type UserSkill struct {
+	UserID       string    `json:"userId"`
+	SkillID      string    `json:"skillId"`
+	SelfLevel    *int      `json:"selfLevel,omitempty"`
+	ManagerLevel *int      `json:"managerLevel,omitempty"`
+	AssessedBy   *string   `json:"assessedBy,omitempty"` // who gave the manager assessment
+	Level        int       `json:"level"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+
+	SkillName string `json:"skillName"` // from JOIN with skills
+}
+
+// AssessSelf records the user's own assessment; it only becomes the
+// recorded level while no manager has assessed the skill
+func (us *UserSkill) AssessSelf(level int) error {
+	if !ValidSkillLevel(level) {
+		return ErrInvalidSkillLevel
+	}
+	us.SelfLevel = &level
+	if us.ManagerLevel == nil {
+		us.Level = level
+	}
+	return nil
+}
+
+// AssessByManager records the manager's assessment as the recorded level
+func (us *UserSkill) AssessByManager(level int, assessorID string) error {
+	if !ValidSkillLevel(level) {
+		return ErrInvalidSkillLevel
+	}
+	us.ManagerLevel = &level
+	us.AssessedBy = &assessorID
+	us.Level = level
+	return nil
+}
+
+// RaiseLevel moves the recorded level one step up the scale; it reports
+// false when the user is already an expert
+func (us *UserSkill) RaiseLevel() bool {
+	if us.Level >= MaxSkillLevel {
+		return false
+	}
+	us.Level++
+	return true
+}
+
+// SkillGap is a user below the target level in a skill
+type SkillGap struct {
+	UserID      string  `json:"userId"`
+	UserName    string  `json:"userName"`
+	Department  *string `json:"department,omitempty"`
+	JobTitle    *string `json:"jobTitle,omitempty"`
+	SkillID     string  `json:"skillId"`
+	SkillName   string  `json:"skillName"`
+	TargetLevel int     `json:"targetLevel"`
+	Level       int     `json:"level"`
+	Gap         int     `json:"gap"`
+}
+
+// SkillGapSummary aggregates the gaps in one skill
+type SkillGapSummary struct {
+	SkillID     string  `json:"skillId"`
+	SkillName   string  `json:"skillName"`
+	Employees   int     `json:"employees"`   // users with a target in the skill
+	BelowTarget int     `json:"belowTarget"` // of them, users below it
+	AverageGap  float64 `json:"averageGap"`  // over the users below the target
+}
+
+// SkillGapReport lists the gaps of a department, or of everyone when
+// Department is nil
+type SkillGapReport struct {
+	Department *string           `json:"department,omitempty"`
+	Skills     []SkillGapSummary `json:"skills"`
+	Gaps       []SkillGap        `json:"gaps"`
+}
+
+// CleanSkills trims the skills and drops empty ones and case-insensitive
+// duplicates, keeping the first spelling; the result is never nil
+func CleanSkills(skills []string) []string {
+	clean := make([]string, 0, len(skills))
+	seen := make(map[string]bool, len(skills))
+	for _, skill := range skills {
+		skill = strings.TrimSpace(skill)
+		key := strings.ToLower(skill)
+		if skill == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		clean = append(clean, skill)
+	}
+	return clean
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CourseFormat is how a course is delivered
+type CourseFormat string
+
+const (
+	CourseOnline    CourseFormat = "online"
+	CourseClassroom CourseFormat = "classroom"
+	CourseBlended   CourseFormat = "blended"
+	CourseSelfPaced CourseFormat = "self_paced"
+)
+
+// IsValid checks if the course format is known
+func (f CourseFormat) IsValid() bool {
+	switch f {
+	case CourseOnline, CourseClassroom, CourseBlended, CourseSelfPaced:
+		return true
+	}
+	return false
+}
+
+// Course is an entry of the course catalog. Employees enroll through a
+// training request that goes through the usual approval chain; an approved
+// request takes a seat instead of waiting for a mentor.
+type Course struct {
+	ID            string       `json:"id"`
+	Title         string       `json:"title"`
+	Description   string       `json:"description"`
+	Format        CourseFormat `json:"format"`
+	DurationHours int          `json:"durationHours"`
+	Provider      string       `json:"provider"`
+	Skills        []string     `json:"skills"`
+	Capacity      *int         `json:"capacity"` // nil means unlimited seats
+
+	// Enrollment is open from EnrollmentOpensAt until EnrollmentClosesAt;
+	// either bound may be unset
+	EnrollmentOpensAt  *time.Time `json:"enrollmentOpensAt,omitempty"`
+	EnrollmentClosesAt *time.Time `json:"enrollmentClosesAt,omitempty"`
+
+	// SeatsTaken counts the enrollments that are not cancelled; it is kept
+	// by the enrollment repository, not set by clients
+	SeatsTaken int `json:"seatsTaken"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate checks the required fields, the format, the duration, the
+// capacity and the enrollment window, and tidies up the skills
+func (c *Course) Validate() error {
+	c.Title = strings.TrimSpace(c.Title)
+	if c.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidInput)
+	}
+	if !c.Format.IsValid() {
+		return fmt.Errorf("%w: unknown course format %q", ErrInvalidInput, c.Format)
+	}
+	if c.DurationHours <= 0 {
+		return fmt.Errorf("%w: duration must be a positive number of hours", ErrInvalidInput)
+	}
+	if c.Capacity != nil && *c.Capacity <= 0 {
+		return fmt.Errorf("%w: capacity must be positive; leave it empty for unlimited seats", ErrInvalidInput)
+	}
+	if c.EnrollmentOpensAt != nil && c.EnrollmentClosesAt != nil && !c.EnrollmentClosesAt.After(*c.EnrollmentOpensAt) {
+		return fmt.Errorf("%w: enrollment must close after it opens", ErrInvalidInput)
+	}
+	c.Skills = CleanSkills(c.Skills)
+	return nil
+}
+
+// EnrollmentOpen checks if the enrollment window includes the given time
+func (c *Course) EnrollmentOpen(at time.Time) bool {
+	if c.EnrollmentOpensAt != nil && at.Before(*c.EnrollmentOpensAt) {
+		return false
+	}
+	if c.EnrollmentClosesAt != nil && !at.Before(*c.EnrollmentClosesAt) {
+		return false
+	}
+	return true
+}
+
+// IsFull checks if every seat of a course with limited capacity is taken
+func (c *Course) IsFull() bool {
+	return c.Capacity != nil && c.SeatsTaken >= *c.Capacity
+}
+
+// HasSkill checks if the course teaches the skill, ignoring case
+func (c *Course) HasSkill(skill string) bool {
+	for _, s := range c.Skills {
+		if strings.EqualFold(s, skill) {
+			return true
+		}
+	}
+	return false
+}
+
+// CourseFilter narrows a catalog search; zero fields match every course
+type CourseFilter struct {
+	Query  string // case-insensitive substring of title, description or provider
+	Skill  string // case-insensitive exact skill
+	Format *CourseFormat
+	OpenAt *time.Time // only courses whose enrollment window includes this time
+}
+
+// EnrollmentStatus represents the state of a course enrollment
+type EnrollmentStatus string
+
+const (
+	EnrollmentActive    EnrollmentStatus = "enrolled"
+	EnrollmentCompleted EnrollmentStatus = "completed"
+	EnrollmentCancelled EnrollmentStatus = "cancelled"
+)
+
+// CourseEnrollment is a seat an employee holds on a course after their
+// enrollment request was approved
+type CourseEnrollment struct {
+	ID          string           `json:"id"`
+	CourseID    string           `json:"courseId"`
+	UserID      string           `json:"userId"`
+	RequestID   string           `json:"requestId"`
+	Status      EnrollmentStatus `json:"status"`
+	EnrolledAt  time.Time        `json:"enrolledAt"`
+	CompletedAt *time.Time       `json:"completedAt,omitempty"`
+	Feedback    *Feedback        `json:"feedback,omitempty"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+
+	// CourseTitle and UserName are joined from courses and users
+	CourseTitle string `json:"courseTitle"`
+	UserName    string `json:"userName"`
+}
+
+// IsActive checks if the employee still attends the course
+func (e *CourseEnrollment) IsActive() bool {
+	return e.Status == EnrollmentActive
+}
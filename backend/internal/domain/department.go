@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Department is a unit of the organisation. Departments form a tree through
+// ParentID; the head of a department administers it and its subdepartments
+// when their role is department-scoped.
+type Department struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *string   `json:"parentId,omitempty"`
+	HeadID    *string   `json:"headId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate checks the department has a name and trims it
+func (d *Department) Validate() error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" {
+		return fmt.Errorf("%w: department name is required", ErrInvalidInput)
+	}
+	if len(d.Name) > 255 {
+		return fmt.Errorf("%w: department name is too long", ErrInvalidInput)
+	}
+	return nil
+}
+
+// DepartmentSubtree returns the IDs of the given departments and of all
+// departments below them
+func DepartmentSubtree(departments []*Department, rootIDs ...string) []string {
+	children := map[string][]string{}
+	for _, d := range departments {
+		if d.ParentID != nil {
+			children[*d.ParentID] = append(children[*d.ParentID], d.ID)
+		}
+	}
+
+	var subtree []string
+	queue := slices.Clone(rootIDs)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if slices.Contains(subtree, id) {
+			continue
+		}
+		subtree = append(subtree, id)
+		queue = append(queue, children[id]...)
+	}
+	return subtree
+}
+
+// Scope is the reach of a permission: the whole organisation or the users
+// of some departments
+type Scope struct {
+	All         bool
+	Departments []string // IDs, subdepartments included
+}
+
+// IncludesDepartment checks if the department is in reach; users without a
+// department are only reached organisation-wide
+func (s Scope) IncludesDepartment(departmentID *string) bool {
+	return s.All || (departmentID != nil && slices.Contains(s.Departments, *departmentID))
+}
+
+// Includes checks if the user is in reach
+func (s Scope) Includes(user *User) bool {
+	return s.IncludesDepartment(user.DepartmentID)
+}
+
+// Within narrows the scope to the given departments
+func (s Scope) Within(departmentIDs []string) Scope {
+	within := Scope{}
+	for _, id := range departmentIDs {
+		if s.IncludesDepartment(&id) {
+			within.Departments = append(within.Departments, id)
+		}
+	}
+	return within
+}
+
+// IsEmpty checks if the scope reaches nobody
+func (s Scope) IsEmpty() bool {
+	return !s.All && len(s.Departments) == 0
+}
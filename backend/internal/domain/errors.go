@@ -5,20 +5,42 @@ import "errors"
 // Domain-level errors
 var (
 	// User errors
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUnauthorized       = errors.New("unauthorized")
-	ErrForbidden          = errors.New("forbidden: insufficient permissions")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrUserAlreadyExists    = errors.New("user already exists")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden: insufficient permissions")
+	ErrInvalidRole          = errors.New("invalid user role")
+	ErrUserDeactivated      = errors.New("user account is deactivated")
+	ErrCannotDeactivateSelf = errors.New("cannot deactivate your own account")
+	ErrManagerCycle         = errors.New("a user cannot report to themselves, directly or through other managers")
+	ErrInvalidInvitation    = errors.New("invitation is invalid, used or expired")
+	ErrRoleNotCovered       = errors.New("the user's role grants permissions yours does not")
 
 	// Mentor errors
 	ErrMentorNotFound     = errors.New("mentor not found")
 	ErrMentorNotAvailable = errors.New("mentor is not available (workload full)")
+	ErrMentorDeactivated  = errors.New("mentor is deactivated")
+	ErrMentorHasLearnings = errors.New("mentor still has active learnings; reassign them first")
+	ErrNoReplacement      = errors.New("no active mentor has capacity for every learning")
+	ErrMentorAbsent       = errors.New("mentor is out of office")
+
+	// Availability errors
+	ErrWindowNotFound  = errors.New("availability window not found")
+	ErrWindowOverlaps  = errors.New("availability window overlaps an existing one")
+	ErrAbsenceNotFound = errors.New("absence not found")
 
 	// Training request errors
 	ErrRequestNotFound        = errors.New("training request not found")
 	ErrRequestAlreadyApproved = errors.New("request already approved")
 	ErrRequestAlreadyRejected = errors.New("request already rejected")
+	ErrRequestNotPending      = errors.New("request is not pending")
+	ErrRequestNotQueued       = errors.New("request is not queued")
+	ErrRequestChanged         = errors.New("training request was changed in the meantime; reload and try again")
+
+	// Approval errors
+	ErrRequestNotAwaitingApproval = errors.New("request is not awaiting approval")
+	ErrNotApprover                = errors.New("only the employee's manager or an admin can decide on this request")
 
 	// Learning process errors
 	ErrLearningNotFound      = errors.New("learning process not found")
@@ -26,11 +48,97 @@ var (
 	ErrLearningNotActive     = errors.New("learning process is not active")
 	ErrInvalidRating         = errors.New("rating must be between 1 and 5")
 	ErrPlanItemNotFound      = errors.New("plan item not found")
+	ErrLearningNotCompleted  = errors.New("learning process is not completed")
+	ErrLearningChanged       = errors.New("learning process was changed in the meantime; reload and try again")
+
+	// Certificate errors
+	ErrCertificateNotFound = errors.New("certificate not found")
+	ErrCertificateExists   = errors.New("a certificate was already issued for this learning")
+
+	// Feedback errors
+	ErrQuestionnaireNotFound = errors.New("questionnaire not found")
+	ErrQuestionnaireInUse    = errors.New("questionnaire has answers; create a new one instead")
+	ErrNoQuestionnaire       = errors.New("no active questionnaire for this feedback")
+	ErrFeedbackExists        = errors.New("feedback was already given for this learning")
+	ErrInvalidFeedback       = errors.New("answers do not fit the questionnaire")
+
+	// Check-in errors
+	ErrCheckInNotFound = errors.New("check-in not found")
+	ErrCheckInExists   = errors.New("check-in was already sent for this round")
+	ErrCheckInAnswered = errors.New("check-in was already answered")
+
+	// Job errors
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobExists         = errors.New("a job with this unique key is already pending or running")
+	ErrJobNotRetryable   = errors.New("only failed or cancelled jobs can be retried")
+	ErrJobNotCancellable = errors.New("only pending jobs can be cancelled")
+	ErrJobLeaseLost      = errors.New("job is no longer held by this worker")
+
+	// Webhook errors
+	ErrWebhookNotFound      = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrDeliveryNotRetryable = errors.New("only dead deliveries can be redelivered")
+
+	// Provisioning errors
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("a group with this name already exists")
+	ErrNoHRIS        = errors.New("no HRIS export is configured for the tenant")
+
+	// Role errors
+	ErrRoleNotFound  = errors.New("role not found")
+	ErrRoleExists    = errors.New("a role with this name already exists")
+	ErrRoleInUse     = errors.New("role is assigned to users")
+	ErrRoleProtected = errors.New("built-in roles cannot be deleted and the admin role cannot be changed")
+	ErrLastAdmin     = errors.New("at least one active user must keep the roles.manage permission")
+
+	// Department errors
+	ErrDepartmentNotFound = errors.New("department not found")
+	ErrDepartmentExists   = errors.New("a department with this name already exists")
+	ErrDepartmentInUse    = errors.New("department has members or subdepartments")
+	ErrDepartmentCycle    = errors.New("a department cannot be placed below itself")
+
+	// Tenant errors
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrTenantExists   = errors.New("a tenant with this slug or host already exists")
+	ErrTenantMismatch = errors.New("token belongs to another organisation")
+
+	// Course errors
+	ErrCourseNotFound      = errors.New("course not found")
+	ErrCourseInUse         = errors.New("course has enrollment requests and cannot be deleted")
+	ErrCourseFull          = errors.New("course has no seats left")
+	ErrEnrollmentClosed    = errors.New("enrollment for this course is closed")
+	ErrAlreadyEnrolled     = errors.New("already enrolled or waiting for approval on this course")
+	ErrEnrollmentNotFound  = errors.New("enrollment not found")
+	ErrEnrollmentNotActive = errors.New("enrollment is not active")
+	ErrCourseRequest       = errors.New("course enrollment requests get a seat, not a mentor")
+
+	// Competency errors
+	ErrSkillNotFound       = errors.New("skill not found")
+	ErrSkillExists         = errors.New("a skill with this name already exists")
+	ErrSkillTargetNotFound = errors.New("skill target not found")
+	ErrSkillTargetExists   = errors.New("a target for this skill, department and job title already exists")
+	ErrInvalidSkillLevel   = errors.New("skill level must be between 1 and 5")
+
+	// Comment errors
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrNotParticipant   = errors.New("only participants of the thread can read or write comments")
+	ErrNotCommentAuthor = errors.New("only the author can change a comment")
+	ErrCommentDeleted   = errors.New("comment is deleted")
+
+	// Attachment errors
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrFileTypeNotAllowed = errors.New("file type is not allowed")
+	ErrFileInfected       = errors.New("file was rejected by the virus scanner")
+	ErrBlobNotFound       = errors.New("file content not found")
+
+	// Notification errors
+	ErrNotificationNotFound = errors.New("notification not found")
 
 	// Validation errors
 	ErrInvalidInput    = errors.New("invalid input data")
 	ErrEmptyField      = errors.New("required field is empty")
 	ErrInvalidEmail    = errors.New("invalid email format")
 	ErrWeakPassword    = errors.New("password must be at least 8 characters")
-	ErrInvalidWorkload = errors.New("workload must be between 0 and 5")
+	ErrInvalidWorkload = errors.New("workload must be between 0 and the mentor's capacity")
 )
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FeedbackAudience says who answers a questionnaire after a learning
+type FeedbackAudience string
+
+const (
+	FeedbackFromLearner FeedbackAudience = "learner" // the learner on the mentor
+	FeedbackFromMentor  FeedbackAudience = "mentor"  // the mentor on the learner
+)
+
+// IsValid checks if the audience is known
+func (a FeedbackAudience) IsValid() bool {
+	switch a {
+	case FeedbackFromLearner, FeedbackFromMentor:
+		return true
+	}
+	return false
+}
+
+// Criterion scales stay within 0 (as for a recommend score) and 10
+const (
+	MinCriterionScore = 0
+	MaxCriterionScore = 10
+)
+
+var criterionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// FeedbackCriterion is one question of a questionnaire, answered with a
+// score between Min and Max
+type FeedbackCriterion struct {
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Min      int    `json:"min"`
+	Max      int    `json:"max"`
+	Required bool   `json:"required"`
+}
+
+// Questionnaire is a set of criteria admins manage for one audience. The
+// active questionnaire of an audience is the one new feedback answers;
+// answered questionnaires are kept unchanged so old answers keep their
+// meaning.
+type Questionnaire struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Audience  FeedbackAudience    `json:"audience"`
+	Criteria  []FeedbackCriterion `json:"criteria"`
+	Active    bool                `json:"active"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+// DefaultQuestionnaires returns the active questionnaires of a new tenant,
+// as seeded by the migrations for the default tenant
+func DefaultQuestionnaires() []*Questionnaire {
+	return []*Questionnaire{
+		{
+			Name:     "Feedback on the mentor",
+			Audience: FeedbackFromLearner,
+			Criteria: []FeedbackCriterion{
+				{Key: "clarity", Label: "How clearly did the mentor explain things?", Min: 1, Max: 5, Required: true},
+				{Key: "availability", Label: "How available was the mentor?", Min: 1, Max: 5, Required: true},
+				{Key: "usefulness", Label: "How useful was the learning for your work?", Min: 1, Max: 5, Required: true},
+				{Key: "recommend", Label: "How likely are you to recommend this mentor to a colleague?", Min: 0, Max: 10, Required: true},
+			},
+		},
+		{
+			Name:     "Assessment of the learner",
+			Audience: FeedbackFromMentor,
+			Criteria: []FeedbackCriterion{
+				{Key: "engagement", Label: "How engaged was the learner?", Min: 1, Max: 5, Required: true},
+				{Key: "skill_growth", Label: "How much did the learner's skills grow?", Min: 1, Max: 5, Required: true},
+			},
+		},
+	}
+}
+
+// Validate checks the name, the audience and the criteria, trimming the
+// texts
+func (q *Questionnaire) Validate() error {
+	q.Name = strings.TrimSpace(q.Name)
+	if q.Name == "" {
+		return fmt.Errorf("%w: questionnaire name is required", ErrInvalidInput)
+	}
+	if !q.Audience.IsValid() {
+		return fmt.Errorf("%w: unknown audience %q", ErrInvalidInput, q.Audience)
+	}
+	if len(q.Criteria) == 0 {
+		return fmt.Errorf("%w: a questionnaire needs at least one criterion", ErrInvalidInput)
+	}
+
+	seen := make(map[string]bool, len(q.Criteria))
+	for i := range q.Criteria {
+		c := &q.Criteria[i]
+		c.Key = strings.TrimSpace(c.Key)
+		c.Label = strings.TrimSpace(c.Label)
+		if !criterionKeyPattern.MatchString(c.Key) {
+			return fmt.Errorf("%w: criterion key %q must be lowercase letters, digits and underscores", ErrInvalidInput, c.Key)
+		}
+		if seen[c.Key] {
+			return fmt.Errorf("%w: criterion %q appears twice", ErrInvalidInput, c.Key)
+		}
+		seen[c.Key] = true
+		if c.Label == "" {
+			return fmt.Errorf("%w: criterion %q needs a label", ErrInvalidInput, c.Key)
+		}
+		if c.Min < MinCriterionScore || c.Max > MaxCriterionScore || c.Min >= c.Max {
+			return fmt.Errorf("%w: criterion %q must have a scale within %d and %d", ErrInvalidInput, c.Key, MinCriterionScore, MaxCriterionScore)
+		}
+	}
+	return nil
+}
+
+// Check validates answers against the criteria: every required criterion
+// is answered, nothing else is, and scores are on the criterion's scale
+func (q *Questionnaire) Check(answers map[string]int) error {
+	known := make(map[string]bool, len(q.Criteria))
+	for _, c := range q.Criteria {
+		known[c.Key] = true
+		score, ok := answers[c.Key]
+		if !ok {
+			if c.Required {
+				return fmt.Errorf("%w: %q is required", ErrInvalidFeedback, c.Key)
+			}
+			continue
+		}
+		if score < c.Min || score > c.Max {
+			return fmt.Errorf("%w: %q must be between %d and %d", ErrInvalidFeedback, c.Key, c.Min, c.Max)
+		}
+	}
+	for key := range answers {
+		if !known[key] {
+			return fmt.Errorf("%w: unknown criterion %q", ErrInvalidFeedback, key)
+		}
+	}
+	return nil
+}
+
+// FeedbackResponse is a filled-in questionnaire about a completed learning:
+// the learner's feedback on the mentor or the mentor's assessment of the
+// learner. A learner may stay anonymous toward the mentor.
+type FeedbackResponse struct {
+	ID              string           `json:"id"`
+	LearningID      string           `json:"learningId"`
+	QuestionnaireID string           `json:"questionnaireId"`
+	Audience        FeedbackAudience `json:"audience"`
+	AuthorID        *string          `json:"authorId"` // null once the author's account is deleted
+	MentorID        string           `json:"mentorId"` // the mentor at the time of the feedback
+	Answers         map[string]int   `json:"answers"`
+	Comment         string           `json:"comment"`
+	Anonymous       bool             `json:"anonymous"`
+	CreatedAt       time.Time        `json:"createdAt"`
+
+	AuthorName string `json:"authorName,omitempty"` // from JOIN with users
+}
+
+// LearningFeedback is what a participant sees of the feedback on a
+// learning, with the questionnaire they still have to answer
+type LearningFeedback struct {
+	Responses []*FeedbackResponse `json:"responses"`
+	Pending   *Questionnaire      `json:"pending"`
+}
+
+// CriterionSummary averages the answers to one criterion
+type CriterionSummary struct {
+	Key       string  `json:"key"`
+	Label     string  `json:"label"`
+	Min       int     `json:"min"`
+	Max       int     `json:"max"`
+	Responses int     `json:"responses"`
+	Average   float64 `json:"average"`
+}
+
+// MentorFeedbackSummary aggregates what learners said about a mentor:
+// the overall completion ratings and the questionnaire answers per
+// criterion. Comments are listed newest first without their authors.
+type MentorFeedbackSummary struct {
+	MentorID       string             `json:"mentorId"`
+	RatedLearnings int                `json:"ratedLearnings"`
+	AverageRating  float64            `json:"averageRating"`
+	Responses      int                `json:"responses"`
+	Criteria       []CriterionSummary `json:"criteria"`
+	Comments       []string           `json:"comments"`
+}
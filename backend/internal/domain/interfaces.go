@@ -1,6 +1,16 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// TxManager runs a function atomically; repository calls made with the
+// context passed to fn take part in the same transaction
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
 
 // UserRepository defines methods for user data access
 type UserRepository interface {
@@ -9,29 +19,98 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetAll(ctx context.Context) ([]*User, error)
 	Update(ctx context.Context, user *User) error
+	UpdateDeactivatedAt(ctx context.Context, id string, deactivatedAt *time.Time) error
+	UpdateManager(ctx context.Context, id string, managerID *string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// RoleRepository defines methods for role data access
+type RoleRepository interface {
+	Create(ctx context.Context, role *Role) error
+	GetByName(ctx context.Context, name UserRole) (*Role, error)
+	GetAll(ctx context.Context) ([]*Role, error)
+	Update(ctx context.Context, role *Role) error
+	Delete(ctx context.Context, name UserRole) error
+}
+
+// DepartmentRepository defines methods for department data access
+type DepartmentRepository interface {
+	Create(ctx context.Context, department *Department) error
+	GetByID(ctx context.Context, id string) (*Department, error)
+	GetByName(ctx context.Context, name string) (*Department, error) // ignoring case
+	GetAll(ctx context.Context) ([]*Department, error)
+	Update(ctx context.Context, department *Department) error
 	Delete(ctx context.Context, id string) error
 }
 
+// TenantRepository defines methods for tenant data access. Tenants are
+// looked up across the deployment, whatever the tenant in the context.
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *Tenant) error
+	GetByID(ctx context.Context, id string) (*Tenant, error)
+	// GetByIDForUpdate retrieves a tenant and locks it until the transaction
+	// ends, so concurrent changes to its settings do not overwrite each other
+	GetByIDForUpdate(ctx context.Context, id string) (*Tenant, error)
+	GetByHost(ctx context.Context, host string) (*Tenant, error)
+	GetBySCIMTokenHash(ctx context.Context, hash string) (*Tenant, error)
+	GetAll(ctx context.Context) ([]*Tenant, error)
+	Update(ctx context.Context, tenant *Tenant) error
+}
+
 // RequestRepository defines methods for training request data access
 type RequestRepository interface {
 	Create(ctx context.Context, request *TrainingRequest) error
 	GetByID(ctx context.Context, id string) (*TrainingRequest, error)
 	GetByUserID(ctx context.Context, userID string) ([]*TrainingRequest, error)
 	GetAll(ctx context.Context, status *string) ([]*TrainingRequest, error)
+	GetByManagerID(ctx context.Context, managerID string, status *string) ([]*TrainingRequest, error)
 	Update(ctx context.Context, request *TrainingRequest) error
-	UpdateStatus(ctx context.Context, id, status string) error
+	// UpdateStatus moves a request that still has the status from to the
+	// status to and fails with ErrRequestChanged otherwise, so concurrent
+	// decisions and assignments cannot both apply
+	UpdateStatus(ctx context.Context, id string, from, to RequestStatus) error
+	// Enqueue puts a request that still has the status from in the queue
+	// with the priority and fails with ErrRequestChanged otherwise
+	Enqueue(ctx context.Context, id string, from RequestStatus, priority int) error
+	GetQueue(ctx context.Context) ([]*TrainingRequest, error)
+}
+
+// ApprovalRepository defines methods for approval decision data access
+type ApprovalRepository interface {
+	Create(ctx context.Context, decision *ApprovalDecision) error
+	GetByRequestID(ctx context.Context, requestID string) ([]*ApprovalDecision, error)
 }
 
 // MentorRepository defines methods for mentor data access
 type MentorRepository interface {
 	Create(ctx context.Context, mentor *Mentor) error
 	GetByID(ctx context.Context, id string) (*Mentor, error)
-	GetAll(ctx context.Context, maxWorkload *int) ([]*Mentor, error)
+	GetAll(ctx context.Context, filter MentorFilter) ([]*Mentor, error)
 	Update(ctx context.Context, mentor *Mentor) error
 	UpdateWorkload(ctx context.Context, id string, workload int) error
+	// IncrementWorkload takes n slots of a mentor in one statement and fails
+	// with ErrMentorNotAvailable rather than going over the capacity, so
+	// concurrent assignments cannot overbook a mentor
+	IncrementWorkload(ctx context.Context, id string, n int) error
+	// DecrementWorkload frees n slots of a mentor in one statement, stopping
+	// at zero
+	DecrementWorkload(ctx context.Context, id string, n int) error
+	UpdateDeactivatedAt(ctx context.Context, id string, deactivatedAt *time.Time) error
 	Delete(ctx context.Context, id string) error
 }
 
+// AvailabilityRepository defines methods for mentor availability data access
+type AvailabilityRepository interface {
+	CreateWindow(ctx context.Context, window *AvailabilityWindow) error
+	GetWindows(ctx context.Context, mentorID string) ([]*AvailabilityWindow, error)
+	UpdateWindow(ctx context.Context, window *AvailabilityWindow) error
+	DeleteWindow(ctx context.Context, id, mentorID string) error
+	CreateAbsence(ctx context.Context, absence *Absence) error
+	GetAbsences(ctx context.Context, filter AbsenceFilter) ([]*Absence, error)
+	UpdateAbsence(ctx context.Context, absence *Absence) error
+	DeleteAbsence(ctx context.Context, id, mentorID string) error
+}
+
 // LearningRepository defines methods for learning process data access
 type LearningRepository interface {
 	Create(ctx context.Context, learning *LearningProcess) error
@@ -41,7 +120,219 @@ type LearningRepository interface {
 	GetAll(ctx context.Context) ([]*LearningProcess, error)
 	UpdatePlan(ctx context.Context, id string, plan []LearningPlanItem) error
 	UpdateNotes(ctx context.Context, id string, notes string) error
-	Update(ctx context.Context, id string, learning *LearningProcess) error
+	// Update changes a learning that still has the status from and fails
+	// with ErrLearningChanged otherwise, so concurrent status changes cannot
+	// both adjust the mentor's workload
+	Update(ctx context.Context, id string, from LearningStatus, learning *LearningProcess) error
+	// Complete completes an active learning and fails with
+	// ErrLearningNotActive otherwise
+	Complete(ctx context.Context, id string, feedback Feedback) error
+	// UpdateMentor moves a learning from one mentor to another and fails
+	// with ErrLearningChanged when it is no longer with from
+	UpdateMentor(ctx context.Context, learningID, from, to string) error
+}
+
+// CourseRepository defines methods for course catalog data access
+type CourseRepository interface {
+	Create(ctx context.Context, course *Course) error
+	GetByID(ctx context.Context, id string) (*Course, error)
+	Search(ctx context.Context, filter CourseFilter) ([]*Course, error)
+	Update(ctx context.Context, course *Course) error
+	Delete(ctx context.Context, id string) error
+}
+
+// EnrollmentRepository defines methods for course enrollment data access.
+// Create takes a seat and fails with ErrCourseFull when none is left; Cancel
+// gives it back.
+type EnrollmentRepository interface {
+	Create(ctx context.Context, enrollment *CourseEnrollment) error
+	GetByID(ctx context.Context, id string) (*CourseEnrollment, error)
+	GetByUserID(ctx context.Context, userID string) ([]*CourseEnrollment, error)
+	GetByCourseID(ctx context.Context, courseID string) ([]*CourseEnrollment, error)
 	Complete(ctx context.Context, id string, feedback Feedback) error
-	UpdateMentor(ctx context.Context, learningID, mentorID string) error
+	Cancel(ctx context.Context, id string) error
+}
+
+// SkillRepository defines methods for competency model data access; skill
+// names are unique ignoring case
+type SkillRepository interface {
+	Create(ctx context.Context, skill *Skill) error
+	GetByID(ctx context.Context, id string) (*Skill, error)
+	GetAll(ctx context.Context) ([]*Skill, error)
+	Update(ctx context.Context, skill *Skill) error
+	Delete(ctx context.Context, id string) error
+}
+
+// CompetencyRepository defines methods for skill targets and user skill
+// levels; deleting a skill removes both
+type CompetencyRepository interface {
+	CreateTarget(ctx context.Context, target *SkillTarget) error
+	GetTargets(ctx context.Context) ([]*SkillTarget, error)
+	DeleteTarget(ctx context.Context, id string) error
+	GetUserSkills(ctx context.Context, userID string) ([]*UserSkill, error)
+	GetAllUserSkills(ctx context.Context) ([]*UserSkill, error)
+	SaveUserSkill(ctx context.Context, userSkill *UserSkill) error
+}
+
+// CommentRepository defines methods for comment data access
+type CommentRepository interface {
+	Create(ctx context.Context, comment *Comment) error
+	GetByID(ctx context.Context, id string) (*Comment, error)
+	GetByTarget(ctx context.Context, target CommentTarget, targetID string, includeInternal bool) ([]*Comment, error)
+	UpdateBody(ctx context.Context, id, body string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// AttachmentRepository defines methods for attachment metadata access
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *Attachment) error
+	GetByID(ctx context.Context, id string) (*Attachment, error)
+	GetByLearningID(ctx context.Context, learningID string) ([]*Attachment, error)
+	GetByCommentID(ctx context.Context, commentID string) ([]*Attachment, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// BlobStore keeps the content of uploaded files under opaque keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// VirusScanner inspects uploaded content before it is stored; Scan returns
+// an error wrapping ErrFileInfected to reject a file
+type VirusScanner interface {
+	Scan(ctx context.Context, fileName string, content []byte) error
+}
+
+// Mailer sends plain-text emails
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// CertificateRepository defines methods for certificate data access
+type CertificateRepository interface {
+	Create(ctx context.Context, certificate *Certificate) error
+	GetByLearningID(ctx context.Context, learningID string) (*Certificate, error)
+	GetByCode(ctx context.Context, code string) (*Certificate, error)
+}
+
+// CertificateRenderer lays a certificate out as a PDF document
+type CertificateRenderer interface {
+	Render(certificate *Certificate) ([]byte, error)
+}
+
+// QuestionnaireRepository defines methods for feedback questionnaire data
+// access
+type QuestionnaireRepository interface {
+	Create(ctx context.Context, questionnaire *Questionnaire) error
+	GetByID(ctx context.Context, id string) (*Questionnaire, error)
+	GetAll(ctx context.Context) ([]*Questionnaire, error)
+	GetActive(ctx context.Context, audience FeedbackAudience) (*Questionnaire, error)
+	Update(ctx context.Context, questionnaire *Questionnaire) error
+	Delete(ctx context.Context, id string) error
+	Activate(ctx context.Context, id string) error
+}
+
+// FeedbackRepository defines methods for feedback response data access
+type FeedbackRepository interface {
+	Create(ctx context.Context, response *FeedbackResponse) error
+	GetByLearningID(ctx context.Context, learningID string) ([]*FeedbackResponse, error)
+	GetByMentorID(ctx context.Context, mentorID string) ([]*FeedbackResponse, error)
+}
+
+// CheckInRepository defines methods for check-in data access. Create fails
+// with ErrCheckInExists when the side already has a check-in for the round,
+// so concurrent schedulers send each check-in once.
+type CheckInRepository interface {
+	Create(ctx context.Context, checkIn *CheckIn) error
+	GetByID(ctx context.Context, id string) (*CheckIn, error)
+	GetByLearningID(ctx context.Context, learningID string) ([]*CheckIn, error)
+	GetPending(ctx context.Context, userID string) ([]*CheckIn, error)
+	Answer(ctx context.Context, id string, pulse CheckInPulse, comment string) error
+	// ClaimStallAlert records that the mentor was told about a stalled
+	// learning. It reports false when they were already told after since.
+	ClaimStallAlert(ctx context.Context, learningID string, since time.Time) (bool, error)
+}
+
+// JobRepository defines methods for background job data access. Claim
+// hands each due job to one worker even when several app instances poll the
+// same table.
+type JobRepository interface {
+	// Enqueue inserts a pending job; it fails with ErrJobExists when a job
+	// with the same unique key is pending or running
+	Enqueue(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, id string) (*Job, error)
+	List(ctx context.Context, filter JobFilter) ([]*Job, error)
+	// LatestByKind returns the newest job of the kind in each tenant that
+	// has one; like Claim, it serves every tenant
+	LatestByKind(ctx context.Context, kind string) ([]*Job, error)
+	// Claim locks the next due job of one of the kinds for the worker until
+	// now+lease, counting an attempt. Jobs whose lease expired are claimed
+	// again while they have attempts left and fail otherwise. It returns nil
+	// when no job is due.
+	Claim(ctx context.Context, workerID string, kinds []string, now time.Time, lease time.Duration) (*Job, error)
+	// Complete marks a claimed job as succeeded
+	Complete(ctx context.Context, id, workerID string, now time.Time) error
+	// Fail records a failed attempt: the job runs again at retryAt, or fails
+	// for good when retryAt is nil
+	Fail(ctx context.Context, id, workerID, lastError string, now time.Time, retryAt *time.Time) error
+	// Retry makes a failed or cancelled job pending again with fresh attempts
+	Retry(ctx context.Context, id string, now time.Time) error
+	// Cancel stops a pending job from running
+	Cancel(ctx context.Context, id string, now time.Time) error
+	// DeleteFinished removes jobs finished before the time
+	DeleteFinished(ctx context.Context, before time.Time) (int, error)
+	// ClaimSchedule advances a recurring schedule from a due fire time to
+	// next. It reports false when the schedule is not due or another instance
+	// advanced it first. A schedule seen for the first time starts at next.
+	ClaimSchedule(ctx context.Context, name string, now, next time.Time) (bool, error)
+}
+
+// OutboxRepository defines methods for the transactional outbox. Append
+// joins the caller's transaction so the event commits with the change.
+type OutboxRepository interface {
+	Append(ctx context.Context, event *OutboxEvent) error
+	// ListUndispatched returns up to limit events not yet fanned out to
+	// subscriptions, oldest first. Inside a transaction the events stay
+	// locked until it ends; events locked by others are skipped.
+	ListUndispatched(ctx context.Context, limit int) ([]*OutboxEvent, error)
+	MarkDispatched(ctx context.Context, id string, now time.Time) error
+	// DeleteDispatched removes events dispatched before the time once all
+	// their deliveries are delivered, together with those deliveries
+	DeleteDispatched(ctx context.Context, before time.Time) (int, error)
+}
+
+// WebhookRepository defines methods for webhook subscription and delivery
+// data access
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *WebhookSubscription) error
+	GetSubscription(ctx context.Context, id string) (*WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *WebhookSubscription) error
+	// DeleteSubscription removes a subscription with its deliveries
+	DeleteSubscription(ctx context.Context, id string) error
+
+	CreateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (*WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*WebhookDelivery, error)
+	// ClaimDeliveries takes up to limit due pending deliveries of active
+	// subscriptions, counting an attempt and pushing their next attempt to
+	// now+lease, so other instances leave them alone while they are sent and
+	// pick them up again if this one crashes
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error)
+	// MarkDelivered records the successful attempt
+	MarkDelivered(ctx context.Context, id string, statusCode int, now time.Time) error
+	// MarkFailed records a failed attempt: the delivery is tried again at
+	// retryAt, or is dead when retryAt is nil
+	MarkFailed(ctx context.Context, id string, statusCode *int, lastError string, retryAt *time.Time) error
+	// Redeliver makes a dead delivery pending again with fresh attempts
+	Redeliver(ctx context.Context, id string, now time.Time) error
+}
+
+// NotificationRepository defines methods for notification data access
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *Notification) error
+	GetByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*Notification, error)
+	MarkRead(ctx context.Context, id, userID string) error
 }
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobStatus is the state of a background job
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // waiting for runAt
+	JobRunning   JobStatus = "running"   // held by a worker until lockedUntil
+	JobSucceeded JobStatus = "succeeded" // finished
+	JobFailed    JobStatus = "failed"    // gave up after maxAttempts
+	JobCancelled JobStatus = "cancelled" // cancelled by an admin
+)
+
+// IsValid checks if the status is a known job state
+func (s JobStatus) IsValid() bool {
+	switch s {
+	case JobPending, JobRunning, JobSucceeded, JobFailed, JobCancelled:
+		return true
+	}
+	return false
+}
+
+// IsFinished reports whether the job will not run again unless retried
+func (s JobStatus) IsFinished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Job is a unit of deferred or periodic work. A worker claims a due job
+// for a lease; if it crashes, the job becomes claimable again once the lease
+// expires, so handlers must tolerate running more than once. Jobs sharing a
+// unique key are never pending or running at the same time.
+type Job struct {
+	ID          string          `json:"id"`
+	TenantID    string          `json:"-"` // the handler runs in the tenant
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	UniqueKey   string          `json:"uniqueKey,omitempty"`
+	Status      JobStatus       `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	RunAt       time.Time       `json:"runAt"`
+	LockedBy    string          `json:"lockedBy,omitempty"`
+	LockedUntil *time.Time      `json:"lockedUntil,omitempty"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	StartedAt   *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// JobFilter narrows the admin job list; zero fields match everything
+type JobFilter struct {
+	Status JobStatus
+	Kind   string
+	Limit  int
+}
+
+const (
+	// DefaultJobMaxAttempts applies when a job is enqueued without a limit
+	DefaultJobMaxAttempts = 5
+	// DefaultJobListLimit caps the admin job list
+	DefaultJobListLimit = 100
+	// MaxJobErrorLength caps the stored error of a failed attempt
+	MaxJobErrorLength = 2000
+
+	jobBaseBackoff = 30 * time.Second
+	jobMaxBackoff  = time.Hour
+)
+
+// JobBackoff returns the delay before retrying after the given failed
+// attempt: 30s, 1m, 2m, ... doubling up to an hour
+func JobBackoff(attempt int) time.Duration {
+	delay := jobBaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= jobMaxBackoff {
+			return jobMaxBackoff
+		}
+	}
+	return delay
+}
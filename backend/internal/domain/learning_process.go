@@ -13,6 +13,15 @@ const (
 	LearningCompleted LearningStatus = "completed"
 )
 
+// IsValid checks if the status is a known learning status
+func (s LearningStatus) IsValid() bool {
+	switch s {
+	case LearningActive, LearningCompleted:
+		return true
+	}
+	return false
+}
+
 // Feedback represents feedback for completed learning
 type Feedback struct {
 	Rating  int    `json:"rating"`
@@ -33,6 +42,9 @@ type LearningProcess struct {
 	Notes     *string            `json:"notes,omitempty"`
 	CreatedAt time.Time          `json:"createdAt"`
 	UpdatedAt time.Time          `json:"updatedAt"`
+	// PlanUpdatedAt is when the plan last changed; nil for learnings whose
+	// plan has not changed since this was tracked
+	PlanUpdatedAt *time.Time `json:"planUpdatedAt,omitempty"`
 
 	RequestTopic       string  `json:"-"`
 	RequestDescription string  `json:"-"`
@@ -156,6 +168,15 @@ func (lp *LearningProcess) GetCompletedItemsCount() int {
 	return count
 }
 
+// LastPlanChange returns when the plan last changed; learnings whose plan
+// has not changed since plan changes were tracked fall back to updatedAt
+func (lp *LearningProcess) LastPlanChange() time.Time {
+	if lp.PlanUpdatedAt != nil {
+		return *lp.PlanUpdatedAt
+	}
+	return lp.UpdatedAt
+}
+
 // Validate feedback
 func (f *Feedback) Validate() error {
 	if f.Rating < 1 || f.Rating > 5 {
@@ -1,33 +1,81 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Mentor represents a training mentor in the system
 type Mentor struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	JobTitle   string    `json:"jobTitle"`
-	Experience *string   `json:"experience,omitempty"`
-	Workload   int       `json:"workload"` // 0-5 scale
-	Email      string    `json:"email"`
-	Telegram   *string   `json:"telegram,omitempty"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	JobTitle      string     `json:"jobTitle"`
+	Experience    *string    `json:"experience,omitempty"`
+	Workload      int        `json:"workload"` // active learnings, up to Capacity
+	Capacity      int        `json:"capacity"`
+	Email         string     `json:"email"`
+	Telegram      *string    `json:"telegram,omitempty"`
+	Skills        []string   `json:"skills"` // what the mentor teaches; none means any subject
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// Mentor capacity limits
+const (
+	DefaultMentorCapacity = 5
+	MaxMentorCapacity     = 20
+)
+
+// MentorFilter narrows down mentor listings
+type MentorFilter struct {
+	MaxWorkload *int // only mentors with workload <= MaxWorkload
+	ActiveOnly  bool // skip deactivated mentors
+	HasCapacity bool // only mentors below their capacity
+}
+
+// IsActive checks if mentor has not been deactivated
+func (m *Mentor) IsActive() bool {
+	return m.DeactivatedAt == nil
 }
 
 // IsAvailable checks if mentor has capacity for new students
 func (m *Mentor) IsAvailable() bool {
-	return m.Workload < 5
+	return m.Workload < m.Capacity
 }
 
 // CanTakeStudent checks if mentor can accept one more student
 func (m *Mentor) CanTakeStudent() bool {
-	return m.Workload <= 4
+	return m.Workload < m.Capacity
+}
+
+// HasSkill checks if the mentor teaches the skill, ignoring case
+func (m *Mentor) HasSkill(skill string) bool {
+	for _, s := range m.Skills {
+		if strings.EqualFold(s, skill) {
+			return true
+		}
+	}
+	return false
+}
+
+// Teaches checks if the mentor covers the topic or one of the skill tags of
+// a request
+func (m *Mentor) Teaches(request *TrainingRequest) bool {
+	if m.HasSkill(request.Topic) {
+		return true
+	}
+	for _, skill := range request.Skills {
+		if m.HasSkill(skill) {
+			return true
+		}
+	}
+	return false
 }
 
 // IncrementWorkload increases mentor's workload by 1
 func (m *Mentor) IncrementWorkload() {
-	if m.Workload < 5 {
+	if m.Workload < m.Capacity {
 		m.Workload++
 	}
 }
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// NotificationKind identifies what a notification is about
+type NotificationKind string
+
+const (
+	NotificationMentorChanged   NotificationKind = "mentor_changed"
+	NotificationRequestAssigned NotificationKind = "request_assigned"
+	NotificationApprovalNeeded  NotificationKind = "approval_needed"
+	NotificationRequestDecided  NotificationKind = "request_decided"
+	NotificationMentioned       NotificationKind = "mentioned"
+	NotificationCheckInDue      NotificationKind = "check_in_due"
+	NotificationLearningStalled NotificationKind = "learning_stalled"
+)
+
+// Notification is an in-app message for a user
+type Notification struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"userId"`
+	Kind      NotificationKind `json:"kind"`
+	Title     string           `json:"title"`
+	Body      string           `json:"body"`
+	ReadAt    *time.Time       `json:"readAt,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// IsRead checks if the user has seen the notification
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
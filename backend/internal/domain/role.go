@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Permission is a named capability that roles grant
+type Permission string
+
+const (
+	PermUsersView         Permission = "users.view"          // read any user profile and history
+	PermUsersManage       Permission = "users.manage"        // edit, deactivate and import users
+	PermRolesManage       Permission = "roles.manage"        // define roles and assign them
+	PermRequestsView      Permission = "requests.view"       // read any training request
+	PermRequestsApprove   Permission = "requests.approve"    // decide the L&D approval stage
+	PermRequestsAssign    Permission = "requests.assign"     // assign mentors, run the queue, edit others' requests
+	PermLearningsView     Permission = "learnings.view"      // read any learning and its check-ins
+	PermLearningsManage   Permission = "learnings.manage"    // update, reassign and complete learnings
+	PermLearningsEditPlan Permission = "learnings.edit_plan" // edit plans and notes of any learning
+	PermMentorsManage     Permission = "mentors.manage"      // mentor records, availability and handoffs
+	PermCoursesManage     Permission = "courses.manage"      // catalog and enrollments
+	PermSkillsManage      Permission = "skills.manage"       // skills, targets and assessments of others
+	PermCommentsInternal  Permission = "comments.internal"   // read and post internal comments
+	PermCommentsModerate  Permission = "comments.moderate"   // join any thread and delete others' posts
+	PermFeedbackView      Permission = "feedback.view"       // read all feedback, anonymous included
+	PermFeedbackManage    Permission = "feedback.manage"     // questionnaires
+	PermAnalyticsView     Permission = "analytics.view"      // skill gaps and at-risk learnings
+	PermJobsManage        Permission = "jobs.manage"         // background jobs
+	PermWebhooksManage    Permission = "webhooks.manage"     // webhook subscriptions and deliveries
+	PermDepartmentsManage Permission = "departments.manage"  // departments and their heads
+	PermSettingsManage    Permission = "settings.manage"     // organisation settings and branding
+)
+
+// Permissions lists every known permission
+var Permissions = []Permission{
+	PermUsersView, PermUsersManage, PermRolesManage,
+	PermRequestsView, PermRequestsApprove, PermRequestsAssign,
+	PermLearningsView, PermLearningsManage, PermLearningsEditPlan,
+	PermMentorsManage, PermCoursesManage, PermSkillsManage,
+	PermCommentsInternal, PermCommentsModerate,
+	PermFeedbackView, PermFeedbackManage,
+	PermAnalyticsView, PermJobsManage, PermWebhooksManage,
+	PermDepartmentsManage, PermSettingsManage,
+}
+
+// IsValid checks if the permission is one of the known permissions
+func (p Permission) IsValid() bool {
+	return slices.Contains(Permissions, p)
+}
+
+// Built-in roles, seeded by the migrations
+const (
+	RoleAdmin          UserRole = "admin"
+	RoleLDManager      UserRole = "ld_manager"
+	RoleDepartmentHead UserRole = "department_head"
+	RoleMentor         UserRole = "mentor"
+	RoleEmployee       UserRole = "employee"
+)
+
+// RoleScope tells where the permissions of a role apply
+type RoleScope string
+
+const (
+	ScopeOrganization RoleScope = "organization" // to everyone
+	ScopeDepartment   RoleScope = "department"   // to the members of the departments the user heads, subdepartments included
+)
+
+// IsValid checks if the scope is one of the known scopes
+func (s RoleScope) IsValid() bool {
+	return s == ScopeOrganization || s == ScopeDepartment
+}
+
+// Role is a named set of permissions assigned to users
+type Role struct {
+	Name        UserRole     `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+	Scope       RoleScope    `json:"scope"`
+	BuiltIn     bool         `json:"builtIn"` // seeded role that cannot be deleted
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// Has checks if the role grants the permission
+func (r *Role) Has(p Permission) bool {
+	return slices.Contains(r.Permissions, p)
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// IsValid checks if the role name is well-formed: lowercase letters, digits
+// and underscores, starting with a letter. Whether the role exists is up to
+// the role repository.
+func (r UserRole) IsValid() bool {
+	return roleNamePattern.MatchString(string(r))
+}
+
+// DefaultRoles returns the built-in roles as seeded by the migrations
+func DefaultRoles() []*Role {
+	ldManager := slices.DeleteFunc(slices.Clone(Permissions), func(p Permission) bool {
+		return p == PermUsersManage || p == PermRolesManage || p == PermJobsManage || p == PermWebhooksManage ||
+			p == PermDepartmentsManage || p == PermSettingsManage
+	})
+	departmentHead := []Permission{PermUsersView, PermUsersManage, PermRequestsView, PermRequestsApprove, PermLearningsView, PermAnalyticsView}
+	return []*Role{
+		{Name: RoleAdmin, Description: "Full access, including roles and integrations", Permissions: slices.Clone(Permissions), Scope: ScopeOrganization, BuiltIn: true},
+		{Name: RoleLDManager, Description: "Runs the learning programme: approvals, mentors, catalog and reports", Permissions: ldManager, Scope: ScopeOrganization, BuiltIn: true},
+		{Name: RoleDepartmentHead, Description: "Administers the people, requests and reports of the departments they head", Permissions: departmentHead, Scope: ScopeDepartment, BuiltIn: true},
+		{Name: RoleMentor, Description: "Guides the learnings they mentor", Permissions: []Permission{}, Scope: ScopeOrganization, BuiltIn: true},
+		{Name: RoleEmployee, Description: "Requests and takes part in own learnings", Permissions: []Permission{}, Scope: ScopeOrganization, BuiltIn: true},
+	}
+}
@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultTenantID is the organisation that data from before multi-tenancy
+// belongs to. Hosts no tenant claims, tokens issued before tenants existed
+// and the admin CLI without -tenant pick it explicitly.
+const DefaultTenantID = "00000000-0000-0000-0000-000000000001"
+
+// Tenant is an organisation hosted on the deployment. Every record belongs
+// to exactly one tenant and repositories only see the records of the tenant
+// in the context.
+type Tenant struct {
+	ID        string         `json:"id"`
+	Slug      string         `json:"slug"`
+	Name      string         `json:"name"`
+	Hosts     []string       `json:"hosts"` // host names that resolve to the tenant
+	Settings  TenantSettings `json:"settings"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// TenantSettings are the defaults, branding and integrations of a tenant
+type TenantSettings struct {
+	// MentorCapacity is how many active learnings new mentors take on
+	MentorCapacity int          `json:"mentorCapacity"`
+	Branding       Branding     `json:"branding"`
+	SCIM           SCIMSettings `json:"scim"`
+	HRIS           HRISSettings `json:"hris"`
+}
+
+// SCIMSettings hold the bearer token the tenant's identity provider
+// provisions users with. Only its SHA-256 is kept, see HashSCIMToken; SCIM
+// is off for the tenant until a token is issued.
+type SCIMSettings struct {
+	TokenHash string `json:"tokenHash,omitempty"`
+}
+
+// HRISSettings locate the JSON employee export the scheduled sync imports
+// into the tenant; tenants without a URL are skipped
+type HRISSettings struct {
+	URL        string            `json:"url,omitempty"`        // http(s) URL of the export
+	Token      string            `json:"token,omitempty"`      // sent as a bearer token
+	RecordsKey string            `json:"recordsKey,omitempty"` // key of the employee array when the export is an object
+	Fields     map[string]string `json:"fields,omitempty"`     // row fields to the keys of the export
+	Invite     bool              `json:"invite,omitempty"`     // email an invitation to created users
+}
+
+// RedactedSecret stands in for the secrets of settings shown to clients;
+// settings sent back with it keep the stored secret
+const RedactedSecret = "********"
+
+// Branding is what the frontend shows on the pages of a tenant
+type Branding struct {
+	DisplayName  string `json:"displayName,omitempty"`
+	LogoURL      string `json:"logoUrl,omitempty"`
+	PrimaryColor string `json:"primaryColor,omitempty"` // #rrggbb
+}
+
+var (
+	tenantSlugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	hostPattern       = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]{0,251}[a-z0-9])?$`)
+	colorPattern      = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// Validate checks the tenant and normalises its name and hosts
+func (t *Tenant) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("%w: tenant name is required", ErrInvalidInput)
+	}
+	if len(t.Name) > 255 {
+		return fmt.Errorf("%w: tenant name is too long", ErrInvalidInput)
+	}
+	if !tenantSlugPattern.MatchString(t.Slug) {
+		return fmt.Errorf("%w: slug must be lowercase letters, digits and dashes", ErrInvalidInput)
+	}
+
+	hosts := make([]string, 0, len(t.Hosts))
+	for _, host := range t.Hosts {
+		host = NormalizeHost(host)
+		if !hostPattern.MatchString(host) {
+			return fmt.Errorf("%w: invalid host %q", ErrInvalidInput, host)
+		}
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	t.Hosts = hosts
+
+	return t.Settings.Validate()
+}
+
+// Validate checks the settings, filling in the default mentor capacity
+func (s *TenantSettings) Validate() error {
+	if s.MentorCapacity == 0 {
+		s.MentorCapacity = DefaultMentorCapacity
+	}
+	if s.MentorCapacity < 1 || s.MentorCapacity > MaxMentorCapacity {
+		return fmt.Errorf("%w: mentor capacity must be between 1 and %d", ErrInvalidInput, MaxMentorCapacity)
+	}
+
+	b := &s.Branding
+	b.DisplayName = strings.TrimSpace(b.DisplayName)
+	if len(b.DisplayName) > 255 {
+		return fmt.Errorf("%w: display name is too long", ErrInvalidInput)
+	}
+	if b.LogoURL != "" {
+		u, err := url.Parse(b.LogoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: logo URL must be an http(s) URL", ErrInvalidInput)
+		}
+	}
+	if b.PrimaryColor != "" && !colorPattern.MatchString(b.PrimaryColor) {
+		return fmt.Errorf("%w: primary color must look like #1a2b3c", ErrInvalidInput)
+	}
+
+	h := &s.HRIS
+	h.URL = strings.TrimSpace(h.URL)
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: HRIS URL must be an http(s) URL", ErrInvalidInput)
+		}
+	}
+	if h.Token == RedactedSecret {
+		return fmt.Errorf("%w: HRIS token is redacted; send the stored settings back to keep it", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the settings with their secrets replaced by
+// RedactedSecret, for responses
+func (s TenantSettings) Redacted() TenantSettings {
+	if s.SCIM.TokenHash != "" {
+		s.SCIM.TokenHash = RedactedSecret
+	}
+	if s.HRIS.Token != "" {
+		s.HRIS.Token = RedactedSecret
+	}
+	s.HRIS.Fields = maps.Clone(s.HRIS.Fields)
+	return s
+}
+
+// KeepSecrets carries secrets over from the stored settings to settings a
+// client sent: the SCIM token, which only changes when one is issued or
+// revoked, and the HRIS token where the client sent back RedactedSecret
+func (s *TenantSettings) KeepSecrets(stored TenantSettings) {
+	s.SCIM = stored.SCIM
+	if s.HRIS.Token == RedactedSecret {
+		s.HRIS.Token = stored.HRIS.Token
+	}
+}
+
+// HashSCIMToken returns the hex-encoded SHA-256 of a SCIM bearer token as
+// kept in SCIMSettings; tokens are random, so a fast hash is enough
+func HashSCIMToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeHost lowercases a host name and strips the port, so that Host
+// headers match the hosts of tenants
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+type tenantKey struct{}
+
+// WithTenant confines the repository calls made with the context to the
+// tenant
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantID returns the tenant of the context. It panics when none was set:
+// falling back to some tenant would read and write another organisation's
+// records, so every entry point must choose the tenant with WithTenant.
+func TenantID(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantKey{}).(string); ok && id != "" {
+		return id
+	}
+	panic("domain: no tenant in context")
+}
@@ -12,6 +12,11 @@ const (
 	RequestPending  RequestStatus = "pending"
 	RequestApproved RequestStatus = "approved"
 	RequestRejected RequestStatus = "rejected"
+	RequestQueued   RequestStatus = "queued" // waiting for a mentor with capacity
+
+	// RequestAwaitingManager waits for the employee's line manager; pending
+	// requests wait for an L&D admin
+	RequestAwaitingManager RequestStatus = "awaiting_manager"
 )
 
 // TrainingRequest represents a request for training or mentorship
@@ -21,9 +26,21 @@ type TrainingRequest struct {
 	Topic       string        `json:"topic"`
 	Description string        `json:"description"`
 	Status      RequestStatus `json:"status"`
+	Priority    int           `json:"priority"`           // higher is served first from the queue
+	QueuedAt    *time.Time    `json:"queuedAt,omitempty"` // when the request joined the queue
+	CourseID    *string       `json:"courseId,omitempty"` // set on enrollment requests for a catalog course
+	Skills      []string      `json:"skills"`             // skill tags; a positively rated learning raises them
 	CreatedAt   time.Time     `json:"createdAt"`
 	UpdatedAt   time.Time     `json:"updatedAt"`
 
+	// ApprovalChain lists the stages the request goes through, fixed when it
+	// is created; after the last one it joins the queue
+	ApprovalChain []ApprovalStage `json:"approvalChain"`
+
+	// QueuePosition is the 1-based place in the queue of a queued request,
+	// 0 otherwise; it is computed by the service, not stored
+	QueuePosition int `json:"-"`
+
 	// ↓ Новые поля из JOIN с users (для response DTO)
 	UserName     string  `json:"-"` // Не показывать в JSON напрямую
 	UserJobTitle *string `json:"-"`
@@ -60,6 +77,44 @@ func (tr *TrainingRequest) IsRejected() bool {
 	return tr.Status == RequestRejected
 }
 
+// IsQueued checks if the request is waiting in the queue
+func (tr *TrainingRequest) IsQueued() bool {
+	return tr.Status == RequestQueued
+}
+
+// IsCourseEnrollment checks if the request asks for a seat on a catalog
+// course rather than for a mentor
+func (tr *TrainingRequest) IsCourseEnrollment() bool {
+	return tr.CourseID != nil
+}
+
+// IsAwaitingManager checks if the request waits for the manager's sign-off
+func (tr *TrainingRequest) IsAwaitingManager() bool {
+	return tr.Status == RequestAwaitingManager
+}
+
+// Stage returns the approval stage the request is waiting at, if any
+func (tr *TrainingRequest) Stage() (ApprovalStage, bool) {
+	switch tr.Status {
+	case RequestAwaitingManager:
+		return StageManager, true
+	case RequestPending:
+		return StageAdmin, true
+	}
+	return "", false
+}
+
+// NextStage returns the stage of the chain that follows the given one, if
+// any
+func (tr *TrainingRequest) NextStage(stage ApprovalStage) (ApprovalStage, bool) {
+	for i, s := range tr.ApprovalChain {
+		if s == stage && i+1 < len(tr.ApprovalChain) {
+			return tr.ApprovalChain[i+1], true
+		}
+	}
+	return "", false
+}
+
 // Approve marks the request as approved
 func (tr *TrainingRequest) Approve() {
 	tr.Status = RequestApproved
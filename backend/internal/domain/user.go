@@ -1,36 +1,61 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
-// UserRole defines possible user roles in the system
+// UserRole is the name of the role assigned to a user, see Role
 type UserRole string
 
-const (
-	RoleEmployee UserRole = "employee"
-	RoleAdmin    UserRole = "admin"
-	RoleUser     UserRole = "user"
-)
-
-// User represents a system user (employee or administrator)
+// User represents a system user
 type User struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Never expose in JSON
-	Role         UserRole  `json:"role"`
-	Department   *string   `json:"department,omitempty"`
-	JobTitle     *string   `json:"jobTitle,omitempty"`
-	Telegram     *string   `json:"telegram,omitempty"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID              string       `json:"id"`
+	Name            string       `json:"name"`
+	Email           string       `json:"email"`
+	PasswordHash    string       `json:"-"` // Never expose in JSON
+	Role            UserRole     `json:"role"`
+	DepartmentID    *string      `json:"departmentId,omitempty"`
+	Department      *string      `json:"department,omitempty"` // name of the department, resolved on read
+	JobTitle        *string      `json:"jobTitle,omitempty"`
+	Telegram        *string      `json:"telegram,omitempty"`
+	ManagerID       *string      `json:"managerId,omitempty"`  // line manager who signs off training requests
+	ExternalID      *string      `json:"externalId,omitempty"` // identifier in the identity provider that provisions the user
+	Permissions     []Permission `json:"permissions"`          // granted by the role, resolved on read
+	PermissionScope RoleScope    `json:"permissionScope"`      // where the permissions apply, from the role
+	DeactivatedAt   *time.Time   `json:"deactivatedAt,omitempty"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
+}
+
+// IsActive checks if user has not been deactivated
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
+// Can checks if the user's role grants the permission organisation-wide.
+// It is the single policy check of the application: handlers and services
+// ask for a permission, never for a role.
+func (u *User) Can(p Permission) bool {
+	return u.Grants(p) && u.PermissionScope != ScopeDepartment
 }
 
-// IsAdmin checks if user has admin privileges
-func (u *User) IsAdmin() bool {
-	return u.Role == RoleAdmin
+// Grants checks if the user's role grants the permission in any scope; a
+// department-scoped permission only reaches the departments the user heads,
+// see Scope
+func (u *User) Grants(p Permission) bool {
+	return slices.Contains(u.Permissions, p)
 }
 
-// IsEmployee checks if user is a regular employee
-func (u *User) IsEmployee() bool {
-	return u.Role == RoleEmployee
+// Covers checks if the user's role grants every permission the other
+// user's role grants, organisation-wide where the other's does. Nobody may
+// administer a user who can do more than they can, or they could take the
+// account over and act with its permissions.
+func (u *User) Covers(other *User) bool {
+	for _, p := range other.Permissions {
+		if !u.Grants(p) || (other.Can(p) && !u.Can(p)) {
+			return false
+		}
+	}
+	return true
 }
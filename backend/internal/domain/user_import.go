@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// UserImportRow is one employee of an import file or HRIS export. Rows are
+// matched to existing users by email, ignoring case; empty fields leave the
+// stored value unchanged.
+type UserImportRow struct {
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Department   string `json:"department"`
+	JobTitle     string `json:"jobTitle"`
+	Telegram     string `json:"telegram"`
+	ManagerEmail string `json:"managerEmail"`
+	Role         string `json:"role"`
+}
+
+// ImportAction is what an import does, or would do on a dry run, with a row
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"    // a new user
+	ImportUpdate    ImportAction = "update"    // an existing user with changed fields
+	ImportUnchanged ImportAction = "unchanged" // an existing user already up to date
+	ImportFailed    ImportAction = "failed"    // the row was rejected, see Error
+)
+
+// UserImportResult reports the outcome of one row
+type UserImportResult struct {
+	Row     int          `json:"row"` // 1-based, not counting the CSV header
+	Email   string       `json:"email"`
+	Action  ImportAction `json:"action"`
+	UserID  string       `json:"userId,omitempty"`
+	Changes []string     `json:"changes,omitempty"` // fields set on an existing user
+	Invited bool         `json:"invited,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// UserImportReport is the outcome of an import: the counts per action and
+// the result of every row in file order
+type UserImportReport struct {
+	DryRun    bool               `json:"dryRun"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Failed    int                `json:"failed"`
+	Rows      []UserImportResult `json:"rows"`
+}
+
+// UserImportOptions controls an import
+type UserImportOptions struct {
+	// DryRun validates the rows and reports the changes without writing
+	DryRun bool
+	// Invite emails created users a link to choose their password
+	Invite bool
+}
+
+// InvitationTTL is how long an invitation link can be used
+const InvitationTTL = 7 * 24 * time.Hour
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// EventType names a training event other systems can subscribe to
+type EventType string
+
+const (
+	EventRequestCreated        EventType = "request.created"         // a training request was submitted
+	EventRequestDecided        EventType = "request.decided"         // an approver approved or rejected a request
+	EventLearningStarted       EventType = "learning.started"        // a request got a mentor
+	EventLearningMentorChanged EventType = "learning.mentor_changed" // a learning moved to another mentor
+	EventLearningCompleted     EventType = "learning.completed"      // a learning was completed
+)
+
+// EventTypes lists every event type, in the order they happen
+var EventTypes = []EventType{
+	EventRequestCreated,
+	EventRequestDecided,
+	EventLearningStarted,
+	EventLearningMentorChanged,
+	EventLearningCompleted,
+}
+
+// IsValid checks if the event type is known
+func (t EventType) IsValid() bool {
+	return slices.Contains(EventTypes, t)
+}
+
+// OutboxEvent is a training event recorded in the same transaction as the
+// change it describes, so an event is published if and only if the change
+// is committed. The dispatcher later turns it into one delivery per
+// subscribed webhook.
+type OutboxEvent struct {
+	ID           string          `json:"id"`
+	Type         EventType       `json:"type"`
+	Payload      json.RawMessage `json:"data"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	DispatchedAt *time.Time      `json:"dispatchedAt,omitempty"`
+}
+
+// RequestEvent is the payload of request events
+type RequestEvent struct {
+	RequestID string        `json:"requestId"`
+	UserID    string        `json:"userId"`
+	Topic     string        `json:"topic"`
+	Status    RequestStatus `json:"status"`
+	CourseID  *string       `json:"courseId,omitempty"`
+
+	// Set on request.decided
+	Stage     ApprovalStage `json:"stage,omitempty"`
+	Decision  Decision      `json:"decision,omitempty"`
+	DeciderID string        `json:"deciderId,omitempty"`
+}
+
+// LearningEvent is the payload of learning events
+type LearningEvent struct {
+	LearningID string         `json:"learningId"`
+	RequestID  string         `json:"requestId"`
+	UserID     string         `json:"userId"`
+	MentorID   string         `json:"mentorId"`
+	Status     LearningStatus `json:"status"`
+
+	// Set on learning.mentor_changed
+	PreviousMentorID string `json:"previousMentorId,omitempty"`
+	// Set on learning.completed when the learner rated it
+	Rating int `json:"rating,omitempty"`
+}
+
+// WebhookSubscription is an endpoint of another system that receives the
+// events of the listed types, or of every type when the list is empty.
+// Each delivery is signed with the secret.
+type WebhookSubscription struct {
+	ID          string      `json:"id"`
+	URL         string      `json:"url"`
+	Secret      string      `json:"secret,omitempty"` // only returned when set
+	EventTypes  []EventType `json:"eventTypes"`
+	Description string      `json:"description,omitempty"`
+	Active      bool        `json:"active"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// Wants reports whether the subscription receives events of the type
+func (s *WebhookSubscription) Wants(t EventType) bool {
+	return s.Active && (len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, t))
+}
+
+// Validate checks the endpoint URL and the event types
+func (s *WebhookSubscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook url must be an absolute http(s) URL", ErrInvalidInput)
+	}
+	for _, t := range s.EventTypes {
+		if !t.IsValid() {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidInput, t)
+		}
+	}
+	if len(s.Secret) < MinWebhookSecretLength {
+		return fmt.Errorf("%w: webhook secret must be at least %d characters", ErrInvalidInput, MinWebhookSecretLength)
+	}
+	return nil
+}
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"   // waiting for nextAttemptAt
+	DeliveryDelivered DeliveryStatus = "delivered" // the endpoint answered 2xx
+	DeliveryDead      DeliveryStatus = "dead"      // gave up after maxAttempts
+)
+
+// IsValid checks if the status is a known delivery state
+func (s DeliveryStatus) IsValid() bool {
+	switch s {
+	case DeliveryPending, DeliveryDelivered, DeliveryDead:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery is one event on its way to one subscription. Failed
+// attempts are retried with backoff; a delivery that runs out of attempts
+// is dead and waits in the dead-letter list for an admin to redeliver it.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	EventType      EventType       `json:"eventType"`
+	Payload        json.RawMessage `json:"data"`
+	EventCreatedAt time.Time       `json:"eventCreatedAt"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// WebhookDeliveryFilter narrows the admin delivery list; zero fields match
+// everything
+type WebhookDeliveryFilter struct {
+	Status         DeliveryStatus
+	SubscriptionID string
+	Limit          int
+}
+
+const (
+	// MinWebhookSecretLength is the shortest signing secret accepted
+	MinWebhookSecretLength = 16
+	// DefaultWebhookMaxAttempts applies when no limit is configured
+	DefaultWebhookMaxAttempts = 8
+	// DefaultDeliveryListLimit caps the admin delivery list
+	DefaultDeliveryListLimit = 100
+
+	webhookBaseBackoff = time.Minute
+	webhookMaxBackoff  = 6 * time.Hour
+)
+
+// WebhookBackoff returns the delay before retrying after the given failed
+// attempt: 1m, 2m, 4m, ... doubling up to six hours
+func WebhookBackoff(attempt int) time.Duration {
+	delay := webhookBaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,153 @@
+// Package certificate renders completion certificates as PDF from a text
+// template.
+//
+// The template is a text/template executed with the certificate and its
+// VerifyURL; the date function formats a time. Each line of its output is
+// laid out by its prefix: "# " is the title, "## " a highlighted line,
+// "- " a list item, an empty line leaves a gap and any other line is body
+// text. Titles and body text are centered; long lines wrap. A line holding
+// the VerifyURL links to it.
+package certificate
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/pdf"
+)
+
+//go:embed default.tmpl
+var defaultTemplate string
+
+// Layout of a landscape A4 page, in points
+const (
+	margin      = 60.0
+	borderInset = 24.0
+	indent      = 40.0
+)
+
+type style struct {
+	font     pdf.Font
+	size     float64
+	centered bool
+}
+
+var (
+	titleStyle     = style{pdf.Bold, 30, true}
+	highlightStyle = style{pdf.Bold, 18, true}
+	bodyStyle      = style{pdf.Regular, 12, true}
+	itemStyle      = style{pdf.Regular, 11, false}
+)
+
+// Renderer implements domain.CertificateRenderer
+type Renderer struct {
+	tmpl      *template.Template
+	publicURL string
+}
+
+// NewRenderer parses the template at templatePath, or the built-in one when
+// the path is empty. Verification links point below publicURL.
+func NewRenderer(templatePath, publicURL string) (*Renderer, error) {
+	text := defaultTemplate
+	if templatePath != "" {
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate template: %w", err)
+		}
+		text = string(content)
+	}
+
+	tmpl, err := template.New("certificate").
+		Funcs(template.FuncMap{"date": func(t time.Time) string { return t.Format("2 January 2006") }}).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate template: %w", err)
+	}
+
+	return &Renderer{tmpl: tmpl, publicURL: strings.TrimRight(publicURL, "/")}, nil
+}
+
+// VerifyURL is the public address that confirms the certificate
+func (r *Renderer) VerifyURL(code string) string {
+	return r.publicURL + "/api/certificates/" + code
+}
+
+// Render lays the certificate out on as many pages as it takes
+func (r *Renderer) Render(certificate *domain.Certificate) ([]byte, error) {
+	verifyURL := r.VerifyURL(certificate.Code)
+	data := struct {
+		domain.Certificate
+		VerifyURL string
+	}{*flatten(certificate), verifyURL}
+
+	var text bytes.Buffer
+	if err := r.tmpl.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to execute certificate template: %w", err)
+	}
+
+	doc := pdf.New(pdf.A4Height, pdf.A4Width)
+	var y float64
+	newPage := func() {
+		doc.AddPage()
+		doc.Rect(borderInset, borderInset, doc.Width()-2*borderInset, doc.Height()-2*borderInset, 2)
+		y = doc.Height() - margin
+	}
+	newPage()
+
+	for _, line := range strings.Split(strings.TrimRight(text.String(), "\n"), "\n") {
+		st, x := bodyStyle, margin
+		switch {
+		case strings.TrimSpace(line) == "":
+			y -= bodyStyle.size
+			continue
+		case strings.HasPrefix(line, "## "):
+			st, line = highlightStyle, line[3:]
+		case strings.HasPrefix(line, "# "):
+			st, line = titleStyle, line[2:]
+		case strings.HasPrefix(line, "- "):
+			st, x, line = itemStyle, margin+indent, "• "+line[2:]
+		}
+
+		width := doc.Width() - margin - x
+		for _, wrapped := range pdf.Wrap(st.font, st.size, line, width) {
+			y -= st.size * 1.4
+			if y < margin {
+				newPage()
+				y -= st.size * 1.4
+			}
+			lx, lw := x, pdf.TextWidth(st.font, st.size, wrapped)
+			if st.centered {
+				lx = (doc.Width() - lw) / 2
+			}
+			doc.Text(lx, y, st.font, st.size, wrapped)
+			// The line with the verification link opens it when clicked
+			if strings.Contains(wrapped, verifyURL) {
+				doc.Link(lx, y-st.size*0.3, lw, st.size*1.3, verifyURL)
+			}
+		}
+	}
+
+	return doc.Bytes(), nil
+}
+
+// flatten copies the certificate with line breaks in its texts replaced by
+// spaces, so names and items cannot add lines of their own to the layout
+func flatten(c *domain.Certificate) *domain.Certificate {
+	v := *c
+	oneLine := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+	v.LearnerName = oneLine.Replace(c.LearnerName)
+	v.Topic = oneLine.Replace(c.Topic)
+	v.MentorName = oneLine.Replace(c.MentorName)
+	v.CompletedItems = make([]string, len(c.CompletedItems))
+	for i, item := range c.CompletedItems {
+		v.CompletedItems[i] = oneLine.Replace(item)
+	}
+	return &v
+}
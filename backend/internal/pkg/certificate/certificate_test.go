@@ -0,0 +1,222 @@
+package certificate
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+var bfcharPattern = regexp.MustCompile(`(?m)^<[0-9A-F]{4}> <([0-9A-F]+)>$`)
+
+func testCertificate() *domain.Certificate {
+	return &domain.Certificate{
+		Code:           "ABCD-EFGH-JKLM",
+		LearnerName:    "Иван Петров",
+		Topic:          "Go concurrency",
+		MentorName:     "Zoë Müller",
+		StartDate:      time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2026, 5, 29, 0, 0, 0, 0, time.UTC),
+		CompletedItems: []string{"Read the memory model", "Profile a service"},
+		IssuedAt:       time.Date(2026, 5, 30, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// printed returns the characters the document can print, read from the
+// ToUnicode maps of its fonts
+func printed(doc []byte) map[rune]bool {
+	chars := map[rune]bool{}
+	for _, m := range bfcharPattern.FindAllSubmatch(doc, -1) {
+		var units []uint16
+		for i := 0; i < len(m[1]); i += 4 {
+			u, _ := strconv.ParseUint(string(m[1][i:i+4]), 16, 16)
+			units = append(units, uint16(u))
+		}
+		for _, r := range utf16.Decode(units) {
+			chars[r] = true
+		}
+	}
+	return chars
+}
+
+// render renders the certificate with a renderer for the given template
+// text, or the built-in one when it is empty
+func render(t *testing.T, tmpl, publicURL string, certificate *domain.Certificate) []byte {
+	t.Helper()
+
+	path := ""
+	if tmpl != "" {
+		path = filepath.Join(t.TempDir(), "certificate.tmpl")
+		if err := os.WriteFile(path, []byte(tmpl), 0o600); err != nil {
+			t.Fatalf("write template: %v", err)
+		}
+	}
+	r, err := NewRenderer(path, publicURL)
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	doc, err := r.Render(certificate)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	return doc
+}
+
+func TestRenderer_Render(t *testing.T) {
+	tests := []struct {
+		name      string
+		tmpl      string
+		publicURL string
+		edit      func(c *domain.Certificate)
+		wantLines int
+		wantPages int
+		wantLink  string
+	}{
+		{
+			name:      "built-in template",
+			publicURL: "https://learning.example.com/",
+			wantLines: 11,
+			wantPages: 1,
+			wantLink:  "https://learning.example.com/api/certificates/ABCD-EFGH-JKLM",
+		},
+		{
+			name:      "no completed items",
+			publicURL: "https://learning.example.com",
+			edit:      func(c *domain.Certificate) { c.CompletedItems = nil },
+			wantLines: 8,
+			wantPages: 1,
+			wantLink:  "https://learning.example.com/api/certificates/ABCD-EFGH-JKLM",
+		},
+		{
+			name:      "line breaks in names stay on one line",
+			publicURL: "https://learning.example.com",
+			edit: func(c *domain.Certificate) {
+				c.LearnerName = "Иван\nПетров"
+				c.CompletedItems[0] = "Read\r\nthe model"
+			},
+			wantLines: 11,
+			wantPages: 1,
+			wantLink:  "https://learning.example.com/api/certificates/ABCD-EFGH-JKLM",
+		},
+		{
+			name:      "long plan runs onto more pages",
+			publicURL: "https://learning.example.com",
+			edit: func(c *domain.Certificate) {
+				c.CompletedItems = nil
+				for range 40 {
+					c.CompletedItems = append(c.CompletedItems, "Pair on a production incident review")
+				}
+			},
+			wantLines: 49,
+			wantPages: 2,
+			wantLink:  "https://learning.example.com/api/certificates/ABCD-EFGH-JKLM",
+		},
+		{
+			name:      "custom template",
+			tmpl:      "# {{.LearnerName}}\n\n{{.Topic}} with {{.MentorName}}\n{{.Code}}\n",
+			publicURL: "https://learning.example.com",
+			wantLines: 3,
+			wantPages: 1,
+		},
+		{
+			name:      "link quoted in the PDF string",
+			tmpl:      "{{.VerifyURL}}",
+			publicURL: `https://learning.example.com/a(b)\c`,
+			wantLines: 1,
+			wantPages: 1,
+			wantLink:  `https://learning.example.com/a\(b\)\\c/api/certificates/ABCD-EFGH-JKLM`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certificate := testCertificate()
+			if tt.edit != nil {
+				tt.edit(certificate)
+			}
+
+			doc := render(t, tt.tmpl, tt.publicURL, certificate)
+			if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) {
+				t.Fatalf("not a PDF")
+			}
+			if got := bytes.Count(doc, []byte(" Tj ET")); got != tt.wantLines {
+				t.Errorf("%d lines of text, want %d", got, tt.wantLines)
+			}
+			if got := bytes.Count(doc, []byte("/Type /Page /Parent")); got != tt.wantPages {
+				t.Errorf("%d pages, want %d", got, tt.wantPages)
+			}
+			// Every page has its border
+			if got := bytes.Count(doc, []byte(" re S")); got != tt.wantPages {
+				t.Errorf("%d borders, want %d", got, tt.wantPages)
+			}
+
+			links := bytes.Count(doc, []byte("/S /URI"))
+			if tt.wantLink == "" && links != 0 {
+				t.Errorf("%d links, want none", links)
+			}
+			if tt.wantLink != "" && (links != 1 || !bytes.Contains(doc, []byte("/URI ("+tt.wantLink+")"))) {
+				t.Errorf("%d links, want one to %s", links, tt.wantLink)
+			}
+
+			if tt.tmpl != "" && !strings.Contains(tt.tmpl, "Name") {
+				return
+			}
+			chars := printed(doc)
+			for _, r := range certificate.LearnerName + certificate.MentorName {
+				if r != '\n' && !chars[r] {
+					t.Errorf("%q is not printed", r)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRenderer_Errors(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.tmpl")
+	if err := os.WriteFile(broken, []byte("# {{.LearnerName"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	if _, err := NewRenderer(filepath.Join(dir, "missing.tmpl"), ""); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing template: %v", err)
+	}
+	if _, err := NewRenderer(broken, ""); err == nil || !strings.Contains(err.Error(), "parse") {
+		t.Errorf("broken template: %v", err)
+	}
+}
+
+func TestRenderer_RenderUnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "certificate.tmpl")
+	if err := os.WriteFile(path, []byte("# {{.Grade}}"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	r, err := NewRenderer(path, "https://learning.example.com")
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+
+	if _, err := r.Render(testCertificate()); err == nil || !strings.Contains(err.Error(), "execute") {
+		t.Errorf("Render with an unknown field: %v", err)
+	}
+}
+
+func TestRenderer_VerifyURL(t *testing.T) {
+	for _, publicURL := range []string{"https://learning.example.com", "https://learning.example.com/", "https://learning.example.com//"} {
+		r, err := NewRenderer("", publicURL)
+		if err != nil {
+			t.Fatalf("NewRenderer: %v", err)
+		}
+		if got := r.VerifyURL("ABCD-EFGH-JKLM"); got != "https://learning.example.com/api/certificates/ABCD-EFGH-JKLM" {
+			t.Errorf("VerifyURL with public URL %q = %q", publicURL, got)
+		}
+	}
+}
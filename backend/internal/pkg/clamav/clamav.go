@@ -0,0 +1,128 @@
+package clamav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/health"
+)
+
+// chunkSize is how much content is sent per INSTREAM chunk
+const chunkSize = 64 << 10
+
+// Scanner checks uploads with a clamd daemon through its INSTREAM command
+type Scanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// New creates a scanner for clamd at address: "host:port" for TCP or an
+// absolute path for a Unix socket. Each scan must finish within timeout.
+func New(address string, timeout time.Duration) *Scanner {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	return &Scanner{network: network, address: address, timeout: timeout}
+}
+
+// Scan sends content to clamd and fails with ErrFileInfected when a
+// signature matches. Errors talking to clamd reject the file as well, so
+// nothing is stored unscanned.
+func (s *Scanner) Scan(ctx context.Context, fileName string, content []byte) error {
+	reply, err := s.command(ctx, "INSTREAM", func(w *bufio.Writer) error {
+		var size [4]byte
+		for len(content) > 0 {
+			chunk := content[:min(chunkSize, len(content))]
+			content = content[len(chunk):]
+			binary.BigEndian.PutUint32(size[:], uint32(len(chunk)))
+			if _, err := w.Write(size[:]); err != nil {
+				return err
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+		}
+		binary.BigEndian.PutUint32(size[:], 0)
+		_, err := w.Write(size[:])
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", fileName, err)
+	}
+
+	// "stream: OK", "stream: <signature> FOUND" or "<message> ERROR"
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return fmt.Errorf("%w: %s", domain.ErrFileInfected, strings.TrimSuffix(reply, " FOUND"))
+	default:
+		return fmt.Errorf("failed to scan %s: clamd answered %q", fileName, reply)
+	}
+}
+
+// Ping checks that clamd answers
+func (s *Scanner) Ping(ctx context.Context) error {
+	reply, err := s.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamd answered %q to PING", reply)
+	}
+	return nil
+}
+
+// command sends a null-terminated command, followed by what body writes,
+// and returns the reply without its terminator
+func (s *Scanner) command(ctx context.Context, name string, body func(w *bufio.Writer) error) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to clamd at %s: %w", s.address, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	w := bufio.NewWriter(conn)
+	if _, err := w.WriteString("z" + name + "\x00"); err != nil {
+		return "", fmt.Errorf("clamd %s: %w", name, err)
+	}
+	if body != nil {
+		if err := body(w); err != nil {
+			return "", fmt.Errorf("clamd %s: %w", name, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("clamd %s: %w", name, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil {
+		return "", fmt.Errorf("clamd %s reply: %w", name, err)
+	}
+	return string(bytes.TrimSpace(bytes.TrimSuffix(reply, []byte{0}))), nil
+}
+
+// NewCheck reports whether clamd answers PING
+func NewCheck(s *Scanner) health.Checker {
+	return health.CheckFunc{
+		CheckName: "virus_scanner",
+		Fn:        s.Ping,
+	}
+}
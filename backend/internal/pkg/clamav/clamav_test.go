@@ -0,0 +1,203 @@
+package clamav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// eicar is the standard antivirus test file
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd serves connections like clamd: it answers PING with PONG and
+// streams with what reply returns for their content. Streams it received
+// are sent to the returned channel.
+func fakeClamd(t *testing.T, reply func(content []byte) string) (string, <-chan []byte) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	streams := make(chan []byte, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				cmd, err := r.ReadString(0)
+				if err != nil {
+					return
+				}
+				switch cmd {
+				case "zPING\x00":
+					conn.Write([]byte("PONG\x00"))
+				case "zINSTREAM\x00":
+					var content []byte
+					for {
+						var size uint32
+						if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+							return
+						}
+						if size == 0 {
+							break
+						}
+						chunk := make([]byte, size)
+						if _, err := io.ReadFull(r, chunk); err != nil {
+							return
+						}
+						content = append(content, chunk...)
+					}
+					streams <- content
+					conn.Write([]byte(reply(content) + "\x00"))
+				default:
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+				}
+			}()
+		}
+	}()
+
+	return ln.Addr().String(), streams
+}
+
+func clamdReply(content []byte) string {
+	if bytes.Contains(content, []byte(eicar)) {
+		return "stream: Eicar-Test-Signature FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestScanner_Scan(t *testing.T) {
+	addr, streams := fakeClamd(t, clamdReply)
+	scanner := New(addr, time.Second)
+	large := bytes.Repeat([]byte("a"), 3*chunkSize+17)
+
+	tests := []struct {
+		name     string
+		content  []byte
+		infected bool
+	}{
+		{name: "clean", content: []byte("meeting notes")},
+		{name: "several chunks", content: large},
+		{name: "infected", content: []byte(eicar), infected: true},
+		{name: "infected after the first chunk", content: append(append([]byte{}, large...), eicar...), infected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scanner.Scan(context.Background(), "notes.txt", tt.content)
+			if got := <-streams; !bytes.Equal(got, tt.content) {
+				t.Errorf("clamd received %d bytes, want %d", len(got), len(tt.content))
+			}
+			if !tt.infected {
+				if err != nil {
+					t.Errorf("Scan() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrFileInfected) || !strings.Contains(err.Error(), "Eicar-Test-Signature") {
+				t.Errorf("Scan() error = %v, want ErrFileInfected naming the signature", err)
+			}
+		})
+	}
+}
+
+func TestScanner_Failures(t *testing.T) {
+	addr, _ := fakeClamd(t, func([]byte) string { return "INSTREAM size limit exceeded. ERROR" })
+
+	// Closed right away so nothing listens on the address
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := ln.Addr().String()
+	ln.Close()
+
+	tests := []struct {
+		name    string
+		scanner *Scanner
+	}{
+		{name: "clamd error", scanner: New(addr, time.Second)},
+		{name: "unreachable", scanner: New(unreachable, time.Second)},
+		{name: "missing socket", scanner: New("/nonexistent/clamd.sock", time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scanner.Scan(context.Background(), "notes.txt", []byte("notes"))
+			if err == nil {
+				t.Fatal("Scan() succeeded, want the file rejected")
+			}
+			if errors.Is(err, domain.ErrFileInfected) {
+				t.Errorf("Scan() error = %v, want a scan failure rather than an infection", err)
+			}
+		})
+	}
+}
+
+func TestScanner_Timeout(t *testing.T) {
+	// Accepts connections but never answers
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		<-done
+		conn.Close()
+	}()
+
+	start := time.Now()
+	err = New(ln.Addr().String(), 50*time.Millisecond).Scan(context.Background(), "notes.txt", []byte("notes"))
+	if err == nil {
+		t.Fatal("Scan() succeeded against a silent clamd")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Scan() took %v, want it to give up after the timeout", elapsed)
+	}
+}
+
+func TestNewCheck(t *testing.T) {
+	addr, _ := fakeClamd(t, clamdReply)
+
+	check := NewCheck(New(addr, time.Second))
+	if check.Name() != "virus_scanner" {
+		t.Errorf("Name() = %q", check.Name())
+	}
+	if err := check.Check(context.Background()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if err := NewCheck(New("/nonexistent/clamd.sock", time.Second)).Check(context.Background()); err == nil {
+		t.Error("Check() of an unreachable clamd succeeded")
+	}
+}
+
+func TestNew_Network(t *testing.T) {
+	if s := New("clamav:3310", time.Second); s.network != "tcp" {
+		t.Errorf("network of host:port = %s, want tcp", s.network)
+	}
+	if s := New("/run/clamav/clamd.sock", time.Second); s.network != "unix" {
+		t.Errorf("network of a path = %s, want unix", s.network)
+	}
+}
@@ -0,0 +1,200 @@
+// Package cron parses cron-style schedules for recurring background jobs.
+//
+// A spec is either five fields — minute, hour, day of month, month, day of
+// week — or a descriptor: @hourly, @daily (@midnight), @weekly, @monthly,
+// @yearly (@annually) or "@every <duration>". Fields accept *, numbers,
+// ranges (1-5), steps (*/15, 0-30/10) and comma-separated lists. Day of
+// week runs from 0 (Sunday) to 7 (Sunday again). As in Vixie cron, when both
+// the day of month and the day of week are restricted a day matching either
+// one fires.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes the fire times of a spec
+type Schedule interface {
+	// Next returns the first fire time strictly after t
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a five-field spec or a descriptor
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		return parseDescriptor(spec)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron spec %q: want 5 fields, got %d", spec, len(fields))
+	}
+
+	var s fieldSchedule
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron spec %q: minute: %w", spec, err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron spec %q: hour: %w", spec, err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron spec %q: day of month: %w", spec, err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron spec %q: month: %w", spec, err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron spec %q: day of week: %w", spec, err)
+	}
+	// 7 is another name for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return &s, nil
+}
+
+func parseDescriptor(spec string) (Schedule, error) {
+	switch spec {
+	case "@yearly", "@annually":
+		return Parse("0 0 1 1 *")
+	case "@monthly":
+		return Parse("0 0 1 * *")
+	case "@weekly":
+		return Parse("0 0 * * 0")
+	case "@daily", "@midnight":
+		return Parse("0 0 * * *")
+	case "@hourly":
+		return Parse("0 * * * *")
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("cron spec %q: %w", spec, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("cron spec %q: interval must be at least a second", spec)
+		}
+		return every(d), nil
+	}
+	return nil, fmt.Errorf("cron spec %q: unknown descriptor", spec)
+}
+
+// parseField turns a field into a bit set of the allowed values
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(from, min, max); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(to, min, max); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("bad range %q", rangePart)
+			}
+		default:
+			v, err := parseValue(rangePart, min, max)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			// "5/15" means from 5 to the end in steps of 15
+			if !hasStep {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+// fieldSchedule is a parsed five-field spec; each field is a bit set
+type fieldSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// Next walks forward field by field, in t's location
+func (s *fieldSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Impossible specs such as Feb 30 never match; give up after five years
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *fieldSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// every fires at multiples of the interval rather than relative to startup,
+// so that every replica computes the same fire times
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+	return t.Truncate(d).Add(d)
+}
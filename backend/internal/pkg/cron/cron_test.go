@@ -0,0 +1,152 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "", wantErr: "want 5 fields, got 0"},
+		{spec: "* * * *", wantErr: "want 5 fields, got 4"},
+		{spec: "* * * * * *", wantErr: "want 5 fields, got 6"},
+		{spec: "60 * * * *", wantErr: "minute: value 60 out of range 0-59"},
+		{spec: "* 24 * * *", wantErr: "hour: value 24 out of range 0-23"},
+		{spec: "* * 0 * *", wantErr: "day of month: value 0 out of range 1-31"},
+		{spec: "* * 32 * *", wantErr: "day of month: value 32 out of range 1-31"},
+		{spec: "* * * 13 *", wantErr: "month: value 13 out of range 1-12"},
+		{spec: "* * * * 8", wantErr: "day of week: value 8 out of range 0-7"},
+		{spec: "-1 * * * *", wantErr: `minute: bad value ""`},
+		{spec: "5- * * * *", wantErr: `minute: bad value ""`},
+		{spec: "1-2-3 * * * *", wantErr: `minute: bad value "2-3"`},
+		{spec: "30-10 * * * *", wantErr: `minute: bad range "30-10"`},
+		{spec: "*/0 * * * *", wantErr: `minute: bad step "0"`},
+		{spec: "*/-5 * * * *", wantErr: `minute: bad step "-5"`},
+		{spec: "*/ * * * *", wantErr: `minute: bad step ""`},
+		{spec: "*/99999999999999999999 * * * *", wantErr: "minute: bad step"},
+		{spec: "1,,2 * * * *", wantErr: `minute: bad value ""`},
+		{spec: ", * * * *", wantErr: `minute: bad value ""`},
+		{spec: "** * * * *", wantErr: `minute: bad value "**"`},
+		{spec: "* * * JAN *", wantErr: `month: bad value "JAN"`},
+		{spec: "* * * * MON", wantErr: `day of week: bad value "MON"`},
+		{spec: "1/2/3 * * * *", wantErr: `minute: bad step "2/3"`},
+		{spec: "@reboot", wantErr: "unknown descriptor"},
+		{spec: "@DAILY", wantErr: "unknown descriptor"},
+		{spec: "@every", wantErr: "unknown descriptor"},
+		{spec: "@every ", wantErr: "unknown descriptor"},
+		{spec: "@every soon", wantErr: `invalid duration "soon"`},
+		{spec: "@every 500ms", wantErr: "interval must be at least a second"},
+		{spec: "@every -1h", wantErr: "interval must be at least a second"},
+		{spec: "@every 0s", wantErr: "interval must be at least a second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) = %v, %v, want error containing %q", tt.spec, s, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Wednesday, 4 March 2026, 10:07:30
+	from := time.Date(2026, 3, 4, 10, 7, 30, 0, time.UTC)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		year := 2026
+		if month < time.March {
+			year = 2027
+		}
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		want []time.Time
+	}{
+		{name: "every minute", spec: "* * * * *", want: []time.Time{at(3, 4, 10, 8), at(3, 4, 10, 9)}},
+		{name: "surrounding spaces", spec: "  * * * * *\n", want: []time.Time{at(3, 4, 10, 8)}},
+		{name: "minute step", spec: "*/15 * * * *", want: []time.Time{at(3, 4, 10, 15), at(3, 4, 10, 30), at(3, 4, 10, 45), at(3, 4, 11, 0)}},
+		{name: "start with step", spec: "5/20 * * * *", want: []time.Time{at(3, 4, 10, 25), at(3, 4, 10, 45), at(3, 4, 11, 5)}},
+		{name: "range with step", spec: "0-30/10 9 * * *", want: []time.Time{at(3, 5, 9, 0), at(3, 5, 9, 10), at(3, 5, 9, 20), at(3, 5, 9, 30), at(3, 6, 9, 0)}},
+		{name: "list", spec: "0 8,12,18 * * *", want: []time.Time{at(3, 4, 12, 0), at(3, 4, 18, 0), at(3, 5, 8, 0)}},
+		{name: "weekdays", spec: "0 9 * * 1-5", want: []time.Time{at(3, 5, 9, 0), at(3, 6, 9, 0), at(3, 9, 9, 0)}},
+		{name: "sunday as 0", spec: "30 6 * * 0", want: []time.Time{at(3, 8, 6, 30), at(3, 15, 6, 30)}},
+		{name: "sunday as 7", spec: "30 6 * * 7", want: []time.Time{at(3, 8, 6, 30), at(3, 15, 6, 30)}},
+		{name: "day of month or day of week", spec: "0 0 13 * 5", want: []time.Time{at(3, 6, 0, 0), at(3, 13, 0, 0), at(3, 20, 0, 0)}},
+		{name: "31st skips short months", spec: "0 0 31 * *", want: []time.Time{at(3, 31, 0, 0), at(5, 31, 0, 0), at(7, 31, 0, 0)}},
+		{name: "month range", spec: "0 0 1 6-8 *", want: []time.Time{at(6, 1, 0, 0), at(7, 1, 0, 0), at(8, 1, 0, 0), at(6, 1, 0, 0).AddDate(1, 0, 0)}},
+		{name: "hourly", spec: "@hourly", want: []time.Time{at(3, 4, 11, 0), at(3, 4, 12, 0)}},
+		{name: "daily", spec: "@daily", want: []time.Time{at(3, 5, 0, 0)}},
+		{name: "midnight", spec: "@midnight", want: []time.Time{at(3, 5, 0, 0)}},
+		{name: "weekly", spec: "@weekly", want: []time.Time{at(3, 8, 0, 0), at(3, 15, 0, 0)}},
+		{name: "monthly", spec: "@monthly", want: []time.Time{at(4, 1, 0, 0), at(5, 1, 0, 0)}},
+		{name: "yearly", spec: "@yearly", want: []time.Time{at(1, 1, 0, 0)}},
+		{name: "annually", spec: "@annually", want: []time.Time{at(1, 1, 0, 0)}},
+		{name: "every 30 minutes", spec: "@every 30m", want: []time.Time{at(3, 4, 10, 30), at(3, 4, 11, 0)}},
+		{name: "every 90 seconds", spec: "@every 90s", want: []time.Time{
+			time.Date(2026, 3, 4, 10, 9, 0, 0, time.UTC), time.Date(2026, 3, 4, 10, 10, 30, 0, time.UTC),
+		}},
+		{name: "leap day", spec: "0 0 29 2 *", want: []time.Time{time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)}},
+		{name: "impossible date", spec: "0 0 30 2 *", want: []time.Time{{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			next := from
+			for i, want := range tt.want {
+				next = s.Next(next)
+				if !next.Equal(want) {
+					t.Fatalf("fire time %d = %v, want %v", i+1, next, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedule_NextKeepsLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	s, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Across the switch to summer time, 9:00 stays 9:00 local time
+	next := s.Next(time.Date(2026, 3, 28, 12, 0, 0, 0, berlin))
+	if want := time.Date(2026, 3, 29, 9, 0, 0, 0, berlin); !next.Equal(want) || next.Location() != berlin {
+		t.Errorf("Next = %v, want %v", next, want)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"* * * * *", "*/15 0-30/10 1,15 */2 1-5", "0 0 30 2 *", "@every 1h", "@weekly",
+		"1-2-3 * * * *", "*/0 * * * *", "@every", ",,,, * * * *", "59 23 31 12 7",
+	} {
+		f.Add(seed)
+	}
+
+	from := time.Date(2026, 3, 4, 10, 7, 30, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, spec string) {
+		s, err := Parse(spec)
+		if err != nil {
+			return
+		}
+		if next := s.Next(from); !next.IsZero() && !next.After(from) {
+			t.Errorf("Parse(%q).Next(%v) = %v, not after it", spec, from, next)
+		}
+	})
+}
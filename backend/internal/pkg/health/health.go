@@ -0,0 +1,156 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status values reported by probes
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+)
+
+// Checker verifies a single dependency
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to the Checker interface
+type CheckFunc struct {
+	CheckName string
+	Fn        func(ctx context.Context) error
+}
+
+// Name returns the dependency name
+func (c CheckFunc) Name() string { return c.CheckName }
+
+// Check runs the check function
+func (c CheckFunc) Check(ctx context.Context) error { return c.Fn(ctx) }
+
+// CheckResult is the outcome of a single dependency check
+type CheckResult struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+	// Optional checks are reported without failing readiness
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Report is the aggregated readiness state
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Ready reports whether the service can take traffic
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+// Monitor runs registered dependency checks and tracks the draining state
+type Monitor struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks []registeredCheck
+}
+
+type registeredCheck struct {
+	checker  Checker
+	optional bool
+}
+
+// NewMonitor creates a monitor; each check is bounded by timeout
+func NewMonitor(timeout time.Duration) *Monitor {
+	return &Monitor{timeout: timeout}
+}
+
+// Register adds a dependency check to readiness
+func (m *Monitor) Register(checker Checker) {
+	m.register(checker, false)
+}
+
+// RegisterOptional adds a check that readiness reports but does not fail
+// on, for dependencies such as integrations the API can serve without
+func (m *Monitor) RegisterOptional(checker Checker) {
+	m.register(checker, true)
+}
+
+func (m *Monitor) register(checker Checker, optional bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks = append(m.checks, registeredCheck{checker: checker, optional: optional})
+}
+
+// SetDraining marks the service as shutting down; readiness fails from now on
+func (m *Monitor) SetDraining() {
+	m.draining.Store(true)
+}
+
+// IsDraining reports whether shutdown has started
+func (m *Monitor) IsDraining() bool {
+	return m.draining.Load()
+}
+
+// Readiness runs all checks concurrently and aggregates the results
+func (m *Monitor) Readiness(ctx context.Context) Report {
+	m.mu.RLock()
+	checks := append([]registeredCheck(nil), m.checks...)
+	m.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check registeredCheck) {
+			defer wg.Done()
+			results[i] = m.run(ctx, check.checker)
+			results[i].Optional = check.optional
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusReady,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+	for i, check := range checks {
+		report.Checks[check.checker.Name()] = results[i]
+		if results[i].Status != StatusUp && !check.optional {
+			report.Status = StatusNotReady
+		}
+	}
+
+	if m.IsDraining() {
+		report.Status = StatusDraining
+	}
+
+	return report
+}
+
+// run executes a single check with the configured timeout
+func (m *Monitor) run(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
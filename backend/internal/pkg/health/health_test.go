@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func check(name string, err error) Checker {
+	return CheckFunc{CheckName: name, Fn: func(context.Context) error { return err }}
+}
+
+func TestMonitor_Readiness(t *testing.T) {
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		required   []Checker
+		optional   []Checker
+		draining   bool
+		wantStatus string
+	}{
+		{name: "all up", required: []Checker{check("database", nil)}, optional: []Checker{check("hris", nil)}, wantStatus: StatusReady},
+		{name: "required down", required: []Checker{check("database", down)}, optional: []Checker{check("hris", nil)}, wantStatus: StatusNotReady},
+		{name: "optional down", required: []Checker{check("database", nil)}, optional: []Checker{check("hris", down)}, wantStatus: StatusReady},
+		{name: "no checks", wantStatus: StatusReady},
+		{name: "draining", required: []Checker{check("database", nil)}, draining: true, wantStatus: StatusDraining},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMonitor(time.Second)
+			for _, c := range tt.required {
+				m.Register(c)
+			}
+			for _, c := range tt.optional {
+				m.RegisterOptional(c)
+			}
+			if tt.draining {
+				m.SetDraining()
+			}
+
+			report := m.Readiness(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if len(report.Checks) != len(tt.required)+len(tt.optional) {
+				t.Fatalf("%d checks reported", len(report.Checks))
+			}
+			for _, c := range tt.optional {
+				if !report.Checks[c.Name()].Optional {
+					t.Errorf("%s not reported as optional", c.Name())
+				}
+			}
+			for name, result := range report.Checks {
+				if (result.Status == StatusDown) != (result.Error != "") {
+					t.Errorf("%s = %+v", name, result)
+				}
+			}
+		})
+	}
+}
+
+func TestMonitor_CheckTimeout(t *testing.T) {
+	m := NewMonitor(10 * time.Millisecond)
+	m.Register(CheckFunc{CheckName: "smtp", Fn: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	report := m.Readiness(context.Background())
+	if report.Ready() || report.Checks["smtp"].Status != StatusDown {
+		t.Errorf("report = %+v", report)
+	}
+}
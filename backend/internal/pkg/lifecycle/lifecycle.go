@@ -0,0 +1,125 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Manager owns background goroutines and resources that must be released
+// when the process stops. Workers are cancelled first, then shutdown hooks
+// run in reverse registration order (last registered, first closed). A
+// worker that fails cancels the others, since the process cannot do its job
+// without it.
+type Manager struct {
+	logger *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	failed     chan struct{}
+	failedOnce sync.Once
+
+	mu         sync.Mutex
+	hooks      []hook
+	workerErrs []error
+	done       bool
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New creates a lifecycle manager
+func New(logger *slog.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		failed: make(chan struct{}),
+	}
+}
+
+// Go starts a background worker. The worker must return once ctx is
+// cancelled. An error returned before that cancels the other workers,
+// closes Failed and is reported by Shutdown.
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.logger.Debug("background worker started", "worker", name)
+		err := fn(m.ctx)
+		if err == nil || (m.ctx.Err() != nil && errors.Is(err, context.Canceled)) {
+			m.logger.Debug("background worker stopped", "worker", name)
+			return
+		}
+
+		m.logger.Error("background worker failed", "worker", name, "error", err)
+		m.mu.Lock()
+		m.workerErrs = append(m.workerErrs, fmt.Errorf("%s: %w", name, err))
+		m.mu.Unlock()
+		m.cancel()
+		m.failedOnce.Do(func() { close(m.failed) })
+	}()
+}
+
+// Failed is closed once a worker fails, telling the owner to shut down
+func (m *Manager) Failed() <-chan struct{} {
+	return m.failed
+}
+
+// OnShutdown registers a hook executed after all workers have stopped
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Shutdown stops all workers and runs shutdown hooks in reverse order.
+// Hooks still run if workers do not stop before ctx expires.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	var errs []error
+
+	// Stop background workers
+	m.cancel()
+	stopped := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("background workers did not stop: %w", ctx.Err()))
+	}
+	m.mu.Lock()
+	errs = append(errs, m.workerErrs...)
+	m.mu.Unlock()
+
+	// Release resources, last registered first
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		m.logger.Info("shutting down", "component", h.name)
+		if err := h.fn(ctx); err != nil {
+			m.logger.Error("shutdown hook failed", "component", h.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,156 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newManager() *Manager {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestShutdown_RunsHooksInReverseOrder(t *testing.T) {
+	m := newManager()
+	var order []string
+	var workerStopped bool
+	m.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		workerStopped = true
+		return nil
+	})
+	for _, name := range []string{"db", "cache", "server"} {
+		m.OnShutdown(name, func(ctx context.Context) error {
+			if !workerStopped {
+				t.Errorf("hook %s ran before the worker stopped", name)
+			}
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if want := []string{"server", "cache", "db"}; !slices.Equal(order, want) {
+		t.Errorf("hooks ran in order %v, want %v", order, want)
+	}
+}
+
+func TestShutdown_ReportsHookErrors(t *testing.T) {
+	m := newManager()
+	errClose := errors.New("close failed")
+	var ran bool
+	m.OnShutdown("first", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	m.OnShutdown("second", func(ctx context.Context) error { return errClose })
+
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, errClose) {
+		t.Errorf("Shutdown() error = %v, want %v", err, errClose)
+	}
+	if !ran {
+		t.Error("a failing hook stopped the ones after it")
+	}
+}
+
+func TestShutdown_RespectsDeadline(t *testing.T) {
+	m := newManager()
+	release := make(chan struct{})
+	defer close(release)
+	m.Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	var hookRan bool
+	m.OnShutdown("db", func(ctx context.Context) error {
+		hookRan = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := m.Shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v, want it to stop waiting at the deadline", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if !hookRan {
+		t.Error("hooks did not run after the deadline")
+	}
+}
+
+func TestGo_FailureCancelsOtherWorkers(t *testing.T) {
+	m := newManager()
+	errBroken := errors.New("broken")
+	stopped := make(chan struct{})
+	m.Go("healthy", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+	m.Go("broken", func(ctx context.Context) error { return errBroken })
+
+	select {
+	case <-m.Failed():
+	case <-time.After(time.Second):
+		t.Fatal("Failed() was not closed")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the healthy worker was not cancelled")
+	}
+
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, errBroken) {
+		t.Errorf("Shutdown() error = %v, want %v", err, errBroken)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("Shutdown() error = %v, want cancelled workers not reported", err)
+	}
+}
+
+func TestShutdown_StopsWorkersWithoutFailure(t *testing.T) {
+	m := newManager()
+	m.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	select {
+	case <-m.Failed():
+		t.Error("Failed() closed after a clean shutdown")
+	default:
+	}
+}
+
+func TestShutdown_RunsOnce(t *testing.T) {
+	m := newManager()
+	var calls int
+	m.OnShutdown("db", func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+
+	for range 2 {
+		if err := m.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("hook ran %d times, want 1", calls)
+	}
+}
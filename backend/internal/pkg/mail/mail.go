@@ -0,0 +1,125 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/health"
+)
+
+// SMTPConfig holds the settings of an SMTP relay
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // no authentication when empty
+	Password string
+	From     string
+}
+
+// SMTPMailer sends emails through an SMTP relay, upgrading to TLS when the
+// server offers STARTTLS
+type SMTPMailer struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", cfg.From, err)
+	}
+	return &SMTPMailer{cfg: cfg, from: from}, nil
+}
+
+// Send delivers a plain-text email to one recipient
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address %q: %w", to, err)
+	}
+	message, err := compose(m.from, recipient, subject, body)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	if err := smtp.SendMail(addr, auth, m.from.Address, []string{recipient.Address}, message); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", recipient.Address, err)
+	}
+	return nil
+}
+
+// NewCheck reports whether the SMTP relay answers: it connects, greets the
+// server and sends NOOP, without sending mail or authenticating
+func NewCheck(m *SMTPMailer) health.Checker {
+	return health.CheckFunc{
+		CheckName: "mailer",
+		Fn: func(ctx context.Context) error {
+			addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer conn.Close()
+			if deadline, ok := ctx.Deadline(); ok {
+				conn.SetDeadline(deadline)
+			}
+
+			client, err := smtp.NewClient(conn, m.cfg.Host)
+			if err != nil {
+				return fmt.Errorf("SMTP greeting from %s: %w", addr, err)
+			}
+			defer client.Close()
+			if err := client.Hello("localhost"); err != nil {
+				return fmt.Errorf("SMTP EHLO to %s: %w", addr, err)
+			}
+			if err := client.Noop(); err != nil {
+				return fmt.Errorf("SMTP NOOP to %s: %w", addr, err)
+			}
+			return client.Quit()
+		},
+	}
+}
+
+// LogMailer writes emails to the log instead of sending them, for
+// development setups without an SMTP relay
+type LogMailer struct{}
+
+// Send logs the email
+func (LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	slog.InfoContext(ctx, "email not sent, no SMTP host configured", "to", to, "subject", subject, "body", body)
+	return nil
+}
+
+// compose builds an RFC 5322 message with a UTF-8 plain-text body
+func compose(from, to *mail.Address, subject, body string) ([]byte, error) {
+	if strings.ContainsAny(subject, "\r\n") {
+		return nil, errors.New("email subject must be a single line")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes(), nil
+}
@@ -0,0 +1,145 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP serves one connection with the given reply to NOOP and records
+// the commands it received
+func fakeSMTP(t *testing.T, noopReply string) (SMTPConfig, <-chan []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var seen []string
+		defer func() { commands <- seen }()
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("220 mail.example.com ESMTP\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.Fields(line + " x")[0])
+			seen = append(seen, cmd)
+			switch cmd {
+			case "EHLO":
+				conn.Write([]byte("250-mail.example.com\r\n250 8BITMIME\r\n"))
+			case "NOOP":
+				conn.Write([]byte(noopReply + "\r\n"))
+			case "QUIT":
+				conn.Write([]byte("221 bye\r\n"))
+				return
+			default:
+				conn.Write([]byte("502 not implemented\r\n"))
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return SMTPConfig{Host: host, Port: p, From: "learning@example.com"}, commands
+}
+
+func TestNewCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		noopReply string
+		wantErr   string
+	}{
+		{name: "relay up", noopReply: "250 ok"},
+		{name: "relay refuses NOOP", noopReply: "421 service not available", wantErr: "SMTP NOOP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, commands := fakeSMTP(t, tt.noopReply)
+			m, err := NewSMTPMailer(cfg)
+			if err != nil {
+				t.Fatalf("NewSMTPMailer: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			check := NewCheck(m)
+			err = check.Check(ctx)
+			if (tt.wantErr == "" && err != nil) || (tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr))) {
+				t.Fatalf("Check = %v, want error %q", err, tt.wantErr)
+			}
+			if check.Name() != "mailer" {
+				t.Errorf("Name = %q", check.Name())
+			}
+
+			// Nothing is sent and no credentials are used
+			for _, cmd := range <-commands {
+				if cmd == "MAIL" || cmd == "AUTH" || cmd == "DATA" {
+					t.Errorf("check sent %s", cmd)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCheck_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	ln.Close()
+	p, _ := strconv.Atoi(port)
+
+	m, err := NewSMTPMailer(SMTPConfig{Host: host, Port: p, From: "learning@example.com"})
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+	if err := NewCheck(m).Check(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to connect") {
+		t.Errorf("Check of a closed port = %v", err)
+	}
+}
+
+func TestNewCheck_SilentServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		// Accept and never greet
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	m, err := NewSMTPMailer(SMTPConfig{Host: host, Port: p, From: "learning@example.com"})
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := NewCheck(m).Check(ctx); err == nil || !strings.Contains(err.Error(), "greeting") {
+		t.Errorf("Check of a silent server = %v, want a greeting timeout", err)
+	}
+}
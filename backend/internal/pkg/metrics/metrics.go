@@ -90,18 +90,90 @@ var (
 		},
 	)
 
-	FeedbackRatingSum = promauto.NewCounter(
+	// Ratings given on completion of a learning or a course enrollment
+	CompletionRatings = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "feedback_completion_rating",
+			Help:    "Ratings given on completion",
+			Buckets: []float64{1, 2, 3, 4, 5},
+		},
+		[]string{"subject"},
+	)
+
+	// Questionnaire answers per mentor, audience and criterion
+	FeedbackScores = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "feedback_score",
+			Help:    "Scores given in feedback questionnaires",
+			Buckets: []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		[]string{"mentor_id", "audience", "criterion"},
+	)
+
+	CheckInsSent = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "check_ins_sent_total",
+			Help: "Total number of check-ins sent to learners and mentors",
+		},
+		[]string{"role"},
+	)
+
+	// Background jobs
+	JobsEnqueued = promauto.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "feedback_rating_sum",
-			Help: "Sum of all feedback ratings",
+			Name: "jobs_enqueued_total",
+			Help: "Total number of background jobs enqueued",
 		},
+		[]string{"kind"},
 	)
 
-	FeedbackRatingCount = promauto.NewCounter(
+	JobsProcessed = promauto.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "feedback_rating_count",
-			Help: "Total number of feedback ratings",
+			Name: "jobs_processed_total",
+			Help: "Total number of job attempts by outcome (succeeded, retried, failed)",
 		},
+		[]string{"kind", "outcome"},
+	)
+
+	JobDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "job_duration_seconds",
+			Help:    "Duration of job attempts in seconds",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+		},
+		[]string{"kind"},
+	)
+
+	Jobs = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "jobs",
+			Help: "Number of background jobs by status",
+		},
+		[]string{"status"},
+	)
+
+	JobQueueLag = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "job_queue_lag_seconds",
+			Help: "How long the oldest due job has been waiting for a worker",
+		},
+	)
+
+	// Webhooks
+	OutboxEvents = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_total",
+			Help: "Total number of training events written to the outbox",
+		},
+		[]string{"type"},
+	)
+
+	WebhookDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Total number of webhook delivery attempts by outcome (delivered, retried, dead)",
+		},
+		[]string{"event_type", "outcome"},
 	)
 )
 
@@ -111,6 +183,12 @@ func RecordHttpRequest(method, endpoint string, status int, duration time.Durati
 	HttpRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
 }
 
+// RecordJob records the outcome and duration of a job attempt
+func RecordJob(kind, outcome string, duration time.Duration) {
+	JobsProcessed.WithLabelValues(kind, outcome).Inc()
+	JobDuration.WithLabelValues(kind).Observe(duration.Seconds())
+}
+
 // RecordDbQuery records database query metrics
 func RecordDbQuery(operation string, duration time.Duration, err error) {
 	status := "success"
@@ -0,0 +1,75 @@
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a request would reach an address that
+// is not public
+var ErrPrivateAddress = errors.New("destination is a private, loopback or link-local address")
+
+// reserved are ranges not covered by the netip predicates that must not be
+// reachable either: "this network", carrier-grade NAT, IETF protocol
+// assignments, benchmarking, reserved and broadcast
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// IsPublic reports whether addr may be reached by requests to addresses
+// chosen by users: it is not loopback, private (RFC 1918, unique local),
+// link-local (such as the 169.254.169.254 metadata endpoint), multicast,
+// unspecified or otherwise reserved
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control is a net.Dialer Control function that refuses to connect to
+// addresses that are not public. It sees the address after DNS resolution,
+// so host names resolving to internal addresses, including ones that change
+// between checks, are refused as well.
+func Control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for URLs chosen by users that only
+// connects to public addresses. Proxies from the environment are not used,
+// since the proxy would make the connection on the client's behalf.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
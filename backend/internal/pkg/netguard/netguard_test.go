@@ -0,0 +1,104 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "8.8.8.8", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "127.8.9.10"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "172.31.255.255"},
+		{addr: "192.168.1.1"},
+		{addr: "fd00::1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "100.64.0.1"},
+		{addr: "224.0.0.1"},
+		{addr: "255.255.255.255"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:169.254.169.254"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("IsPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControl(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{address: "93.184.216.34:443"},
+		{address: "[2606:2800:220:1:248:1893:25c8:1946]:443"},
+		{address: "127.0.0.1:8080", wantErr: true},
+		{address: "169.254.169.254:80", wantErr: true},
+		{address: "[::1]:443", wantErr: true},
+		{address: "not-an-address", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := Control("tcp", tt.address, nil)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Control(%s) = %v, want error %v", tt.address, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrPrivateAddress) {
+				t.Errorf("error = %v, want ErrPrivateAddress", err)
+			}
+		})
+	}
+}
+
+func TestNewClient_RefusesLoopback(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, which a plain client reaches
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("plain client: %v", err)
+	}
+	resp.Body.Close()
+	called = false
+
+	// Host names are resolved before the check, so localhost is refused too
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range []string{server.URL, "http://localhost:" + port} {
+		_, err = NewClient(time.Second).Get(url)
+		if !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("GET %s error = %v, want ErrPrivateAddress", url, err)
+		}
+	}
+	if called {
+		t.Error("the guarded client reached the server")
+	}
+}
@@ -0,0 +1,366 @@
+// Package pdf writes simple text documents in PDF. Text is set in the Go
+// fonts, embedded as TrueType with the Identity-H encoding, so every
+// character the fonts cover (Latin, Greek, Cyrillic and more) prints as
+// itself; characters they lack print as an empty box.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Font is one of the fonts the writer embeds
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+var faces = [...]*face{
+	Regular: parseFace("GoRegular", goregular.TTF, 80),
+	Bold:    parseFace("GoBold", gobold.TTF, 140),
+}
+
+// Page sizes in points
+const (
+	A4Width  = 595.0
+	A4Height = 842.0
+)
+
+// Document is a PDF under construction. Coordinates are in points from the
+// bottom left corner of the page.
+type Document struct {
+	width, height float64
+	pages         []*page
+	// glyphs the pages use per font, with the character each one stands for
+	used [len(faces)]map[sfnt.GlyphIndex]rune
+}
+
+// New starts a document whose pages are width by height points
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// Width is the page width in points
+func (d *Document) Width() float64 { return d.width }
+
+// Height is the page height in points
+func (d *Document) Height() float64 { return d.height }
+
+// page is the content of a page and the links on it
+type page struct {
+	content bytes.Buffer
+	links   []string
+}
+
+// AddPage starts a new page; drawing goes to the last page
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &page{})
+}
+
+// Text draws a line of text with its baseline starting at x, y. The text is
+// written as a hex string of glyph indices, so no character needs escaping.
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	f := faces[font]
+	if d.used[font] == nil {
+		d.used[font] = map[sfnt.GlyphIndex]rune{}
+	}
+
+	var b sfnt.Buffer
+	var glyphs strings.Builder
+	for _, r := range s {
+		g := f.glyph(&b, r)
+		if _, ok := d.used[font][g]; !ok {
+			d.used[font][g] = r
+		}
+		fmt.Fprintf(&glyphs, "%04X", uint16(g))
+	}
+	fmt.Fprintf(&d.page().content, "BT /F%d %s Tf %s %s Td <%s> Tj ET\n", font+1, num(size), num(x), num(y), glyphs.String())
+}
+
+// Rect strokes a rectangle
+func (d *Document) Rect(x, y, w, h, lineWidth float64) {
+	fmt.Fprintf(&d.page().content, "%s w %s %s %s %s re S\n", num(lineWidth), num(x), num(y), num(w), num(h))
+}
+
+// Link makes the rectangle open uri when clicked; it draws nothing
+func (d *Document) Link(x, y, w, h float64, uri string) {
+	p := d.page()
+	p.links = append(p.links, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /A << /S /URI /URI (%s) >> >>",
+		num(x), num(y), num(x+w), num(y+h), escape(uri)))
+}
+
+// Bytes serializes the document
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 and 2 are the catalog and the page tree, then the objects of
+	// each font in use, then a page and its content for each page
+	var fonts []string
+	next := 3
+	for i := range faces {
+		if d.used[i] != nil {
+			fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, next))
+			next += objectsPerFont
+		}
+	}
+	firstPage := next
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for i, f := range faces {
+		if d.used[i] != nil {
+			f.write(object, len(offsets)+1, d.used[i])
+		}
+	}
+	for i, p := range d.pages {
+		annots := ""
+		if len(p.links) > 0 {
+			annots = fmt.Sprintf(" /Annots [%s]", strings.Join(p.links, " "))
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R%s >>",
+			num(d.width), num(d.height), strings.Join(fonts, " "), firstPage+2*i+1, annots))
+		object(stream("", p.content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func (d *Document) page() *page {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// TextWidth measures a line of text in points
+func TextWidth(font Font, size float64, s string) float64 {
+	f := faces[font]
+	var b sfnt.Buffer
+	total := 0
+	for _, r := range s {
+		total += f.width(&b, f.glyph(&b, r))
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks text into lines no wider than maxWidth, splitting at spaces;
+// a word longer than a line is put on a line of its own
+func Wrap(font Font, size float64, s string, maxWidth float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && TextWidth(font, size, candidate) > maxWidth {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// objectsPerFont counts the objects of an embedded font: the Type0 font,
+// its CID font, the font descriptor, the font program and the ToUnicode map
+const objectsPerFont = 5
+
+// face is an embeddable TrueType font
+type face struct {
+	name  string
+	font  *sfnt.Font
+	stemV int
+	// the font program, compressed once on first use, and its size before
+	// compression
+	program func() []byte
+	size    int
+	// ascent, descent, cap height and bounding box in thousandths of the
+	// font size
+	ascent, descent, capHeight int
+	bbox                       [4]int
+}
+
+func parseFace(name string, ttf []byte, stemV int) *face {
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		panic(fmt.Sprintf("pdf: failed to parse font %s: %v", name, err))
+	}
+
+	fc := &face{
+		name:  name,
+		font:  f,
+		stemV: stemV,
+		size:  len(ttf),
+		program: sync.OnceValue(func() []byte {
+			var z bytes.Buffer
+			w := zlib.NewWriter(&z)
+			w.Write(ttf)
+			w.Close()
+			return z.Bytes()
+		}),
+	}
+
+	var b sfnt.Buffer
+	ppem := fixed.I(int(f.UnitsPerEm()))
+	metrics, err := f.Metrics(&b, ppem, font.HintingNone)
+	if err != nil {
+		panic(fmt.Sprintf("pdf: failed to read metrics of font %s: %v", name, err))
+	}
+	bounds, err := f.Bounds(&b, ppem, font.HintingNone)
+	if err != nil {
+		panic(fmt.Sprintf("pdf: failed to read bounds of font %s: %v", name, err))
+	}
+	// sfnt measures y downwards, PDF upwards
+	fc.ascent = fc.scale(metrics.Ascent)
+	fc.descent = -fc.scale(metrics.Descent)
+	fc.capHeight = fc.scale(metrics.CapHeight)
+	fc.bbox = [4]int{fc.scale(bounds.Min.X), -fc.scale(bounds.Max.Y), fc.scale(bounds.Max.X), -fc.scale(bounds.Min.Y)}
+	return fc
+}
+
+// glyph looks up the glyph of a character; characters the font lacks get
+// glyph 0, the empty box
+func (f *face) glyph(b *sfnt.Buffer, r rune) sfnt.GlyphIndex {
+	g, err := f.font.GlyphIndex(b, r)
+	if err != nil {
+		return 0
+	}
+	return g
+}
+
+// width is the advance of a glyph in thousandths of the font size
+func (f *face) width(b *sfnt.Buffer, g sfnt.GlyphIndex) int {
+	advance, err := f.font.GlyphAdvance(b, g, fixed.I(int(f.font.UnitsPerEm())), font.HintingNone)
+	if err != nil {
+		return 0
+	}
+	return f.scale(advance)
+}
+
+// scale converts a length measured at one em per font unit to thousandths
+// of the font size
+func (f *face) scale(v fixed.Int26_6) int {
+	return int(math.Round(float64(v) / 64 * 1000 / float64(f.font.UnitsPerEm())))
+}
+
+// write adds the objects of the font, numbered from first on, with the
+// widths and character mappings of the used glyphs
+func (f *face) write(object func(string), first int, used map[sfnt.GlyphIndex]rune) {
+	glyphs := make([]sfnt.GlyphIndex, 0, len(used))
+	for g := range used {
+		glyphs = append(glyphs, g)
+	}
+	slices.Sort(glyphs)
+
+	var b sfnt.Buffer
+	widths := make([]string, len(glyphs))
+	for i, g := range glyphs {
+		widths[i] = fmt.Sprintf("%d [%d]", g, f.width(&b, g))
+	}
+
+	object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, first+1, first+4))
+	object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		f.name, first+2, strings.Join(widths, " ")))
+	object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV %d /FontFile2 %d 0 R >>",
+		f.name, f.bbox[0], f.bbox[1], f.bbox[2], f.bbox[3], f.ascent, f.descent, f.capHeight, f.stemV, first+3))
+	object(stream(fmt.Sprintf("/Length1 %d /Filter /FlateDecode", f.size), f.program()))
+	object(stream("", toUnicode(glyphs, used)))
+}
+
+// toUnicode builds the CMap that maps glyphs back to characters, so text
+// can be searched and copied
+func toUnicode(glyphs []sfnt.GlyphIndex, used map[sfnt.GlyphIndex]rune) []byte {
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// A bfchar block holds at most 100 mappings
+	for chunk := range slices.Chunk(glyphs, 100) {
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&cmap, "<%04X> <", uint16(g))
+			for _, unit := range utf16.Encode([]rune{used[g]}) {
+				fmt.Fprintf(&cmap, "%04X", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+	return cmap.Bytes()
+}
+
+// stream formats a stream object with the given extra dictionary entries
+func stream(dict string, data []byte) string {
+	if dict != "" {
+		dict = " " + dict
+	}
+	return fmt.Sprintf("<< /Length %d%s >>\nstream\n%s\nendstream", len(data), dict, data)
+}
+
+// escape quotes text for a PDF string literal; bytes outside ASCII are
+// written as octal escapes
+func escape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '(' || c == ')' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < 32 || c >= 127:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// num formats a coordinate without needless decimals
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,282 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+var (
+	objectPattern = regexp.MustCompile(`(?m)^(\d+) 0 obj\n`)
+	textPattern   = regexp.MustCompile(`/F(\d) \S+ Tf \S+ \S+ Td <([0-9A-F]*)> Tj`)
+	bfcharPattern = regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]+)>`)
+)
+
+// objects splits a serialized document into its objects by number and
+// checks the cross-reference table points at each of them
+func objects(t *testing.T, doc []byte) map[int]string {
+	t.Helper()
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("document lacks the PDF header or trailer")
+	}
+	start := bytes.LastIndex(doc, []byte("startxref\n"))
+	xref, err := strconv.Atoi(strings.Fields(string(doc[start+len("startxref\n"):]))[0])
+	if err != nil || !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the cross-reference table")
+	}
+	entries := strings.Split(string(doc[xref:]), "\n")[3:]
+
+	objs := map[int]string{}
+	for _, m := range objectPattern.FindAllSubmatchIndex(doc, -1) {
+		n, _ := strconv.Atoi(string(doc[m[2]:m[3]]))
+		if want := fmt.Sprintf("%010d 00000 n ", m[0]); entries[n-1] != want {
+			t.Errorf("xref entry of object %d = %q, want %q", n, entries[n-1], want)
+		}
+		end := bytes.Index(doc[m[1]:], []byte("\nendobj\n"))
+		objs[n] = string(doc[m[1] : m[1]+end])
+	}
+	return objs
+}
+
+// streamData returns the data of a stream object, checking its length
+func streamData(t *testing.T, obj string) string {
+	t.Helper()
+
+	m := regexp.MustCompile(`/Length (\d+)`).FindStringSubmatch(obj)
+	begin := strings.Index(obj, "\nstream\n") + len("\nstream\n")
+	end := strings.LastIndex(obj, "\nendstream")
+	if m == nil || begin < len("\nstream\n") || end < begin {
+		t.Fatalf("not a stream: %.80q", obj)
+	}
+	if n, _ := strconv.Atoi(m[1]); n != end-begin {
+		t.Errorf("stream /Length %d, data is %d bytes", n, end-begin)
+	}
+	return obj[begin:end]
+}
+
+// extractText decodes the text drawn on the pages through the ToUnicode
+// maps of the fonts, one line per Text call
+func extractText(t *testing.T, doc []byte) []string {
+	t.Helper()
+
+	objs := objects(t, doc)
+	cmaps := map[string]map[string]string{}
+	for _, obj := range objs {
+		m := regexp.MustCompile(`/Subtype /Type0 .*/ToUnicode (\d+) 0 R`).FindStringSubmatch(obj)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		cmap := map[string]string{}
+		for _, c := range bfcharPattern.FindAllStringSubmatch(streamData(t, objs[n]), -1) {
+			var units []uint16
+			for i := 0; i < len(c[2]); i += 4 {
+				u, _ := strconv.ParseUint(c[2][i:i+4], 16, 16)
+				units = append(units, uint16(u))
+			}
+			cmap[c[1]] = string(utf16.Decode(units))
+		}
+		cmaps[strings.Fields(obj[strings.Index(obj, "/BaseFont /")+len("/BaseFont /"):])[0]] = cmap
+	}
+
+	var lines []string
+	for _, obj := range objs {
+		if strings.Contains(obj, "/Type") || !strings.Contains(obj, "stream") {
+			continue
+		}
+		for _, m := range textPattern.FindAllStringSubmatch(streamData(t, obj), -1) {
+			font, _ := strconv.Atoi(m[1])
+			cmap := cmaps[faces[font-1].name]
+			var line strings.Builder
+			for i := 0; i < len(m[2]); i += 4 {
+				line.WriteString(cmap[m[2][i:i+4]])
+			}
+			lines = append(lines, line.String())
+		}
+	}
+	return lines
+}
+
+func TestDocument_Text(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		missing bool
+	}{
+		{name: "ascii", text: "Certificate of Completion"},
+		{name: "pdf string delimiters", text: `Go (advanced) \ part 1)`},
+		{name: "latin", text: "Zoë Müller – “Straße”"},
+		{name: "cyrillic", text: "Иван Петров: Основы Go"},
+		{name: "greek", text: "Αθηνά"},
+		{name: "missing glyph", text: "😀", missing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New(A4Width, A4Height)
+			doc.Text(72, 700, Regular, 12, tt.text)
+			out := doc.Bytes()
+
+			lines := extractText(t, out)
+			if len(lines) != 1 || lines[0] != tt.text {
+				t.Errorf("extracted %q, want %q", lines, tt.text)
+			}
+			// A hex string of glyphs needs no escaping of ( ) and \
+			if !strings.Contains(string(out), " Td <") || strings.Contains(string(out), " Td (") {
+				t.Errorf("text not written as a hex string")
+			}
+
+			var b sfnt.Buffer
+			for _, r := range tt.text {
+				if g := faces[Regular].glyph(&b, r); (g == 0) != tt.missing {
+					t.Errorf("glyph of %q = %d", r, g)
+				}
+			}
+		})
+	}
+}
+
+func TestDocument_Bytes(t *testing.T) {
+	doc := New(A4Height, A4Width)
+	doc.Text(72, 500, Bold, 30, "Certificate")
+	doc.Rect(24, 24, 794, 547, 2)
+	doc.AddPage()
+	doc.Text(72, 500, Regular, 12, "Page two")
+
+	objs := objects(t, doc.Bytes())
+	// Catalog, page tree, two fonts and two pages with their content
+	if len(objs) != 2+2*objectsPerFont+4 {
+		t.Fatalf("%d objects", len(objs))
+	}
+	if !strings.Contains(objs[2], "/Kids [13 0 R 15 0 R] /Count 2") {
+		t.Errorf("page tree %q", objs[2])
+	}
+	if !strings.Contains(objs[13], "/MediaBox [0 0 842 595]") || !strings.Contains(objs[13], "/F1 3 0 R /F2 8 0 R") {
+		t.Errorf("page %q", objs[13])
+	}
+	if !strings.Contains(objs[3], "/Subtype /Type0 /BaseFont /GoRegular /Encoding /Identity-H") {
+		t.Errorf("font %q", objs[3])
+	}
+	if content := streamData(t, objs[14]); !strings.Contains(content, "2 w 24 24 794 547 re S") {
+		t.Errorf("content of page one %q", content)
+	}
+
+	// The font program is the whole TrueType file
+	program := objs[6]
+	if !strings.Contains(program, fmt.Sprintf("/Length1 %d /Filter /FlateDecode", len(goregular.TTF))) {
+		t.Errorf("font program %.80q", program)
+	}
+	r, err := zlib.NewReader(strings.NewReader(streamData(t, program)))
+	if err != nil {
+		t.Fatalf("font program: %v", err)
+	}
+	ttf, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(ttf, goregular.TTF) {
+		t.Errorf("font program does not decompress to Go Regular: %v", err)
+	}
+}
+
+func TestDocument_BytesEmbedsOnlyUsedFonts(t *testing.T) {
+	doc := New(A4Width, A4Height)
+	doc.Text(72, 700, Bold, 12, "Bold only")
+
+	objs := objects(t, doc.Bytes())
+	if len(objs) != 2+objectsPerFont+2 {
+		t.Fatalf("%d objects, want one font", len(objs))
+	}
+	if !strings.Contains(objs[3], "/BaseFont /GoBold") || !strings.Contains(objs[8], "/Font << /F2 3 0 R >>") {
+		t.Errorf("font %q, page %q", objs[3], objs[8])
+	}
+
+	if objs := objects(t, New(A4Width, A4Height).Bytes()); len(objs) != 4 {
+		t.Errorf("empty document has %d objects, want catalog, pages and one page", len(objs))
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if w := TextWidth(Regular, 12, ""); w != 0 {
+		t.Errorf("width of empty text = %v", w)
+	}
+	if a, b := TextWidth(Regular, 10, "Иван"), TextWidth(Regular, 20, "Иван"); a <= 0 || b != 2*a {
+		t.Errorf("widths at 10 and 20 points = %v, %v", a, b)
+	}
+	if narrow, wide := TextWidth(Regular, 12, "iiii"), TextWidth(Regular, 12, "WWWW"); narrow >= wide {
+		t.Errorf("iiii is %v wide, WWWW %v", narrow, wide)
+	}
+	if regular, bold := TextWidth(Regular, 12, "Certificate"), TextWidth(Bold, 12, "Certificate"); regular >= bold {
+		t.Errorf("regular %v, bold %v", regular, bold)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	word := TextWidth(Regular, 12, "Основы")
+	space := TextWidth(Regular, 12, " ")
+
+	tests := []struct {
+		name     string
+		text     string
+		maxWidth float64
+		want     []string
+	}{
+		{name: "empty", text: "  ", maxWidth: 100, want: nil},
+		{name: "fits", text: "Основы Основы", maxWidth: 2*word + space, want: []string{"Основы Основы"}},
+		{name: "breaks", text: "Основы Основы Основы", maxWidth: 2*word + space, want: []string{"Основы Основы", "Основы"}},
+		{name: "collapses spaces", text: " Основы \n Основы ", maxWidth: 2*word + space, want: []string{"Основы Основы"}},
+		{name: "long word", text: "Основы Основы", maxWidth: word / 2, want: []string{"Основы", "Основы"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(Regular, 12, tt.text, tt.maxWidth)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("Wrap = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_Link(t *testing.T) {
+	doc := New(A4Width, A4Height)
+	doc.Link(72, 100, 200.5, 14, `https://learning.example.com/api/certificates/ABCD(1)\x`)
+	doc.AddPage()
+
+	objs := objects(t, doc.Bytes())
+	want := `/Annots [<< /Type /Annot /Subtype /Link /Rect [72 100 272.5 114] /Border [0 0 0] ` +
+		`/A << /S /URI /URI (https://learning.example.com/api/certificates/ABCD\(1\)\\x) >> >>]`
+	if !strings.Contains(objs[3], want) {
+		t.Errorf("first page %q, want the link %q", objs[3], want)
+	}
+	if strings.Contains(objs[5], "/Annots") {
+		t.Errorf("second page %q has links", objs[5])
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "", want: ""},
+		{in: "plain text", want: "plain text"},
+		{in: "(nested (parens))", want: `\(nested \(parens\)\)`},
+		{in: `back\slash`, want: `back\\slash`},
+		{in: "unbalanced )(", want: `unbalanced \)\(`},
+		{in: "line\nbreak", want: `line\012break`},
+		{in: "é", want: `\303\251`},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
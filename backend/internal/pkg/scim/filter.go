@@ -0,0 +1,432 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Filter selects resources, as in the filter query parameter
+type Filter interface {
+	// Match reports whether a resource in map form matches
+	Match(resource map[string]any) bool
+}
+
+// ParseFilter parses a filter expression: comparisons with eq, ne, co, sw,
+// ew, gt, ge, lt and le, presence tests with pr, and, or, not, parentheses
+// and value paths such as emails[type eq "work"]. Strings compare ignoring
+// case; values that are both timestamps compare as times.
+func ParseFilter(expr string) (Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, p.peek().text)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpen    // (
+	tokenClose   // )
+	tokenBracket // [
+	tokenEnd     // ]
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a filter into words, string literals and brackets
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenBracket, text: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenEnd, text: "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(expr) && expr[end] != '"'; end++ {
+				if expr[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+			}
+			var s string
+			if err := json.Unmarshal([]byte(expr[i:end+1]), &s); err != nil {
+				return nil, fmt.Errorf("%w: bad string %s", ErrInvalidFilter, expr[i:end+1])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(expr) && !strings.ContainsRune(" \t\n\r()[]\"", rune(expr[end])); end++ {
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: expr[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	if p.done() {
+		return token{kind: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+// keyword reports whether the next token is the keyword, consuming it
+func (p *filterParser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokenWord && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) error {
+	if p.peek().kind != kind {
+		return fmt.Errorf("%w: expected %q", ErrInvalidFilter, text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.keyword("not") {
+		if err := p.expect(tokenOpen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenClose, ")"); err != nil {
+			return nil, err
+		}
+		return notFilter{f}, nil
+	}
+
+	if p.peek().kind == tokenOpen {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenClose, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	t := p.peek()
+	if t.kind != tokenWord {
+		return nil, fmt.Errorf("%w: expected an attribute", ErrInvalidFilter)
+	}
+	p.pos++
+	path, err := parseAttrPath(t.text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	if p.peek().kind == tokenBracket {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenEnd, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{path: path, filter: inner}, nil
+	}
+
+	op := strings.ToLower(p.peek().text)
+	if p.peek().kind != tokenWord {
+		return nil, fmt.Errorf("%w: expected an operator after %s", ErrInvalidFilter, t.text)
+	}
+	p.pos++
+	if op == "pr" {
+		return presentFilter{path}, nil
+	}
+	switch op {
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, op)
+	}
+
+	v := p.peek()
+	if p.done() || (v.kind != tokenString && v.kind != tokenWord) {
+		return nil, fmt.Errorf("%w: expected a value after %s", ErrInvalidFilter, op)
+	}
+	p.pos++
+	var value any = v.text
+	if v.kind == tokenWord {
+		if err := json.Unmarshal([]byte(v.text), &value); err != nil {
+			return nil, fmt.Errorf("%w: bad value %q", ErrInvalidFilter, v.text)
+		}
+	}
+	return compareFilter{path: path, op: op, value: value}, nil
+}
+
+// attrPath is an attribute with an optional sub-attribute, under an
+// extension schema when schema is set; all lowercased
+type attrPath struct {
+	schema string
+	attr   string
+	sub    string
+}
+
+// parseAttrPath parses [urn:...:]attr[.sub]
+func parseAttrPath(s string) (attrPath, error) {
+	var path attrPath
+	s = strings.ToLower(s)
+	for _, core := range []string{SchemaUser, SchemaGroup} {
+		s = strings.TrimPrefix(s, strings.ToLower(core)+":")
+	}
+	if strings.HasPrefix(s, "urn:") {
+		i := strings.LastIndex(s, ":")
+		path.schema, s = s[:i], s[i+1:]
+	}
+	path.attr, path.sub, _ = strings.Cut(s, ".")
+	if path.attr == "" || strings.ContainsAny(path.attr+path.sub, ".:") {
+		return attrPath{}, fmt.Errorf("bad attribute path %q", s)
+	}
+	return path, nil
+}
+
+// container returns the map holding the attribute
+func (p attrPath) container(resource map[string]any) map[string]any {
+	if p.schema == "" {
+		return resource
+	}
+	m, _ := resource[p.schema].(map[string]any)
+	return m
+}
+
+// values returns the values of the attribute; the value sub-attribute
+// stands for multi-valued complex attributes without a sub-attribute
+func (p attrPath) values(resource map[string]any) []any {
+	v := p.container(resource)[p.attr]
+	items, multi := v.([]any)
+	if !multi {
+		if m, ok := v.(map[string]any); ok && p.sub != "" {
+			v = m[p.sub]
+		} else if p.sub != "" {
+			return nil
+		}
+		if v == nil {
+			return nil
+		}
+		return []any{v}
+	}
+
+	sub := p.sub
+	if sub == "" {
+		sub = "value"
+	}
+	var values []any
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			if m[sub] != nil {
+				values = append(values, m[sub])
+			}
+		} else if p.sub == "" && item != nil {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+type andFilter struct{ left, right Filter }
+
+func (f andFilter) Match(r map[string]any) bool { return f.left.Match(r) && f.right.Match(r) }
+
+type orFilter struct{ left, right Filter }
+
+func (f orFilter) Match(r map[string]any) bool { return f.left.Match(r) || f.right.Match(r) }
+
+type notFilter struct{ inner Filter }
+
+func (f notFilter) Match(r map[string]any) bool { return !f.inner.Match(r) }
+
+type presentFilter struct{ path attrPath }
+
+func (f presentFilter) Match(r map[string]any) bool {
+	for _, v := range f.path.values(r) {
+		if s, ok := v.(string); !ok || s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// valuePathFilter matches resources with an item of a multi-valued
+// attribute that matches the inner filter
+type valuePathFilter struct {
+	path   attrPath
+	filter Filter
+}
+
+func (f valuePathFilter) Match(r map[string]any) bool {
+	v := f.path.container(r)[f.path.attr]
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok && f.filter.Match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  attrPath
+	op    string
+	value any
+}
+
+func (f compareFilter) Match(r map[string]any) bool {
+	values := f.path.values(r)
+	if f.op == "ne" {
+		for _, v := range values {
+			if compare(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare applies an operator other than ne to an attribute value
+func compare(attr any, op string, value any) bool {
+	switch a := attr.(type) {
+	case string:
+		b, ok := value.(string)
+		if !ok {
+			return false
+		}
+		if ta, err := time.Parse(time.RFC3339Nano, a); err == nil {
+			if tb, err := time.Parse(time.RFC3339Nano, b); err == nil {
+				return order(ta.Compare(tb), op)
+			}
+		}
+		a, b = strings.ToLower(a), strings.ToLower(b)
+		switch op {
+		case "co":
+			return strings.Contains(a, b)
+		case "sw":
+			return strings.HasPrefix(a, b)
+		case "ew":
+			return strings.HasSuffix(a, b)
+		}
+		return order(strings.Compare(a, b), op)
+	case bool:
+		b, ok := value.(bool)
+		return ok && op == "eq" && a == b
+	case float64:
+		b, ok := value.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case a < b:
+			return order(-1, op)
+		case a > b:
+			return order(1, op)
+		}
+		return order(0, op)
+	}
+	return false
+}
+
+// order applies an ordering operator to the result of a comparison
+func order(cmp int, op string) bool {
+	switch op {
+	case "eq":
+		return cmp == 0
+	case "gt":
+		return cmp > 0
+	case "ge":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "le":
+		return cmp <= 0
+	}
+	return false
+}
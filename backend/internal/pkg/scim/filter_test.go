@@ -0,0 +1,181 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// decode decodes JSON into the lowercased map form filters and patches
+// work on
+func decode(t testing.TB, data string) map[string]any {
+	t.Helper()
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return lowerKeys(m).(map[string]any)
+}
+
+// bjensen is the full user representation of RFC 7643, section 8.2,
+// trimmed to the attributes the filters use
+const bjensen = `{
+	"schemas": [
+		"urn:ietf:params:scim:schemas:core:2.0:User",
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	],
+	"id": "2819c223-7f76-453a-919d-413861904646",
+	"userName": "bjensen@example.com",
+	"name": {"familyName": "O'Malley", "givenName": "Barbara"},
+	"title": "Tour Guide",
+	"userType": "Employee",
+	"active": true,
+	"loginCount": 42,
+	"emails": [
+		{"value": "bjensen@example.com", "type": "work", "primary": true},
+		{"value": "babs@jensen.org", "type": "home"}
+	],
+	"ims": [{"value": "someaimhandle", "type": "aim"}],
+	"meta": {"lastModified": "2011-05-13T04:42:34Z"},
+	"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {
+		"employeeNumber": "701984",
+		"manager": {"value": "26118915-6090-4610-87e4-49d8ca9f808d"}
+	}
+}`
+
+func TestParseFilter_Match(t *testing.T) {
+	user := decode(t, bjensen)
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		// The examples of RFC 7644, section 3.4.2.2
+		{filter: `userName eq "bjensen@example.com"`, want: true},
+		{filter: `name.familyName co "O'Malley"`, want: true},
+		{filter: `userName sw "J"`, want: false},
+		{filter: `userName sw "B"`, want: true},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName sw "B"`, want: true},
+		{filter: `title pr`, want: true},
+		{filter: `meta.lastModified gt "2011-05-13T04:42:34Z"`, want: false},
+		{filter: `meta.lastModified ge "2011-05-13T04:42:34Z"`, want: true},
+		{filter: `meta.lastModified lt "2011-05-13T04:42:34Z"`, want: false},
+		{filter: `meta.lastModified le "2011-05-13T04:42:34Z"`, want: true},
+		{filter: `title pr and userType eq "Employee"`, want: true},
+		{filter: `title pr or userType eq "Intern"`, want: true},
+		{filter: `schemas eq "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`, want: true},
+		{filter: `userType eq "Employee" and (emails co "example.com" or emails.value co "example.org")`, want: true},
+		{filter: `userType ne "Employee" and not (emails co "example.com" or emails.value co "example.org")`, want: false},
+		{filter: `userType eq "Employee" and (emails.type eq "work")`, want: true},
+		{filter: `userType eq "Employee" and emails[type eq "work" and value co "@example.com"]`, want: true},
+		{filter: `emails[type eq "work" and value co "@example.com"] or ims[type eq "xmpp" and value co "@foo.com"]`, want: true},
+
+		// Case, precedence and types
+		{filter: `USERNAME EQ "BJENSEN@EXAMPLE.COM"`, want: true},
+		{filter: `userName ne "bjensen@example.com"`, want: false},
+		{filter: `nickName ne "Babs"`, want: true},
+		{filter: `userName ew "@EXAMPLE.com"`, want: true},
+		{filter: `userType eq "Intern" and title pr or active eq true`, want: true},
+		{filter: `userType eq "Intern" and (title pr or active eq true)`, want: false},
+		{filter: `not (userType eq "Intern")`, want: true},
+		{filter: `active eq true`, want: true},
+		{filter: `active eq false`, want: false},
+		{filter: `active gt false`, want: false},
+		{filter: `active eq "true"`, want: false},
+		{filter: `loginCount gt 40 and loginCount le 42`, want: true},
+		{filter: `loginCount eq "42"`, want: false},
+		{filter: `meta.lastModified gt "2011-05-13T06:42:34+03:00"`, want: true},
+		{filter: `nickName pr`, want: false},
+		{filter: `nickName eq null`, want: false},
+		{filter: `emails[type eq "other"]`, want: false},
+		{filter: `emails.primary eq true`, want: true},
+		{filter: `name.middleName pr`, want: false},
+		{filter: `title.sub pr`, want: false},
+		{filter: `emails pr`, want: true},
+		{filter: `userName eq "a \"quoted\" name"`, want: false},
+
+		// The enterprise extension
+		{filter: `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber eq "701984"`, want: true},
+		{filter: `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value pr`, want: true},
+		{filter: `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:costCenter pr`, want: false},
+		{filter: `urn:example:params:scim:schemas:extension:other:2.0:User:employeeNumber pr`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter: %v", err)
+			}
+			if got := f.Match(user); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`   `,
+		`userName`,
+		`userName eq`,
+		`userName eq "bjensen`,
+		`userName eq "bad \x escape"`,
+		`userName eq bjensen`,
+		`userName is "bjensen"`,
+		`userName eq "bjensen" and`,
+		`userName eq "bjensen" or or title pr`,
+		`userName eq "bjensen" title pr`,
+		`eq "bjensen"`,
+		`"bjensen" eq userName`,
+		`(userName eq "bjensen"`,
+		`userName eq "bjensen")`,
+		`()`,
+		`not userName eq "bjensen"`,
+		`not (userName eq "bjensen"`,
+		`emails[type eq "work"`,
+		`emails[type eq "work"]]`,
+		`emails[]`,
+		`emails[type eq "home"].value eq "babs@jensen.org"`,
+		`emails]`,
+		`[type eq "work"]`,
+		`name..familyName pr`,
+		`name.familyName.x pr`,
+		`.familyName pr`,
+		`urn: pr`,
+		`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User: pr`,
+		`userName eq (`,
+		`userName eq ]`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			if f, err := ParseFilter(filter); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter = %v, %v, want an invalid filter error", f, err)
+			}
+		})
+	}
+}
+
+func FuzzParseFilter(f *testing.F) {
+	for _, seed := range []string{
+		`userName eq "bjensen"`,
+		`title pr and userType eq "Employee"`,
+		`userType ne "Employee" and not (emails co "example.com" or emails.value co "example.org")`,
+		`emails[type eq "work" and value co "@example.com"] or ims[type eq "xmpp"]`,
+		`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value pr`,
+		`loginCount gt 4.2e1`, `((((`, `not (`, `a[b[c eq 1]]`, `"é" eq`,
+	} {
+		f.Add(seed)
+	}
+
+	user := decode(f, bjensen)
+	f.Fuzz(func(t *testing.T, filter string) {
+		if match, err := ParseFilter(filter); err == nil {
+			match.Match(user)
+			match.Match(map[string]any{})
+		} else if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ParseFilter(%q): %v is not an invalid filter error", filter, err)
+		}
+	})
+}
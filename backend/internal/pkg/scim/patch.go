@@ -0,0 +1,290 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// patchPath is the target of a PATCH operation: an attribute path with an
+// optional filter selecting items of a multi-valued attribute, as in
+// emails[type eq "work"].value
+type patchPath struct {
+	attrPath
+	filter Filter
+}
+
+// parsePatchPath parses attr[.sub] or attr[filter][.sub], with an optional
+// schema URN prefix
+func parsePatchPath(s string) (patchPath, error) {
+	open := strings.Index(s, "[")
+	if open < 0 {
+		path, err := parseAttrPath(s)
+		if err != nil {
+			return patchPath{}, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+		}
+		return patchPath{attrPath: path}, nil
+	}
+
+	end := strings.LastIndex(s, "]")
+	if end < open {
+		return patchPath{}, fmt.Errorf("%w: unbalanced brackets in %q", ErrInvalidPath, s)
+	}
+	path, err := parseAttrPath(s[:open])
+	if err != nil || path.sub != "" {
+		return patchPath{}, fmt.Errorf("%w: bad attribute in %q", ErrInvalidPath, s)
+	}
+	filter, err := ParseFilter(s[open+1 : end])
+	if err != nil {
+		return patchPath{}, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	if rest := s[end+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") || strings.ContainsAny(rest[1:], ".[]:") || len(rest) == 1 {
+			return patchPath{}, fmt.Errorf("%w: bad sub-attribute in %q", ErrInvalidPath, s)
+		}
+		path.sub = strings.ToLower(rest[1:])
+	}
+	return patchPath{attrPath: path, filter: filter}, nil
+}
+
+// Patch applies PATCH operations in order to a resource in map form. Values
+// of operations without a path are objects whose keys are paths, so
+// {"name.givenName": "Ann"} works as well as {"name": {"givenName": "Ann"}}.
+// A remove with a value on a multi-valued attribute removes the items with
+// the given values, as some providers send to remove group members.
+func Patch(resource map[string]any, ops []PatchOperation) error {
+	for _, op := range ops {
+		kind := strings.ToLower(op.Op)
+		switch kind {
+		case "add", "replace", "remove":
+		default:
+			return fmt.Errorf("%w: unknown op %q", ErrInvalidSyntax, op.Op)
+		}
+
+		var value any
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+			}
+			value = lowerKeys(value)
+		}
+
+		if op.Path != "" {
+			path, err := parsePatchPath(op.Path)
+			if err != nil {
+				return err
+			}
+			if err := apply(resource, kind, path, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if kind == "remove" {
+			return fmt.Errorf("%w: remove needs a path", ErrNoTarget)
+		}
+		if err := applyObject(resource, kind, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyObject applies an add or replace without a path
+func applyObject(resource map[string]any, kind string, value any) error {
+	values, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: an operation without a path needs an object value", ErrInvalidValue)
+	}
+	for key, v := range values {
+		// The attributes of an extension, keyed by its URN
+		if sub, ok := v.(map[string]any); ok && isSchemaKey(key) {
+			ext, _ := resource[key].(map[string]any)
+			if ext == nil {
+				ext = map[string]any{}
+				resource[key] = ext
+			}
+			if err := applyObject(ext, kind, sub); err != nil {
+				return err
+			}
+			continue
+		}
+
+		path, err := parsePatchPath(key)
+		if err != nil {
+			return err
+		}
+		if err := apply(resource, kind, path, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSchemaKey reports whether a key is the URN of an extension rather than
+// a URN-prefixed attribute
+func isSchemaKey(key string) bool {
+	return strings.EqualFold(key, SchemaEnterpriseUser)
+}
+
+// apply applies one operation to its path
+func apply(resource map[string]any, kind string, path patchPath, value any) error {
+	container := path.container(resource)
+	if container == nil {
+		if kind == "remove" {
+			return nil
+		}
+		container = map[string]any{}
+		resource[path.schema] = container
+	}
+	current := container[path.attr]
+
+	if path.filter != nil {
+		items, _ := current.([]any)
+		var matched []int
+		for i, item := range items {
+			if m, ok := item.(map[string]any); ok && path.filter.Match(m) {
+				matched = append(matched, i)
+			}
+		}
+		if len(matched) == 0 {
+			if kind == "remove" {
+				return nil
+			}
+			return fmt.Errorf("%w: no %s matches the filter", ErrNoTarget, path.attr)
+		}
+
+		if kind == "remove" && path.sub == "" {
+			kept := items[:0:0]
+			for i, item := range items {
+				if !slices.Contains(matched, i) {
+					kept = append(kept, item)
+				}
+			}
+			container[path.attr] = kept
+			return nil
+		}
+		for _, i := range matched {
+			item := items[i].(map[string]any)
+			switch {
+			case kind == "remove":
+				delete(item, path.sub)
+			case path.sub != "":
+				item[path.sub] = value
+			default:
+				merged, err := merge(item, value)
+				if err != nil {
+					return err
+				}
+				items[i] = merged
+			}
+		}
+		return nil
+	}
+
+	if path.sub != "" {
+		switch current := current.(type) {
+		case []any:
+			// A sub-attribute of every item
+			for _, item := range current {
+				if m, ok := item.(map[string]any); ok {
+					if kind == "remove" {
+						delete(m, path.sub)
+					} else {
+						m[path.sub] = value
+					}
+				}
+			}
+		case map[string]any:
+			if kind == "remove" {
+				delete(current, path.sub)
+			} else {
+				current[path.sub] = value
+			}
+		case nil:
+			if kind != "remove" {
+				container[path.attr] = map[string]any{path.sub: value}
+			}
+		default:
+			return fmt.Errorf("%w: %s has no sub-attributes", ErrInvalidPath, path.attr)
+		}
+		return nil
+	}
+
+	items, multi := current.([]any)
+	switch {
+	case kind == "remove" && multi && value != nil:
+		removed, ok := value.([]any)
+		if !ok {
+			removed = []any{value}
+		}
+		kept := items[:0:0]
+		for _, item := range items {
+			if !containsValue(removed, item) {
+				kept = append(kept, item)
+			}
+		}
+		container[path.attr] = kept
+	case kind == "remove":
+		delete(container, path.attr)
+	case kind == "add" && multi:
+		added, ok := value.([]any)
+		if !ok {
+			added = []any{value}
+		}
+		for _, item := range added {
+			if !containsValue(items, item) {
+				items = append(items, item)
+			}
+		}
+		container[path.attr] = items
+	default:
+		// Sub-attributes of a complex attribute are merged; anything else,
+		// including null, replaces it
+		m, isComplex := current.(map[string]any)
+		if _, ok := value.(map[string]any); ok && isComplex {
+			merged, err := merge(m, value)
+			if err != nil {
+				return err
+			}
+			container[path.attr] = merged
+			return nil
+		}
+		container[path.attr] = value
+	}
+	return nil
+}
+
+// merge sets the sub-attributes given in value on a complex attribute
+func merge(m map[string]any, value any) (map[string]any, error) {
+	sub, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected an object", ErrInvalidValue)
+	}
+	for key, v := range sub {
+		m[key] = v
+	}
+	return m, nil
+}
+
+// containsValue reports whether items hold the item, comparing the value
+// sub-attribute of complex items
+func containsValue(items []any, item any) bool {
+	for _, existing := range items {
+		if sameValue(existing, item) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameValue(a, b any) bool {
+	ma, okA := a.(map[string]any)
+	mb, okB := b.(map[string]any)
+	if okA && okB && ma["value"] != nil {
+		return reflect.DeepEqual(ma["value"], mb["value"])
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,357 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// group is a group with two members, as in the examples of RFC 7644,
+// section 3.5.2
+const group = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+	"id": "acbf3ae7-8463-4692-b4fd-9b4da3f908ce",
+	"displayName": "Tour Guides",
+	"members": [
+		{"value": "2819c223-7f76-453a-919d-413861904646", "display": "Babs Jensen"},
+		{"value": "902c246b-6245-4190-8e05-00816be7344a", "display": "Mandy Pepperidge"}
+	]
+}`
+
+// user is a user with multi-valued, complex and extension attributes
+const user = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+	"userName": "bjensen",
+	"name": {"familyName": "Jensen", "givenName": "Barbara"},
+	"emails": [
+		{"value": "bjensen@example.com", "type": "work", "primary": true},
+		{"value": "babs@jensen.org", "type": "home"}
+	],
+	"addresses": [
+		{"type": "work", "streetAddress": "100 Universal City Plaza", "locality": "Hollywood"},
+		{"type": "home", "streetAddress": "456 Hollywood Blvd", "locality": "Hollywood"}
+	],
+	"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"employeeNumber": "701984"}
+}`
+
+// ops builds PATCH operations from the JSON form of a request's Operations
+func ops(t *testing.T, data string) []PatchOperation {
+	t.Helper()
+
+	var operations []PatchOperation
+	if err := json.Unmarshal([]byte(data), &operations); err != nil {
+		t.Fatalf("decode operations %s: %v", data, err)
+	}
+	return operations
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		ops      string
+		want     string
+	}{
+		// The examples of RFC 7644, section 3.5.2
+		{
+			name:     "add members",
+			resource: group,
+			ops:      `[{"op": "add", "path": "members", "value": [{"display": "Babs Jensen", "value": "2819c223-7f76-453a-919d-413861904646"}, {"display": "James Smith", "value": "08e1d05d-121c-4561-8b96-473d93df9210"}]}]`,
+			want: `{
+				"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+				"id": "acbf3ae7-8463-4692-b4fd-9b4da3f908ce",
+				"displayName": "Tour Guides",
+				"members": [
+					{"value": "2819c223-7f76-453a-919d-413861904646", "display": "Babs Jensen"},
+					{"value": "902c246b-6245-4190-8e05-00816be7344a", "display": "Mandy Pepperidge"},
+					{"value": "08e1d05d-121c-4561-8b96-473d93df9210", "display": "James Smith"}
+				]
+			}`,
+		},
+		{
+			name:     "add without a path",
+			resource: `{"userName": "bjensen", "emails": [{"value": "bjensen@example.com", "type": "work"}]}`,
+			ops:      `[{"op": "add", "value": {"emails": [{"value": "babs@jensen.org", "type": "home"}], "nickname": "Babs"}}]`,
+			want: `{
+				"userName": "bjensen",
+				"nickName": "Babs",
+				"emails": [{"value": "bjensen@example.com", "type": "work"}, {"value": "babs@jensen.org", "type": "home"}]
+			}`,
+		},
+		{
+			name:     "remove all members",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members"}]`,
+			want:     `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "acbf3ae7-8463-4692-b4fd-9b4da3f908ce", "displayName": "Tour Guides"}`,
+		},
+		{
+			name:     "remove a single member",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members[value eq \"2819c223-7f76-453a-919d-413861904646\"]"}]`,
+			want: `{
+				"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+				"id": "acbf3ae7-8463-4692-b4fd-9b4da3f908ce",
+				"displayName": "Tour Guides",
+				"members": [{"value": "902c246b-6245-4190-8e05-00816be7344a", "display": "Mandy Pepperidge"}]
+			}`,
+		},
+		{
+			name:     "remove all members and add a member",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members"}, {"op": "add", "path": "members", "value": [{"display": "James Smith", "value": "08e1d05d-121c-4561-8b96-473d93df9210"}]}]`,
+			want: `{
+				"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+				"id": "acbf3ae7-8463-4692-b4fd-9b4da3f908ce",
+				"displayName": "Tour Guides",
+				"members": [{"value": "08e1d05d-121c-4561-8b96-473d93df9210", "display": "James Smith"}]
+			}`,
+		},
+		{
+			name:     "replace all members",
+			resource: group,
+			ops:      `[{"op": "replace", "path": "members", "value": [{"display": "James Smith", "value": "08e1d05d-121c-4561-8b96-473d93df9210"}]}]`,
+			want: `{
+				"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+				"id": "acbf3ae7-8463-4692-b4fd-9b4da3f908ce",
+				"displayName": "Tour Guides",
+				"members": [{"value": "08e1d05d-121c-4561-8b96-473d93df9210", "display": "James Smith"}]
+			}`,
+		},
+		{
+			name:     "replace a filtered item",
+			resource: user,
+			ops:      `[{"op": "replace", "path": "addresses[type eq \"work\"]", "value": {"streetAddress": "911 Universal City Plaza", "postalCode": "91608"}}]`,
+			want: `{
+				"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+				"userName": "bjensen",
+				"name": {"familyName": "Jensen", "givenName": "Barbara"},
+				"emails": [
+					{"value": "bjensen@example.com", "type": "work", "primary": true},
+					{"value": "babs@jensen.org", "type": "home"}
+				],
+				"addresses": [
+					{"type": "work", "streetAddress": "911 Universal City Plaza", "locality": "Hollywood", "postalCode": "91608"},
+					{"type": "home", "streetAddress": "456 Hollywood Blvd", "locality": "Hollywood"}
+				],
+				"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"employeeNumber": "701984"}
+			}`,
+		},
+		{
+			name:     "replace a sub-attribute of a filtered item",
+			resource: user,
+			ops:      `[{"op": "replace", "path": "addresses[type eq \"work\"].streetAddress", "value": "1010 Broadway Ave"}]`,
+			want: `{
+				"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+				"userName": "bjensen",
+				"name": {"familyName": "Jensen", "givenName": "Barbara"},
+				"emails": [
+					{"value": "bjensen@example.com", "type": "work", "primary": true},
+					{"value": "babs@jensen.org", "type": "home"}
+				],
+				"addresses": [
+					{"type": "work", "streetAddress": "1010 Broadway Ave", "locality": "Hollywood"},
+					{"type": "home", "streetAddress": "456 Hollywood Blvd", "locality": "Hollywood"}
+				],
+				"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"employeeNumber": "701984"}
+			}`,
+		},
+		{
+			name:     "replace without a path",
+			resource: `{"userName": "bjensen", "nickName": "Barbie", "emails": [{"value": "bjensen@example.com", "type": "work"}]}`,
+			ops:      `[{"op": "replace", "value": {"emails": [{"value": "bjensen@example.com", "type": "work", "primary": true}], "nickname": "Babs"}}]`,
+			want:     `{"userName": "bjensen", "nickName": "Babs", "emails": [{"value": "bjensen@example.com", "type": "work", "primary": true}]}`,
+		},
+
+		// Paths, case and extensions
+		{
+			name:     "case-insensitive op and path",
+			resource: `{"userName": "bjensen"}`,
+			ops:      `[{"op": "Replace", "path": "USERNAME", "value": "babs"}]`,
+			want:     `{"userName": "babs"}`,
+		},
+		{
+			name:     "core schema prefix",
+			resource: `{"userName": "bjensen"}`,
+			ops:      `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:userName", "value": "babs"}]`,
+			want:     `{"userName": "babs"}`,
+		},
+		{
+			name:     "sub-attribute of a complex attribute",
+			resource: `{"name": {"familyName": "Jensen", "givenName": "Barbara"}}`,
+			ops:      `[{"op": "replace", "path": "name.givenName", "value": "Babs"}]`,
+			want:     `{"name": {"familyName": "Jensen", "givenName": "Babs"}}`,
+		},
+		{
+			name:     "sub-attribute of a missing attribute",
+			resource: `{}`,
+			ops:      `[{"op": "add", "path": "name.givenName", "value": "Babs"}]`,
+			want:     `{"name": {"givenName": "Babs"}}`,
+		},
+		{
+			name:     "complex attribute merged",
+			resource: `{"name": {"familyName": "Jensen", "givenName": "Barbara"}}`,
+			ops:      `[{"op": "replace", "path": "name", "value": {"givenName": "Babs"}}]`,
+			want:     `{"name": {"familyName": "Jensen", "givenName": "Babs"}}`,
+		},
+		{
+			name:     "dotted keys without a path",
+			resource: `{"name": {"familyName": "Jensen", "givenName": "Barbara"}}`,
+			ops:      `[{"op": "replace", "value": {"name.givenName": "Babs", "active": false}}]`,
+			want:     `{"name": {"familyName": "Jensen", "givenName": "Babs"}, "active": false}`,
+		},
+		{
+			name:     "sub-attribute of every item",
+			resource: `{"emails": [{"value": "a@example.com", "primary": true}, {"value": "b@example.com"}]}`,
+			ops:      `[{"op": "replace", "path": "emails.primary", "value": false}]`,
+			want:     `{"emails": [{"value": "a@example.com", "primary": false}, {"value": "b@example.com", "primary": false}]}`,
+		},
+		{
+			name:     "remove a sub-attribute of a filtered item",
+			resource: `{"emails": [{"value": "a@example.com", "type": "work", "primary": true}]}`,
+			ops:      `[{"op": "remove", "path": "emails[type eq \"work\"].primary"}]`,
+			want:     `{"emails": [{"value": "a@example.com", "type": "work"}]}`,
+		},
+		{
+			name:     "remove members by value",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members", "value": [{"value": "2819c223-7f76-453a-919d-413861904646"}]}]`,
+			want: `{
+				"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+				"id": "acbf3ae7-8463-4692-b4fd-9b4da3f908ce",
+				"displayName": "Tour Guides",
+				"members": [{"value": "902c246b-6245-4190-8e05-00816be7344a", "display": "Mandy Pepperidge"}]
+			}`,
+		},
+		{
+			name:     "remove what is not there",
+			resource: `{"userName": "bjensen", "emails": [{"value": "a@example.com", "type": "work"}]}`,
+			ops: `[
+				{"op": "remove", "path": "nickName"},
+				{"op": "remove", "path": "emails[type eq \"home\"]"},
+				{"op": "remove", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager"}
+			]`,
+			want: `{"userName": "bjensen", "emails": [{"value": "a@example.com", "type": "work"}]}`,
+		},
+		{
+			name:     "replace with null",
+			resource: `{"userName": "bjensen", "nickName": "Babs"}`,
+			ops:      `[{"op": "replace", "path": "nickName", "value": null}]`,
+			want:     `{"userName": "bjensen", "nickName": null}`,
+		},
+		{
+			name:     "extension attribute by path",
+			resource: `{}`,
+			ops:      `[{"op": "add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager", "value": {"value": "26118915"}}]`,
+			want:     `{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"manager": {"value": "26118915"}}}`,
+		},
+		{
+			name:     "extension attributes without a path",
+			resource: `{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"employeeNumber": "701984"}}`,
+			ops:      `[{"op": "replace", "value": {"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "Tours", "manager.value": "26118915"}}}]`,
+			want:     `{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"employeeNumber": "701984", "department": "Tours", "manager": {"value": "26118915"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := decode(t, tt.resource)
+			if err := Patch(resource, ops(t, tt.ops)); err != nil {
+				t.Fatalf("Patch: %v", err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(resource, want) {
+				got, _ := json.Marshal(resource)
+				t.Errorf("patched to %s", got)
+			}
+		})
+	}
+}
+
+func TestPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		ops      string
+		want     error
+	}{
+		{name: "unknown op", ops: `[{"op": "move", "path": "userName", "value": "babs"}]`, want: ErrInvalidSyntax},
+		{name: "missing op", ops: `[{"path": "userName", "value": "babs"}]`, want: ErrInvalidSyntax},
+		{name: "remove without a path", ops: `[{"op": "remove", "value": {"nickName": "Babs"}}]`, want: ErrNoTarget},
+		{name: "no path and no value", ops: `[{"op": "add"}]`, want: ErrInvalidValue},
+		{name: "no path and a string value", ops: `[{"op": "replace", "value": "Babs"}]`, want: ErrInvalidValue},
+		{name: "no path and an array value", ops: `[{"op": "add", "value": [{"nickName": "Babs"}]}]`, want: ErrInvalidValue},
+		{name: "empty path segment", ops: `[{"op": "add", "path": "name..givenName", "value": "Babs"}]`, want: ErrInvalidPath},
+		{name: "too deep", ops: `[{"op": "add", "path": "name.givenName.first", "value": "Babs"}]`, want: ErrInvalidPath},
+		{name: "empty schema attribute", ops: `[{"op": "add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:", "value": "x"}]`, want: ErrInvalidPath},
+		{name: "unclosed filter", ops: `[{"op": "remove", "path": "members[value eq \"1\""}]`, want: ErrInvalidPath},
+		{name: "brackets reversed", ops: `[{"op": "remove", "path": "members]value eq \"1\"["}]`, want: ErrInvalidPath},
+		{name: "empty filter", ops: `[{"op": "remove", "path": "members[]"}]`, want: ErrInvalidPath},
+		{name: "bad filter", ops: `[{"op": "remove", "path": "members[value is 1]"}]`, want: ErrInvalidPath},
+		{name: "filter on a sub-attribute", ops: `[{"op": "remove", "path": "name.x[value eq 1]"}]`, want: ErrInvalidPath},
+		{name: "dot after the filter only", ops: `[{"op": "remove", "path": "emails[type eq \"work\"]."}]`, want: ErrInvalidPath},
+		{name: "text after the filter", ops: `[{"op": "remove", "path": "emails[type eq \"work\"]value"}]`, want: ErrInvalidPath},
+		{name: "nested sub-attribute after the filter", ops: `[{"op": "remove", "path": "emails[type eq \"work\"].a.b"}]`, want: ErrInvalidPath},
+		{name: "bad key without a path", ops: `[{"op": "add", "value": {"name..givenName": "Babs"}}]`, want: ErrInvalidPath},
+		{
+			name:     "filter matches nothing",
+			resource: `{"emails": [{"value": "a@example.com", "type": "work"}]}`,
+			ops:      `[{"op": "replace", "path": "emails[type eq \"home\"].value", "value": "b@example.com"}]`,
+			want:     ErrNoTarget,
+		},
+		{
+			name:     "sub-attribute of a string",
+			resource: `{"userName": "bjensen"}`,
+			ops:      `[{"op": "replace", "path": "userName.first", "value": "b"}]`,
+			want:     ErrInvalidPath,
+		},
+		{
+			name:     "filtered item merged with a string",
+			resource: `{"emails": [{"value": "a@example.com", "type": "work"}]}`,
+			ops:      `[{"op": "replace", "path": "emails[type eq \"work\"]", "value": "b@example.com"}]`,
+			want:     ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.resource == "" {
+				tt.resource = user
+			}
+			if err := Patch(decode(t, tt.resource), ops(t, tt.ops)); !errors.Is(err, tt.want) {
+				t.Errorf("Patch: %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// A value that is not JSON never comes out of a decoded request, but
+	// operations can be built by hand
+	op := PatchOperation{Op: "add", Path: "nickName", Value: json.RawMessage(`{"broken`)}
+	if err := Patch(decode(t, user), []PatchOperation{op}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Patch with a broken value: %v", err)
+	}
+}
+
+func FuzzPatch(f *testing.F) {
+	for _, seed := range []struct{ op, path, value string }{
+		{"add", "members", `[{"value": "1"}]`},
+		{"remove", `members[value eq "2819c223-7f76-453a-919d-413861904646"]`, ``},
+		{"replace", `addresses[type eq "work"].streetAddress`, `"1010 Broadway Ave"`},
+		{"replace", ``, `{"name.givenName": "Babs", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"manager": 1}}`},
+		{"add", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value`, `"26118915"`},
+		{"remove", `emails`, `{"value": "babs@jensen.org"}`},
+		{"add", `name.givenName`, `null`},
+		{"replace", `emails[type eq "work"]`, `[1, 2]`},
+		{"add", `][`, `{`},
+	} {
+		f.Add(seed.op, seed.path, seed.value)
+	}
+
+	f.Fuzz(func(t *testing.T, op, path, value string) {
+		for _, resource := range []string{user, group, `{}`} {
+			operation := PatchOperation{Op: op, Path: path, Value: json.RawMessage(value)}
+			if err := Patch(decode(t, resource), []PatchOperation{operation}); err != nil && ErrorType(err) == "" {
+				t.Errorf("Patch(%+v): %v is not a SCIM error", operation, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,256 @@
+// Package scim implements the parts of SCIM 2.0 (RFC 7643, RFC 7644) needed
+// to provision users and groups: the resource representations, filters and
+// PATCH operations.
+//
+// Filters and patches work on the JSON form of a resource decoded into a
+// map with lowercased keys, since attribute names are case-insensitive.
+// Attributes of the core schema may be prefixed with its URN; extension
+// attributes are nested under the extension's URN as in the JSON form.
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schema URNs
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaEnterpriseUser        = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// MediaType is the content type of SCIM requests and responses
+const MediaType = "application/scim+json"
+
+// Errors of malformed requests, named after the scimType they are reported
+// with
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidPath   = errors.New("invalid path")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrInvalidSyntax = errors.New("invalid syntax")
+	ErrNoTarget      = errors.New("path matches nothing")
+	ErrMutability    = errors.New("attribute cannot be changed")
+)
+
+// ErrorType returns the scimType of an error of this package, or "" for
+// other errors
+func ErrorType(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidFilter):
+		return "invalidFilter"
+	case errors.Is(err, ErrInvalidPath):
+		return "invalidPath"
+	case errors.Is(err, ErrInvalidValue):
+		return "invalidValue"
+	case errors.Is(err, ErrInvalidSyntax):
+		return "invalidSyntax"
+	case errors.Is(err, ErrNoTarget):
+		return "noTarget"
+	case errors.Is(err, ErrMutability):
+		return "mutability"
+	}
+	return ""
+}
+
+// Error is the body of an error response
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// NewError builds an error response
+func NewError(status int, scimType, detail string) Error {
+	return Error{Schemas: []string{SchemaError}, Status: fmt.Sprint(status), ScimType: scimType, Detail: detail}
+}
+
+// Meta describes a resource
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created,omitzero"`
+	LastModified time.Time `json:"lastModified,omitzero"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// Name is the name of a user
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an item of a multi-valued attribute such as emails
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Manager is the manager of a user in the enterprise extension. It also
+// decodes from a bare ID, which some providers send.
+type Manager struct {
+	Value       string `json:"value"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+func (m *Manager) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*m = Manager{Value: id}
+		return nil
+	}
+	type plain Manager
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+// EnterpriseUser holds the attributes of the enterprise extension
+type EnterpriseUser struct {
+	Department string   `json:"department,omitempty"`
+	Manager    *Manager `json:"manager,omitempty"`
+}
+
+// Boolean is a boolean that also decodes from "true" and "false" strings,
+// which some providers send
+type Boolean bool
+
+func (b *Boolean) UnmarshalJSON(data []byte) error {
+	var v bool
+	if err := json.Unmarshal(data, &v); err == nil {
+		*b = Boolean(v)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s is not a boolean", ErrInvalidValue, data)
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		*b = true
+	case "false":
+		*b = false
+	default:
+		return fmt.Errorf("%w: %q is not a boolean", ErrInvalidValue, s)
+	}
+	return nil
+}
+
+// User is a user resource
+type User struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *Name           `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	Emails      []MultiValue    `json:"emails,omitempty"`
+	Active      *Boolean        `json:"active,omitempty"`
+	Password    string          `json:"password,omitempty"` // write-only
+	Groups      []MultiValue    `json:"groups,omitempty"`   // read-only
+	Enterprise  *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *Meta           `json:"meta,omitempty"`
+}
+
+// Group is a group resource
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// ListResponse is a page of query results
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+// NewListResponse pages results; startIndex is 1-based and count caps the
+// page size
+func NewListResponse[T any](results []T, startIndex, count int) *ListResponse[T] {
+	page := []T{}
+	if startIndex <= len(results) && count > 0 {
+		end := min(startIndex-1+count, len(results))
+		page = results[startIndex-1 : end]
+	}
+	return &ListResponse[T]{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(results),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// PatchRequest is the body of a PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is one add, replace or remove operation
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ToMap converts a resource to the form filters and patches work on
+func ToMap(resource any) (map[string]any, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return lowerKeys(m).(map[string]any), nil
+}
+
+// FromMap converts a map back to a resource; keys are matched ignoring case
+func FromMap(m map[string]any, resource any) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, resource); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+	return nil
+}
+
+// lowerKeys lowercases the keys of maps at any depth
+func lowerKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[strings.ToLower(key)] = lowerKeys(value)
+		}
+		return m
+	case []any:
+		for i := range v {
+			v[i] = lowerKeys(v[i])
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type approvalRecord struct {
+	decision domain.ApprovalDecision
+	tenant   string
+	seq      int64
+}
+
+type ApprovalRepository struct {
+	store *Store
+}
+
+func NewApprovalRepository(store *Store) *ApprovalRepository {
+	return &ApprovalRepository{store: store}
+}
+
+// Create inserts a new approval decision
+func (r *ApprovalRepository) Create(ctx context.Context, decision *domain.ApprovalDecision) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.requests[decision.RequestID]; !ok {
+		return fmt.Errorf("failed to create approval decision: %w", ErrForeignKeyViolation)
+	}
+	if decision.DeciderID != nil {
+		if _, ok := r.store.users[*decision.DeciderID]; !ok {
+			return fmt.Errorf("failed to create approval decision: %w", ErrForeignKeyViolation)
+		}
+	}
+	if !decision.Stage.IsValid() || !decision.Decision.IsValid() {
+		return fmt.Errorf("failed to create approval decision: %w", ErrCheckViolation)
+	}
+
+	decision.ID = newID()
+	decision.CreatedAt = now()
+
+	rec := &approvalRecord{decision: cloneDecision(decision), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	rec.decision.DeciderName = ""
+	r.store.approvals[decision.ID] = rec
+	return nil
+}
+
+// GetByRequestID retrieves the decisions taken on a request, oldest first
+func (r *ApprovalRepository) GetByRequestID(ctx context.Context, requestID string) ([]*domain.ApprovalDecision, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*approvalRecord
+	for _, rec := range r.store.approvals {
+		if rec.tenant == tenant && rec.decision.RequestID == requestID {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return newerFirst(records[j].decision.CreatedAt, records[j].seq, records[i].decision.CreatedAt, records[i].seq)
+	})
+
+	decisions := make([]*domain.ApprovalDecision, 0, len(records))
+	for _, rec := range records {
+		decision := cloneDecision(&rec.decision)
+		if decision.DeciderID != nil {
+			if u, ok := r.store.users[*decision.DeciderID]; ok {
+				decision.DeciderName = u.user.Name
+			}
+		}
+		decisions = append(decisions, &decision)
+	}
+	return decisions, nil
+}
+
+// cloneDecision copies a decision so callers cannot mutate stored state
+func cloneDecision(d *domain.ApprovalDecision) domain.ApprovalDecision {
+	c := *d
+	c.DeciderID = cloneString(d.DeciderID)
+	c.Comment = cloneString(d.Comment)
+	return c
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type attachmentRecord struct {
+	attachment domain.Attachment
+	tenant     string
+	seq        int64
+}
+
+type AttachmentRepository struct {
+	store *Store
+}
+
+func NewAttachmentRepository(store *Store) *AttachmentRepository {
+	return &AttachmentRepository{store: store}
+}
+
+// Create inserts the metadata of an uploaded file
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if (attachment.LearningID == nil) == (attachment.CommentID == nil) {
+		return fmt.Errorf("failed to create attachment: %w", ErrCheckViolation)
+	}
+	if attachment.PlanItemID != nil && attachment.LearningID == nil {
+		return fmt.Errorf("failed to create attachment: %w", ErrCheckViolation)
+	}
+	if attachment.Size < 0 {
+		return fmt.Errorf("failed to create attachment: %w", ErrCheckViolation)
+	}
+	if attachment.LearningID != nil {
+		if _, ok := r.store.learnings[*attachment.LearningID]; !ok {
+			return fmt.Errorf("failed to create attachment: %w", ErrForeignKeyViolation)
+		}
+	}
+	if attachment.CommentID != nil {
+		if _, ok := r.store.comments[*attachment.CommentID]; !ok {
+			return fmt.Errorf("failed to create attachment: %w", ErrForeignKeyViolation)
+		}
+	}
+	if attachment.UploaderID != nil {
+		if _, ok := r.store.users[*attachment.UploaderID]; !ok {
+			return fmt.Errorf("failed to create attachment: %w", ErrForeignKeyViolation)
+		}
+	}
+	for _, rec := range r.store.attachments {
+		if rec.attachment.StorageKey == attachment.StorageKey {
+			return fmt.Errorf("failed to create attachment: %w", ErrDuplicateKey)
+		}
+	}
+
+	attachment.ID = newID()
+	attachment.CreatedAt = now()
+
+	rec := &attachmentRecord{attachment: cloneAttachment(attachment), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	rec.attachment.UploaderName = ""
+	r.store.attachments[attachment.ID] = rec
+	return nil
+}
+
+// GetByID retrieves an attachment by its ID
+func (r *AttachmentRepository) GetByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.attachments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return r.joinAttachment(rec), nil
+}
+
+// GetByLearningID retrieves the attachments of a learning and its plan
+// items, oldest first
+func (r *AttachmentRepository) GetByLearningID(ctx context.Context, learningID string) ([]*domain.Attachment, error) {
+	return r.list(ctx, func(a *domain.Attachment) bool {
+		return a.LearningID != nil && *a.LearningID == learningID
+	}), nil
+}
+
+// GetByCommentID retrieves the attachments of a comment, oldest first
+func (r *AttachmentRepository) GetByCommentID(ctx context.Context, commentID string) ([]*domain.Attachment, error) {
+	return r.list(ctx, func(a *domain.Attachment) bool {
+		return a.CommentID != nil && *a.CommentID == commentID
+	}), nil
+}
+
+// Delete removes the metadata of an attachment
+func (r *AttachmentRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.attachments[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrAttachmentNotFound
+	}
+	delete(r.store.attachments, id)
+	return nil
+}
+
+func (r *AttachmentRepository) list(ctx context.Context, match func(*domain.Attachment) bool) []*domain.Attachment {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*attachmentRecord
+	for _, rec := range r.store.attachments {
+		if rec.tenant == tenant && match(&rec.attachment) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return newerFirst(records[j].attachment.CreatedAt, records[j].seq, records[i].attachment.CreatedAt, records[i].seq)
+	})
+
+	attachments := make([]*domain.Attachment, 0, len(records))
+	for _, rec := range records {
+		attachments = append(attachments, r.joinAttachment(rec))
+	}
+	return attachments
+}
+
+// joinAttachment copies a stored attachment and resolves its uploader name;
+// caller holds the lock
+func (r *AttachmentRepository) joinAttachment(rec *attachmentRecord) *domain.Attachment {
+	attachment := cloneAttachment(&rec.attachment)
+	if attachment.UploaderID != nil {
+		if u, ok := r.store.users[*attachment.UploaderID]; ok {
+			attachment.UploaderName = u.user.Name
+		}
+	}
+	return &attachment
+}
+
+// attachmentOwnerExists checks that the learning or comment an attachment
+// belongs to is stored; caller holds the lock
+func (s *Store) attachmentOwnerExists(a *domain.Attachment) bool {
+	if a.LearningID != nil {
+		_, ok := s.learnings[*a.LearningID]
+		return ok
+	}
+	if a.CommentID != nil {
+		_, ok := s.comments[*a.CommentID]
+		return ok
+	}
+	return false
+}
+
+// cloneAttachment copies an attachment so callers cannot mutate stored state
+func cloneAttachment(a *domain.Attachment) domain.Attachment {
+	v := *a
+	v.LearningID = cloneString(a.LearningID)
+	v.PlanItemID = cloneString(a.PlanItemID)
+	v.CommentID = cloneString(a.CommentID)
+	v.UploaderID = cloneString(a.UploaderID)
+	return v
+}
@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type windowRecord struct {
+	window domain.AvailabilityWindow
+	tenant string
+	seq    int64
+}
+
+type absenceRecord struct {
+	absence domain.Absence
+	tenant  string
+	seq     int64
+}
+
+type AvailabilityRepository struct {
+	store *Store
+}
+
+func NewAvailabilityRepository(store *Store) *AvailabilityRepository {
+	return &AvailabilityRepository{store: store}
+}
+
+// CreateWindow inserts a new weekly availability window
+func (r *AvailabilityRepository) CreateWindow(ctx context.Context, window *domain.AvailabilityWindow) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.mentors[window.MentorID]; !ok {
+		return fmt.Errorf("failed to create availability window: %w", ErrForeignKeyViolation)
+	}
+	if window.Weekday < 0 || window.Weekday > 6 || window.StartTime >= window.EndTime {
+		return fmt.Errorf("failed to create availability window: %w", ErrCheckViolation)
+	}
+
+	window.ID = newID()
+	window.CreatedAt = now()
+
+	r.store.windows[window.ID] = &windowRecord{window: *window, tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetWindows retrieves the weekly windows of a mentor, ordered by weekday
+// and start time
+func (r *AvailabilityRepository) GetWindows(ctx context.Context, mentorID string) ([]*domain.AvailabilityWindow, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	windows := make([]*domain.AvailabilityWindow, 0)
+	for _, rec := range r.store.windows {
+		if rec.tenant == tenant && rec.window.MentorID == mentorID {
+			window := rec.window
+			windows = append(windows, &window)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].Weekday != windows[j].Weekday {
+			return windows[i].Weekday < windows[j].Weekday
+		}
+		return windows[i].StartTime < windows[j].StartTime
+	})
+
+	return windows, nil
+}
+
+// UpdateWindow changes the weekday and times of a window of the mentor
+func (r *AvailabilityRepository) UpdateWindow(ctx context.Context, window *domain.AvailabilityWindow) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.windows[window.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.window.MentorID != window.MentorID {
+		return domain.ErrWindowNotFound
+	}
+	if window.Weekday < 0 || window.Weekday > 6 || window.StartTime >= window.EndTime {
+		return fmt.Errorf("failed to update availability window: %w", ErrCheckViolation)
+	}
+
+	window.CreatedAt = rec.window.CreatedAt
+	rec.window = *window
+	return nil
+}
+
+// DeleteWindow removes a window of the mentor
+func (r *AvailabilityRepository) DeleteWindow(ctx context.Context, id, mentorID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.windows[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.window.MentorID != mentorID {
+		return domain.ErrWindowNotFound
+	}
+
+	delete(r.store.windows, id)
+	return nil
+}
+
+// CreateAbsence inserts a new absence period
+func (r *AvailabilityRepository) CreateAbsence(ctx context.Context, absence *domain.Absence) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.mentors[absence.MentorID]; !ok {
+		return fmt.Errorf("failed to create absence: %w", ErrForeignKeyViolation)
+	}
+	if !absence.Kind.IsValid() || !absence.StartsAt.Before(absence.EndsAt) {
+		return fmt.Errorf("failed to create absence: %w", ErrCheckViolation)
+	}
+
+	absence.ID = newID()
+	absence.CreatedAt = now()
+	absence.StartsAt = absence.StartsAt.Truncate(time.Microsecond)
+	absence.EndsAt = absence.EndsAt.Truncate(time.Microsecond)
+
+	r.store.absences[absence.ID] = &absenceRecord{absence: cloneAbsence(absence), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetAbsences retrieves absences matching the filter, ordered by start
+func (r *AvailabilityRepository) GetAbsences(ctx context.Context, filter domain.AbsenceFilter) ([]*domain.Absence, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*absenceRecord
+	for _, rec := range r.store.absences {
+		a := rec.absence
+		if rec.tenant != tenant {
+			continue
+		}
+		if filter.MentorID != "" && a.MentorID != filter.MentorID {
+			continue
+		}
+		if filter.From != nil && !a.EndsAt.After(*filter.From) {
+			continue
+		}
+		if filter.To != nil && a.StartsAt.After(*filter.To) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.absence.StartsAt.Equal(b.absence.StartsAt) {
+			return a.absence.StartsAt.Before(b.absence.StartsAt)
+		}
+		return a.seq < b.seq
+	})
+
+	absences := make([]*domain.Absence, 0, len(records))
+	for _, rec := range records {
+		absence := cloneAbsence(&rec.absence)
+		absences = append(absences, &absence)
+	}
+
+	return absences, nil
+}
+
+// UpdateAbsence changes the kind, period and note of an absence of the mentor
+func (r *AvailabilityRepository) UpdateAbsence(ctx context.Context, absence *domain.Absence) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.absences[absence.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.absence.MentorID != absence.MentorID {
+		return domain.ErrAbsenceNotFound
+	}
+	if !absence.Kind.IsValid() || !absence.StartsAt.Before(absence.EndsAt) {
+		return fmt.Errorf("failed to update absence: %w", ErrCheckViolation)
+	}
+
+	absence.CreatedAt = rec.absence.CreatedAt
+	absence.StartsAt = absence.StartsAt.Truncate(time.Microsecond)
+	absence.EndsAt = absence.EndsAt.Truncate(time.Microsecond)
+	rec.absence = cloneAbsence(absence)
+	return nil
+}
+
+// DeleteAbsence removes an absence of the mentor
+func (r *AvailabilityRepository) DeleteAbsence(ctx context.Context, id, mentorID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.absences[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.absence.MentorID != mentorID {
+		return domain.ErrAbsenceNotFound
+	}
+
+	delete(r.store.absences, id)
+	return nil
+}
+
+// cloneAbsence copies an absence so callers cannot mutate stored state
+func cloneAbsence(a *domain.Absence) domain.Absence {
+	c := *a
+	c.Note = cloneString(a.Note)
+	return c
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type certificateRecord struct {
+	certificate domain.Certificate
+	tenant      string
+	seq         int64
+}
+
+// CertificateRepository stores issued certificates
+type CertificateRepository struct {
+	store *Store
+}
+
+func NewCertificateRepository(store *Store) *CertificateRepository {
+	return &CertificateRepository{store: store}
+}
+
+// Create inserts a certificate; a learning gets at most one
+func (r *CertificateRepository) Create(ctx context.Context, certificate *domain.Certificate) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.learnings[certificate.LearningID]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrLearningNotFound
+	}
+	for _, rec := range r.store.certificates {
+		c := &rec.certificate
+		if c.LearningID == certificate.LearningID || c.Code == certificate.Code || c.StorageKey == certificate.StorageKey {
+			return domain.ErrCertificateExists
+		}
+	}
+
+	certificate.ID = newID()
+	certificate.StartDate = certificate.StartDate.Truncate(time.Microsecond)
+	certificate.EndDate = certificate.EndDate.Truncate(time.Microsecond)
+	certificate.IssuedAt = certificate.IssuedAt.Truncate(time.Microsecond)
+
+	r.store.certificates[certificate.ID] = &certificateRecord{certificate: cloneCertificate(certificate), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByLearningID retrieves the certificate of a learning
+func (r *CertificateRepository) GetByLearningID(ctx context.Context, learningID string) (*domain.Certificate, error) {
+	tenant := domain.TenantID(ctx)
+	return r.find(func(rec *certificateRecord) bool {
+		return rec.tenant == tenant && rec.certificate.LearningID == learningID
+	})
+}
+
+// GetByCode retrieves a certificate by its verification code. Codes are
+// unique across tenants and the verification link is public, so this is
+// the one lookup that ignores the tenant.
+func (r *CertificateRepository) GetByCode(ctx context.Context, code string) (*domain.Certificate, error) {
+	return r.find(func(rec *certificateRecord) bool { return rec.certificate.Code == code })
+}
+
+func (r *CertificateRepository) find(match func(*certificateRecord) bool) (*domain.Certificate, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, rec := range r.store.certificates {
+		if match(rec) {
+			certificate := cloneCertificate(&rec.certificate)
+			return &certificate, nil
+		}
+	}
+	return nil, domain.ErrCertificateNotFound
+}
+
+// cloneCertificate copies a certificate so callers cannot mutate stored
+// state
+func cloneCertificate(c *domain.Certificate) domain.Certificate {
+	v := *c
+	v.CompletedItems = append(make([]string, 0, len(c.CompletedItems)), c.CompletedItems...)
+	return v
+}
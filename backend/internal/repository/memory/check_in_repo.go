@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type checkInRecord struct {
+	checkIn domain.CheckIn
+	tenant  string
+	seq     int64
+}
+
+type stallAlertRecord struct {
+	notifiedAt time.Time
+}
+
+// CheckInRepository stores check-ins of active learnings and stall alerts
+type CheckInRepository struct {
+	store *Store
+}
+
+func NewCheckInRepository(store *Store) *CheckInRepository {
+	return &CheckInRepository{store: store}
+}
+
+// Create inserts an unanswered check-in; a side gets one per round
+func (r *CheckInRepository) Create(ctx context.Context, checkIn *domain.CheckIn) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.learnings[checkIn.LearningID]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrLearningNotFound
+	}
+	if _, ok := r.store.users[checkIn.UserID]; !ok {
+		return fmt.Errorf("failed to create check-in: %w", ErrForeignKeyViolation)
+	}
+	if checkIn.Round < 1 {
+		return fmt.Errorf("failed to create check-in: %w", ErrCheckViolation)
+	}
+	for _, rec := range r.store.checkIns {
+		c := &rec.checkIn
+		if c.LearningID == checkIn.LearningID && c.Role == checkIn.Role && c.Round == checkIn.Round {
+			return domain.ErrCheckInExists
+		}
+	}
+
+	checkIn.ID = newID()
+	checkIn.Pulse = ""
+	checkIn.Comment = ""
+	checkIn.AnsweredAt = nil
+	checkIn.CreatedAt = now()
+
+	r.store.checkIns[checkIn.ID] = &checkInRecord{checkIn: cloneCheckIn(checkIn), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a check-in by its ID
+func (r *CheckInRepository) GetByID(ctx context.Context, id string) (*domain.CheckIn, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.checkIns[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrCheckInNotFound
+	}
+	return r.store.joinCheckIn(rec), nil
+}
+
+// GetByLearningID retrieves the check-ins of a learning, newest first
+func (r *CheckInRepository) GetByLearningID(ctx context.Context, learningID string) ([]*domain.CheckIn, error) {
+	return r.list(ctx, func(c *domain.CheckIn) bool { return c.LearningID == learningID }), nil
+}
+
+// GetPending retrieves the unanswered check-ins of a user, newest first
+func (r *CheckInRepository) GetPending(ctx context.Context, userID string) ([]*domain.CheckIn, error) {
+	return r.list(ctx, func(c *domain.CheckIn) bool { return c.UserID == userID && !c.IsAnswered() }), nil
+}
+
+// Answer records the reply to an unanswered check-in
+func (r *CheckInRepository) Answer(ctx context.Context, id string, pulse domain.CheckInPulse, comment string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.checkIns[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrCheckInNotFound
+	}
+	if rec.checkIn.IsAnswered() {
+		return domain.ErrCheckInAnswered
+	}
+	if !pulse.IsValid() {
+		return fmt.Errorf("failed to answer check-in: %w", ErrCheckViolation)
+	}
+
+	answeredAt := now()
+	rec.checkIn.Pulse = pulse
+	rec.checkIn.Comment = comment
+	rec.checkIn.AnsweredAt = &answeredAt
+	return nil
+}
+
+// ClaimStallAlert records a stall alert unless one was recorded after since
+func (r *CheckInRepository) ClaimStallAlert(ctx context.Context, learningID string, since time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.learnings[learningID]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return false, domain.ErrLearningNotFound
+	}
+	if rec, ok := r.store.stallAlerts[learningID]; ok && !rec.notifiedAt.Before(since) {
+		return false, nil
+	}
+
+	r.store.stallAlerts[learningID] = &stallAlertRecord{notifiedAt: now()}
+	return true, nil
+}
+
+// list returns the matching check-ins newest first, joined with their topic
+func (r *CheckInRepository) list(ctx context.Context, match func(*domain.CheckIn) bool) []*domain.CheckIn {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*checkInRecord, 0)
+	for _, rec := range r.store.checkIns {
+		if rec.tenant == tenant && match(&rec.checkIn) {
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return newerFirst(recs[i].checkIn.CreatedAt, recs[i].seq, recs[j].checkIn.CreatedAt, recs[j].seq)
+	})
+
+	checkIns := make([]*domain.CheckIn, 0, len(recs))
+	for _, rec := range recs {
+		checkIns = append(checkIns, r.store.joinCheckIn(rec))
+	}
+	return checkIns
+}
+
+// joinCheckIn copies a check-in and fills the topic; caller holds the lock
+func (s *Store) joinCheckIn(rec *checkInRecord) *domain.CheckIn {
+	checkIn := cloneCheckIn(&rec.checkIn)
+	if l, ok := s.learnings[checkIn.LearningID]; ok {
+		if req, ok := s.requests[l.learning.RequestID]; ok {
+			checkIn.Topic = req.request.Topic
+		}
+	}
+	return &checkIn
+}
+
+// cloneCheckIn copies a check-in without joined fields
+func cloneCheckIn(c *domain.CheckIn) domain.CheckIn {
+	v := *c
+	v.AnsweredAt = cloneTime(c.AnsweredAt)
+	v.Topic = ""
+	return v
+}
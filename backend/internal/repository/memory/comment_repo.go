@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type commentRecord struct {
+	comment domain.Comment
+	tenant  string
+	seq     int64
+}
+
+type CommentRepository struct {
+	store *Store
+}
+
+func NewCommentRepository(store *Store) *CommentRepository {
+	return &CommentRepository{store: store}
+}
+
+// Create inserts a new comment
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !comment.TargetType.IsValid() {
+		return fmt.Errorf("%w: unknown comment target %q", domain.ErrInvalidInput, comment.TargetType)
+	}
+	if !r.store.targetExists(comment.TargetType, comment.TargetID) {
+		return fmt.Errorf("failed to create comment: %w", ErrForeignKeyViolation)
+	}
+	if comment.ParentID != nil {
+		if _, ok := r.store.comments[*comment.ParentID]; !ok {
+			return fmt.Errorf("failed to create comment: %w", ErrForeignKeyViolation)
+		}
+	}
+	if comment.AuthorID != nil {
+		if _, ok := r.store.users[*comment.AuthorID]; !ok {
+			return fmt.Errorf("failed to create comment: %w", ErrForeignKeyViolation)
+		}
+	}
+	if !comment.Visibility.IsValid() {
+		return fmt.Errorf("failed to create comment: %w", ErrCheckViolation)
+	}
+
+	comment.ID = newID()
+	comment.CreatedAt = now()
+	comment.EditedAt = nil
+	comment.DeletedAt = nil
+
+	rec := &commentRecord{comment: cloneComment(comment), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	rec.comment.AuthorName = ""
+	r.store.comments[comment.ID] = rec
+	return nil
+}
+
+// GetByID retrieves a comment by its ID
+func (r *CommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.comments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrCommentNotFound
+	}
+	return r.joinComment(rec), nil
+}
+
+// GetByTarget retrieves the thread of a request or learning, oldest first,
+// including deleted comments; internal comments only if asked for
+func (r *CommentRepository) GetByTarget(ctx context.Context, target domain.CommentTarget, targetID string, includeInternal bool) ([]*domain.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if !target.IsValid() {
+		return nil, fmt.Errorf("%w: unknown comment target %q", domain.ErrInvalidInput, target)
+	}
+
+	tenant := domain.TenantID(ctx)
+	var records []*commentRecord
+	for _, rec := range r.store.comments {
+		c := &rec.comment
+		if rec.tenant == tenant && c.TargetType == target && c.TargetID == targetID && (includeInternal || !c.IsInternal()) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return newerFirst(records[j].comment.CreatedAt, records[j].seq, records[i].comment.CreatedAt, records[i].seq)
+	})
+
+	comments := make([]*domain.Comment, 0, len(records))
+	for _, rec := range records {
+		comments = append(comments, r.joinComment(rec))
+	}
+	return comments, nil
+}
+
+// UpdateBody replaces the body of a comment that is not deleted and marks it
+// as edited
+func (r *CommentRepository) UpdateBody(ctx context.Context, id, body string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.comments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.comment.IsDeleted() {
+		return domain.ErrCommentNotFound
+	}
+
+	editedAt := now()
+	rec.comment.Body = body
+	rec.comment.EditedAt = &editedAt
+	return nil
+}
+
+// Delete clears the body of a comment and marks it as deleted, keeping it
+// in the thread for its replies; deleting it again keeps the first time
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.comments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrCommentNotFound
+	}
+
+	rec.comment.Body = ""
+	if rec.comment.DeletedAt == nil {
+		deletedAt := now()
+		rec.comment.DeletedAt = &deletedAt
+	}
+	return nil
+}
+
+// joinComment copies a stored comment and resolves its author name; caller
+// holds the lock
+func (r *CommentRepository) joinComment(rec *commentRecord) *domain.Comment {
+	comment := cloneComment(&rec.comment)
+	if comment.AuthorID != nil {
+		if u, ok := r.store.users[*comment.AuthorID]; ok {
+			comment.AuthorName = u.user.Name
+		}
+	}
+	return &comment
+}
+
+// targetExists checks that the request or learning a thread belongs to is
+// stored; caller holds the lock
+func (s *Store) targetExists(target domain.CommentTarget, id string) bool {
+	switch target {
+	case domain.CommentOnRequest:
+		_, ok := s.requests[id]
+		return ok
+	case domain.CommentOnLearning:
+		_, ok := s.learnings[id]
+		return ok
+	}
+	return false
+}
+
+// cloneComment copies a comment so callers cannot mutate stored state
+func cloneComment(c *domain.Comment) domain.Comment {
+	v := *c
+	v.ParentID = cloneString(c.ParentID)
+	v.AuthorID = cloneString(c.AuthorID)
+	v.EditedAt = cloneTime(c.EditedAt)
+	v.DeletedAt = cloneTime(c.DeletedAt)
+	return v
+}
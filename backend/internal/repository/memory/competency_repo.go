@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type skillTargetRecord struct {
+	target domain.SkillTarget
+	tenant string
+	seq    int64
+}
+
+type userSkillRecord struct {
+	userSkill domain.UserSkill
+	tenant    string
+	seq       int64
+}
+
+// CompetencyRepository stores skill targets and the levels users hold
+type CompetencyRepository struct {
+	store *Store
+}
+
+func NewCompetencyRepository(store *Store) *CompetencyRepository {
+	return &CompetencyRepository{store: store}
+}
+
+// userSkillKey is the primary key of user_skills
+func userSkillKey(userID, skillID string) string {
+	return userID + "/" + skillID
+}
+
+// CreateTarget inserts a skill target; there is one target per skill,
+// department and job title
+func (r *CompetencyRepository) CreateTarget(ctx context.Context, target *domain.SkillTarget) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	skill, ok := r.store.skills[target.SkillID]
+	if !ok || skill.tenant != domain.TenantID(ctx) {
+		return domain.ErrSkillNotFound
+	}
+	if (target.Department == nil && target.JobTitle == nil) || !domain.ValidSkillLevel(target.Level) {
+		return fmt.Errorf("failed to create skill target: %w", ErrCheckViolation)
+	}
+	for _, rec := range r.store.skillTargets {
+		t := &rec.target
+		if t.SkillID == target.SkillID && sameScope(t.Department, target.Department) && sameScope(t.JobTitle, target.JobTitle) {
+			return domain.ErrSkillTargetExists
+		}
+	}
+
+	target.ID = newID()
+	target.CreatedAt = now()
+	target.SkillName = skill.skill.Name
+
+	r.store.skillTargets[target.ID] = &skillTargetRecord{target: cloneSkillTarget(target), tenant: skill.tenant, seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetTargets retrieves every skill target ordered by skill name
+func (r *CompetencyRepository) GetTargets(ctx context.Context) ([]*domain.SkillTarget, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	targets := make([]*domain.SkillTarget, 0, len(r.store.skillTargets))
+	for _, rec := range r.store.skillTargets {
+		if rec.tenant != tenant {
+			continue
+		}
+		target := cloneSkillTarget(&rec.target)
+		target.SkillName = r.store.skills[target.SkillID].skill.Name
+		targets = append(targets, &target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		a, b := targets[i], targets[j]
+		if an, bn := strings.ToLower(a.SkillName), strings.ToLower(b.SkillName); an != bn {
+			return an < bn
+		}
+		if c := compareScope(a.Department, b.Department); c != 0 {
+			return c < 0
+		}
+		return compareScope(a.JobTitle, b.JobTitle) < 0
+	})
+	return targets, nil
+}
+
+// DeleteTarget removes a skill target
+func (r *CompetencyRepository) DeleteTarget(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.skillTargets[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrSkillTargetNotFound
+	}
+	delete(r.store.skillTargets, id)
+	return nil
+}
+
+// GetUserSkills retrieves the levels a user holds, ordered by skill name
+func (r *CompetencyRepository) GetUserSkills(ctx context.Context, userID string) ([]*domain.UserSkill, error) {
+	return r.list(ctx, func(us *domain.UserSkill) bool { return us.UserID == userID }), nil
+}
+
+// GetAllUserSkills retrieves the levels of every user
+func (r *CompetencyRepository) GetAllUserSkills(ctx context.Context) ([]*domain.UserSkill, error) {
+	return r.list(ctx, func(*domain.UserSkill) bool { return true }), nil
+}
+
+// SaveUserSkill inserts or replaces the user's standing in a skill
+func (r *CompetencyRepository) SaveUserSkill(ctx context.Context, us *domain.UserSkill) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.skills[us.SkillID]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrSkillNotFound
+	}
+	if _, ok := r.store.users[us.UserID]; !ok {
+		return fmt.Errorf("failed to save user skill: %w", ErrForeignKeyViolation)
+	}
+	if us.AssessedBy != nil {
+		if _, ok := r.store.users[*us.AssessedBy]; !ok {
+			return fmt.Errorf("failed to save user skill: %w", ErrForeignKeyViolation)
+		}
+	}
+	if !domain.ValidSkillLevel(us.Level) ||
+		(us.SelfLevel != nil && !domain.ValidSkillLevel(*us.SelfLevel)) ||
+		(us.ManagerLevel != nil && !domain.ValidSkillLevel(*us.ManagerLevel)) {
+		return fmt.Errorf("failed to save user skill: %w", ErrCheckViolation)
+	}
+
+	us.UpdatedAt = now()
+
+	key := userSkillKey(us.UserID, us.SkillID)
+	rec, ok := r.store.userSkills[key]
+	if !ok {
+		rec = &userSkillRecord{tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+		r.store.userSkills[key] = rec
+	}
+	rec.userSkill = cloneUserSkill(us)
+	return nil
+}
+
+func (r *CompetencyRepository) list(ctx context.Context, keep func(*domain.UserSkill) bool) []*domain.UserSkill {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	userSkills := make([]*domain.UserSkill, 0)
+	for _, rec := range r.store.userSkills {
+		if rec.tenant != tenant || !keep(&rec.userSkill) {
+			continue
+		}
+		us := cloneUserSkill(&rec.userSkill)
+		us.SkillName = r.store.skills[us.SkillID].skill.Name
+		userSkills = append(userSkills, &us)
+	}
+	sort.Slice(userSkills, func(i, j int) bool {
+		a, b := userSkills[i], userSkills[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return strings.ToLower(a.SkillName) < strings.ToLower(b.SkillName)
+	})
+	return userSkills
+}
+
+// sameScope compares scopes like the unique target index, where a missing
+// scope equals an empty one and case is ignored
+func sameScope(a, b *string) bool {
+	return scopeKey(a) == scopeKey(b)
+}
+
+func scopeKey(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.ToLower(*s)
+}
+
+// compareScope orders optional scopes like NULLS FIRST
+func compareScope(a, b *string) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return strings.Compare(*a, *b)
+}
+
+// cloneSkillTarget copies a skill target so callers cannot mutate stored
+// state
+func cloneSkillTarget(t *domain.SkillTarget) domain.SkillTarget {
+	v := *t
+	v.Department = cloneString(t.Department)
+	v.JobTitle = cloneString(t.JobTitle)
+	return v
+}
+
+// cloneUserSkill copies a user skill so callers cannot mutate stored state
+func cloneUserSkill(us *domain.UserSkill) domain.UserSkill {
+	v := *us
+	v.SelfLevel = cloneInt(us.SelfLevel)
+	v.ManagerLevel = cloneInt(us.ManagerLevel)
+	v.AssessedBy = cloneString(us.AssessedBy)
+	return v
+}
+
+// cloneInt copies an optional integer
+func cloneInt(n *int) *int {
+	if n == nil {
+		return nil
+	}
+	v := *n
+	return &v
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type courseRecord struct {
+	course domain.Course
+	tenant string
+	seq    int64
+}
+
+type CourseRepository struct {
+	store *Store
+}
+
+func NewCourseRepository(store *Store) *CourseRepository {
+	return &CourseRepository{store: store}
+}
+
+// Create inserts a new course
+func (r *CourseRepository) Create(ctx context.Context, course *domain.Course) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := checkCourse(course); err != nil {
+		return fmt.Errorf("failed to create course: %w", err)
+	}
+
+	course.ID = newID()
+	course.SeatsTaken = 0
+	course.CreatedAt = now()
+	course.UpdatedAt = course.CreatedAt
+
+	rec := &courseRecord{course: cloneCourse(course), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	r.store.courses[course.ID] = rec
+	return nil
+}
+
+// GetByID retrieves a course by its ID
+func (r *CourseRepository) GetByID(ctx context.Context, id string) (*domain.Course, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.courses[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrCourseNotFound
+	}
+	course := cloneCourse(&rec.course)
+	return &course, nil
+}
+
+// Search retrieves the courses matching the filter, ordered by title
+func (r *CourseRepository) Search(ctx context.Context, filter domain.CourseFilter) ([]*domain.Course, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	query := strings.ToLower(filter.Query)
+	var records []*courseRecord
+	for _, rec := range r.store.courses {
+		c := &rec.course
+		text := strings.ToLower(c.Title + " " + c.Description + " " + c.Provider)
+		switch {
+		case rec.tenant != tenant:
+		case query != "" && !strings.Contains(text, query):
+		case filter.Skill != "" && !c.HasSkill(filter.Skill):
+		case filter.Format != nil && c.Format != *filter.Format:
+		case filter.OpenAt != nil && !c.EnrollmentOpen(*filter.OpenAt):
+		default:
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].course.Title != records[j].course.Title {
+			return records[i].course.Title < records[j].course.Title
+		}
+		return newerFirst(records[j].course.CreatedAt, records[j].seq, records[i].course.CreatedAt, records[i].seq)
+	})
+
+	courses := make([]*domain.Course, 0, len(records))
+	for _, rec := range records {
+		course := cloneCourse(&rec.course)
+		courses = append(courses, &course)
+	}
+	return courses, nil
+}
+
+// Update replaces the catalog fields of a course; taken seats are kept
+func (r *CourseRepository) Update(ctx context.Context, course *domain.Course) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.courses[course.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrCourseNotFound
+	}
+	if err := checkCourse(course); err != nil {
+		return fmt.Errorf("failed to update course: %w", err)
+	}
+
+	course.SeatsTaken = rec.course.SeatsTaken
+	course.CreatedAt = rec.course.CreatedAt
+	course.UpdatedAt = now()
+	rec.course = cloneCourse(course)
+	return nil
+}
+
+// Delete removes a course; courses referenced by enrollment requests are
+// kept and reported as in use
+func (r *CourseRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.courses[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrCourseNotFound
+	}
+	for _, rec := range r.store.requests {
+		if rec.request.CourseID != nil && *rec.request.CourseID == id {
+			return domain.ErrCourseInUse
+		}
+	}
+	delete(r.store.courses, id)
+	return nil
+}
+
+// checkCourse mirrors the check constraints of the courses table
+func checkCourse(c *domain.Course) error {
+	if !c.Format.IsValid() || c.DurationHours <= 0 || (c.Capacity != nil && *c.Capacity <= 0) {
+		return ErrCheckViolation
+	}
+	if c.EnrollmentOpensAt != nil && c.EnrollmentClosesAt != nil && !c.EnrollmentClosesAt.After(*c.EnrollmentOpensAt) {
+		return ErrCheckViolation
+	}
+	return nil
+}
+
+// cloneCourse copies a course at Postgres precision so callers cannot
+// mutate stored state
+func cloneCourse(c *domain.Course) domain.Course {
+	v := *c
+	v.Skills = domain.CleanSkills(c.Skills)
+	if c.Capacity != nil {
+		capacity := *c.Capacity
+		v.Capacity = &capacity
+	}
+	v.EnrollmentOpensAt = truncateTime(c.EnrollmentOpensAt)
+	v.EnrollmentClosesAt = truncateTime(c.EnrollmentClosesAt)
+	return v
+}
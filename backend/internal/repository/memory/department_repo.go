@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type departmentRecord struct {
+	department domain.Department
+	tenant     string
+	seq        int64
+}
+
+// DepartmentRepository keeps the department tree in the store
+type DepartmentRepository struct {
+	store *Store
+}
+
+func NewDepartmentRepository(store *Store) *DepartmentRepository {
+	return &DepartmentRepository{store: store}
+}
+
+// Create inserts a new department; names are unique ignoring case
+func (r *DepartmentRepository) Create(ctx context.Context, department *domain.Department) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	if r.store.departmentNameTaken(tenant, department.Name, "") {
+		return domain.ErrDepartmentExists
+	}
+	if err := r.store.checkDepartmentRefs(department); err != nil {
+		return fmt.Errorf("failed to create department: %w", err)
+	}
+
+	department.ID = newID()
+	department.CreatedAt = now()
+	department.UpdatedAt = department.CreatedAt
+
+	r.store.departments[department.ID] = &departmentRecord{department: cloneDepartment(department), tenant: tenant, seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a department by its ID
+func (r *DepartmentRepository) GetByID(ctx context.Context, id string) (*domain.Department, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.departments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrDepartmentNotFound
+	}
+	department := cloneDepartment(&rec.department)
+	return &department, nil
+}
+
+// GetByName retrieves a department by its name, ignoring case
+func (r *DepartmentRepository) GetByName(ctx context.Context, name string) (*domain.Department, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	for _, rec := range r.store.departments {
+		if rec.tenant == tenant && strings.EqualFold(rec.department.Name, name) {
+			department := cloneDepartment(&rec.department)
+			return &department, nil
+		}
+	}
+	return nil, domain.ErrDepartmentNotFound
+}
+
+// GetAll retrieves all departments ordered by name
+func (r *DepartmentRepository) GetAll(ctx context.Context) ([]*domain.Department, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	departments := make([]*domain.Department, 0, len(r.store.departments))
+	for _, rec := range r.store.departments {
+		if rec.tenant != tenant {
+			continue
+		}
+		department := cloneDepartment(&rec.department)
+		departments = append(departments, &department)
+	}
+	sort.Slice(departments, func(i, j int) bool {
+		return strings.ToLower(departments[i].Name) < strings.ToLower(departments[j].Name)
+	})
+	return departments, nil
+}
+
+// Update renames, moves or changes the head of a department
+func (r *DepartmentRepository) Update(ctx context.Context, department *domain.Department) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.departments[department.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrDepartmentNotFound
+	}
+	if r.store.departmentNameTaken(rec.tenant, department.Name, department.ID) {
+		return domain.ErrDepartmentExists
+	}
+	if err := r.store.checkDepartmentRefs(department); err != nil {
+		return fmt.Errorf("failed to update department: %w", err)
+	}
+
+	rec.department.Name = department.Name
+	rec.department.ParentID = cloneString(department.ParentID)
+	rec.department.HeadID = cloneString(department.HeadID)
+	rec.department.UpdatedAt = now()
+
+	department.CreatedAt = rec.department.CreatedAt
+	department.UpdatedAt = rec.department.UpdatedAt
+	return nil
+}
+
+// Delete removes a department without members or subdepartments
+func (r *DepartmentRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.departments[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrDepartmentNotFound
+	}
+	for _, rec := range r.store.departments {
+		if rec.department.ParentID != nil && *rec.department.ParentID == id {
+			return domain.ErrDepartmentInUse
+		}
+	}
+	for _, rec := range r.store.users {
+		if rec.user.DepartmentID != nil && *rec.user.DepartmentID == id {
+			return domain.ErrDepartmentInUse
+		}
+	}
+
+	delete(r.store.departments, id)
+	return nil
+}
+
+// departmentNameTaken mirrors the unique index on tenant and lower(name);
+// caller holds the lock
+func (s *Store) departmentNameTaken(tenant, name, exceptID string) bool {
+	for id, rec := range s.departments {
+		if id != exceptID && rec.tenant == tenant && strings.EqualFold(rec.department.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDepartmentRefs checks the parent and head foreign keys and that a
+// department is not its own parent; caller holds the lock
+func (s *Store) checkDepartmentRefs(d *domain.Department) error {
+	if d.ParentID != nil {
+		if *d.ParentID == d.ID {
+			return ErrCheckViolation
+		}
+		if _, ok := s.departments[*d.ParentID]; !ok {
+			return ErrForeignKeyViolation
+		}
+	}
+	if d.HeadID != nil {
+		if _, ok := s.users[*d.HeadID]; !ok {
+			return ErrForeignKeyViolation
+		}
+	}
+	return nil
+}
+
+// departmentName resolves the name of a user's department like the SQL
+// LEFT JOIN on departments; caller holds the lock
+func (s *Store) departmentName(id *string) *string {
+	if id == nil {
+		return nil
+	}
+	rec, ok := s.departments[*id]
+	if !ok {
+		return nil
+	}
+	return cloneString(&rec.department.Name)
+}
+
+// cloneDepartment copies a department so callers cannot mutate stored state
+func cloneDepartment(d *domain.Department) domain.Department {
+	c := *d
+	c.ParentID = cloneString(d.ParentID)
+	c.HeadID = cloneString(d.HeadID)
+	return c
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type enrollmentRecord struct {
+	enrollment domain.CourseEnrollment
+	tenant     string
+	seq        int64
+}
+
+type EnrollmentRepository struct {
+	store *Store
+}
+
+func NewEnrollmentRepository(store *Store) *EnrollmentRepository {
+	return &EnrollmentRepository{store: store}
+}
+
+// Create takes a seat on the course and inserts the enrollment
+func (r *EnrollmentRepository) Create(ctx context.Context, enrollment *domain.CourseEnrollment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	course, ok := r.store.courses[enrollment.CourseID]
+	if !ok || course.tenant != domain.TenantID(ctx) {
+		return domain.ErrCourseNotFound
+	}
+	if _, ok := r.store.users[enrollment.UserID]; !ok {
+		return fmt.Errorf("failed to create enrollment: %w", ErrForeignKeyViolation)
+	}
+	if _, ok := r.store.requests[enrollment.RequestID]; !ok {
+		return fmt.Errorf("failed to create enrollment: %w", ErrForeignKeyViolation)
+	}
+	for _, rec := range r.store.enrollments {
+		e := &rec.enrollment
+		if e.RequestID == enrollment.RequestID ||
+			(e.IsActive() && e.CourseID == enrollment.CourseID && e.UserID == enrollment.UserID) {
+			return fmt.Errorf("failed to create enrollment: %w", ErrDuplicateKey)
+		}
+	}
+	if course.course.IsFull() {
+		return domain.ErrCourseFull
+	}
+
+	course.course.SeatsTaken++
+	course.course.UpdatedAt = now()
+
+	enrollment.ID = newID()
+	enrollment.Status = domain.EnrollmentActive
+	enrollment.EnrolledAt = now()
+	enrollment.UpdatedAt = enrollment.EnrolledAt
+	enrollment.CompletedAt = nil
+	enrollment.Feedback = nil
+
+	rec := &enrollmentRecord{enrollment: cloneEnrollment(enrollment), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	rec.enrollment.CourseTitle, rec.enrollment.UserName = "", ""
+	r.store.enrollments[enrollment.ID] = rec
+	return nil
+}
+
+// GetByID retrieves an enrollment by its ID
+func (r *EnrollmentRepository) GetByID(ctx context.Context, id string) (*domain.CourseEnrollment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.enrollments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrEnrollmentNotFound
+	}
+	return r.joinEnrollment(rec), nil
+}
+
+// GetByUserID retrieves the enrollments of a user, newest first
+func (r *EnrollmentRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.CourseEnrollment, error) {
+	return r.list(ctx, func(e *domain.CourseEnrollment) bool {
+		return e.UserID == userID
+	}, true), nil
+}
+
+// GetByCourseID retrieves the enrollments of a course, oldest first
+func (r *EnrollmentRepository) GetByCourseID(ctx context.Context, courseID string) ([]*domain.CourseEnrollment, error) {
+	return r.list(ctx, func(e *domain.CourseEnrollment) bool {
+		return e.CourseID == courseID
+	}, false), nil
+}
+
+// Complete marks an active enrollment as completed with feedback; the seat
+// stays taken
+func (r *EnrollmentRepository) Complete(ctx context.Context, id string, feedback domain.Feedback) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.enrollments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || !rec.enrollment.IsActive() {
+		return domain.ErrEnrollmentNotActive
+	}
+
+	completedAt := now()
+	rec.enrollment.Status = domain.EnrollmentCompleted
+	rec.enrollment.Feedback = &feedback
+	rec.enrollment.CompletedAt = &completedAt
+	rec.enrollment.UpdatedAt = completedAt
+	return nil
+}
+
+// Cancel marks an active enrollment as cancelled and frees its seat
+func (r *EnrollmentRepository) Cancel(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.enrollments[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || !rec.enrollment.IsActive() {
+		return domain.ErrEnrollmentNotActive
+	}
+
+	rec.enrollment.Status = domain.EnrollmentCancelled
+	rec.enrollment.UpdatedAt = now()
+	if course, ok := r.store.courses[rec.enrollment.CourseID]; ok {
+		course.course.SeatsTaken--
+		course.course.UpdatedAt = rec.enrollment.UpdatedAt
+	}
+	return nil
+}
+
+// list returns joined enrollments matching keep, by enrollment time
+func (r *EnrollmentRepository) list(ctx context.Context, keep func(*domain.CourseEnrollment) bool, newestFirst bool) []*domain.CourseEnrollment {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*enrollmentRecord
+	for _, rec := range r.store.enrollments {
+		if rec.tenant == tenant && keep(&rec.enrollment) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !newestFirst {
+			a, b = b, a
+		}
+		return newerFirst(a.enrollment.EnrolledAt, a.seq, b.enrollment.EnrolledAt, b.seq)
+	})
+
+	enrollments := make([]*domain.CourseEnrollment, 0, len(records))
+	for _, rec := range records {
+		enrollments = append(enrollments, r.joinEnrollment(rec))
+	}
+	return enrollments
+}
+
+// joinEnrollment copies a stored enrollment and resolves the course title
+// and user name; caller holds the lock
+func (r *EnrollmentRepository) joinEnrollment(rec *enrollmentRecord) *domain.CourseEnrollment {
+	enrollment := cloneEnrollment(&rec.enrollment)
+	if c, ok := r.store.courses[enrollment.CourseID]; ok {
+		enrollment.CourseTitle = c.course.Title
+	}
+	if u, ok := r.store.users[enrollment.UserID]; ok {
+		enrollment.UserName = u.user.Name
+	}
+	return &enrollment
+}
+
+// cloneEnrollment copies an enrollment so callers cannot mutate stored state
+func cloneEnrollment(e *domain.CourseEnrollment) domain.CourseEnrollment {
+	v := *e
+	v.CompletedAt = cloneTime(e.CompletedAt)
+	v.Feedback = cloneFeedback(e.Feedback)
+	return v
+}
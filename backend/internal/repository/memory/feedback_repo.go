@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type questionnaireRecord struct {
+	questionnaire domain.Questionnaire
+	tenant        string
+	seq           int64
+}
+
+type feedbackRecord struct {
+	response domain.FeedbackResponse
+	tenant   string
+	seq      int64
+}
+
+// QuestionnaireRepository stores feedback questionnaires
+type QuestionnaireRepository struct {
+	store *Store
+}
+
+func NewQuestionnaireRepository(store *Store) *QuestionnaireRepository {
+	return &QuestionnaireRepository{store: store}
+}
+
+// Create inserts an inactive questionnaire
+func (r *QuestionnaireRepository) Create(ctx context.Context, questionnaire *domain.Questionnaire) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !questionnaire.Audience.IsValid() {
+		return fmt.Errorf("failed to create questionnaire: %w", ErrCheckViolation)
+	}
+
+	questionnaire.ID = newID()
+	questionnaire.Active = false
+	questionnaire.CreatedAt = now()
+	questionnaire.UpdatedAt = questionnaire.CreatedAt
+
+	r.store.questionnaires[questionnaire.ID] = &questionnaireRecord{questionnaire: cloneQuestionnaire(questionnaire), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a questionnaire by its ID
+func (r *QuestionnaireRepository) GetByID(ctx context.Context, id string) (*domain.Questionnaire, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.questionnaires[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrQuestionnaireNotFound
+	}
+	questionnaire := cloneQuestionnaire(&rec.questionnaire)
+	return &questionnaire, nil
+}
+
+// GetActive retrieves the questionnaire new feedback of the audience answers
+func (r *QuestionnaireRepository) GetActive(ctx context.Context, audience domain.FeedbackAudience) (*domain.Questionnaire, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	for _, rec := range r.store.questionnaires {
+		if rec.tenant == tenant && rec.questionnaire.Audience == audience && rec.questionnaire.Active {
+			questionnaire := cloneQuestionnaire(&rec.questionnaire)
+			return &questionnaire, nil
+		}
+	}
+	return nil, domain.ErrQuestionnaireNotFound
+}
+
+// GetAll retrieves all questionnaires by audience, newest first
+func (r *QuestionnaireRepository) GetAll(ctx context.Context) ([]*domain.Questionnaire, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*questionnaireRecord, 0, len(r.store.questionnaires))
+	for _, rec := range r.store.questionnaires {
+		if rec.tenant == tenant {
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		a, b := &recs[i].questionnaire, &recs[j].questionnaire
+		if a.Audience != b.Audience {
+			return a.Audience < b.Audience
+		}
+		return newerFirst(a.CreatedAt, recs[i].seq, b.CreatedAt, recs[j].seq)
+	})
+
+	questionnaires := make([]*domain.Questionnaire, 0, len(recs))
+	for _, rec := range recs {
+		questionnaire := cloneQuestionnaire(&rec.questionnaire)
+		questionnaires = append(questionnaires, &questionnaire)
+	}
+	return questionnaires, nil
+}
+
+// Update renames a questionnaire or changes its criteria as long as
+// nobody has answered it; the audience stays
+func (r *QuestionnaireRepository) Update(ctx context.Context, questionnaire *domain.Questionnaire) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.questionnaires[questionnaire.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrQuestionnaireNotFound
+	}
+	if r.store.questionnaireAnswered(questionnaire.ID) {
+		return domain.ErrQuestionnaireInUse
+	}
+
+	rec.questionnaire.Name = questionnaire.Name
+	rec.questionnaire.Criteria = cloneCriteria(questionnaire.Criteria)
+	rec.questionnaire.UpdatedAt = now()
+
+	*questionnaire = cloneQuestionnaire(&rec.questionnaire)
+	return nil
+}
+
+// Delete removes a questionnaire nobody has answered
+func (r *QuestionnaireRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.questionnaires[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrQuestionnaireNotFound
+	}
+	if r.store.questionnaireAnswered(id) {
+		return domain.ErrQuestionnaireInUse
+	}
+
+	delete(r.store.questionnaires, id)
+	return nil
+}
+
+// Activate makes a questionnaire the one its audience answers, retiring
+// the previous one
+func (r *QuestionnaireRepository) Activate(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.questionnaires[id]
+	if !ok || target.tenant != domain.TenantID(ctx) {
+		return domain.ErrQuestionnaireNotFound
+	}
+
+	t := now()
+	for _, rec := range r.store.questionnaires {
+		if rec != target && rec.tenant == target.tenant && rec.questionnaire.Active && rec.questionnaire.Audience == target.questionnaire.Audience {
+			rec.questionnaire.Active = false
+			rec.questionnaire.UpdatedAt = t
+		}
+	}
+	target.questionnaire.Active = true
+	target.questionnaire.UpdatedAt = t
+	return nil
+}
+
+// questionnaireAnswered checks for responses to a questionnaire; caller
+// holds the lock
+func (s *Store) questionnaireAnswered(id string) bool {
+	for _, rec := range s.feedback {
+		if rec.response.QuestionnaireID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// FeedbackRepository stores questionnaire answers on learnings
+type FeedbackRepository struct {
+	store *Store
+}
+
+func NewFeedbackRepository(store *Store) *FeedbackRepository {
+	return &FeedbackRepository{store: store}
+}
+
+// Create inserts a response; a learning gets at most one per audience
+func (r *FeedbackRepository) Create(ctx context.Context, response *domain.FeedbackResponse) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.learnings[response.LearningID]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrLearningNotFound
+	}
+	if _, ok := r.store.questionnaires[response.QuestionnaireID]; !ok {
+		return fmt.Errorf("failed to create feedback: %w", ErrForeignKeyViolation)
+	}
+	if _, ok := r.store.mentors[response.MentorID]; !ok {
+		return fmt.Errorf("failed to create feedback: %w", ErrForeignKeyViolation)
+	}
+	if response.AuthorID != nil {
+		if _, ok := r.store.users[*response.AuthorID]; !ok {
+			return fmt.Errorf("failed to create feedback: %w", ErrForeignKeyViolation)
+		}
+	}
+	for _, rec := range r.store.feedback {
+		if rec.response.LearningID == response.LearningID && rec.response.Audience == response.Audience {
+			return domain.ErrFeedbackExists
+		}
+	}
+
+	response.ID = newID()
+	response.CreatedAt = now()
+
+	r.store.feedback[response.ID] = &feedbackRecord{response: cloneFeedbackResponse(response), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByLearningID retrieves the responses on a learning, oldest first
+func (r *FeedbackRepository) GetByLearningID(ctx context.Context, learningID string) ([]*domain.FeedbackResponse, error) {
+	responses := r.list(ctx, func(f *domain.FeedbackResponse) bool { return f.LearningID == learningID })
+	for i, j := 0, len(responses)-1; i < j; i, j = i+1, j-1 {
+		responses[i], responses[j] = responses[j], responses[i]
+	}
+	return responses, nil
+}
+
+// GetByMentorID retrieves the responses on the mentor's learnings, newest
+// first
+func (r *FeedbackRepository) GetByMentorID(ctx context.Context, mentorID string) ([]*domain.FeedbackResponse, error) {
+	return r.list(ctx, func(f *domain.FeedbackResponse) bool { return f.MentorID == mentorID }), nil
+}
+
+// list returns the matching responses newest first, joined with their
+// authors
+func (r *FeedbackRepository) list(ctx context.Context, match func(*domain.FeedbackResponse) bool) []*domain.FeedbackResponse {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*feedbackRecord, 0)
+	for _, rec := range r.store.feedback {
+		if rec.tenant == tenant && match(&rec.response) {
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return newerFirst(recs[i].response.CreatedAt, recs[i].seq, recs[j].response.CreatedAt, recs[j].seq)
+	})
+
+	responses := make([]*domain.FeedbackResponse, 0, len(recs))
+	for _, rec := range recs {
+		response := cloneFeedbackResponse(&rec.response)
+		if response.AuthorID != nil {
+			if u, ok := r.store.users[*response.AuthorID]; ok {
+				response.AuthorName = u.user.Name
+			}
+		}
+		responses = append(responses, &response)
+	}
+	return responses
+}
+
+// cloneQuestionnaire copies a questionnaire so callers cannot mutate
+// stored state
+func cloneQuestionnaire(q *domain.Questionnaire) domain.Questionnaire {
+	v := *q
+	v.Criteria = cloneCriteria(q.Criteria)
+	return v
+}
+
+func cloneCriteria(criteria []domain.FeedbackCriterion) []domain.FeedbackCriterion {
+	return append(make([]domain.FeedbackCriterion, 0, len(criteria)), criteria...)
+}
+
+// cloneFeedbackResponse copies a response so callers cannot mutate stored
+// state
+func cloneFeedbackResponse(f *domain.FeedbackResponse) domain.FeedbackResponse {
+	v := *f
+	v.AuthorID = cloneString(f.AuthorID)
+	v.AuthorName = ""
+	v.Answers = make(map[string]int, len(f.Answers))
+	for key, score := range f.Answers {
+		v.Answers[key] = score
+	}
+	return v
+}
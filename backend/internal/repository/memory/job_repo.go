@@ -0,0 +1,339 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type jobRecord struct {
+	job domain.Job
+	seq int64
+}
+
+type jobScheduleRecord struct {
+	nextRunAt time.Time
+	lastRunAt *time.Time
+}
+
+// JobRepository is a job queue in the store; the store lock plays the part
+// of the row locks taken by repository/postgres. Like there, Claim, Complete,
+// Fail, ClaimSchedule and LatestByKind serve every tenant.
+type JobRepository struct {
+	store *Store
+}
+
+func NewJobRepository(store *Store) *JobRepository {
+	return &JobRepository{store: store}
+}
+
+// Enqueue inserts a pending job
+func (r *JobRepository) Enqueue(ctx context.Context, job *domain.Job) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if job.MaxAttempts < 1 {
+		return fmt.Errorf("failed to enqueue job: %w", ErrCheckViolation)
+	}
+	job.TenantID = domain.TenantID(ctx)
+	if job.UniqueKey != "" && r.store.liveJobWithKey(job.TenantID, job.UniqueKey, "") {
+		return domain.ErrJobExists
+	}
+
+	job.ID = newID()
+	job.Status = domain.JobPending
+	job.Attempts = 0
+	job.LockedBy = ""
+	job.LockedUntil = nil
+	job.LastError = ""
+	job.StartedAt = nil
+	job.FinishedAt = nil
+	job.CreatedAt = now()
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+	job.RunAt = job.RunAt.Truncate(time.Microsecond)
+
+	r.store.jobs[job.ID] = &jobRecord{job: cloneJob(job), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a job by its ID
+func (r *JobRepository) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.jobs[id]
+	if !ok || rec.job.TenantID != domain.TenantID(ctx) {
+		return nil, domain.ErrJobNotFound
+	}
+	job := cloneJob(&rec.job)
+	return &job, nil
+}
+
+// List retrieves jobs matching the filter, newest first
+func (r *JobRepository) List(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*jobRecord, 0)
+	for _, rec := range r.store.jobs {
+		if rec.job.TenantID != tenant {
+			continue
+		}
+		if filter.Status != "" && rec.job.Status != filter.Status {
+			continue
+		}
+		if filter.Kind != "" && rec.job.Kind != filter.Kind {
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return newerFirst(recs[i].job.CreatedAt, recs[i].seq, recs[j].job.CreatedAt, recs[j].seq)
+	})
+	if filter.Limit > 0 && len(recs) > filter.Limit {
+		recs = recs[:filter.Limit]
+	}
+
+	jobs := make([]*domain.Job, 0, len(recs))
+	for _, rec := range recs {
+		job := cloneJob(&rec.job)
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// LatestByKind retrieves the newest job of the kind of every tenant
+func (r *JobRepository) LatestByKind(ctx context.Context, kind string) ([]*domain.Job, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	latest := make(map[string]*jobRecord)
+	for _, rec := range r.store.jobs {
+		if rec.job.Kind != kind {
+			continue
+		}
+		if cur, ok := latest[rec.job.TenantID]; !ok ||
+			newerFirst(rec.job.CreatedAt, rec.seq, cur.job.CreatedAt, cur.seq) {
+			latest[rec.job.TenantID] = rec
+		}
+	}
+
+	jobs := make([]*domain.Job, 0, len(latest))
+	for _, rec := range latest {
+		job := cloneJob(&rec.job)
+		jobs = append(jobs, &job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].TenantID < jobs[j].TenantID })
+	return jobs, nil
+}
+
+// Claim locks the next due job for the worker
+func (r *JobRepository) Claim(ctx context.Context, workerID string, kinds []string, at time.Time, lease time.Duration) (*domain.Job, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var next *jobRecord
+	for _, rec := range r.store.jobs {
+		j := &rec.job
+		if !slices.Contains(kinds, j.Kind) {
+			continue
+		}
+		expired := j.Status == domain.JobRunning && j.LockedUntil != nil && !j.LockedUntil.After(at)
+		if expired && j.Attempts >= j.MaxAttempts {
+			// The lease of the last attempt expired: the job fails
+			finishedAt := at.Truncate(time.Microsecond)
+			j.Status = domain.JobFailed
+			j.FinishedAt = &finishedAt
+			j.LockedBy = ""
+			j.LockedUntil = nil
+			j.LastError = "lease expired"
+			continue
+		}
+		due := (j.Status == domain.JobPending && !j.RunAt.After(at)) || expired
+		if !due {
+			continue
+		}
+		if next == nil || j.RunAt.Before(next.job.RunAt) ||
+			(j.RunAt.Equal(next.job.RunAt) && rec.seq < next.seq) {
+			next = rec
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	j := &next.job
+	if j.Status == domain.JobRunning {
+		j.LastError = "lease expired"
+	}
+	startedAt := at.Truncate(time.Microsecond)
+	lockedUntil := at.Add(lease).Truncate(time.Microsecond)
+	j.Status = domain.JobRunning
+	j.Attempts++
+	j.LockedBy = workerID
+	j.LockedUntil = &lockedUntil
+	j.StartedAt = &startedAt
+
+	job := cloneJob(j)
+	return &job, nil
+}
+
+// Complete marks a claimed job as succeeded
+func (r *JobRepository) Complete(ctx context.Context, id, workerID string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	j, err := r.store.heldJob(id, workerID)
+	if err != nil {
+		return err
+	}
+
+	finishedAt := at.Truncate(time.Microsecond)
+	j.Status = domain.JobSucceeded
+	j.FinishedAt = &finishedAt
+	j.LockedBy = ""
+	j.LockedUntil = nil
+	j.LastError = ""
+	return nil
+}
+
+// Fail records a failed attempt and schedules the retry, if any
+func (r *JobRepository) Fail(ctx context.Context, id, workerID, lastError string, at time.Time, retryAt *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	j, err := r.store.heldJob(id, workerID)
+	if err != nil {
+		return err
+	}
+
+	if retryAt != nil {
+		j.Status = domain.JobPending
+		j.RunAt = retryAt.Truncate(time.Microsecond)
+	} else {
+		finishedAt := at.Truncate(time.Microsecond)
+		j.Status = domain.JobFailed
+		j.FinishedAt = &finishedAt
+	}
+	j.LastError = lastError
+	j.LockedBy = ""
+	j.LockedUntil = nil
+	return nil
+}
+
+// Retry makes a failed or cancelled job pending again
+func (r *JobRepository) Retry(ctx context.Context, id string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.jobs[id]
+	if !ok || rec.job.TenantID != domain.TenantID(ctx) {
+		return domain.ErrJobNotFound
+	}
+	j := &rec.job
+	if j.Status != domain.JobFailed && j.Status != domain.JobCancelled {
+		return domain.ErrJobNotRetryable
+	}
+	if j.UniqueKey != "" && r.store.liveJobWithKey(j.TenantID, j.UniqueKey, id) {
+		return domain.ErrJobExists
+	}
+
+	j.Status = domain.JobPending
+	j.Attempts = 0
+	j.RunAt = at.Truncate(time.Microsecond)
+	j.FinishedAt = nil
+	return nil
+}
+
+// Cancel stops a pending job from running
+func (r *JobRepository) Cancel(ctx context.Context, id string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.jobs[id]
+	if !ok || rec.job.TenantID != domain.TenantID(ctx) {
+		return domain.ErrJobNotFound
+	}
+	if rec.job.Status != domain.JobPending {
+		return domain.ErrJobNotCancellable
+	}
+
+	finishedAt := at.Truncate(time.Microsecond)
+	rec.job.Status = domain.JobCancelled
+	rec.job.FinishedAt = &finishedAt
+	return nil
+}
+
+// DeleteFinished removes jobs finished before the time
+func (r *JobRepository) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	deleted := 0
+	for id, rec := range r.store.jobs {
+		if rec.job.TenantID == tenant && rec.job.Status.IsFinished() && rec.job.FinishedAt != nil && rec.job.FinishedAt.Before(before) {
+			delete(r.store.jobs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ClaimSchedule advances a due schedule to next
+func (r *JobRepository) ClaimSchedule(ctx context.Context, name string, at, next time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.jobSchedules[name]
+	if !ok {
+		r.store.jobSchedules[name] = &jobScheduleRecord{nextRunAt: next.Truncate(time.Microsecond)}
+		return false, nil
+	}
+	if rec.nextRunAt.After(at) {
+		return false, nil
+	}
+
+	lastRunAt := at.Truncate(time.Microsecond)
+	rec.nextRunAt = next.Truncate(time.Microsecond)
+	rec.lastRunAt = &lastRunAt
+	return true, nil
+}
+
+// liveJobWithKey reports whether another pending or running job of the
+// tenant holds the unique key; caller holds the lock
+func (s *Store) liveJobWithKey(tenant, key, exceptID string) bool {
+	for id, rec := range s.jobs {
+		if id != exceptID && rec.job.TenantID == tenant && rec.job.UniqueKey == key &&
+			(rec.job.Status == domain.JobPending || rec.job.Status == domain.JobRunning) {
+			return true
+		}
+	}
+	return false
+}
+
+// heldJob returns the running job locked by the worker; caller holds the lock
+func (s *Store) heldJob(id, workerID string) (*domain.Job, error) {
+	rec, ok := s.jobs[id]
+	if !ok || rec.job.Status != domain.JobRunning || rec.job.LockedBy != workerID {
+		return nil, domain.ErrJobLeaseLost
+	}
+	return &rec.job, nil
+}
+
+// cloneJob copies a job
+func cloneJob(j *domain.Job) domain.Job {
+	v := *j
+	v.Payload = slices.Clone(j.Payload)
+	v.LockedUntil = cloneTime(j.LockedUntil)
+	v.StartedAt = cloneTime(j.StartedAt)
+	v.FinishedAt = cloneTime(j.FinishedAt)
+	return v
+}
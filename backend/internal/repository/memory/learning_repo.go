@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type learningRecord struct {
+	learning domain.LearningProcess
+	tenant   string
+	seq      int64
+}
+
+type LearningRepository struct {
+	store *Store
+}
+
+func NewLearningRepository(store *Store) *LearningRepository {
+	return &LearningRepository{store: store}
+}
+
+// Create inserts a new learning process
+func (r *LearningRepository) Create(ctx context.Context, learning *domain.LearningProcess) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	_, requestOK := r.store.requests[learning.RequestID]
+	_, userOK := r.store.users[learning.UserID]
+	_, mentorOK := r.store.mentors[learning.MentorID]
+	if !requestOK || !userOK || !mentorOK {
+		return fmt.Errorf("failed to create learning process: %w", ErrForeignKeyViolation)
+	}
+	for _, rec := range r.store.learnings {
+		if rec.learning.RequestID == learning.RequestID {
+			return fmt.Errorf("failed to create learning process: %w", ErrDuplicateKey)
+		}
+	}
+
+	learning.ID = newID()
+	learning.StartDate = learning.StartDate.Truncate(time.Microsecond)
+	learning.CreatedAt = now()
+	learning.UpdatedAt = learning.CreatedAt
+	learning.PlanUpdatedAt = cloneTime(&learning.CreatedAt)
+
+	r.store.learnings[learning.ID] = &learningRecord{
+		learning: cloneLearning(learning),
+		tenant:   domain.TenantID(ctx),
+		seq:      r.store.nextSeq(),
+	}
+	return nil
+}
+
+// GetByID retrieves a learning process by ID with joined fields
+func (r *LearningRepository) GetByID(ctx context.Context, id string) (*domain.LearningProcess, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.learnings[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrLearningNotFound
+	}
+
+	return r.store.joinLearning(rec), nil
+}
+
+// GetByUserID retrieves all learning processes for a user, newest first
+func (r *LearningRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.LearningProcess, error) {
+	return r.list(ctx, func(lp *domain.LearningProcess) bool { return lp.UserID == userID }), nil
+}
+
+// GetByMentorID retrieves all learning processes for a mentor, newest first
+func (r *LearningRepository) GetByMentorID(ctx context.Context, mentorID string) ([]*domain.LearningProcess, error) {
+	return r.list(ctx, func(lp *domain.LearningProcess) bool { return lp.MentorID == mentorID }), nil
+}
+
+// GetAll retrieves all learning processes, newest first
+func (r *LearningRepository) GetAll(ctx context.Context) ([]*domain.LearningProcess, error) {
+	return r.list(ctx, func(*domain.LearningProcess) bool { return true }), nil
+}
+
+// UpdateMentor moves a learning process from one mentor to another
+func (r *LearningRepository) UpdateMentor(ctx context.Context, learningID, from, to string) error {
+	return r.update(ctx, learningID, func(lp *domain.LearningProcess) error {
+		if lp.MentorID != from {
+			return domain.ErrLearningChanged
+		}
+		if _, ok := r.store.mentors[to]; !ok {
+			return fmt.Errorf("failed to update mentor: %w", ErrForeignKeyViolation)
+		}
+		lp.MentorID = to
+		return nil
+	})
+}
+
+// UpdatePlan updates the learning plan
+func (r *LearningRepository) UpdatePlan(ctx context.Context, id string, plan []domain.LearningPlanItem) error {
+	return r.update(ctx, id, func(lp *domain.LearningProcess) error {
+		lp.Plan = clonePlan(plan)
+		return nil
+	})
+}
+
+// UpdateNotes updates notes; an empty string clears them
+func (r *LearningRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	return r.update(ctx, id, func(lp *domain.LearningProcess) error {
+		if notes == "" {
+			lp.Notes = nil
+		} else {
+			lp.Notes = &notes
+		}
+		return nil
+	})
+}
+
+// Update updates status, plan, feedback, notes and end date of a learning
+// that still has the status from
+func (r *LearningRepository) Update(ctx context.Context, id string, from domain.LearningStatus, learning *domain.LearningProcess) error {
+	return r.update(ctx, id, func(lp *domain.LearningProcess) error {
+		if lp.Status != from {
+			return domain.ErrLearningChanged
+		}
+		lp.Status = learning.Status
+		lp.Plan = clonePlan(learning.Plan)
+		lp.Feedback = cloneFeedback(learning.Feedback)
+		lp.Notes = cloneString(learning.Notes)
+		lp.EndDate = cloneTime(learning.EndDate)
+		return nil
+	})
+}
+
+// Complete marks an active learning as completed with feedback
+func (r *LearningRepository) Complete(ctx context.Context, id string, feedback domain.Feedback) error {
+	return r.update(ctx, id, func(lp *domain.LearningProcess) error {
+		if !lp.IsActive() {
+			return domain.ErrLearningNotActive
+		}
+		endDate := now()
+		lp.Status = domain.LearningCompleted
+		lp.Feedback = &feedback
+		lp.EndDate = &endDate
+		return nil
+	})
+}
+
+// update applies fn to a stored learning under the write lock
+func (r *LearningRepository) update(ctx context.Context, id string, fn func(*domain.LearningProcess) error) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.learnings[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrLearningNotFound
+	}
+
+	updated := cloneLearning(&rec.learning)
+	if err := fn(&updated); err != nil {
+		return err
+	}
+	updated.UpdatedAt = now()
+	if !samePlan(updated.Plan, rec.learning.Plan) {
+		updated.PlanUpdatedAt = cloneTime(&updated.UpdatedAt)
+	}
+	rec.learning = updated
+
+	return nil
+}
+
+// list returns joined learnings matching keep, newest first
+func (r *LearningRepository) list(ctx context.Context, keep func(*domain.LearningProcess) bool) []*domain.LearningProcess {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*learningRecord
+	for _, rec := range r.store.learnings {
+		if rec.tenant == tenant && keep(&rec.learning) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return newerFirst(records[i].learning.CreatedAt, records[i].seq, records[j].learning.CreatedAt, records[j].seq)
+	})
+
+	var learnings []*domain.LearningProcess
+	for _, rec := range records {
+		learnings = append(learnings, r.store.joinLearning(rec))
+	}
+	return learnings
+}
+
+// joinLearning copies a learning and fills request, user and mentor fields;
+// caller holds the lock
+func (s *Store) joinLearning(rec *learningRecord) *domain.LearningProcess {
+	learning := cloneLearning(&rec.learning)
+
+	if req, ok := s.requests[learning.RequestID]; ok {
+		learning.RequestTopic = req.request.Topic
+		learning.RequestDescription = req.request.Description
+	}
+	if u, ok := s.users[learning.UserID]; ok {
+		learning.UserName = u.user.Name
+	}
+	if m, ok := s.mentors[learning.MentorID]; ok {
+		learning.MentorName = m.mentor.Name
+		learning.MentorTelegram = cloneString(m.mentor.Telegram)
+		learning.MentorJobTitle = m.mentor.JobTitle
+		learning.MentorExperience = cloneString(m.mentor.Experience)
+	}
+
+	return &learning
+}
+
+// cloneLearning copies a learning without joined fields
+func cloneLearning(lp *domain.LearningProcess) domain.LearningProcess {
+	return domain.LearningProcess{
+		ID:        lp.ID,
+		RequestID: lp.RequestID,
+		UserID:    lp.UserID,
+		MentorID:  lp.MentorID,
+		Status:    lp.Status,
+		StartDate: lp.StartDate,
+		EndDate:   cloneTime(lp.EndDate),
+		Plan:      clonePlan(lp.Plan),
+		Feedback:  cloneFeedback(lp.Feedback),
+		Notes:     cloneString(lp.Notes),
+		CreatedAt: lp.CreatedAt,
+		UpdatedAt: lp.UpdatedAt,
+
+		PlanUpdatedAt: cloneTime(lp.PlanUpdatedAt),
+	}
+}
+
+// clonePlan copies plan items
+func clonePlan(plan []domain.LearningPlanItem) []domain.LearningPlanItem {
+	if plan == nil {
+		return nil
+	}
+	return append([]domain.LearningPlanItem{}, plan...)
+}
+
+// samePlan checks if two plans have the same items in the same order
+func samePlan(a, b []domain.LearningPlanItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneFeedback copies optional feedback
+func cloneFeedback(f *domain.Feedback) *domain.Feedback {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	return &c
+}
@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type mentorRecord struct {
+	mentor domain.Mentor
+	tenant string
+	seq    int64
+}
+
+type MentorRepository struct {
+	store *Store
+}
+
+func NewMentorRepository(store *Store) *MentorRepository {
+	return &MentorRepository{store: store}
+}
+
+// Create inserts a new mentor
+func (r *MentorRepository) Create(ctx context.Context, mentor *domain.Mentor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := checkWorkload(mentor.Workload, mentor.Capacity); err != nil {
+		return fmt.Errorf("failed to create mentor: %w", err)
+	}
+	tenant := domain.TenantID(ctx)
+	if r.store.mentorEmailTaken(tenant, mentor.Email, "") {
+		return fmt.Errorf("failed to create mentor: %w", ErrDuplicateKey)
+	}
+
+	mentor.ID = newID()
+	mentor.CreatedAt = now()
+	mentor.UpdatedAt = mentor.CreatedAt
+
+	r.store.mentors[mentor.ID] = &mentorRecord{mentor: cloneMentor(mentor), tenant: tenant, seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a mentor by ID
+func (r *MentorRepository) GetByID(ctx context.Context, id string) (*domain.Mentor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.mentors[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrMentorNotFound
+	}
+
+	mentor := cloneMentor(&rec.mentor)
+	return &mentor, nil
+}
+
+// GetAll retrieves mentors matching the filter, ordered by workload then name
+func (r *MentorRepository) GetAll(ctx context.Context, filter domain.MentorFilter) ([]*domain.Mentor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var mentors []*domain.Mentor
+	for _, rec := range r.store.mentors {
+		if rec.tenant != tenant {
+			continue
+		}
+		if filter.MaxWorkload != nil && rec.mentor.Workload > *filter.MaxWorkload {
+			continue
+		}
+		if filter.ActiveOnly && !rec.mentor.IsActive() {
+			continue
+		}
+		if filter.HasCapacity && rec.mentor.Workload >= rec.mentor.Capacity {
+			continue
+		}
+		mentor := cloneMentor(&rec.mentor)
+		mentors = append(mentors, &mentor)
+	}
+
+	sort.Slice(mentors, func(i, j int) bool {
+		if mentors[i].Workload != mentors[j].Workload {
+			return mentors[i].Workload < mentors[j].Workload
+		}
+		return mentors[i].Name < mentors[j].Name
+	})
+
+	return mentors, nil
+}
+
+// Update updates an existing mentor
+func (r *MentorRepository) Update(ctx context.Context, mentor *domain.Mentor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.mentors[mentor.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrMentorNotFound
+	}
+	if err := checkWorkload(mentor.Workload, mentor.Capacity); err != nil {
+		return fmt.Errorf("failed to update mentor: %w", err)
+	}
+	if r.store.mentorEmailTaken(rec.tenant, mentor.Email, mentor.ID) {
+		return fmt.Errorf("failed to update mentor: %w", ErrDuplicateKey)
+	}
+
+	rec.mentor.Name = mentor.Name
+	rec.mentor.JobTitle = mentor.JobTitle
+	rec.mentor.Experience = cloneString(mentor.Experience)
+	rec.mentor.Workload = mentor.Workload
+	rec.mentor.Capacity = mentor.Capacity
+	rec.mentor.Email = mentor.Email
+	rec.mentor.Telegram = cloneString(mentor.Telegram)
+	rec.mentor.Skills = domain.CleanSkills(mentor.Skills)
+	rec.mentor.UpdatedAt = now()
+
+	mentor.UpdatedAt = rec.mentor.UpdatedAt
+	return nil
+}
+
+// UpdateWorkload updates mentor's workload
+func (r *MentorRepository) UpdateWorkload(ctx context.Context, id string, workload int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.mentors[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrMentorNotFound
+	}
+	if err := checkWorkload(workload, rec.mentor.Capacity); err != nil {
+		return fmt.Errorf("failed to update workload: %w", err)
+	}
+
+	rec.mentor.Workload = workload
+	rec.mentor.UpdatedAt = now()
+	return nil
+}
+
+// IncrementWorkload takes n slots of a mentor unless that would exceed its
+// capacity
+func (r *MentorRepository) IncrementWorkload(ctx context.Context, id string, n int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.mentors[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrMentorNotFound
+	}
+	if rec.mentor.Workload+n > rec.mentor.Capacity {
+		return domain.ErrMentorNotAvailable
+	}
+
+	rec.mentor.Workload += n
+	rec.mentor.UpdatedAt = now()
+	return nil
+}
+
+// DecrementWorkload frees n slots of a mentor, stopping at zero
+func (r *MentorRepository) DecrementWorkload(ctx context.Context, id string, n int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.mentors[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrMentorNotFound
+	}
+
+	rec.mentor.Workload = max(rec.mentor.Workload-n, 0)
+	rec.mentor.UpdatedAt = now()
+	return nil
+}
+
+// UpdateDeactivatedAt deactivates a mentor, or reactivates them when
+// deactivatedAt is nil
+func (r *MentorRepository) UpdateDeactivatedAt(ctx context.Context, id string, deactivatedAt *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.mentors[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrMentorNotFound
+	}
+
+	rec.mentor.DeactivatedAt = truncateTime(deactivatedAt)
+	rec.mentor.UpdatedAt = now()
+	return nil
+}
+
+// Delete removes a mentor and their availability; fails while learnings
+// reference it (ON DELETE RESTRICT)
+func (r *MentorRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.mentors[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrMentorNotFound
+	}
+	for _, rec := range r.store.learnings {
+		if rec.learning.MentorID == id {
+			return fmt.Errorf("failed to delete mentor: %w", ErrForeignKeyViolation)
+		}
+	}
+	for _, rec := range r.store.feedback {
+		if rec.response.MentorID == id {
+			return fmt.Errorf("failed to delete mentor: %w", ErrForeignKeyViolation)
+		}
+	}
+
+	delete(r.store.mentors, id)
+	for windowID, rec := range r.store.windows {
+		if rec.window.MentorID == id {
+			delete(r.store.windows, windowID)
+		}
+	}
+	for absenceID, rec := range r.store.absences {
+		if rec.absence.MentorID == id {
+			delete(r.store.absences, absenceID)
+		}
+	}
+	return nil
+}
+
+// mentorEmailTaken checks the unique email index of the tenant; caller holds
+// the lock
+func (s *Store) mentorEmailTaken(tenant, email, exceptID string) bool {
+	for id, rec := range s.mentors {
+		if id != exceptID && rec.tenant == tenant && rec.mentor.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWorkload mirrors CHECK (capacity BETWEEN 1 AND 20) and
+// CHECK (workload >= 0 AND workload <= capacity)
+func checkWorkload(workload, capacity int) error {
+	if capacity < 1 || capacity > domain.MaxMentorCapacity {
+		return ErrCheckViolation
+	}
+	if workload < 0 || workload > capacity {
+		return ErrCheckViolation
+	}
+	return nil
+}
+
+// cloneMentor copies a mentor so callers cannot mutate stored state
+func cloneMentor(m *domain.Mentor) domain.Mentor {
+	c := *m
+	c.Experience = cloneString(m.Experience)
+	c.Telegram = cloneString(m.Telegram)
+	c.Skills = domain.CleanSkills(m.Skills)
+	c.DeactivatedAt = cloneTime(m.DeactivatedAt)
+	return c
+}
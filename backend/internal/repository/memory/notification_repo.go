@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type notificationRecord struct {
+	notification domain.Notification
+	tenant       string
+	seq          int64
+}
+
+type NotificationRepository struct {
+	store *Store
+}
+
+func NewNotificationRepository(store *Store) *NotificationRepository {
+	return &NotificationRepository{store: store}
+}
+
+// Create inserts a new notification
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[notification.UserID]; !ok {
+		return fmt.Errorf("failed to create notification: %w", ErrForeignKeyViolation)
+	}
+
+	notification.ID = newID()
+	notification.CreatedAt = now()
+	notification.ReadAt = nil
+
+	r.store.notifications[notification.ID] = &notificationRecord{
+		notification: cloneNotification(notification),
+		tenant:       domain.TenantID(ctx),
+		seq:          r.store.nextSeq(),
+	}
+	return nil
+}
+
+// GetByUserID retrieves notifications of a user, newest first
+func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*domain.Notification, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*notificationRecord
+	for _, rec := range r.store.notifications {
+		if rec.tenant == tenant && rec.notification.UserID == userID && (!unreadOnly || !rec.notification.IsRead()) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return newerFirst(records[i].notification.CreatedAt, records[i].seq, records[j].notification.CreatedAt, records[j].seq)
+	})
+
+	notifications := make([]*domain.Notification, 0, len(records))
+	for _, rec := range records {
+		notification := cloneNotification(&rec.notification)
+		notifications = append(notifications, &notification)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks a notification of the user as read; reading it again keeps
+// the first read time
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.notifications[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.notification.UserID != userID {
+		return domain.ErrNotificationNotFound
+	}
+
+	if rec.notification.ReadAt == nil {
+		readAt := now()
+		rec.notification.ReadAt = &readAt
+	}
+	return nil
+}
+
+// cloneNotification copies a notification so callers cannot mutate stored state
+func cloneNotification(n *domain.Notification) domain.Notification {
+	c := *n
+	c.ReadAt = cloneTime(n.ReadAt)
+	return c
+}
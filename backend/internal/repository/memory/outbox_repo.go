@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type outboxRecord struct {
+	event  domain.OutboxEvent
+	tenant string
+	seq    int64
+}
+
+type OutboxRepository struct {
+	store *Store
+}
+
+func NewOutboxRepository(store *Store) *OutboxRepository {
+	return &OutboxRepository{store: store}
+}
+
+// Append writes an event to the outbox
+func (r *OutboxRepository) Append(ctx context.Context, event *domain.OutboxEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event.ID = newID()
+	event.CreatedAt = now()
+	event.DispatchedAt = nil
+
+	r.store.outbox[event.ID] = &outboxRecord{event: cloneOutboxEvent(event), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// ListUndispatched returns up to limit undispatched events, oldest first
+func (r *OutboxRepository) ListUndispatched(ctx context.Context, limit int) ([]*domain.OutboxEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*outboxRecord, 0)
+	for _, rec := range r.store.outbox {
+		if rec.tenant == tenant && rec.event.DispatchedAt == nil {
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return newerFirst(recs[j].event.CreatedAt, recs[j].seq, recs[i].event.CreatedAt, recs[i].seq)
+	})
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+
+	events := make([]*domain.OutboxEvent, 0, len(recs))
+	for _, rec := range recs {
+		event := cloneOutboxEvent(&rec.event)
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+// MarkDispatched records that the event was fanned out to its subscriptions
+func (r *OutboxRepository) MarkDispatched(ctx context.Context, id string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.outbox[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return fmt.Errorf("outbox event %s not found", id)
+	}
+	dispatchedAt := at.Truncate(time.Microsecond)
+	rec.event.DispatchedAt = &dispatchedAt
+	return nil
+}
+
+// DeleteDispatched removes events dispatched before the time whose
+// deliveries all went through, together with those deliveries
+func (r *OutboxRepository) DeleteDispatched(ctx context.Context, before time.Time) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	open := make(map[string]bool)
+	for _, rec := range r.store.deliveries {
+		if rec.delivery.Status != domain.DeliveryDelivered {
+			open[rec.delivery.EventID] = true
+		}
+	}
+
+	tenant := domain.TenantID(ctx)
+	deleted := 0
+	for id, rec := range r.store.outbox {
+		if rec.tenant != tenant || rec.event.DispatchedAt == nil || !rec.event.DispatchedAt.Before(before) || open[id] {
+			continue
+		}
+		delete(r.store.outbox, id)
+		for deliveryID, d := range r.store.deliveries {
+			if d.delivery.EventID == id {
+				delete(r.store.deliveries, deliveryID)
+			}
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// cloneOutboxEvent copies an event
+func cloneOutboxEvent(e *domain.OutboxEvent) domain.OutboxEvent {
+	v := *e
+	v.Payload = slices.Clone(e.Payload)
+	v.DispatchedAt = cloneTime(e.DispatchedAt)
+	return v
+}
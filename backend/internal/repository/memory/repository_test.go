@@ -0,0 +1,41 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/memory"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/repotest"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := memory.NewStore()
+		return repotest.Repositories{
+			Tenants:       memory.NewTenantRepository(store),
+			Users:         memory.NewUserRepository(store),
+			Roles:         memory.NewRoleRepository(store),
+			Departments:   memory.NewDepartmentRepository(store),
+			Requests:      memory.NewRequestRepository(store),
+			Mentors:       memory.NewMentorRepository(store),
+			Learnings:     memory.NewLearningRepository(store),
+			Notifications: memory.NewNotificationRepository(store),
+			Availability:  memory.NewAvailabilityRepository(store),
+			Approvals:     memory.NewApprovalRepository(store),
+			Comments:      memory.NewCommentRepository(store),
+			Attachments:   memory.NewAttachmentRepository(store),
+			Courses:       memory.NewCourseRepository(store),
+			Enrollments:   memory.NewEnrollmentRepository(store),
+			Skills:        memory.NewSkillRepository(store),
+			Competencies:  memory.NewCompetencyRepository(store),
+			Certificates:  memory.NewCertificateRepository(store),
+			Tx:            memory.NewTxManager(store),
+
+			Questionnaires: memory.NewQuestionnaireRepository(store),
+			Feedback:       memory.NewFeedbackRepository(store),
+			CheckIns:       memory.NewCheckInRepository(store),
+			Jobs:           memory.NewJobRepository(store),
+			Outbox:         memory.NewOutboxRepository(store),
+			Webhooks:       memory.NewWebhookRepository(store),
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type requestRecord struct {
+	request domain.TrainingRequest
+	tenant  string
+	seq     int64
+}
+
+type RequestRepository struct {
+	store *Store
+}
+
+func NewRequestRepository(store *Store) *RequestRepository {
+	return &RequestRepository{store: store}
+}
+
+// Create inserts a new training request; a request created as queued joins
+// the end of the queue
+func (r *RequestRepository) Create(ctx context.Context, request *domain.TrainingRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[request.UserID]; !ok {
+		return fmt.Errorf("failed to create training request: %w", ErrForeignKeyViolation)
+	}
+	if request.CourseID != nil {
+		if _, ok := r.store.courses[*request.CourseID]; !ok {
+			return fmt.Errorf("failed to create training request: %w", ErrForeignKeyViolation)
+		}
+	}
+
+	request.ID = newID()
+	request.CreatedAt = now()
+	request.UpdatedAt = request.CreatedAt
+
+	request.QueuedAt = nil
+	if request.IsQueued() {
+		queuedAt := request.CreatedAt
+		request.QueuedAt = &queuedAt
+	}
+
+	rec := &requestRecord{request: *request, tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	rec.request.QueuedAt = cloneTime(request.QueuedAt)
+	rec.request.ApprovalChain = cloneChain(request.ApprovalChain)
+	rec.request.CourseID = cloneString(request.CourseID)
+	rec.request.Skills = domain.CleanSkills(request.Skills)
+	rec.request.UserName, rec.request.UserJobTitle, rec.request.UserTelegram = "", nil, nil
+	r.store.requests[request.ID] = rec
+
+	return nil
+}
+
+// GetByID retrieves a training request by ID with user data
+func (r *RequestRepository) GetByID(ctx context.Context, id string) (*domain.TrainingRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.requests[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrRequestNotFound
+	}
+
+	return r.store.joinRequest(rec), nil
+}
+
+// GetByUserID retrieves all training requests for a user, newest first
+func (r *RequestRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.TrainingRequest, error) {
+	return r.list(ctx, func(req *domain.TrainingRequest) bool {
+		return req.UserID == userID
+	}), nil
+}
+
+// GetAll retrieves all training requests with optional status filter
+func (r *RequestRepository) GetAll(ctx context.Context, status *string) ([]*domain.TrainingRequest, error) {
+	return r.list(ctx, func(req *domain.TrainingRequest) bool {
+		return status == nil || string(req.Status) == *status
+	}), nil
+}
+
+// GetByManagerID retrieves the training requests of the users reporting to
+// a manager, with optional status filter, newest first
+func (r *RequestRepository) GetByManagerID(ctx context.Context, managerID string, status *string) ([]*domain.TrainingRequest, error) {
+	return r.list(ctx, func(req *domain.TrainingRequest) bool {
+		u, ok := r.store.users[req.UserID]
+		return ok && u.user.ManagerID != nil && *u.user.ManagerID == managerID &&
+			(status == nil || string(req.Status) == *status)
+	}), nil
+}
+
+// Update updates the topic, description and skill tags of a training request
+func (r *RequestRepository) Update(ctx context.Context, req *domain.TrainingRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.requests[req.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrRequestNotFound
+	}
+
+	rec.request.Topic = req.Topic
+	rec.request.Description = req.Description
+	rec.request.Skills = domain.CleanSkills(req.Skills)
+	rec.request.UpdatedAt = now()
+
+	req.UpdatedAt = rec.request.UpdatedAt
+	return nil
+}
+
+// UpdateStatus changes the status of a request that still has the status
+// from
+func (r *RequestRepository) UpdateStatus(ctx context.Context, id string, from, to domain.RequestStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.requests[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrRequestNotFound
+	}
+	if rec.request.Status != from {
+		return domain.ErrRequestChanged
+	}
+
+	rec.request.Status = to
+	rec.request.UpdatedAt = now()
+	return nil
+}
+
+// Enqueue puts a request that still has the status from in the queue with
+// the given priority; a request that is already queued keeps its place
+// among equal priorities
+func (r *RequestRepository) Enqueue(ctx context.Context, id string, from domain.RequestStatus, priority int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.requests[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrRequestNotFound
+	}
+	if rec.request.Status != from {
+		return domain.ErrRequestChanged
+	}
+
+	if !rec.request.IsQueued() {
+		queuedAt := now()
+		rec.request.QueuedAt = &queuedAt
+	}
+	rec.request.Status = domain.RequestQueued
+	rec.request.Priority = priority
+	rec.request.UpdatedAt = now()
+	return nil
+}
+
+// GetQueue retrieves queued requests, highest priority first, then oldest
+func (r *RequestRepository) GetQueue(ctx context.Context) ([]*domain.TrainingRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*requestRecord
+	for _, rec := range r.store.requests {
+		if rec.tenant == tenant && rec.request.IsQueued() {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i].request, records[j].request
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if !a.QueuedAt.Equal(*b.QueuedAt) {
+			return a.QueuedAt.Before(*b.QueuedAt)
+		}
+		return records[i].seq < records[j].seq
+	})
+
+	requests := make([]*domain.TrainingRequest, 0, len(records))
+	for _, rec := range records {
+		requests = append(requests, r.store.joinRequest(rec))
+	}
+	return requests, nil
+}
+
+// list returns joined requests matching keep, newest first
+func (r *RequestRepository) list(ctx context.Context, keep func(*domain.TrainingRequest) bool) []*domain.TrainingRequest {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	var records []*requestRecord
+	for _, rec := range r.store.requests {
+		if rec.tenant == tenant && keep(&rec.request) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return newerFirst(records[i].request.CreatedAt, records[i].seq, records[j].request.CreatedAt, records[j].seq)
+	})
+
+	var requests []*domain.TrainingRequest
+	for _, rec := range records {
+		requests = append(requests, r.store.joinRequest(rec))
+	}
+	return requests
+}
+
+// joinRequest copies a request and fills user fields; caller holds the lock
+func (s *Store) joinRequest(rec *requestRecord) *domain.TrainingRequest {
+	request := rec.request
+	request.QueuedAt = cloneTime(rec.request.QueuedAt)
+	request.ApprovalChain = cloneChain(rec.request.ApprovalChain)
+	request.CourseID = cloneString(rec.request.CourseID)
+	request.Skills = domain.CleanSkills(rec.request.Skills)
+	if u, ok := s.users[request.UserID]; ok {
+		request.UserName = u.user.Name
+		request.UserJobTitle = cloneString(u.user.JobTitle)
+		request.UserTelegram = cloneString(u.user.Telegram)
+	}
+	return &request
+}
+
+// cloneChain copies an approval chain; like the TEXT[] column it is never
+// nil
+func cloneChain(chain []domain.ApprovalStage) []domain.ApprovalStage {
+	return append(make([]domain.ApprovalStage, 0, len(chain)), chain...)
+}
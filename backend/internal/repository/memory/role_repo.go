@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type roleRecord struct {
+	role   domain.Role
+	tenant string
+	seq    int64
+}
+
+// RoleRepository keeps roles in the store, keyed by tenant and name. The
+// default tenant is seeded with domain.DefaultRoles like the migrations seed
+// the roles table.
+type RoleRepository struct {
+	store *Store
+}
+
+func NewRoleRepository(store *Store) *RoleRepository {
+	return &RoleRepository{store: store}
+}
+
+// Create inserts a new role
+func (r *RoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	if _, ok := r.store.roles[roleKey(tenant, role.Name)]; ok {
+		return domain.ErrRoleExists
+	}
+
+	role.Permissions = clonePermissions(role.Permissions)
+	role.CreatedAt = now()
+	role.UpdatedAt = role.CreatedAt
+
+	r.store.roles[roleKey(tenant, role.Name)] = &roleRecord{role: cloneRole(role), tenant: tenant, seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByName retrieves a role by its name
+func (r *RoleRepository) GetByName(ctx context.Context, name domain.UserRole) (*domain.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.roles[roleKey(domain.TenantID(ctx), name)]
+	if !ok {
+		return nil, domain.ErrRoleNotFound
+	}
+	role := cloneRole(&rec.role)
+	return &role, nil
+}
+
+// GetAll retrieves all roles, built-in ones first
+func (r *RoleRepository) GetAll(ctx context.Context) ([]*domain.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*roleRecord, 0, len(r.store.roles))
+	for _, rec := range r.store.roles {
+		if rec.tenant == tenant {
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		a, b := recs[i], recs[j]
+		if a.role.BuiltIn != b.role.BuiltIn {
+			return a.role.BuiltIn
+		}
+		if !a.role.CreatedAt.Equal(b.role.CreatedAt) {
+			return a.role.CreatedAt.Before(b.role.CreatedAt)
+		}
+		return a.seq < b.seq
+	})
+
+	roles := make([]*domain.Role, 0, len(recs))
+	for _, rec := range recs {
+		role := cloneRole(&rec.role)
+		roles = append(roles, &role)
+	}
+	return roles, nil
+}
+
+// Update replaces the description, permissions and scope of a role
+func (r *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.roles[roleKey(domain.TenantID(ctx), role.Name)]
+	if !ok {
+		return domain.ErrRoleNotFound
+	}
+
+	rec.role.Description = role.Description
+	rec.role.Permissions = clonePermissions(role.Permissions)
+	rec.role.Scope = role.Scope
+	rec.role.UpdatedAt = now()
+
+	role.BuiltIn = rec.role.BuiltIn
+	role.CreatedAt = rec.role.CreatedAt
+	role.UpdatedAt = rec.role.UpdatedAt
+	return nil
+}
+
+// Delete removes a role that no user is assigned
+func (r *RoleRepository) Delete(ctx context.Context, name domain.UserRole) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	if _, ok := r.store.roles[roleKey(tenant, name)]; !ok {
+		return domain.ErrRoleNotFound
+	}
+	for _, rec := range r.store.users {
+		if rec.tenant == tenant && rec.user.Role == name {
+			return domain.ErrRoleInUse
+		}
+	}
+
+	delete(r.store.roles, roleKey(tenant, name))
+	return nil
+}
+
+// roleKey keys the roles table by its primary key
+func roleKey(tenant string, name domain.UserRole) string {
+	return tenant + "/" + string(name)
+}
+
+// permissionsOf resolves the permissions a role of the tenant grants and
+// where they apply like the SQL JOIN on roles; caller holds the lock
+func (s *Store) permissionsOf(tenant string, role domain.UserRole) ([]domain.Permission, domain.RoleScope) {
+	rec, ok := s.roles[roleKey(tenant, role)]
+	if !ok {
+		return []domain.Permission{}, domain.ScopeOrganization
+	}
+	return clonePermissions(rec.role.Permissions), rec.role.Scope
+}
+
+// seedRoles stores the built-in roles of the default tenant
+func (s *Store) seedRoles() {
+	for _, role := range domain.DefaultRoles() {
+		role.CreatedAt = now()
+		role.UpdatedAt = role.CreatedAt
+		s.roles[roleKey(domain.DefaultTenantID, role.Name)] = &roleRecord{role: *role, tenant: domain.DefaultTenantID, seq: s.nextSeq()}
+	}
+}
+
+// cloneRole copies a role so callers cannot mutate stored state
+func cloneRole(r *domain.Role) domain.Role {
+	c := *r
+	c.Permissions = clonePermissions(r.Permissions)
+	return c
+}
+
+func clonePermissions(permissions []domain.Permission) []domain.Permission {
+	if permissions == nil {
+		return []domain.Permission{}
+	}
+	return slices.Clone(permissions)
+}
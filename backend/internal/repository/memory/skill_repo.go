@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type skillRecord struct {
+	skill  domain.Skill
+	tenant string
+	seq    int64
+}
+
+type SkillRepository struct {
+	store *Store
+}
+
+func NewSkillRepository(store *Store) *SkillRepository {
+	return &SkillRepository{store: store}
+}
+
+// Create inserts a new skill; names are unique ignoring case
+func (r *SkillRepository) Create(ctx context.Context, skill *domain.Skill) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	if r.store.skillNameTaken(tenant, skill.Name, "") {
+		return domain.ErrSkillExists
+	}
+
+	skill.ID = newID()
+	skill.CreatedAt = now()
+	skill.UpdatedAt = skill.CreatedAt
+
+	r.store.skills[skill.ID] = &skillRecord{skill: *skill, tenant: tenant, seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a skill by its ID
+func (r *SkillRepository) GetByID(ctx context.Context, id string) (*domain.Skill, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.skills[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrSkillNotFound
+	}
+	skill := rec.skill
+	return &skill, nil
+}
+
+// GetAll retrieves the competency model ordered by name
+func (r *SkillRepository) GetAll(ctx context.Context) ([]*domain.Skill, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	skills := make([]*domain.Skill, 0, len(r.store.skills))
+	for _, rec := range r.store.skills {
+		if rec.tenant != tenant {
+			continue
+		}
+		skill := rec.skill
+		skills = append(skills, &skill)
+	}
+	sort.Slice(skills, func(i, j int) bool {
+		return strings.ToLower(skills[i].Name) < strings.ToLower(skills[j].Name)
+	})
+	return skills, nil
+}
+
+// Update renames or redescribes a skill
+func (r *SkillRepository) Update(ctx context.Context, skill *domain.Skill) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	rec, ok := r.store.skills[skill.ID]
+	if !ok || rec.tenant != tenant {
+		return domain.ErrSkillNotFound
+	}
+	if r.store.skillNameTaken(tenant, skill.Name, skill.ID) {
+		return domain.ErrSkillExists
+	}
+
+	rec.skill.Name = skill.Name
+	rec.skill.Description = skill.Description
+	rec.skill.UpdatedAt = now()
+
+	skill.CreatedAt = rec.skill.CreatedAt
+	skill.UpdatedAt = rec.skill.UpdatedAt
+	return nil
+}
+
+// Delete removes a skill with its targets and recorded levels
+func (r *SkillRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.skills[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrSkillNotFound
+	}
+	for tid, rec := range r.store.skillTargets {
+		if rec.target.SkillID == id {
+			delete(r.store.skillTargets, tid)
+		}
+	}
+	for key, rec := range r.store.userSkills {
+		if rec.userSkill.SkillID == id {
+			delete(r.store.userSkills, key)
+		}
+	}
+	delete(r.store.skills, id)
+	return nil
+}
+
+// skillNameTaken mirrors the unique index on (tenantId, lower(name)); caller
+// holds the lock
+func (s *Store) skillNameTaken(tenant, name, exceptID string) bool {
+	for id, rec := range s.skills {
+		if id != exceptID && rec.tenant == tenant && strings.EqualFold(rec.skill.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Constraint errors mirroring the Postgres schema
+var (
+	ErrDuplicateKey        = errors.New("duplicate key value violates unique constraint")
+	ErrForeignKeyViolation = errors.New("insert or update violates foreign key constraint")
+	ErrCheckViolation      = errors.New("new row violates check constraint")
+)
+
+// Store is an in-memory database shared by the repositories so that joined
+// fields (user names, request topics, mentor details) resolve the same way
+// as the SQL JOINs in repository/postgres
+type Store struct {
+	mu  sync.RWMutex
+	seq int64
+
+	tenants       map[string]*tenantRecord
+	users         map[string]*userRecord
+	roles         map[string]*roleRecord // keyed by roleKey
+	departments   map[string]*departmentRecord
+	requests      map[string]*requestRecord
+	mentors       map[string]*mentorRecord
+	learnings     map[string]*learningRecord
+	notifications map[string]*notificationRecord
+	windows       map[string]*windowRecord
+	absences      map[string]*absenceRecord
+	approvals     map[string]*approvalRecord
+	comments      map[string]*commentRecord
+	attachments   map[string]*attachmentRecord
+	courses       map[string]*courseRecord
+	enrollments   map[string]*enrollmentRecord
+	skills        map[string]*skillRecord
+	skillTargets  map[string]*skillTargetRecord
+	userSkills    map[string]*userSkillRecord // keyed by userSkillKey
+	certificates  map[string]*certificateRecord
+
+	questionnaires map[string]*questionnaireRecord
+	feedback       map[string]*feedbackRecord
+	checkIns       map[string]*checkInRecord
+	stallAlerts    map[string]*stallAlertRecord // keyed by learning ID
+	jobs           map[string]*jobRecord
+	jobSchedules   map[string]*jobScheduleRecord // keyed by schedule name
+	outbox         map[string]*outboxRecord
+	webhooks       map[string]*webhookRecord
+	deliveries     map[string]*deliveryRecord
+}
+
+// NewStore creates a store holding only the default tenant and its
+// built-in roles
+func NewStore() *Store {
+	s := &Store{
+		tenants:       make(map[string]*tenantRecord),
+		users:         make(map[string]*userRecord),
+		roles:         make(map[string]*roleRecord),
+		departments:   make(map[string]*departmentRecord),
+		requests:      make(map[string]*requestRecord),
+		mentors:       make(map[string]*mentorRecord),
+		learnings:     make(map[string]*learningRecord),
+		notifications: make(map[string]*notificationRecord),
+		windows:       make(map[string]*windowRecord),
+		absences:      make(map[string]*absenceRecord),
+		approvals:     make(map[string]*approvalRecord),
+		comments:      make(map[string]*commentRecord),
+		attachments:   make(map[string]*attachmentRecord),
+		courses:       make(map[string]*courseRecord),
+		enrollments:   make(map[string]*enrollmentRecord),
+		skills:        make(map[string]*skillRecord),
+		skillTargets:  make(map[string]*skillTargetRecord),
+		userSkills:    make(map[string]*userSkillRecord),
+		certificates:  make(map[string]*certificateRecord),
+
+		questionnaires: make(map[string]*questionnaireRecord),
+		feedback:       make(map[string]*feedbackRecord),
+		checkIns:       make(map[string]*checkInRecord),
+		stallAlerts:    make(map[string]*stallAlertRecord),
+		jobs:           make(map[string]*jobRecord),
+		jobSchedules:   make(map[string]*jobScheduleRecord),
+		outbox:         make(map[string]*outboxRecord),
+		webhooks:       make(map[string]*webhookRecord),
+		deliveries:     make(map[string]*deliveryRecord),
+	}
+	s.seedTenant()
+	s.seedRoles()
+	return s
+}
+
+// nextSeq returns an increasing insertion counter used to keep ordering
+// stable when timestamps collide
+func (s *Store) nextSeq() int64 {
+	s.seq++
+	return s.seq
+}
+
+// newID generates a primary key like gen_random_uuid()
+func newID() string {
+	return uuid.New().String()
+}
+
+// now returns the current timestamp with Postgres (microsecond) precision
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// newerFirst orders records by creation time descending
+func newerFirst(aCreated time.Time, aSeq int64, bCreated time.Time, bSeq int64) bool {
+	if !aCreated.Equal(bCreated) {
+		return aCreated.After(bCreated)
+	}
+	return aSeq > bSeq
+}
+
+// cloneString copies an optional string
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+// cloneTime copies an optional timestamp
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
+
+// truncateTime copies an optional timestamp at Postgres precision
+func truncateTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.Truncate(time.Microsecond)
+	return &v
+}
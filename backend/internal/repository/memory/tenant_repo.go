@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type tenantRecord struct {
+	tenant domain.Tenant
+	seq    int64
+}
+
+// TenantRepository keeps tenants in the store. The default tenant is seeded
+// like the migrations seed the tenants table; unlike the other repositories
+// it ignores the tenant of the context.
+type TenantRepository struct {
+	store *Store
+}
+
+func NewTenantRepository(store *Store) *TenantRepository {
+	return &TenantRepository{store: store}
+}
+
+// Create inserts a new tenant with its hosts; slugs and hosts are unique
+func (r *TenantRepository) Create(ctx context.Context, tenant *domain.Tenant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, rec := range r.store.tenants {
+		if rec.tenant.Slug == tenant.Slug {
+			return domain.ErrTenantExists
+		}
+	}
+	if r.store.hostTaken(tenant.Hosts, "") {
+		return domain.ErrTenantExists
+	}
+
+	tenant.ID = newID()
+	tenant.CreatedAt = now()
+	tenant.UpdatedAt = tenant.CreatedAt
+
+	r.store.tenants[tenant.ID] = &tenantRecord{tenant: cloneTenant(tenant), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a tenant by its ID
+func (r *TenantRepository) GetByID(ctx context.Context, id string) (*domain.Tenant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.tenants[id]
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	tenant := cloneTenant(&rec.tenant)
+	return &tenant, nil
+}
+
+// GetByIDForUpdate retrieves a tenant by its ID; transactions of the store
+// already run one at a time, so there is nothing to lock
+func (r *TenantRepository) GetByIDForUpdate(ctx context.Context, id string) (*domain.Tenant, error) {
+	return r.GetByID(ctx, id)
+}
+
+// GetBySCIMTokenHash retrieves the tenant a SCIM token with the hash was
+// issued for
+func (r *TenantRepository) GetBySCIMTokenHash(ctx context.Context, hash string) (*domain.Tenant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, rec := range r.store.tenants {
+		if hash != "" && rec.tenant.Settings.SCIM.TokenHash == hash {
+			tenant := cloneTenant(&rec.tenant)
+			return &tenant, nil
+		}
+	}
+	return nil, domain.ErrTenantNotFound
+}
+
+// GetByHost retrieves the tenant a normalised host name resolves to
+func (r *TenantRepository) GetByHost(ctx context.Context, host string) (*domain.Tenant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, rec := range r.store.tenants {
+		if slices.Contains(rec.tenant.Hosts, host) {
+			tenant := cloneTenant(&rec.tenant)
+			return &tenant, nil
+		}
+	}
+	return nil, domain.ErrTenantNotFound
+}
+
+// GetAll retrieves every tenant, oldest first
+func (r *TenantRepository) GetAll(ctx context.Context) ([]*domain.Tenant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	recs := make([]*tenantRecord, 0, len(r.store.tenants))
+	for _, rec := range r.store.tenants {
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return newerFirst(recs[j].tenant.CreatedAt, recs[j].seq, recs[i].tenant.CreatedAt, recs[i].seq)
+	})
+
+	tenants := make([]*domain.Tenant, 0, len(recs))
+	for _, rec := range recs {
+		tenant := cloneTenant(&rec.tenant)
+		tenants = append(tenants, &tenant)
+	}
+	return tenants, nil
+}
+
+// Update replaces the name, settings and hosts of a tenant; the slug stays
+func (r *TenantRepository) Update(ctx context.Context, tenant *domain.Tenant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.tenants[tenant.ID]
+	if !ok {
+		return domain.ErrTenantNotFound
+	}
+	if r.store.hostTaken(tenant.Hosts, tenant.ID) {
+		return domain.ErrTenantExists
+	}
+
+	rec.tenant.Name = tenant.Name
+	rec.tenant.Settings = tenant.Settings
+	rec.tenant.Hosts = sortedHosts(tenant.Hosts)
+	rec.tenant.UpdatedAt = now()
+
+	*tenant = cloneTenant(&rec.tenant)
+	return nil
+}
+
+// hostTaken mirrors the primary key of tenant_hosts; caller holds the lock
+func (s *Store) hostTaken(hosts []string, exceptID string) bool {
+	for id, rec := range s.tenants {
+		if id == exceptID {
+			continue
+		}
+		for _, host := range hosts {
+			if slices.Contains(rec.tenant.Hosts, host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// seedTenant stores the default tenant
+func (s *Store) seedTenant() {
+	tenant := domain.Tenant{
+		ID:       domain.DefaultTenantID,
+		Slug:     "default",
+		Name:     "Default",
+		Hosts:    []string{},
+		Settings: domain.TenantSettings{MentorCapacity: domain.DefaultMentorCapacity},
+	}
+	tenant.CreatedAt = now()
+	tenant.UpdatedAt = tenant.CreatedAt
+	s.tenants[tenant.ID] = &tenantRecord{tenant: tenant, seq: s.nextSeq()}
+}
+
+// cloneTenant copies a tenant with its hosts sorted like the SQL ARRAY
+// subquery
+func cloneTenant(t *domain.Tenant) domain.Tenant {
+	v := *t
+	v.Hosts = sortedHosts(t.Hosts)
+	return v
+}
+
+func sortedHosts(hosts []string) []string {
+	sorted := append(make([]string, 0, len(hosts)), hosts...)
+	slices.Sort(sorted)
+	return sorted
+}
@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// TxManager gives the store all-or-nothing semantics: it snapshots the data
+// before fn and restores it if fn fails. Transactions are serialized with
+// each other but, unlike Postgres, are not isolated from writes made outside
+// a transaction; that is enough for tests.
+type TxManager struct {
+	store *Store
+	mu    sync.Mutex
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+type txKey struct{}
+
+// WithinTx runs fn and rolls the store back if it returns an error. Nested
+// calls join the outer transaction.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := m.store.snapshot()
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		m.store.restore(snapshot)
+		return err
+	}
+	return nil
+}
+
+// storeData is a deep copy of the store contents
+type storeData struct {
+	tenants       map[string]*tenantRecord
+	users         map[string]*userRecord
+	roles         map[string]*roleRecord
+	departments   map[string]*departmentRecord
+	requests      map[string]*requestRecord
+	mentors       map[string]*mentorRecord
+	learnings     map[string]*learningRecord
+	notifications map[string]*notificationRecord
+	windows       map[string]*windowRecord
+	absences      map[string]*absenceRecord
+	approvals     map[string]*approvalRecord
+	comments      map[string]*commentRecord
+	attachments   map[string]*attachmentRecord
+	courses       map[string]*courseRecord
+	enrollments   map[string]*enrollmentRecord
+	skills        map[string]*skillRecord
+	skillTargets  map[string]*skillTargetRecord
+	userSkills    map[string]*userSkillRecord
+	certificates  map[string]*certificateRecord
+
+	questionnaires map[string]*questionnaireRecord
+	feedback       map[string]*feedbackRecord
+	checkIns       map[string]*checkInRecord
+	stallAlerts    map[string]*stallAlertRecord
+	jobs           map[string]*jobRecord
+	jobSchedules   map[string]*jobScheduleRecord
+	outbox         map[string]*outboxRecord
+	webhooks       map[string]*webhookRecord
+	deliveries     map[string]*deliveryRecord
+}
+
+func (s *Store) snapshot() storeData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return storeData{
+		tenants: copyRecords(s.tenants, func(r tenantRecord) tenantRecord {
+			r.tenant = cloneTenant(&r.tenant)
+			return r
+		}),
+		users: copyRecords(s.users, func(r userRecord) userRecord {
+			r.user = cloneUser(&r.user)
+			return r
+		}),
+		roles: copyRecords(s.roles, func(r roleRecord) roleRecord {
+			r.role = cloneRole(&r.role)
+			return r
+		}),
+		departments: copyRecords(s.departments, func(r departmentRecord) departmentRecord {
+			r.department = cloneDepartment(&r.department)
+			return r
+		}),
+		requests: copyRecords(s.requests, func(r requestRecord) requestRecord {
+			r.request.QueuedAt = cloneTime(r.request.QueuedAt)
+			r.request.ApprovalChain = cloneChain(r.request.ApprovalChain)
+			r.request.CourseID = cloneString(r.request.CourseID)
+			r.request.Skills = domain.CleanSkills(r.request.Skills)
+			return r
+		}),
+		mentors: copyRecords(s.mentors, func(r mentorRecord) mentorRecord {
+			r.mentor = cloneMentor(&r.mentor)
+			return r
+		}),
+		learnings: copyRecords(s.learnings, func(r learningRecord) learningRecord {
+			r.learning = cloneLearning(&r.learning)
+			return r
+		}),
+		notifications: copyRecords(s.notifications, func(r notificationRecord) notificationRecord {
+			r.notification = cloneNotification(&r.notification)
+			return r
+		}),
+		windows: copyRecords(s.windows, func(r windowRecord) windowRecord { return r }),
+		absences: copyRecords(s.absences, func(r absenceRecord) absenceRecord {
+			r.absence = cloneAbsence(&r.absence)
+			return r
+		}),
+		approvals: copyRecords(s.approvals, func(r approvalRecord) approvalRecord {
+			r.decision = cloneDecision(&r.decision)
+			return r
+		}),
+		comments: copyRecords(s.comments, func(r commentRecord) commentRecord {
+			r.comment = cloneComment(&r.comment)
+			return r
+		}),
+		attachments: copyRecords(s.attachments, func(r attachmentRecord) attachmentRecord {
+			r.attachment = cloneAttachment(&r.attachment)
+			return r
+		}),
+		courses: copyRecords(s.courses, func(r courseRecord) courseRecord {
+			r.course = cloneCourse(&r.course)
+			return r
+		}),
+		enrollments: copyRecords(s.enrollments, func(r enrollmentRecord) enrollmentRecord {
+			r.enrollment = cloneEnrollment(&r.enrollment)
+			return r
+		}),
+		skills: copyRecords(s.skills, func(r skillRecord) skillRecord { return r }),
+		skillTargets: copyRecords(s.skillTargets, func(r skillTargetRecord) skillTargetRecord {
+			r.target = cloneSkillTarget(&r.target)
+			return r
+		}),
+		userSkills: copyRecords(s.userSkills, func(r userSkillRecord) userSkillRecord {
+			r.userSkill = cloneUserSkill(&r.userSkill)
+			return r
+		}),
+		certificates: copyRecords(s.certificates, func(r certificateRecord) certificateRecord {
+			r.certificate = cloneCertificate(&r.certificate)
+			return r
+		}),
+		questionnaires: copyRecords(s.questionnaires, func(r questionnaireRecord) questionnaireRecord {
+			r.questionnaire = cloneQuestionnaire(&r.questionnaire)
+			return r
+		}),
+		feedback: copyRecords(s.feedback, func(r feedbackRecord) feedbackRecord {
+			r.response = cloneFeedbackResponse(&r.response)
+			return r
+		}),
+		checkIns: copyRecords(s.checkIns, func(r checkInRecord) checkInRecord {
+			r.checkIn = cloneCheckIn(&r.checkIn)
+			return r
+		}),
+		stallAlerts: copyRecords(s.stallAlerts, func(r stallAlertRecord) stallAlertRecord { return r }),
+		jobs: copyRecords(s.jobs, func(r jobRecord) jobRecord {
+			r.job = cloneJob(&r.job)
+			return r
+		}),
+		jobSchedules: copyRecords(s.jobSchedules, func(r jobScheduleRecord) jobScheduleRecord {
+			r.lastRunAt = cloneTime(r.lastRunAt)
+			return r
+		}),
+		outbox: copyRecords(s.outbox, func(r outboxRecord) outboxRecord {
+			r.event = cloneOutboxEvent(&r.event)
+			return r
+		}),
+		webhooks: copyRecords(s.webhooks, func(r webhookRecord) webhookRecord {
+			r.sub = cloneSubscription(&r.sub)
+			return r
+		}),
+		deliveries: copyRecords(s.deliveries, func(r deliveryRecord) deliveryRecord {
+			r.delivery = cloneDelivery(&r.delivery)
+			return r
+		}),
+	}
+}
+
+func (s *Store) restore(data storeData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tenants = data.tenants
+	s.users = data.users
+	s.roles = data.roles
+	s.departments = data.departments
+	s.requests = data.requests
+	s.mentors = data.mentors
+	s.learnings = data.learnings
+	s.notifications = data.notifications
+	s.windows = data.windows
+	s.absences = data.absences
+	s.approvals = data.approvals
+	s.comments = data.comments
+	s.attachments = data.attachments
+	s.courses = data.courses
+	s.enrollments = data.enrollments
+	s.skills = data.skills
+	s.skillTargets = data.skillTargets
+	s.userSkills = data.userSkills
+	s.certificates = data.certificates
+	s.questionnaires = data.questionnaires
+	s.feedback = data.feedback
+	s.checkIns = data.checkIns
+	s.stallAlerts = data.stallAlerts
+	s.jobs = data.jobs
+	s.jobSchedules = data.jobSchedules
+	s.outbox = data.outbox
+	s.webhooks = data.webhooks
+	s.deliveries = data.deliveries
+}
+
+// copyRecords copies a table, cloning each record
+func copyRecords[R any](records map[string]*R, clone func(R) R) map[string]*R {
+	c := make(map[string]*R, len(records))
+	for id, rec := range records {
+		v := clone(*rec)
+		c[id] = &v
+	}
+	return c
+}
@@ -0,0 +1,346 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type userRecord struct {
+	user   domain.User
+	tenant string
+	seq    int64
+}
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// Create inserts a new user
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	if r.store.emailTaken(tenant, user.Email, "") || r.store.externalIDTaken(tenant, user.ExternalID, "") {
+		return fmt.Errorf("failed to create user: %w", ErrDuplicateKey)
+	}
+	if user.ManagerID != nil {
+		if _, ok := r.store.users[*user.ManagerID]; !ok {
+			return fmt.Errorf("failed to create user: %w", ErrForeignKeyViolation)
+		}
+	}
+	if _, ok := r.store.roles[roleKey(tenant, user.Role)]; !ok {
+		return fmt.Errorf("failed to create user: %w", ErrForeignKeyViolation)
+	}
+	if !r.store.departmentExists(user.DepartmentID) {
+		return fmt.Errorf("failed to create user: %w", ErrForeignKeyViolation)
+	}
+
+	user.ID = newID()
+	user.Permissions, user.PermissionScope = r.store.permissionsOf(tenant, user.Role)
+	user.Department = r.store.departmentName(user.DepartmentID)
+	user.CreatedAt = now()
+	user.UpdatedAt = user.CreatedAt
+
+	r.store.users[user.ID] = &userRecord{user: cloneUser(user), tenant: tenant, seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.users[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrUserNotFound
+	}
+
+	user := r.store.readUser(rec)
+	return &user, nil
+}
+
+// GetByEmail retrieves a user by email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	for _, rec := range r.store.users {
+		if rec.tenant == tenant && rec.user.Email == email {
+			user := r.store.readUser(rec)
+			return &user, nil
+		}
+	}
+
+	return nil, domain.ErrUserNotFound
+}
+
+// GetAll retrieves all users, newest first
+func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	records := make([]*userRecord, 0, len(r.store.users))
+	for _, rec := range r.store.users {
+		if rec.tenant == tenant {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return newerFirst(records[i].user.CreatedAt, records[i].seq, records[j].user.CreatedAt, records[j].seq)
+	})
+
+	var users []*domain.User
+	for _, rec := range records {
+		user := r.store.readUser(rec)
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+// Update updates user information
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	rec, ok := r.store.users[user.ID]
+	if !ok || rec.tenant != tenant {
+		return domain.ErrUserNotFound
+	}
+	if r.store.emailTaken(tenant, user.Email, user.ID) || r.store.externalIDTaken(tenant, user.ExternalID, user.ID) {
+		return fmt.Errorf("failed to update user: %w", ErrDuplicateKey)
+	}
+	if _, ok := r.store.roles[roleKey(tenant, user.Role)]; !ok {
+		return fmt.Errorf("failed to update user: %w", ErrForeignKeyViolation)
+	}
+	if !r.store.departmentExists(user.DepartmentID) {
+		return fmt.Errorf("failed to update user: %w", ErrForeignKeyViolation)
+	}
+
+	rec.user.Name = user.Name
+	rec.user.Email = user.Email
+	rec.user.PasswordHash = user.PasswordHash
+	rec.user.Role = user.Role
+	rec.user.DepartmentID = cloneString(user.DepartmentID)
+	rec.user.JobTitle = cloneString(user.JobTitle)
+	rec.user.Telegram = cloneString(user.Telegram)
+	rec.user.ExternalID = cloneString(user.ExternalID)
+	rec.user.UpdatedAt = now()
+
+	user.Permissions, user.PermissionScope = r.store.permissionsOf(tenant, user.Role)
+	user.Department = r.store.departmentName(user.DepartmentID)
+	user.UpdatedAt = rec.user.UpdatedAt
+	return nil
+}
+
+// UpdateDeactivatedAt deactivates a user, or reactivates them when
+// deactivatedAt is nil
+func (r *UserRepository) UpdateDeactivatedAt(ctx context.Context, id string, deactivatedAt *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.users[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrUserNotFound
+	}
+
+	rec.user.DeactivatedAt = truncateTime(deactivatedAt)
+	rec.user.UpdatedAt = now()
+	return nil
+}
+
+// UpdateManager sets the line manager of a user, or clears it when
+// managerID is nil
+func (r *UserRepository) UpdateManager(ctx context.Context, id string, managerID *string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.users[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrUserNotFound
+	}
+	if managerID != nil {
+		if *managerID == id {
+			return fmt.Errorf("failed to update user manager: %w", ErrCheckViolation)
+		}
+		if _, ok := r.store.users[*managerID]; !ok {
+			return fmt.Errorf("failed to update user manager: %w", ErrForeignKeyViolation)
+		}
+	}
+
+	rec.user.ManagerID = cloneString(managerID)
+	rec.user.UpdatedAt = now()
+	return nil
+}
+
+// Delete removes a user together with their requests, learnings,
+// certificates and check-ins (ON DELETE CASCADE); their reports lose their
+// manager, their approval decisions their decider, their comments their
+// author, their attachments their uploader and the departments they head
+// their head (ON DELETE SET NULL)
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.users[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrUserNotFound
+	}
+
+	for lid, rec := range r.store.learnings {
+		if rec.learning.UserID == id {
+			delete(r.store.learnings, lid)
+		}
+	}
+	for cid, rec := range r.store.certificates {
+		if _, ok := r.store.learnings[rec.certificate.LearningID]; !ok {
+			delete(r.store.certificates, cid)
+		}
+	}
+	for fid, rec := range r.store.feedback {
+		if _, ok := r.store.learnings[rec.response.LearningID]; !ok {
+			delete(r.store.feedback, fid)
+		} else if rec.response.AuthorID != nil && *rec.response.AuthorID == id {
+			rec.response.AuthorID = nil
+		}
+	}
+	for cid, rec := range r.store.checkIns {
+		if _, ok := r.store.learnings[rec.checkIn.LearningID]; !ok || rec.checkIn.UserID == id {
+			delete(r.store.checkIns, cid)
+		}
+	}
+	for lid := range r.store.stallAlerts {
+		if _, ok := r.store.learnings[lid]; !ok {
+			delete(r.store.stallAlerts, lid)
+		}
+	}
+	for rid, rec := range r.store.requests {
+		if rec.request.UserID == id {
+			delete(r.store.requests, rid)
+		}
+	}
+	for eid, rec := range r.store.enrollments {
+		if rec.enrollment.UserID == id {
+			if c, ok := r.store.courses[rec.enrollment.CourseID]; ok && rec.enrollment.Status != domain.EnrollmentCancelled {
+				c.course.SeatsTaken--
+			}
+			delete(r.store.enrollments, eid)
+		}
+	}
+	for aid, rec := range r.store.approvals {
+		if _, ok := r.store.requests[rec.decision.RequestID]; !ok {
+			delete(r.store.approvals, aid)
+		} else if rec.decision.DeciderID != nil && *rec.decision.DeciderID == id {
+			rec.decision.DeciderID = nil
+		}
+	}
+	for cid, rec := range r.store.comments {
+		if !r.store.targetExists(rec.comment.TargetType, rec.comment.TargetID) {
+			delete(r.store.comments, cid)
+		} else if rec.comment.IsAuthor(id) {
+			rec.comment.AuthorID = nil
+		}
+	}
+	for aid, rec := range r.store.attachments {
+		if !r.store.attachmentOwnerExists(&rec.attachment) {
+			delete(r.store.attachments, aid)
+		} else if rec.attachment.IsUploader(id) {
+			rec.attachment.UploaderID = nil
+		}
+	}
+	for key, rec := range r.store.userSkills {
+		if rec.userSkill.UserID == id {
+			delete(r.store.userSkills, key)
+		} else if rec.userSkill.AssessedBy != nil && *rec.userSkill.AssessedBy == id {
+			rec.userSkill.AssessedBy = nil
+		}
+	}
+	for _, rec := range r.store.users {
+		if rec.user.ManagerID != nil && *rec.user.ManagerID == id {
+			rec.user.ManagerID = nil
+		}
+	}
+	for _, rec := range r.store.departments {
+		if rec.department.HeadID != nil && *rec.department.HeadID == id {
+			rec.department.HeadID = nil
+		}
+	}
+	for nid, rec := range r.store.notifications {
+		if rec.notification.UserID == id {
+			delete(r.store.notifications, nid)
+		}
+	}
+	delete(r.store.users, id)
+
+	return nil
+}
+
+// emailTaken checks the unique email index of the tenant; caller holds the
+// lock
+func (s *Store) emailTaken(tenant, email, exceptID string) bool {
+	for id, rec := range s.users {
+		if id != exceptID && rec.tenant == tenant && rec.user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// externalIDTaken checks the unique index on set external IDs of the tenant;
+// caller holds the lock
+func (s *Store) externalIDTaken(tenant string, externalID *string, exceptID string) bool {
+	if externalID == nil {
+		return false
+	}
+	for id, rec := range s.users {
+		if id != exceptID && rec.tenant == tenant && rec.user.ExternalID != nil && *rec.user.ExternalID == *externalID {
+			return true
+		}
+	}
+	return false
+}
+
+// departmentExists checks the department foreign key of a user; caller
+// holds the lock
+func (s *Store) departmentExists(id *string) bool {
+	if id == nil {
+		return true
+	}
+	_, ok := s.departments[*id]
+	return ok
+}
+
+// readUser copies a stored user and resolves the permissions of their role
+// and the name of their department; caller holds the lock
+func (s *Store) readUser(rec *userRecord) domain.User {
+	user := cloneUser(&rec.user)
+	user.Permissions, user.PermissionScope = s.permissionsOf(rec.tenant, user.Role)
+	user.Department = s.departmentName(user.DepartmentID)
+	return user
+}
+
+// cloneUser copies a user so callers cannot mutate stored state
+func cloneUser(u *domain.User) domain.User {
+	c := *u
+	c.DepartmentID = cloneString(u.DepartmentID)
+	c.Department = nil // resolved from the department on read
+	c.JobTitle = cloneString(u.JobTitle)
+	c.Telegram = cloneString(u.Telegram)
+	c.ManagerID = cloneString(u.ManagerID)
+	c.ExternalID = cloneString(u.ExternalID)
+	c.DeactivatedAt = cloneTime(u.DeactivatedAt)
+	c.Permissions = nil // resolved from the role on read
+	c.PermissionScope = ""
+	return c
+}
@@ -0,0 +1,338 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type webhookRecord struct {
+	sub    domain.WebhookSubscription
+	tenant string
+	seq    int64
+}
+
+type deliveryRecord struct {
+	delivery domain.WebhookDelivery
+	tenant   string
+	seq      int64
+}
+
+// WebhookRepository keeps subscriptions and deliveries in the store;
+// event fields of a delivery are resolved from the outbox like the SQL JOIN
+type WebhookRepository struct {
+	store *Store
+}
+
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+// CreateSubscription inserts a new subscription
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sub.ID = newID()
+	sub.EventTypes = slices.Clone(sub.EventTypes)
+	if sub.EventTypes == nil {
+		sub.EventTypes = []domain.EventType{}
+	}
+	sub.CreatedAt = now()
+	sub.UpdatedAt = sub.CreatedAt
+
+	r.store.webhooks[sub.ID] = &webhookRecord{sub: cloneSubscription(sub), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetSubscription retrieves a subscription by its ID
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.webhooks[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	sub := cloneSubscription(&rec.sub)
+	return &sub, nil
+}
+
+// ListSubscriptions retrieves all subscriptions, newest first
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*webhookRecord, 0, len(r.store.webhooks))
+	for _, rec := range r.store.webhooks {
+		if rec.tenant == tenant {
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return newerFirst(recs[i].sub.CreatedAt, recs[i].seq, recs[j].sub.CreatedAt, recs[j].seq)
+	})
+
+	subs := make([]*domain.WebhookSubscription, 0, len(recs))
+	for _, rec := range recs {
+		sub := cloneSubscription(&rec.sub)
+		subs = append(subs, &sub)
+	}
+	return subs, nil
+}
+
+// UpdateSubscription replaces the fields of a subscription
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.webhooks[sub.ID]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrWebhookNotFound
+	}
+
+	s := &rec.sub
+	s.URL = sub.URL
+	s.Secret = sub.Secret
+	s.EventTypes = slices.Clone(sub.EventTypes)
+	if s.EventTypes == nil {
+		s.EventTypes = []domain.EventType{}
+	}
+	s.Description = sub.Description
+	s.Active = sub.Active
+	s.UpdatedAt = now()
+
+	sub.EventTypes = slices.Clone(s.EventTypes)
+	sub.CreatedAt = s.CreatedAt
+	sub.UpdatedAt = s.UpdatedAt
+	return nil
+}
+
+// DeleteSubscription removes a subscription with its deliveries
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.webhooks[id]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrWebhookNotFound
+	}
+	delete(r.store.webhooks, id)
+	for deliveryID, rec := range r.store.deliveries {
+		if rec.delivery.SubscriptionID == id {
+			delete(r.store.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// CreateDelivery inserts a pending delivery of an event to a subscription
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, ok := r.store.webhooks[delivery.SubscriptionID]; !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrWebhookNotFound
+	}
+	if _, ok := r.store.outbox[delivery.EventID]; !ok {
+		return fmt.Errorf("failed to create webhook delivery: %w", ErrForeignKeyViolation)
+	}
+	for _, rec := range r.store.deliveries {
+		if rec.delivery.SubscriptionID == delivery.SubscriptionID && rec.delivery.EventID == delivery.EventID {
+			return fmt.Errorf("failed to create webhook delivery: %w", ErrDuplicateKey)
+		}
+	}
+
+	delivery.ID = newID()
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = 0
+	delivery.LastStatusCode = nil
+	delivery.LastError = ""
+	delivery.CreatedAt = now()
+	delivery.DeliveredAt = nil
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = delivery.CreatedAt
+	}
+	delivery.NextAttemptAt = delivery.NextAttemptAt.Truncate(time.Microsecond)
+
+	r.store.deliveries[delivery.ID] = &deliveryRecord{delivery: cloneDelivery(delivery), tenant: domain.TenantID(ctx), seq: r.store.nextSeq()}
+	return nil
+}
+
+// GetDelivery retrieves a delivery by its ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.deliveries[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	return r.store.joinDelivery(rec), nil
+}
+
+// ListDeliveries retrieves deliveries matching the filter, newest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*deliveryRecord, 0)
+	for _, rec := range r.store.deliveries {
+		if rec.tenant != tenant {
+			continue
+		}
+		if filter.Status != "" && rec.delivery.Status != filter.Status {
+			continue
+		}
+		if filter.SubscriptionID != "" && rec.delivery.SubscriptionID != filter.SubscriptionID {
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return newerFirst(recs[i].delivery.CreatedAt, recs[i].seq, recs[j].delivery.CreatedAt, recs[j].seq)
+	})
+	if filter.Limit > 0 && len(recs) > filter.Limit {
+		recs = recs[:filter.Limit]
+	}
+
+	deliveries := make([]*domain.WebhookDelivery, 0, len(recs))
+	for _, rec := range recs {
+		deliveries = append(deliveries, r.store.joinDelivery(rec))
+	}
+	return deliveries, nil
+}
+
+// ClaimDeliveries takes up to limit due deliveries of active subscriptions
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, at time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tenant := domain.TenantID(ctx)
+	recs := make([]*deliveryRecord, 0)
+	for _, rec := range r.store.deliveries {
+		d := &rec.delivery
+		sub, ok := r.store.webhooks[d.SubscriptionID]
+		if !ok || rec.tenant != tenant || !sub.sub.Active || d.Status != domain.DeliveryPending || d.NextAttemptAt.After(at) {
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		a, b := &recs[i].delivery, &recs[j].delivery
+		if !a.NextAttemptAt.Equal(b.NextAttemptAt) {
+			return a.NextAttemptAt.Before(b.NextAttemptAt)
+		}
+		return recs[i].seq < recs[j].seq
+	})
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+
+	nextAttemptAt := at.Add(lease).Truncate(time.Microsecond)
+	deliveries := make([]*domain.WebhookDelivery, 0, len(recs))
+	for _, rec := range recs {
+		rec.delivery.Attempts++
+		rec.delivery.NextAttemptAt = nextAttemptAt
+		deliveries = append(deliveries, r.store.joinDelivery(rec))
+	}
+	return deliveries, nil
+}
+
+// MarkDelivered records the successful attempt
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id string, statusCode int, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.deliveries[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.delivery.Status != domain.DeliveryPending {
+		return domain.ErrDeliveryNotFound
+	}
+
+	deliveredAt := at.Truncate(time.Microsecond)
+	rec.delivery.Status = domain.DeliveryDelivered
+	rec.delivery.LastStatusCode = &statusCode
+	rec.delivery.LastError = ""
+	rec.delivery.DeliveredAt = &deliveredAt
+	return nil
+}
+
+// MarkFailed records a failed attempt and schedules the retry, if any
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id string, statusCode *int, lastError string, retryAt *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.deliveries[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) || rec.delivery.Status != domain.DeliveryPending {
+		return domain.ErrDeliveryNotFound
+	}
+
+	d := &rec.delivery
+	if retryAt != nil {
+		d.NextAttemptAt = retryAt.Truncate(time.Microsecond)
+	} else {
+		d.Status = domain.DeliveryDead
+	}
+	d.LastStatusCode = nil
+	if statusCode != nil {
+		code := *statusCode
+		d.LastStatusCode = &code
+	}
+	d.LastError = lastError
+	return nil
+}
+
+// Redeliver makes a dead delivery pending again
+func (r *WebhookRepository) Redeliver(ctx context.Context, id string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.deliveries[id]
+	if !ok || rec.tenant != domain.TenantID(ctx) {
+		return domain.ErrDeliveryNotFound
+	}
+	if rec.delivery.Status != domain.DeliveryDead {
+		return domain.ErrDeliveryNotRetryable
+	}
+
+	rec.delivery.Status = domain.DeliveryPending
+	rec.delivery.Attempts = 0
+	rec.delivery.NextAttemptAt = at.Truncate(time.Microsecond)
+	return nil
+}
+
+// joinDelivery copies a delivery with the fields of its event; caller holds
+// the lock
+func (s *Store) joinDelivery(rec *deliveryRecord) *domain.WebhookDelivery {
+	d := cloneDelivery(&rec.delivery)
+	if event, ok := s.outbox[d.EventID]; ok {
+		d.EventType = event.event.Type
+		d.Payload = slices.Clone(event.event.Payload)
+		d.EventCreatedAt = event.event.CreatedAt
+	}
+	return &d
+}
+
+// cloneSubscription copies a subscription
+func cloneSubscription(s *domain.WebhookSubscription) domain.WebhookSubscription {
+	v := *s
+	v.EventTypes = slices.Clone(s.EventTypes)
+	return v
+}
+
+// cloneDelivery copies a delivery
+func cloneDelivery(d *domain.WebhookDelivery) domain.WebhookDelivery {
+	v := *d
+	v.Payload = slices.Clone(d.Payload)
+	if d.LastStatusCode != nil {
+		code := *d.LastStatusCode
+		v.LastStatusCode = &code
+	}
+	v.DeliveredAt = cloneTime(d.DeliveredAt)
+	return v
+}
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivatedAt TIMESTAMP WITH TIME ZONE;
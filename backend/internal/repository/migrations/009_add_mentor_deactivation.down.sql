@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_mentors_active;
+
+ALTER TABLE mentors DROP COLUMN IF EXISTS deactivatedAt;
@@ -0,0 +1,3 @@
+ALTER TABLE mentors ADD COLUMN IF NOT EXISTS deactivatedAt TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_mentors_active ON mentors(workload) WHERE deactivatedAt IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS mentor_absences CASCADE;
+DROP TABLE IF EXISTS mentor_availability_windows CASCADE;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS mentor_availability_windows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    mentorId UUID NOT NULL REFERENCES mentors(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday >= 0 AND weekday <= 6),
+    startTime VARCHAR(5) NOT NULL,
+    endTime VARCHAR(5) NOT NULL,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (startTime < endTime)
+);
+
+CREATE INDEX idx_mentor_availability_windows_mentorId ON mentor_availability_windows(mentorId, weekday, startTime);
+
+CREATE TABLE IF NOT EXISTS mentor_absences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    mentorId UUID NOT NULL REFERENCES mentors(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL CHECK (kind IN ('vacation', 'sick_leave', 'other')),
+    startsAt TIMESTAMP WITH TIME ZONE NOT NULL,
+    endsAt TIMESTAMP WITH TIME ZONE NOT NULL,
+    note TEXT,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (startsAt < endsAt)
+);
+
+CREATE INDEX idx_mentor_absences_mentorId ON mentor_absences(mentorId, startsAt);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_training_requests_queue;
+
+UPDATE training_requests SET status = 'pending' WHERE status = 'queued';
+
+ALTER TABLE training_requests DROP COLUMN IF EXISTS queuedAt;
+ALTER TABLE training_requests DROP COLUMN IF EXISTS priority;
+
+ALTER TABLE training_requests DROP CONSTRAINT IF EXISTS training_requests_status_check;
+ALTER TABLE training_requests ADD CONSTRAINT training_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected'));
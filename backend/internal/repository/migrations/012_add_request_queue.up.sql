@@ -0,0 +1,8 @@
+ALTER TABLE training_requests DROP CONSTRAINT IF EXISTS training_requests_status_check;
+ALTER TABLE training_requests ADD CONSTRAINT training_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'queued'));
+
+ALTER TABLE training_requests ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE training_requests ADD COLUMN IF NOT EXISTS queuedAt TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_training_requests_queue ON training_requests(priority DESC, queuedAt) WHERE status = 'queued';
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS request_approvals;
+
+UPDATE training_requests SET status = 'pending' WHERE status = 'awaiting_manager';
+
+ALTER TABLE training_requests DROP COLUMN IF EXISTS approvalChain;
+
+ALTER TABLE training_requests DROP CONSTRAINT IF EXISTS training_requests_status_check;
+ALTER TABLE training_requests ADD CONSTRAINT training_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'queued'));
+
+DROP INDEX IF EXISTS idx_users_managerId;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_manager_check;
+ALTER TABLE users DROP COLUMN IF EXISTS managerId;
@@ -0,0 +1,22 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS managerId UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE users ADD CONSTRAINT users_manager_check CHECK (managerId <> id);
+
+CREATE INDEX idx_users_managerId ON users(managerId);
+
+ALTER TABLE training_requests DROP CONSTRAINT IF EXISTS training_requests_status_check;
+ALTER TABLE training_requests ADD CONSTRAINT training_requests_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'queued', 'awaiting_manager'));
+
+ALTER TABLE training_requests ADD COLUMN IF NOT EXISTS approvalChain TEXT[] NOT NULL DEFAULT '{admin}';
+
+CREATE TABLE IF NOT EXISTS request_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requestId UUID NOT NULL REFERENCES training_requests(id) ON DELETE CASCADE,
+    stage VARCHAR(50) NOT NULL CHECK (stage IN ('manager', 'admin')),
+    deciderId UUID REFERENCES users(id) ON DELETE SET NULL,
+    decision VARCHAR(50) NOT NULL CHECK (decision IN ('approved', 'rejected')),
+    comment TEXT,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_request_approvals_requestId ON request_approvals(requestId, createdAt);
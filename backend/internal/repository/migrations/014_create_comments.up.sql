@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requestId UUID REFERENCES training_requests(id) ON DELETE CASCADE,
+    learningId UUID REFERENCES learning_processes(id) ON DELETE CASCADE,
+    parentId UUID REFERENCES comments(id) ON DELETE CASCADE,
+    authorId UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'internal')),
+    editedAt TIMESTAMP WITH TIME ZONE,
+    deletedAt TIMESTAMP WITH TIME ZONE,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    -- A comment belongs to exactly one thread
+    CONSTRAINT comments_target_check CHECK ((requestId IS NULL) <> (learningId IS NULL))
+);
+
+CREATE INDEX idx_comments_requestId ON comments(requestId, createdAt) WHERE requestId IS NOT NULL;
+CREATE INDEX idx_comments_learningId ON comments(learningId, createdAt) WHERE learningId IS NOT NULL;
+CREATE INDEX idx_comments_parentId ON comments(parentId);
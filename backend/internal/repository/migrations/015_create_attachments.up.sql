@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    learningId UUID REFERENCES learning_processes(id) ON DELETE CASCADE,
+    -- Plan items live in learning_processes.plan, so this is not a foreign key
+    planItemId VARCHAR(255),
+    commentId UUID REFERENCES comments(id) ON DELETE CASCADE,
+    uploaderId UUID REFERENCES users(id) ON DELETE SET NULL,
+    fileName VARCHAR(255) NOT NULL,
+    contentType VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    checksum CHAR(64) NOT NULL,
+    storageKey VARCHAR(512) NOT NULL UNIQUE,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    -- An attachment belongs to a learning (or one of its plan items) or to a comment
+    CONSTRAINT attachments_owner_check CHECK ((learningId IS NULL) <> (commentId IS NULL)),
+    CONSTRAINT attachments_plan_item_check CHECK (planItemId IS NULL OR learningId IS NOT NULL)
+);
+
+CREATE INDEX idx_attachments_learningId ON attachments(learningId, createdAt) WHERE learningId IS NOT NULL;
+CREATE INDEX idx_attachments_commentId ON attachments(commentId, createdAt) WHERE commentId IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS course_enrollments;
+DROP FUNCTION IF EXISTS release_course_seat();
+
+DROP INDEX IF EXISTS idx_training_requests_courseId;
+ALTER TABLE training_requests DROP COLUMN IF EXISTS courseId;
+
+DROP TABLE IF EXISTS courses;
@@ -0,0 +1,66 @@
+CREATE TABLE IF NOT EXISTS courses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(500) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    format VARCHAR(20) NOT NULL CHECK (format IN ('online', 'classroom', 'blended', 'self_paced')),
+    durationHours INT NOT NULL CHECK (durationHours > 0),
+    provider VARCHAR(255) NOT NULL DEFAULT '',
+    skills TEXT[] NOT NULL DEFAULT '{}',
+    -- NULL means unlimited seats
+    capacity INT CHECK (capacity > 0),
+    -- Maintained by enrollments so that taking a seat is a single conditional UPDATE
+    seatsTaken INT NOT NULL DEFAULT 0 CHECK (seatsTaken >= 0),
+    enrollmentOpensAt TIMESTAMP WITH TIME ZONE,
+    enrollmentClosesAt TIMESTAMP WITH TIME ZONE,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT courses_enrollment_window_check CHECK (enrollmentClosesAt > enrollmentOpensAt)
+);
+
+CREATE INDEX idx_courses_title ON courses(title);
+CREATE INDEX idx_courses_skills ON courses USING GIN (skills);
+
+CREATE TRIGGER update_courses_updated_at
+    BEFORE UPDATE ON courses
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Enrollment requests point at their course; a course stays while requests reference it
+ALTER TABLE training_requests ADD COLUMN IF NOT EXISTS courseId UUID REFERENCES courses(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_training_requests_courseId ON training_requests(courseId) WHERE courseId IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS course_enrollments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    courseId UUID NOT NULL REFERENCES courses(id),
+    userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requestId UUID NOT NULL UNIQUE REFERENCES training_requests(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'enrolled' CHECK (status IN ('enrolled', 'completed', 'cancelled')),
+    feedback JSONB,
+    enrolledAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completedAt TIMESTAMP WITH TIME ZONE,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_course_enrollments_courseId ON course_enrollments(courseId, enrolledAt);
+CREATE INDEX idx_course_enrollments_userId ON course_enrollments(userId, enrolledAt);
+CREATE UNIQUE INDEX idx_course_enrollments_active ON course_enrollments(courseId, userId) WHERE status = 'enrolled';
+
+CREATE TRIGGER update_course_enrollments_updated_at
+    BEFORE UPDATE ON course_enrollments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Enrollments removed with their user give their seat back
+CREATE OR REPLACE FUNCTION release_course_seat()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.status <> 'cancelled' THEN
+        UPDATE courses SET seatsTaken = seatsTaken - 1 WHERE id = OLD.courseId;
+    END IF;
+    RETURN OLD;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER release_course_enrollments_seat
+    AFTER DELETE ON course_enrollments
+    FOR EACH ROW EXECUTE FUNCTION release_course_seat();
@@ -0,0 +1,5 @@
+ALTER TABLE training_requests DROP COLUMN IF EXISTS skills;
+
+DROP TABLE IF EXISTS user_skills;
+DROP TABLE IF EXISTS skill_targets;
+DROP TABLE IF EXISTS skills;
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS skills (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Skill tags match skills ignoring case, so names must be unique that way too
+CREATE UNIQUE INDEX idx_skills_name ON skills(lower(name));
+
+CREATE TRIGGER update_skills_updated_at
+    BEFORE UPDATE ON skills
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS skill_targets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    skillId UUID NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    department VARCHAR(255),
+    jobTitle VARCHAR(255),
+    level INT NOT NULL CHECK (level BETWEEN 1 AND 5),
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT skill_targets_scope_check CHECK (department IS NOT NULL OR jobTitle IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_skill_targets_scope ON skill_targets(skillId, lower(COALESCE(department, '')), lower(COALESCE(jobTitle, '')));
+
+CREATE TABLE IF NOT EXISTS user_skills (
+    userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    skillId UUID NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    selfLevel INT CHECK (selfLevel BETWEEN 1 AND 5),
+    managerLevel INT CHECK (managerLevel BETWEEN 1 AND 5),
+    assessedBy UUID REFERENCES users(id) ON DELETE SET NULL,
+    level INT NOT NULL CHECK (level BETWEEN 1 AND 5),
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (userId, skillId)
+);
+
+CREATE INDEX idx_user_skills_skillId ON user_skills(skillId);
+
+CREATE TRIGGER update_user_skills_updated_at
+    BEFORE UPDATE ON user_skills
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Skill tags of a request; a positively rated learning raises the learner's level in them
+ALTER TABLE training_requests ADD COLUMN IF NOT EXISTS skills TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS certificates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL UNIQUE,
+    learningId UUID NOT NULL UNIQUE REFERENCES learning_processes(id) ON DELETE CASCADE,
+    -- What the PDF says, kept as issued
+    learnerName VARCHAR(255) NOT NULL,
+    topic VARCHAR(500) NOT NULL,
+    mentorName VARCHAR(255) NOT NULL,
+    startDate TIMESTAMP WITH TIME ZONE NOT NULL,
+    endDate TIMESTAMP WITH TIME ZONE NOT NULL,
+    completedItems TEXT[] NOT NULL DEFAULT '{}',
+    storageKey VARCHAR(512) NOT NULL UNIQUE,
+    issuedAt TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS feedback_responses;
+DROP TABLE IF EXISTS feedback_questionnaires;
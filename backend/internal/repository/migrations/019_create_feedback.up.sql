@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS feedback_questionnaires (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    audience VARCHAR(20) NOT NULL CHECK (audience IN ('learner', 'mentor')),
+    -- [{"key", "label", "min", "max", "required"}]
+    criteria JSONB NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- New feedback answers the one active questionnaire of its audience
+CREATE UNIQUE INDEX idx_feedback_questionnaires_active ON feedback_questionnaires(audience) WHERE active;
+
+CREATE TRIGGER update_feedback_questionnaires_updated_at
+    BEFORE UPDATE ON feedback_questionnaires
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS feedback_responses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    learningId UUID NOT NULL REFERENCES learning_processes(id) ON DELETE CASCADE,
+    questionnaireId UUID NOT NULL REFERENCES feedback_questionnaires(id) ON DELETE RESTRICT,
+    audience VARCHAR(20) NOT NULL CHECK (audience IN ('learner', 'mentor')),
+    authorId UUID REFERENCES users(id) ON DELETE SET NULL,
+    -- The mentor at the time of the feedback
+    mentorId UUID NOT NULL REFERENCES mentors(id) ON DELETE RESTRICT,
+    answers JSONB NOT NULL DEFAULT '{}',
+    comment TEXT NOT NULL DEFAULT '',
+    anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (learningId, audience)
+);
+
+CREATE INDEX idx_feedback_responses_mentorId ON feedback_responses(mentorId, createdAt DESC);
+CREATE INDEX idx_feedback_responses_questionnaireId ON feedback_responses(questionnaireId);
+
+-- Default questionnaires
+INSERT INTO feedback_questionnaires (name, audience, active, criteria) VALUES
+('Feedback on the mentor', 'learner', TRUE, '[
+    {"key": "clarity", "label": "How clearly did the mentor explain things?", "min": 1, "max": 5, "required": true},
+    {"key": "availability", "label": "How available was the mentor?", "min": 1, "max": 5, "required": true},
+    {"key": "usefulness", "label": "How useful was the learning for your work?", "min": 1, "max": 5, "required": true},
+    {"key": "recommend", "label": "How likely are you to recommend this mentor to a colleague?", "min": 0, "max": 10, "required": true}
+]'),
+('Assessment of the learner', 'mentor', TRUE, '[
+    {"key": "engagement", "label": "How engaged was the learner?", "min": 1, "max": 5, "required": true},
+    {"key": "skill_growth", "label": "How much did the learner''s skills grow?", "min": 1, "max": 5, "required": true}
+]');
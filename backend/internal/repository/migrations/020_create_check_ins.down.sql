@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS learning_stall_alerts;
+DROP TABLE IF EXISTS learning_check_ins;
+
+DROP TRIGGER IF EXISTS update_learning_processes_plan_updated_at ON learning_processes;
+DROP FUNCTION IF EXISTS update_plan_updated_at_column();
+
+ALTER TABLE learning_processes DROP COLUMN IF EXISTS planUpdatedAt;
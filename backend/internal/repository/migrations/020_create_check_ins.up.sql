@@ -0,0 +1,41 @@
+-- When the plan last changed. Learnings started before this migration keep
+-- NULL until their plan changes and fall back to updatedAt.
+ALTER TABLE learning_processes ADD COLUMN IF NOT EXISTS planUpdatedAt TIMESTAMP WITH TIME ZONE;
+ALTER TABLE learning_processes ALTER COLUMN planUpdatedAt SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE OR REPLACE FUNCTION update_plan_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.plan IS DISTINCT FROM OLD.plan THEN
+        NEW.planUpdatedAt = CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER update_learning_processes_plan_updated_at
+    BEFORE UPDATE ON learning_processes
+    FOR EACH ROW EXECUTE FUNCTION update_plan_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS learning_check_ins (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    learningId UUID NOT NULL REFERENCES learning_processes(id) ON DELETE CASCADE,
+    userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('learner', 'mentor')),
+    round INTEGER NOT NULL CHECK (round > 0),
+    pulse VARCHAR(20) CHECK (pulse IN ('on_track', 'behind', 'blocked')),
+    comment TEXT NOT NULL DEFAULT '',
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    answeredAt TIMESTAMP WITH TIME ZONE,
+
+    -- Several app instances may run the scheduler; each check-in goes out once
+    UNIQUE (learningId, role, round)
+);
+
+CREATE INDEX idx_learning_check_ins_pending ON learning_check_ins(userId) WHERE answeredAt IS NULL;
+
+-- Last time the mentor was told a learning stalled
+CREATE TABLE IF NOT EXISTS learning_stall_alerts (
+    learningId UUID PRIMARY KEY REFERENCES learning_processes(id) ON DELETE CASCADE,
+    notifiedAt TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB,
+    uniqueKey VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'cancelled')),
+    attempts INTEGER NOT NULL DEFAULT 0 CHECK (attempts >= 0),
+    maxAttempts INTEGER NOT NULL CHECK (maxAttempts > 0),
+    runAt TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lockedBy VARCHAR(255),
+    lockedUntil TIMESTAMP WITH TIME ZONE,
+    lastError TEXT NOT NULL DEFAULT '',
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    startedAt TIMESTAMP WITH TIME ZONE,
+    finishedAt TIMESTAMP WITH TIME ZONE
+);
+
+-- At most one live job per unique key
+CREATE UNIQUE INDEX idx_jobs_unique_key ON jobs(uniqueKey)
+    WHERE uniqueKey IS NOT NULL AND status IN ('pending', 'running');
+
+-- Workers look for due pending jobs and for running jobs whose lease expired
+CREATE INDEX idx_jobs_due ON jobs(runAt) WHERE status = 'pending';
+CREATE INDEX idx_jobs_leased ON jobs(lockedUntil) WHERE status = 'running';
+CREATE INDEX idx_jobs_created_at ON jobs(createdAt DESC);
+
+-- Next fire time of each recurring schedule, shared by all app instances
+CREATE TABLE IF NOT EXISTS job_schedules (
+    name VARCHAR(100) PRIMARY KEY,
+    nextRunAt TIMESTAMP WITH TIME ZONE NOT NULL,
+    lastRunAt TIMESTAMP WITH TIME ZONE
+);
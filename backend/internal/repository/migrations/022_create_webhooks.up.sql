@@ -0,0 +1,45 @@
+-- Training events written in the same transaction as the change they describe
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    dispatchedAt TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_events_undispatched ON outbox_events(createdAt) WHERE dispatchedAt IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    eventTypes TEXT[] NOT NULL DEFAULT '{}', -- empty means every type
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_webhook_subscriptions_updated_at
+    BEFORE UPDATE ON webhook_subscriptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- One event on its way to one subscription
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscriptionId UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    eventId UUID NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0 CHECK (attempts >= 0),
+    nextAttemptAt TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lastStatusCode INTEGER,
+    lastError TEXT NOT NULL DEFAULT '',
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deliveredAt TIMESTAMP WITH TIME ZONE,
+    UNIQUE (subscriptionId, eventId)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(nextAttemptAt) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_event ON webhook_deliveries(eventId);
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries(createdAt DESC);
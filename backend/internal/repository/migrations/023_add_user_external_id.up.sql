@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS externalId VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users(externalId) WHERE externalId IS NOT NULL;
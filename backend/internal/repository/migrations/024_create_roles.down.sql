@@ -0,0 +1,8 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+
+-- Custom and new built-in roles fall back to the fixed list
+UPDATE users SET role = 'employee' WHERE role NOT IN ('employee', 'admin', 'user');
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(20);
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('employee', 'admin', 'user'));
+
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,48 @@
+-- Named sets of permissions assigned to users. The built-in roles are seeded
+-- here and mirrored by domain.DefaultRoles.
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    builtIn BOOLEAN NOT NULL DEFAULT FALSE,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_roles_updated_at
+    BEFORE UPDATE ON roles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO roles (name, description, permissions, builtIn) VALUES
+    ('admin', 'Full access, including roles and integrations', ARRAY[
+        'users.view', 'users.manage', 'roles.manage',
+        'requests.view', 'requests.approve', 'requests.assign',
+        'learnings.view', 'learnings.manage', 'learnings.edit_plan',
+        'mentors.manage', 'courses.manage', 'skills.manage',
+        'comments.internal', 'comments.moderate',
+        'feedback.view', 'feedback.manage',
+        'analytics.view', 'jobs.manage', 'webhooks.manage'
+    ], TRUE),
+    ('ld_manager', 'Runs the learning programme: approvals, mentors, catalog and reports', ARRAY[
+        'users.view',
+        'requests.view', 'requests.approve', 'requests.assign',
+        'learnings.view', 'learnings.manage', 'learnings.edit_plan',
+        'mentors.manage', 'courses.manage', 'skills.manage',
+        'comments.internal', 'comments.moderate',
+        'feedback.view', 'feedback.manage',
+        'analytics.view'
+    ], TRUE),
+    ('department_head', 'Follows the requests, learnings and reports of the organisation', ARRAY[
+        'users.view', 'requests.view', 'learnings.view', 'analytics.view'
+    ], TRUE),
+    ('mentor', 'Curates the plans of learnings', ARRAY['learnings.view', 'learnings.edit_plan'], TRUE),
+    ('employee', 'Requests and takes part in own learnings', '{}', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+-- The unused "user" role becomes employee, and roles are now checked against
+-- the roles table instead of a fixed list
+UPDATE users SET role = 'employee' WHERE role NOT IN (SELECT name FROM roles);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50);
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
@@ -0,0 +1,22 @@
+UPDATE roles
+SET permissions = array_remove(permissions, 'departments.manage');
+
+UPDATE roles
+SET description = 'Follows the requests, learnings and reports of the organisation',
+    permissions = ARRAY['users.view', 'requests.view', 'learnings.view', 'analytics.view']
+WHERE name = 'department_head';
+
+ALTER TABLE roles DROP COLUMN IF EXISTS scope;
+
+-- Departments go back to free text on the user
+ALTER TABLE users ADD COLUMN IF NOT EXISTS department VARCHAR(255);
+
+UPDATE users u
+SET department = d.name
+FROM departments d
+WHERE u.departmentId = d.id;
+
+DROP INDEX IF EXISTS idx_users_department;
+ALTER TABLE users DROP COLUMN IF EXISTS departmentId;
+
+DROP TABLE IF EXISTS departments;
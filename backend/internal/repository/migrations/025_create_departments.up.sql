@@ -0,0 +1,55 @@
+-- Departments replace the free-text users.department. They form a tree and
+-- may have a head, who administers the subtree when their role is
+-- department-scoped.
+CREATE TABLE IF NOT EXISTS departments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    parentId UUID REFERENCES departments(id) ON DELETE RESTRICT,
+    headId UUID REFERENCES users(id) ON DELETE SET NULL,
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT departments_parent_check CHECK (parentId <> id)
+);
+
+-- Users pick departments by name, ignoring case
+CREATE UNIQUE INDEX idx_departments_name ON departments(lower(name));
+CREATE INDEX idx_departments_parent ON departments(parentId);
+CREATE INDEX idx_departments_head ON departments(headId);
+
+CREATE TRIGGER update_departments_updated_at
+    BEFORE UPDATE ON departments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Existing department strings become top-level departments; spellings that
+-- differ only in case or surrounding spaces are merged
+INSERT INTO departments (name)
+SELECT DISTINCT ON (lower(btrim(department))) btrim(department)
+FROM users
+WHERE btrim(COALESCE(department, '')) <> ''
+ORDER BY lower(btrim(department)), btrim(department)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS departmentId UUID REFERENCES departments(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_users_department ON users(departmentId);
+
+UPDATE users u
+SET departmentId = d.id
+FROM departments d
+WHERE lower(btrim(u.department)) = lower(d.name);
+
+ALTER TABLE users DROP COLUMN IF EXISTS department;
+
+-- Roles apply organisation-wide or to the departments their holders head
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS scope VARCHAR(20) NOT NULL DEFAULT 'organization'
+    CHECK (scope IN ('organization', 'department'));
+
+UPDATE roles
+SET scope = 'department',
+    description = 'Administers the people, requests and reports of the departments they head',
+    permissions = ARRAY['users.view', 'users.manage', 'requests.view', 'requests.approve', 'learnings.view', 'analytics.view']
+WHERE name = 'department_head';
+
+UPDATE roles
+SET permissions = array_append(permissions, 'departments.manage')
+WHERE name = 'admin' AND NOT 'departments.manage' = ANY(permissions);
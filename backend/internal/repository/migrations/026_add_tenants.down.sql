@@ -0,0 +1,73 @@
+ALTER TABLE mentors DROP CONSTRAINT IF EXISTS mentors_workload_check;
+UPDATE mentors SET workload = 5 WHERE workload > 5;
+ALTER TABLE mentors ADD CONSTRAINT mentors_workload_check CHECK (workload >= 0 AND workload <= 5);
+ALTER TABLE mentors DROP COLUMN IF EXISTS capacity;
+
+UPDATE roles
+SET permissions = array_remove(permissions, 'settings.manage');
+
+-- Only the default tenant survives going back to a single organisation
+UPDATE departments SET parentId = NULL WHERE tenantId <> '00000000-0000-0000-0000-000000000001';
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    -- Referencing tables first
+    FOREACH t IN ARRAY ARRAY[
+        'webhook_deliveries', 'outbox_events', 'webhook_subscriptions', 'jobs',
+        'learning_stall_alerts', 'learning_check_ins', 'feedback_responses', 'feedback_questionnaires',
+        'certificates', 'user_skills', 'skill_targets', 'skills', 'course_enrollments', 'attachments',
+        'comments', 'request_approvals', 'learning_processes', 'training_requests', 'courses',
+        'mentor_absences', 'mentor_availability_windows', 'notifications', 'mentors', 'users',
+        'departments', 'roles'
+    ] LOOP
+        EXECUTE format('DELETE FROM %I WHERE tenantId <> %L', t, '00000000-0000-0000-0000-000000000001');
+    END LOOP;
+END $$;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE roles DROP CONSTRAINT IF EXISTS roles_pkey;
+ALTER TABLE roles ADD PRIMARY KEY (name);
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+DROP INDEX IF EXISTS idx_jobs_unique_key;
+CREATE UNIQUE INDEX idx_jobs_unique_key ON jobs(uniqueKey)
+    WHERE uniqueKey IS NOT NULL AND status IN ('pending', 'running');
+
+DROP INDEX IF EXISTS idx_feedback_questionnaires_active;
+CREATE UNIQUE INDEX idx_feedback_questionnaires_active ON feedback_questionnaires(audience) WHERE active;
+
+DROP INDEX IF EXISTS idx_skills_name;
+CREATE UNIQUE INDEX idx_skills_name ON skills(lower(name));
+
+DROP INDEX IF EXISTS idx_departments_name;
+CREATE UNIQUE INDEX idx_departments_name ON departments(lower(name));
+
+DROP INDEX IF EXISTS idx_mentors_tenant_email;
+ALTER TABLE mentors ADD CONSTRAINT mentors_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_users_external_id;
+CREATE UNIQUE INDEX idx_users_external_id ON users(externalId) WHERE externalId IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_tenant_email;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'roles', 'departments', 'mentors', 'training_requests', 'learning_processes',
+        'notifications', 'mentor_availability_windows', 'mentor_absences', 'request_approvals',
+        'comments', 'attachments', 'courses', 'course_enrollments', 'skills', 'skill_targets',
+        'user_skills', 'certificates', 'feedback_questionnaires', 'feedback_responses',
+        'learning_check_ins', 'learning_stall_alerts', 'jobs', 'outbox_events',
+        'webhook_subscriptions', 'webhook_deliveries'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS tenantId', t);
+    END LOOP;
+END $$;
+
+DROP TABLE IF EXISTS tenant_hosts;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,91 @@
+-- Organisations hosted on the deployment. Existing data belongs to the
+-- default tenant, mirrored by domain.DefaultTenantID.
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    -- {"mentorCapacity", "branding": {"displayName", "logoUrl", "primaryColor"}}
+    settings JSONB NOT NULL DEFAULT '{}',
+    createdAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_tenants_updated_at
+    BEFORE UPDATE ON tenants
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Host names that resolve to a tenant, lowercase and without port
+CREATE TABLE IF NOT EXISTS tenant_hosts (
+    host VARCHAR(253) PRIMARY KEY,
+    tenantId UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tenant_hosts_tenant ON tenant_hosts(tenantId);
+
+INSERT INTO tenants (id, slug, name, settings)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'Default', '{"mentorCapacity": 5}')
+ON CONFLICT (id) DO NOTHING;
+
+-- Every row belongs to a tenant. Existing rows go to the default tenant; new
+-- rows must name theirs, so the column has no default.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'roles', 'departments', 'mentors', 'training_requests', 'learning_processes',
+        'notifications', 'mentor_availability_windows', 'mentor_absences', 'request_approvals',
+        'comments', 'attachments', 'courses', 'course_enrollments', 'skills', 'skill_targets',
+        'user_skills', 'certificates', 'feedback_questionnaires', 'feedback_responses',
+        'learning_check_ins', 'learning_stall_alerts', 'jobs', 'outbox_events',
+        'webhook_subscriptions', 'webhook_deliveries'
+    ] LOOP
+        EXECUTE format(
+            'ALTER TABLE %I ADD COLUMN IF NOT EXISTS tenantId UUID NOT NULL DEFAULT %L REFERENCES tenants(id)',
+            t, '00000000-0000-0000-0000-000000000001'
+        );
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenantId DROP DEFAULT', t);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(tenantId)', 'idx_' || t || '_tenant', t);
+    END LOOP;
+END $$;
+
+-- Names, emails and keys are unique within a tenant
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX idx_users_tenant_email ON users(tenantId, email);
+
+DROP INDEX IF EXISTS idx_users_external_id;
+CREATE UNIQUE INDEX idx_users_external_id ON users(tenantId, externalId) WHERE externalId IS NOT NULL;
+
+ALTER TABLE mentors DROP CONSTRAINT IF EXISTS mentors_email_key;
+CREATE UNIQUE INDEX idx_mentors_tenant_email ON mentors(tenantId, email);
+
+DROP INDEX IF EXISTS idx_departments_name;
+CREATE UNIQUE INDEX idx_departments_name ON departments(tenantId, lower(name));
+
+DROP INDEX IF EXISTS idx_skills_name;
+CREATE UNIQUE INDEX idx_skills_name ON skills(tenantId, lower(name));
+
+DROP INDEX IF EXISTS idx_feedback_questionnaires_active;
+CREATE UNIQUE INDEX idx_feedback_questionnaires_active ON feedback_questionnaires(tenantId, audience) WHERE active;
+
+DROP INDEX IF EXISTS idx_jobs_unique_key;
+CREATE UNIQUE INDEX idx_jobs_unique_key ON jobs(tenantId, uniqueKey)
+    WHERE uniqueKey IS NOT NULL AND status IN ('pending', 'running');
+
+-- Each tenant has its own roles, so users reference them by tenant and name
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE roles DROP CONSTRAINT IF EXISTS roles_pkey;
+ALTER TABLE roles ADD PRIMARY KEY (tenantId, name);
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (tenantId, role)
+    REFERENCES roles(tenantId, name) ON UPDATE CASCADE;
+
+UPDATE roles
+SET permissions = array_append(permissions, 'settings.manage')
+WHERE name = 'admin' AND NOT 'settings.manage' = ANY(permissions);
+
+-- Mentors take up to their capacity of learnings; new mentors get the
+-- tenant's default
+ALTER TABLE mentors ADD COLUMN IF NOT EXISTS capacity INTEGER NOT NULL DEFAULT 5
+    CHECK (capacity BETWEEN 1 AND 20);
+ALTER TABLE mentors DROP CONSTRAINT IF EXISTS mentors_workload_check;
+ALTER TABLE mentors ADD CONSTRAINT mentors_workload_check CHECK (workload >= 0 AND workload <= capacity);
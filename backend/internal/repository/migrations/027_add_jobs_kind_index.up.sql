@@ -0,0 +1,2 @@
+-- Readiness looks up the newest job of a kind in every tenant
+CREATE INDEX IF NOT EXISTS idx_jobs_kind_tenant ON jobs(kind, tenantId, createdAt DESC);
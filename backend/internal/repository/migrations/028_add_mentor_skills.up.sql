@@ -0,0 +1,2 @@
+-- Subjects a mentor teaches; the queue offers requests to matching mentors first
+ALTER TABLE mentors ADD COLUMN IF NOT EXISTS skills TEXT[] NOT NULL DEFAULT '{}';
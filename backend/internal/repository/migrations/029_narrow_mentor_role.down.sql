@@ -0,0 +1,3 @@
+UPDATE roles
+SET permissions = ARRAY['learnings.view', 'learnings.edit_plan']::TEXT[], description = 'Curates the plans of learnings'
+WHERE name = 'mentor' AND builtIn AND permissions = '{}';
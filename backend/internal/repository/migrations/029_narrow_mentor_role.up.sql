@@ -0,0 +1,7 @@
+-- Mentors reach the learnings they mentor through their account's email;
+-- the built-in role no longer grants every learning of the organisation.
+-- Roles an admin has tuned are left alone.
+UPDATE roles
+SET permissions = '{}', description = 'Guides the learnings they mentor'
+WHERE name = 'mentor' AND builtIn
+    AND permissions = ARRAY['learnings.view', 'learnings.edit_plan']::TEXT[];
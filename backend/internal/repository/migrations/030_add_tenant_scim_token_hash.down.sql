@@ -0,0 +1,6 @@
+UPDATE tenants
+SET settings = jsonb_set(settings, '{scim}', jsonb_build_object('tokenHash', scimTokenHash))
+WHERE scimTokenHash IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_tenants_scim_token_hash;
+ALTER TABLE tenants DROP COLUMN IF EXISTS scimTokenHash;
@@ -0,0 +1,10 @@
+-- SCIM requests look their tenant up by the hash of their bearer token, so
+-- it moves out of the settings document into an indexed column
+ALTER TABLE tenants ADD COLUMN scimTokenHash TEXT;
+
+UPDATE tenants
+SET scimTokenHash = NULLIF(settings->'scim'->>'tokenHash', ''),
+    settings = settings - 'scim';
+
+CREATE UNIQUE INDEX idx_tenants_scim_token_hash ON tenants(scimTokenHash)
+    WHERE scimTokenHash IS NOT NULL;
@@ -0,0 +1,251 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// files holds the SQL migrations compiled into the binary
+//
+//go:embed *.sql
+var files embed.FS
+
+// Direction of a migration step
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Step is a single migration that would be applied or reverted
+type Step struct {
+	Version    uint      `json:"version"`
+	Identifier string    `json:"identifier"`
+	Direction  Direction `json:"direction"`
+}
+
+// FS returns the embedded migrations
+func FS() fs.FS {
+	return files
+}
+
+// Latest returns the highest migration version shipped with the binary
+func Latest() (uint, error) {
+	all, err := list()
+	if err != nil {
+		return 0, err
+	}
+	if len(all) == 0 {
+		return 0, errors.New("no migrations found")
+	}
+	return all[len(all)-1].Version, nil
+}
+
+// Migrator applies embedded migrations to a database
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// New creates a migrator for a postgres:// database URL
+func New(databaseURL string) (*Migrator, error) {
+	src, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+
+	return &Migrator{m: m}, nil
+}
+
+// Close releases the source and database connections
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// Up applies all pending migrations
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down reverts the last n applied migrations
+func (mg *Migrator) Down(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to revert must be positive, got %d", n)
+	}
+	return ignoreNoChange(mg.m.Steps(-n))
+}
+
+// Goto migrates up or down to the given version
+func (mg *Migrator) Goto(version uint) error {
+	return ignoreNoChange(mg.m.Migrate(version))
+}
+
+// Force sets the schema version without running migrations, clearing the
+// dirty flag. Use -1 to mark the database as having no migrations applied.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// Version returns the applied schema version; 0 means none applied
+func (mg *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// PlanUp lists the migrations Up would apply
+func (mg *Migrator) PlanUp() ([]Step, error) {
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return nil, err
+	}
+	return planUp(current, dirty)
+}
+
+// PlanDown lists the migrations Down(n) would revert
+func (mg *Migrator) PlanDown(n int) ([]Step, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of migrations to revert must be positive, got %d", n)
+	}
+
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return nil, err
+	}
+	return planDown(current, dirty, n)
+}
+
+// PlanGoto lists the migrations Goto(version) would apply or revert
+func (mg *Migrator) PlanGoto(version uint) ([]Step, error) {
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return nil, err
+	}
+	return planVersion(current, dirty, version)
+}
+
+// planUp lists steps from the current version to the latest one
+func planUp(current uint, dirty bool) ([]Step, error) {
+	if dirty {
+		return nil, errDirty(current)
+	}
+	return planGoto(current, ^uint(0))
+}
+
+// planDown lists at most n steps reverting the current version
+func planDown(current uint, dirty bool, n int) ([]Step, error) {
+	if dirty {
+		return nil, errDirty(current)
+	}
+
+	steps, err := planGoto(current, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) > n {
+		steps = steps[:n]
+	}
+	return steps, nil
+}
+
+// planVersion lists steps from the current version to an existing one
+func planVersion(current uint, dirty bool, version uint) ([]Step, error) {
+	all, err := list()
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, m := range all {
+		if m.Version == version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("migration version %d does not exist", version)
+	}
+
+	if dirty {
+		return nil, errDirty(current)
+	}
+	return planGoto(current, version)
+}
+
+// errDirty is what migrate reports for a dirty schema, so a dry run fails
+// the same way the real command would
+func errDirty(version uint) error {
+	return migrate.ErrDirty{Version: int(version)}
+}
+
+// planGoto lists steps between the current and target versions
+func planGoto(current, target uint) ([]Step, error) {
+	all, err := list()
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	if target >= current {
+		for _, m := range all {
+			if m.Version > current && m.Version <= target {
+				steps = append(steps, Step{Version: m.Version, Identifier: m.Identifier, Direction: Up})
+			}
+		}
+		return steps, nil
+	}
+
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if m.Version <= current && m.Version > target {
+			steps = append(steps, Step{Version: m.Version, Identifier: m.Identifier, Direction: Down})
+		}
+	}
+	return steps, nil
+}
+
+// list returns embedded migrations ordered by version
+func list() ([]*source.Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	seen := make(map[uint]bool)
+	var all []*source.Migration
+	for _, entry := range entries {
+		m, err := source.Parse(entry.Name())
+		if err != nil || m.Direction != source.Up || seen[m.Version] {
+			continue
+		}
+		seen[m.Version] = true
+		all = append(all, m)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all, nil
+}
+
+// ignoreNoChange treats "nothing to do" as success
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
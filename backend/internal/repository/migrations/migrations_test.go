@@ -0,0 +1,217 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// versionRange returns the versions from..to inclusive, counting down when
+// from is above to
+func versionRange(from, to uint) []uint {
+	var versions []uint
+	for v := from; ; {
+		versions = append(versions, v)
+		if v == to {
+			return versions
+		}
+		if from < to {
+			v++
+		} else {
+			v--
+		}
+	}
+}
+
+// checkSteps compares the versions of steps and that they all go in dir
+func checkSteps(t *testing.T, steps []Step, dir Direction, want []uint) {
+	t.Helper()
+	got := make([]uint, len(steps))
+	for i, step := range steps {
+		got[i] = step.Version
+		if step.Direction != dir {
+			t.Errorf("step %d direction = %s, want %s", step.Version, step.Direction, dir)
+		}
+		if step.Identifier == "" {
+			t.Errorf("step %d has no identifier", step.Version)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+}
+
+func TestLatest(t *testing.T) {
+	latest, err := Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+
+	all, err := list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Versions are consecutive, so the plans below can count on them
+	for i, m := range all {
+		if m.Version != uint(i+1) {
+			t.Fatalf("migration %d has version %d, want %d", i, m.Version, i+1)
+		}
+	}
+	if latest != uint(len(all)) {
+		t.Errorf("Latest() = %d, want %d", latest, len(all))
+	}
+}
+
+func TestPlanUp(t *testing.T) {
+	latest, err := Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		current uint
+		want    []uint
+	}{
+		{name: "empty database", current: 0, want: versionRange(1, latest)},
+		{name: "partly migrated", current: latest - 2, want: versionRange(latest-1, latest)},
+		{name: "up to date", current: latest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := planUp(tt.current, false)
+			if err != nil {
+				t.Fatalf("planUp() error = %v", err)
+			}
+			checkSteps(t, steps, Up, tt.want)
+		})
+	}
+
+	first, err := planUp(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first[0] != (Step{Version: 1, Identifier: "create_users", Direction: Up}) {
+		t.Errorf("first step = %+v, want 001_create_users up", first[0])
+	}
+}
+
+func TestPlanDown(t *testing.T) {
+	latest, err := Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		current uint
+		n       int
+		want    []uint
+	}{
+		{name: "last migration", current: latest, n: 1, want: []uint{latest}},
+		{name: "several", current: 5, n: 3, want: []uint{5, 4, 3}},
+		{name: "down to nothing", current: 3, n: 3, want: []uint{3, 2, 1}},
+		{name: "past version 0", current: 2, n: 10, want: []uint{2, 1}},
+		{name: "empty database", current: 0, n: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := planDown(tt.current, false, tt.n)
+			if err != nil {
+				t.Fatalf("planDown() error = %v", err)
+			}
+			checkSteps(t, steps, Down, tt.want)
+		})
+	}
+}
+
+func TestPlanVersion(t *testing.T) {
+	latest, err := Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		current uint
+		target  uint
+		dir     Direction
+		want    []uint
+	}{
+		{name: "up from empty", current: 0, target: 3, dir: Up, want: []uint{1, 2, 3}},
+		{name: "up", current: 3, target: 5, dir: Up, want: []uint{4, 5}},
+		{name: "up to latest", current: latest - 1, target: latest, dir: Up, want: []uint{latest}},
+		{name: "down", current: 5, target: 2, dir: Down, want: []uint{5, 4, 3}},
+		{name: "down to first", current: latest, target: 1, dir: Down, want: versionRange(latest, 2)},
+		{name: "same version", current: 4, target: 4, dir: Up},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := planVersion(tt.current, false, tt.target)
+			if err != nil {
+				t.Fatalf("planVersion() error = %v", err)
+			}
+			checkSteps(t, steps, tt.dir, tt.want)
+		})
+	}
+
+	for _, target := range []uint{0, latest + 1} {
+		if _, err := planVersion(1, false, target); err == nil {
+			t.Errorf("planVersion(1, %d) error = nil, want unknown version", target)
+		}
+	}
+}
+
+func TestPlan_DirtyVersion(t *testing.T) {
+	plans := map[string]func() ([]Step, error){
+		"up":   func() ([]Step, error) { return planUp(3, true) },
+		"down": func() ([]Step, error) { return planDown(3, true, 1) },
+		"goto": func() ([]Step, error) { return planVersion(3, true, 5) },
+	}
+
+	for name, plan := range plans {
+		t.Run(name, func(t *testing.T) {
+			steps, err := plan()
+			var dirty migrate.ErrDirty
+			if !errors.As(err, &dirty) {
+				t.Fatalf("error = %v, want migrate.ErrDirty", err)
+			}
+			if dirty.Version != 3 {
+				t.Errorf("dirty version = %d, want 3", dirty.Version)
+			}
+			if steps != nil {
+				t.Errorf("steps = %v, want none", steps)
+			}
+		})
+	}
+}
+
+func TestIgnoreNoChange(t *testing.T) {
+	errOther := errors.New("connection refused")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "success", err: nil, want: nil},
+		{name: "no change", err: migrate.ErrNoChange, want: nil},
+		{name: "wrapped no change", err: fmt.Errorf("up: %w", migrate.ErrNoChange), want: nil},
+		{name: "other error", err: errOther, want: errOther},
+		{name: "dirty", err: migrate.ErrDirty{Version: 2}, want: migrate.ErrDirty{Version: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ignoreNoChange(tt.err); !errors.Is(got, tt.want) {
+				t.Errorf("ignoreNoChange(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
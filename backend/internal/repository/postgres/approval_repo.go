@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ApprovalRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewApprovalRepository(pool *pgxpool.Pool) *ApprovalRepository {
+	return &ApprovalRepository{pool: pool}
+}
+
+// Create inserts a new approval decision
+func (r *ApprovalRepository) Create(ctx context.Context, decision *domain.ApprovalDecision) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO request_approvals (tenantId, requestId, stage, deciderId, decision, comment)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), decision.RequestID, decision.Stage, decision.DeciderID, decision.Decision, decision.Comment,
+	).Scan(&decision.ID, &decision.CreatedAt)
+
+	metrics.RecordDbQuery("approvals.Create", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create approval decision: %w", err)
+	}
+
+	return nil
+}
+
+// GetByRequestID retrieves the decisions taken on a request, oldest first
+func (r *ApprovalRepository) GetByRequestID(ctx context.Context, requestID string) ([]*domain.ApprovalDecision, error) {
+	start := time.Now()
+
+	query := `
+		SELECT a.id, a.requestId, a.stage, a.deciderId, a.decision, a.comment, a.createdAt,
+			COALESCE(u.name, '') AS deciderName
+		FROM request_approvals a
+		LEFT JOIN users u ON a.deciderId = u.id
+		WHERE a.tenantId = $1 AND a.requestId = $2
+		ORDER BY a.createdAt
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), requestID)
+
+	metrics.RecordDbQuery("approvals.GetByRequestID", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval decisions: %w", err)
+	}
+	defer rows.Close()
+
+	decisions := make([]*domain.ApprovalDecision, 0)
+	for rows.Next() {
+		var d domain.ApprovalDecision
+		if err := rows.Scan(&d.ID, &d.RequestID, &d.Stage, &d.DeciderID, &d.Decision, &d.Comment, &d.CreatedAt, &d.DeciderName); err != nil {
+			return nil, fmt.Errorf("failed to scan approval decision: %w", err)
+		}
+		decisions = append(decisions, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating approval decisions: %w", err)
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttachmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAttachmentRepository(pool *pgxpool.Pool) *AttachmentRepository {
+	return &AttachmentRepository{pool: pool}
+}
+
+// attachmentColumns selects an attachment joined with its uploader
+const attachmentColumns = `
+	a.id, a.learningId, a.planItemId, a.commentId, a.uploaderId,
+	a.fileName, a.contentType, a.size, a.checksum, a.storageKey, a.createdAt,
+	COALESCE(u.name, '') AS uploaderName
+`
+
+// Create inserts the metadata of an uploaded file
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO attachments (tenantId, learningId, planItemId, commentId, uploaderId, fileName, contentType, size, checksum, storageKey)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), attachment.LearningID, attachment.PlanItemID, attachment.CommentID, attachment.UploaderID,
+		attachment.FileName, attachment.ContentType, attachment.Size, attachment.Checksum, attachment.StorageKey,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+
+	metrics.RecordDbQuery("attachments.Create", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an attachment by its ID
+func (r *AttachmentRepository) GetByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		LEFT JOIN users u ON a.uploaderId = u.id
+		WHERE a.tenantId = $1 AND a.id = $2
+	`
+
+	attachment, err := scanAttachment(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("attachments.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// GetByLearningID retrieves the attachments of a learning and its plan
+// items, oldest first
+func (r *AttachmentRepository) GetByLearningID(ctx context.Context, learningID string) ([]*domain.Attachment, error) {
+	return r.list(ctx, "attachments.GetByLearningID", "a.learningId = $2", learningID)
+}
+
+// GetByCommentID retrieves the attachments of a comment, oldest first
+func (r *AttachmentRepository) GetByCommentID(ctx context.Context, commentID string) ([]*domain.Attachment, error) {
+	return r.list(ctx, "attachments.GetByCommentID", "a.commentId = $2", commentID)
+}
+
+// Delete removes the metadata of an attachment
+func (r *AttachmentRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM attachments WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("attachments.Delete", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+func (r *AttachmentRepository) list(ctx context.Context, op, where string, arg any) ([]*domain.Attachment, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		LEFT JOIN users u ON a.uploaderId = u.id
+		WHERE a.tenantId = $1 AND ` + where + `
+		ORDER BY a.createdAt
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), arg)
+
+	metrics.RecordDbQuery(op, time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]*domain.Attachment, 0)
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func scanAttachment(row pgx.Row) (*domain.Attachment, error) {
+	var a domain.Attachment
+	err := row.Scan(
+		&a.ID, &a.LearningID, &a.PlanItemID, &a.CommentID, &a.UploaderID,
+		&a.FileName, &a.ContentType, &a.Size, &a.Checksum, &a.StorageKey, &a.CreatedAt,
+		&a.UploaderName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
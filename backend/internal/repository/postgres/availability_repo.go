@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AvailabilityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAvailabilityRepository(pool *pgxpool.Pool) *AvailabilityRepository {
+	return &AvailabilityRepository{pool: pool}
+}
+
+// CreateWindow inserts a new weekly availability window
+func (r *AvailabilityRepository) CreateWindow(ctx context.Context, window *domain.AvailabilityWindow) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO mentor_availability_windows (tenantId, mentorId, weekday, startTime, endTime)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), window.MentorID, int(window.Weekday), window.StartTime, window.EndTime,
+	).Scan(&window.ID, &window.CreatedAt)
+
+	metrics.RecordDbQuery("availability.CreateWindow", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create availability window: %w", err)
+	}
+
+	return nil
+}
+
+// GetWindows retrieves the weekly windows of a mentor, ordered by weekday
+// and start time
+func (r *AvailabilityRepository) GetWindows(ctx context.Context, mentorID string) ([]*domain.AvailabilityWindow, error) {
+	start := time.Now()
+
+	query := `
+		SELECT id, mentorId, weekday, startTime, endTime, createdAt
+		FROM mentor_availability_windows
+		WHERE tenantId = $1 AND mentorId = $2
+		ORDER BY weekday, startTime
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), mentorID)
+
+	metrics.RecordDbQuery("availability.GetWindows", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability windows: %w", err)
+	}
+	defer rows.Close()
+
+	windows := make([]*domain.AvailabilityWindow, 0)
+	for rows.Next() {
+		var w domain.AvailabilityWindow
+		var weekday int
+		if err := rows.Scan(&w.ID, &w.MentorID, &weekday, &w.StartTime, &w.EndTime, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan availability window: %w", err)
+		}
+		w.Weekday = time.Weekday(weekday)
+		windows = append(windows, &w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating availability windows: %w", err)
+	}
+
+	return windows, nil
+}
+
+// UpdateWindow changes the weekday and times of a window of the mentor
+func (r *AvailabilityRepository) UpdateWindow(ctx context.Context, window *domain.AvailabilityWindow) error {
+	start := time.Now()
+
+	query := `
+		UPDATE mentor_availability_windows
+		SET weekday = $4, startTime = $5, endTime = $6
+		WHERE tenantId = $1 AND id = $2 AND mentorId = $3
+		RETURNING createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), window.ID, window.MentorID, int(window.Weekday), window.StartTime, window.EndTime,
+	).Scan(&window.CreatedAt)
+
+	metrics.RecordDbQuery("availability.UpdateWindow", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrWindowNotFound
+		}
+		return fmt.Errorf("failed to update availability window: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteWindow removes a window of the mentor
+func (r *AvailabilityRepository) DeleteWindow(ctx context.Context, id, mentorID string) error {
+	start := time.Now()
+
+	query := `DELETE FROM mentor_availability_windows WHERE tenantId = $1 AND id = $2 AND mentorId = $3`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id, mentorID)
+
+	metrics.RecordDbQuery("availability.DeleteWindow", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete availability window: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrWindowNotFound
+	}
+
+	return nil
+}
+
+// CreateAbsence inserts a new absence period
+func (r *AvailabilityRepository) CreateAbsence(ctx context.Context, absence *domain.Absence) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO mentor_absences (tenantId, mentorId, kind, startsAt, endsAt, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), absence.MentorID, absence.Kind, absence.StartsAt, absence.EndsAt, absence.Note,
+	).Scan(&absence.ID, &absence.CreatedAt)
+
+	metrics.RecordDbQuery("availability.CreateAbsence", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create absence: %w", err)
+	}
+
+	return nil
+}
+
+// GetAbsences retrieves absences matching the filter, ordered by start
+func (r *AvailabilityRepository) GetAbsences(ctx context.Context, filter domain.AbsenceFilter) ([]*domain.Absence, error) {
+	start := time.Now()
+
+	query := `
+		SELECT id, mentorId, kind, startsAt, endsAt, note, createdAt
+		FROM mentor_absences
+		WHERE tenantId = $1
+		  AND ($2 = '' OR mentorId::text = $2)
+		  AND ($3::timestamptz IS NULL OR endsAt > $3)
+		  AND ($4::timestamptz IS NULL OR startsAt <= $4)
+		ORDER BY startsAt, createdAt
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), filter.MentorID, filter.From, filter.To)
+
+	metrics.RecordDbQuery("availability.GetAbsences", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+	defer rows.Close()
+
+	absences := make([]*domain.Absence, 0)
+	for rows.Next() {
+		var a domain.Absence
+		if err := rows.Scan(&a.ID, &a.MentorID, &a.Kind, &a.StartsAt, &a.EndsAt, &a.Note, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan absence: %w", err)
+		}
+		absences = append(absences, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating absences: %w", err)
+	}
+
+	return absences, nil
+}
+
+// UpdateAbsence changes the kind, period and note of an absence of the mentor
+func (r *AvailabilityRepository) UpdateAbsence(ctx context.Context, absence *domain.Absence) error {
+	start := time.Now()
+
+	query := `
+		UPDATE mentor_absences
+		SET kind = $4, startsAt = $5, endsAt = $6, note = $7
+		WHERE tenantId = $1 AND id = $2 AND mentorId = $3
+		RETURNING createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), absence.ID, absence.MentorID, absence.Kind, absence.StartsAt, absence.EndsAt, absence.Note,
+	).Scan(&absence.CreatedAt)
+
+	metrics.RecordDbQuery("availability.UpdateAbsence", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrAbsenceNotFound
+		}
+		return fmt.Errorf("failed to update absence: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAbsence removes an absence of the mentor
+func (r *AvailabilityRepository) DeleteAbsence(ctx context.Context, id, mentorID string) error {
+	start := time.Now()
+
+	query := `DELETE FROM mentor_absences WHERE tenantId = $1 AND id = $2 AND mentorId = $3`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id, mentorID)
+
+	metrics.RecordDbQuery("availability.DeleteAbsence", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrAbsenceNotFound
+	}
+
+	return nil
+}
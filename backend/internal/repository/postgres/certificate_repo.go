@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CertificateRepository stores issued certificates
+type CertificateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCertificateRepository(pool *pgxpool.Pool) *CertificateRepository {
+	return &CertificateRepository{pool: pool}
+}
+
+const certificateColumns = `
+	id, code, learningId, learnerName, topic, mentorName,
+	startDate, endDate, completedItems, storageKey, issuedAt
+`
+
+// Create inserts a certificate; a learning gets at most one
+func (r *CertificateRepository) Create(ctx context.Context, certificate *domain.Certificate) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO certificates (tenantId, code, learningId, learnerName, topic, mentorName, startDate, endDate, completedItems, storageKey, issuedAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), certificate.Code, certificate.LearningID, certificate.LearnerName, certificate.Topic, certificate.MentorName,
+		certificate.StartDate, certificate.EndDate, certificate.CompletedItems, certificate.StorageKey, certificate.IssuedAt,
+	).Scan(&certificate.ID)
+
+	metrics.RecordDbQuery("certificates.Create", time.Since(start), err)
+
+	if err != nil {
+		switch {
+		case isViolation(err, uniqueViolation):
+			return domain.ErrCertificateExists
+		case isViolation(err, foreignKeyViolation):
+			return domain.ErrLearningNotFound
+		}
+		return fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return nil
+}
+
+// GetByLearningID retrieves the certificate of a learning
+func (r *CertificateRepository) GetByLearningID(ctx context.Context, learningID string) (*domain.Certificate, error) {
+	return r.get(ctx, "certificates.GetByLearningID", "tenantId = $1 AND learningId = $2", domain.TenantID(ctx), learningID)
+}
+
+// GetByCode retrieves a certificate by its verification code. Codes are
+// unique across tenants and the verification link is public, so this is
+// the one lookup that ignores the tenant.
+func (r *CertificateRepository) GetByCode(ctx context.Context, code string) (*domain.Certificate, error) {
+	return r.get(ctx, "certificates.GetByCode", "code = $1", code)
+}
+
+func (r *CertificateRepository) get(ctx context.Context, op, where string, args ...any) (*domain.Certificate, error) {
+	start := time.Now()
+
+	query := `SELECT ` + certificateColumns + ` FROM certificates WHERE ` + where
+
+	var c domain.Certificate
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&c.ID, &c.Code, &c.LearningID, &c.LearnerName, &c.Topic, &c.MentorName,
+		&c.StartDate, &c.EndDate, &c.CompletedItems, &c.StorageKey, &c.IssuedAt,
+	)
+
+	metrics.RecordDbQuery(op, time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCertificateNotFound
+		}
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+
+	return &c, nil
+}
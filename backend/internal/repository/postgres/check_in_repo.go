@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CheckInRepository stores check-ins of active learnings and stall alerts
+type CheckInRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCheckInRepository(pool *pgxpool.Pool) *CheckInRepository {
+	return &CheckInRepository{pool: pool}
+}
+
+// checkInColumns selects a check-in joined with the learning topic
+const checkInColumns = `
+	c.id, c.learningId, c.userId, c.role, c.round,
+	COALESCE(c.pulse, '') AS pulse, c.comment, c.createdAt, c.answeredAt,
+	COALESCE(r.topic, '') AS topic
+`
+
+// checkInFrom joins check-ins with the request of their learning
+const checkInFrom = `
+	FROM learning_check_ins c
+	LEFT JOIN learning_processes lp ON c.learningId = lp.id
+	LEFT JOIN training_requests r ON lp.requestId = r.id
+`
+
+// Create inserts an unanswered check-in; a side gets one per round
+func (r *CheckInRepository) Create(ctx context.Context, checkIn *domain.CheckIn) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO learning_check_ins (tenantId, learningId, userId, role, round)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), checkIn.LearningID, checkIn.UserID, checkIn.Role, checkIn.Round,
+	).Scan(&checkIn.ID, &checkIn.CreatedAt)
+
+	metrics.RecordDbQuery("checkIns.Create", time.Since(start), err)
+
+	if err == nil {
+		metrics.CheckInsSent.WithLabelValues(string(checkIn.Role)).Inc()
+	}
+
+	if err != nil {
+		switch {
+		case isViolation(err, uniqueViolation):
+			return domain.ErrCheckInExists
+		case isViolation(err, foreignKeyViolation):
+			return domain.ErrLearningNotFound
+		}
+		return fmt.Errorf("failed to create check-in: %w", err)
+	}
+
+	checkIn.Pulse = ""
+	checkIn.Comment = ""
+	checkIn.AnsweredAt = nil
+	return nil
+}
+
+// GetByID retrieves a check-in by its ID
+func (r *CheckInRepository) GetByID(ctx context.Context, id string) (*domain.CheckIn, error) {
+	start := time.Now()
+
+	query := `SELECT ` + checkInColumns + checkInFrom + `WHERE c.tenantId = $1 AND c.id = $2`
+
+	checkIn, err := scanCheckIn(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("checkIns.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCheckInNotFound
+		}
+		return nil, fmt.Errorf("failed to get check-in: %w", err)
+	}
+
+	return checkIn, nil
+}
+
+// GetByLearningID retrieves the check-ins of a learning, newest first
+func (r *CheckInRepository) GetByLearningID(ctx context.Context, learningID string) ([]*domain.CheckIn, error) {
+	return r.list(ctx, "checkIns.GetByLearningID", "c.learningId = $2", learningID)
+}
+
+// GetPending retrieves the unanswered check-ins of a user, newest first
+func (r *CheckInRepository) GetPending(ctx context.Context, userID string) ([]*domain.CheckIn, error) {
+	return r.list(ctx, "checkIns.GetPending", "c.userId = $2 AND c.answeredAt IS NULL", userID)
+}
+
+func (r *CheckInRepository) list(ctx context.Context, op, where string, arg any) ([]*domain.CheckIn, error) {
+	start := time.Now()
+
+	query := `SELECT ` + checkInColumns + checkInFrom + `WHERE c.tenantId = $1 AND ` + where + ` ORDER BY c.createdAt DESC, c.id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), arg)
+
+	metrics.RecordDbQuery(op, time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check-ins: %w", err)
+	}
+	defer rows.Close()
+
+	checkIns := make([]*domain.CheckIn, 0)
+	for rows.Next() {
+		checkIn, err := scanCheckIn(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan check-in: %w", err)
+		}
+		checkIns = append(checkIns, checkIn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating check-ins: %w", err)
+	}
+
+	return checkIns, nil
+}
+
+// Answer records the reply to an unanswered check-in
+func (r *CheckInRepository) Answer(ctx context.Context, id string, pulse domain.CheckInPulse, comment string) error {
+	start := time.Now()
+
+	query := `
+		UPDATE learning_check_ins
+		SET pulse = $3, comment = $4, answeredAt = CURRENT_TIMESTAMP
+		WHERE tenantId = $1 AND id = $2 AND answeredAt IS NULL
+		RETURNING answeredAt
+	`
+
+	var answeredAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, pulse, comment).Scan(&answeredAt)
+
+	metrics.RecordDbQuery("checkIns.Answer", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Either missing or answered already
+			if _, getErr := r.GetByID(ctx, id); getErr != nil {
+				return getErr
+			}
+			return domain.ErrCheckInAnswered
+		}
+		return fmt.Errorf("failed to answer check-in: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimStallAlert records a stall alert unless one was recorded after since.
+// The upsert makes concurrent schedulers alert the mentor once.
+func (r *CheckInRepository) ClaimStallAlert(ctx context.Context, learningID string, since time.Time) (bool, error) {
+	start := time.Now()
+
+	query := `
+		INSERT INTO learning_stall_alerts (tenantId, learningId)
+		VALUES ($1, $2)
+		ON CONFLICT (learningId) DO UPDATE
+		SET notifiedAt = CURRENT_TIMESTAMP
+		WHERE learning_stall_alerts.notifiedAt < $3
+		RETURNING notifiedAt
+	`
+
+	var notifiedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), learningID, since).Scan(&notifiedAt)
+
+	metrics.RecordDbQuery("checkIns.ClaimStallAlert", time.Since(start), err)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return false, nil
+		case isViolation(err, foreignKeyViolation):
+			return false, domain.ErrLearningNotFound
+		}
+		return false, fmt.Errorf("failed to record stall alert: %w", err)
+	}
+
+	return true, nil
+}
+
+// scanCheckIn reads a row selected with checkInColumns
+func scanCheckIn(row pgx.Row) (*domain.CheckIn, error) {
+	var c domain.CheckIn
+	err := row.Scan(
+		&c.ID, &c.LearningID, &c.UserID, &c.Role, &c.Round,
+		&c.Pulse, &c.Comment, &c.CreatedAt, &c.AnsweredAt,
+		&c.Topic,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
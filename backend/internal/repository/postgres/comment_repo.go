@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CommentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCommentRepository(pool *pgxpool.Pool) *CommentRepository {
+	return &CommentRepository{pool: pool}
+}
+
+// commentColumns selects a comment joined with its author; the thread is
+// stored in one of two columns so both can reference their table
+const commentColumns = `
+	c.id,
+	CASE WHEN c.requestId IS NOT NULL THEN 'request' ELSE 'learning' END AS targetType,
+	COALESCE(c.requestId, c.learningId) AS targetId,
+	c.parentId, c.authorId, c.body, c.visibility, c.editedAt, c.deletedAt, c.createdAt,
+	COALESCE(u.name, '') AS authorName
+`
+
+// Create inserts a new comment
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	start := time.Now()
+
+	requestID, learningID, err := commentTarget(comment.TargetType, comment.TargetID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO comments (tenantId, requestId, learningId, parentId, authorId, body, visibility)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, createdAt
+	`
+
+	err = conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), requestID, learningID, comment.ParentID, comment.AuthorID, comment.Body, comment.Visibility,
+	).Scan(&comment.ID, &comment.CreatedAt)
+
+	metrics.RecordDbQuery("comments.Create", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a comment by its ID
+func (r *CommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		LEFT JOIN users u ON c.authorId = u.id
+		WHERE c.tenantId = $1 AND c.id = $2
+	`
+
+	comment, err := scanComment(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("comments.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// GetByTarget retrieves the thread of a request or learning, oldest first,
+// including deleted comments; internal comments only if asked for
+func (r *CommentRepository) GetByTarget(ctx context.Context, target domain.CommentTarget, targetID string, includeInternal bool) ([]*domain.Comment, error) {
+	start := time.Now()
+
+	requestID, learningID, err := commentTarget(target, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		LEFT JOIN users u ON c.authorId = u.id
+		WHERE c.tenantId = $1
+			AND (c.requestId = $2 OR c.learningId = $3)
+			AND ($4 OR c.visibility <> 'internal')
+		ORDER BY c.createdAt
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), requestID, learningID, includeInternal)
+
+	metrics.RecordDbQuery("comments.GetByTarget", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*domain.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// UpdateBody replaces the body of a comment that is not deleted and marks it
+// as edited
+func (r *CommentRepository) UpdateBody(ctx context.Context, id, body string) error {
+	start := time.Now()
+
+	query := `
+		UPDATE comments
+		SET body = $3, editedAt = NOW()
+		WHERE tenantId = $1 AND id = $2 AND deletedAt IS NULL
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id, body)
+
+	metrics.RecordDbQuery("comments.UpdateBody", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// Delete clears the body of a comment and marks it as deleted, keeping it
+// in the thread for its replies; deleting it again keeps the first time
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+
+	query := `
+		UPDATE comments
+		SET body = '', deletedAt = COALESCE(deletedAt, NOW())
+		WHERE tenantId = $1 AND id = $2
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("comments.Delete", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// commentTarget splits a thread reference into the request and learning
+// columns
+func commentTarget(target domain.CommentTarget, targetID string) (requestID, learningID *string, err error) {
+	switch target {
+	case domain.CommentOnRequest:
+		return &targetID, nil, nil
+	case domain.CommentOnLearning:
+		return nil, &targetID, nil
+	}
+	return nil, nil, fmt.Errorf("%w: unknown comment target %q", domain.ErrInvalidInput, target)
+}
+
+func scanComment(row pgx.Row) (*domain.Comment, error) {
+	var c domain.Comment
+	err := row.Scan(
+		&c.ID, &c.TargetType, &c.TargetID,
+		&c.ParentID, &c.AuthorID, &c.Body, &c.Visibility, &c.EditedAt, &c.DeletedAt, &c.CreatedAt,
+		&c.AuthorName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,225 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CompetencyRepository stores skill targets and the levels users hold
+type CompetencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCompetencyRepository(pool *pgxpool.Pool) *CompetencyRepository {
+	return &CompetencyRepository{pool: pool}
+}
+
+// userSkillColumns selects a user skill joined with its skill
+const userSkillColumns = `
+	us.userId, us.skillId, us.selfLevel, us.managerLevel, us.assessedBy, us.level, us.updatedAt,
+	s.name AS skillName
+`
+
+// CreateTarget inserts a skill target; there is one target per skill,
+// department and job title
+func (r *CompetencyRepository) CreateTarget(ctx context.Context, target *domain.SkillTarget) error {
+	start := time.Now()
+
+	// Selecting the skill keeps targets from pointing at another tenant's
+	query := `
+		WITH inserted AS (
+			INSERT INTO skill_targets (tenantId, skillId, department, jobTitle, level)
+			SELECT tenantId, id, $3::varchar, $4::varchar, $5::int
+			FROM skills
+			WHERE tenantId = $1 AND id = $2
+			RETURNING id, skillId, createdAt
+		)
+		SELECT i.id, i.createdAt, s.name
+		FROM inserted i
+		INNER JOIN skills s ON i.skillId = s.id
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), target.SkillID, target.Department, target.JobTitle, target.Level).Scan(
+		&target.ID, &target.CreatedAt, &target.SkillName,
+	)
+
+	metrics.RecordDbQuery("competencies.CreateTarget", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isViolation(err, foreignKeyViolation) {
+			return domain.ErrSkillNotFound
+		}
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrSkillTargetExists
+		}
+		return fmt.Errorf("failed to create skill target: %w", err)
+	}
+
+	return nil
+}
+
+// GetTargets retrieves every skill target ordered by skill name
+func (r *CompetencyRepository) GetTargets(ctx context.Context) ([]*domain.SkillTarget, error) {
+	start := time.Now()
+
+	query := `
+		SELECT t.id, t.skillId, t.department, t.jobTitle, t.level, t.createdAt, s.name AS skillName
+		FROM skill_targets t
+		INNER JOIN skills s ON t.skillId = s.id
+		WHERE t.tenantId = $1
+		ORDER BY lower(s.name), t.department NULLS FIRST, t.jobTitle NULLS FIRST
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("competencies.GetTargets", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skill targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := make([]*domain.SkillTarget, 0)
+	for rows.Next() {
+		var t domain.SkillTarget
+		if err := rows.Scan(&t.ID, &t.SkillID, &t.Department, &t.JobTitle, &t.Level, &t.CreatedAt, &t.SkillName); err != nil {
+			return nil, fmt.Errorf("failed to scan skill target: %w", err)
+		}
+		targets = append(targets, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating skill targets: %w", err)
+	}
+
+	return targets, nil
+}
+
+// DeleteTarget removes a skill target
+func (r *CompetencyRepository) DeleteTarget(ctx context.Context, id string) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM skill_targets WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("competencies.DeleteTarget", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete skill target: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrSkillTargetNotFound
+	}
+
+	return nil
+}
+
+// GetUserSkills retrieves the levels a user holds, ordered by skill name
+func (r *CompetencyRepository) GetUserSkills(ctx context.Context, userID string) ([]*domain.UserSkill, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + userSkillColumns + `
+		FROM user_skills us
+		INNER JOIN skills s ON us.skillId = s.id
+		WHERE us.tenantId = $1 AND us.userId = $2
+		ORDER BY lower(s.name)
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), userID)
+
+	metrics.RecordDbQuery("competencies.GetUserSkills", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user skills: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserSkills(rows)
+}
+
+// GetAllUserSkills retrieves the levels of every user
+func (r *CompetencyRepository) GetAllUserSkills(ctx context.Context) ([]*domain.UserSkill, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + userSkillColumns + `
+		FROM user_skills us
+		INNER JOIN skills s ON us.skillId = s.id
+		WHERE us.tenantId = $1
+		ORDER BY us.userId, lower(s.name)
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("competencies.GetAllUserSkills", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user skills: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserSkills(rows)
+}
+
+// SaveUserSkill inserts or replaces the user's standing in a skill
+func (r *CompetencyRepository) SaveUserSkill(ctx context.Context, us *domain.UserSkill) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO user_skills (tenantId, userId, skillId, selfLevel, managerLevel, assessedBy, level)
+		SELECT tenantId, $2::uuid, id, $4::int, $5::int, $6::uuid, $7::int
+		FROM skills
+		WHERE tenantId = $1 AND id = $3
+		ON CONFLICT (userId, skillId) DO UPDATE
+		SET selfLevel = EXCLUDED.selfLevel,
+		    managerLevel = EXCLUDED.managerLevel,
+		    assessedBy = EXCLUDED.assessedBy,
+		    level = EXCLUDED.level
+		RETURNING updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query, domain.TenantID(ctx), us.UserID, us.SkillID, us.SelfLevel, us.ManagerLevel, us.AssessedBy, us.Level,
+	).Scan(&us.UpdatedAt)
+
+	metrics.RecordDbQuery("competencies.SaveUserSkill", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isViolation(err, foreignKeyViolation) {
+			return domain.ErrSkillNotFound
+		}
+		return fmt.Errorf("failed to save user skill: %w", err)
+	}
+
+	return nil
+}
+
+func scanUserSkills(rows pgx.Rows) ([]*domain.UserSkill, error) {
+	userSkills := make([]*domain.UserSkill, 0)
+	for rows.Next() {
+		var us domain.UserSkill
+		err := rows.Scan(
+			&us.UserID, &us.SkillID, &us.SelfLevel, &us.ManagerLevel, &us.AssessedBy, &us.Level, &us.UpdatedAt,
+			&us.SkillName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user skill: %w", err)
+		}
+		userSkills = append(userSkills, &us)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user skills: %w", err)
+	}
+
+	return userSkills, nil
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CourseRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCourseRepository(pool *pgxpool.Pool) *CourseRepository {
+	return &CourseRepository{pool: pool}
+}
+
+const courseColumns = `
+	id, title, description, format, durationHours, provider, skills, capacity,
+	enrollmentOpensAt, enrollmentClosesAt, seatsTaken, createdAt, updatedAt
+`
+
+// Create inserts a new course
+func (r *CourseRepository) Create(ctx context.Context, course *domain.Course) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO courses (tenantId, title, description, format, durationHours, provider, skills, capacity, enrollmentOpensAt, enrollmentClosesAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, seatsTaken, createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), course.Title, course.Description, course.Format, course.DurationHours, course.Provider,
+		domain.CleanSkills(course.Skills), course.Capacity, course.EnrollmentOpensAt, course.EnrollmentClosesAt,
+	).Scan(&course.ID, &course.SeatsTaken, &course.CreatedAt, &course.UpdatedAt)
+
+	metrics.RecordDbQuery("courses.Create", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create course: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a course by its ID
+func (r *CourseRepository) GetByID(ctx context.Context, id string) (*domain.Course, error) {
+	start := time.Now()
+
+	query := `SELECT ` + courseColumns + ` FROM courses WHERE tenantId = $1 AND id = $2`
+
+	course, err := scanCourse(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("courses.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCourseNotFound
+		}
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+
+	return course, nil
+}
+
+// Search retrieves the courses matching the filter, ordered by title
+func (r *CourseRepository) Search(ctx context.Context, filter domain.CourseFilter) ([]*domain.Course, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + courseColumns + `
+		FROM courses
+		WHERE tenantId = $1
+		  AND ($2 = '' OR strpos(lower(title || ' ' || description || ' ' || provider), lower($2)) > 0)
+		  AND ($3 = '' OR EXISTS (SELECT 1 FROM unnest(skills) AS skill WHERE lower(skill) = lower($3)))
+		  AND ($4::text IS NULL OR format = $4)
+		  AND ($5::timestamptz IS NULL OR (
+		      (enrollmentOpensAt IS NULL OR enrollmentOpensAt <= $5) AND
+		      (enrollmentClosesAt IS NULL OR enrollmentClosesAt > $5)))
+		ORDER BY title, createdAt
+	`
+
+	var format *string
+	if filter.Format != nil {
+		f := string(*filter.Format)
+		format = &f
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), filter.Query, filter.Skill, format, filter.OpenAt)
+
+	metrics.RecordDbQuery("courses.Search", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search courses: %w", err)
+	}
+	defer rows.Close()
+
+	courses := make([]*domain.Course, 0)
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course: %w", err)
+		}
+		courses = append(courses, course)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating courses: %w", err)
+	}
+
+	return courses, nil
+}
+
+// Update replaces the catalog fields of a course; taken seats are kept
+func (r *CourseRepository) Update(ctx context.Context, course *domain.Course) error {
+	start := time.Now()
+
+	query := `
+		UPDATE courses
+		SET title = $3, description = $4, format = $5, durationHours = $6, provider = $7,
+		    skills = $8, capacity = $9, enrollmentOpensAt = $10, enrollmentClosesAt = $11
+		WHERE tenantId = $1 AND id = $2
+		RETURNING seatsTaken, createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query, domain.TenantID(ctx), course.ID,
+		course.Title, course.Description, course.Format, course.DurationHours, course.Provider,
+		domain.CleanSkills(course.Skills), course.Capacity, course.EnrollmentOpensAt, course.EnrollmentClosesAt,
+	).Scan(&course.SeatsTaken, &course.CreatedAt, &course.UpdatedAt)
+
+	metrics.RecordDbQuery("courses.Update", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrCourseNotFound
+		}
+		return fmt.Errorf("failed to update course: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a course; courses referenced by enrollment requests are
+// kept and reported as in use
+func (r *CourseRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM courses WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("courses.Delete", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, foreignKeyViolation) {
+			return domain.ErrCourseInUse
+		}
+		return fmt.Errorf("failed to delete course: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCourseNotFound
+	}
+
+	return nil
+}
+
+func scanCourse(row pgx.Row) (*domain.Course, error) {
+	var c domain.Course
+	err := row.Scan(
+		&c.ID, &c.Title, &c.Description, &c.Format, &c.DurationHours, &c.Provider, &c.Skills, &c.Capacity,
+		&c.EnrollmentOpensAt, &c.EnrollmentClosesAt, &c.SeatsTaken, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -2,13 +2,27 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
 )
 
+// SQLSTATE codes of the constraint violations mapped to domain errors
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+// isViolation checks if err is a Postgres error with the given SQLSTATE
+func isViolation(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
 // Config holds database configuration
 type Config struct {
 	Host     string
@@ -48,8 +62,6 @@ func NewPool(ctx context.Context, cfg Config) (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	go updateConnectionMetrics(pool)
-
 	return pool, nil
 }
 
@@ -60,14 +72,20 @@ func Close(pool *pgxpool.Pool) {
 	}
 }
 
-// updateConnectionMetrics periodically updates connection pool metrics
-func updateConnectionMetrics(pool *pgxpool.Pool) {
-	ticker := time.NewTicker(10 * time.Second)
+// CollectConnectionMetrics periodically updates connection pool metrics
+// until ctx is cancelled
+func CollectConnectionMetrics(ctx context.Context, pool *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		stat := pool.Stat()
-		metrics.DbConnectionsActive.Set(float64(stat.AcquiredConns()))
-		metrics.DbConnectionsIdle.Set(float64(stat.IdleConns()))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stat := pool.Stat()
+			metrics.DbConnectionsActive.Set(float64(stat.AcquiredConns()))
+			metrics.DbConnectionsIdle.Set(float64(stat.IdleConns()))
+		}
 	}
 }
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DepartmentRepository stores the department tree. Departments with members
+// or subdepartments cannot be deleted; deleting the head of a department
+// leaves it without one.
+type DepartmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDepartmentRepository(pool *pgxpool.Pool) *DepartmentRepository {
+	return &DepartmentRepository{pool: pool}
+}
+
+const departmentColumns = `id, name, parentId, headId, createdAt, updatedAt`
+
+// Create inserts a new department; names are unique ignoring case
+func (r *DepartmentRepository) Create(ctx context.Context, department *domain.Department) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO departments (tenantId, name, parentId, headId)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), department.Name, department.ParentID, department.HeadID).Scan(
+		&department.ID, &department.CreatedAt, &department.UpdatedAt,
+	)
+
+	metrics.RecordDbQuery("departments.Create", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrDepartmentExists
+		}
+		return fmt.Errorf("failed to create department: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a department by its ID
+func (r *DepartmentRepository) GetByID(ctx context.Context, id string) (*domain.Department, error) {
+	start := time.Now()
+
+	query := `SELECT ` + departmentColumns + ` FROM departments WHERE tenantId = $1 AND id = $2`
+
+	department, err := scanDepartment(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("departments.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDepartmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get department: %w", err)
+	}
+
+	return department, nil
+}
+
+// GetByName retrieves a department by its name, ignoring case
+func (r *DepartmentRepository) GetByName(ctx context.Context, name string) (*domain.Department, error) {
+	start := time.Now()
+
+	query := `SELECT ` + departmentColumns + ` FROM departments WHERE tenantId = $1 AND lower(name) = lower($2)`
+
+	department, err := scanDepartment(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), name))
+
+	metrics.RecordDbQuery("departments.GetByName", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDepartmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get department: %w", err)
+	}
+
+	return department, nil
+}
+
+// GetAll retrieves all departments ordered by name
+func (r *DepartmentRepository) GetAll(ctx context.Context) ([]*domain.Department, error) {
+	start := time.Now()
+
+	query := `SELECT ` + departmentColumns + ` FROM departments WHERE tenantId = $1 ORDER BY lower(name)`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("departments.GetAll", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get departments: %w", err)
+	}
+	defer rows.Close()
+
+	departments := make([]*domain.Department, 0)
+	for rows.Next() {
+		department, err := scanDepartment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan department: %w", err)
+		}
+		departments = append(departments, department)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating departments: %w", err)
+	}
+
+	return departments, nil
+}
+
+// Update renames, moves or changes the head of a department
+func (r *DepartmentRepository) Update(ctx context.Context, department *domain.Department) error {
+	start := time.Now()
+
+	query := `
+		UPDATE departments
+		SET name = $3, parentId = $4, headId = $5
+		WHERE tenantId = $1 AND id = $2
+		RETURNING createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), department.ID, department.Name, department.ParentID, department.HeadID,
+	).Scan(&department.CreatedAt, &department.UpdatedAt)
+
+	metrics.RecordDbQuery("departments.Update", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrDepartmentNotFound
+		}
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrDepartmentExists
+		}
+		return fmt.Errorf("failed to update department: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a department without members or subdepartments
+func (r *DepartmentRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM departments WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("departments.Delete", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, foreignKeyViolation) {
+			return domain.ErrDepartmentInUse
+		}
+		return fmt.Errorf("failed to delete department: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrDepartmentNotFound
+	}
+
+	return nil
+}
+
+// scanDepartment reads a row selected with departmentColumns
+func scanDepartment(row pgx.Row) (*domain.Department, error) {
+	var d domain.Department
+	if err := row.Scan(&d.ID, &d.Name, &d.ParentID, &d.HeadID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,250 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EnrollmentRepository stores course enrollments. Enrollments count as
+// learning processes in the business metrics, so the catalog shows up in
+// the same dashboards.
+type EnrollmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEnrollmentRepository(pool *pgxpool.Pool) *EnrollmentRepository {
+	return &EnrollmentRepository{pool: pool}
+}
+
+// enrollmentColumns selects an enrollment joined with its course and user
+const enrollmentColumns = `
+	e.id, e.courseId, e.userId, e.requestId, e.status, e.feedback,
+	e.enrolledAt, e.completedAt, e.updatedAt,
+	c.title AS courseTitle,
+	u.name AS userName
+`
+
+// Create takes a seat on the course and inserts the enrollment. The seat
+// is taken with a conditional UPDATE, which Postgres re-checks against the
+// latest row, so concurrent enrollments cannot overbook a course.
+func (r *EnrollmentRepository) Create(ctx context.Context, enrollment *domain.CourseEnrollment) error {
+	start := time.Now()
+
+	query := `
+		WITH seat AS (
+			UPDATE courses
+			SET seatsTaken = seatsTaken + 1
+			WHERE tenantId = $1 AND id = $2 AND (capacity IS NULL OR seatsTaken < capacity)
+			RETURNING id
+		)
+		INSERT INTO course_enrollments (tenantId, courseId, userId, requestId)
+		SELECT $1, id, $3::uuid, $4::uuid FROM seat
+		RETURNING id, status, enrolledAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), enrollment.CourseID, enrollment.UserID, enrollment.RequestID).Scan(
+		&enrollment.ID, &enrollment.Status, &enrollment.EnrolledAt, &enrollment.UpdatedAt,
+	)
+
+	metrics.RecordDbQuery("enrollments.Create", time.Since(start), err)
+
+	if err == nil {
+		metrics.LearningProcessesActive.Inc()
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.noSeat(ctx, enrollment.CourseID)
+		}
+		return fmt.Errorf("failed to create enrollment: %w", err)
+	}
+
+	return nil
+}
+
+// noSeat tells a full course from a missing one after Create took no seat
+func (r *EnrollmentRepository) noSeat(ctx context.Context, courseID string) error {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM courses WHERE tenantId = $1 AND id = $2)`, domain.TenantID(ctx), courseID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check course: %w", err)
+	}
+	if !exists {
+		return domain.ErrCourseNotFound
+	}
+	return domain.ErrCourseFull
+}
+
+// GetByID retrieves an enrollment by its ID
+func (r *EnrollmentRepository) GetByID(ctx context.Context, id string) (*domain.CourseEnrollment, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + enrollmentColumns + `
+		FROM course_enrollments e
+		INNER JOIN courses c ON e.courseId = c.id
+		INNER JOIN users u ON e.userId = u.id
+		WHERE e.tenantId = $1 AND e.id = $2
+	`
+
+	enrollment, err := scanEnrollment(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("enrollments.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrEnrollmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+
+	return enrollment, nil
+}
+
+// GetByUserID retrieves the enrollments of a user, newest first
+func (r *EnrollmentRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.CourseEnrollment, error) {
+	return r.list(ctx, "enrollments.GetByUserID", "e.userId = $2", "e.enrolledAt DESC", userID)
+}
+
+// GetByCourseID retrieves the enrollments of a course, oldest first
+func (r *EnrollmentRepository) GetByCourseID(ctx context.Context, courseID string) ([]*domain.CourseEnrollment, error) {
+	return r.list(ctx, "enrollments.GetByCourseID", "e.courseId = $2", "e.enrolledAt", courseID)
+}
+
+// Complete marks an active enrollment as completed with feedback; the seat
+// stays taken
+func (r *EnrollmentRepository) Complete(ctx context.Context, id string, feedback domain.Feedback) error {
+	start := time.Now()
+
+	feedbackJSON, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+
+	query := `
+		UPDATE course_enrollments
+		SET status = 'completed',
+		    feedback = $3,
+		    completedAt = CURRENT_TIMESTAMP
+		WHERE tenantId = $1 AND id = $2 AND status = 'enrolled'
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id, feedbackJSON)
+
+	metrics.RecordDbQuery("enrollments.Complete", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to complete enrollment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrEnrollmentNotActive
+	}
+
+	metrics.LearningProcessesActive.Dec()
+	metrics.LearningProcessesCompleted.Inc()
+	metrics.CompletionRatings.WithLabelValues("course").Observe(float64(feedback.Rating))
+
+	return nil
+}
+
+// Cancel marks an active enrollment as cancelled and frees its seat
+func (r *EnrollmentRepository) Cancel(ctx context.Context, id string) error {
+	start := time.Now()
+
+	query := `
+		WITH cancelled AS (
+			UPDATE course_enrollments
+			SET status = 'cancelled'
+			WHERE tenantId = $1 AND id = $2 AND status = 'enrolled'
+			RETURNING courseId
+		)
+		UPDATE courses
+		SET seatsTaken = seatsTaken - 1
+		WHERE id IN (SELECT courseId FROM cancelled)
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("enrollments.Cancel", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to cancel enrollment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrEnrollmentNotActive
+	}
+
+	metrics.LearningProcessesActive.Dec()
+
+	return nil
+}
+
+func (r *EnrollmentRepository) list(ctx context.Context, op, where, orderBy string, arg any) ([]*domain.CourseEnrollment, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + enrollmentColumns + `
+		FROM course_enrollments e
+		INNER JOIN courses c ON e.courseId = c.id
+		INNER JOIN users u ON e.userId = u.id
+		WHERE e.tenantId = $1 AND ` + where + `
+		ORDER BY ` + orderBy + `
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), arg)
+
+	metrics.RecordDbQuery(op, time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	enrollments := make([]*domain.CourseEnrollment, 0)
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating enrollments: %w", err)
+	}
+
+	return enrollments, nil
+}
+
+func scanEnrollment(row pgx.Row) (*domain.CourseEnrollment, error) {
+	var e domain.CourseEnrollment
+	var feedbackJSON []byte
+	err := row.Scan(
+		&e.ID, &e.CourseID, &e.UserID, &e.RequestID, &e.Status, &feedbackJSON,
+		&e.EnrolledAt, &e.CompletedAt, &e.UpdatedAt,
+		&e.CourseTitle, &e.UserName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if feedbackJSON != nil {
+		var feedback domain.Feedback
+		if err := json.Unmarshal(feedbackJSON, &feedback); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal feedback: %w", err)
+		}
+		e.Feedback = &feedback
+	}
+	return &e, nil
+}
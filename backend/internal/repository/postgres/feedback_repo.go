@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FeedbackRepository stores questionnaire answers on learnings
+type FeedbackRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFeedbackRepository(pool *pgxpool.Pool) *FeedbackRepository {
+	return &FeedbackRepository{pool: pool}
+}
+
+// feedbackColumns selects a response joined with its author
+const feedbackColumns = `
+	f.id, f.learningId, f.questionnaireId, f.audience, f.authorId, f.mentorId,
+	f.answers, f.comment, f.anonymous, f.createdAt,
+	COALESCE(u.name, '') AS authorName
+`
+
+// Create inserts a response; a learning gets at most one per audience
+func (r *FeedbackRepository) Create(ctx context.Context, response *domain.FeedbackResponse) error {
+	start := time.Now()
+
+	answersJSON, err := json.Marshal(response.Answers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal answers: %w", err)
+	}
+
+	query := `
+		INSERT INTO feedback_responses (tenantId, learningId, questionnaireId, audience, authorId, mentorId, answers, comment, anonymous)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, createdAt
+	`
+
+	err = conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), response.LearningID, response.QuestionnaireID, response.Audience, response.AuthorID,
+		response.MentorID, answersJSON, response.Comment, response.Anonymous,
+	).Scan(&response.ID, &response.CreatedAt)
+
+	metrics.RecordDbQuery("feedback.Create", time.Since(start), err)
+
+	if err != nil {
+		switch {
+		case isViolation(err, uniqueViolation):
+			return domain.ErrFeedbackExists
+		case isViolation(err, foreignKeyViolation):
+			return domain.ErrLearningNotFound
+		}
+		return fmt.Errorf("failed to create feedback: %w", err)
+	}
+
+	for criterion, score := range response.Answers {
+		metrics.FeedbackScores.WithLabelValues(response.MentorID, string(response.Audience), criterion).Observe(float64(score))
+	}
+
+	return nil
+}
+
+// GetByLearningID retrieves the responses on a learning, oldest first
+func (r *FeedbackRepository) GetByLearningID(ctx context.Context, learningID string) ([]*domain.FeedbackResponse, error) {
+	return r.list(ctx, "feedback.GetByLearningID", "f.learningId = $2 ORDER BY f.createdAt, f.id", learningID)
+}
+
+// GetByMentorID retrieves the responses on the mentor's learnings, newest
+// first
+func (r *FeedbackRepository) GetByMentorID(ctx context.Context, mentorID string) ([]*domain.FeedbackResponse, error) {
+	return r.list(ctx, "feedback.GetByMentorID", "f.mentorId = $2 ORDER BY f.createdAt DESC, f.id", mentorID)
+}
+
+func (r *FeedbackRepository) list(ctx context.Context, op, where string, arg any) ([]*domain.FeedbackResponse, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + feedbackColumns + `
+		FROM feedback_responses f
+		LEFT JOIN users u ON f.authorId = u.id
+		WHERE f.tenantId = $1 AND ` + where
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), arg)
+
+	metrics.RecordDbQuery(op, time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	defer rows.Close()
+
+	responses := make([]*domain.FeedbackResponse, 0)
+	for rows.Next() {
+		response, err := scanFeedbackResponse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		responses = append(responses, response)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback: %w", err)
+	}
+
+	return responses, nil
+}
+
+// scanFeedbackResponse reads a row selected with feedbackColumns
+func scanFeedbackResponse(row pgx.Row) (*domain.FeedbackResponse, error) {
+	var f domain.FeedbackResponse
+	var answersJSON []byte
+	err := row.Scan(
+		&f.ID, &f.LearningID, &f.QuestionnaireID, &f.Audience, &f.AuthorID, &f.MentorID,
+		&answersJSON, &f.Comment, &f.Anonymous, &f.CreatedAt,
+		&f.AuthorName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(answersJSON, &f.Answers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal answers: %w", err)
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/health"
+)
+
+// NewPingCheck reports whether the database accepts connections
+func NewPingCheck(pool *pgxpool.Pool) health.Checker {
+	return health.CheckFunc{
+		CheckName: "database",
+		Fn: func(ctx context.Context) error {
+			return pool.Ping(ctx)
+		},
+	}
+}
+
+// NewSchemaCheck reports whether the applied golang-migrate schema version
+// matches the version the binary was built against and is not dirty
+func NewSchemaCheck(pool *pgxpool.Pool, expectedVersion uint) health.Checker {
+	return health.CheckFunc{
+		CheckName: "migrations",
+		Fn: func(ctx context.Context) error {
+			var version int64
+			var dirty bool
+
+			err := pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return fmt.Errorf("no migrations applied, expected version %d", expectedVersion)
+				}
+				return fmt.Errorf("failed to read schema version: %w", err)
+			}
+
+			if dirty {
+				return fmt.Errorf("schema version %d is dirty", version)
+			}
+			if uint(version) != expectedVersion {
+				return fmt.Errorf("schema version %d, expected %d", version, expectedVersion)
+			}
+
+			return nil
+		},
+	}
+}
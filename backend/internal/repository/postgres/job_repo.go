@@ -0,0 +1,495 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobRepository is a job queue on a Postgres table. Workers claim jobs with
+// SELECT ... FOR UPDATE SKIP LOCKED, so app instances polling the same table
+// never run a job twice at once and do not wait on each other's locks.
+// Workers serve every tenant: Claim, Complete, Fail, ClaimSchedule and
+// LatestByKind ignore the tenant of the context, the admin methods do not.
+type JobRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewJobRepository(pool *pgxpool.Pool) *JobRepository {
+	return &JobRepository{pool: pool}
+}
+
+const jobColumns = `
+	id, tenantId, kind, payload, COALESCE(uniqueKey, ''), status, attempts, maxAttempts, runAt,
+	COALESCE(lockedBy, ''), lockedUntil, lastError, createdAt, startedAt, finishedAt
+`
+
+// Enqueue inserts a pending job. A clash on the unique key is skipped rather
+// than raised, so that it does not abort the transaction it runs in.
+func (r *JobRepository) Enqueue(ctx context.Context, job *domain.Job) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO jobs (tenantId, kind, payload, uniqueKey, maxAttempts, runAt)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		ON CONFLICT (tenantId, uniqueKey)
+			WHERE uniqueKey IS NOT NULL AND status IN ('pending', 'running')
+			DO NOTHING
+		RETURNING id, status, attempts, createdAt
+	`
+
+	var payload []byte
+	if len(job.Payload) > 0 {
+		payload = job.Payload
+	}
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), job.Kind, payload, job.UniqueKey, job.MaxAttempts, job.RunAt,
+	).Scan(&job.ID, &job.Status, &job.Attempts, &job.CreatedAt)
+
+	metrics.RecordDbQuery("jobs.Enqueue", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrJobExists
+		}
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	metrics.JobsEnqueued.WithLabelValues(job.Kind).Inc()
+
+	job.TenantID = domain.TenantID(ctx)
+	job.LockedBy = ""
+	job.LockedUntil = nil
+	job.LastError = ""
+	job.StartedAt = nil
+	job.FinishedAt = nil
+	return nil
+}
+
+// GetByID retrieves a job by its ID
+func (r *JobRepository) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	start := time.Now()
+
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE tenantId = $1 AND id = $2`
+
+	job, err := scanJob(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("jobs.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
+
+// List retrieves jobs matching the filter, newest first
+func (r *JobRepository) List(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	start := time.Now()
+
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE tenantId = $1`
+	args := []interface{}{domain.TenantID(ctx)}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		query += fmt.Sprintf(" AND kind = $%d", len(args))
+	}
+	query += " ORDER BY createdAt DESC, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+
+	metrics.RecordDbQuery("jobs.List", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]*domain.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// LatestByKind retrieves the newest job of the kind of every tenant in one
+// query, so readiness probes cost the same however many tenants there are
+func (r *JobRepository) LatestByKind(ctx context.Context, kind string) ([]*domain.Job, error) {
+	start := time.Now()
+
+	query := `
+		SELECT DISTINCT ON (tenantId) ` + jobColumns + `
+		FROM jobs
+		WHERE kind = $1
+		ORDER BY tenantId, createdAt DESC, id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, kind)
+
+	metrics.RecordDbQuery("jobs.LatestByKind", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]*domain.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Claim locks the next due job for the worker. Rows locked by another
+// worker's claim are skipped rather than waited for.
+func (r *JobRepository) Claim(ctx context.Context, workerID string, kinds []string, now time.Time, lease time.Duration) (*domain.Job, error) {
+	if err := r.failExpired(ctx, kinds, now); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, lockedBy = $1, lockedUntil = $3,
+			startedAt = $2, lastError = CASE WHEN status = 'running' THEN 'lease expired' ELSE lastError END
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE kind = ANY($4)
+				AND ((status = 'pending' AND runAt <= $2)
+					OR (status = 'running' AND lockedUntil <= $2 AND attempts < maxAttempts))
+			ORDER BY runAt, createdAt
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(conn(ctx, r.pool).QueryRow(ctx, query, workerID, now, now.Add(lease), kinds))
+
+	metrics.RecordDbQuery("jobs.Claim", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return job, nil
+}
+
+// failExpired fails the running jobs whose lease expired on their last
+// attempt, so a job that keeps killing its worker is not claimed forever
+func (r *JobRepository) failExpired(ctx context.Context, kinds []string, now time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = 'failed', finishedAt = $2, lockedBy = NULL, lockedUntil = NULL, lastError = 'lease expired'
+		WHERE kind = ANY($1) AND status = 'running' AND lockedUntil <= $2 AND attempts >= maxAttempts
+		RETURNING kind, startedAt
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, kinds, now)
+	if err != nil {
+		metrics.RecordDbQuery("jobs.FailExpired", time.Since(start), err)
+		return fmt.Errorf("failed to fail expired jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var startedAt time.Time
+		if err := rows.Scan(&kind, &startedAt); err != nil {
+			return fmt.Errorf("failed to scan expired job: %w", err)
+		}
+		metrics.RecordJob(kind, "failed", now.Sub(startedAt))
+	}
+	err = rows.Err()
+
+	metrics.RecordDbQuery("jobs.FailExpired", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to fail expired jobs: %w", err)
+	}
+	return nil
+}
+
+// Complete marks a claimed job as succeeded
+func (r *JobRepository) Complete(ctx context.Context, id, workerID string, now time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = 'succeeded', finishedAt = $3, lockedBy = NULL, lockedUntil = NULL, lastError = ''
+		WHERE id = $1 AND lockedBy = $2 AND status = 'running'
+		RETURNING kind, startedAt
+	`
+
+	var kind string
+	var startedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, id, workerID, now).Scan(&kind, &startedAt)
+
+	metrics.RecordDbQuery("jobs.Complete", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrJobLeaseLost
+		}
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+
+	metrics.RecordJob(kind, "succeeded", now.Sub(startedAt))
+	return nil
+}
+
+// Fail records a failed attempt and schedules the retry, if any
+func (r *JobRepository) Fail(ctx context.Context, id, workerID, lastError string, now time.Time, retryAt *time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			runAt = COALESCE($4, runAt),
+			finishedAt = CASE WHEN $4::timestamptz IS NULL THEN $5::timestamptz END,
+			lastError = $3, lockedBy = NULL, lockedUntil = NULL
+		WHERE id = $1 AND lockedBy = $2 AND status = 'running'
+		RETURNING kind, startedAt
+	`
+
+	var kind string
+	var startedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, id, workerID, lastError, retryAt, now).Scan(&kind, &startedAt)
+
+	metrics.RecordDbQuery("jobs.Fail", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrJobLeaseLost
+		}
+		return fmt.Errorf("failed to record job failure: %w", err)
+	}
+
+	outcome := "failed"
+	if retryAt != nil {
+		outcome = "retried"
+	}
+	metrics.RecordJob(kind, outcome, now.Sub(startedAt))
+	return nil
+}
+
+// Retry makes a failed or cancelled job pending again
+func (r *JobRepository) Retry(ctx context.Context, id string, now time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = 'pending', attempts = 0, runAt = $3, finishedAt = NULL
+		WHERE tenantId = $1 AND id = $2 AND status IN ('failed', 'cancelled')
+		RETURNING id
+	`
+
+	var updated string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, now).Scan(&updated)
+
+	metrics.RecordDbQuery("jobs.Retry", time.Since(start), err)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			if _, getErr := r.GetByID(ctx, id); getErr != nil {
+				return getErr
+			}
+			return domain.ErrJobNotRetryable
+		case isViolation(err, uniqueViolation):
+			return domain.ErrJobExists
+		}
+		return fmt.Errorf("failed to retry job: %w", err)
+	}
+
+	return nil
+}
+
+// Cancel stops a pending job from running
+func (r *JobRepository) Cancel(ctx context.Context, id string, now time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = 'cancelled', finishedAt = $3
+		WHERE tenantId = $1 AND id = $2 AND status = 'pending'
+		RETURNING id
+	`
+
+	var updated string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, now).Scan(&updated)
+
+	metrics.RecordDbQuery("jobs.Cancel", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.GetByID(ctx, id); getErr != nil {
+				return getErr
+			}
+			return domain.ErrJobNotCancellable
+		}
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFinished removes jobs finished before the time
+func (r *JobRepository) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	start := time.Now()
+
+	query := `DELETE FROM jobs WHERE tenantId = $1 AND status IN ('succeeded', 'failed', 'cancelled') AND finishedAt < $2`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), before)
+
+	metrics.RecordDbQuery("jobs.DeleteFinished", time.Since(start), err)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ClaimSchedule advances a due schedule to next. The row lock taken by the
+// UPDATE makes concurrent instances see the advanced fire time and back off.
+func (r *JobRepository) ClaimSchedule(ctx context.Context, name string, now, next time.Time) (bool, error) {
+	start := time.Now()
+
+	// Rows inserted by the CTE are invisible to the UPDATE, so a schedule
+	// seen for the first time is not due
+	query := `
+		WITH first_seen AS (
+			INSERT INTO job_schedules (name, nextRunAt)
+			VALUES ($1, $3)
+			ON CONFLICT (name) DO NOTHING
+		)
+		UPDATE job_schedules
+		SET nextRunAt = $3, lastRunAt = $2
+		WHERE name = $1 AND nextRunAt <= $2
+		RETURNING name
+	`
+
+	var claimed string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, name, now, next).Scan(&claimed)
+
+	metrics.RecordDbQuery("jobs.ClaimSchedule", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim schedule: %w", err)
+	}
+
+	return true, nil
+}
+
+// CollectJobMetrics periodically exports the number of jobs per status and
+// how long the oldest due job has been waiting
+func CollectJobMetrics(ctx context.Context, pool *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			collectJobMetrics(ctx, pool)
+		}
+	}
+}
+
+func collectJobMetrics(ctx context.Context, pool *pgxpool.Pool) {
+	rows, err := pool.Query(ctx, `SELECT status, COUNT(*) FROM jobs GROUP BY status`)
+	if err != nil {
+		return
+	}
+	counts := make(map[domain.JobStatus]int)
+	for rows.Next() {
+		var status domain.JobStatus
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			rows.Close()
+			return
+		}
+		counts[status] = n
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return
+	}
+	for _, status := range []domain.JobStatus{domain.JobPending, domain.JobRunning, domain.JobSucceeded, domain.JobFailed, domain.JobCancelled} {
+		metrics.Jobs.WithLabelValues(string(status)).Set(float64(counts[status]))
+	}
+
+	var lag float64
+	err = pool.QueryRow(ctx, `
+		SELECT COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - MIN(runAt)), 0)
+		FROM jobs WHERE status = 'pending' AND runAt <= CURRENT_TIMESTAMP
+	`).Scan(&lag)
+	if err == nil {
+		metrics.JobQueueLag.Set(lag)
+	}
+}
+
+// scanJob reads a row selected with jobColumns
+func scanJob(row pgx.Row) (*domain.Job, error) {
+	var j domain.Job
+	var payload []byte
+	err := row.Scan(
+		&j.ID, &j.TenantID, &j.Kind, &payload, &j.UniqueKey, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.LockedBy, &j.LockedUntil, &j.LastError, &j.CreatedAt, &j.StartedAt, &j.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > 0 {
+		j.Payload = payload
+	}
+	return &j, nil
+}
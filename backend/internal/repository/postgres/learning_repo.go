@@ -33,16 +33,16 @@ func (r *LearningRepository) Create(ctx context.Context, learning *domain.Learni
 
 	query := `
 		INSERT INTO learning_processes 
-		(requestId, userId, mentorId, status, startDate, plan, notes)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, startDate, createdAt, updatedAt
+		(tenantId, requestId, userId, mentorId, status, startDate, plan, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, startDate, createdAt, updatedAt, planUpdatedAt
 	`
 
-	err = r.pool.QueryRow(
+	err = conn(ctx, r.pool).QueryRow(
 		ctx, query,
-		learning.RequestID, learning.UserID, learning.MentorID,
+		domain.TenantID(ctx), learning.RequestID, learning.UserID, learning.MentorID,
 		learning.Status, learning.StartDate, planJSON, learning.Notes,
-	).Scan(&learning.ID, &learning.StartDate, &learning.CreatedAt, &learning.UpdatedAt)
+	).Scan(&learning.ID, &learning.StartDate, &learning.CreatedAt, &learning.UpdatedAt, &learning.PlanUpdatedAt)
 
 	metrics.RecordDbQuery("learning.Create", time.Since(start), err)
 
@@ -66,7 +66,7 @@ func (r *LearningRepository) GetByID(ctx context.Context, id string) (*domain.Le
 			lp.id, lp.requestId, lp.userId, lp.mentorId,
 			lp.status, lp.startDate, lp.endDate,
 			lp.plan, lp.feedback, lp.notes,
-			lp.createdAt, lp.updatedAt,
+			lp.createdAt, lp.updatedAt, lp.planUpdatedAt,
 			r.topic AS requestTopic,
 			r.description AS requestDescription,
 			u.name AS userName,
@@ -78,18 +78,18 @@ func (r *LearningRepository) GetByID(ctx context.Context, id string) (*domain.Le
 		INNER JOIN training_requests r ON lp.requestId = r.id
 		INNER JOIN users u ON lp.userId = u.id
 		INNER JOIN mentors m ON lp.mentorId = m.id
-		WHERE lp.id = $1
+		WHERE lp.tenantId = $1 AND lp.id = $2
 	`
 
 	var learning domain.LearningProcess
 	var planJSON []byte
 	var feedbackJSON []byte
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id).Scan(
 		&learning.ID, &learning.RequestID, &learning.UserID, &learning.MentorID,
 		&learning.Status, &learning.StartDate, &learning.EndDate,
 		&planJSON, &feedbackJSON, &learning.Notes,
-		&learning.CreatedAt, &learning.UpdatedAt,
+		&learning.CreatedAt, &learning.UpdatedAt, &learning.PlanUpdatedAt,
 		&learning.RequestTopic, &learning.RequestDescription,
 		&learning.UserName,
 		&learning.MentorName, &learning.MentorTelegram,
@@ -131,7 +131,7 @@ func (r *LearningRepository) GetByUserID(ctx context.Context, userID string) ([]
 			lp.id, lp.requestId, lp.userId, lp.mentorId,
 			lp.status, lp.startDate, lp.endDate,
 			lp.plan, lp.feedback, lp.notes,
-			lp.createdAt, lp.updatedAt,
+			lp.createdAt, lp.updatedAt, lp.planUpdatedAt,
 			r.topic AS requestTopic,
 			r.description AS requestDescription,
 			u.name AS userName,
@@ -143,11 +143,11 @@ func (r *LearningRepository) GetByUserID(ctx context.Context, userID string) ([]
 		INNER JOIN training_requests r ON lp.requestId = r.id
 		INNER JOIN users u ON lp.userId = u.id
 		INNER JOIN mentors m ON lp.mentorId = m.id
-		WHERE lp.userId = $1
+		WHERE lp.tenantId = $1 AND lp.userId = $2
 		ORDER BY lp.createdAt DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), userID)
 
 	metrics.RecordDbQuery("learning.GetByUserID", time.Since(start), err)
 
@@ -168,7 +168,7 @@ func (r *LearningRepository) GetByMentorID(ctx context.Context, mentorID string)
 			lp.id, lp.requestId, lp.userId, lp.mentorId,
 			lp.status, lp.startDate, lp.endDate,
 			lp.plan, lp.feedback, lp.notes,
-			lp.createdAt, lp.updatedAt,
+			lp.createdAt, lp.updatedAt, lp.planUpdatedAt,
 			r.topic AS requestTopic,
 			r.description AS requestDescription,
 			u.name AS userName,
@@ -180,11 +180,11 @@ func (r *LearningRepository) GetByMentorID(ctx context.Context, mentorID string)
 		INNER JOIN training_requests r ON lp.requestId = r.id
 		INNER JOIN users u ON lp.userId = u.id
 		INNER JOIN mentors m ON lp.mentorId = m.id
-		WHERE lp.mentorId = $1
+		WHERE lp.tenantId = $1 AND lp.mentorId = $2
 		ORDER BY lp.createdAt DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, mentorID)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), mentorID)
 
 	metrics.RecordDbQuery("learning.GetByMentorID", time.Since(start), err)
 
@@ -205,7 +205,7 @@ func (r *LearningRepository) GetAll(ctx context.Context) ([]*domain.LearningProc
 			lp.id, lp.requestId, lp.userId, lp.mentorId,
 			lp.status, lp.startDate, lp.endDate,
 			lp.plan, lp.feedback, lp.notes,
-			lp.createdAt, lp.updatedAt,
+			lp.createdAt, lp.updatedAt, lp.planUpdatedAt,
 			r.topic AS requestTopic,
 			r.description AS requestDescription,
 			u.name AS userName,
@@ -217,10 +217,11 @@ func (r *LearningRepository) GetAll(ctx context.Context) ([]*domain.LearningProc
 		INNER JOIN training_requests r ON lp.requestId = r.id
 		INNER JOIN users u ON lp.userId = u.id
 		INNER JOIN mentors m ON lp.mentorId = m.id
+		WHERE lp.tenantId = $1
 		ORDER BY lp.createdAt DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
 
 	metrics.RecordDbQuery("learning.GetAll", time.Since(start), err)
 
@@ -232,25 +233,25 @@ func (r *LearningRepository) GetAll(ctx context.Context) ([]*domain.LearningProc
 	return r.scanLearningProcesses(rows)
 }
 
-// UpdateMentor updates the mentor for a learning process
-func (r *LearningRepository) UpdateMentor(ctx context.Context, learningID, mentorID string) error {
+// UpdateMentor moves a learning process from one mentor to another
+func (r *LearningRepository) UpdateMentor(ctx context.Context, learningID, from, to string) error {
 	start := time.Now()
 
 	query := `
 		UPDATE learning_processes
-		SET mentorId = $2
-		WHERE id = $1
+		SET mentorId = $4
+		WHERE tenantId = $1 AND id = $2 AND mentorId = $3
 		RETURNING updatedAt
 	`
 
 	var updatedAt time.Time
-	err := r.pool.QueryRow(ctx, query, learningID, mentorID).Scan(&updatedAt)
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), learningID, from, to).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("learning.UpdateMentor", time.Since(start), err)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.ErrLearningNotFound
+			return r.missingOr(ctx, learningID, domain.ErrLearningChanged)
 		}
 		return fmt.Errorf("failed to update mentor: %w", err)
 	}
@@ -269,13 +270,13 @@ func (r *LearningRepository) UpdatePlan(ctx context.Context, id string, plan []d
 
 	query := `
 		UPDATE learning_processes
-		SET plan = $2
-		WHERE id = $1
+		SET plan = $3
+		WHERE tenantId = $1 AND id = $2
 		RETURNING updatedAt
 	`
 
 	var updatedAt time.Time
-	err = r.pool.QueryRow(ctx, query, id, planJSON).Scan(&updatedAt)
+	err = conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, planJSON).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("learning.UpdatePlan", time.Since(start), err)
 
@@ -295,8 +296,8 @@ func (r *LearningRepository) UpdateNotes(ctx context.Context, id string, notes s
 
 	query := `
 		UPDATE learning_processes
-		SET notes = $2
-		WHERE id = $1
+		SET notes = $3
+		WHERE tenantId = $1 AND id = $2
 		RETURNING updatedAt
 	`
 
@@ -308,7 +309,7 @@ func (r *LearningRepository) UpdateNotes(ctx context.Context, id string, notes s
 		notesPtr = &notes
 	}
 
-	err := r.pool.QueryRow(ctx, query, id, notesPtr).Scan(&updatedAt)
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, notesPtr).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("learning.UpdateNotes", time.Since(start), err)
 
@@ -322,8 +323,9 @@ func (r *LearningRepository) UpdateNotes(ctx context.Context, id string, notes s
 	return nil
 }
 
-// Update updates full learning process info (admin only)
-func (r *LearningRepository) Update(ctx context.Context, id string, learning *domain.LearningProcess) error {
+// Update updates full learning process info (admin only) if it still has
+// the status from
+func (r *LearningRepository) Update(ctx context.Context, id string, from domain.LearningStatus, learning *domain.LearningProcess) error {
 	start := time.Now()
 
 	planJSON, err := json.Marshal(learning.Plan)
@@ -341,23 +343,23 @@ func (r *LearningRepository) Update(ctx context.Context, id string, learning *do
 
 	query := `
 		UPDATE learning_processes
-		SET status = $2,
-		    plan = $3,
-		    feedback = $4,
-		    notes = $5,
-		    endDate = $6
-		WHERE id = $1
+		SET status = $3,
+		    plan = $4,
+		    feedback = $5,
+		    notes = $6,
+		    endDate = $7
+		WHERE tenantId = $1 AND id = $2 AND status = $8
 		RETURNING updatedAt
 	`
 
 	var updatedAt time.Time
-	err = r.pool.QueryRow(ctx, query, id, learning.Status, planJSON, feedbackJSON, learning.Notes, learning.EndDate).Scan(&updatedAt)
+	err = conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, learning.Status, planJSON, feedbackJSON, learning.Notes, learning.EndDate, from).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("learning.Update", time.Since(start), err)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.ErrLearningNotFound
+			return r.missingOr(ctx, id, domain.ErrLearningChanged)
 		}
 		return fmt.Errorf("failed to update learning: %w", err)
 	}
@@ -365,7 +367,7 @@ func (r *LearningRepository) Update(ctx context.Context, id string, learning *do
 	return nil
 }
 
-// Complete marks learning as completed with feedback
+// Complete marks an active learning as completed with feedback
 func (r *LearningRepository) Complete(ctx context.Context, id string, feedback domain.Feedback) error {
 	start := time.Now()
 
@@ -377,27 +379,26 @@ func (r *LearningRepository) Complete(ctx context.Context, id string, feedback d
 	query := `
 		UPDATE learning_processes
 		SET status = 'completed',
-		    feedback = $2,
+		    feedback = $3,
 		    endDate = CURRENT_TIMESTAMP
-		WHERE id = $1
+		WHERE tenantId = $1 AND id = $2 AND status = 'active'
 		RETURNING endDate, updatedAt
 	`
 
 	var endDate, updatedAt time.Time
-	err = r.pool.QueryRow(ctx, query, id, feedbackJSON).Scan(&endDate, &updatedAt)
+	err = conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, feedbackJSON).Scan(&endDate, &updatedAt)
 
 	metrics.RecordDbQuery("learning.Complete", time.Since(start), err)
 
 	if err == nil {
 		metrics.LearningProcessesActive.Dec()
 		metrics.LearningProcessesCompleted.Inc()
-		metrics.FeedbackRatingSum.Add(float64(feedback.Rating))
-		metrics.FeedbackRatingCount.Inc()
+		metrics.CompletionRatings.WithLabelValues("learning").Observe(float64(feedback.Rating))
 	}
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.ErrLearningNotFound
+			return r.missingOr(ctx, id, domain.ErrLearningNotActive)
 		}
 		return fmt.Errorf("failed to complete learning: %w", err)
 	}
@@ -405,6 +406,23 @@ func (r *LearningRepository) Complete(ctx context.Context, id string, feedback d
 	return nil
 }
 
+// missingOr tells why a conditional update matched no row: the learning is
+// gone, or it no longer is in the expected state and err applies
+func (r *LearningRepository) missingOr(ctx context.Context, id string, err error) error {
+	var exists bool
+	checkErr := conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM learning_processes WHERE tenantId = $1 AND id = $2)`,
+		domain.TenantID(ctx), id,
+	).Scan(&exists)
+	if checkErr != nil {
+		return fmt.Errorf("failed to check learning process: %w", checkErr)
+	}
+	if !exists {
+		return domain.ErrLearningNotFound
+	}
+	return err
+}
+
 // scanLearningProcesses is a helper to scan multiple rows
 func (r *LearningRepository) scanLearningProcesses(rows pgx.Rows) ([]*domain.LearningProcess, error) {
 	var learnings []*domain.LearningProcess
@@ -418,7 +436,7 @@ func (r *LearningRepository) scanLearningProcesses(rows pgx.Rows) ([]*domain.Lea
 			&learning.ID, &learning.RequestID, &learning.UserID, &learning.MentorID,
 			&learning.Status, &learning.StartDate, &learning.EndDate,
 			&planJSON, &feedbackJSON, &learning.Notes,
-			&learning.CreatedAt, &learning.UpdatedAt,
+			&learning.CreatedAt, &learning.UpdatedAt, &learning.PlanUpdatedAt,
 			&learning.RequestTopic, &learning.RequestDescription,
 			&learning.UserName,
 			&learning.MentorName, &learning.MentorTelegram,
@@ -26,15 +26,15 @@ func (r *MentorRepository) Create(ctx context.Context, mentor *domain.Mentor) er
 	start := time.Now()
 
 	query := `
-		INSERT INTO mentors (name, jobTitle, experience, workload, email, telegram)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO mentors (tenantId, name, jobTitle, experience, workload, capacity, email, telegram, skills)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, createdAt, updatedAt
 	`
 
-	err := r.pool.QueryRow(
+	err := conn(ctx, r.pool).QueryRow(
 		ctx, query,
-		mentor.Name, mentor.JobTitle, mentor.Experience, mentor.Workload,
-		mentor.Email, mentor.Telegram,
+		domain.TenantID(ctx), mentor.Name, mentor.JobTitle, mentor.Experience, mentor.Workload, mentor.Capacity,
+		mentor.Email, mentor.Telegram, domain.CleanSkills(mentor.Skills),
 	).Scan(&mentor.ID, &mentor.CreatedAt, &mentor.UpdatedAt)
 
 	metrics.RecordDbQuery("mentors.Create", time.Since(start), err)
@@ -51,15 +51,15 @@ func (r *MentorRepository) GetByID(ctx context.Context, id string) (*domain.Ment
 	start := time.Now()
 
 	query := `
-		SELECT id, name, jobTitle, experience, workload, email, telegram, createdAt, updatedAt
+		SELECT id, name, jobTitle, experience, workload, capacity, email, telegram, skills, deactivatedAt, createdAt, updatedAt
 		FROM mentors
-		WHERE id = $1
+		WHERE tenantId = $1 AND id = $2
 	`
 
 	var mentor domain.Mentor
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id).Scan(
 		&mentor.ID, &mentor.Name, &mentor.JobTitle, &mentor.Experience,
-		&mentor.Workload, &mentor.Email, &mentor.Telegram,
+		&mentor.Workload, &mentor.Capacity, &mentor.Email, &mentor.Telegram, &mentor.Skills, &mentor.DeactivatedAt,
 		&mentor.CreatedAt, &mentor.UpdatedAt,
 	)
 
@@ -75,30 +75,30 @@ func (r *MentorRepository) GetByID(ctx context.Context, id string) (*domain.Ment
 	return &mentor, nil
 }
 
-// GetAll retrieves all mentors with optional workload filter
-func (r *MentorRepository) GetAll(ctx context.Context, maxWorkload *int) ([]*domain.Mentor, error) {
+// GetAll retrieves mentors matching the filter, least loaded first
+func (r *MentorRepository) GetAll(ctx context.Context, filter domain.MentorFilter) ([]*domain.Mentor, error) {
 	start := time.Now()
 
-	var query string
-	var args []interface{}
+	query := `
+		SELECT id, name, jobTitle, experience, workload, capacity, email, telegram, skills, deactivatedAt, createdAt, updatedAt
+		FROM mentors
+		WHERE tenantId = $1
+	`
+	args := []interface{}{domain.TenantID(ctx)}
 
-	if maxWorkload != nil {
-		query = `
-			SELECT id, name, jobTitle, experience, workload, email, telegram, createdAt, updatedAt
-			FROM mentors
-			WHERE workload <= $1
-			ORDER BY workload ASC, name ASC
-		`
-		args = append(args, *maxWorkload)
-	} else {
-		query = `
-			SELECT id, name, jobTitle, experience, workload, email, telegram, createdAt, updatedAt
-			FROM mentors
-			ORDER BY workload ASC, name ASC
-		`
+	if filter.MaxWorkload != nil {
+		args = append(args, *filter.MaxWorkload)
+		query += fmt.Sprintf(" AND workload <= $%d", len(args))
+	}
+	if filter.ActiveOnly {
+		query += " AND deactivatedAt IS NULL"
+	}
+	if filter.HasCapacity {
+		query += " AND workload < capacity"
 	}
+	query += " ORDER BY workload ASC, name ASC"
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
 
 	metrics.RecordDbQuery("mentors.GetAll", time.Since(start), err)
 
@@ -112,7 +112,7 @@ func (r *MentorRepository) GetAll(ctx context.Context, maxWorkload *int) ([]*dom
 		var mentor domain.Mentor
 		err := rows.Scan(
 			&mentor.ID, &mentor.Name, &mentor.JobTitle, &mentor.Experience,
-			&mentor.Workload, &mentor.Email, &mentor.Telegram,
+			&mentor.Workload, &mentor.Capacity, &mentor.Email, &mentor.Telegram, &mentor.Skills, &mentor.DeactivatedAt,
 			&mentor.CreatedAt, &mentor.UpdatedAt,
 		)
 		if err != nil {
@@ -134,16 +134,16 @@ func (r *MentorRepository) Update(ctx context.Context, mentor *domain.Mentor) er
 
 	query := `
 		UPDATE mentors
-		SET name = $2, jobTitle = $3, experience = $4, workload = $5, email = $6, telegram = $7
-		WHERE id = $1
+		SET name = $3, jobTitle = $4, experience = $5, workload = $6, capacity = $7, email = $8, telegram = $9, skills = $10
+		WHERE tenantId = $1 AND id = $2
 		RETURNING updatedAt
 	`
 
 	var updatedAt time.Time
-	err := r.pool.QueryRow(
+	err := conn(ctx, r.pool).QueryRow(
 		ctx, query,
-		mentor.ID, mentor.Name, mentor.JobTitle, mentor.Experience,
-		mentor.Workload, mentor.Email, mentor.Telegram,
+		domain.TenantID(ctx), mentor.ID, mentor.Name, mentor.JobTitle, mentor.Experience,
+		mentor.Workload, mentor.Capacity, mentor.Email, mentor.Telegram, domain.CleanSkills(mentor.Skills),
 	).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("mentors.Update", time.Since(start), err)
@@ -165,13 +165,13 @@ func (r *MentorRepository) UpdateWorkload(ctx context.Context, id string, worklo
 
 	query := `
 		UPDATE mentors
-		SET workload = $2
-		WHERE id = $1
+		SET workload = $3
+		WHERE tenantId = $1 AND id = $2
 		RETURNING updatedAt
 	`
 
 	var updatedAt time.Time
-	err := r.pool.QueryRow(ctx, query, id, workload).Scan(&updatedAt)
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, workload).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("mentors.UpdateWorkload", time.Since(start), err)
 
@@ -185,13 +185,109 @@ func (r *MentorRepository) UpdateWorkload(ctx context.Context, id string, worklo
 	return nil
 }
 
+// IncrementWorkload takes n slots of a mentor unless that would exceed its
+// capacity
+func (r *MentorRepository) IncrementWorkload(ctx context.Context, id string, n int) error {
+	start := time.Now()
+
+	query := `
+		UPDATE mentors
+		SET workload = workload + $3
+		WHERE tenantId = $1 AND id = $2 AND workload + $3 <= capacity
+		RETURNING updatedAt
+	`
+
+	var updatedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, n).Scan(&updatedAt)
+
+	metrics.RecordDbQuery("mentors.IncrementWorkload", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.missingOrFull(ctx, id)
+		}
+		return fmt.Errorf("failed to increment workload: %w", err)
+	}
+
+	return nil
+}
+
+// DecrementWorkload frees n slots of a mentor, stopping at zero
+func (r *MentorRepository) DecrementWorkload(ctx context.Context, id string, n int) error {
+	start := time.Now()
+
+	query := `
+		UPDATE mentors
+		SET workload = GREATEST(workload - $3, 0)
+		WHERE tenantId = $1 AND id = $2
+		RETURNING updatedAt
+	`
+
+	var updatedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, n).Scan(&updatedAt)
+
+	metrics.RecordDbQuery("mentors.DecrementWorkload", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrMentorNotFound
+		}
+		return fmt.Errorf("failed to decrement workload: %w", err)
+	}
+
+	return nil
+}
+
+// missingOrFull tells why a conditional workload update matched no row
+func (r *MentorRepository) missingOrFull(ctx context.Context, id string) error {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM mentors WHERE tenantId = $1 AND id = $2)`,
+		domain.TenantID(ctx), id,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check mentor: %w", err)
+	}
+	if !exists {
+		return domain.ErrMentorNotFound
+	}
+	return domain.ErrMentorNotAvailable
+}
+
+// UpdateDeactivatedAt deactivates a mentor, or reactivates them when
+// deactivatedAt is nil
+func (r *MentorRepository) UpdateDeactivatedAt(ctx context.Context, id string, deactivatedAt *time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE mentors
+		SET deactivatedAt = $3
+		WHERE tenantId = $1 AND id = $2
+		RETURNING updatedAt
+	`
+
+	var updatedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, deactivatedAt).Scan(&updatedAt)
+
+	metrics.RecordDbQuery("mentors.UpdateDeactivatedAt", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrMentorNotFound
+		}
+		return fmt.Errorf("failed to update mentor deactivation: %w", err)
+	}
+
+	return nil
+}
+
 // Delete removes a mentor
 func (r *MentorRepository) Delete(ctx context.Context, id string) error {
 	start := time.Now()
 
-	query := `DELETE FROM mentors WHERE id = $1`
+	query := `DELETE FROM mentors WHERE tenantId = $1 AND id = $2`
 
-	result, err := r.pool.Exec(ctx, query, id)
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id)
 
 	metrics.RecordDbQuery("mentors.Delete", time.Since(start), err)
 
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{pool: pool}
+}
+
+// Create inserts a new notification
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO notifications (tenantId, userId, kind, title, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), notification.UserID, notification.Kind, notification.Title, notification.Body,
+	).Scan(&notification.ID, &notification.CreatedAt)
+
+	metrics.RecordDbQuery("notifications.Create", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUserID retrieves notifications of a user, newest first
+func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*domain.Notification, error) {
+	start := time.Now()
+
+	query := `
+		SELECT id, userId, kind, title, body, readAt, createdAt
+		FROM notifications
+		WHERE tenantId = $1 AND userId = $2 AND (NOT $3 OR readAt IS NULL)
+		ORDER BY createdAt DESC
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), userID, unreadOnly)
+
+	metrics.RecordDbQuery("notifications.GetByUserID", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*domain.Notification, 0)
+	for rows.Next() {
+		var n domain.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Title, &n.Body, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, &n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks a notification of the user as read; reading it again keeps
+// the first read time
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, userID string) error {
+	start := time.Now()
+
+	query := `
+		UPDATE notifications
+		SET readAt = COALESCE(readAt, NOW())
+		WHERE tenantId = $1 AND id = $2 AND userId = $3
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id, userID)
+
+	metrics.RecordDbQuery("notifications.MarkRead", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutboxRepository stores training events next to the rows they describe,
+// so they commit or roll back with the caller's transaction
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{pool: pool}
+}
+
+const outboxColumns = `id, type, payload, createdAt, dispatchedAt`
+
+// Append writes an event to the outbox
+func (r *OutboxRepository) Append(ctx context.Context, event *domain.OutboxEvent) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO outbox_events (tenantId, type, payload)
+		VALUES ($1, $2, $3)
+		RETURNING id, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), event.Type, []byte(event.Payload)).Scan(&event.ID, &event.CreatedAt)
+
+	metrics.RecordDbQuery("outbox.Append", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to append outbox event: %w", err)
+	}
+
+	metrics.OutboxEvents.WithLabelValues(string(event.Type)).Inc()
+
+	event.DispatchedAt = nil
+	return nil
+}
+
+// ListUndispatched locks up to limit undispatched events, oldest first.
+// Events locked by another dispatcher are skipped rather than waited for.
+func (r *OutboxRepository) ListUndispatched(ctx context.Context, limit int) ([]*domain.OutboxEvent, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM outbox_events
+		WHERE tenantId = $1 AND dispatchedAt IS NULL
+		ORDER BY createdAt, id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), limit)
+
+	metrics.RecordDbQuery("outbox.ListUndispatched", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*domain.OutboxEvent, 0)
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+// MarkDispatched records that the event was fanned out to its subscriptions
+func (r *OutboxRepository) MarkDispatched(ctx context.Context, id string, now time.Time) error {
+	start := time.Now()
+
+	var updated string
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, `UPDATE outbox_events SET dispatchedAt = $3 WHERE tenantId = $1 AND id = $2 RETURNING id`, domain.TenantID(ctx), id, now,
+	).Scan(&updated)
+
+	metrics.RecordDbQuery("outbox.MarkDispatched", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("outbox event %s not found", id)
+		}
+		return fmt.Errorf("failed to mark outbox event dispatched: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDispatched removes events dispatched before the time whose
+// deliveries all went through; their deliveries go with them
+func (r *OutboxRepository) DeleteDispatched(ctx context.Context, before time.Time) (int, error) {
+	start := time.Now()
+
+	query := `
+		DELETE FROM outbox_events e
+		WHERE e.tenantId = $1 AND e.dispatchedAt < $2
+			AND NOT EXISTS (
+				SELECT 1 FROM webhook_deliveries d
+				WHERE d.eventId = e.id AND d.status <> 'delivered'
+			)
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), before)
+
+	metrics.RecordDbQuery("outbox.DeleteDispatched", time.Since(start), err)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete dispatched outbox events: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// scanOutboxEvent reads a row selected with outboxColumns
+func scanOutboxEvent(row pgx.Row) (*domain.OutboxEvent, error) {
+	var e domain.OutboxEvent
+	var payload []byte
+	if err := row.Scan(&e.ID, &e.Type, &payload, &e.CreatedAt, &e.DispatchedAt); err != nil {
+		return nil, err
+	}
+	e.Payload = payload
+	return &e, nil
+}
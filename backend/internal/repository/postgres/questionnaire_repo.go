@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QuestionnaireRepository stores feedback questionnaires
+type QuestionnaireRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewQuestionnaireRepository(pool *pgxpool.Pool) *QuestionnaireRepository {
+	return &QuestionnaireRepository{pool: pool}
+}
+
+const questionnaireColumns = `id, name, audience, criteria, active, createdAt, updatedAt`
+
+// Create inserts an inactive questionnaire
+func (r *QuestionnaireRepository) Create(ctx context.Context, questionnaire *domain.Questionnaire) error {
+	start := time.Now()
+
+	criteriaJSON, err := json.Marshal(questionnaire.Criteria)
+	if err != nil {
+		return fmt.Errorf("failed to marshal criteria: %w", err)
+	}
+
+	query := `
+		INSERT INTO feedback_questionnaires (tenantId, name, audience, criteria)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, active, createdAt, updatedAt
+	`
+
+	err = conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), questionnaire.Name, questionnaire.Audience, criteriaJSON).Scan(
+		&questionnaire.ID, &questionnaire.Active, &questionnaire.CreatedAt, &questionnaire.UpdatedAt,
+	)
+
+	metrics.RecordDbQuery("questionnaires.Create", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create questionnaire: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a questionnaire by its ID
+func (r *QuestionnaireRepository) GetByID(ctx context.Context, id string) (*domain.Questionnaire, error) {
+	return r.get(ctx, "questionnaires.GetByID", "id = $2", id)
+}
+
+// GetActive retrieves the questionnaire new feedback of the audience answers
+func (r *QuestionnaireRepository) GetActive(ctx context.Context, audience domain.FeedbackAudience) (*domain.Questionnaire, error) {
+	return r.get(ctx, "questionnaires.GetActive", "audience = $2 AND active", audience)
+}
+
+func (r *QuestionnaireRepository) get(ctx context.Context, op, where string, arg any) (*domain.Questionnaire, error) {
+	start := time.Now()
+
+	query := `SELECT ` + questionnaireColumns + ` FROM feedback_questionnaires WHERE tenantId = $1 AND ` + where
+
+	questionnaire, err := scanQuestionnaire(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), arg))
+
+	metrics.RecordDbQuery(op, time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrQuestionnaireNotFound
+		}
+		return nil, fmt.Errorf("failed to get questionnaire: %w", err)
+	}
+
+	return questionnaire, nil
+}
+
+// GetAll retrieves all questionnaires by audience, newest first
+func (r *QuestionnaireRepository) GetAll(ctx context.Context) ([]*domain.Questionnaire, error) {
+	start := time.Now()
+
+	query := `SELECT ` + questionnaireColumns + ` FROM feedback_questionnaires WHERE tenantId = $1 ORDER BY audience, createdAt DESC`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("questionnaires.GetAll", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get questionnaires: %w", err)
+	}
+	defer rows.Close()
+
+	questionnaires := make([]*domain.Questionnaire, 0)
+	for rows.Next() {
+		questionnaire, err := scanQuestionnaire(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan questionnaire: %w", err)
+		}
+		questionnaires = append(questionnaires, questionnaire)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating questionnaires: %w", err)
+	}
+
+	return questionnaires, nil
+}
+
+// Update renames a questionnaire or changes its criteria as long as
+// nobody has answered it; the audience stays
+func (r *QuestionnaireRepository) Update(ctx context.Context, questionnaire *domain.Questionnaire) error {
+	start := time.Now()
+
+	criteriaJSON, err := json.Marshal(questionnaire.Criteria)
+	if err != nil {
+		return fmt.Errorf("failed to marshal criteria: %w", err)
+	}
+
+	query := `
+		UPDATE feedback_questionnaires
+		SET name = $3, criteria = $4
+		WHERE tenantId = $1 AND id = $2
+		  AND NOT EXISTS (SELECT 1 FROM feedback_responses WHERE questionnaireId = $2)
+		RETURNING audience, active, createdAt, updatedAt
+	`
+
+	err = conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), questionnaire.ID, questionnaire.Name, criteriaJSON).Scan(
+		&questionnaire.Audience, &questionnaire.Active, &questionnaire.CreatedAt, &questionnaire.UpdatedAt,
+	)
+
+	metrics.RecordDbQuery("questionnaires.Update", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Tell a missing questionnaire from an answered one
+			if _, getErr := r.GetByID(ctx, questionnaire.ID); getErr != nil {
+				return getErr
+			}
+			return domain.ErrQuestionnaireInUse
+		}
+		return fmt.Errorf("failed to update questionnaire: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a questionnaire nobody has answered
+func (r *QuestionnaireRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM feedback_questionnaires WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("questionnaires.Delete", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, foreignKeyViolation) {
+			return domain.ErrQuestionnaireInUse
+		}
+		return fmt.Errorf("failed to delete questionnaire: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrQuestionnaireNotFound
+	}
+
+	return nil
+}
+
+// Activate makes a questionnaire the one its audience answers, retiring
+// the previous one. It takes two statements, so callers run it within a
+// transaction.
+func (r *QuestionnaireRepository) Activate(ctx context.Context, id string) error {
+	start := time.Now()
+
+	q := conn(ctx, r.pool)
+	_, err := q.Exec(ctx, `
+		UPDATE feedback_questionnaires
+		SET active = FALSE
+		WHERE tenantId = $1 AND active AND id <> $2
+		  AND audience = (SELECT audience FROM feedback_questionnaires WHERE tenantId = $1 AND id = $2)
+	`, domain.TenantID(ctx), id)
+	var result pgconn.CommandTag
+	if err == nil {
+		result, err = q.Exec(ctx, `UPDATE feedback_questionnaires SET active = TRUE WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+	}
+
+	metrics.RecordDbQuery("questionnaires.Activate", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to activate questionnaire: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrQuestionnaireNotFound
+	}
+
+	return nil
+}
+
+// scanQuestionnaire reads a row selected with questionnaireColumns
+func scanQuestionnaire(row pgx.Row) (*domain.Questionnaire, error) {
+	var q domain.Questionnaire
+	var criteriaJSON []byte
+	err := row.Scan(&q.ID, &q.Name, &q.Audience, &criteriaJSON, &q.Active, &q.CreatedAt, &q.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(criteriaJSON, &q.Criteria); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal criteria: %w", err)
+	}
+
+	return &q, nil
+}
@@ -0,0 +1,84 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/migrations"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/postgres"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/repotest"
+)
+
+// TestRepositories runs the conformance suite against a real database.
+// It is skipped unless TEST_DATABASE_URL points at a disposable database.
+func TestRepositories(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	migrator, err := migrations.New(dsn)
+	if err != nil {
+		t.Fatalf("create migrator: %v", err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	migrator.Close()
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer pool.Close()
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		_, err := pool.Exec(ctx, "TRUNCATE departments, users, mentors, training_requests, learning_processes, notifications, mentor_availability_windows, mentor_absences, courses, skills, feedback_questionnaires, jobs, job_schedules, outbox_events, webhook_subscriptions CASCADE")
+		if err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		// The default tenant and its built-in roles are seeded by the
+		// migrations and stay
+		if _, err := pool.Exec(ctx, "DELETE FROM roles WHERE NOT builtIn OR tenantId <> $1", domain.DefaultTenantID); err != nil {
+			t.Fatalf("delete roles: %v", err)
+		}
+		if _, err := pool.Exec(ctx, "DELETE FROM tenants WHERE id <> $1", domain.DefaultTenantID); err != nil {
+			t.Fatalf("delete tenants: %v", err)
+		}
+		if _, err := pool.Exec(ctx, "DELETE FROM tenant_hosts"); err != nil {
+			t.Fatalf("delete tenant hosts: %v", err)
+		}
+		return repotest.Repositories{
+			Tenants:       postgres.NewTenantRepository(pool),
+			Users:         postgres.NewUserRepository(pool),
+			Roles:         postgres.NewRoleRepository(pool),
+			Departments:   postgres.NewDepartmentRepository(pool),
+			Requests:      postgres.NewRequestRepository(pool),
+			Mentors:       postgres.NewMentorRepository(pool),
+			Learnings:     postgres.NewLearningRepository(pool),
+			Notifications: postgres.NewNotificationRepository(pool),
+			Availability:  postgres.NewAvailabilityRepository(pool),
+			Approvals:     postgres.NewApprovalRepository(pool),
+			Comments:      postgres.NewCommentRepository(pool),
+			Attachments:   postgres.NewAttachmentRepository(pool),
+			Courses:       postgres.NewCourseRepository(pool),
+			Enrollments:   postgres.NewEnrollmentRepository(pool),
+			Skills:        postgres.NewSkillRepository(pool),
+			Competencies:  postgres.NewCompetencyRepository(pool),
+			Certificates:  postgres.NewCertificateRepository(pool),
+			Tx:            postgres.NewTxManager(pool),
+
+			Questionnaires: postgres.NewQuestionnaireRepository(pool),
+			Feedback:       postgres.NewFeedbackRepository(pool),
+			CheckIns:       postgres.NewCheckInRepository(pool),
+			Jobs:           postgres.NewJobRepository(pool),
+			Outbox:         postgres.NewOutboxRepository(pool),
+			Webhooks:       postgres.NewWebhookRepository(pool),
+		}
+	})
+}
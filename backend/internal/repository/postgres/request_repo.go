@@ -21,20 +21,22 @@ func NewRequestRepository(pool *pgxpool.Pool) *RequestRepository {
 	return &RequestRepository{pool: pool}
 }
 
-// Create inserts a new training request
+// Create inserts a new training request; a request created as queued joins
+// the end of the queue
 func (r *RequestRepository) Create(ctx context.Context, request *domain.TrainingRequest) error {
 	start := time.Now()
 
 	query := `
-		INSERT INTO training_requests (userId, topic, description, status)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, createdAt, updatedAt
+		INSERT INTO training_requests (tenantId, userId, topic, description, status, priority, approvalChain, courseId, skills, queuedAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $5 = 'queued' THEN CURRENT_TIMESTAMP END)
+		RETURNING id, queuedAt, createdAt, updatedAt
 	`
 
-	err := r.pool.QueryRow(
+	err := conn(ctx, r.pool).QueryRow(
 		ctx, query,
-		request.UserID, request.Topic, request.Description, request.Status,
-	).Scan(&request.ID, &request.CreatedAt, &request.UpdatedAt)
+		domain.TenantID(ctx), request.UserID, request.Topic, request.Description, request.Status, request.Priority,
+		stageNames(request.ApprovalChain), request.CourseID, domain.CleanSkills(request.Skills),
+	).Scan(&request.ID, &request.QueuedAt, &request.CreatedAt, &request.UpdatedAt)
 
 	metrics.RecordDbQuery("requests.Create", time.Since(start), err)
 
@@ -55,21 +57,16 @@ func (r *RequestRepository) GetByID(ctx context.Context, id string) (*domain.Tra
 
 	query := `
 		SELECT 
-			r.id, r.userId, r.topic, r.description, r.status, r.createdAt, r.updatedAt,
+			r.id, r.userId, r.topic, r.description, r.status, r.priority, r.approvalChain, r.queuedAt, r.courseId, r.skills, r.createdAt, r.updatedAt,
 			u.name AS userName,
 			u.jobTitle AS userJobTitle,
 			u.telegram AS userTelegram
 		FROM training_requests r
 		INNER JOIN users u ON r.userId = u.id
-		WHERE r.id = $1
+		WHERE r.tenantId = $1 AND r.id = $2
 	`
 
-	var request domain.TrainingRequest
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&request.ID, &request.UserID, &request.Topic, &request.Description,
-		&request.Status, &request.CreatedAt, &request.UpdatedAt,
-		&request.UserName, &request.UserJobTitle, &request.UserTelegram,
-	)
+	request, err := scanRequest(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
 
 	metrics.RecordDbQuery("requests.GetByID", time.Since(start), err)
 
@@ -80,7 +77,7 @@ func (r *RequestRepository) GetByID(ctx context.Context, id string) (*domain.Tra
 		return nil, fmt.Errorf("failed to get training request: %w", err)
 	}
 
-	return &request, nil
+	return request, nil
 }
 
 // GetByUserID retrieves all training requests for a user
@@ -89,17 +86,17 @@ func (r *RequestRepository) GetByUserID(ctx context.Context, userID string) ([]*
 
 	query := `
 		SELECT 
-			r.id, r.userId, r.topic, r.description, r.status, r.createdAt, r.updatedAt,
+			r.id, r.userId, r.topic, r.description, r.status, r.priority, r.approvalChain, r.queuedAt, r.courseId, r.skills, r.createdAt, r.updatedAt,
 			u.name AS userName,
 			u.jobTitle AS userJobTitle,
 			u.telegram AS userTelegram
 		FROM training_requests r
 		INNER JOIN users u ON r.userId = u.id
-		WHERE r.userId = $1
+		WHERE r.tenantId = $1 AND r.userId = $2
 		ORDER BY r.createdAt DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), userID)
 
 	metrics.RecordDbQuery("requests.GetByUserID", time.Since(start), err)
 
@@ -117,23 +114,24 @@ func (r *RequestRepository) GetAll(ctx context.Context, status *string) ([]*doma
 
 	query := `
 		SELECT 
-			r.id, r.userId, r.topic, r.description, r.status, r.createdAt, r.updatedAt,
+			r.id, r.userId, r.topic, r.description, r.status, r.priority, r.approvalChain, r.queuedAt, r.courseId, r.skills, r.createdAt, r.updatedAt,
 			u.name AS userName,
 			u.jobTitle AS userJobTitle,
 			u.telegram AS userTelegram
 		FROM training_requests r
 		INNER JOIN users u ON r.userId = u.id
+		WHERE r.tenantId = $1
 	`
 
 	var rows pgx.Rows
 	var err error
 
 	if status != nil {
-		query += " WHERE r.status = $1 ORDER BY r.createdAt DESC"
-		rows, err = r.pool.Query(ctx, query, *status)
+		query += " AND r.status = $2 ORDER BY r.createdAt DESC"
+		rows, err = conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), *status)
 	} else {
 		query += " ORDER BY r.createdAt DESC"
-		rows, err = r.pool.Query(ctx, query)
+		rows, err = conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
 	}
 
 	metrics.RecordDbQuery("requests.GetAll", time.Since(start), err)
@@ -146,19 +144,48 @@ func (r *RequestRepository) GetAll(ctx context.Context, status *string) ([]*doma
 	return r.scanRequests(rows)
 }
 
-// Update updates an existing training request
+// GetByManagerID retrieves the training requests of the users reporting to
+// a manager, with optional status filter
+func (r *RequestRepository) GetByManagerID(ctx context.Context, managerID string, status *string) ([]*domain.TrainingRequest, error) {
+	start := time.Now()
+
+	query := `
+		SELECT 
+			r.id, r.userId, r.topic, r.description, r.status, r.priority, r.approvalChain, r.queuedAt, r.courseId, r.skills, r.createdAt, r.updatedAt,
+			u.name AS userName,
+			u.jobTitle AS userJobTitle,
+			u.telegram AS userTelegram
+		FROM training_requests r
+		INNER JOIN users u ON r.userId = u.id
+		WHERE r.tenantId = $1 AND u.managerId = $2 AND ($3::text IS NULL OR r.status = $3)
+		ORDER BY r.createdAt DESC
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), managerID, status)
+
+	metrics.RecordDbQuery("requests.GetByManagerID", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team training requests: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRequests(rows)
+}
+
+// Update updates the topic, description and skill tags of a training request
 func (r *RequestRepository) Update(ctx context.Context, req *domain.TrainingRequest) error {
 	start := time.Now()
 
 	query := `
 		UPDATE training_requests
-		SET topic = $2, description = $3
-		WHERE id = $1
+		SET topic = $3, description = $4, skills = $5
+		WHERE tenantId = $1 AND id = $2
 		RETURNING updatedAt
 	`
 
 	var updatedAt time.Time
-	err := r.pool.QueryRow(ctx, query, req.ID, req.Topic, req.Description).Scan(&updatedAt)
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), req.ID, req.Topic, req.Description, domain.CleanSkills(req.Skills)).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("requests.Update", time.Since(start), err)
 
@@ -173,25 +200,26 @@ func (r *RequestRepository) Update(ctx context.Context, req *domain.TrainingRequ
 	return nil
 }
 
-// UpdateStatus updates the status of a training request
-func (r *RequestRepository) UpdateStatus(ctx context.Context, id, status string) error {
+// UpdateStatus changes the status of a request that still has the status
+// from
+func (r *RequestRepository) UpdateStatus(ctx context.Context, id string, from, to domain.RequestStatus) error {
 	start := time.Now()
 
 	query := `
 		UPDATE training_requests
-		SET status = $2
-		WHERE id = $1
+		SET status = $4
+		WHERE tenantId = $1 AND id = $2 AND status = $3
 		RETURNING updatedAt
 	`
 
 	var updatedAt time.Time
-	err := r.pool.QueryRow(ctx, query, id, status).Scan(&updatedAt)
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, from, to).Scan(&updatedAt)
 
 	metrics.RecordDbQuery("requests.UpdateStatus", time.Since(start), err)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.ErrRequestNotFound
+			return r.missingOr(ctx, id, domain.ErrRequestChanged)
 		}
 		return fmt.Errorf("failed to update request status: %w", err)
 	}
@@ -199,21 +227,73 @@ func (r *RequestRepository) UpdateStatus(ctx context.Context, id, status string)
 	return nil
 }
 
+// Enqueue puts a request that still has the status from in the queue with
+// the given priority; a request that is already queued keeps its place
+// among equal priorities
+func (r *RequestRepository) Enqueue(ctx context.Context, id string, from domain.RequestStatus, priority int) error {
+	start := time.Now()
+
+	query := `
+		UPDATE training_requests
+		SET status = 'queued',
+			priority = $4,
+			queuedAt = CASE WHEN status = 'queued' THEN queuedAt ELSE CURRENT_TIMESTAMP END
+		WHERE tenantId = $1 AND id = $2 AND status = $3
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id, from, priority)
+
+	metrics.RecordDbQuery("requests.Enqueue", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to enqueue request: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.missingOr(ctx, id, domain.ErrRequestChanged)
+	}
+
+	return nil
+}
+
+// GetQueue retrieves queued requests, highest priority first, then oldest
+func (r *RequestRepository) GetQueue(ctx context.Context) ([]*domain.TrainingRequest, error) {
+	start := time.Now()
+
+	query := `
+		SELECT 
+			r.id, r.userId, r.topic, r.description, r.status, r.priority, r.approvalChain, r.queuedAt, r.courseId, r.skills, r.createdAt, r.updatedAt,
+			u.name AS userName,
+			u.jobTitle AS userJobTitle,
+			u.telegram AS userTelegram
+		FROM training_requests r
+		INNER JOIN users u ON r.userId = u.id
+		WHERE r.tenantId = $1 AND r.status = 'queued'
+		ORDER BY r.priority DESC, r.queuedAt, r.createdAt
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("requests.GetQueue", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get request queue: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRequests(rows)
+}
+
 // scanRequests is a helper to scan multiple rows
 func (r *RequestRepository) scanRequests(rows pgx.Rows) ([]*domain.TrainingRequest, error) {
 	var requests []*domain.TrainingRequest
 
 	for rows.Next() {
-		var request domain.TrainingRequest
-		err := rows.Scan(
-			&request.ID, &request.UserID, &request.Topic, &request.Description,
-			&request.Status, &request.CreatedAt, &request.UpdatedAt,
-			&request.UserName, &request.UserJobTitle, &request.UserTelegram,
-		)
+		request, err := scanRequest(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan training request: %w", err)
 		}
-		requests = append(requests, &request)
+		requests = append(requests, request)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -222,3 +302,49 @@ func (r *RequestRepository) scanRequests(rows pgx.Rows) ([]*domain.TrainingReque
 
 	return requests, nil
 }
+
+// scanRequest scans one joined request row
+func scanRequest(row pgx.Row) (*domain.TrainingRequest, error) {
+	var request domain.TrainingRequest
+	var chain []string
+	err := row.Scan(
+		&request.ID, &request.UserID, &request.Topic, &request.Description,
+		&request.Status, &request.Priority, &chain, &request.QueuedAt, &request.CourseID, &request.Skills, &request.CreatedAt, &request.UpdatedAt,
+		&request.UserName, &request.UserJobTitle, &request.UserTelegram,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApprovalChain = make([]domain.ApprovalStage, len(chain))
+	for i, stage := range chain {
+		request.ApprovalChain[i] = domain.ApprovalStage(stage)
+	}
+	return &request, nil
+}
+
+// stageNames converts an approval chain to a TEXT[] value; never NULL
+func stageNames(chain []domain.ApprovalStage) []string {
+	names := make([]string, len(chain))
+	for i, stage := range chain {
+		names[i] = string(stage)
+	}
+	return names
+}
+
+// missingOr returns ErrRequestNotFound if the request does not exist and
+// err otherwise
+func (r *RequestRepository) missingOr(ctx context.Context, id string, err error) error {
+	var exists bool
+	checkErr := conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM training_requests WHERE tenantId = $1 AND id = $2)`,
+		domain.TenantID(ctx), id,
+	).Scan(&exists)
+	if checkErr != nil {
+		return fmt.Errorf("failed to check training request: %w", checkErr)
+	}
+	if !exists {
+		return domain.ErrRequestNotFound
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RoleRepository stores the roles users are assigned. Users reference roles
+// by name, so a role cannot be deleted while it is assigned.
+type RoleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoleRepository(pool *pgxpool.Pool) *RoleRepository {
+	return &RoleRepository{pool: pool}
+}
+
+const roleColumns = `name, description, permissions, scope, builtIn, createdAt, updatedAt`
+
+// Create inserts a new role
+func (r *RoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO roles (tenantId, name, description, permissions, scope, builtIn)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), role.Name, role.Description, permissionNames(role.Permissions), role.Scope, role.BuiltIn,
+	).Scan(&role.CreatedAt, &role.UpdatedAt)
+
+	metrics.RecordDbQuery("roles.Create", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrRoleExists
+		}
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	return nil
+}
+
+// GetByName retrieves a role by its name
+func (r *RoleRepository) GetByName(ctx context.Context, name domain.UserRole) (*domain.Role, error) {
+	start := time.Now()
+
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE tenantId = $1 AND name = $2`
+
+	role, err := scanRole(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), name))
+
+	metrics.RecordDbQuery("roles.GetByName", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetAll retrieves all roles, built-in ones first
+func (r *RoleRepository) GetAll(ctx context.Context) ([]*domain.Role, error) {
+	start := time.Now()
+
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE tenantId = $1 ORDER BY builtIn DESC, createdAt, name`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("roles.GetAll", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make([]*domain.Role, 0)
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// Update replaces the description, permissions and scope of a role
+func (r *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	start := time.Now()
+
+	query := `
+		UPDATE roles
+		SET description = $3, permissions = $4, scope = $5
+		WHERE tenantId = $1 AND name = $2
+		RETURNING builtIn, createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), role.Name, role.Description, permissionNames(role.Permissions), role.Scope,
+	).Scan(&role.BuiltIn, &role.CreatedAt, &role.UpdatedAt)
+
+	metrics.RecordDbQuery("roles.Update", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a role that no user is assigned
+func (r *RoleRepository) Delete(ctx context.Context, name domain.UserRole) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM roles WHERE tenantId = $1 AND name = $2`, domain.TenantID(ctx), name)
+
+	metrics.RecordDbQuery("roles.Delete", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, foreignKeyViolation) {
+			return domain.ErrRoleInUse
+		}
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// scanRole reads a row selected with roleColumns
+func scanRole(row pgx.Row) (*domain.Role, error) {
+	var role domain.Role
+	var permissions []string
+	err := row.Scan(&role.Name, &role.Description, &permissions, &role.Scope, &role.BuiltIn, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = permissionList(permissions)
+	return &role, nil
+}
+
+func permissionNames(permissions []domain.Permission) []string {
+	names := make([]string, len(permissions))
+	for i, p := range permissions {
+		names[i] = string(p)
+	}
+	return names
+}
+
+func permissionList(names []string) []domain.Permission {
+	permissions := make([]domain.Permission, len(names))
+	for i, name := range names {
+		permissions[i] = domain.Permission(name)
+	}
+	return permissions
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SkillRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSkillRepository(pool *pgxpool.Pool) *SkillRepository {
+	return &SkillRepository{pool: pool}
+}
+
+const skillColumns = `id, name, description, createdAt, updatedAt`
+
+// Create inserts a new skill; names are unique ignoring case
+func (r *SkillRepository) Create(ctx context.Context, skill *domain.Skill) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO skills (tenantId, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), skill.Name, skill.Description).Scan(
+		&skill.ID, &skill.CreatedAt, &skill.UpdatedAt,
+	)
+
+	metrics.RecordDbQuery("skills.Create", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrSkillExists
+		}
+		return fmt.Errorf("failed to create skill: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a skill by its ID
+func (r *SkillRepository) GetByID(ctx context.Context, id string) (*domain.Skill, error) {
+	start := time.Now()
+
+	query := `SELECT ` + skillColumns + ` FROM skills WHERE tenantId = $1 AND id = $2`
+
+	skill, err := scanSkill(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("skills.GetByID", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSkillNotFound
+		}
+		return nil, fmt.Errorf("failed to get skill: %w", err)
+	}
+
+	return skill, nil
+}
+
+// GetAll retrieves the competency model ordered by name
+func (r *SkillRepository) GetAll(ctx context.Context) ([]*domain.Skill, error) {
+	start := time.Now()
+
+	query := `SELECT ` + skillColumns + ` FROM skills WHERE tenantId = $1 ORDER BY lower(name)`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("skills.GetAll", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skills: %w", err)
+	}
+	defer rows.Close()
+
+	skills := make([]*domain.Skill, 0)
+	for rows.Next() {
+		skill, err := scanSkill(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan skill: %w", err)
+		}
+		skills = append(skills, skill)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating skills: %w", err)
+	}
+
+	return skills, nil
+}
+
+// Update renames or redescribes a skill
+func (r *SkillRepository) Update(ctx context.Context, skill *domain.Skill) error {
+	start := time.Now()
+
+	query := `
+		UPDATE skills
+		SET name = $3, description = $4
+		WHERE tenantId = $1 AND id = $2
+		RETURNING createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), skill.ID, skill.Name, skill.Description).Scan(
+		&skill.CreatedAt, &skill.UpdatedAt,
+	)
+
+	metrics.RecordDbQuery("skills.Update", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrSkillNotFound
+		}
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrSkillExists
+		}
+		return fmt.Errorf("failed to update skill: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a skill with its targets and recorded levels
+func (r *SkillRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM skills WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("skills.Delete", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete skill: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrSkillNotFound
+	}
+
+	return nil
+}
+
+func scanSkill(row pgx.Row) (*domain.Skill, error) {
+	var s domain.Skill
+	if err := row.Scan(&s.ID, &s.Name, &s.Description, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TenantRepository stores the organisations hosted on the deployment and
+// the host names that resolve to them. Unlike the other repositories it
+// ignores the tenant of the context. The SCIM token hash of the settings is
+// kept in its own indexed column, so that SCIM requests find their tenant
+// without scanning the others.
+type TenantRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTenantRepository(pool *pgxpool.Pool) *TenantRepository {
+	return &TenantRepository{pool: pool}
+}
+
+const tenantColumns = `
+	t.id, t.slug, t.name, t.settings, COALESCE(t.scimTokenHash, ''),
+	ARRAY(SELECT h.host FROM tenant_hosts h WHERE h.tenantId = t.id ORDER BY h.host) AS hosts,
+	t.createdAt, t.updatedAt
+`
+
+// Create inserts a new tenant with its hosts; slugs and hosts are unique
+func (r *TenantRepository) Create(ctx context.Context, tenant *domain.Tenant) error {
+	start := time.Now()
+
+	settingsJSON, err := marshalSettings(tenant.Settings)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO tenants (slug, name, settings, scimTokenHash)
+			VALUES ($1, $2, $3, NULLIF($5, ''))
+			RETURNING id, createdAt, updatedAt
+		), hosts AS (
+			INSERT INTO tenant_hosts (host, tenantId)
+			SELECT h.name, inserted.id FROM inserted, unnest($4::text[]) AS h(name)
+		)
+		SELECT id, createdAt, updatedAt FROM inserted
+	`
+
+	err = conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		tenant.Slug, tenant.Name, settingsJSON, hostNames(tenant.Hosts), tenant.Settings.SCIM.TokenHash,
+	).Scan(
+		&tenant.ID, &tenant.CreatedAt, &tenant.UpdatedAt,
+	)
+
+	metrics.RecordDbQuery("tenants.Create", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrTenantExists
+		}
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a tenant by its ID
+func (r *TenantRepository) GetByID(ctx context.Context, id string) (*domain.Tenant, error) {
+	return r.get(ctx, "tenants.GetByID", `SELECT `+tenantColumns+` FROM tenants t WHERE t.id = $1`, id)
+}
+
+// GetByIDForUpdate retrieves a tenant by its ID and locks its row until the
+// transaction ends
+func (r *TenantRepository) GetByIDForUpdate(ctx context.Context, id string) (*domain.Tenant, error) {
+	return r.get(ctx, "tenants.GetByIDForUpdate", `SELECT `+tenantColumns+` FROM tenants t WHERE t.id = $1 FOR UPDATE OF t`, id)
+}
+
+// GetBySCIMTokenHash retrieves the tenant a SCIM token with the hash was
+// issued for
+func (r *TenantRepository) GetBySCIMTokenHash(ctx context.Context, hash string) (*domain.Tenant, error) {
+	return r.get(ctx, "tenants.GetBySCIMTokenHash", `SELECT `+tenantColumns+` FROM tenants t WHERE t.scimTokenHash = $1`, hash)
+}
+
+// GetByHost retrieves the tenant a normalised host name resolves to
+func (r *TenantRepository) GetByHost(ctx context.Context, host string) (*domain.Tenant, error) {
+	query := `
+		SELECT ` + tenantColumns + `
+		FROM tenants t
+		INNER JOIN tenant_hosts th ON th.tenantId = t.id
+		WHERE th.host = $1
+	`
+	return r.get(ctx, "tenants.GetByHost", query, host)
+}
+
+func (r *TenantRepository) get(ctx context.Context, op, query string, arg any) (*domain.Tenant, error) {
+	start := time.Now()
+
+	tenant, err := scanTenant(conn(ctx, r.pool).QueryRow(ctx, query, arg))
+
+	metrics.RecordDbQuery(op, time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+// GetAll retrieves every tenant, oldest first
+func (r *TenantRepository) GetAll(ctx context.Context) ([]*domain.Tenant, error) {
+	start := time.Now()
+
+	query := `SELECT ` + tenantColumns + ` FROM tenants t ORDER BY t.createdAt, t.id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query)
+
+	metrics.RecordDbQuery("tenants.GetAll", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := make([]*domain.Tenant, 0)
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// Update replaces the name, settings and hosts of a tenant; the slug stays
+func (r *TenantRepository) Update(ctx context.Context, tenant *domain.Tenant) error {
+	start := time.Now()
+
+	settingsJSON, err := marshalSettings(tenant.Settings)
+	if err != nil {
+		return err
+	}
+
+	// All parts of the statement see the hosts as they were, so only hosts
+	// the tenant does not have yet are inserted
+	query := `
+		WITH updated AS (
+			UPDATE tenants
+			SET name = $2, settings = $3, scimTokenHash = NULLIF($5, '')
+			WHERE id = $1
+			RETURNING id, slug, createdAt, updatedAt
+		), removed AS (
+			DELETE FROM tenant_hosts
+			WHERE tenantId IN (SELECT id FROM updated) AND host <> ALL($4::text[])
+		), added AS (
+			INSERT INTO tenant_hosts (host, tenantId)
+			SELECT h.name, updated.id FROM updated, unnest($4::text[]) AS h(name)
+			WHERE NOT EXISTS (
+				SELECT 1 FROM tenant_hosts th WHERE th.host = h.name AND th.tenantId = updated.id
+			)
+		)
+		SELECT slug, createdAt, updatedAt FROM updated
+	`
+
+	err = conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		tenant.ID, tenant.Name, settingsJSON, hostNames(tenant.Hosts), tenant.Settings.SCIM.TokenHash,
+	).Scan(&tenant.Slug, &tenant.CreatedAt, &tenant.UpdatedAt)
+
+	metrics.RecordDbQuery("tenants.Update", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrTenantNotFound
+		}
+		if isViolation(err, uniqueViolation) {
+			return domain.ErrTenantExists
+		}
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	return nil
+}
+
+// scanTenant reads a row selected with tenantColumns
+func scanTenant(row pgx.Row) (*domain.Tenant, error) {
+	var t domain.Tenant
+	var settingsJSON []byte
+	var scimTokenHash string
+	err := row.Scan(&t.ID, &t.Slug, &t.Name, &settingsJSON, &scimTokenHash, &t.Hosts, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(settingsJSON, &t.Settings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	t.Settings.SCIM.TokenHash = scimTokenHash
+
+	return &t, nil
+}
+
+// marshalSettings encodes the settings for the settings column, leaving out
+// the SCIM token hash, which has a column of its own
+func marshalSettings(settings domain.TenantSettings) ([]byte, error) {
+	settings.SCIM = domain.SCIMSettings{}
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	return settingsJSON, nil
+}
+
+// hostNames converts hosts to a TEXT[] value; never NULL
+func hostNames(hosts []string) []string {
+	if hosts == nil {
+		return []string{}
+	}
+	return hosts
+}
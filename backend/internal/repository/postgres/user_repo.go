@@ -21,21 +21,35 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 	return &UserRepository{pool: pool}
 }
 
+// userColumns are selected from userTables: users u joined with the roles r
+// granting their permissions and their departments d
+const userColumns = `
+	u.id, u.name, u.email, u.password_hash, u.role, u.departmentId, d.name, u.jobTitle, u.telegram, u.managerId, u.externalId,
+	u.deactivatedAt, u.createdAt, u.updatedAt, r.permissions, r.scope
+`
+
+const userTables = `users u JOIN roles r ON r.tenantId = u.tenantId AND r.name = u.role LEFT JOIN departments d ON d.id = u.departmentId`
+
 // Create inserts a new user into the database
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	start := time.Now()
 
 	query := `
-		INSERT INTO users (name, email, password_hash, role, department, jobTitle, telegram)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, createdAt, updatedAt
+		WITH u AS (
+			INSERT INTO users (tenantId, name, email, password_hash, role, departmentId, jobTitle, telegram, managerId, externalId)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, tenantId, role, departmentId, createdAt, updatedAt
+		)
+		SELECT u.id, u.createdAt, u.updatedAt, r.permissions, r.scope, d.name
+		FROM u JOIN roles r ON r.tenantId = u.tenantId AND r.name = u.role LEFT JOIN departments d ON d.id = u.departmentId
 	`
 
-	err := r.pool.QueryRow(
+	var permissions []string
+	err := conn(ctx, r.pool).QueryRow(
 		ctx, query,
-		user.Name, user.Email, user.PasswordHash, user.Role,
-		user.Department, user.JobTitle, user.Telegram,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		domain.TenantID(ctx), user.Name, user.Email, user.PasswordHash, user.Role,
+		user.DepartmentID, user.JobTitle, user.Telegram, user.ManagerID, user.ExternalID,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &permissions, &user.PermissionScope, &user.Department)
 
 	metrics.RecordDbQuery("users.Create", time.Since(start), err)
 
@@ -43,20 +57,22 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
+	user.Permissions = permissionList(permissions)
 	return nil
 }
 
-// GetAll retrieves all users (admin only)
+// GetAll retrieves all users
 func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
 	start := time.Now()
 
 	query := `
-		SELECT id, name, email, passwordHash, role, department, jobTitle, telegram, createdAt, updatedAt
-		FROM users
-		ORDER BY createdAt DESC
+		SELECT ` + userColumns + `
+		FROM ` + userTables + `
+		WHERE u.tenantId = $1
+		ORDER BY u.createdAt DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
 
 	metrics.RecordDbQuery("users.GetAll", time.Since(start), err)
 
@@ -67,16 +83,11 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
 
 	var users []*domain.User
 	for rows.Next() {
-		var user domain.User
-		err := rows.Scan(
-			&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.Role,
-			&user.Department, &user.JobTitle, &user.Telegram,
-			&user.CreatedAt, &user.UpdatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -91,17 +102,12 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User,
 	start := time.Now()
 
 	query := `
-		SELECT id, name, email, password_hash, role, department, jobTitle, telegram, createdAt, updatedAt
-		FROM users
-		WHERE id = $1
+		SELECT ` + userColumns + `
+		FROM ` + userTables + `
+		WHERE u.tenantId = $1 AND u.id = $2
 	`
 
-	var user domain.User
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.Role,
-		&user.Department, &user.JobTitle, &user.Telegram,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
 
 	metrics.RecordDbQuery("users.GetByID", time.Since(start), err)
 
@@ -112,7 +118,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User,
 		return nil, fmt.Errorf("failed to get user by id: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // GetByEmail retrieves a user by email (for login)
@@ -120,17 +126,12 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 	start := time.Now()
 
 	query := `
-		SELECT id, name, email, password_hash, role, department, jobTitle, telegram, createdAt, updatedAt
-		FROM users
-		WHERE email = $1
+		SELECT ` + userColumns + `
+		FROM ` + userTables + `
+		WHERE u.tenantId = $1 AND u.email = $2
 	`
 
-	var user domain.User
-	err := r.pool.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.Role,
-		&user.Department, &user.JobTitle, &user.Telegram,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), email))
 
 	metrics.RecordDbQuery("users.GetByEmail", time.Since(start), err)
 
@@ -141,7 +142,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // Update updates user information
@@ -149,16 +150,23 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	start := time.Now()
 
 	query := `
-		UPDATE users
-		SET name = $2, department = $3, jobTitle = $4, telegram = $5
-		WHERE id = $1
-		RETURNING updatedAt
+		WITH u AS (
+			UPDATE users
+			SET name = $3, email = $4, password_hash = $5, role = $6,
+			    departmentId = $7, jobTitle = $8, telegram = $9, externalId = $10
+			WHERE tenantId = $1 AND id = $2
+			RETURNING tenantId, role, departmentId, updatedAt
+		)
+		SELECT u.updatedAt, r.permissions, r.scope, d.name
+		FROM u JOIN roles r ON r.tenantId = u.tenantId AND r.name = u.role LEFT JOIN departments d ON d.id = u.departmentId
 	`
 
-	err := r.pool.QueryRow(
+	var permissions []string
+	err := conn(ctx, r.pool).QueryRow(
 		ctx, query,
-		user.ID, user.Name, user.Department, user.JobTitle, user.Telegram,
-	).Scan(&user.UpdatedAt)
+		domain.TenantID(ctx), user.ID, user.Name, user.Email, user.PasswordHash, user.Role,
+		user.DepartmentID, user.JobTitle, user.Telegram, user.ExternalID,
+	).Scan(&user.UpdatedAt, &permissions, &user.PermissionScope, &user.Department)
 
 	metrics.RecordDbQuery("users.Update", time.Since(start), err)
 
@@ -169,6 +177,61 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
+	user.Permissions = permissionList(permissions)
+	return nil
+}
+
+// UpdateDeactivatedAt deactivates a user, or reactivates them when
+// deactivatedAt is nil
+func (r *UserRepository) UpdateDeactivatedAt(ctx context.Context, id string, deactivatedAt *time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE users
+		SET deactivatedAt = $3
+		WHERE tenantId = $1 AND id = $2
+		RETURNING updatedAt
+	`
+
+	var updatedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, deactivatedAt).Scan(&updatedAt)
+
+	metrics.RecordDbQuery("users.UpdateDeactivatedAt", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update user deactivation: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateManager sets the line manager of a user, or clears it when
+// managerID is nil
+func (r *UserRepository) UpdateManager(ctx context.Context, id string, managerID *string) error {
+	start := time.Now()
+
+	query := `
+		UPDATE users
+		SET managerId = $3
+		WHERE tenantId = $1 AND id = $2
+		RETURNING updatedAt
+	`
+
+	var updatedAt time.Time
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, managerID).Scan(&updatedAt)
+
+	metrics.RecordDbQuery("users.UpdateManager", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update user manager: %w", err)
+	}
+
 	return nil
 }
 
@@ -176,9 +239,9 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	start := time.Now()
 
-	query := `DELETE FROM users WHERE id = $1`
+	query := `DELETE FROM users WHERE tenantId = $1 AND id = $2`
 
-	result, err := r.pool.Exec(ctx, query, id)
+	result, err := conn(ctx, r.pool).Exec(ctx, query, domain.TenantID(ctx), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -191,3 +254,19 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// scanUser reads a row selected with userColumns
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var user domain.User
+	var permissions []string
+	err := row.Scan(
+		&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.Role,
+		&user.DepartmentID, &user.Department, &user.JobTitle, &user.Telegram, &user.ManagerID, &user.ExternalID, &user.DeactivatedAt,
+		&user.CreatedAt, &user.UpdatedAt, &permissions, &user.PermissionScope,
+	)
+	if err != nil {
+		return nil, err
+	}
+	user.Permissions = permissionList(permissions)
+	return &user, nil
+}
@@ -0,0 +1,429 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookRepository stores webhook subscriptions and the deliveries of
+// outbox events to them. Dispatchers claim deliveries with FOR UPDATE SKIP
+// LOCKED, so app instances never send the same delivery at once.
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+const subscriptionColumns = `id, url, secret, eventTypes, description, active, createdAt, updatedAt`
+
+// deliveryColumns are selected from webhook_deliveries d joined with its
+// outbox_events e
+const deliveryColumns = `
+	d.id, d.subscriptionId, d.eventId, e.type, e.payload, e.createdAt, d.status, d.attempts,
+	d.nextAttemptAt, d.lastStatusCode, d.lastError, d.createdAt, d.deliveredAt
+`
+
+// CreateSubscription inserts a new subscription
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO webhook_subscriptions (tenantId, url, secret, eventTypes, description, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query,
+		domain.TenantID(ctx), sub.URL, sub.Secret, eventTypeNames(sub.EventTypes), sub.Description, sub.Active,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+
+	metrics.RecordDbQuery("webhooks.CreateSubscription", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetSubscription retrieves a subscription by its ID
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	start := time.Now()
+
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE tenantId = $1 AND id = $2`
+
+	sub, err := scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("webhooks.GetSubscription", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// ListSubscriptions retrieves all subscriptions, newest first
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	start := time.Now()
+
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE tenantId = $1 ORDER BY createdAt DESC, id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx))
+
+	metrics.RecordDbQuery("webhooks.ListSubscriptions", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]*domain.WebhookSubscription, 0)
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// UpdateSubscription replaces the fields of a subscription
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	start := time.Now()
+
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $3, secret = $4, eventTypes = $5, description = $6, active = $7
+		WHERE tenantId = $1 AND id = $2
+		RETURNING createdAt, updatedAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query, domain.TenantID(ctx), sub.ID,
+		sub.URL, sub.Secret, eventTypeNames(sub.EventTypes), sub.Description, sub.Active,
+	).Scan(&sub.CreatedAt, &sub.UpdatedAt)
+
+	metrics.RecordDbQuery("webhooks.UpdateSubscription", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSubscription removes a subscription with its deliveries
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	start := time.Now()
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM webhook_subscriptions WHERE tenantId = $1 AND id = $2`, domain.TenantID(ctx), id)
+
+	metrics.RecordDbQuery("webhooks.DeleteSubscription", time.Since(start), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// CreateDelivery inserts a pending delivery of an event to a subscription
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	start := time.Now()
+
+	query := `
+		INSERT INTO webhook_deliveries (tenantId, subscriptionId, eventId, nextAttemptAt)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, attempts, createdAt
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx, query, domain.TenantID(ctx), delivery.SubscriptionID, delivery.EventID, delivery.NextAttemptAt,
+	).Scan(&delivery.ID, &delivery.Status, &delivery.Attempts, &delivery.CreatedAt)
+
+	metrics.RecordDbQuery("webhooks.CreateDelivery", time.Since(start), err)
+
+	if err != nil {
+		if isViolation(err, foreignKeyViolation) {
+			return domain.ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	delivery.LastStatusCode = nil
+	delivery.LastError = ""
+	delivery.DeliveredAt = nil
+	return nil
+}
+
+// GetDelivery retrieves a delivery by its ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.eventId
+		WHERE d.tenantId = $1 AND d.id = $2
+	`
+
+	delivery, err := scanDelivery(conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id))
+
+	metrics.RecordDbQuery("webhooks.GetDelivery", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries retrieves deliveries matching the filter, newest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	start := time.Now()
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.eventId
+		WHERE d.tenantId = $1
+	`
+	args := []interface{}{domain.TenantID(ctx)}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND d.status = $%d", len(args))
+	}
+	if filter.SubscriptionID != "" {
+		args = append(args, filter.SubscriptionID)
+		query += fmt.Sprintf(" AND d.subscriptionId = $%d", len(args))
+	}
+	query += " ORDER BY d.createdAt DESC, d.id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+
+	metrics.RecordDbQuery("webhooks.ListDeliveries", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return collectDeliveries(rows)
+}
+
+// ClaimDeliveries takes up to limit due deliveries of active subscriptions,
+// oldest first. Rows locked by another dispatcher are skipped.
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	start := time.Now()
+
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, nextAttemptAt = $3
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhook_subscriptions s ON s.id = d.subscriptionId
+				WHERE d.tenantId = $1 AND d.status = 'pending' AND d.nextAttemptAt <= $2 AND s.active
+				ORDER BY d.nextAttemptAt, d.createdAt
+				LIMIT $4
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + deliveryColumns + `
+		FROM claimed d
+		JOIN outbox_events e ON e.id = d.eventId
+		ORDER BY e.createdAt, d.id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, domain.TenantID(ctx), now, now.Add(lease), limit)
+
+	metrics.RecordDbQuery("webhooks.ClaimDeliveries", time.Since(start), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return collectDeliveries(rows)
+}
+
+// MarkDelivered records the successful attempt
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id string, statusCode int, now time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', lastStatusCode = $3, lastError = '', deliveredAt = $4
+		WHERE tenantId = $1 AND id = $2 AND status = 'pending'
+		RETURNING (SELECT type FROM outbox_events WHERE id = eventId)
+	`
+
+	var eventType string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, statusCode, now).Scan(&eventType)
+
+	metrics.RecordDbQuery("webhooks.MarkDelivered", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrDeliveryNotFound
+		}
+		return fmt.Errorf("failed to mark webhook delivery delivered: %w", err)
+	}
+
+	metrics.WebhookDeliveries.WithLabelValues(eventType, "delivered").Inc()
+	return nil
+}
+
+// MarkFailed records a failed attempt and schedules the retry, if any
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id string, statusCode *int, lastError string, retryAt *time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $5::timestamptz IS NULL THEN 'dead' ELSE 'pending' END,
+			nextAttemptAt = COALESCE($5, nextAttemptAt),
+			lastStatusCode = $3, lastError = $4
+		WHERE tenantId = $1 AND id = $2 AND status = 'pending'
+		RETURNING (SELECT type FROM outbox_events WHERE id = eventId)
+	`
+
+	var eventType string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, statusCode, lastError, retryAt).Scan(&eventType)
+
+	metrics.RecordDbQuery("webhooks.MarkFailed", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrDeliveryNotFound
+		}
+		return fmt.Errorf("failed to record webhook delivery failure: %w", err)
+	}
+
+	outcome := "dead"
+	if retryAt != nil {
+		outcome = "retried"
+	}
+	metrics.WebhookDeliveries.WithLabelValues(eventType, outcome).Inc()
+	return nil
+}
+
+// Redeliver makes a dead delivery pending again
+func (r *WebhookRepository) Redeliver(ctx context.Context, id string, now time.Time) error {
+	start := time.Now()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, nextAttemptAt = $3
+		WHERE tenantId = $1 AND id = $2 AND status = 'dead'
+		RETURNING id
+	`
+
+	var updated string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, domain.TenantID(ctx), id, now).Scan(&updated)
+
+	metrics.RecordDbQuery("webhooks.Redeliver", time.Since(start), err)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.GetDelivery(ctx, id); getErr != nil {
+				return getErr
+			}
+			return domain.ErrDeliveryNotRetryable
+		}
+		return fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// collectDeliveries reads and closes rows selected with deliveryColumns
+func collectDeliveries(rows pgx.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func scanSubscription(row pgx.Row) (*domain.WebhookSubscription, error) {
+	var s domain.WebhookSubscription
+	var eventTypes []string
+	err := row.Scan(&s.ID, &s.URL, &s.Secret, &eventTypes, &s.Description, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	s.EventTypes = make([]domain.EventType, len(eventTypes))
+	for i, t := range eventTypes {
+		s.EventTypes[i] = domain.EventType(t)
+	}
+	return &s, nil
+}
+
+// scanDelivery reads a row selected with deliveryColumns
+func scanDelivery(row pgx.Row) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var payload []byte
+	err := row.Scan(
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.EventCreatedAt, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+func eventTypeNames(types []domain.EventType) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return names
+}
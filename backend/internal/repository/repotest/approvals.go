@@ -0,0 +1,104 @@
+package repotest
+
+import (
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testApprovals(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndListOldestFirst", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		boss := createUser(t, repos, "boss")
+		admin := createUser(t, repos, "admin")
+		request := createRequest(t, repos, alice.ID, "Go")
+
+		empty, err := repos.Approvals.GetByRequestID(ctx, request.ID)
+		if err != nil || empty == nil || len(empty) != 0 {
+			t.Errorf("GetByRequestID with no decisions = %v, %v; want empty slice", empty, err)
+		}
+
+		first := createDecision(t, repos, request.ID, domain.StageManager, boss.ID, ptr("worth it"))
+		if first.ID == "" || first.CreatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamp: %+v", first)
+		}
+		createDecision(t, repos, request.ID, domain.StageAdmin, admin.ID, nil)
+
+		decisions, err := repos.Approvals.GetByRequestID(ctx, request.ID)
+		if err != nil {
+			t.Fatalf("GetByRequestID: %v", err)
+		}
+		if len(decisions) != 2 || decisions[0].ID != first.ID || decisions[1].Stage != domain.StageAdmin {
+			t.Fatalf("GetByRequestID returned %d decisions out of order", len(decisions))
+		}
+		got := decisions[0]
+		if got.DeciderID == nil || *got.DeciderID != boss.ID || got.DeciderName != "boss" ||
+			got.Decision != domain.DecisionApproved || got.Comment == nil || *got.Comment != "worth it" {
+			t.Errorf("GetByRequestID returned %+v", got)
+		}
+		if decisions[1].Comment != nil {
+			t.Errorf("comment = %v, want nil", *decisions[1].Comment)
+		}
+	})
+
+	t.Run("CreateChecksReferences", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		request := createRequest(t, repos, alice.ID, "Go")
+
+		orphan := &domain.ApprovalDecision{RequestID: missingID(), Stage: domain.StageAdmin, Decision: domain.DecisionApproved}
+		if err := repos.Approvals.Create(ctx, orphan); err == nil {
+			t.Error("Create for a missing request succeeded")
+		}
+		unknown := &domain.ApprovalDecision{RequestID: request.ID, Stage: domain.StageAdmin, Decision: "maybe"}
+		if err := repos.Approvals.Create(ctx, unknown); err == nil {
+			t.Error("Create with an unknown decision succeeded")
+		}
+	})
+
+	t.Run("DeleteKeepsHistory", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		boss := createUser(t, repos, "boss")
+		request := createRequest(t, repos, alice.ID, "Go")
+		createDecision(t, repos, request.ID, domain.StageManager, boss.ID, nil)
+
+		// The decision outlives its decider
+		if err := repos.Users.Delete(ctx, boss.ID); err != nil {
+			t.Fatalf("Delete decider: %v", err)
+		}
+		decisions, _ := repos.Approvals.GetByRequestID(ctx, request.ID)
+		if len(decisions) != 1 || decisions[0].DeciderID != nil || decisions[0].DeciderName != "" {
+			t.Fatalf("decision after the decider was deleted = %+v", decisions)
+		}
+
+		// and goes away with the request
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete requester: %v", err)
+		}
+		decisions, _ = repos.Approvals.GetByRequestID(ctx, request.ID)
+		if len(decisions) != 0 {
+			t.Errorf("decisions not cascaded: %d left", len(decisions))
+		}
+	})
+}
+
+// createDecision inserts an approving decision on a request
+func createDecision(t *testing.T, repos Repositories, requestID string, stage domain.ApprovalStage, deciderID string, comment *string) *domain.ApprovalDecision {
+	t.Helper()
+
+	decision := &domain.ApprovalDecision{
+		RequestID: requestID,
+		Stage:     stage,
+		DeciderID: &deciderID,
+		Decision:  domain.DecisionApproved,
+		Comment:   comment,
+	}
+	if err := repos.Approvals.Create(defaultCtx(), decision); err != nil {
+		t.Fatalf("create decision: %v", err)
+	}
+	return decision
+}
@@ -0,0 +1,180 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testAttachments(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndListOldestFirst", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "mentor", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		other := createLearning(t, repos, alice.ID, mentor.ID, "Rust")
+
+		empty, err := repos.Attachments.GetByLearningID(ctx, learning.ID)
+		if err != nil || empty == nil || len(empty) != 0 {
+			t.Errorf("GetByLearningID with no attachments = %v, %v; want empty slice", empty, err)
+		}
+
+		first := createAttachment(t, repos, &domain.Attachment{LearningID: &learning.ID, UploaderID: &alice.ID, FileName: "notes.pdf"})
+		if first.ID == "" || first.CreatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamp: %+v", first)
+		}
+		second := createAttachment(t, repos, &domain.Attachment{LearningID: &learning.ID, PlanItemID: ptr("item-1"), UploaderID: &alice.ID, FileName: "step.png"})
+		createAttachment(t, repos, &domain.Attachment{LearningID: &other.ID, UploaderID: &alice.ID, FileName: "other.pdf"})
+
+		attachments, err := repos.Attachments.GetByLearningID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByLearningID: %v", err)
+		}
+		if len(attachments) != 2 || attachments[0].ID != first.ID || attachments[1].ID != second.ID {
+			t.Fatalf("GetByLearningID returned %d attachments out of order", len(attachments))
+		}
+		got := attachments[1]
+		if got.PlanItemID == nil || *got.PlanItemID != "item-1" || got.CommentID != nil || !got.IsUploader(alice.ID) ||
+			got.UploaderName != "alice" || got.FileName != "step.png" || got.ContentType != "application/pdf" ||
+			got.Size != 4 || got.Checksum != second.Checksum || got.StorageKey != second.StorageKey {
+			t.Errorf("GetByLearningID returned %+v", got)
+		}
+
+		byID, err := repos.Attachments.GetByID(ctx, first.ID)
+		if err != nil || byID.FileName != "notes.pdf" || byID.UploaderName != "alice" || byID.PlanItemID != nil {
+			t.Errorf("GetByID = %+v, %v", byID, err)
+		}
+		if _, err := repos.Attachments.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrAttachmentNotFound) {
+			t.Errorf("GetByID of a missing attachment = %v, want ErrAttachmentNotFound", err)
+		}
+	})
+
+	t.Run("CommentAttachments", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		request := createRequest(t, repos, alice.ID, "Go")
+		comment := createComment(t, repos, domain.CommentOnRequest, request.ID, alice.ID, nil, "see attached")
+
+		attachment := createAttachment(t, repos, &domain.Attachment{CommentID: &comment.ID, UploaderID: &alice.ID, FileName: "quote.pdf"})
+
+		attachments, err := repos.Attachments.GetByCommentID(ctx, comment.ID)
+		if err != nil || len(attachments) != 1 || attachments[0].ID != attachment.ID || attachments[0].LearningID != nil {
+			t.Fatalf("GetByCommentID = %+v, %v", attachments, err)
+		}
+		attachments, _ = repos.Attachments.GetByCommentID(ctx, missingID())
+		if len(attachments) != 0 {
+			t.Errorf("GetByCommentID of another comment returned %d attachments", len(attachments))
+		}
+	})
+
+	t.Run("CreateChecksReferences", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "mentor", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		request := createRequest(t, repos, alice.ID, "Rust")
+		comment := createComment(t, repos, domain.CommentOnRequest, request.ID, alice.ID, nil, "x")
+		taken := createAttachment(t, repos, &domain.Attachment{LearningID: &learning.ID, UploaderID: &alice.ID})
+
+		cases := map[string]*domain.Attachment{
+			"no owner":          {UploaderID: &alice.ID},
+			"two owners":        {LearningID: &learning.ID, CommentID: &comment.ID, UploaderID: &alice.ID},
+			"plan item only":    {CommentID: &comment.ID, PlanItemID: ptr("item-1"), UploaderID: &alice.ID},
+			"missing learning":  {LearningID: ptr(missingID()), UploaderID: &alice.ID},
+			"missing comment":   {CommentID: ptr(missingID()), UploaderID: &alice.ID},
+			"missing uploader":  {LearningID: &learning.ID, UploaderID: ptr(missingID())},
+			"negative size":     {LearningID: &learning.ID, UploaderID: &alice.ID, Size: -1},
+			"taken storage key": {LearningID: &learning.ID, UploaderID: &alice.ID, StorageKey: taken.StorageKey},
+		}
+		for name, attachment := range cases {
+			fillAttachment(attachment)
+			if err := repos.Attachments.Create(ctx, attachment); err == nil {
+				t.Errorf("Create with %s succeeded", name)
+			}
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "mentor", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		attachment := createAttachment(t, repos, &domain.Attachment{LearningID: &learning.ID, UploaderID: &alice.ID})
+
+		if err := repos.Attachments.Delete(ctx, attachment.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Attachments.GetByID(ctx, attachment.ID); !errors.Is(err, domain.ErrAttachmentNotFound) {
+			t.Errorf("GetByID after Delete = %v, want ErrAttachmentNotFound", err)
+		}
+		if err := repos.Attachments.Delete(ctx, attachment.ID); !errors.Is(err, domain.ErrAttachmentNotFound) {
+			t.Errorf("Delete again = %v, want ErrAttachmentNotFound", err)
+		}
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		admin := createUser(t, repos, "admin")
+		mentor := createMentor(t, repos, "mentor", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		request := createRequest(t, repos, alice.ID, "Rust")
+		comment := createComment(t, repos, domain.CommentOnRequest, request.ID, admin.ID, nil, "x")
+		byAdmin := createAttachment(t, repos, &domain.Attachment{LearningID: &learning.ID, UploaderID: &admin.ID})
+		onComment := createAttachment(t, repos, &domain.Attachment{CommentID: &comment.ID, UploaderID: &admin.ID})
+
+		// The attachment outlives its uploader
+		if err := repos.Users.Delete(ctx, admin.ID); err != nil {
+			t.Fatalf("Delete uploader: %v", err)
+		}
+		got, err := repos.Attachments.GetByID(ctx, byAdmin.ID)
+		if err != nil || got.UploaderID != nil || got.UploaderName != "" {
+			t.Fatalf("attachment after the uploader was deleted = %+v, %v", got, err)
+		}
+
+		// and goes away with the learning or comment
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete learner: %v", err)
+		}
+		for _, id := range []string{byAdmin.ID, onComment.ID} {
+			if _, err := repos.Attachments.GetByID(ctx, id); !errors.Is(err, domain.ErrAttachmentNotFound) {
+				t.Errorf("attachment not cascaded: %v", err)
+			}
+		}
+	})
+}
+
+// fillAttachment sets the file fields a test left empty
+func fillAttachment(attachment *domain.Attachment) {
+	if attachment.FileName == "" {
+		attachment.FileName = "file.pdf"
+	}
+	if attachment.ContentType == "" {
+		attachment.ContentType = "application/pdf"
+	}
+	if attachment.Size == 0 {
+		attachment.Size = 4
+	}
+	if attachment.Checksum == "" {
+		attachment.Checksum = "9f64a747e1b97f131fabb6b447296c9b6f0201e79fb3c5356e6c77e89b6a806a"
+	}
+	if attachment.StorageKey == "" {
+		attachment.StorageKey = "attachments/" + uuid.New().String()
+	}
+}
+
+// createAttachment inserts an attachment, filling in its file fields
+func createAttachment(t *testing.T, repos Repositories, attachment *domain.Attachment) *domain.Attachment {
+	t.Helper()
+
+	fillAttachment(attachment)
+	if err := repos.Attachments.Create(defaultCtx(), attachment); err != nil {
+		t.Fatalf("create attachment: %v", err)
+	}
+	return attachment
+}
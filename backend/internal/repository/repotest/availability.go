@@ -0,0 +1,246 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testAvailability(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("Windows", func(t *testing.T) {
+		repos := newRepos(t)
+		ann := createMentor(t, repos, "ann", 0)
+		max := createMentor(t, repos, "max", 0)
+		friday := createWindow(t, repos, ann.ID, time.Friday, "09:00", "12:00")
+		mondayLate := createWindow(t, repos, ann.ID, time.Monday, "14:00", "18:00")
+		mondayEarly := createWindow(t, repos, ann.ID, time.Monday, "08:00", "10:00")
+		createWindow(t, repos, max.ID, time.Monday, "08:00", "10:00")
+
+		if friday.ID == "" || friday.CreatedAt.IsZero() {
+			t.Fatalf("CreateWindow did not fill ID and timestamps: %+v", friday)
+		}
+
+		windows, err := repos.Availability.GetWindows(ctx, ann.ID)
+		if err != nil {
+			t.Fatalf("GetWindows: %v", err)
+		}
+		if len(windows) != 3 || windows[0].ID != mondayEarly.ID || windows[1].ID != mondayLate.ID || windows[2].ID != friday.ID {
+			t.Fatalf("GetWindows returned %d windows in wrong order", len(windows))
+		}
+		if windows[2].Weekday != time.Friday || windows[2].StartTime != "09:00" || windows[2].EndTime != "12:00" || windows[2].MentorID != ann.ID {
+			t.Errorf("GetWindows returned %+v", windows[2])
+		}
+
+		if err := repos.Availability.DeleteWindow(ctx, friday.ID, max.ID); !errors.Is(err, domain.ErrWindowNotFound) {
+			t.Errorf("DeleteWindow of another mentor's window error = %v, want ErrWindowNotFound", err)
+		}
+		if err := repos.Availability.DeleteWindow(ctx, friday.ID, ann.ID); err != nil {
+			t.Fatalf("DeleteWindow: %v", err)
+		}
+		if err := repos.Availability.DeleteWindow(ctx, friday.ID, ann.ID); !errors.Is(err, domain.ErrWindowNotFound) {
+			t.Errorf("second DeleteWindow error = %v, want ErrWindowNotFound", err)
+		}
+		windows, _ = repos.Availability.GetWindows(ctx, ann.ID)
+		if len(windows) != 2 {
+			t.Errorf("GetWindows after delete returned %d windows", len(windows))
+		}
+
+		empty, err := repos.Availability.GetWindows(ctx, missingID())
+		if err != nil || empty == nil || len(empty) != 0 {
+			t.Errorf("GetWindows for a mentor without windows = %v, %v; want empty slice", empty, err)
+		}
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		repos := newRepos(t)
+		ann := createMentor(t, repos, "ann", 0)
+
+		reversed := &domain.AvailabilityWindow{MentorID: ann.ID, Weekday: time.Monday, StartTime: "12:00", EndTime: "09:00"}
+		if err := repos.Availability.CreateWindow(ctx, reversed); err == nil {
+			t.Error("CreateWindow ending before it starts succeeded")
+		}
+		orphan := &domain.AvailabilityWindow{MentorID: missingID(), Weekday: time.Monday, StartTime: "09:00", EndTime: "12:00"}
+		if err := repos.Availability.CreateWindow(ctx, orphan); err == nil {
+			t.Error("CreateWindow for a missing mentor succeeded")
+		}
+	})
+
+	t.Run("Absences", func(t *testing.T) {
+		repos := newRepos(t)
+		ann := createMentor(t, repos, "ann", 0)
+		max := createMentor(t, repos, "max", 0)
+		base := time.Date(2030, time.July, 1, 0, 0, 0, 0, time.UTC)
+		summer := createAbsence(t, repos, ann.ID, base.AddDate(0, 0, 14), base.AddDate(0, 0, 28))
+		sick := createAbsence(t, repos, ann.ID, base, base.AddDate(0, 0, 2))
+		other := createAbsence(t, repos, max.ID, base.AddDate(0, 0, 1), base.AddDate(0, 0, 3))
+
+		if summer.ID == "" || summer.CreatedAt.IsZero() {
+			t.Fatalf("CreateAbsence did not fill ID and timestamps: %+v", summer)
+		}
+
+		mine, err := repos.Availability.GetAbsences(ctx, domain.AbsenceFilter{MentorID: ann.ID})
+		if err != nil {
+			t.Fatalf("GetAbsences: %v", err)
+		}
+		if len(mine) != 2 || mine[0].ID != sick.ID || mine[1].ID != summer.ID {
+			t.Fatalf("GetAbsences returned %d absences in wrong order", len(mine))
+		}
+		if mine[0].Kind != domain.AbsenceVacation || mine[0].Note == nil || *mine[0].Note != "away" || !mine[0].StartsAt.Equal(base) {
+			t.Errorf("GetAbsences returned %+v", mine[0])
+		}
+
+		// [day 1, day 2] overlaps the sick leave and max's absence only
+		from, to := base.AddDate(0, 0, 1), base.AddDate(0, 0, 2)
+		overlapping, err := repos.Availability.GetAbsences(ctx, domain.AbsenceFilter{From: &from, To: &to})
+		if err != nil {
+			t.Fatalf("GetAbsences(range): %v", err)
+		}
+		if len(overlapping) != 2 || overlapping[0].ID != sick.ID || overlapping[1].ID != other.ID {
+			t.Errorf("GetAbsences(range) returned %d absences", len(overlapping))
+		}
+
+		// An absence ending exactly at From is over
+		from = base.AddDate(0, 0, 2)
+		later, _ := repos.Availability.GetAbsences(ctx, domain.AbsenceFilter{MentorID: ann.ID, From: &from})
+		if len(later) != 1 || later[0].ID != summer.ID {
+			t.Errorf("GetAbsences(from) returned %d absences", len(later))
+		}
+
+		if err := repos.Availability.DeleteAbsence(ctx, sick.ID, max.ID); !errors.Is(err, domain.ErrAbsenceNotFound) {
+			t.Errorf("DeleteAbsence of another mentor's absence error = %v, want ErrAbsenceNotFound", err)
+		}
+		if err := repos.Availability.DeleteAbsence(ctx, sick.ID, ann.ID); err != nil {
+			t.Fatalf("DeleteAbsence: %v", err)
+		}
+		if err := repos.Availability.DeleteAbsence(ctx, sick.ID, ann.ID); !errors.Is(err, domain.ErrAbsenceNotFound) {
+			t.Errorf("second DeleteAbsence error = %v, want ErrAbsenceNotFound", err)
+		}
+
+		reversed := &domain.Absence{MentorID: ann.ID, Kind: domain.AbsenceOther, StartsAt: base, EndsAt: base}
+		if err := repos.Availability.CreateAbsence(ctx, reversed); err == nil {
+			t.Error("CreateAbsence of an empty period succeeded")
+		}
+	})
+
+	t.Run("UpdateWindow", func(t *testing.T) {
+		repos := newRepos(t)
+		ann := createMentor(t, repos, "ann", 0)
+		max := createMentor(t, repos, "max", 0)
+		window := createWindow(t, repos, ann.ID, time.Monday, "09:00", "12:00")
+		createdAt := window.CreatedAt
+
+		moved := &domain.AvailabilityWindow{ID: window.ID, MentorID: ann.ID, Weekday: time.Tuesday, StartTime: "13:00", EndTime: "17:30"}
+		if err := repos.Availability.UpdateWindow(ctx, moved); err != nil {
+			t.Fatalf("UpdateWindow: %v", err)
+		}
+		if !moved.CreatedAt.Equal(createdAt) {
+			t.Errorf("UpdateWindow CreatedAt = %v, want %v", moved.CreatedAt, createdAt)
+		}
+		windows, _ := repos.Availability.GetWindows(ctx, ann.ID)
+		if len(windows) != 1 || windows[0].Weekday != time.Tuesday || windows[0].StartTime != "13:00" || windows[0].EndTime != "17:30" {
+			t.Errorf("GetWindows after update returned %+v", windows)
+		}
+
+		stolen := &domain.AvailabilityWindow{ID: window.ID, MentorID: max.ID, Weekday: time.Monday, StartTime: "09:00", EndTime: "12:00"}
+		if err := repos.Availability.UpdateWindow(ctx, stolen); !errors.Is(err, domain.ErrWindowNotFound) {
+			t.Errorf("UpdateWindow of another mentor's window error = %v, want ErrWindowNotFound", err)
+		}
+		missing := &domain.AvailabilityWindow{ID: missingID(), MentorID: ann.ID, Weekday: time.Monday, StartTime: "09:00", EndTime: "12:00"}
+		if err := repos.Availability.UpdateWindow(ctx, missing); !errors.Is(err, domain.ErrWindowNotFound) {
+			t.Errorf("UpdateWindow of a missing window error = %v, want ErrWindowNotFound", err)
+		}
+		reversed := &domain.AvailabilityWindow{ID: window.ID, MentorID: ann.ID, Weekday: time.Monday, StartTime: "12:00", EndTime: "09:00"}
+		if err := repos.Availability.UpdateWindow(ctx, reversed); err == nil {
+			t.Error("UpdateWindow ending before it starts succeeded")
+		}
+	})
+
+	t.Run("UpdateAbsence", func(t *testing.T) {
+		repos := newRepos(t)
+		ann := createMentor(t, repos, "ann", 0)
+		max := createMentor(t, repos, "max", 0)
+		base := time.Date(2030, time.July, 1, 0, 0, 0, 0, time.UTC)
+		absence := createAbsence(t, repos, ann.ID, base, base.AddDate(0, 0, 7))
+		createdAt := absence.CreatedAt
+
+		shortened := &domain.Absence{
+			ID:       absence.ID,
+			MentorID: ann.ID,
+			Kind:     domain.AbsenceSickLeave,
+			StartsAt: base,
+			EndsAt:   base.AddDate(0, 0, 3),
+		}
+		if err := repos.Availability.UpdateAbsence(ctx, shortened); err != nil {
+			t.Fatalf("UpdateAbsence: %v", err)
+		}
+		if !shortened.CreatedAt.Equal(createdAt) {
+			t.Errorf("UpdateAbsence CreatedAt = %v, want %v", shortened.CreatedAt, createdAt)
+		}
+		absences, _ := repos.Availability.GetAbsences(ctx, domain.AbsenceFilter{MentorID: ann.ID})
+		if len(absences) != 1 || absences[0].Kind != domain.AbsenceSickLeave || absences[0].Note != nil ||
+			!absences[0].EndsAt.Equal(base.AddDate(0, 0, 3)) {
+			t.Errorf("GetAbsences after update returned %+v", absences)
+		}
+
+		shortened.MentorID = max.ID
+		if err := repos.Availability.UpdateAbsence(ctx, shortened); !errors.Is(err, domain.ErrAbsenceNotFound) {
+			t.Errorf("UpdateAbsence of another mentor's absence error = %v, want ErrAbsenceNotFound", err)
+		}
+		missing := &domain.Absence{ID: missingID(), MentorID: ann.ID, Kind: domain.AbsenceOther, StartsAt: base, EndsAt: base.AddDate(0, 0, 1)}
+		if err := repos.Availability.UpdateAbsence(ctx, missing); !errors.Is(err, domain.ErrAbsenceNotFound) {
+			t.Errorf("UpdateAbsence of a missing absence error = %v, want ErrAbsenceNotFound", err)
+		}
+		empty := &domain.Absence{ID: absence.ID, MentorID: ann.ID, Kind: domain.AbsenceOther, StartsAt: base, EndsAt: base}
+		if err := repos.Availability.UpdateAbsence(ctx, empty); err == nil {
+			t.Error("UpdateAbsence to an empty period succeeded")
+		}
+	})
+
+	t.Run("CascadeOnMentorDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		ann := createMentor(t, repos, "ann", 0)
+		createWindow(t, repos, ann.ID, time.Monday, "09:00", "12:00")
+		createAbsence(t, repos, ann.ID, time.Now(), time.Now().Add(time.Hour))
+
+		if err := repos.Mentors.Delete(ctx, ann.ID); err != nil {
+			t.Fatalf("Delete mentor: %v", err)
+		}
+		windows, _ := repos.Availability.GetWindows(ctx, ann.ID)
+		absences, _ := repos.Availability.GetAbsences(ctx, domain.AbsenceFilter{MentorID: ann.ID})
+		if len(windows) != 0 || len(absences) != 0 {
+			t.Errorf("availability not cascaded: %d windows, %d absences left", len(windows), len(absences))
+		}
+	})
+}
+
+// createWindow inserts a weekly window for mentorID
+func createWindow(t *testing.T, repos Repositories, mentorID string, weekday time.Weekday, start, end string) *domain.AvailabilityWindow {
+	t.Helper()
+
+	window := &domain.AvailabilityWindow{MentorID: mentorID, Weekday: weekday, StartTime: start, EndTime: end}
+	if err := repos.Availability.CreateWindow(defaultCtx(), window); err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+	return window
+}
+
+// createAbsence inserts a vacation for mentorID
+func createAbsence(t *testing.T, repos Repositories, mentorID string, startsAt, endsAt time.Time) *domain.Absence {
+	t.Helper()
+
+	absence := &domain.Absence{
+		MentorID: mentorID,
+		Kind:     domain.AbsenceVacation,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Note:     ptr("away"),
+	}
+	if err := repos.Availability.CreateAbsence(defaultCtx(), absence); err != nil {
+		t.Fatalf("create absence: %v", err)
+	}
+	return absence
+}
@@ -0,0 +1,111 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testCertificates(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+
+		certificate := newCertificate(learning.ID, "AAAA-BBBB-CCCC")
+		if err := repos.Certificates.Create(ctx, certificate); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if certificate.ID == "" {
+			t.Fatal("Create did not fill the ID")
+		}
+
+		byLearning, err := repos.Certificates.GetByLearningID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByLearningID: %v", err)
+		}
+		if byLearning.ID != certificate.ID || byLearning.LearnerName != "Alice" || byLearning.StorageKey != certificate.StorageKey ||
+			len(byLearning.CompletedItems) != 2 || byLearning.CompletedItems[1] != "Write a service" ||
+			!byLearning.EndDate.Equal(certificate.EndDate) || !byLearning.IssuedAt.Equal(certificate.IssuedAt) {
+			t.Errorf("GetByLearningID = %+v, want %+v", byLearning, certificate)
+		}
+
+		byCode, err := repos.Certificates.GetByCode(ctx, "AAAA-BBBB-CCCC")
+		if err != nil {
+			t.Fatalf("GetByCode: %v", err)
+		}
+		if byCode.ID != certificate.ID {
+			t.Errorf("GetByCode = %+v", byCode)
+		}
+
+		if _, err := repos.Certificates.GetByCode(ctx, "ZZZZ-ZZZZ-ZZZZ"); !errors.Is(err, domain.ErrCertificateNotFound) {
+			t.Errorf("GetByCode of an unknown code = %v, want ErrCertificateNotFound", err)
+		}
+		if _, err := repos.Certificates.GetByLearningID(ctx, missingID()); !errors.Is(err, domain.ErrCertificateNotFound) {
+			t.Errorf("GetByLearningID of a missing learning = %v, want ErrCertificateNotFound", err)
+		}
+	})
+
+	t.Run("OnePerLearning", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		other := createLearning(t, repos, alice.ID, mentor.ID, "Rust")
+
+		if err := repos.Certificates.Create(ctx, newCertificate(learning.ID, "AAAA-BBBB-CCCC")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repos.Certificates.Create(ctx, newCertificate(learning.ID, "DDDD-EEEE-FFFF")); !errors.Is(err, domain.ErrCertificateExists) {
+			t.Errorf("second certificate for a learning = %v, want ErrCertificateExists", err)
+		}
+		if err := repos.Certificates.Create(ctx, newCertificate(other.ID, "AAAA-BBBB-CCCC")); !errors.Is(err, domain.ErrCertificateExists) {
+			t.Errorf("certificate with a taken code = %v, want ErrCertificateExists", err)
+		}
+		if err := repos.Certificates.Create(ctx, newCertificate(missingID(), "GGGG-HHHH-JJJJ")); !errors.Is(err, domain.ErrLearningNotFound) {
+			t.Errorf("certificate for a missing learning = %v, want ErrLearningNotFound", err)
+		}
+	})
+
+	t.Run("DeletedWithTheLearner", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		if err := repos.Certificates.Create(ctx, newCertificate(learning.ID, "AAAA-BBBB-CCCC")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete user: %v", err)
+		}
+		if _, err := repos.Certificates.GetByCode(ctx, "AAAA-BBBB-CCCC"); !errors.Is(err, domain.ErrCertificateNotFound) {
+			t.Errorf("certificate of a deleted user = %v, want ErrCertificateNotFound", err)
+		}
+	})
+}
+
+// newCertificate builds a certificate for the learning with a unique
+// storage key
+func newCertificate(learningID, code string) *domain.Certificate {
+	start := time.Now().AddDate(0, -1, 0)
+	return &domain.Certificate{
+		Code:           code,
+		LearningID:     learningID,
+		LearnerName:    "Alice",
+		Topic:          "Go",
+		MentorName:     "Ann",
+		StartDate:      start,
+		EndDate:        start.AddDate(0, 0, 20),
+		CompletedItems: []string{"Read the spec", "Write a service"},
+		StorageKey:     "certificates/" + uuid.New().String(),
+		IssuedAt:       time.Now(),
+	}
+}
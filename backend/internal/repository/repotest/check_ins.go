@@ -0,0 +1,161 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testCheckIns(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		account := createUser(t, repos, "ann")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		other := createLearning(t, repos, alice.ID, mentor.ID, "Rust")
+
+		first := createCheckIn(t, repos, learning.ID, alice.ID, domain.CheckInLearner, 1)
+		if first.ID == "" || first.CreatedAt.IsZero() || first.IsAnswered() {
+			t.Fatalf("Create returned %+v", first)
+		}
+		fromMentor := createCheckIn(t, repos, learning.ID, account.ID, domain.CheckInMentor, 1)
+		second := createCheckIn(t, repos, learning.ID, alice.ID, domain.CheckInLearner, 2)
+		onOther := createCheckIn(t, repos, other.ID, alice.ID, domain.CheckInLearner, 1)
+
+		duplicate := &domain.CheckIn{LearningID: learning.ID, UserID: alice.ID, Role: domain.CheckInLearner, Round: 2}
+		if err := repos.CheckIns.Create(ctx, duplicate); !errors.Is(err, domain.ErrCheckInExists) {
+			t.Errorf("second check-in for the round = %v, want ErrCheckInExists", err)
+		}
+		missing := &domain.CheckIn{LearningID: missingID(), UserID: alice.ID, Role: domain.CheckInLearner, Round: 1}
+		if err := repos.CheckIns.Create(ctx, missing); !errors.Is(err, domain.ErrLearningNotFound) {
+			t.Errorf("check-in on a missing learning = %v, want ErrLearningNotFound", err)
+		}
+
+		got, err := repos.CheckIns.GetByID(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.LearningID != learning.ID || got.UserID != alice.ID || got.Role != domain.CheckInLearner || got.Round != 1 || got.Topic != "Go" || got.Pulse != "" {
+			t.Errorf("GetByID = %+v", got)
+		}
+		if _, err := repos.CheckIns.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrCheckInNotFound) {
+			t.Errorf("GetByID of a missing check-in = %v, want ErrCheckInNotFound", err)
+		}
+
+		byLearning, err := repos.CheckIns.GetByLearningID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByLearningID: %v", err)
+		}
+		if len(byLearning) != 3 || byLearning[0].ID != second.ID || byLearning[1].ID != fromMentor.ID || byLearning[2].ID != first.ID {
+			t.Errorf("GetByLearningID = %+v, want the learning's check-ins newest first", byLearning)
+		}
+
+		if err := repos.CheckIns.Answer(ctx, first.ID, domain.PulseOnTrack, ""); err != nil {
+			t.Fatalf("Answer: %v", err)
+		}
+		pending, err := repos.CheckIns.GetPending(ctx, alice.ID)
+		if err != nil {
+			t.Fatalf("GetPending: %v", err)
+		}
+		if len(pending) != 2 || pending[0].ID != onOther.ID || pending[1].ID != second.ID {
+			t.Errorf("GetPending = %+v, want the unanswered check-ins newest first", pending)
+		}
+	})
+
+	t.Run("Answer", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		checkIn := createCheckIn(t, repos, learning.ID, alice.ID, domain.CheckInLearner, 1)
+
+		if err := repos.CheckIns.Answer(ctx, checkIn.ID, domain.PulseBlocked, "Waiting for access"); err != nil {
+			t.Fatalf("Answer: %v", err)
+		}
+		got, err := repos.CheckIns.GetByID(ctx, checkIn.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !got.IsAnswered() || got.Pulse != domain.PulseBlocked || got.Comment != "Waiting for access" {
+			t.Errorf("answered check-in = %+v", got)
+		}
+
+		if err := repos.CheckIns.Answer(ctx, checkIn.ID, domain.PulseOnTrack, ""); !errors.Is(err, domain.ErrCheckInAnswered) {
+			t.Errorf("second answer = %v, want ErrCheckInAnswered", err)
+		}
+		if err := repos.CheckIns.Answer(ctx, missingID(), domain.PulseOnTrack, ""); !errors.Is(err, domain.ErrCheckInNotFound) {
+			t.Errorf("answer to a missing check-in = %v, want ErrCheckInNotFound", err)
+		}
+	})
+
+	t.Run("StallAlerts", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		past := time.Now().Add(-time.Hour)
+
+		claim := func(since time.Time) bool {
+			t.Helper()
+			ok, err := repos.CheckIns.ClaimStallAlert(ctx, learning.ID, since)
+			if err != nil {
+				t.Fatalf("ClaimStallAlert: %v", err)
+			}
+			return ok
+		}
+		if !claim(past) {
+			t.Fatal("first alert was not claimed")
+		}
+		if claim(past) {
+			t.Error("alert claimed twice for the same stall")
+		}
+		if !claim(time.Now().Add(time.Hour)) {
+			t.Error("alert for a later stall was not claimed")
+		}
+
+		if _, err := repos.CheckIns.ClaimStallAlert(ctx, missingID(), past); !errors.Is(err, domain.ErrLearningNotFound) {
+			t.Errorf("alert on a missing learning = %v, want ErrLearningNotFound", err)
+		}
+	})
+
+	t.Run("UserDeletion", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		account := createUser(t, repos, "ann")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		kept := createLearning(t, repos, createUser(t, repos, "bob").ID, mentor.ID, "Rust")
+
+		createCheckIn(t, repos, learning.ID, alice.ID, domain.CheckInLearner, 1)
+		createCheckIn(t, repos, kept.ID, account.ID, domain.CheckInMentor, 1)
+		if err := repos.Users.Delete(ctx, account.ID); err != nil {
+			t.Fatalf("Delete mentor account: %v", err)
+		}
+		if got, _ := repos.CheckIns.GetByLearningID(ctx, kept.ID); len(got) != 0 {
+			t.Errorf("check-ins of a deleted account = %+v, want none", got)
+		}
+
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete learner: %v", err)
+		}
+		if got, _ := repos.CheckIns.GetPending(ctx, alice.ID); len(got) != 0 {
+			t.Errorf("check-ins of a deleted learner = %+v, want none", got)
+		}
+	})
+}
+
+// createCheckIn inserts an unanswered check-in
+func createCheckIn(t *testing.T, repos Repositories, learningID, userID string, role domain.CheckInRole, round int) *domain.CheckIn {
+	t.Helper()
+
+	checkIn := &domain.CheckIn{LearningID: learningID, UserID: userID, Role: role, Round: round}
+	if err := repos.CheckIns.Create(defaultCtx(), checkIn); err != nil {
+		t.Fatalf("create check-in: %v", err)
+	}
+	return checkIn
+}
@@ -0,0 +1,206 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testComments(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndListOldestFirst", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		admin := createUser(t, repos, "admin")
+		request := createRequest(t, repos, alice.ID, "Go")
+		other := createRequest(t, repos, alice.ID, "Rust")
+
+		empty, err := repos.Comments.GetByTarget(ctx, domain.CommentOnRequest, request.ID, true)
+		if err != nil || empty == nil || len(empty) != 0 {
+			t.Errorf("GetByTarget with no comments = %v, %v; want empty slice", empty, err)
+		}
+
+		first := createComment(t, repos, domain.CommentOnRequest, request.ID, alice.ID, nil, "When can we start?")
+		if first.ID == "" || first.CreatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamp: %+v", first)
+		}
+		reply := createComment(t, repos, domain.CommentOnRequest, request.ID, admin.ID, &first.ID, "Next week")
+		createComment(t, repos, domain.CommentOnRequest, other.ID, alice.ID, nil, "elsewhere")
+
+		comments, err := repos.Comments.GetByTarget(ctx, domain.CommentOnRequest, request.ID, false)
+		if err != nil {
+			t.Fatalf("GetByTarget: %v", err)
+		}
+		if len(comments) != 2 || comments[0].ID != first.ID || comments[1].ID != reply.ID {
+			t.Fatalf("GetByTarget returned %d comments out of order", len(comments))
+		}
+		got := comments[1]
+		if got.TargetType != domain.CommentOnRequest || got.TargetID != request.ID ||
+			got.ParentID == nil || *got.ParentID != first.ID || !got.IsAuthor(admin.ID) ||
+			got.AuthorName != "admin" || got.Body != "Next week" || got.Visibility != domain.VisibilityPublic ||
+			got.EditedAt != nil || got.DeletedAt != nil {
+			t.Errorf("GetByTarget returned %+v", got)
+		}
+
+		byID, err := repos.Comments.GetByID(ctx, first.ID)
+		if err != nil || byID.Body != "When can we start?" || byID.AuthorName != "alice" || byID.ParentID != nil {
+			t.Errorf("GetByID = %+v, %v", byID, err)
+		}
+		if _, err := repos.Comments.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrCommentNotFound) {
+			t.Errorf("GetByID of a missing comment = %v, want ErrCommentNotFound", err)
+		}
+	})
+
+	t.Run("LearningThread", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "mentor", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+
+		comment := createComment(t, repos, domain.CommentOnLearning, learning.ID, alice.ID, nil, "Done with chapter 1")
+
+		comments, err := repos.Comments.GetByTarget(ctx, domain.CommentOnLearning, learning.ID, false)
+		if err != nil || len(comments) != 1 || comments[0].ID != comment.ID || comments[0].TargetType != domain.CommentOnLearning {
+			t.Fatalf("GetByTarget = %+v, %v", comments, err)
+		}
+		// The learning's request has a thread of its own
+		comments, _ = repos.Comments.GetByTarget(ctx, domain.CommentOnRequest, learning.RequestID, true)
+		if len(comments) != 0 {
+			t.Errorf("request thread has %d comments of the learning", len(comments))
+		}
+	})
+
+	t.Run("InternalVisibility", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		request := createRequest(t, repos, alice.ID, "Go")
+
+		public := createComment(t, repos, domain.CommentOnRequest, request.ID, alice.ID, nil, "public")
+		internal := &domain.Comment{
+			TargetType: domain.CommentOnRequest,
+			TargetID:   request.ID,
+			AuthorID:   &alice.ID,
+			Body:       "internal",
+			Visibility: domain.VisibilityInternal,
+		}
+		if err := repos.Comments.Create(ctx, internal); err != nil {
+			t.Fatalf("Create internal: %v", err)
+		}
+
+		comments, _ := repos.Comments.GetByTarget(ctx, domain.CommentOnRequest, request.ID, false)
+		if len(comments) != 1 || comments[0].ID != public.ID {
+			t.Errorf("GetByTarget without internal returned %d comments", len(comments))
+		}
+		comments, _ = repos.Comments.GetByTarget(ctx, domain.CommentOnRequest, request.ID, true)
+		if len(comments) != 2 || !comments[1].IsInternal() {
+			t.Errorf("GetByTarget with internal returned %d comments", len(comments))
+		}
+	})
+
+	t.Run("CreateChecksReferences", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		request := createRequest(t, repos, alice.ID, "Go")
+
+		cases := map[string]*domain.Comment{
+			"missing request":  {TargetType: domain.CommentOnRequest, TargetID: missingID(), AuthorID: &alice.ID, Body: "x", Visibility: domain.VisibilityPublic},
+			"missing learning": {TargetType: domain.CommentOnLearning, TargetID: request.ID, AuthorID: &alice.ID, Body: "x", Visibility: domain.VisibilityPublic},
+			"missing parent":   {TargetType: domain.CommentOnRequest, TargetID: request.ID, ParentID: ptr(missingID()), AuthorID: &alice.ID, Body: "x", Visibility: domain.VisibilityPublic},
+			"missing author":   {TargetType: domain.CommentOnRequest, TargetID: request.ID, AuthorID: ptr(missingID()), Body: "x", Visibility: domain.VisibilityPublic},
+			"bad visibility":   {TargetType: domain.CommentOnRequest, TargetID: request.ID, AuthorID: &alice.ID, Body: "x", Visibility: "secret"},
+		}
+		for name, comment := range cases {
+			if err := repos.Comments.Create(ctx, comment); err == nil {
+				t.Errorf("Create with %s succeeded", name)
+			}
+		}
+	})
+
+	t.Run("UpdateBodyAndDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		request := createRequest(t, repos, alice.ID, "Go")
+		comment := createComment(t, repos, domain.CommentOnRequest, request.ID, alice.ID, nil, "draft")
+
+		if err := repos.Comments.UpdateBody(ctx, comment.ID, "final"); err != nil {
+			t.Fatalf("UpdateBody: %v", err)
+		}
+		got, _ := repos.Comments.GetByID(ctx, comment.ID)
+		if got.Body != "final" || got.EditedAt == nil {
+			t.Errorf("after UpdateBody = %+v", got)
+		}
+
+		if err := repos.Comments.Delete(ctx, comment.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		got, _ = repos.Comments.GetByID(ctx, comment.ID)
+		if got.Body != "" || !got.IsDeleted() {
+			t.Fatalf("after Delete = %+v", got)
+		}
+		deletedAt := *got.DeletedAt
+
+		// A deleted comment stays deleted and cannot be edited
+		if err := repos.Comments.Delete(ctx, comment.ID); err != nil {
+			t.Errorf("Delete again: %v", err)
+		}
+		got, _ = repos.Comments.GetByID(ctx, comment.ID)
+		if !got.DeletedAt.Equal(deletedAt) {
+			t.Errorf("Delete again moved deletedAt from %v to %v", deletedAt, *got.DeletedAt)
+		}
+		if err := repos.Comments.UpdateBody(ctx, comment.ID, "revived"); !errors.Is(err, domain.ErrCommentNotFound) {
+			t.Errorf("UpdateBody of a deleted comment = %v, want ErrCommentNotFound", err)
+		}
+
+		if err := repos.Comments.UpdateBody(ctx, missingID(), "x"); !errors.Is(err, domain.ErrCommentNotFound) {
+			t.Errorf("UpdateBody of a missing comment = %v, want ErrCommentNotFound", err)
+		}
+		if err := repos.Comments.Delete(ctx, missingID()); !errors.Is(err, domain.ErrCommentNotFound) {
+			t.Errorf("Delete of a missing comment = %v, want ErrCommentNotFound", err)
+		}
+	})
+
+	t.Run("DeleteUserKeepsThread", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		admin := createUser(t, repos, "admin")
+		request := createRequest(t, repos, alice.ID, "Go")
+		createComment(t, repos, domain.CommentOnRequest, request.ID, admin.ID, nil, "hello")
+
+		// The comment outlives its author
+		if err := repos.Users.Delete(ctx, admin.ID); err != nil {
+			t.Fatalf("Delete author: %v", err)
+		}
+		comments, _ := repos.Comments.GetByTarget(ctx, domain.CommentOnRequest, request.ID, true)
+		if len(comments) != 1 || comments[0].AuthorID != nil || comments[0].AuthorName != "" || comments[0].Body != "hello" {
+			t.Fatalf("comment after the author was deleted = %+v", comments)
+		}
+
+		// and goes away with the request
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete requester: %v", err)
+		}
+		if _, err := repos.Comments.GetByID(ctx, comments[0].ID); !errors.Is(err, domain.ErrCommentNotFound) {
+			t.Errorf("comment not cascaded: %v", err)
+		}
+	})
+}
+
+// createComment inserts a public comment
+func createComment(t *testing.T, repos Repositories, target domain.CommentTarget, targetID, authorID string, parentID *string, body string) *domain.Comment {
+	t.Helper()
+
+	comment := &domain.Comment{
+		TargetType: target,
+		TargetID:   targetID,
+		ParentID:   parentID,
+		AuthorID:   &authorID,
+		Body:       body,
+		Visibility: domain.VisibilityPublic,
+	}
+	if err := repos.Comments.Create(defaultCtx(), comment); err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	return comment
+}
@@ -0,0 +1,258 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testSkills(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateGetUpdateDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		skill := createSkill(t, repos, "Kubernetes")
+		if skill.ID == "" || skill.CreatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", skill)
+		}
+
+		got, err := repos.Skills.GetByID(ctx, skill.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Kubernetes" || got.Description != "Kubernetes description" {
+			t.Errorf("GetByID = %+v", got)
+		}
+
+		got.Name = "K8s"
+		got.Description = "Container orchestration"
+		if err := repos.Skills.Update(ctx, got); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		updated, _ := repos.Skills.GetByID(ctx, skill.ID)
+		if updated.Name != "K8s" || updated.Description != "Container orchestration" || !updated.CreatedAt.Equal(skill.CreatedAt) {
+			t.Errorf("after Update = %+v", updated)
+		}
+
+		if err := repos.Skills.Delete(ctx, skill.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Skills.GetByID(ctx, skill.ID); !errors.Is(err, domain.ErrSkillNotFound) {
+			t.Errorf("GetByID after Delete = %v, want ErrSkillNotFound", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if err := repos.Skills.Update(ctx, &domain.Skill{ID: missingID(), Name: "Go"}); !errors.Is(err, domain.ErrSkillNotFound) {
+			t.Errorf("Update of a missing skill = %v, want ErrSkillNotFound", err)
+		}
+		if err := repos.Skills.Delete(ctx, missingID()); !errors.Is(err, domain.ErrSkillNotFound) {
+			t.Errorf("Delete of a missing skill = %v, want ErrSkillNotFound", err)
+		}
+	})
+
+	t.Run("NamesUniqueIgnoringCase", func(t *testing.T) {
+		repos := newRepos(t)
+		createSkill(t, repos, "Go")
+		rust := createSkill(t, repos, "Rust")
+
+		if err := repos.Skills.Create(ctx, &domain.Skill{Name: "GO"}); !errors.Is(err, domain.ErrSkillExists) {
+			t.Errorf("Create of a duplicate name = %v, want ErrSkillExists", err)
+		}
+		rust.Name = "go"
+		if err := repos.Skills.Update(ctx, rust); !errors.Is(err, domain.ErrSkillExists) {
+			t.Errorf("Update to a taken name = %v, want ErrSkillExists", err)
+		}
+		rust.Name = "RUST"
+		if err := repos.Skills.Update(ctx, rust); err != nil {
+			t.Errorf("Update changing the case of its own name: %v", err)
+		}
+	})
+
+	t.Run("GetAllOrdersByName", func(t *testing.T) {
+		repos := newRepos(t)
+		createSkill(t, repos, "rust")
+		createSkill(t, repos, "Go")
+		createSkill(t, repos, "Kubernetes")
+
+		skills, err := repos.Skills.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(skills) != 3 || skills[0].Name != "Go" || skills[1].Name != "Kubernetes" || skills[2].Name != "rust" {
+			t.Errorf("GetAll = %+v", skills)
+		}
+	})
+}
+
+func testCompetencies(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("Targets", func(t *testing.T) {
+		repos := newRepos(t)
+		goSkill := createSkill(t, repos, "Go")
+		k8s := createSkill(t, repos, "Kubernetes")
+
+		backend := createTarget(t, repos, &domain.SkillTarget{SkillID: goSkill.ID, Department: ptr("Backend"), Level: 3})
+		if backend.ID == "" || backend.CreatedAt.IsZero() || backend.SkillName != "Go" {
+			t.Fatalf("CreateTarget did not fill the target: %+v", backend)
+		}
+		createTarget(t, repos, &domain.SkillTarget{SkillID: goSkill.ID, Department: ptr("Backend"), JobTitle: ptr("Senior Engineer"), Level: 4})
+		createTarget(t, repos, &domain.SkillTarget{SkillID: k8s.ID, JobTitle: ptr("SRE"), Level: 4})
+
+		duplicate := &domain.SkillTarget{SkillID: goSkill.ID, Department: ptr("BACKEND"), Level: 2}
+		if err := repos.Competencies.CreateTarget(ctx, duplicate); !errors.Is(err, domain.ErrSkillTargetExists) {
+			t.Errorf("CreateTarget of a duplicate scope = %v, want ErrSkillTargetExists", err)
+		}
+		missing := &domain.SkillTarget{SkillID: missingID(), Department: ptr("Backend"), Level: 2}
+		if err := repos.Competencies.CreateTarget(ctx, missing); !errors.Is(err, domain.ErrSkillNotFound) {
+			t.Errorf("CreateTarget for a missing skill = %v, want ErrSkillNotFound", err)
+		}
+		unscoped := &domain.SkillTarget{SkillID: goSkill.ID, Level: 2}
+		if err := repos.Competencies.CreateTarget(ctx, unscoped); err == nil {
+			t.Error("CreateTarget without a department or job title succeeded")
+		}
+
+		targets, err := repos.Competencies.GetTargets(ctx)
+		if err != nil {
+			t.Fatalf("GetTargets: %v", err)
+		}
+		if len(targets) != 3 || targets[0].ID != backend.ID || targets[1].JobTitle == nil ||
+			*targets[1].JobTitle != "Senior Engineer" || targets[2].SkillName != "Kubernetes" {
+			t.Errorf("GetTargets = %+v", targets)
+		}
+
+		if err := repos.Competencies.DeleteTarget(ctx, backend.ID); err != nil {
+			t.Fatalf("DeleteTarget: %v", err)
+		}
+		if err := repos.Competencies.DeleteTarget(ctx, backend.ID); !errors.Is(err, domain.ErrSkillTargetNotFound) {
+			t.Errorf("DeleteTarget twice = %v, want ErrSkillTargetNotFound", err)
+		}
+	})
+
+	t.Run("SaveUserSkillUpserts", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		boss := createUser(t, repos, "boss")
+		goSkill := createSkill(t, repos, "Go")
+		rust := createSkill(t, repos, "Rust")
+
+		self := &domain.UserSkill{UserID: alice.ID, SkillID: goSkill.ID}
+		if err := self.AssessSelf(2); err != nil {
+			t.Fatalf("AssessSelf: %v", err)
+		}
+		saveUserSkill(t, repos, self)
+		if self.UpdatedAt.IsZero() {
+			t.Error("SaveUserSkill did not fill UpdatedAt")
+		}
+		saveUserSkill(t, repos, &domain.UserSkill{UserID: alice.ID, SkillID: rust.ID, Level: 1})
+		saveUserSkill(t, repos, &domain.UserSkill{UserID: boss.ID, SkillID: goSkill.ID, Level: 5})
+
+		if err := self.AssessByManager(3, boss.ID); err != nil {
+			t.Fatalf("AssessByManager: %v", err)
+		}
+		saveUserSkill(t, repos, self)
+
+		levels, err := repos.Competencies.GetUserSkills(ctx, alice.ID)
+		if err != nil {
+			t.Fatalf("GetUserSkills: %v", err)
+		}
+		if len(levels) != 2 || levels[0].SkillName != "Go" || levels[1].SkillName != "Rust" {
+			t.Fatalf("GetUserSkills = %+v", levels)
+		}
+		got := levels[0]
+		if got.Level != 3 || got.SelfLevel == nil || *got.SelfLevel != 2 || got.ManagerLevel == nil || *got.ManagerLevel != 3 ||
+			got.AssessedBy == nil || *got.AssessedBy != boss.ID {
+			t.Errorf("saved user skill = %+v", got)
+		}
+
+		all, err := repos.Competencies.GetAllUserSkills(ctx)
+		if err != nil {
+			t.Fatalf("GetAllUserSkills: %v", err)
+		}
+		if len(all) != 3 {
+			t.Errorf("GetAllUserSkills returned %d levels, want 3", len(all))
+		}
+
+		if err := repos.Competencies.SaveUserSkill(ctx, &domain.UserSkill{UserID: alice.ID, SkillID: missingID(), Level: 1}); !errors.Is(err, domain.ErrSkillNotFound) {
+			t.Errorf("SaveUserSkill for a missing skill = %v, want ErrSkillNotFound", err)
+		}
+		if err := repos.Competencies.SaveUserSkill(ctx, &domain.UserSkill{UserID: alice.ID, SkillID: rust.ID, Level: 6}); err == nil {
+			t.Error("SaveUserSkill above the scale succeeded")
+		}
+	})
+
+	t.Run("Cascades", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		boss := createUser(t, repos, "boss")
+		goSkill := createSkill(t, repos, "Go")
+		rust := createSkill(t, repos, "Rust")
+		createTarget(t, repos, &domain.SkillTarget{SkillID: goSkill.ID, Department: ptr("Backend"), Level: 3})
+
+		assessed := &domain.UserSkill{UserID: alice.ID, SkillID: rust.ID}
+		_ = assessed.AssessByManager(2, boss.ID)
+		saveUserSkill(t, repos, assessed)
+		saveUserSkill(t, repos, &domain.UserSkill{UserID: alice.ID, SkillID: goSkill.ID, Level: 2})
+		saveUserSkill(t, repos, &domain.UserSkill{UserID: boss.ID, SkillID: goSkill.ID, Level: 4})
+
+		// Deleting a skill takes its targets and levels along
+		if err := repos.Skills.Delete(ctx, goSkill.ID); err != nil {
+			t.Fatalf("Delete skill: %v", err)
+		}
+		targets, _ := repos.Competencies.GetTargets(ctx)
+		all, _ := repos.Competencies.GetAllUserSkills(ctx)
+		if len(targets) != 0 || len(all) != 1 || all[0].SkillID != rust.ID {
+			t.Errorf("after deleting the skill targets = %d, levels = %+v", len(targets), all)
+		}
+
+		// Deleting the assessor keeps the assessment
+		if err := repos.Users.Delete(ctx, boss.ID); err != nil {
+			t.Fatalf("Delete assessor: %v", err)
+		}
+		levels, _ := repos.Competencies.GetUserSkills(ctx, alice.ID)
+		if len(levels) != 1 || levels[0].AssessedBy != nil || levels[0].ManagerLevel == nil || *levels[0].ManagerLevel != 2 {
+			t.Errorf("after deleting the assessor = %+v", levels)
+		}
+
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete user: %v", err)
+		}
+		if all, _ := repos.Competencies.GetAllUserSkills(ctx); len(all) != 0 {
+			t.Errorf("levels of a deleted user = %+v", all)
+		}
+	})
+}
+
+// createSkill inserts a skill
+func createSkill(t *testing.T, repos Repositories, name string) *domain.Skill {
+	t.Helper()
+
+	skill := &domain.Skill{Name: name, Description: name + " description"}
+	if err := repos.Skills.Create(defaultCtx(), skill); err != nil {
+		t.Fatalf("create skill: %v", err)
+	}
+	return skill
+}
+
+// createTarget inserts a skill target
+func createTarget(t *testing.T, repos Repositories, target *domain.SkillTarget) *domain.SkillTarget {
+	t.Helper()
+
+	if err := repos.Competencies.CreateTarget(defaultCtx(), target); err != nil {
+		t.Fatalf("create skill target: %v", err)
+	}
+	return target
+}
+
+// saveUserSkill stores a user's level in a skill
+func saveUserSkill(t *testing.T, repos Repositories, userSkill *domain.UserSkill) {
+	t.Helper()
+
+	if err := repos.Competencies.SaveUserSkill(defaultCtx(), userSkill); err != nil {
+		t.Fatalf("save user skill: %v", err)
+	}
+}
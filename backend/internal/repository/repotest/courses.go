@@ -0,0 +1,340 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testCourses(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateGetUpdate", func(t *testing.T) {
+		repos := newRepos(t)
+		opens := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		closes := opens.AddDate(0, 1, 0)
+
+		course := createCourse(t, repos, &domain.Course{
+			Title:              "Kubernetes Basics",
+			Description:        "Pods, deployments and services",
+			Format:             domain.CourseBlended,
+			DurationHours:      16,
+			Provider:           "Cloud Academy",
+			Skills:             []string{"Kubernetes", "Docker"},
+			Capacity:           intPtr(10),
+			EnrollmentOpensAt:  &opens,
+			EnrollmentClosesAt: &closes,
+		})
+		if course.ID == "" || course.CreatedAt.IsZero() || course.SeatsTaken != 0 {
+			t.Fatalf("Create did not fill ID, timestamps and seats: %+v", course)
+		}
+
+		got, err := repos.Courses.GetByID(ctx, course.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Title != "Kubernetes Basics" || got.Format != domain.CourseBlended || got.DurationHours != 16 ||
+			got.Provider != "Cloud Academy" || len(got.Skills) != 2 || got.Skills[0] != "Kubernetes" ||
+			got.Capacity == nil || *got.Capacity != 10 ||
+			got.EnrollmentOpensAt == nil || !got.EnrollmentOpensAt.Equal(opens) ||
+			got.EnrollmentClosesAt == nil || !got.EnrollmentClosesAt.Equal(closes) {
+			t.Errorf("GetByID = %+v", got)
+		}
+		if _, err := repos.Courses.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrCourseNotFound) {
+			t.Errorf("GetByID of a missing course = %v, want ErrCourseNotFound", err)
+		}
+
+		got.Title = "Kubernetes in Practice"
+		got.Capacity = nil
+		got.Skills = []string{}
+		got.EnrollmentOpensAt, got.EnrollmentClosesAt = nil, nil
+		if err := repos.Courses.Update(ctx, got); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		updated, _ := repos.Courses.GetByID(ctx, course.ID)
+		if updated.Title != "Kubernetes in Practice" || updated.Capacity != nil || len(updated.Skills) != 0 ||
+			updated.Skills == nil || updated.EnrollmentOpensAt != nil || updated.EnrollmentClosesAt != nil {
+			t.Errorf("after Update = %+v", updated)
+		}
+
+		got.ID = missingID()
+		if err := repos.Courses.Update(ctx, got); !errors.Is(err, domain.ErrCourseNotFound) {
+			t.Errorf("Update of a missing course = %v, want ErrCourseNotFound", err)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		past, future := now.Add(-48*time.Hour), now.Add(48*time.Hour)
+
+		goCourse := createCourse(t, repos, &domain.Course{Title: "Go Concurrency", Description: "goroutines and channels", Format: domain.CourseOnline, DurationHours: 8, Provider: "GopherGuides", Skills: []string{"Go"}})
+		k8s := createCourse(t, repos, &domain.Course{Title: "Kubernetes Basics", Format: domain.CourseClassroom, DurationHours: 16, Skills: []string{"Kubernetes"}, EnrollmentClosesAt: &past})
+		leadership := createCourse(t, repos, &domain.Course{Title: "Leading Teams", Format: domain.CourseOnline, DurationHours: 4, Provider: "Acme", Skills: []string{"Leadership", "go-to-market"}, EnrollmentOpensAt: &future})
+
+		ids := func(filter domain.CourseFilter) []string {
+			t.Helper()
+			courses, err := repos.Courses.Search(ctx, filter)
+			if err != nil {
+				t.Fatalf("Search(%+v): %v", filter, err)
+			}
+			var ids []string
+			for _, c := range courses {
+				ids = append(ids, c.ID)
+			}
+			return ids
+		}
+		online := domain.CourseOnline
+
+		for name, tc := range map[string]struct {
+			filter domain.CourseFilter
+			want   []string
+		}{
+			"all by title":         {domain.CourseFilter{}, []string{goCourse.ID, k8s.ID, leadership.ID}},
+			"query in title":       {domain.CourseFilter{Query: "KUBERNETES"}, []string{k8s.ID}},
+			"query in description": {domain.CourseFilter{Query: "channels"}, []string{goCourse.ID}},
+			"query in provider":    {domain.CourseFilter{Query: "acme"}, []string{leadership.ID}},
+			"skill ignores case":   {domain.CourseFilter{Skill: "go"}, []string{goCourse.ID}},
+			"format":               {domain.CourseFilter{Format: &online}, []string{goCourse.ID, leadership.ID}},
+			"open now":             {domain.CourseFilter{OpenAt: &now}, []string{goCourse.ID}},
+			"combined":             {domain.CourseFilter{Query: "go", Format: &online, OpenAt: &now}, []string{goCourse.ID}},
+			"no match":             {domain.CourseFilter{Query: "rust"}, nil},
+		} {
+			got := ids(tc.filter)
+			if len(got) != len(tc.want) {
+				t.Errorf("%s: Search returned %d courses, want %d", name, len(got), len(tc.want))
+				continue
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("%s: Search returned courses out of order", name)
+					break
+				}
+			}
+		}
+
+		courses, err := repos.Courses.Search(ctx, domain.CourseFilter{Query: "rust"})
+		if err != nil || courses == nil {
+			t.Errorf("Search with no match = %v, %v; want empty slice", courses, err)
+		}
+	})
+
+	t.Run("DeleteKeepsCoursesInUse", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		used := createCourse(t, repos, &domain.Course{Title: "Used", Format: domain.CourseOnline, DurationHours: 1})
+		unused := createCourse(t, repos, &domain.Course{Title: "Unused", Format: domain.CourseOnline, DurationHours: 1})
+		createCourseRequest(t, repos, alice.ID, used)
+
+		if err := repos.Courses.Delete(ctx, used.ID); !errors.Is(err, domain.ErrCourseInUse) {
+			t.Errorf("Delete of a course with requests = %v, want ErrCourseInUse", err)
+		}
+		if _, err := repos.Courses.GetByID(ctx, used.ID); err != nil {
+			t.Errorf("course in use was deleted: %v", err)
+		}
+		if err := repos.Courses.Delete(ctx, unused.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Courses.Delete(ctx, unused.ID); !errors.Is(err, domain.ErrCourseNotFound) {
+			t.Errorf("second Delete = %v, want ErrCourseNotFound", err)
+		}
+	})
+
+	t.Run("RequestsReferenceCourses", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		course := createCourse(t, repos, &domain.Course{Title: "Go", Format: domain.CourseOnline, DurationHours: 1})
+
+		request := createCourseRequest(t, repos, alice.ID, course)
+		got, err := repos.Requests.GetByID(ctx, request.ID)
+		if err != nil || got.CourseID == nil || *got.CourseID != course.ID || !got.IsCourseEnrollment() {
+			t.Errorf("GetByID of a course request = %+v, %v", got, err)
+		}
+		plain := createRequest(t, repos, alice.ID, "Mentoring")
+		if got, _ := repos.Requests.GetByID(ctx, plain.ID); got.CourseID != nil {
+			t.Errorf("plain request has course %v", *got.CourseID)
+		}
+
+		bad := &domain.TrainingRequest{UserID: alice.ID, Topic: "x", Description: "x", Status: domain.RequestPending, CourseID: ptr(missingID())}
+		if err := repos.Requests.Create(ctx, bad); err == nil {
+			t.Error("Create with a missing course succeeded")
+		}
+	})
+}
+
+func testEnrollments(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateTakesSeats", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		carol := createUser(t, repos, "carol")
+		course := createCourse(t, repos, &domain.Course{Title: "Go", Format: domain.CourseOnline, DurationHours: 8, Capacity: intPtr(2)})
+
+		first := createEnrollment(t, repos, alice.ID, course)
+		if first.ID == "" || first.Status != domain.EnrollmentActive || first.EnrolledAt.IsZero() {
+			t.Fatalf("Create did not fill the enrollment: %+v", first)
+		}
+		createEnrollment(t, repos, bob.ID, course)
+
+		full := &domain.CourseEnrollment{CourseID: course.ID, UserID: carol.ID, RequestID: createCourseRequest(t, repos, carol.ID, course).ID}
+		if err := repos.Enrollments.Create(ctx, full); !errors.Is(err, domain.ErrCourseFull) {
+			t.Errorf("Create on a full course = %v, want ErrCourseFull", err)
+		}
+		got, _ := repos.Courses.GetByID(ctx, course.ID)
+		if got.SeatsTaken != 2 || !got.IsFull() {
+			t.Errorf("SeatsTaken = %d, want 2", got.SeatsTaken)
+		}
+
+		missing := &domain.CourseEnrollment{CourseID: missingID(), UserID: carol.ID, RequestID: full.RequestID}
+		if err := repos.Enrollments.Create(ctx, missing); !errors.Is(err, domain.ErrCourseNotFound) {
+			t.Errorf("Create on a missing course = %v, want ErrCourseNotFound", err)
+		}
+
+		// Cancelling frees the seat
+		if err := repos.Enrollments.Cancel(ctx, first.ID); err != nil {
+			t.Fatalf("Cancel: %v", err)
+		}
+		if err := repos.Enrollments.Cancel(ctx, first.ID); !errors.Is(err, domain.ErrEnrollmentNotActive) {
+			t.Errorf("second Cancel = %v, want ErrEnrollmentNotActive", err)
+		}
+		if err := repos.Enrollments.Create(ctx, full); err != nil {
+			t.Fatalf("Create after a cancellation: %v", err)
+		}
+		got, _ = repos.Courses.GetByID(ctx, course.ID)
+		if got.SeatsTaken != 2 {
+			t.Errorf("SeatsTaken after cancel and enroll = %d, want 2", got.SeatsTaken)
+		}
+	})
+
+	t.Run("UnlimitedCapacity", func(t *testing.T) {
+		repos := newRepos(t)
+		course := createCourse(t, repos, &domain.Course{Title: "Go", Format: domain.CourseSelfPaced, DurationHours: 8})
+		for _, name := range []string{"alice", "bob", "carol"} {
+			createEnrollment(t, repos, createUser(t, repos, name).ID, course)
+		}
+		got, _ := repos.Courses.GetByID(ctx, course.ID)
+		if got.SeatsTaken != 3 || got.IsFull() {
+			t.Errorf("SeatsTaken = %d, full = %v; want 3, false", got.SeatsTaken, got.IsFull())
+		}
+	})
+
+	t.Run("CompleteAndList", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		goCourse := createCourse(t, repos, &domain.Course{Title: "Go", Format: domain.CourseOnline, DurationHours: 8, Capacity: intPtr(5)})
+		k8s := createCourse(t, repos, &domain.Course{Title: "Kubernetes", Format: domain.CourseOnline, DurationHours: 8})
+
+		first := createEnrollment(t, repos, alice.ID, goCourse)
+		second := createEnrollment(t, repos, alice.ID, k8s)
+		createEnrollment(t, repos, bob.ID, goCourse)
+
+		if err := repos.Enrollments.Complete(ctx, first.ID, domain.Feedback{Rating: 4, Comment: "useful"}); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		if err := repos.Enrollments.Complete(ctx, first.ID, domain.Feedback{Rating: 5}); !errors.Is(err, domain.ErrEnrollmentNotActive) {
+			t.Errorf("second Complete = %v, want ErrEnrollmentNotActive", err)
+		}
+		if err := repos.Enrollments.Cancel(ctx, first.ID); !errors.Is(err, domain.ErrEnrollmentNotActive) {
+			t.Errorf("Cancel of a completed enrollment = %v, want ErrEnrollmentNotActive", err)
+		}
+
+		got, err := repos.Enrollments.GetByID(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.EnrollmentCompleted || got.CompletedAt == nil || got.Feedback == nil ||
+			got.Feedback.Rating != 4 || got.Feedback.Comment != "useful" ||
+			got.CourseTitle != "Go" || got.UserName != "alice" || got.RequestID != first.RequestID {
+			t.Errorf("GetByID after Complete = %+v", got)
+		}
+		if course, _ := repos.Courses.GetByID(ctx, goCourse.ID); course.SeatsTaken != 2 {
+			t.Errorf("completing freed a seat: SeatsTaken = %d, want 2", course.SeatsTaken)
+		}
+		if _, err := repos.Enrollments.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrEnrollmentNotFound) {
+			t.Errorf("GetByID of a missing enrollment = %v, want ErrEnrollmentNotFound", err)
+		}
+
+		mine, err := repos.Enrollments.GetByUserID(ctx, alice.ID)
+		if err != nil || len(mine) != 2 || mine[0].ID != second.ID || mine[1].ID != first.ID {
+			t.Errorf("GetByUserID = %d enrollments, %v; want newest first", len(mine), err)
+		}
+		roster, err := repos.Enrollments.GetByCourseID(ctx, goCourse.ID)
+		if err != nil || len(roster) != 2 || roster[0].ID != first.ID || roster[1].UserName != "bob" {
+			t.Errorf("GetByCourseID = %d enrollments, %v; want oldest first", len(roster), err)
+		}
+		none, err := repos.Enrollments.GetByUserID(ctx, missingID())
+		if err != nil || none == nil || len(none) != 0 {
+			t.Errorf("GetByUserID of a stranger = %v, %v; want empty slice", none, err)
+		}
+	})
+
+	t.Run("DeletingUserFreesSeat", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		course := createCourse(t, repos, &domain.Course{Title: "Go", Format: domain.CourseOnline, DurationHours: 8, Capacity: intPtr(1)})
+		enrollment := createEnrollment(t, repos, alice.ID, course)
+
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete user: %v", err)
+		}
+		if _, err := repos.Enrollments.GetByID(ctx, enrollment.ID); !errors.Is(err, domain.ErrEnrollmentNotFound) {
+			t.Errorf("enrollment of a deleted user = %v, want ErrEnrollmentNotFound", err)
+		}
+		createEnrollment(t, repos, bob.ID, course)
+		if err := repos.Courses.Delete(ctx, course.ID); !errors.Is(err, domain.ErrCourseInUse) {
+			t.Errorf("Delete of a course with enrollments = %v, want ErrCourseInUse", err)
+		}
+	})
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// createCourse inserts a course
+func createCourse(t *testing.T, repos Repositories, course *domain.Course) *domain.Course {
+	t.Helper()
+
+	if err := repos.Courses.Create(defaultCtx(), course); err != nil {
+		t.Fatalf("create course: %v", err)
+	}
+	return course
+}
+
+// createCourseRequest inserts an approved enrollment request for the course
+func createCourseRequest(t *testing.T, repos Repositories, userID string, course *domain.Course) *domain.TrainingRequest {
+	t.Helper()
+
+	request := &domain.TrainingRequest{
+		UserID:      userID,
+		Topic:       course.Title,
+		Description: "Enrollment in " + course.Title,
+		Status:      domain.RequestApproved,
+		CourseID:    &course.ID,
+	}
+	if err := repos.Requests.Create(defaultCtx(), request); err != nil {
+		t.Fatalf("create course request: %v", err)
+	}
+	return request
+}
+
+// createEnrollment enrolls the user through a new request
+func createEnrollment(t *testing.T, repos Repositories, userID string, course *domain.Course) *domain.CourseEnrollment {
+	t.Helper()
+
+	enrollment := &domain.CourseEnrollment{
+		CourseID:  course.ID,
+		UserID:    userID,
+		RequestID: createCourseRequest(t, repos, userID, course).ID,
+	}
+	if err := repos.Enrollments.Create(defaultCtx(), enrollment); err != nil {
+		t.Fatalf("create enrollment: %v", err)
+	}
+	return enrollment
+}
@@ -0,0 +1,163 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testDepartments(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateGetUpdateDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		head := createUser(t, repos, "hank")
+		engineering := createDepartment(t, repos, "Engineering", nil)
+		if engineering.ID == "" || engineering.CreatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", engineering)
+		}
+		platform := createDepartment(t, repos, "Platform", &engineering.ID)
+
+		got, err := repos.Departments.GetByID(ctx, platform.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Platform" || got.ParentID == nil || *got.ParentID != engineering.ID || got.HeadID != nil {
+			t.Errorf("GetByID = %+v", got)
+		}
+		byName, err := repos.Departments.GetByName(ctx, "PLATFORM")
+		if err != nil || byName.ID != platform.ID {
+			t.Errorf("GetByName ignoring case = %+v, %v", byName, err)
+		}
+
+		got.Name = "Platform Engineering"
+		got.ParentID = nil
+		got.HeadID = &head.ID
+		if err := repos.Departments.Update(ctx, got); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		updated, _ := repos.Departments.GetByID(ctx, platform.ID)
+		if updated.Name != "Platform Engineering" || updated.ParentID != nil || updated.HeadID == nil || *updated.HeadID != head.ID ||
+			!updated.CreatedAt.Equal(platform.CreatedAt) {
+			t.Errorf("after Update = %+v", updated)
+		}
+
+		if err := repos.Departments.Delete(ctx, platform.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Departments.GetByID(ctx, platform.ID); !errors.Is(err, domain.ErrDepartmentNotFound) {
+			t.Errorf("GetByID after Delete = %v, want ErrDepartmentNotFound", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if _, err := repos.Departments.GetByName(ctx, "Nowhere"); !errors.Is(err, domain.ErrDepartmentNotFound) {
+			t.Errorf("GetByName of a missing department = %v, want ErrDepartmentNotFound", err)
+		}
+		if err := repos.Departments.Update(ctx, &domain.Department{ID: missingID(), Name: "Sales"}); !errors.Is(err, domain.ErrDepartmentNotFound) {
+			t.Errorf("Update of a missing department = %v, want ErrDepartmentNotFound", err)
+		}
+		if err := repos.Departments.Delete(ctx, missingID()); !errors.Is(err, domain.ErrDepartmentNotFound) {
+			t.Errorf("Delete of a missing department = %v, want ErrDepartmentNotFound", err)
+		}
+	})
+
+	t.Run("NamesUniqueIgnoringCase", func(t *testing.T) {
+		repos := newRepos(t)
+		createDepartment(t, repos, "Sales", nil)
+		marketing := createDepartment(t, repos, "Marketing", nil)
+
+		if err := repos.Departments.Create(ctx, &domain.Department{Name: "SALES"}); !errors.Is(err, domain.ErrDepartmentExists) {
+			t.Errorf("Create of a duplicate name = %v, want ErrDepartmentExists", err)
+		}
+		marketing.Name = "sales"
+		if err := repos.Departments.Update(ctx, marketing); !errors.Is(err, domain.ErrDepartmentExists) {
+			t.Errorf("Update to a taken name = %v, want ErrDepartmentExists", err)
+		}
+	})
+
+	t.Run("References", func(t *testing.T) {
+		repos := newRepos(t)
+
+		orphan := &domain.Department{Name: "Orphan", ParentID: ptr(missingID())}
+		if err := repos.Departments.Create(ctx, orphan); err == nil {
+			t.Error("Create below a missing parent succeeded")
+		}
+		headless := &domain.Department{Name: "Headless", HeadID: ptr(missingID())}
+		if err := repos.Departments.Create(ctx, headless); err == nil {
+			t.Error("Create with a missing head succeeded")
+		}
+		sales := createDepartment(t, repos, "Sales", nil)
+		sales.ParentID = &sales.ID
+		if err := repos.Departments.Update(ctx, sales); err == nil {
+			t.Error("Update making a department its own parent succeeded")
+		}
+	})
+
+	t.Run("DeleteInUse", func(t *testing.T) {
+		repos := newRepos(t)
+		engineering := createDepartment(t, repos, "Engineering", nil)
+		platform := createDepartment(t, repos, "Platform", &engineering.ID)
+
+		if err := repos.Departments.Delete(ctx, engineering.ID); !errors.Is(err, domain.ErrDepartmentInUse) {
+			t.Errorf("Delete of a department with subdepartments = %v, want ErrDepartmentInUse", err)
+		}
+
+		user := createUser(t, repos, "alice")
+		user.DepartmentID = &platform.ID
+		if err := repos.Users.Update(ctx, user); err != nil {
+			t.Fatalf("Update user: %v", err)
+		}
+		if err := repos.Departments.Delete(ctx, platform.ID); !errors.Is(err, domain.ErrDepartmentInUse) {
+			t.Errorf("Delete of a department with members = %v, want ErrDepartmentInUse", err)
+		}
+	})
+
+	t.Run("DeletingHeadLeavesDepartment", func(t *testing.T) {
+		repos := newRepos(t)
+		head := createUser(t, repos, "hank")
+		sales := createDepartment(t, repos, "Sales", nil)
+		sales.HeadID = &head.ID
+		if err := repos.Departments.Update(ctx, sales); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		if err := repos.Users.Delete(ctx, head.ID); err != nil {
+			t.Fatalf("Delete head: %v", err)
+		}
+		got, err := repos.Departments.GetByID(ctx, sales.ID)
+		if err != nil || got.HeadID != nil {
+			t.Errorf("department after deleting its head = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("GetAllOrdersByName", func(t *testing.T) {
+		repos := newRepos(t)
+		createDepartment(t, repos, "sales", nil)
+		createDepartment(t, repos, "Engineering", nil)
+		createDepartment(t, repos, "Marketing", nil)
+
+		departments, err := repos.Departments.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(departments) != 3 || departments[0].Name != "Engineering" || departments[1].Name != "Marketing" || departments[2].Name != "sales" {
+			t.Errorf("GetAll = %+v", departments)
+		}
+	})
+}
+
+// createDepartment inserts a department below parentID, or at the top when it
+// is nil
+func createDepartment(t *testing.T, repos Repositories, name string, parentID *string) *domain.Department {
+	t.Helper()
+
+	department := &domain.Department{Name: name, ParentID: parentID}
+	if err := repos.Departments.Create(defaultCtx(), department); err != nil {
+		t.Fatalf("create department: %v", err)
+	}
+	return department
+}
@@ -0,0 +1,285 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testQuestionnaires(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CRUD", func(t *testing.T) {
+		repos := newRepos(t)
+
+		questionnaire := newQuestionnaire("Mentor feedback", domain.FeedbackFromLearner)
+		if err := repos.Questionnaires.Create(ctx, questionnaire); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if questionnaire.ID == "" || questionnaire.Active || questionnaire.CreatedAt.IsZero() {
+			t.Fatalf("Create = %+v, want an inactive questionnaire with an ID", questionnaire)
+		}
+
+		got, err := repos.Questionnaires.GetByID(ctx, questionnaire.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Mentor feedback" || got.Audience != domain.FeedbackFromLearner || len(got.Criteria) != 2 ||
+			got.Criteria[1] != questionnaire.Criteria[1] {
+			t.Errorf("GetByID = %+v", got)
+		}
+
+		questionnaire.Name = "Mentor feedback v2"
+		questionnaire.Criteria = questionnaire.Criteria[:1]
+		if err := repos.Questionnaires.Update(ctx, questionnaire); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, _ = repos.Questionnaires.GetByID(ctx, questionnaire.ID)
+		if got.Name != "Mentor feedback v2" || len(got.Criteria) != 1 {
+			t.Errorf("after Update = %+v", got)
+		}
+
+		mentorForm := newQuestionnaire("Learner assessment", domain.FeedbackFromMentor)
+		if err := repos.Questionnaires.Create(ctx, mentorForm); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		all, err := repos.Questionnaires.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 2 || all[0].ID != questionnaire.ID || all[1].ID != mentorForm.ID {
+			t.Errorf("GetAll = %+v, want the learner questionnaire first", all)
+		}
+
+		if err := repos.Questionnaires.Delete(ctx, questionnaire.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Questionnaires.GetByID(ctx, questionnaire.ID); !errors.Is(err, domain.ErrQuestionnaireNotFound) {
+			t.Errorf("GetByID after Delete = %v, want ErrQuestionnaireNotFound", err)
+		}
+		if err := repos.Questionnaires.Delete(ctx, missingID()); !errors.Is(err, domain.ErrQuestionnaireNotFound) {
+			t.Errorf("Delete of a missing questionnaire = %v, want ErrQuestionnaireNotFound", err)
+		}
+		questionnaire.ID = missingID()
+		if err := repos.Questionnaires.Update(ctx, questionnaire); !errors.Is(err, domain.ErrQuestionnaireNotFound) {
+			t.Errorf("Update of a missing questionnaire = %v, want ErrQuestionnaireNotFound", err)
+		}
+	})
+
+	t.Run("OneActivePerAudience", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if _, err := repos.Questionnaires.GetActive(ctx, domain.FeedbackFromLearner); !errors.Is(err, domain.ErrQuestionnaireNotFound) {
+			t.Errorf("GetActive without questionnaires = %v, want ErrQuestionnaireNotFound", err)
+		}
+
+		first := newQuestionnaire("First", domain.FeedbackFromLearner)
+		second := newQuestionnaire("Second", domain.FeedbackFromLearner)
+		mentorForm := newQuestionnaire("Assessment", domain.FeedbackFromMentor)
+		for _, q := range []*domain.Questionnaire{first, second, mentorForm} {
+			if err := repos.Questionnaires.Create(ctx, q); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		activate := func(id string) error {
+			return repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				return repos.Questionnaires.Activate(ctx, id)
+			})
+		}
+		for _, id := range []string{mentorForm.ID, first.ID, second.ID} {
+			if err := activate(id); err != nil {
+				t.Fatalf("Activate: %v", err)
+			}
+		}
+		if err := activate(second.ID); err != nil {
+			t.Errorf("Activate of the active questionnaire = %v", err)
+		}
+
+		active, err := repos.Questionnaires.GetActive(ctx, domain.FeedbackFromLearner)
+		if err != nil || active.ID != second.ID {
+			t.Errorf("GetActive(learner) = %+v, %v, want the second questionnaire", active, err)
+		}
+		if got, _ := repos.Questionnaires.GetByID(ctx, first.ID); got.Active {
+			t.Error("the replaced questionnaire is still active")
+		}
+		active, err = repos.Questionnaires.GetActive(ctx, domain.FeedbackFromMentor)
+		if err != nil || active.ID != mentorForm.ID {
+			t.Errorf("GetActive(mentor) = %+v, %v, want the mentor questionnaire", active, err)
+		}
+
+		if err := activate(missingID()); !errors.Is(err, domain.ErrQuestionnaireNotFound) {
+			t.Errorf("Activate of a missing questionnaire = %v, want ErrQuestionnaireNotFound", err)
+		}
+	})
+}
+
+func testFeedback(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		account := createUser(t, repos, "ann")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		other := createLearning(t, repos, alice.ID, mentor.ID, "Rust")
+		learnerForm := createQuestionnaire(t, repos, domain.FeedbackFromLearner)
+		mentorForm := createQuestionnaire(t, repos, domain.FeedbackFromMentor)
+
+		fromLearner := newResponse(learning, learnerForm, domain.FeedbackFromLearner, alice.ID)
+		fromLearner.Anonymous = true
+		fromMentor := newResponse(learning, mentorForm, domain.FeedbackFromMentor, account.ID)
+		onOther := newResponse(other, learnerForm, domain.FeedbackFromLearner, alice.ID)
+		for _, response := range []*domain.FeedbackResponse{fromLearner, fromMentor, onOther} {
+			if err := repos.Feedback.Create(ctx, response); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if response.ID == "" || response.CreatedAt.IsZero() {
+				t.Fatalf("Create did not fill the ID and time: %+v", response)
+			}
+		}
+
+		byLearning, err := repos.Feedback.GetByLearningID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByLearningID: %v", err)
+		}
+		if len(byLearning) != 2 || byLearning[0].ID != fromLearner.ID || byLearning[1].ID != fromMentor.ID {
+			t.Fatalf("GetByLearningID = %+v, want both responses oldest first", byLearning)
+		}
+		got := byLearning[0]
+		if got.AuthorName != "alice" || got.AuthorID == nil || *got.AuthorID != alice.ID || !got.Anonymous ||
+			got.Answers["clarity"] != 4 || got.Answers["recommend"] != 9 || got.Comment != "Thanks" ||
+			got.MentorID != mentor.ID || got.QuestionnaireID != learnerForm.ID || got.Audience != domain.FeedbackFromLearner {
+			t.Errorf("learner response = %+v", got)
+		}
+
+		byMentor, err := repos.Feedback.GetByMentorID(ctx, mentor.ID)
+		if err != nil {
+			t.Fatalf("GetByMentorID: %v", err)
+		}
+		if len(byMentor) != 3 || byMentor[0].ID != onOther.ID || byMentor[2].ID != fromLearner.ID {
+			t.Errorf("GetByMentorID = %+v, want all responses newest first", byMentor)
+		}
+
+		if empty, err := repos.Feedback.GetByLearningID(ctx, missingID()); err != nil || len(empty) != 0 {
+			t.Errorf("GetByLearningID of a missing learning = %v, %v", empty, err)
+		}
+	})
+
+	t.Run("OnePerAudience", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		learnerForm := createQuestionnaire(t, repos, domain.FeedbackFromLearner)
+
+		if err := repos.Feedback.Create(ctx, newResponse(learning, learnerForm, domain.FeedbackFromLearner, alice.ID)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		err := repos.Feedback.Create(ctx, newResponse(learning, learnerForm, domain.FeedbackFromLearner, alice.ID))
+		if !errors.Is(err, domain.ErrFeedbackExists) {
+			t.Errorf("second learner response = %v, want ErrFeedbackExists", err)
+		}
+
+		missing := newResponse(learning, learnerForm, domain.FeedbackFromLearner, alice.ID)
+		missing.LearningID = missingID()
+		if err := repos.Feedback.Create(ctx, missing); !errors.Is(err, domain.ErrLearningNotFound) {
+			t.Errorf("response on a missing learning = %v, want ErrLearningNotFound", err)
+		}
+	})
+
+	t.Run("AnsweredQuestionnairesStay", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "ann", 0)
+		learning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		learnerForm := createQuestionnaire(t, repos, domain.FeedbackFromLearner)
+
+		if err := repos.Feedback.Create(ctx, newResponse(learning, learnerForm, domain.FeedbackFromLearner, alice.ID)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		learnerForm.Name = "Renamed"
+		if err := repos.Questionnaires.Update(ctx, learnerForm); !errors.Is(err, domain.ErrQuestionnaireInUse) {
+			t.Errorf("Update of an answered questionnaire = %v, want ErrQuestionnaireInUse", err)
+		}
+		if err := repos.Questionnaires.Delete(ctx, learnerForm.ID); !errors.Is(err, domain.ErrQuestionnaireInUse) {
+			t.Errorf("Delete of an answered questionnaire = %v, want ErrQuestionnaireInUse", err)
+		}
+	})
+
+	t.Run("UserDeletion", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		account := createUser(t, repos, "ann")
+		mentor := createMentor(t, repos, "ann", 0)
+		aliceLearning := createLearning(t, repos, alice.ID, mentor.ID, "Go")
+		bobLearning := createLearning(t, repos, bob.ID, mentor.ID, "Go")
+		learnerForm := createQuestionnaire(t, repos, domain.FeedbackFromLearner)
+		mentorForm := createQuestionnaire(t, repos, domain.FeedbackFromMentor)
+
+		for _, response := range []*domain.FeedbackResponse{
+			newResponse(aliceLearning, learnerForm, domain.FeedbackFromLearner, alice.ID),
+			newResponse(bobLearning, mentorForm, domain.FeedbackFromMentor, account.ID),
+		} {
+			if err := repos.Feedback.Create(ctx, response); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		// The learner's responses go with the learning; others lose their author
+		for _, id := range []string{alice.ID, account.ID} {
+			if err := repos.Users.Delete(ctx, id); err != nil {
+				t.Fatalf("Delete user: %v", err)
+			}
+		}
+
+		remaining, err := repos.Feedback.GetByMentorID(ctx, mentor.ID)
+		if err != nil {
+			t.Fatalf("GetByMentorID: %v", err)
+		}
+		if len(remaining) != 1 || remaining[0].LearningID != bobLearning.ID || remaining[0].AuthorID != nil || remaining[0].AuthorName != "" {
+			t.Errorf("responses after deleting users = %+v", remaining)
+		}
+	})
+}
+
+// newQuestionnaire builds a questionnaire with two criteria
+func newQuestionnaire(name string, audience domain.FeedbackAudience) *domain.Questionnaire {
+	return &domain.Questionnaire{
+		Name:     name,
+		Audience: audience,
+		Criteria: []domain.FeedbackCriterion{
+			{Key: "clarity", Label: "Clarity", Min: 1, Max: 5, Required: true},
+			{Key: "recommend", Label: "Would you recommend?", Min: 0, Max: 10},
+		},
+	}
+}
+
+// createQuestionnaire inserts an inactive questionnaire for the audience
+func createQuestionnaire(t *testing.T, repos Repositories, audience domain.FeedbackAudience) *domain.Questionnaire {
+	t.Helper()
+
+	questionnaire := newQuestionnaire(string(audience)+" questionnaire", audience)
+	if err := repos.Questionnaires.Create(defaultCtx(), questionnaire); err != nil {
+		t.Fatalf("create questionnaire: %v", err)
+	}
+	return questionnaire
+}
+
+// newResponse builds answers to the questionnaire on the learning
+func newResponse(learning *domain.LearningProcess, questionnaire *domain.Questionnaire, audience domain.FeedbackAudience, authorID string) *domain.FeedbackResponse {
+	return &domain.FeedbackResponse{
+		LearningID:      learning.ID,
+		QuestionnaireID: questionnaire.ID,
+		Audience:        audience,
+		AuthorID:        ptr(authorID),
+		MentorID:        learning.MentorID,
+		Answers:         map[string]int{"clarity": 4, "recommend": 9},
+		Comment:         "Thanks",
+	}
+}
@@ -0,0 +1,311 @@
+package repotest
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testJobs(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+	kinds := []string{"email", "digest"}
+
+	t.Run("EnqueueAndList", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+
+		first := enqueueJob(t, repos, "email", "", now)
+		if first.ID == "" || first.Status != domain.JobPending || first.Attempts != 0 || first.CreatedAt.IsZero() {
+			t.Fatalf("Enqueue returned %+v", first)
+		}
+		second := enqueueJob(t, repos, "digest", "digest:alice", now)
+
+		got, err := repos.Jobs.GetByID(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		var payload struct{ To string }
+		if err := json.Unmarshal(got.Payload, &payload); err != nil || payload.To != "alice" {
+			t.Errorf("payload = %s, %v", got.Payload, err)
+		}
+		if got.Kind != "email" || got.MaxAttempts != 3 || got.Status != domain.JobPending {
+			t.Errorf("GetByID = %+v", got)
+		}
+		if _, err := repos.Jobs.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrJobNotFound) {
+			t.Errorf("GetByID of a missing job = %v, want ErrJobNotFound", err)
+		}
+
+		all, err := repos.Jobs.List(ctx, domain.JobFilter{})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(all) != 2 || all[0].ID != second.ID || all[1].ID != first.ID {
+			t.Errorf("List = %+v, want both jobs newest first", all)
+		}
+		digests, _ := repos.Jobs.List(ctx, domain.JobFilter{Kind: "digest", Status: domain.JobPending})
+		if len(digests) != 1 || digests[0].UniqueKey != "digest:alice" {
+			t.Errorf("List by kind and status = %+v", digests)
+		}
+		limited, _ := repos.Jobs.List(ctx, domain.JobFilter{Limit: 1})
+		if len(limited) != 1 {
+			t.Errorf("List with a limit = %d jobs, want 1", len(limited))
+		}
+	})
+
+	t.Run("UniqueKey", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+
+		job := enqueueJob(t, repos, "digest", "digest:alice", now)
+		duplicate := &domain.Job{Kind: "digest", UniqueKey: "digest:alice", MaxAttempts: 3, RunAt: now}
+		if err := repos.Jobs.Enqueue(ctx, duplicate); !errors.Is(err, domain.ErrJobExists) {
+			t.Fatalf("second live job with the key = %v, want ErrJobExists", err)
+		}
+
+		// The key is free again once the job finished
+		claimed, err := repos.Jobs.Claim(ctx, "w1", kinds, now, time.Minute)
+		if err != nil || claimed == nil || claimed.ID != job.ID {
+			t.Fatalf("Claim = %+v, %v", claimed, err)
+		}
+		if err := repos.Jobs.Complete(ctx, job.ID, "w1", now); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		enqueueJob(t, repos, "digest", "digest:alice", now)
+	})
+
+	t.Run("Claim", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+
+		later := enqueueJob(t, repos, "email", "", now.Add(time.Hour))
+		due := enqueueJob(t, repos, "email", "", now.Add(-time.Minute))
+		enqueueJob(t, repos, "other", "", now.Add(-time.Hour))
+
+		claimed, err := repos.Jobs.Claim(ctx, "w1", kinds, now, time.Minute)
+		if err != nil {
+			t.Fatalf("Claim: %v", err)
+		}
+		if claimed == nil || claimed.ID != due.ID || claimed.Status != domain.JobRunning || claimed.Attempts != 1 ||
+			claimed.LockedBy != "w1" || claimed.LockedUntil == nil || claimed.StartedAt == nil {
+			t.Fatalf("Claim = %+v, want the due job locked by w1", claimed)
+		}
+
+		// Held jobs, jobs not yet due and unknown kinds are not handed out
+		if again, err := repos.Jobs.Claim(ctx, "w2", kinds, now, time.Minute); err != nil || again != nil {
+			t.Fatalf("second Claim = %+v, %v, want nothing", again, err)
+		}
+
+		// An expired lease lets another worker take over
+		stolen, err := repos.Jobs.Claim(ctx, "w2", kinds, now.Add(2*time.Minute), time.Minute)
+		if err != nil || stolen == nil || stolen.ID != due.ID || stolen.Attempts != 2 || stolen.LockedBy != "w2" || stolen.LastError == "" {
+			t.Fatalf("Claim after the lease = %+v, %v", stolen, err)
+		}
+		if err := repos.Jobs.Complete(ctx, due.ID, "w1", now); !errors.Is(err, domain.ErrJobLeaseLost) {
+			t.Errorf("Complete by the old worker = %v, want ErrJobLeaseLost", err)
+		}
+		if err := repos.Jobs.Complete(ctx, due.ID, "w2", now.Add(2*time.Minute)); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		done, _ := repos.Jobs.GetByID(ctx, due.ID)
+		if done.Status != domain.JobSucceeded || done.FinishedAt == nil || done.LockedBy != "" || done.LastError != "" {
+			t.Errorf("completed job = %+v", done)
+		}
+
+		next, err := repos.Jobs.Claim(ctx, "w1", kinds, now.Add(time.Hour), time.Minute)
+		if err != nil || next == nil || next.ID != later.ID {
+			t.Errorf("Claim an hour later = %+v, %v, want the later job", next, err)
+		}
+	})
+
+	t.Run("ClaimFailsExhaustedLease", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+
+		// Every attempt dies without completing or failing the job
+		job := enqueueJob(t, repos, "email", "", now)
+		for attempt := 1; attempt <= job.MaxAttempts; attempt++ {
+			at := now.Add(time.Duration(attempt-1) * 2 * time.Minute)
+			claimed, err := repos.Jobs.Claim(ctx, "w1", kinds, at, time.Minute)
+			if err != nil || claimed == nil || claimed.ID != job.ID || claimed.Attempts != attempt {
+				t.Fatalf("Claim of attempt %d = %+v, %v", attempt, claimed, err)
+			}
+		}
+
+		after := now.Add(time.Duration(job.MaxAttempts) * 2 * time.Minute)
+		if claimed, err := repos.Jobs.Claim(ctx, "w2", kinds, after, time.Minute); err != nil || claimed != nil {
+			t.Fatalf("Claim after the last lease = %+v, %v, want nothing", claimed, err)
+		}
+		failed, _ := repos.Jobs.GetByID(ctx, job.ID)
+		if failed.Status != domain.JobFailed || failed.Attempts != job.MaxAttempts || failed.FinishedAt == nil ||
+			failed.LockedBy != "" || failed.LockedUntil != nil || failed.LastError != "lease expired" {
+			t.Errorf("job after its last lease = %+v, want failed", failed)
+		}
+
+		// A failed job can be retried with fresh attempts
+		if err := repos.Jobs.Retry(ctx, job.ID, after); err != nil {
+			t.Fatalf("Retry: %v", err)
+		}
+		if claimed, err := repos.Jobs.Claim(ctx, "w2", kinds, after, time.Minute); err != nil || claimed == nil || claimed.Attempts != 1 {
+			t.Errorf("Claim after the retry = %+v, %v", claimed, err)
+		}
+	})
+
+	t.Run("Fail", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		job := enqueueJob(t, repos, "email", "", now)
+
+		if _, err := repos.Jobs.Claim(ctx, "w1", kinds, now, time.Minute); err != nil {
+			t.Fatalf("Claim: %v", err)
+		}
+		retryAt := now.Add(time.Minute)
+		if err := repos.Jobs.Fail(ctx, job.ID, "w1", "smtp down", now, &retryAt); err != nil {
+			t.Fatalf("Fail with retry: %v", err)
+		}
+		got, _ := repos.Jobs.GetByID(ctx, job.ID)
+		if got.Status != domain.JobPending || got.LastError != "smtp down" || !got.RunAt.Equal(retryAt.Truncate(time.Microsecond)) || got.FinishedAt != nil {
+			t.Errorf("job after a retryable failure = %+v", got)
+		}
+		if claimed, _ := repos.Jobs.Claim(ctx, "w1", kinds, now, time.Minute); claimed != nil {
+			t.Errorf("job claimed before its retry time: %+v", claimed)
+		}
+
+		if _, err := repos.Jobs.Claim(ctx, "w1", kinds, retryAt, time.Minute); err != nil {
+			t.Fatalf("Claim for the retry: %v", err)
+		}
+		if err := repos.Jobs.Fail(ctx, job.ID, "w1", "smtp down", retryAt, nil); err != nil {
+			t.Fatalf("Fail for good: %v", err)
+		}
+		got, _ = repos.Jobs.GetByID(ctx, job.ID)
+		if got.Status != domain.JobFailed || got.Attempts != 2 || got.FinishedAt == nil {
+			t.Errorf("job after the last failure = %+v", got)
+		}
+		if err := repos.Jobs.Fail(ctx, job.ID, "w1", "again", retryAt, nil); !errors.Is(err, domain.ErrJobLeaseLost) {
+			t.Errorf("Fail of a finished job = %v, want ErrJobLeaseLost", err)
+		}
+	})
+
+	t.Run("RetryAndCancel", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		job := enqueueJob(t, repos, "digest", "digest:alice", now)
+
+		if err := repos.Jobs.Retry(ctx, job.ID, now); !errors.Is(err, domain.ErrJobNotRetryable) {
+			t.Errorf("Retry of a pending job = %v, want ErrJobNotRetryable", err)
+		}
+		if err := repos.Jobs.Cancel(ctx, job.ID, now); err != nil {
+			t.Fatalf("Cancel: %v", err)
+		}
+		got, _ := repos.Jobs.GetByID(ctx, job.ID)
+		if got.Status != domain.JobCancelled || got.FinishedAt == nil {
+			t.Errorf("cancelled job = %+v", got)
+		}
+		if err := repos.Jobs.Cancel(ctx, job.ID, now); !errors.Is(err, domain.ErrJobNotCancellable) {
+			t.Errorf("second Cancel = %v, want ErrJobNotCancellable", err)
+		}
+		if claimed, _ := repos.Jobs.Claim(ctx, "w1", kinds, now, time.Minute); claimed != nil {
+			t.Errorf("cancelled job was claimed: %+v", claimed)
+		}
+
+		// A newer job took the key, so the cancelled one cannot come back
+		newer := enqueueJob(t, repos, "digest", "digest:alice", now)
+		if err := repos.Jobs.Retry(ctx, job.ID, now); !errors.Is(err, domain.ErrJobExists) {
+			t.Errorf("Retry while the key is taken = %v, want ErrJobExists", err)
+		}
+		if err := repos.Jobs.Cancel(ctx, newer.ID, now); err != nil {
+			t.Fatalf("Cancel the newer job: %v", err)
+		}
+		if err := repos.Jobs.Retry(ctx, job.ID, now.Add(time.Second)); err != nil {
+			t.Fatalf("Retry: %v", err)
+		}
+		got, _ = repos.Jobs.GetByID(ctx, job.ID)
+		if got.Status != domain.JobPending || got.Attempts != 0 || got.FinishedAt != nil {
+			t.Errorf("retried job = %+v", got)
+		}
+
+		if err := repos.Jobs.Retry(ctx, missingID(), now); !errors.Is(err, domain.ErrJobNotFound) {
+			t.Errorf("Retry of a missing job = %v, want ErrJobNotFound", err)
+		}
+		if err := repos.Jobs.Cancel(ctx, missingID(), now); !errors.Is(err, domain.ErrJobNotFound) {
+			t.Errorf("Cancel of a missing job = %v, want ErrJobNotFound", err)
+		}
+	})
+
+	t.Run("DeleteFinished", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		old := enqueueJob(t, repos, "email", "", now)
+		recent := enqueueJob(t, repos, "email", "", now)
+		pending := enqueueJob(t, repos, "email", "", now)
+
+		if err := repos.Jobs.Cancel(ctx, old.ID, now.Add(-48*time.Hour)); err != nil {
+			t.Fatalf("Cancel: %v", err)
+		}
+		if err := repos.Jobs.Cancel(ctx, recent.ID, now); err != nil {
+			t.Fatalf("Cancel: %v", err)
+		}
+
+		deleted, err := repos.Jobs.DeleteFinished(ctx, now.Add(-24*time.Hour))
+		if err != nil {
+			t.Fatalf("DeleteFinished: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("DeleteFinished = %d, want 1", deleted)
+		}
+		left, _ := repos.Jobs.List(ctx, domain.JobFilter{})
+		if len(left) != 2 || left[0].ID != pending.ID || left[1].ID != recent.ID {
+			t.Errorf("jobs left = %+v", left)
+		}
+	})
+
+	t.Run("Schedules", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		claim := func(at, next time.Time) bool {
+			t.Helper()
+			ok, err := repos.Jobs.ClaimSchedule(ctx, "digest", at, next)
+			if err != nil {
+				t.Fatalf("ClaimSchedule: %v", err)
+			}
+			return ok
+		}
+
+		// The first sight only records the next fire time
+		if claim(now, now.Add(time.Hour)) {
+			t.Error("new schedule fired right away")
+		}
+		if claim(now.Add(time.Minute), now.Add(time.Hour)) {
+			t.Error("schedule fired before its time")
+		}
+		if !claim(now.Add(time.Hour), now.Add(2*time.Hour)) {
+			t.Fatal("due schedule was not claimed")
+		}
+		// Another instance reaching the same fire time backs off
+		if claim(now.Add(time.Hour), now.Add(2*time.Hour)) {
+			t.Error("fire time claimed twice")
+		}
+		if !claim(now.Add(3*time.Hour), now.Add(4*time.Hour)) {
+			t.Error("later fire time was not claimed")
+		}
+	})
+}
+
+// enqueueJob inserts a job with a small payload and three attempts
+func enqueueJob(t *testing.T, repos Repositories, kind, uniqueKey string, runAt time.Time) *domain.Job {
+	t.Helper()
+
+	job := &domain.Job{
+		Kind:        kind,
+		Payload:     json.RawMessage(`{"to":"alice"}`),
+		UniqueKey:   uniqueKey,
+		MaxAttempts: 3,
+		RunAt:       runAt,
+	}
+	if err := repos.Jobs.Enqueue(defaultCtx(), job); err != nil {
+		t.Fatalf("enqueue job: %v", err)
+	}
+	return job
+}
@@ -0,0 +1,292 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testLearnings(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndGetJoinsFields", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+
+		if learning.ID == "" || learning.CreatedAt.IsZero() || learning.StartDate.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", learning)
+		}
+
+		got, err := repos.Learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.LearningActive || got.UserID != user.ID || got.MentorID != mentor.ID || got.EndDate != nil || got.Feedback != nil || got.Notes != nil {
+			t.Errorf("GetByID returned %+v", got)
+		}
+		if got.RequestTopic != "Go" || got.RequestDescription != "Go description" || got.UserName != "alice" {
+			t.Errorf("joined request/user fields: %q %q %q", got.RequestTopic, got.RequestDescription, got.UserName)
+		}
+		if got.MentorName != "Ann" || got.MentorJobTitle != "Senior Engineer" || got.MentorTelegram == nil || got.MentorExperience == nil {
+			t.Errorf("joined mentor fields: %q %q %v %v", got.MentorName, got.MentorJobTitle, got.MentorTelegram, got.MentorExperience)
+		}
+		if len(got.Plan) != 0 {
+			t.Errorf("plan = %v, want empty", got.Plan)
+		}
+	})
+
+	t.Run("OnePerRequest", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+
+		duplicate := &domain.LearningProcess{
+			RequestID: learning.RequestID,
+			UserID:    user.ID,
+			MentorID:  mentor.ID,
+			Status:    domain.LearningActive,
+			StartDate: learning.StartDate,
+		}
+		if err := repos.Learnings.Create(ctx, duplicate); err == nil {
+			t.Error("second learning for the same request succeeded")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 0)
+		id := missingID()
+
+		checks := map[string]error{
+			"UpdatePlan":   repos.Learnings.UpdatePlan(ctx, id, nil),
+			"UpdateNotes":  repos.Learnings.UpdateNotes(ctx, id, "notes"),
+			"Update":       repos.Learnings.Update(ctx, id, domain.LearningActive, &domain.LearningProcess{Status: domain.LearningActive}),
+			"Complete":     repos.Learnings.Complete(ctx, id, domain.Feedback{Rating: 5, Comment: "ok"}),
+			"UpdateMentor": repos.Learnings.UpdateMentor(ctx, id, mentor.ID, mentor.ID),
+		}
+		if _, err := repos.Learnings.GetByID(ctx, id); !errors.Is(err, domain.ErrLearningNotFound) {
+			t.Errorf("GetByID error = %v, want ErrLearningNotFound", err)
+		}
+		for name, err := range checks {
+			if !errors.Is(err, domain.ErrLearningNotFound) {
+				t.Errorf("%s error = %v, want ErrLearningNotFound", name, err)
+			}
+		}
+	})
+
+	t.Run("ListsNewestFirst", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		ann := createMentor(t, repos, "Ann", 0)
+		max := createMentor(t, repos, "Max", 0)
+		first := createLearning(t, repos, alice.ID, ann.ID, "first")
+		second := createLearning(t, repos, bob.ID, ann.ID, "second")
+		third := createLearning(t, repos, alice.ID, max.ID, "third")
+
+		byUser, err := repos.Learnings.GetByUserID(ctx, alice.ID)
+		if err != nil {
+			t.Fatalf("GetByUserID: %v", err)
+		}
+		assertIDs(t, "GetByUserID", byUser, third.ID, first.ID)
+
+		byMentor, err := repos.Learnings.GetByMentorID(ctx, ann.ID)
+		if err != nil {
+			t.Fatalf("GetByMentorID: %v", err)
+		}
+		assertIDs(t, "GetByMentorID", byMentor, second.ID, first.ID)
+
+		all, err := repos.Learnings.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		assertIDs(t, "GetAll", all, third.ID, second.ID, first.ID)
+		if all[0].MentorName != "Max" || all[0].RequestTopic != "third" {
+			t.Errorf("GetAll did not join fields: %+v", all[0])
+		}
+	})
+
+	t.Run("UpdatePlanAndNotes", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+
+		plan := []domain.LearningPlanItem{
+			{ID: "1", Text: "Tour of Go", Completed: true},
+			{ID: "2", Text: "Build a service"},
+		}
+		if err := repos.Learnings.UpdatePlan(ctx, learning.ID, plan); err != nil {
+			t.Fatalf("UpdatePlan: %v", err)
+		}
+		if err := repos.Learnings.UpdateNotes(ctx, learning.ID, "going well"); err != nil {
+			t.Fatalf("UpdateNotes: %v", err)
+		}
+
+		got, err := repos.Learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if len(got.Plan) != 2 || got.Plan[0] != plan[0] || got.Plan[1] != plan[1] {
+			t.Errorf("plan = %+v", got.Plan)
+		}
+		if got.Notes == nil || *got.Notes != "going well" {
+			t.Errorf("notes = %v", got.Notes)
+		}
+
+		if err := repos.Learnings.UpdateNotes(ctx, learning.ID, ""); err != nil {
+			t.Fatalf("UpdateNotes(empty): %v", err)
+		}
+		got, _ = repos.Learnings.GetByID(ctx, learning.ID)
+		if got.Notes != nil {
+			t.Errorf("empty notes stored as %q, want NULL", *got.Notes)
+		}
+	})
+
+	t.Run("UpdateAndComplete", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		other := createMentor(t, repos, "Max", 0)
+		updated := createLearning(t, repos, user.ID, mentor.ID, "updated")
+		completed := createLearning(t, repos, user.ID, mentor.ID, "completed")
+
+		notes := "admin notes"
+		err := repos.Learnings.Update(ctx, updated.ID, domain.LearningActive, &domain.LearningProcess{
+			Status:   domain.LearningActive,
+			Plan:     []domain.LearningPlanItem{{ID: "1", Text: "Read docs"}},
+			Feedback: &domain.Feedback{Rating: 4, Comment: "fine"},
+			Notes:    &notes,
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := repos.Learnings.UpdateMentor(ctx, updated.ID, mentor.ID, other.ID); err != nil {
+			t.Fatalf("UpdateMentor: %v", err)
+		}
+
+		got, err := repos.Learnings.GetByID(ctx, updated.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if len(got.Plan) != 1 || got.Feedback == nil || got.Feedback.Rating != 4 || got.Notes == nil || *got.Notes != notes {
+			t.Errorf("Update not persisted: %+v", got)
+		}
+		if got.MentorID != other.ID || got.MentorName != "Max" {
+			t.Errorf("UpdateMentor not persisted: mentor %s %q", got.MentorID, got.MentorName)
+		}
+
+		if err := repos.Learnings.Complete(ctx, completed.ID, domain.Feedback{Rating: 5, Comment: "great"}); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		got, err = repos.Learnings.GetByID(ctx, completed.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.LearningCompleted || got.EndDate == nil || got.Feedback == nil || got.Feedback.Rating != 5 || got.Feedback.Comment != "great" {
+			t.Errorf("Complete not persisted: %+v", got)
+		}
+	})
+
+	t.Run("CompleteTwice", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+
+		if err := repos.Learnings.Complete(ctx, learning.ID, domain.Feedback{Rating: 5, Comment: "great"}); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		if err := repos.Learnings.Complete(ctx, learning.ID, domain.Feedback{Rating: 1, Comment: "again"}); !errors.Is(err, domain.ErrLearningNotActive) {
+			t.Fatalf("second Complete error = %v, want ErrLearningNotActive", err)
+		}
+
+		got, err := repos.Learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Feedback == nil || got.Feedback.Rating != 5 {
+			t.Errorf("second Complete overwrote the feedback: %+v", got.Feedback)
+		}
+	})
+
+	t.Run("ConditionalUpdates", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		other := createMentor(t, repos, "Max", 0)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+
+		// The learning is active, so an update expecting it completed lost a race
+		err := repos.Learnings.Update(ctx, learning.ID, domain.LearningCompleted, &domain.LearningProcess{Status: domain.LearningActive})
+		if !errors.Is(err, domain.ErrLearningChanged) {
+			t.Errorf("Update from a stale status error = %v, want ErrLearningChanged", err)
+		}
+		if err := repos.Learnings.UpdateMentor(ctx, learning.ID, other.ID, mentor.ID); !errors.Is(err, domain.ErrLearningChanged) {
+			t.Errorf("UpdateMentor from a stale mentor error = %v, want ErrLearningChanged", err)
+		}
+
+		got, err := repos.Learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.LearningActive || got.MentorID != mentor.ID {
+			t.Errorf("failed updates changed the learning: status %s, mentor %s", got.Status, got.MentorID)
+		}
+	})
+
+	t.Run("PlanChanges", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+
+		if learning.PlanUpdatedAt == nil || !learning.PlanUpdatedAt.Equal(learning.CreatedAt) {
+			t.Fatalf("PlanUpdatedAt after Create = %v, want the creation time %v", learning.PlanUpdatedAt, learning.CreatedAt)
+		}
+
+		if err := repos.Learnings.UpdateNotes(ctx, learning.ID, "notes"); err != nil {
+			t.Fatalf("UpdateNotes: %v", err)
+		}
+		got, err := repos.Learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.PlanUpdatedAt == nil || !got.PlanUpdatedAt.Equal(learning.CreatedAt) {
+			t.Errorf("PlanUpdatedAt after UpdateNotes = %v, want it unchanged", got.PlanUpdatedAt)
+		}
+
+		if err := repos.Learnings.UpdatePlan(ctx, learning.ID, []domain.LearningPlanItem{{ID: "1", Text: "Read docs"}}); err != nil {
+			t.Fatalf("UpdatePlan: %v", err)
+		}
+		got, err = repos.Learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.PlanUpdatedAt == nil || !got.PlanUpdatedAt.Equal(got.UpdatedAt) {
+			t.Errorf("PlanUpdatedAt after UpdatePlan = %v, want the update time %v", got.PlanUpdatedAt, got.UpdatedAt)
+		}
+		if !got.LastPlanChange().Equal(*got.PlanUpdatedAt) {
+			t.Errorf("LastPlanChange = %v, want %v", got.LastPlanChange(), *got.PlanUpdatedAt)
+		}
+	})
+}
+
+// assertIDs checks that learnings have exactly the given IDs in order
+func assertIDs(t *testing.T, name string, learnings []*domain.LearningProcess, ids ...string) {
+	t.Helper()
+
+	if len(learnings) != len(ids) {
+		t.Errorf("%s returned %d learnings, want %d", name, len(learnings), len(ids))
+		return
+	}
+	for i, id := range ids {
+		if learnings[i].ID != id {
+			t.Errorf("%s[%d] = %s (%s), want %s", name, i, learnings[i].ID, learnings[i].RequestTopic, id)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testMentors(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 2)
+
+		if mentor.ID == "" || mentor.CreatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", mentor)
+		}
+
+		got, err := repos.Mentors.GetByID(ctx, mentor.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Ann" || got.Workload != 2 || got.Email != mentor.Email || got.Experience == nil || *got.Experience != "5 years" {
+			t.Errorf("GetByID returned %+v", got)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if _, err := repos.Mentors.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("GetByID error = %v, want ErrMentorNotFound", err)
+		}
+		if err := repos.Mentors.Update(ctx, &domain.Mentor{ID: missingID()}); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("Update error = %v, want ErrMentorNotFound", err)
+		}
+		if err := repos.Mentors.UpdateWorkload(ctx, missingID(), 1); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("UpdateWorkload error = %v, want ErrMentorNotFound", err)
+		}
+		if err := repos.Mentors.Delete(ctx, missingID()); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("Delete error = %v, want ErrMentorNotFound", err)
+		}
+		if err := repos.Mentors.UpdateDeactivatedAt(ctx, missingID(), nil); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("UpdateDeactivatedAt error = %v, want ErrMentorNotFound", err)
+		}
+	})
+
+	t.Run("GetAllOrderedByWorkloadThenName", func(t *testing.T) {
+		repos := newRepos(t)
+		createMentor(t, repos, "Zed", 0)
+		createMentor(t, repos, "Bea", 3)
+		createMentor(t, repos, "Abe", 0)
+		createMentor(t, repos, "Full", 5)
+
+		all, err := repos.Mentors.GetAll(ctx, domain.MentorFilter{})
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		want := []string{"Abe", "Zed", "Bea", "Full"}
+		if len(all) != len(want) {
+			t.Fatalf("GetAll returned %d mentors, want %d", len(all), len(want))
+		}
+		for i, name := range want {
+			if all[i].Name != name {
+				t.Errorf("mentors[%d] = %s, want %s", i, all[i].Name, name)
+			}
+		}
+
+		maxWorkload := 3
+		filtered, err := repos.Mentors.GetAll(ctx, domain.MentorFilter{MaxWorkload: &maxWorkload})
+		if err != nil {
+			t.Fatalf("GetAll(max 3): %v", err)
+		}
+		if len(filtered) != 3 {
+			t.Errorf("GetAll(max 3) returned %d mentors, want 3", len(filtered))
+		}
+	})
+
+	t.Run("DeactivateAndFilterActive", func(t *testing.T) {
+		repos := newRepos(t)
+		active := createMentor(t, repos, "Active", 0)
+		inactive := createMentor(t, repos, "Inactive", 0)
+
+		at := time.Now()
+		if err := repos.Mentors.UpdateDeactivatedAt(ctx, inactive.ID, &at); err != nil {
+			t.Fatalf("UpdateDeactivatedAt: %v", err)
+		}
+
+		got, err := repos.Mentors.GetByID(ctx, inactive.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.DeactivatedAt == nil || got.IsActive() {
+			t.Errorf("DeactivatedAt not persisted: %+v", got)
+		}
+
+		all, err := repos.Mentors.GetAll(ctx, domain.MentorFilter{})
+		if err != nil || len(all) != 2 {
+			t.Errorf("GetAll returned %d mentors, %v; want 2", len(all), err)
+		}
+		onlyActive, err := repos.Mentors.GetAll(ctx, domain.MentorFilter{ActiveOnly: true})
+		if err != nil {
+			t.Fatalf("GetAll(active): %v", err)
+		}
+		if len(onlyActive) != 1 || onlyActive[0].ID != active.ID {
+			t.Errorf("GetAll(active) returned %d mentors", len(onlyActive))
+		}
+
+		if err := repos.Mentors.UpdateDeactivatedAt(ctx, inactive.ID, nil); err != nil {
+			t.Fatalf("UpdateDeactivatedAt(nil): %v", err)
+		}
+		got, _ = repos.Mentors.GetByID(ctx, inactive.ID)
+		if got.DeactivatedAt != nil {
+			t.Errorf("DeactivatedAt = %v after reactivation, want nil", got.DeactivatedAt)
+		}
+	})
+
+	t.Run("UpdateAndWorkload", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 0)
+
+		mentor.Name = "Anna"
+		mentor.JobTitle = "Staff Engineer"
+		mentor.Workload = 1
+		if err := repos.Mentors.Update(ctx, mentor); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := repos.Mentors.UpdateWorkload(ctx, mentor.ID, 4); err != nil {
+			t.Fatalf("UpdateWorkload: %v", err)
+		}
+
+		got, err := repos.Mentors.GetByID(ctx, mentor.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Anna" || got.JobTitle != "Staff Engineer" || got.Workload != 4 {
+			t.Errorf("updates not persisted: %+v", got)
+		}
+	})
+
+	t.Run("Skills", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 0)
+
+		got, err := repos.Mentors.GetByID(ctx, mentor.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Skills == nil || len(got.Skills) != 0 {
+			t.Errorf("Skills of a new mentor = %#v, want empty", got.Skills)
+		}
+
+		mentor.Skills = []string{" Go ", "SQL", "go"}
+		if err := repos.Mentors.Update(ctx, mentor); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err = repos.Mentors.GetByID(ctx, mentor.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !slices.Equal(got.Skills, []string{"Go", "SQL"}) {
+			t.Errorf("Skills = %v, want [Go SQL]", got.Skills)
+		}
+	})
+
+	t.Run("WorkloadBounds", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 0)
+
+		for _, workload := range []int{-1, 6} {
+			if err := repos.Mentors.UpdateWorkload(ctx, mentor.ID, workload); err == nil {
+				t.Errorf("UpdateWorkload(%d) succeeded", workload)
+			}
+		}
+	})
+
+	t.Run("IncrementAndDecrementWorkload", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 1)
+
+		if err := repos.Mentors.IncrementWorkload(ctx, mentor.ID, 3); err != nil {
+			t.Fatalf("IncrementWorkload: %v", err)
+		}
+		if err := repos.Mentors.IncrementWorkload(ctx, mentor.ID, 2); !errors.Is(err, domain.ErrMentorNotAvailable) {
+			t.Errorf("IncrementWorkload above the capacity error = %v, want ErrMentorNotAvailable", err)
+		}
+		if got, _ := repos.Mentors.GetByID(ctx, mentor.ID); got.Workload != 4 {
+			t.Fatalf("workload = %d after a refused increment, want 4", got.Workload)
+		}
+
+		if err := repos.Mentors.DecrementWorkload(ctx, mentor.ID, 3); err != nil {
+			t.Fatalf("DecrementWorkload: %v", err)
+		}
+		if got, _ := repos.Mentors.GetByID(ctx, mentor.ID); got.Workload != 1 {
+			t.Errorf("workload = %d, want 1", got.Workload)
+		}
+		if err := repos.Mentors.DecrementWorkload(ctx, mentor.ID, 2); err != nil {
+			t.Fatalf("DecrementWorkload below zero: %v", err)
+		}
+		if got, _ := repos.Mentors.GetByID(ctx, mentor.ID); got.Workload != 0 {
+			t.Errorf("workload = %d, want 0", got.Workload)
+		}
+
+		if err := repos.Mentors.IncrementWorkload(ctx, missingID(), 1); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("IncrementWorkload error = %v, want ErrMentorNotFound", err)
+		}
+		if err := repos.Mentors.DecrementWorkload(ctx, missingID(), 1); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("DecrementWorkload error = %v, want ErrMentorNotFound", err)
+		}
+	})
+
+	t.Run("ConcurrentIncrementsKeepCapacity", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 0)
+
+		var wg sync.WaitGroup
+		var taken atomic.Int32
+		for i := 0; i < 2*mentor.Capacity; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := repos.Mentors.IncrementWorkload(ctx, mentor.ID, 1)
+				switch {
+				case err == nil:
+					taken.Add(1)
+				case !errors.Is(err, domain.ErrMentorNotAvailable):
+					t.Errorf("IncrementWorkload: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		got, err := repos.Mentors.GetByID(ctx, mentor.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if int(taken.Load()) != mentor.Capacity || got.Workload != mentor.Capacity {
+			t.Errorf("%d increments succeeded, workload %d, want both %d", taken.Load(), got.Workload, mentor.Capacity)
+		}
+	})
+
+	t.Run("Capacity", func(t *testing.T) {
+		repos := newRepos(t)
+		createMentor(t, repos, "Open", 1)
+		full := &domain.Mentor{Name: "Full", JobTitle: "Engineer", Workload: 2, Capacity: 2, Email: missingID() + "@example.com"}
+		if err := repos.Mentors.Create(ctx, full); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := repos.Mentors.GetByID(ctx, full.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Capacity != 2 || got.CanTakeStudent() {
+			t.Errorf("GetByID = %+v, want a full mentor of capacity 2", got)
+		}
+
+		open, err := repos.Mentors.GetAll(ctx, domain.MentorFilter{HasCapacity: true})
+		if err != nil {
+			t.Fatalf("GetAll(has capacity): %v", err)
+		}
+		if len(open) != 1 || open[0].Name != "Open" {
+			t.Errorf("GetAll(has capacity) returned %d mentors, want only Open", len(open))
+		}
+
+		if err := repos.Mentors.UpdateWorkload(ctx, full.ID, 3); err == nil {
+			t.Error("UpdateWorkload above the capacity succeeded")
+		}
+		for _, capacity := range []int{0, domain.MaxMentorCapacity + 1} {
+			m := &domain.Mentor{Name: "Odd", JobTitle: "Engineer", Capacity: capacity, Email: missingID() + "@example.com"}
+			if err := repos.Mentors.Create(ctx, m); err == nil {
+				t.Errorf("Create with capacity %d succeeded", capacity)
+			}
+		}
+	})
+
+	t.Run("DeleteRestrictedByLearnings", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		busy := createMentor(t, repos, "Busy", 1)
+		idle := createMentor(t, repos, "Idle", 0)
+		createLearning(t, repos, user.ID, busy.ID, "Go")
+
+		if err := repos.Mentors.Delete(ctx, busy.ID); err == nil {
+			t.Error("Delete of a mentor with learnings succeeded")
+		}
+		if err := repos.Mentors.Delete(ctx, idle.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Mentors.GetByID(ctx, idle.ID); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("mentor still exists: %v", err)
+		}
+	})
+}
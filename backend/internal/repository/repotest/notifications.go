@@ -0,0 +1,114 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testNotifications(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateListAndMarkRead", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		older := createNotification(t, repos, alice.ID, "first")
+		newer := createNotification(t, repos, alice.ID, "second")
+		createNotification(t, repos, bob.ID, "other")
+
+		if older.ID == "" || older.CreatedAt.IsZero() || older.ReadAt != nil {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", older)
+		}
+
+		all, err := repos.Notifications.GetByUserID(ctx, alice.ID, false)
+		if err != nil {
+			t.Fatalf("GetByUserID: %v", err)
+		}
+		if len(all) != 2 || all[0].ID != newer.ID || all[1].ID != older.ID {
+			t.Fatalf("GetByUserID returned %d notifications in wrong order", len(all))
+		}
+		if all[0].Kind != domain.NotificationMentorChanged || all[0].Title != "second" || all[0].Body != "second body" {
+			t.Errorf("GetByUserID returned %+v", all[0])
+		}
+
+		if err := repos.Notifications.MarkRead(ctx, older.ID, alice.ID); err != nil {
+			t.Fatalf("MarkRead: %v", err)
+		}
+		unread, err := repos.Notifications.GetByUserID(ctx, alice.ID, true)
+		if err != nil {
+			t.Fatalf("GetByUserID(unread): %v", err)
+		}
+		if len(unread) != 1 || unread[0].ID != newer.ID {
+			t.Errorf("GetByUserID(unread) returned %d notifications", len(unread))
+		}
+
+		all, _ = repos.Notifications.GetByUserID(ctx, alice.ID, false)
+		readAt := all[1].ReadAt
+		if readAt == nil {
+			t.Fatal("ReadAt not persisted")
+		}
+		if err := repos.Notifications.MarkRead(ctx, older.ID, alice.ID); err != nil {
+			t.Fatalf("MarkRead again: %v", err)
+		}
+		all, _ = repos.Notifications.GetByUserID(ctx, alice.ID, false)
+		if !all[1].ReadAt.Equal(*readAt) {
+			t.Errorf("second MarkRead moved ReadAt from %v to %v", readAt, all[1].ReadAt)
+		}
+	})
+
+	t.Run("MarkReadChecksOwner", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		notification := createNotification(t, repos, alice.ID, "mine")
+
+		if err := repos.Notifications.MarkRead(ctx, notification.ID, bob.ID); !errors.Is(err, domain.ErrNotificationNotFound) {
+			t.Errorf("MarkRead by another user error = %v, want ErrNotificationNotFound", err)
+		}
+		if err := repos.Notifications.MarkRead(ctx, missingID(), alice.ID); !errors.Is(err, domain.ErrNotificationNotFound) {
+			t.Errorf("MarkRead of a missing notification error = %v, want ErrNotificationNotFound", err)
+		}
+	})
+
+	t.Run("EmptyAndCascade", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+
+		empty, err := repos.Notifications.GetByUserID(ctx, alice.ID, false)
+		if err != nil || empty == nil || len(empty) != 0 {
+			t.Errorf("GetByUserID with no notifications = %v, %v; want empty slice", empty, err)
+		}
+
+		createNotification(t, repos, alice.ID, "bye")
+		if err := repos.Users.Delete(ctx, alice.ID); err != nil {
+			t.Fatalf("Delete user: %v", err)
+		}
+		left, _ := repos.Notifications.GetByUserID(ctx, alice.ID, false)
+		if len(left) != 0 {
+			t.Errorf("notifications not cascaded: %d left", len(left))
+		}
+
+		orphan := &domain.Notification{UserID: missingID(), Kind: domain.NotificationMentorChanged, Title: "t", Body: "b"}
+		if err := repos.Notifications.Create(ctx, orphan); err == nil {
+			t.Error("Create for a missing user succeeded")
+		}
+	})
+}
+
+// createNotification inserts a notification for userID
+func createNotification(t *testing.T, repos Repositories, userID, title string) *domain.Notification {
+	t.Helper()
+
+	notification := &domain.Notification{
+		UserID: userID,
+		Kind:   domain.NotificationMentorChanged,
+		Title:  title,
+		Body:   title + " body",
+	}
+	if err := repos.Notifications.Create(defaultCtx(), notification); err != nil {
+		t.Fatalf("create notification: %v", err)
+	}
+	return notification
+}
@@ -0,0 +1,159 @@
+// Package repotest is a conformance suite shared by all implementations of
+// the domain repository interfaces, so the in-memory repositories used in
+// service tests behave like the Postgres ones.
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// Repositories is a set of repositories backed by the same empty database
+type Repositories struct {
+	Tenants       domain.TenantRepository
+	Users         domain.UserRepository
+	Roles         domain.RoleRepository
+	Departments   domain.DepartmentRepository
+	Requests      domain.RequestRepository
+	Mentors       domain.MentorRepository
+	Learnings     domain.LearningRepository
+	Notifications domain.NotificationRepository
+	Availability  domain.AvailabilityRepository
+	Approvals     domain.ApprovalRepository
+	Comments      domain.CommentRepository
+	Attachments   domain.AttachmentRepository
+	Courses       domain.CourseRepository
+	Enrollments   domain.EnrollmentRepository
+	Skills        domain.SkillRepository
+	Competencies  domain.CompetencyRepository
+	Certificates  domain.CertificateRepository
+	Tx            domain.TxManager
+
+	Questionnaires domain.QuestionnaireRepository
+	Feedback       domain.FeedbackRepository
+	CheckIns       domain.CheckInRepository
+	Jobs           domain.JobRepository
+	Outbox         domain.OutboxRepository
+	Webhooks       domain.WebhookRepository
+}
+
+// Factory returns repositories over an empty database for each test
+type Factory func(t *testing.T) Repositories
+
+// Run runs the whole conformance suite
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Tenants", func(t *testing.T) { testTenants(t, newRepos) })
+	t.Run("TenantIsolation", func(t *testing.T) { testTenantIsolation(t, newRepos) })
+	t.Run("Users", func(t *testing.T) { testUsers(t, newRepos) })
+	t.Run("Roles", func(t *testing.T) { testRoles(t, newRepos) })
+	t.Run("Departments", func(t *testing.T) { testDepartments(t, newRepos) })
+	t.Run("Requests", func(t *testing.T) { testRequests(t, newRepos) })
+	t.Run("Mentors", func(t *testing.T) { testMentors(t, newRepos) })
+	t.Run("Learnings", func(t *testing.T) { testLearnings(t, newRepos) })
+	t.Run("Availability", func(t *testing.T) { testAvailability(t, newRepos) })
+	t.Run("Notifications", func(t *testing.T) { testNotifications(t, newRepos) })
+	t.Run("Approvals", func(t *testing.T) { testApprovals(t, newRepos) })
+	t.Run("Comments", func(t *testing.T) { testComments(t, newRepos) })
+	t.Run("Attachments", func(t *testing.T) { testAttachments(t, newRepos) })
+	t.Run("Courses", func(t *testing.T) { testCourses(t, newRepos) })
+	t.Run("Enrollments", func(t *testing.T) { testEnrollments(t, newRepos) })
+	t.Run("Skills", func(t *testing.T) { testSkills(t, newRepos) })
+	t.Run("Competencies", func(t *testing.T) { testCompetencies(t, newRepos) })
+	t.Run("Certificates", func(t *testing.T) { testCertificates(t, newRepos) })
+	t.Run("Questionnaires", func(t *testing.T) { testQuestionnaires(t, newRepos) })
+	t.Run("Feedback", func(t *testing.T) { testFeedback(t, newRepos) })
+	t.Run("CheckIns", func(t *testing.T) { testCheckIns(t, newRepos) })
+	t.Run("Jobs", func(t *testing.T) { testJobs(t, newRepos) })
+	t.Run("Webhooks", func(t *testing.T) { testWebhooks(t, newRepos) })
+	t.Run("Transactions", func(t *testing.T) { testTransactions(t, newRepos) })
+}
+
+// missingID returns a well-formed ID that does not exist
+func missingID() string {
+	return uuid.New().String()
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// createUser inserts a user with a unique email
+func createUser(t *testing.T, repos Repositories, name string) *domain.User {
+	t.Helper()
+
+	user := &domain.User{
+		Name:         name,
+		Email:        uuid.New().String() + "@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleEmployee,
+		JobTitle:     ptr("Engineer"),
+		Telegram:     ptr("@" + name),
+	}
+	if err := repos.Users.Create(defaultCtx(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// createMentor inserts a mentor with a unique email
+func createMentor(t *testing.T, repos Repositories, name string, workload int) *domain.Mentor {
+	t.Helper()
+
+	mentor := &domain.Mentor{
+		Name:       name,
+		JobTitle:   "Senior Engineer",
+		Experience: ptr("5 years"),
+		Workload:   workload,
+		Capacity:   domain.DefaultMentorCapacity,
+		Email:      uuid.New().String() + "@example.com",
+		Telegram:   ptr("@mentor"),
+	}
+	if err := repos.Mentors.Create(defaultCtx(), mentor); err != nil {
+		t.Fatalf("create mentor: %v", err)
+	}
+	return mentor
+}
+
+// createRequest inserts a pending request for userID
+func createRequest(t *testing.T, repos Repositories, userID, topic string) *domain.TrainingRequest {
+	t.Helper()
+
+	request := &domain.TrainingRequest{
+		UserID:      userID,
+		Topic:       topic,
+		Description: topic + " description",
+		Status:      domain.RequestPending,
+	}
+	if err := repos.Requests.Create(defaultCtx(), request); err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	return request
+}
+
+// createLearning inserts an active learning for a new request of userID
+func createLearning(t *testing.T, repos Repositories, userID, mentorID, topic string) *domain.LearningProcess {
+	t.Helper()
+
+	request := createRequest(t, repos, userID, topic)
+	learning := &domain.LearningProcess{
+		RequestID: request.ID,
+		UserID:    userID,
+		MentorID:  mentorID,
+		Status:    domain.LearningActive,
+		StartDate: request.CreatedAt,
+		Plan:      []domain.LearningPlanItem{},
+	}
+	if err := repos.Learnings.Create(defaultCtx(), learning); err != nil {
+		t.Fatalf("create learning: %v", err)
+	}
+	return learning
+}
+
+// defaultCtx is a context in the default tenant, where the fixtures live
+func defaultCtx() context.Context {
+	return domain.WithTenant(context.Background(), domain.DefaultTenantID)
+}
@@ -0,0 +1,309 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testRequests(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndGetJoinsUser", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		request := createRequest(t, repos, user.ID, "Go")
+
+		if request.ID == "" || request.CreatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", request)
+		}
+
+		got, err := repos.Requests.GetByID(ctx, request.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Topic != "Go" || got.Description != "Go description" || got.Status != domain.RequestPending || got.UserID != user.ID {
+			t.Errorf("GetByID returned %+v", got)
+		}
+		if got.UserName != "alice" || got.UserJobTitle == nil || *got.UserJobTitle != "Engineer" || got.UserTelegram == nil {
+			t.Errorf("joined user fields: name=%q jobTitle=%v telegram=%v", got.UserName, got.UserJobTitle, got.UserTelegram)
+		}
+		if got.Skills == nil || len(got.Skills) != 0 {
+			t.Errorf("Skills of an untagged request = %#v, want empty", got.Skills)
+		}
+	})
+
+	t.Run("CreateRequiresUser", func(t *testing.T) {
+		repos := newRepos(t)
+		request := &domain.TrainingRequest{UserID: missingID(), Topic: "Go", Description: "d", Status: domain.RequestPending}
+		if err := repos.Requests.Create(ctx, request); err == nil {
+			t.Error("Create for a missing user succeeded")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if _, err := repos.Requests.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrRequestNotFound) {
+			t.Errorf("GetByID error = %v, want ErrRequestNotFound", err)
+		}
+		if err := repos.Requests.Update(ctx, &domain.TrainingRequest{ID: missingID()}); !errors.Is(err, domain.ErrRequestNotFound) {
+			t.Errorf("Update error = %v, want ErrRequestNotFound", err)
+		}
+		if err := repos.Requests.UpdateStatus(ctx, missingID(), domain.RequestPending, domain.RequestApproved); !errors.Is(err, domain.ErrRequestNotFound) {
+			t.Errorf("UpdateStatus error = %v, want ErrRequestNotFound", err)
+		}
+	})
+
+	t.Run("StatusChangesNeedTheExpectedStatus", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		request := createRequest(t, repos, user.ID, "Go")
+
+		// Two deciders read the pending request; only the first one wins
+		if err := repos.Requests.UpdateStatus(ctx, request.ID, domain.RequestPending, domain.RequestRejected); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		if err := repos.Requests.UpdateStatus(ctx, request.ID, domain.RequestPending, domain.RequestApproved); !errors.Is(err, domain.ErrRequestChanged) {
+			t.Errorf("UpdateStatus from a stale status error = %v, want ErrRequestChanged", err)
+		}
+		if err := repos.Requests.Enqueue(ctx, request.ID, domain.RequestPending, 0); !errors.Is(err, domain.ErrRequestChanged) {
+			t.Errorf("Enqueue from a stale status error = %v, want ErrRequestChanged", err)
+		}
+
+		got, err := repos.Requests.GetByID(ctx, request.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.RequestRejected || got.QueuedAt != nil {
+			t.Errorf("request = %+v, want it still rejected", got)
+		}
+	})
+
+	t.Run("GetByUserIDNewestFirst", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		older := createRequest(t, repos, alice.ID, "first")
+		createRequest(t, repos, bob.ID, "other")
+		newer := createRequest(t, repos, alice.ID, "second")
+
+		requests, err := repos.Requests.GetByUserID(ctx, alice.ID)
+		if err != nil {
+			t.Fatalf("GetByUserID: %v", err)
+		}
+		if len(requests) != 2 || requests[0].ID != newer.ID || requests[1].ID != older.ID {
+			t.Errorf("GetByUserID returned %d requests in wrong order", len(requests))
+		}
+
+		empty, err := repos.Requests.GetByUserID(ctx, missingID())
+		if err != nil || len(empty) != 0 {
+			t.Errorf("GetByUserID for unknown user = %v, %v", empty, err)
+		}
+	})
+
+	t.Run("GetAllFiltersByStatus", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		pending := createRequest(t, repos, user.ID, "pending")
+		approved := createRequest(t, repos, user.ID, "approved")
+		if err := repos.Requests.UpdateStatus(ctx, approved.ID, domain.RequestPending, domain.RequestApproved); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+
+		all, err := repos.Requests.GetAll(ctx, nil)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 2 || all[0].ID != approved.ID || all[1].ID != pending.ID {
+			t.Errorf("GetAll returned %d requests in wrong order", len(all))
+		}
+
+		status := string(domain.RequestPending)
+		filtered, err := repos.Requests.GetAll(ctx, &status)
+		if err != nil {
+			t.Fatalf("GetAll(pending): %v", err)
+		}
+		if len(filtered) != 1 || filtered[0].ID != pending.ID {
+			t.Errorf("GetAll(pending) returned %d requests", len(filtered))
+		}
+		if filtered[0].UserName != "alice" {
+			t.Errorf("GetAll did not join user name")
+		}
+	})
+
+	t.Run("UpdateAndUpdateStatus", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		request := createRequest(t, repos, user.ID, "Go")
+
+		request.Topic = "Rust"
+		request.Description = "ownership"
+		request.Skills = []string{" Rust ", "rust", "Memory safety"}
+		request.Status = domain.RequestRejected // Update must not change status
+		if err := repos.Requests.Update(ctx, request); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := repos.Requests.UpdateStatus(ctx, request.ID, domain.RequestPending, domain.RequestApproved); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+
+		got, err := repos.Requests.GetByID(ctx, request.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Topic != "Rust" || got.Description != "ownership" || got.Status != domain.RequestApproved ||
+			len(got.Skills) != 2 || got.Skills[0] != "Rust" || got.Skills[1] != "Memory safety" {
+			t.Errorf("updates not persisted: %+v", got)
+		}
+	})
+
+	t.Run("QueueOrdersByPriorityThenAge", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		first := createRequest(t, repos, user.ID, "first")
+		second := createRequest(t, repos, user.ID, "second")
+		urgent := createRequest(t, repos, user.ID, "urgent")
+		createRequest(t, repos, user.ID, "pending")
+
+		for _, id := range []string{first.ID, second.ID, urgent.ID} {
+			if err := repos.Requests.Enqueue(ctx, id, domain.RequestPending, 0); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+		}
+		if err := repos.Requests.Enqueue(ctx, urgent.ID, domain.RequestQueued, 5); err != nil {
+			t.Fatalf("Enqueue (reprioritize): %v", err)
+		}
+
+		queue, err := repos.Requests.GetQueue(ctx)
+		if err != nil {
+			t.Fatalf("GetQueue: %v", err)
+		}
+		if len(queue) != 3 || queue[0].ID != urgent.ID || queue[1].ID != first.ID || queue[2].ID != second.ID {
+			t.Fatalf("GetQueue returned %d requests in wrong order", len(queue))
+		}
+		head := queue[0]
+		if head.Status != domain.RequestQueued || head.Priority != 5 || head.QueuedAt == nil || head.UserName != "alice" {
+			t.Errorf("queued request = %+v", head)
+		}
+
+		if err := repos.Requests.UpdateStatus(ctx, urgent.ID, domain.RequestQueued, domain.RequestApproved); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		queue, _ = repos.Requests.GetQueue(ctx)
+		if len(queue) != 2 || queue[0].ID != first.ID {
+			t.Errorf("approved request still queued")
+		}
+
+		if err := repos.Requests.Enqueue(ctx, missingID(), domain.RequestPending, 0); !errors.Is(err, domain.ErrRequestNotFound) {
+			t.Errorf("Enqueue error = %v, want ErrRequestNotFound", err)
+		}
+	})
+
+	t.Run("RequeueKeepsPlace", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		request := &domain.TrainingRequest{UserID: user.ID, Topic: "Go", Description: "d", Status: domain.RequestQueued}
+		if err := repos.Requests.Create(ctx, request); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if request.QueuedAt == nil {
+			t.Fatal("Create as queued did not set queuedAt")
+		}
+		later := createRequest(t, repos, user.ID, "later")
+		if err := repos.Requests.Enqueue(ctx, later.ID, domain.RequestPending, 0); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+
+		// Changing the priority back and forth keeps the original queue time
+		if err := repos.Requests.Enqueue(ctx, request.ID, domain.RequestQueued, 0); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+		got, err := repos.Requests.GetByID(ctx, request.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.QueuedAt == nil || !got.QueuedAt.Equal(*request.QueuedAt) {
+			t.Errorf("queuedAt = %v, want %v", got.QueuedAt, request.QueuedAt)
+		}
+
+		queue, _ := repos.Requests.GetQueue(ctx)
+		if len(queue) != 2 || queue[0].ID != request.ID {
+			t.Errorf("requeued request lost its place")
+		}
+	})
+
+	t.Run("ApprovalChain", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		request := &domain.TrainingRequest{
+			UserID:        user.ID,
+			Topic:         "Go",
+			Description:   "d",
+			Status:        domain.RequestAwaitingManager,
+			ApprovalChain: []domain.ApprovalStage{domain.StageManager, domain.StageAdmin},
+		}
+		if err := repos.Requests.Create(ctx, request); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := repos.Requests.GetByID(ctx, request.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.RequestAwaitingManager || len(got.ApprovalChain) != 2 ||
+			got.ApprovalChain[0] != domain.StageManager || got.ApprovalChain[1] != domain.StageAdmin {
+			t.Errorf("GetByID returned status=%s chain=%v", got.Status, got.ApprovalChain)
+		}
+
+		// Requests created without a chain read back an empty one
+		plain := createRequest(t, repos, user.ID, "plain")
+		got, _ = repos.Requests.GetByID(ctx, plain.ID)
+		if got.ApprovalChain == nil || len(got.ApprovalChain) != 0 {
+			t.Errorf("chain of a request created without one = %v, want empty", got.ApprovalChain)
+		}
+	})
+
+	t.Run("GetByManagerID", func(t *testing.T) {
+		repos := newRepos(t)
+		boss := createUser(t, repos, "boss")
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+		carol := createUser(t, repos, "carol")
+		for _, user := range []*domain.User{alice, bob} {
+			if err := repos.Users.UpdateManager(ctx, user.ID, &boss.ID); err != nil {
+				t.Fatalf("UpdateManager: %v", err)
+			}
+		}
+		first := createRequest(t, repos, alice.ID, "first")
+		second := createRequest(t, repos, bob.ID, "second")
+		createRequest(t, repos, carol.ID, "not a report")
+		createRequest(t, repos, boss.ID, "own request")
+		if err := repos.Requests.UpdateStatus(ctx, second.ID, domain.RequestPending, domain.RequestAwaitingManager); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+
+		team, err := repos.Requests.GetByManagerID(ctx, boss.ID, nil)
+		if err != nil {
+			t.Fatalf("GetByManagerID: %v", err)
+		}
+		if len(team) != 2 || team[0].ID != second.ID || team[1].ID != first.ID || team[0].UserName != "bob" {
+			t.Fatalf("GetByManagerID returned %d requests, want second then first", len(team))
+		}
+
+		status := string(domain.RequestAwaitingManager)
+		waiting, err := repos.Requests.GetByManagerID(ctx, boss.ID, &status)
+		if err != nil {
+			t.Fatalf("GetByManagerID with status: %v", err)
+		}
+		if len(waiting) != 1 || waiting[0].ID != second.ID {
+			t.Errorf("GetByManagerID(awaiting_manager) returned %d requests, want second", len(waiting))
+		}
+
+		none, err := repos.Requests.GetByManagerID(ctx, carol.ID, nil)
+		if err != nil || len(none) != 0 {
+			t.Errorf("GetByManagerID without reports = %d requests, %v", len(none), err)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testRoles(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("BuiltInSeeded", func(t *testing.T) {
+		repos := newRepos(t)
+
+		for _, want := range domain.DefaultRoles() {
+			got, err := repos.Roles.GetByName(ctx, want.Name)
+			if err != nil {
+				t.Fatalf("GetByName(%s): %v", want.Name, err)
+			}
+			if !got.BuiltIn || !slices.Equal(got.Permissions, want.Permissions) || got.Scope != want.Scope {
+				t.Errorf("role %s = %+v, want permissions %v", want.Name, got, want.Permissions)
+			}
+		}
+	})
+
+	t.Run("CreateUpdateDelete", func(t *testing.T) {
+		repos := newRepos(t)
+
+		role := &domain.Role{Name: "reviewer", Description: "Reads requests", Permissions: []domain.Permission{domain.PermRequestsView}, Scope: domain.ScopeOrganization}
+		if err := repos.Roles.Create(ctx, role); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if role.CreatedAt.IsZero() || role.UpdatedAt.IsZero() {
+			t.Errorf("Create did not fill timestamps: %+v", role)
+		}
+		if err := repos.Roles.Create(ctx, &domain.Role{Name: "reviewer"}); !errors.Is(err, domain.ErrRoleExists) {
+			t.Errorf("Create duplicate error = %v, want ErrRoleExists", err)
+		}
+
+		role.Description = "Reads and approves requests"
+		role.Permissions = []domain.Permission{domain.PermRequestsView, domain.PermRequestsApprove}
+		role.Scope = domain.ScopeDepartment
+		if err := repos.Roles.Update(ctx, role); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repos.Roles.GetByName(ctx, "reviewer")
+		if err != nil {
+			t.Fatalf("GetByName: %v", err)
+		}
+		if got.BuiltIn || got.Description != role.Description || !slices.Equal(got.Permissions, role.Permissions) || got.Scope != domain.ScopeDepartment {
+			t.Errorf("GetByName after Update = %+v", got)
+		}
+
+		all, err := repos.Roles.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) == 0 || !all[0].BuiltIn || all[len(all)-1].Name != "reviewer" {
+			t.Errorf("GetAll should list built-in roles first, got %d roles ending with %v", len(all), all[len(all)-1].Name)
+		}
+
+		if err := repos.Roles.Delete(ctx, "reviewer"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Roles.GetByName(ctx, "reviewer"); !errors.Is(err, domain.ErrRoleNotFound) {
+			t.Errorf("GetByName after Delete error = %v, want ErrRoleNotFound", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if err := repos.Roles.Update(ctx, &domain.Role{Name: "ghost"}); !errors.Is(err, domain.ErrRoleNotFound) {
+			t.Errorf("Update error = %v, want ErrRoleNotFound", err)
+		}
+		if err := repos.Roles.Delete(ctx, "ghost"); !errors.Is(err, domain.ErrRoleNotFound) {
+			t.Errorf("Delete error = %v, want ErrRoleNotFound", err)
+		}
+	})
+
+	t.Run("UsersResolvePermissions", func(t *testing.T) {
+		repos := newRepos(t)
+		role := &domain.Role{Name: "reviewer", Permissions: []domain.Permission{domain.PermRequestsView}}
+		if err := repos.Roles.Create(ctx, role); err != nil {
+			t.Fatalf("Create role: %v", err)
+		}
+		user := createUser(t, repos, "alice")
+		if len(user.Permissions) != 0 {
+			t.Errorf("employee created with permissions %v", user.Permissions)
+		}
+
+		user.Role = "reviewer"
+		if err := repos.Users.Update(ctx, user); err != nil {
+			t.Fatalf("Update user: %v", err)
+		}
+		if !user.Can(domain.PermRequestsView) {
+			t.Errorf("Update did not resolve permissions: %v", user.Permissions)
+		}
+
+		role.Permissions = []domain.Permission{domain.PermRequestsView, domain.PermAnalyticsView}
+		if err := repos.Roles.Update(ctx, role); err != nil {
+			t.Fatalf("Update role: %v", err)
+		}
+		got, err := repos.Users.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !got.Can(domain.PermAnalyticsView) {
+			t.Errorf("GetByID permissions = %v, want the role's current permissions", got.Permissions)
+		}
+
+		if err := repos.Roles.Delete(ctx, "reviewer"); !errors.Is(err, domain.ErrRoleInUse) {
+			t.Errorf("Delete assigned role error = %v, want ErrRoleInUse", err)
+		}
+
+		user.Role = "ghost"
+		if err := repos.Users.Update(ctx, user); err == nil {
+			t.Error("Update with an unknown role succeeded")
+		}
+		unknown := &domain.User{Name: "bob", Email: "bob-unknown-role@example.com", PasswordHash: "hash", Role: "ghost"}
+		if err := repos.Users.Create(ctx, unknown); err == nil {
+			t.Error("Create with an unknown role succeeded")
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testTenants(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("DefaultSeeded", func(t *testing.T) {
+		repos := newRepos(t)
+
+		got, err := repos.Tenants.GetByID(ctx, domain.DefaultTenantID)
+		if err != nil {
+			t.Fatalf("GetByID(default): %v", err)
+		}
+		if got.Slug != "default" || got.Settings.MentorCapacity != domain.DefaultMentorCapacity || got.Hosts == nil {
+			t.Errorf("default tenant = %+v", got)
+		}
+	})
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := newRepos(t)
+		tenant := createTenant(t, repos, "acme", "learn.acme.test", "acme.test")
+
+		if tenant.ID == "" || tenant.CreatedAt.IsZero() || tenant.UpdatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", tenant)
+		}
+
+		byID, err := repos.Tenants.GetByID(ctx, tenant.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if byID.Slug != "acme" || byID.Name != "Acme" || !slices.Equal(byID.Hosts, []string{"acme.test", "learn.acme.test"}) {
+			t.Errorf("GetByID returned %+v", byID)
+		}
+		if byID.Settings.MentorCapacity != 3 || byID.Settings.Branding.PrimaryColor != "#112233" {
+			t.Errorf("GetByID settings = %+v", byID.Settings)
+		}
+
+		byHost, err := repos.Tenants.GetByHost(ctx, "acme.test")
+		if err != nil {
+			t.Fatalf("GetByHost: %v", err)
+		}
+		if byHost.ID != tenant.ID {
+			t.Errorf("GetByHost returned tenant %s, want %s", byHost.ID, tenant.ID)
+		}
+
+		all, err := repos.Tenants.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 2 || all[0].ID != domain.DefaultTenantID || all[1].ID != tenant.ID {
+			t.Errorf("GetAll returned %d tenants, want default then acme", len(all))
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if _, err := repos.Tenants.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrTenantNotFound) {
+			t.Errorf("GetByID error = %v, want ErrTenantNotFound", err)
+		}
+		if _, err := repos.Tenants.GetByIDForUpdate(ctx, missingID()); !errors.Is(err, domain.ErrTenantNotFound) {
+			t.Errorf("GetByIDForUpdate error = %v, want ErrTenantNotFound", err)
+		}
+		if _, err := repos.Tenants.GetByHost(ctx, "nowhere.test"); !errors.Is(err, domain.ErrTenantNotFound) {
+			t.Errorf("GetByHost error = %v, want ErrTenantNotFound", err)
+		}
+		if err := repos.Tenants.Update(ctx, &domain.Tenant{ID: missingID(), Name: "Ghost"}); !errors.Is(err, domain.ErrTenantNotFound) {
+			t.Errorf("Update error = %v, want ErrTenantNotFound", err)
+		}
+	})
+
+	t.Run("SCIMTokenHash", func(t *testing.T) {
+		repos := newRepos(t)
+		tenant := createTenant(t, repos, "acme")
+		createTenant(t, repos, "globex")
+
+		tenant.Settings.SCIM.TokenHash = "abc123"
+		if err := repos.Tenants.Update(ctx, tenant); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repos.Tenants.GetBySCIMTokenHash(ctx, "abc123")
+		if err != nil || got.ID != tenant.ID || got.Settings.SCIM.TokenHash != "abc123" {
+			t.Fatalf("GetBySCIMTokenHash = %+v, %v", got, err)
+		}
+		if byID, err := repos.Tenants.GetByID(ctx, tenant.ID); err != nil || byID.Settings.SCIM.TokenHash != "abc123" {
+			t.Errorf("GetByID = %+v, %v, want the token hash", byID, err)
+		}
+
+		tenant.Settings.SCIM.TokenHash = ""
+		if err := repos.Tenants.Update(ctx, tenant); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		for _, hash := range []string{"abc123", ""} {
+			if _, err := repos.Tenants.GetBySCIMTokenHash(ctx, hash); !errors.Is(err, domain.ErrTenantNotFound) {
+				t.Errorf("GetBySCIMTokenHash(%q) error = %v, want ErrTenantNotFound", hash, err)
+			}
+		}
+	})
+
+	t.Run("SlugAndHostsUnique", func(t *testing.T) {
+		repos := newRepos(t)
+		createTenant(t, repos, "acme", "acme.test")
+
+		if err := repos.Tenants.Create(ctx, &domain.Tenant{Slug: "acme", Name: "Other"}); !errors.Is(err, domain.ErrTenantExists) {
+			t.Errorf("Create with taken slug error = %v, want ErrTenantExists", err)
+		}
+		if err := repos.Tenants.Create(ctx, &domain.Tenant{Slug: "other", Name: "Other", Hosts: []string{"acme.test"}}); !errors.Is(err, domain.ErrTenantExists) {
+			t.Errorf("Create with taken host error = %v, want ErrTenantExists", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		created := createTenant(t, repos, "acme", "acme.test", "old.acme.test")
+		other := createTenant(t, repos, "globex", "globex.test")
+
+		var tenant *domain.Tenant
+		err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			tenant, err = repos.Tenants.GetByIDForUpdate(ctx, created.ID)
+			if err != nil {
+				return err
+			}
+			tenant.Name = "Acme Corp"
+			tenant.Hosts = []string{"acme.test", "new.acme.test"}
+			tenant.Settings.MentorCapacity = 8
+			return repos.Tenants.Update(ctx, tenant)
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if tenant.Slug != "acme" {
+			t.Errorf("Update slug = %q, want acme", tenant.Slug)
+		}
+
+		got, err := repos.Tenants.GetByID(ctx, tenant.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Acme Corp" || got.Settings.MentorCapacity != 8 || !slices.Equal(got.Hosts, []string{"acme.test", "new.acme.test"}) {
+			t.Errorf("updates not persisted: %+v", got)
+		}
+		if _, err := repos.Tenants.GetByHost(ctx, "old.acme.test"); !errors.Is(err, domain.ErrTenantNotFound) {
+			t.Errorf("GetByHost(removed host) error = %v, want ErrTenantNotFound", err)
+		}
+
+		other.Hosts = []string{"globex.test", "acme.test"}
+		if err := repos.Tenants.Update(ctx, other); !errors.Is(err, domain.ErrTenantExists) {
+			t.Errorf("Update with taken host error = %v, want ErrTenantExists", err)
+		}
+	})
+}
+
+func testTenantIsolation(t *testing.T, newRepos Factory) {
+	t.Run("Users", func(t *testing.T) {
+		repos := newRepos(t)
+		ctx, other := tenantContexts(t, repos)
+
+		alice := createUser(t, repos, "alice")
+		twin := &domain.User{Name: "alice", Email: alice.Email, PasswordHash: "hash", Role: domain.RoleEmployee}
+		if err := repos.Users.Create(other, twin); err != nil {
+			t.Fatalf("Create with an email taken in another tenant: %v", err)
+		}
+
+		if _, err := repos.Users.GetByID(other, alice.ID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("GetByID across tenants error = %v, want ErrUserNotFound", err)
+		}
+		got, err := repos.Users.GetByEmail(other, alice.Email)
+		if err != nil || got.ID != twin.ID {
+			t.Errorf("GetByEmail returned %v, %v; want the user of the tenant", got, err)
+		}
+		if err := repos.Users.Delete(other, alice.ID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Delete across tenants error = %v, want ErrUserNotFound", err)
+		}
+
+		all, err := repos.Users.GetAll(ctx)
+		if err != nil || len(all) != 1 || all[0].ID != alice.ID {
+			t.Errorf("GetAll returned %d users, %v; want only alice", len(all), err)
+		}
+	})
+
+	t.Run("Roles", func(t *testing.T) {
+		repos := newRepos(t)
+		ctx, other := tenantContexts(t, repos)
+
+		custom := &domain.Role{Name: "reviewer", Permissions: []domain.Permission{domain.PermRequestsView}, Scope: domain.ScopeOrganization}
+		if err := repos.Roles.Create(other, custom); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := repos.Roles.GetByName(ctx, "reviewer"); !errors.Is(err, domain.ErrRoleNotFound) {
+			t.Errorf("GetByName across tenants error = %v, want ErrRoleNotFound", err)
+		}
+
+		user := &domain.User{Name: "bob", Email: "bob@example.com", PasswordHash: "hash", Role: "reviewer"}
+		if err := repos.Users.Create(ctx, user); err == nil {
+			t.Error("Create with a role of another tenant succeeded")
+		}
+	})
+
+	t.Run("MentorsAndRequests", func(t *testing.T) {
+		repos := newRepos(t)
+		ctx, other := tenantContexts(t, repos)
+
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 0)
+		request := createRequest(t, repos, user.ID, "Go")
+
+		if _, err := repos.Mentors.GetByID(other, mentor.ID); !errors.Is(err, domain.ErrMentorNotFound) {
+			t.Errorf("Mentors.GetByID across tenants error = %v, want ErrMentorNotFound", err)
+		}
+		if mentors, err := repos.Mentors.GetAll(other, domain.MentorFilter{}); err != nil || len(mentors) != 0 {
+			t.Errorf("Mentors.GetAll returned %d mentors, %v; want none", len(mentors), err)
+		}
+		if _, err := repos.Requests.GetByID(other, request.ID); !errors.Is(err, domain.ErrRequestNotFound) {
+			t.Errorf("Requests.GetByID across tenants error = %v, want ErrRequestNotFound", err)
+		}
+		if requests, err := repos.Requests.GetAll(other, nil); err != nil || len(requests) != 0 {
+			t.Errorf("Requests.GetAll returned %d requests, %v; want none", len(requests), err)
+		}
+		if requests, err := repos.Requests.GetAll(ctx, nil); err != nil || len(requests) != 1 {
+			t.Errorf("Requests.GetAll returned %d requests, %v; want 1", len(requests), err)
+		}
+	})
+
+	t.Run("Skills", func(t *testing.T) {
+		repos := newRepos(t)
+		ctx, other := tenantContexts(t, repos)
+
+		skill := &domain.Skill{Name: "Go"}
+		if err := repos.Skills.Create(ctx, skill); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repos.Skills.Create(other, &domain.Skill{Name: "go"}); err != nil {
+			t.Errorf("Create with a name taken in another tenant: %v", err)
+		}
+
+		target := &domain.SkillTarget{SkillID: skill.ID, JobTitle: ptr("Engineer"), Level: 3}
+		if err := repos.Competencies.CreateTarget(other, target); !errors.Is(err, domain.ErrSkillNotFound) {
+			t.Errorf("CreateTarget on a skill of another tenant error = %v, want ErrSkillNotFound", err)
+		}
+		if skills, err := repos.Skills.GetAll(other); err != nil || len(skills) != 1 || skills[0].ID == skill.ID {
+			t.Errorf("GetAll returned %d skills, %v; want only the tenant's", len(skills), err)
+		}
+	})
+
+	t.Run("Jobs", func(t *testing.T) {
+		repos := newRepos(t)
+		ctx, other := tenantContexts(t, repos)
+
+		now := time.Now()
+		job := &domain.Job{Kind: "report", Payload: []byte("{}"), UniqueKey: "daily", MaxAttempts: 1, RunAt: now}
+		if err := repos.Jobs.Enqueue(ctx, job); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+		if job.TenantID != domain.DefaultTenantID {
+			t.Errorf("Enqueue TenantID = %q, want the default tenant", job.TenantID)
+		}
+		twin := &domain.Job{Kind: "report", Payload: []byte("{}"), UniqueKey: "daily", MaxAttempts: 1, RunAt: now}
+		if err := repos.Jobs.Enqueue(other, twin); err != nil {
+			t.Fatalf("Enqueue with a key taken in another tenant: %v", err)
+		}
+
+		if _, err := repos.Jobs.GetByID(other, job.ID); !errors.Is(err, domain.ErrJobNotFound) {
+			t.Errorf("GetByID across tenants error = %v, want ErrJobNotFound", err)
+		}
+		if jobs, err := repos.Jobs.List(other, domain.JobFilter{}); err != nil || len(jobs) != 1 || jobs[0].ID != twin.ID {
+			t.Errorf("List returned %d jobs, %v; want only the tenant's", len(jobs), err)
+		}
+
+		// Workers claim the jobs of every tenant and learn whose they are
+		claimed := map[string]string{}
+		for range 2 {
+			got, err := repos.Jobs.Claim(ctx, "worker", []string{"report"}, now.Add(time.Second), time.Minute)
+			if err != nil || got == nil {
+				t.Fatalf("Claim returned %v, %v", got, err)
+			}
+			claimed[got.ID] = got.TenantID
+		}
+		if claimed[job.ID] != domain.DefaultTenantID || claimed[twin.ID] != twin.TenantID {
+			t.Errorf("claimed %v, want both jobs with their tenants", claimed)
+		}
+
+		// Readiness sees the newest job of every tenant at once
+		newer := &domain.Job{Kind: "report", Payload: []byte("{}"), MaxAttempts: 1, RunAt: now}
+		if err := repos.Jobs.Enqueue(ctx, newer); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+		latest, err := repos.Jobs.LatestByKind(other, "report")
+		if err != nil {
+			t.Fatalf("LatestByKind: %v", err)
+		}
+		got := map[string]string{}
+		for _, j := range latest {
+			got[j.TenantID] = j.ID
+		}
+		if len(latest) != 2 || got[domain.DefaultTenantID] != newer.ID || got[twin.TenantID] != twin.ID {
+			t.Errorf("LatestByKind = %v, want the newest job of each tenant", got)
+		}
+	})
+
+	t.Run("Certificates", func(t *testing.T) {
+		repos := newRepos(t)
+		_, other := tenantContexts(t, repos)
+
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 1)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+		certificate := newCertificate(learning.ID, "TENA-NTCE-RT01")
+		if err := repos.Certificates.Create(defaultCtx(), certificate); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		if _, err := repos.Certificates.GetByLearningID(other, learning.ID); !errors.Is(err, domain.ErrCertificateNotFound) {
+			t.Errorf("GetByLearningID across tenants error = %v, want ErrCertificateNotFound", err)
+		}
+		// Verification links are public and work whatever the tenant
+		if got, err := repos.Certificates.GetByCode(other, certificate.Code); err != nil || got.ID != certificate.ID {
+			t.Errorf("GetByCode returned %v, %v; want the certificate", got, err)
+		}
+	})
+}
+
+// createTenant inserts a tenant of capacity 3 with the hosts
+func createTenant(t *testing.T, repos Repositories, slug string, hosts ...string) *domain.Tenant {
+	t.Helper()
+
+	tenant := &domain.Tenant{
+		Slug:  slug,
+		Name:  string(slug[0]-'a'+'A') + slug[1:],
+		Hosts: hosts,
+		Settings: domain.TenantSettings{
+			MentorCapacity: 3,
+			Branding:       domain.Branding{DisplayName: slug, PrimaryColor: "#112233"},
+		},
+	}
+	if err := repos.Tenants.Create(defaultCtx(), tenant); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	return tenant
+}
+
+// tenantContexts returns a context of the default tenant and one of a new
+// tenant holding the built-in roles
+func tenantContexts(t *testing.T, repos Repositories) (context.Context, context.Context) {
+	t.Helper()
+
+	tenant := createTenant(t, repos, "t-"+uuid.New().String()[:8])
+	other := domain.WithTenant(defaultCtx(), tenant.ID)
+	for _, role := range domain.DefaultRoles() {
+		if err := repos.Roles.Create(other, role); err != nil {
+			t.Fatalf("create role: %v", err)
+		}
+	}
+	return defaultCtx(), other
+}
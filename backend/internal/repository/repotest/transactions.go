@@ -0,0 +1,100 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testTransactions(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("Commit", func(t *testing.T) {
+		repos := newRepos(t)
+		mentor := createMentor(t, repos, "Ann", 0)
+
+		err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repos.Mentors.UpdateWorkload(ctx, mentor.ID, 2); err != nil {
+				return err
+			}
+			// Writes are visible inside the transaction
+			got, err := repos.Mentors.GetByID(ctx, mentor.ID)
+			if err != nil {
+				return err
+			}
+			if got.Workload != 2 {
+				t.Errorf("workload inside transaction = %d, want 2", got.Workload)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WithinTx: %v", err)
+		}
+
+		got, _ := repos.Mentors.GetByID(ctx, mentor.ID)
+		if got.Workload != 2 {
+			t.Errorf("committed workload = %d, want 2", got.Workload)
+		}
+	})
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "Ann", 1)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+		other := createMentor(t, repos, "Max", 0)
+		failure := errors.New("boom")
+
+		var created *domain.Notification
+		err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repos.Learnings.UpdateMentor(ctx, learning.ID, mentor.ID, other.ID); err != nil {
+				return err
+			}
+			if err := repos.Mentors.UpdateWorkload(ctx, mentor.ID, 0); err != nil {
+				return err
+			}
+			created = &domain.Notification{UserID: user.ID, Kind: domain.NotificationMentorChanged, Title: "t", Body: "b"}
+			if err := repos.Notifications.Create(ctx, created); err != nil {
+				return err
+			}
+			// Nested calls join the outer transaction
+			return repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				if err := repos.Learnings.UpdateNotes(ctx, learning.ID, "handed off"); err != nil {
+					return err
+				}
+				return failure
+			})
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("WithinTx error = %v, want %v", err, failure)
+		}
+
+		got, err := repos.Learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.MentorID != mentor.ID || got.Notes != nil {
+			t.Errorf("learning changes not rolled back: mentor %s notes %v", got.MentorID, got.Notes)
+		}
+		if workload := mustMentor(t, repos, mentor.ID).Workload; workload != 1 {
+			t.Errorf("workload = %d after rollback, want 1", workload)
+		}
+		notifications, _ := repos.Notifications.GetByUserID(ctx, user.ID, false)
+		if len(notifications) != 0 {
+			t.Errorf("notification %s not rolled back", created.ID)
+		}
+	})
+}
+
+// mustMentor reads a mentor or fails the test
+func mustMentor(t *testing.T, repos Repositories, id string) *domain.Mentor {
+	t.Helper()
+
+	mentor, err := repos.Mentors.GetByID(defaultCtx(), id)
+	if err != nil {
+		t.Fatalf("get mentor: %v", err)
+	}
+	return mentor
+}
@@ -0,0 +1,253 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testUsers(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+
+		if user.ID == "" || user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+			t.Fatalf("Create did not fill ID and timestamps: %+v", user)
+		}
+
+		byID, err := repos.Users.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if byID.Email != user.Email || byID.Name != "alice" || byID.PasswordHash != "hash" || byID.Role != domain.RoleEmployee {
+			t.Errorf("GetByID returned %+v", byID)
+		}
+		if byID.JobTitle == nil || *byID.JobTitle != "Engineer" || byID.Department != nil {
+			t.Errorf("GetByID optional fields: jobTitle=%v department=%v", byID.JobTitle, byID.Department)
+		}
+
+		byEmail, err := repos.Users.GetByEmail(ctx, user.Email)
+		if err != nil {
+			t.Fatalf("GetByEmail: %v", err)
+		}
+		if byEmail.ID != user.ID {
+			t.Errorf("GetByEmail returned user %s, want %s", byEmail.ID, user.ID)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if _, err := repos.Users.GetByID(ctx, missingID()); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("GetByID error = %v, want ErrUserNotFound", err)
+		}
+		if _, err := repos.Users.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("GetByEmail error = %v, want ErrUserNotFound", err)
+		}
+		if err := repos.Users.Update(ctx, &domain.User{ID: missingID(), Role: domain.RoleEmployee}); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Update error = %v, want ErrUserNotFound", err)
+		}
+		if err := repos.Users.Delete(ctx, missingID()); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Delete error = %v, want ErrUserNotFound", err)
+		}
+		if err := repos.Users.UpdateDeactivatedAt(ctx, missingID(), nil); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("UpdateDeactivatedAt error = %v, want ErrUserNotFound", err)
+		}
+	})
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+
+		duplicate := &domain.User{Name: "bob", Email: user.Email, PasswordHash: "hash", Role: domain.RoleEmployee}
+		if err := repos.Users.Create(ctx, duplicate); err == nil {
+			t.Error("Create with duplicate email succeeded")
+		}
+	})
+
+	t.Run("ExternalID", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos, "alice")
+		bob := createUser(t, repos, "bob")
+
+		alice.ExternalID = ptr("00u1")
+		if err := repos.Users.Update(ctx, alice); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, _ := repos.Users.GetByID(ctx, alice.ID)
+		if got.ExternalID == nil || *got.ExternalID != "00u1" {
+			t.Fatalf("ExternalID = %v, want 00u1", got.ExternalID)
+		}
+
+		bob.ExternalID = ptr("00u1")
+		if err := repos.Users.Update(ctx, bob); err == nil {
+			t.Error("Update to a taken external ID succeeded")
+		}
+		duplicate := &domain.User{Name: "carol", Email: "carol@example.com", PasswordHash: "hash", Role: domain.RoleEmployee, ExternalID: ptr("00u1")}
+		if err := repos.Users.Create(ctx, duplicate); err == nil {
+			t.Error("Create with a taken external ID succeeded")
+		}
+		// Users without one do not collide
+		dave := &domain.User{Name: "dave", Email: "dave@example.com", PasswordHash: "hash", Role: domain.RoleEmployee}
+		if err := repos.Users.Create(ctx, dave); err != nil {
+			t.Errorf("Create without an external ID: %v", err)
+		}
+	})
+
+	t.Run("GetAllNewestFirst", func(t *testing.T) {
+		repos := newRepos(t)
+		first := createUser(t, repos, "first")
+		second := createUser(t, repos, "second")
+		third := createUser(t, repos, "third")
+
+		users, err := repos.Users.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+
+		want := []string{third.ID, second.ID, first.ID}
+		if len(users) != len(want) {
+			t.Fatalf("GetAll returned %d users, want %d", len(users), len(want))
+		}
+		for i, id := range want {
+			if users[i].ID != id {
+				t.Errorf("users[%d] = %s, want %s", i, users[i].Name, id)
+			}
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		createdUpdatedAt := user.UpdatedAt
+		engineering := createDepartment(t, repos, "Engineering", nil)
+
+		user.Name = "Alice"
+		user.Email = "alice.renamed@example.com"
+		user.PasswordHash = "new-hash"
+		user.Role = domain.RoleAdmin
+		user.DepartmentID = &engineering.ID
+		if err := repos.Users.Update(ctx, user); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if user.UpdatedAt.Before(createdUpdatedAt) {
+			t.Errorf("UpdatedAt went backwards")
+		}
+
+		got, err := repos.Users.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Alice" || got.Email != "alice.renamed@example.com" || got.PasswordHash != "new-hash" || got.Role != domain.RoleAdmin {
+			t.Errorf("Update not persisted: %+v", got)
+		}
+		if got.DepartmentID == nil || *got.DepartmentID != engineering.ID || got.Department == nil || *got.Department != "Engineering" {
+			t.Errorf("department = %v %v, want Engineering", got.DepartmentID, got.Department)
+		}
+		if got.PermissionScope != domain.ScopeOrganization {
+			t.Errorf("permission scope = %q, want the admin role's", got.PermissionScope)
+		}
+
+		user.DepartmentID = ptr(missingID())
+		if err := repos.Users.Update(ctx, user); err == nil {
+			t.Error("Update to a missing department succeeded")
+		}
+	})
+
+	t.Run("Deactivate", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		if user.DeactivatedAt != nil {
+			t.Fatalf("new user is deactivated: %v", user.DeactivatedAt)
+		}
+
+		at := time.Now()
+		if err := repos.Users.UpdateDeactivatedAt(ctx, user.ID, &at); err != nil {
+			t.Fatalf("UpdateDeactivatedAt: %v", err)
+		}
+		got, err := repos.Users.GetByEmail(ctx, user.Email)
+		if err != nil {
+			t.Fatalf("GetByEmail: %v", err)
+		}
+		if got.DeactivatedAt == nil || got.IsActive() {
+			t.Errorf("DeactivatedAt not persisted: %+v", got)
+		}
+
+		if err := repos.Users.UpdateDeactivatedAt(ctx, user.ID, nil); err != nil {
+			t.Fatalf("UpdateDeactivatedAt(nil): %v", err)
+		}
+		got, _ = repos.Users.GetByID(ctx, user.ID)
+		if got.DeactivatedAt != nil {
+			t.Errorf("DeactivatedAt = %v after reactivation, want nil", got.DeactivatedAt)
+		}
+	})
+
+	t.Run("Manager", func(t *testing.T) {
+		repos := newRepos(t)
+		boss := createUser(t, repos, "boss")
+		alice := createUser(t, repos, "alice")
+
+		if err := repos.Users.UpdateManager(ctx, alice.ID, &boss.ID); err != nil {
+			t.Fatalf("UpdateManager: %v", err)
+		}
+		got, _ := repos.Users.GetByID(ctx, alice.ID)
+		if got.ManagerID == nil || *got.ManagerID != boss.ID {
+			t.Fatalf("ManagerID = %v, want %s", got.ManagerID, boss.ID)
+		}
+
+		// A plain update keeps the manager
+		got.Name = "Alice"
+		if err := repos.Users.Update(ctx, got); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, _ = repos.Users.GetByEmail(ctx, alice.Email)
+		if got.ManagerID == nil || *got.ManagerID != boss.ID {
+			t.Errorf("Update dropped the manager: %v", got.ManagerID)
+		}
+
+		if err := repos.Users.UpdateManager(ctx, alice.ID, &alice.ID); err == nil {
+			t.Error("UpdateManager to the user themselves succeeded")
+		}
+		missing := missingID()
+		if err := repos.Users.UpdateManager(ctx, alice.ID, &missing); err == nil {
+			t.Error("UpdateManager to a missing user succeeded")
+		}
+		if err := repos.Users.UpdateManager(ctx, missingID(), &boss.ID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("UpdateManager of a missing user error = %v, want ErrUserNotFound", err)
+		}
+
+		// Deleting the manager leaves the report without one
+		if err := repos.Users.Delete(ctx, boss.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		got, _ = repos.Users.GetByID(ctx, alice.ID)
+		if got.ManagerID != nil {
+			t.Errorf("ManagerID = %v after the manager was deleted, want nil", *got.ManagerID)
+		}
+	})
+
+	t.Run("DeleteCascades", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "alice")
+		mentor := createMentor(t, repos, "mentor", 0)
+		learning := createLearning(t, repos, user.ID, mentor.ID, "Go")
+
+		if err := repos.Users.Delete(ctx, user.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		if _, err := repos.Users.GetByID(ctx, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("user still exists: %v", err)
+		}
+		if _, err := repos.Requests.GetByID(ctx, learning.RequestID); !errors.Is(err, domain.ErrRequestNotFound) {
+			t.Errorf("request not cascaded: %v", err)
+		}
+		if _, err := repos.Learnings.GetByID(ctx, learning.ID); !errors.Is(err, domain.ErrLearningNotFound) {
+			t.Errorf("learning not cascaded: %v", err)
+		}
+	})
+}
@@ -0,0 +1,322 @@
+package repotest
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func testWebhooks(t *testing.T, newRepos Factory) {
+	ctx := defaultCtx()
+
+	t.Run("Subscriptions", func(t *testing.T) {
+		repos := newRepos(t)
+
+		first := createSubscription(t, repos, domain.EventLearningCompleted)
+		if first.ID == "" || first.CreatedAt.IsZero() || first.UpdatedAt.IsZero() {
+			t.Fatalf("CreateSubscription returned %+v", first)
+		}
+		second := createSubscription(t, repos)
+
+		got, err := repos.Webhooks.GetSubscription(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("GetSubscription: %v", err)
+		}
+		if got.URL != first.URL || got.Secret != first.Secret || !got.Active ||
+			len(got.EventTypes) != 1 || got.EventTypes[0] != domain.EventLearningCompleted {
+			t.Errorf("GetSubscription = %+v", got)
+		}
+		if every, _ := repos.Webhooks.GetSubscription(ctx, second.ID); every == nil || every.EventTypes == nil || len(every.EventTypes) != 0 {
+			t.Errorf("subscription to every type = %+v, want empty event types", every)
+		}
+		if _, err := repos.Webhooks.GetSubscription(ctx, missingID()); !errors.Is(err, domain.ErrWebhookNotFound) {
+			t.Errorf("GetSubscription of a missing one = %v, want ErrWebhookNotFound", err)
+		}
+
+		subs, err := repos.Webhooks.ListSubscriptions(ctx)
+		if err != nil {
+			t.Fatalf("ListSubscriptions: %v", err)
+		}
+		if len(subs) != 2 || subs[0].ID != second.ID || subs[1].ID != first.ID {
+			t.Errorf("ListSubscriptions = %+v, want both newest first", subs)
+		}
+
+		got.URL = "https://hris.example.com/hooks/v2"
+		got.EventTypes = []domain.EventType{domain.EventRequestCreated, domain.EventRequestDecided}
+		got.Active = false
+		if err := repos.Webhooks.UpdateSubscription(ctx, got); err != nil {
+			t.Fatalf("UpdateSubscription: %v", err)
+		}
+		updated, _ := repos.Webhooks.GetSubscription(ctx, first.ID)
+		if updated.URL != got.URL || updated.Active || len(updated.EventTypes) != 2 {
+			t.Errorf("after update = %+v", updated)
+		}
+		missing := *got
+		missing.ID = missingID()
+		if err := repos.Webhooks.UpdateSubscription(ctx, &missing); !errors.Is(err, domain.ErrWebhookNotFound) {
+			t.Errorf("UpdateSubscription of a missing one = %v, want ErrWebhookNotFound", err)
+		}
+
+		if err := repos.Webhooks.DeleteSubscription(ctx, first.ID); err != nil {
+			t.Fatalf("DeleteSubscription: %v", err)
+		}
+		if err := repos.Webhooks.DeleteSubscription(ctx, first.ID); !errors.Is(err, domain.ErrWebhookNotFound) {
+			t.Errorf("second DeleteSubscription = %v, want ErrWebhookNotFound", err)
+		}
+	})
+
+	t.Run("Outbox", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+
+		first := appendEvent(t, repos, domain.EventRequestCreated)
+		if first.ID == "" || first.CreatedAt.IsZero() || first.DispatchedAt != nil {
+			t.Fatalf("Append returned %+v", first)
+		}
+		second := appendEvent(t, repos, domain.EventLearningStarted)
+
+		events, err := repos.Outbox.ListUndispatched(ctx, 10)
+		if err != nil {
+			t.Fatalf("ListUndispatched: %v", err)
+		}
+		if len(events) != 2 || events[0].ID != first.ID || events[1].ID != second.ID {
+			t.Fatalf("ListUndispatched = %+v, want both oldest first", events)
+		}
+		var payload struct{ RequestID string }
+		if err := json.Unmarshal(events[0].Payload, &payload); err != nil || payload.RequestID != "r1" {
+			t.Errorf("payload = %s, %v", events[0].Payload, err)
+		}
+		if limited, _ := repos.Outbox.ListUndispatched(ctx, 1); len(limited) != 1 {
+			t.Errorf("ListUndispatched with a limit = %d events, want 1", len(limited))
+		}
+
+		if err := repos.Outbox.MarkDispatched(ctx, first.ID, now); err != nil {
+			t.Fatalf("MarkDispatched: %v", err)
+		}
+		if events, _ := repos.Outbox.ListUndispatched(ctx, 10); len(events) != 1 || events[0].ID != second.ID {
+			t.Errorf("after dispatch = %+v, want only the second event", events)
+		}
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		sub := createSubscription(t, repos)
+		other := createSubscription(t, repos)
+		event := appendEvent(t, repos, domain.EventRequestCreated)
+
+		delivery := createDelivery(t, repos, sub.ID, event.ID, now)
+		if delivery.ID == "" || delivery.Status != domain.DeliveryPending || delivery.Attempts != 0 || delivery.CreatedAt.IsZero() {
+			t.Fatalf("CreateDelivery returned %+v", delivery)
+		}
+		createDelivery(t, repos, other.ID, event.ID, now)
+		if err := repos.Webhooks.CreateDelivery(ctx, &domain.WebhookDelivery{
+			SubscriptionID: missingID(), EventID: event.ID, NextAttemptAt: now,
+		}); !errors.Is(err, domain.ErrWebhookNotFound) {
+			t.Errorf("delivery to a missing subscription = %v, want ErrWebhookNotFound", err)
+		}
+
+		got, err := repos.Webhooks.GetDelivery(ctx, delivery.ID)
+		if err != nil {
+			t.Fatalf("GetDelivery: %v", err)
+		}
+		if got.EventType != domain.EventRequestCreated || got.EventCreatedAt.IsZero() || len(got.Payload) == 0 {
+			t.Errorf("GetDelivery = %+v, want the event fields joined", got)
+		}
+		if _, err := repos.Webhooks.GetDelivery(ctx, missingID()); !errors.Is(err, domain.ErrDeliveryNotFound) {
+			t.Errorf("GetDelivery of a missing one = %v, want ErrDeliveryNotFound", err)
+		}
+
+		list, err := repos.Webhooks.ListDeliveries(ctx, domain.WebhookDeliveryFilter{SubscriptionID: sub.ID})
+		if err != nil {
+			t.Fatalf("ListDeliveries: %v", err)
+		}
+		if len(list) != 1 || list[0].ID != delivery.ID {
+			t.Errorf("ListDeliveries by subscription = %+v", list)
+		}
+		if all, _ := repos.Webhooks.ListDeliveries(ctx, domain.WebhookDeliveryFilter{Limit: 1}); len(all) != 1 {
+			t.Errorf("ListDeliveries with a limit = %d deliveries, want 1", len(all))
+		}
+
+		// Deleting a subscription takes its deliveries along
+		if err := repos.Webhooks.DeleteSubscription(ctx, other.ID); err != nil {
+			t.Fatalf("DeleteSubscription: %v", err)
+		}
+		if all, _ := repos.Webhooks.ListDeliveries(ctx, domain.WebhookDeliveryFilter{}); len(all) != 1 {
+			t.Errorf("deliveries after deleting a subscription = %d, want 1", len(all))
+		}
+	})
+
+	t.Run("Claim", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		sub := createSubscription(t, repos)
+		paused := createSubscription(t, repos)
+		paused.Active = false
+		if err := repos.Webhooks.UpdateSubscription(ctx, paused); err != nil {
+			t.Fatalf("pause subscription: %v", err)
+		}
+
+		due := createDelivery(t, repos, sub.ID, appendEvent(t, repos, domain.EventRequestCreated).ID, now.Add(-time.Minute))
+		createDelivery(t, repos, sub.ID, appendEvent(t, repos, domain.EventRequestCreated).ID, now.Add(time.Hour))
+		createDelivery(t, repos, paused.ID, appendEvent(t, repos, domain.EventRequestCreated).ID, now.Add(-time.Minute))
+
+		claimed, err := repos.Webhooks.ClaimDeliveries(ctx, now, time.Minute, 10)
+		if err != nil {
+			t.Fatalf("ClaimDeliveries: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].ID != due.ID || claimed[0].Attempts != 1 || claimed[0].EventType != domain.EventRequestCreated {
+			t.Fatalf("ClaimDeliveries = %+v, want only the due delivery of the active subscription", claimed)
+		}
+		if !claimed[0].NextAttemptAt.After(now) {
+			t.Errorf("claimed nextAttemptAt = %v, want pushed past now", claimed[0].NextAttemptAt)
+		}
+		// Another dispatcher leaves it alone until the lease runs out
+		if again, _ := repos.Webhooks.ClaimDeliveries(ctx, now, time.Minute, 10); len(again) != 0 {
+			t.Errorf("second claim = %+v, want none", again)
+		}
+		if again, _ := repos.Webhooks.ClaimDeliveries(ctx, now.Add(2*time.Minute), time.Minute, 10); len(again) != 1 || again[0].Attempts != 2 {
+			t.Errorf("claim after the lease = %+v, want the delivery again", again)
+		}
+	})
+
+	t.Run("Outcomes", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		sub := createSubscription(t, repos)
+		delivery := createDelivery(t, repos, sub.ID, appendEvent(t, repos, domain.EventRequestCreated).ID, now)
+		claim := func(at time.Time) {
+			t.Helper()
+			if claimed, err := repos.Webhooks.ClaimDeliveries(ctx, at, time.Minute, 10); err != nil || len(claimed) != 1 {
+				t.Fatalf("ClaimDeliveries = %+v, %v", claimed, err)
+			}
+		}
+
+		claim(now)
+		code := 503
+		retryAt := now.Add(time.Hour)
+		if err := repos.Webhooks.MarkFailed(ctx, delivery.ID, &code, "503 Service Unavailable", &retryAt); err != nil {
+			t.Fatalf("MarkFailed with retry: %v", err)
+		}
+		got, _ := repos.Webhooks.GetDelivery(ctx, delivery.ID)
+		if got.Status != domain.DeliveryPending || got.LastStatusCode == nil || *got.LastStatusCode != 503 ||
+			got.LastError != "503 Service Unavailable" || !got.NextAttemptAt.Equal(retryAt.Truncate(time.Microsecond)) {
+			t.Errorf("after a retried failure = %+v", got)
+		}
+
+		claim(retryAt)
+		if err := repos.Webhooks.MarkFailed(ctx, delivery.ID, nil, "connection refused", nil); err != nil {
+			t.Fatalf("MarkFailed: %v", err)
+		}
+		dead, _ := repos.Webhooks.ListDeliveries(ctx, domain.WebhookDeliveryFilter{Status: domain.DeliveryDead})
+		if len(dead) != 1 || dead[0].LastStatusCode != nil || dead[0].Attempts != 2 {
+			t.Fatalf("dead deliveries = %+v", dead)
+		}
+		if err := repos.Webhooks.MarkDelivered(ctx, delivery.ID, 200, now); !errors.Is(err, domain.ErrDeliveryNotFound) {
+			t.Errorf("MarkDelivered of a dead delivery = %v, want ErrDeliveryNotFound", err)
+		}
+
+		if err := repos.Webhooks.Redeliver(ctx, delivery.ID, now); err != nil {
+			t.Fatalf("Redeliver: %v", err)
+		}
+		if err := repos.Webhooks.Redeliver(ctx, delivery.ID, now); !errors.Is(err, domain.ErrDeliveryNotRetryable) {
+			t.Errorf("Redeliver of a pending delivery = %v, want ErrDeliveryNotRetryable", err)
+		}
+		if err := repos.Webhooks.Redeliver(ctx, missingID(), now); !errors.Is(err, domain.ErrDeliveryNotFound) {
+			t.Errorf("Redeliver of a missing one = %v, want ErrDeliveryNotFound", err)
+		}
+
+		claim(now)
+		if err := repos.Webhooks.MarkDelivered(ctx, delivery.ID, 204, now); err != nil {
+			t.Fatalf("MarkDelivered: %v", err)
+		}
+		got, _ = repos.Webhooks.GetDelivery(ctx, delivery.ID)
+		if got.Status != domain.DeliveryDelivered || got.Attempts != 1 || got.DeliveredAt == nil ||
+			got.LastError != "" || *got.LastStatusCode != 204 {
+			t.Errorf("after delivery = %+v", got)
+		}
+	})
+
+	t.Run("DeleteDispatched", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		sub := createSubscription(t, repos)
+
+		done := appendEvent(t, repos, domain.EventRequestCreated)
+		delivered := createDelivery(t, repos, sub.ID, done.ID, now)
+		failing := appendEvent(t, repos, domain.EventRequestCreated)
+		createDelivery(t, repos, sub.ID, failing.ID, now)
+		recent := appendEvent(t, repos, domain.EventRequestCreated)
+		undispatched := appendEvent(t, repos, domain.EventRequestCreated)
+
+		for _, id := range []string{done.ID, failing.ID} {
+			if err := repos.Outbox.MarkDispatched(ctx, id, now.Add(-48*time.Hour)); err != nil {
+				t.Fatalf("MarkDispatched: %v", err)
+			}
+		}
+		if err := repos.Outbox.MarkDispatched(ctx, recent.ID, now); err != nil {
+			t.Fatalf("MarkDispatched: %v", err)
+		}
+		if _, err := repos.Webhooks.ClaimDeliveries(ctx, now, time.Minute, 1); err != nil {
+			t.Fatalf("ClaimDeliveries: %v", err)
+		}
+		if err := repos.Webhooks.MarkDelivered(ctx, delivered.ID, 200, now); err != nil {
+			t.Fatalf("MarkDelivered: %v", err)
+		}
+
+		deleted, err := repos.Outbox.DeleteDispatched(ctx, now.Add(-24*time.Hour))
+		if err != nil {
+			t.Fatalf("DeleteDispatched: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("deleted %d events, want only the fully delivered one", deleted)
+		}
+		if _, err := repos.Webhooks.GetDelivery(ctx, delivered.ID); !errors.Is(err, domain.ErrDeliveryNotFound) {
+			t.Errorf("delivery of a deleted event = %v, want ErrDeliveryNotFound", err)
+		}
+		if events, _ := repos.Outbox.ListUndispatched(ctx, 10); len(events) != 1 || events[0].ID != undispatched.ID {
+			t.Errorf("undispatched events = %+v", events)
+		}
+	})
+}
+
+// createSubscription inserts an active subscription to the event types
+func createSubscription(t *testing.T, repos Repositories, types ...domain.EventType) *domain.WebhookSubscription {
+	t.Helper()
+
+	sub := &domain.WebhookSubscription{
+		URL:        "https://hris.example.com/hooks",
+		Secret:     "0123456789abcdef",
+		EventTypes: types,
+		Active:     true,
+	}
+	if err := repos.Webhooks.CreateSubscription(defaultCtx(), sub); err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	return sub
+}
+
+// appendEvent writes an event about request r1 to the outbox
+func appendEvent(t *testing.T, repos Repositories, eventType domain.EventType) *domain.OutboxEvent {
+	t.Helper()
+
+	event := &domain.OutboxEvent{Type: eventType, Payload: json.RawMessage(`{"requestId":"r1"}`)}
+	if err := repos.Outbox.Append(defaultCtx(), event); err != nil {
+		t.Fatalf("append event: %v", err)
+	}
+	return event
+}
+
+// createDelivery inserts a pending delivery due at the time
+func createDelivery(t *testing.T, repos Repositories, subscriptionID, eventID string, at time.Time) *domain.WebhookDelivery {
+	t.Helper()
+
+	delivery := &domain.WebhookDelivery{SubscriptionID: subscriptionID, EventID: eventID, NextAttemptAt: at}
+	if err := repos.Webhooks.CreateDelivery(defaultCtx(), delivery); err != nil {
+		t.Fatalf("create delivery: %v", err)
+	}
+	return delivery
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// ApprovalService walks training requests through the configured approval
+// chain: the employee's line manager signs off first, then an L&D admin or
+// the head of the employee's department.
+// A request that clears its last stage joins the queue and gets a mentor as
+// soon as one is free; a course enrollment request takes a seat instead.
+type ApprovalService struct {
+	tx             domain.TxManager
+	requestRepo    domain.RequestRepository
+	userRepo       domain.UserRepository
+	departmentRepo domain.DepartmentRepository
+	approvalRepo   domain.ApprovalRepository
+	enrollmentRepo domain.EnrollmentRepository
+	queue          *QueueService
+	notifications  *NotificationService
+	outbox         *OutboxService
+	chain          []domain.ApprovalStage
+}
+
+func NewApprovalService(
+	tx domain.TxManager,
+	requestRepo domain.RequestRepository,
+	userRepo domain.UserRepository,
+	departmentRepo domain.DepartmentRepository,
+	approvalRepo domain.ApprovalRepository,
+	enrollmentRepo domain.EnrollmentRepository,
+	queue *QueueService,
+	notifications *NotificationService,
+	outbox *OutboxService,
+	chain []domain.ApprovalStage,
+) *ApprovalService {
+	return &ApprovalService{
+		tx:             tx,
+		requestRepo:    requestRepo,
+		userRepo:       userRepo,
+		departmentRepo: departmentRepo,
+		approvalRepo:   approvalRepo,
+		enrollmentRepo: enrollmentRepo,
+		queue:          queue,
+		notifications:  notifications,
+		outbox:         outbox,
+		chain:          chain,
+	}
+}
+
+// chainFor returns the stages a new request of the user goes through. The
+// manager stage is skipped for users without an active manager; self-service
+// requests skip the admin stage since they are assigned automatically.
+func (s *ApprovalService) chainFor(ctx context.Context, userID string, selfService bool) ([]domain.ApprovalStage, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	chain := make([]domain.ApprovalStage, 0, len(s.chain))
+	for _, stage := range s.chain {
+		switch stage {
+		case domain.StageManager:
+			manager, err := s.activeManager(ctx, user)
+			if err != nil {
+				return nil, err
+			}
+			if manager == nil {
+				continue
+			}
+		case domain.StageAdmin:
+			if selfService {
+				continue
+			}
+		}
+		chain = append(chain, stage)
+	}
+	return chain, nil
+}
+
+// submit stores a new request at the first stage of its chain and asks the
+// manager for a decision when that stage is theirs. A request with an empty
+// chain is stored queued and the queue is served right away, or approved
+// and enrolled if it is for a course.
+func (s *ApprovalService) submit(ctx context.Context, request *domain.TrainingRequest) error {
+	request.Status = domain.RequestQueued
+	if request.IsCourseEnrollment() {
+		request.Status = domain.RequestApproved
+	}
+	if len(request.ApprovalChain) > 0 {
+		request.Status = request.ApprovalChain[0].Status()
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.requestRepo.Create(ctx, request); err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if err := s.outbox.Publish(ctx, domain.EventRequestCreated, requestEvent(request)); err != nil {
+			return err
+		}
+		if request.IsApproved() {
+			return s.enroll(ctx, request)
+		}
+		return s.askApprover(ctx, request)
+	})
+	if err != nil {
+		return err
+	}
+
+	if request.IsQueued() {
+		s.queue.serveQueue(ctx)
+	}
+	return nil
+}
+
+// GetTeamRequests lists the requests of the users reporting to a manager,
+// optionally only those with the given status
+func (s *ApprovalService) GetTeamRequests(ctx context.Context, managerID string, status *string) ([]*domain.TrainingRequest, error) {
+	requests, err := s.requestRepo.GetByManagerID(ctx, managerID, status)
+	if err != nil {
+		return nil, err
+	}
+	return requests, setQueuePositions(ctx, s.requestRepo, requests...)
+}
+
+// GetDecisions lists the decisions taken on a request, oldest first
+func (s *ApprovalService) GetDecisions(ctx context.Context, requestID string) ([]*domain.ApprovalDecision, error) {
+	if _, err := s.requestRepo.GetByID(ctx, requestID); err != nil {
+		return nil, err
+	}
+	return s.approvalRepo.GetByRequestID(ctx, requestID)
+}
+
+// IsManagerOf checks if managerID is the current line manager of userID
+func (s *ApprovalService) IsManagerOf(ctx context.Context, managerID, userID string) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.ManagerID != nil && *user.ManagerID == managerID, nil
+}
+
+// Decide records the decision of deciderID on the stage the request is
+// waiting at. The manager stage is decided by the requester's manager or a
+// user with requests.approve, the admin stage by a user with
+// requests.approve; nobody decides on their own request.
+// A rejection closes the request; an approval moves it to the next stage of
+// its chain, or after the last one into the queue or onto the course.
+func (s *ApprovalService) Decide(ctx context.Context, requestID, deciderID string, decision domain.Decision, comment *string) (*domain.TrainingRequest, error) {
+	if !decision.IsValid() {
+		return nil, fmt.Errorf("%w: unknown decision %q", domain.ErrInvalidInput, decision)
+	}
+
+	decider, err := s.userRepo.GetByID(ctx, deciderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The request is read and moved on in one transaction, and the status
+	// change only applies while it is still at the stage read here, so of
+	// two concurrent decisions only the first counts
+	var request *domain.TrainingRequest
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		request, err = s.requestRepo.GetByID(ctx, requestID)
+		if err != nil {
+			return err
+		}
+		stage, ok := request.Stage()
+		if !ok {
+			return domain.ErrRequestNotAwaitingApproval
+		}
+		if err := s.ensureApprover(ctx, request, stage, decider); err != nil {
+			return err
+		}
+
+		if err := s.approvalRepo.Create(ctx, &domain.ApprovalDecision{
+			RequestID: request.ID,
+			Stage:     stage,
+			DeciderID: &decider.ID,
+			Decision:  decision,
+			Comment:   comment,
+		}); err != nil {
+			return fmt.Errorf("failed to record decision: %w", err)
+		}
+
+		if err := s.advance(ctx, request, stage, decision); err != nil {
+			if errors.Is(err, domain.ErrRequestChanged) {
+				return domain.ErrRequestNotAwaitingApproval
+			}
+			return err
+		}
+
+		event := requestEvent(request)
+		event.Stage = stage
+		event.Decision = decision
+		event.DeciderID = decider.ID
+		if err := s.outbox.Publish(ctx, domain.EventRequestDecided, event); err != nil {
+			return err
+		}
+
+		return s.notifications.Notify(
+			ctx, request.UserID, domain.NotificationRequestDecided,
+			fmt.Sprintf("Your request was %s", decision),
+			fmt.Sprintf("%s %s %q%s.", decider.Name, decision, request.Topic, commentSuffix(comment)),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if request.IsQueued() {
+		s.queue.serveQueue(ctx)
+	}
+	return s.queue.getRequest(ctx, request.ID)
+}
+
+// advance moves a request past a decided stage and asks the next approver;
+// caller runs it in a transaction. It fails with ErrRequestChanged when the
+// request left the stage in the meantime.
+func (s *ApprovalService) advance(ctx context.Context, request *domain.TrainingRequest, stage domain.ApprovalStage, decision domain.Decision) error {
+	from := request.Status
+	if decision == domain.DecisionRejected {
+		request.Reject()
+		if err := s.requestRepo.UpdateStatus(ctx, request.ID, from, request.Status); err != nil {
+			return fmt.Errorf("failed to reject request: %w", err)
+		}
+		return nil
+	}
+
+	next, ok := request.NextStage(stage)
+	if !ok && request.IsCourseEnrollment() {
+		request.Approve()
+		if err := s.requestRepo.UpdateStatus(ctx, request.ID, from, request.Status); err != nil {
+			return fmt.Errorf("failed to approve request: %w", err)
+		}
+		return s.enroll(ctx, request)
+	}
+	if !ok {
+		request.Status = domain.RequestQueued
+		if err := s.requestRepo.Enqueue(ctx, request.ID, from, request.Priority); err != nil {
+			return fmt.Errorf("failed to enqueue request: %w", err)
+		}
+		return nil
+	}
+
+	request.Status = next.Status()
+	if err := s.requestRepo.UpdateStatus(ctx, request.ID, from, request.Status); err != nil {
+		return fmt.Errorf("failed to update request status: %w", err)
+	}
+	return s.askApprover(ctx, request)
+}
+
+// enroll gives an approved course request its seat; a full course fails
+// with ErrCourseFull so the caller's transaction rolls back
+func (s *ApprovalService) enroll(ctx context.Context, request *domain.TrainingRequest) error {
+	return s.enrollmentRepo.Create(ctx, &domain.CourseEnrollment{
+		CourseID:  *request.CourseID,
+		UserID:    request.UserID,
+		RequestID: request.ID,
+	})
+}
+
+// ensureApprover checks that the decider may decide the stage: approvers
+// decide every stage of the requests in their reach, line managers the
+// manager stage of their reports
+func (s *ApprovalService) ensureApprover(ctx context.Context, request *domain.TrainingRequest, stage domain.ApprovalStage, decider *domain.User) error {
+	if decider.ID == request.UserID {
+		return domain.ErrNotApprover
+	}
+	scope, err := permissionScope(ctx, s.departmentRepo, decider, domain.PermRequestsApprove)
+	if err != nil {
+		return err
+	}
+	if scope.All {
+		return nil
+	}
+	if !scope.IsEmpty() {
+		requester, err := s.userRepo.GetByID(ctx, request.UserID)
+		if err != nil {
+			return err
+		}
+		if scope.Includes(requester) {
+			return nil
+		}
+	}
+	if stage != domain.StageManager {
+		return domain.ErrNotApprover
+	}
+
+	isManager, err := s.IsManagerOf(ctx, decider.ID, request.UserID)
+	if err != nil {
+		return err
+	}
+	if !isManager {
+		return domain.ErrNotApprover
+	}
+	return nil
+}
+
+// askApprover tells the manager that a request of their report waits for
+// them; other stages are picked up from the admin request list
+func (s *ApprovalService) askApprover(ctx context.Context, request *domain.TrainingRequest) error {
+	if !request.IsAwaitingManager() {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, request.UserID)
+	if err != nil {
+		return err
+	}
+	manager, err := s.activeManager(ctx, user)
+	if err != nil || manager == nil {
+		return err
+	}
+
+	return s.notifications.Notify(
+		ctx, manager.ID, domain.NotificationApprovalNeeded,
+		"A training request needs your approval",
+		fmt.Sprintf("%s asks for training on %q.", user.Name, request.Topic),
+	)
+}
+
+// activeManager returns the user's manager, or nil if they have none or the
+// manager is deactivated
+func (s *ApprovalService) activeManager(ctx context.Context, user *domain.User) (*domain.User, error) {
+	if user.ManagerID == nil {
+		return nil, nil
+	}
+
+	manager, err := s.userRepo.GetByID(ctx, *user.ManagerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manager: %w", err)
+	}
+	if !manager.IsActive() {
+		return nil, nil
+	}
+	return manager, nil
+}
+
+// commentSuffix formats an optional decision comment for a notification
+func commentSuffix(comment *string) string {
+	if comment == nil || *comment == "" {
+		return ""
+	}
+	return fmt.Sprintf(": %s", *comment)
+}
@@ -0,0 +1,426 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/memory"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// reportsTo makes manager the line manager of user
+func (e *env) reportsTo(t *testing.T, user, manager *domain.User) {
+	t.Helper()
+
+	if err := e.users.UpdateManager(defaultCtx(), user.ID, &manager.ID); err != nil {
+		t.Fatalf("set manager: %v", err)
+	}
+	user.ManagerID = &manager.ID
+}
+
+// addAdmin stores an admin account
+func (e *env) addAdmin(t *testing.T, name string) *domain.User {
+	t.Helper()
+
+	admin := e.addUser(t, name)
+	if _, err := e.user.ChangeRole(defaultCtx(), admin.ID, domain.RoleAdmin); err != nil {
+		t.Fatalf("promote admin: %v", err)
+	}
+	admin.Role = domain.RoleAdmin
+	return admin
+}
+
+func TestApprovalService_Chain(t *testing.T) {
+	manager := domain.StageManager
+	admin := domain.StageAdmin
+
+	tests := []struct {
+		name       string
+		chain      []domain.ApprovalStage
+		hasManager bool
+		inactive   bool
+		wantStatus domain.RequestStatus
+		wantChain  []domain.ApprovalStage
+		// self-service requests skip the admin stage
+		wantSelfService domain.RequestStatus
+	}{
+		{
+			name: "admin only", chain: []domain.ApprovalStage{admin}, hasManager: true,
+			wantStatus: domain.RequestPending, wantChain: []domain.ApprovalStage{admin},
+			wantSelfService: domain.RequestApproved,
+		},
+		{
+			name: "manager then admin", chain: []domain.ApprovalStage{manager, admin}, hasManager: true,
+			wantStatus: domain.RequestAwaitingManager, wantChain: []domain.ApprovalStage{manager, admin},
+			wantSelfService: domain.RequestAwaitingManager,
+		},
+		{
+			name: "no manager skips the stage", chain: []domain.ApprovalStage{manager, admin},
+			wantStatus: domain.RequestPending, wantChain: []domain.ApprovalStage{admin},
+			wantSelfService: domain.RequestApproved,
+		},
+		{
+			name: "deactivated manager skips the stage", chain: []domain.ApprovalStage{manager, admin}, hasManager: true, inactive: true,
+			wantStatus: domain.RequestPending, wantChain: []domain.ApprovalStage{admin},
+			wantSelfService: domain.RequestApproved,
+		},
+		{
+			name: "manager only", chain: []domain.ApprovalStage{manager}, hasManager: true,
+			wantStatus: domain.RequestAwaitingManager, wantChain: []domain.ApprovalStage{manager},
+			wantSelfService: domain.RequestAwaitingManager,
+		},
+		{
+			name: "empty chain goes to the queue", chain: []domain.ApprovalStage{},
+			wantStatus: domain.RequestApproved, wantChain: []domain.ApprovalStage{},
+			wantSelfService: domain.RequestApproved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnvWithChain(t, tt.chain...)
+			ctx := defaultCtx()
+			e.addMentor(t, "ann", 0)
+			alice := e.addUser(t, "alice")
+			if tt.hasManager {
+				boss := e.addUser(t, "boss")
+				e.reportsTo(t, alice, boss)
+				if tt.inactive {
+					_, err := e.user.DeactivateUser(ctx, boss.ID, "admin")
+					expectErr(t, err, nil)
+				}
+			}
+
+			request, err := e.request.CreateRequest(ctx, alice.ID, "Go", "Generics", nil)
+			expectErr(t, err, nil)
+			if request.Status != tt.wantStatus || len(request.ApprovalChain) != len(tt.wantChain) {
+				t.Fatalf("CreateRequest = %s with chain %v, want %s with %v", request.Status, request.ApprovalChain, tt.wantStatus, tt.wantChain)
+			}
+			for i := range tt.wantChain {
+				if request.ApprovalChain[i] != tt.wantChain[i] {
+					t.Errorf("chain = %v, want %v", request.ApprovalChain, tt.wantChain)
+				}
+			}
+
+			learning, selfService, err := e.learning.CreateLearningFromRequest(ctx, alice.ID, "Rust", "Ownership", nil)
+			expectErr(t, err, nil)
+			if selfService.Status != tt.wantSelfService {
+				t.Errorf("self-service request status = %s, want %s", selfService.Status, tt.wantSelfService)
+			}
+			if (learning != nil) != (tt.wantSelfService == domain.RequestApproved) {
+				t.Errorf("self-service learning = %v, want one only when approved", learning)
+			}
+		})
+	}
+}
+
+func TestApprovalService_Decide(t *testing.T) {
+	e := newEnvWithChain(t, domain.StageManager, domain.StageAdmin)
+	ctx := defaultCtx()
+	mentor := e.addMentor(t, "ann", 0)
+	admin := e.addAdmin(t, "root")
+	boss := e.addUser(t, "boss")
+	alice := e.addUser(t, "alice")
+	e.reportsTo(t, alice, boss)
+
+	request, err := e.request.CreateRequest(ctx, alice.ID, "Go", "Generics", nil)
+	expectErr(t, err, nil)
+
+	asked, _ := e.notification.GetUserNotifications(ctx, boss.ID, false)
+	if len(asked) != 1 || asked[0].Kind != domain.NotificationApprovalNeeded {
+		t.Fatalf("manager got %d notifications, want one approval_needed", len(asked))
+	}
+
+	// The admin cannot assign a mentor before the manager signs off
+	_, err = e.request.AssignMentor(ctx, request.ID, mentor.ID)
+	expectErr(t, err, domain.ErrRequestNotPending)
+
+	request, err = e.approval.Decide(ctx, request.ID, boss.ID, domain.DecisionApproved, ptr("Good fit for the team"))
+	expectErr(t, err, nil)
+	if request.Status != domain.RequestPending {
+		t.Fatalf("status after manager approval = %s, want pending", request.Status)
+	}
+
+	// The admin approves without picking a mentor: the queue assigns one
+	request, err = e.approval.Decide(ctx, request.ID, admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, nil)
+	if request.Status != domain.RequestApproved || e.workload(t, mentor.ID) != 1 {
+		t.Fatalf("status after admin approval = %s with workload %d, want approved with 1", request.Status, e.workload(t, mentor.ID))
+	}
+
+	decisions, err := e.approval.GetDecisions(ctx, request.ID)
+	expectErr(t, err, nil)
+	if len(decisions) != 2 ||
+		decisions[0].Stage != domain.StageManager || decisions[0].DeciderName != "boss" || decisions[0].Comment == nil ||
+		decisions[1].Stage != domain.StageAdmin || *decisions[1].DeciderID != admin.ID {
+		t.Errorf("decisions = %+v", decisions)
+	}
+
+	notifications, _ := e.notification.GetUserNotifications(ctx, alice.ID, false)
+	kinds := map[domain.NotificationKind]int{}
+	for _, n := range notifications {
+		kinds[n.Kind]++
+	}
+	if kinds[domain.NotificationRequestDecided] != 2 || kinds[domain.NotificationRequestAssigned] != 1 {
+		t.Errorf("learner notifications = %v, want two decisions and one assignment", kinds)
+	}
+
+	_, err = e.approval.Decide(ctx, request.ID, admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, domain.ErrRequestNotAwaitingApproval)
+}
+
+func TestApprovalService_DecideRejects(t *testing.T) {
+	e := newEnvWithChain(t, domain.StageManager, domain.StageAdmin)
+	ctx := defaultCtx()
+	boss := e.addUser(t, "boss")
+	alice := e.addUser(t, "alice")
+	e.reportsTo(t, alice, boss)
+
+	request, err := e.request.CreateRequest(ctx, alice.ID, "Go", "Generics", nil)
+	expectErr(t, err, nil)
+
+	request, err = e.approval.Decide(ctx, request.ID, boss.ID, domain.DecisionRejected, ptr("Not this quarter"))
+	expectErr(t, err, nil)
+	if !request.IsRejected() {
+		t.Fatalf("status after rejection = %s, want rejected", request.Status)
+	}
+
+	notifications, _ := e.notification.GetUserNotifications(ctx, alice.ID, false)
+	if len(notifications) != 1 || notifications[0].Kind != domain.NotificationRequestDecided {
+		t.Fatalf("learner got %d notifications, want one request_decided", len(notifications))
+	}
+	if want := `boss rejected "Go": Not this quarter.`; notifications[0].Body != want {
+		t.Errorf("notification body = %q, want %q", notifications[0].Body, want)
+	}
+}
+
+func TestApprovalService_ConcurrentDecisions(t *testing.T) {
+	e := newEnvWithChain(t, domain.StageManager, domain.StageAdmin)
+	ctx := defaultCtx()
+	boss := e.addUser(t, "boss")
+	alice := e.addUser(t, "alice")
+	e.reportsTo(t, alice, boss)
+
+	request, err := e.request.CreateRequest(ctx, alice.ID, "Go", "Generics", nil)
+	expectErr(t, err, nil)
+
+	// A double click decides the request once
+	errs := race(5, func() error {
+		_, err := e.approval.Decide(ctx, request.ID, boss.ID, domain.DecisionRejected, nil)
+		return err
+	})
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		expectErr(t, err, domain.ErrRequestNotAwaitingApproval)
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d decisions succeeded, want 1", succeeded)
+	}
+
+	decisions, _ := e.approval.GetDecisions(ctx, request.ID)
+	if len(decisions) != 1 {
+		t.Errorf("%d decisions recorded, want 1", len(decisions))
+	}
+	notifications, _ := e.notification.GetUserNotifications(ctx, alice.ID, false)
+	if len(notifications) != 1 {
+		t.Errorf("learner got %d notifications, want 1", len(notifications))
+	}
+}
+
+// staleRequests returns a request as it was before someone else decided it,
+// as a decision sees it when it read the request just before that
+type staleRequests struct {
+	*memory.RequestRepository
+	stale domain.TrainingRequest
+}
+
+func (r *staleRequests) GetByID(ctx context.Context, id string) (*domain.TrainingRequest, error) {
+	if id != r.stale.ID {
+		return r.RequestRepository.GetByID(ctx, id)
+	}
+	request := r.stale
+	return &request, nil
+}
+
+func TestApprovalService_DecideStaleRequest(t *testing.T) {
+	e := newEnvWithChain(t, domain.StageManager, domain.StageAdmin)
+	ctx := defaultCtx()
+	boss := e.addUser(t, "boss")
+	alice := e.addUser(t, "alice")
+	e.reportsTo(t, alice, boss)
+
+	request, err := e.request.CreateRequest(ctx, alice.ID, "Go", "Generics", nil)
+	expectErr(t, err, nil)
+	stale := *request
+	_, err = e.approval.Decide(ctx, request.ID, boss.ID, domain.DecisionRejected, nil)
+	expectErr(t, err, nil)
+
+	requests := &staleRequests{RequestRepository: e.requests, stale: stale}
+	approval := service.NewApprovalService(e.tx, requests, e.users, e.departments, e.approvals, e.enrollments, e.queue, e.notification, e.outbox, []domain.ApprovalStage{domain.StageManager, domain.StageAdmin})
+	_, err = approval.Decide(ctx, request.ID, boss.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, domain.ErrRequestNotAwaitingApproval)
+
+	got, _ := e.requests.GetByID(ctx, request.ID)
+	if !got.IsRejected() {
+		t.Errorf("status = %s, want the rejection to stand", got.Status)
+	}
+	decisions, _ := e.approval.GetDecisions(ctx, request.ID)
+	if len(decisions) != 1 || decisions[0].Decision != domain.DecisionRejected {
+		t.Errorf("decisions = %+v, want only the rejection", decisions)
+	}
+}
+
+func TestApprovalService_DecidePermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		stage   domain.ApprovalStage
+		decider string
+		wantErr error
+	}{
+		{name: "manager at manager stage", stage: domain.StageManager, decider: "boss"},
+		{name: "admin at manager stage", stage: domain.StageManager, decider: "root"},
+		{name: "other employee at manager stage", stage: domain.StageManager, decider: "bob", wantErr: domain.ErrNotApprover},
+		{name: "requester at manager stage", stage: domain.StageManager, decider: "alice", wantErr: domain.ErrNotApprover},
+		{name: "admin at admin stage", stage: domain.StageAdmin, decider: "root"},
+		{name: "manager at admin stage", stage: domain.StageAdmin, decider: "boss", wantErr: domain.ErrNotApprover},
+		{name: "missing decider", stage: domain.StageAdmin, decider: "nobody", wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnvWithChain(t, domain.StageManager, domain.StageAdmin)
+			ctx := defaultCtx()
+			alice := e.addUser(t, "alice")
+			users := map[string]string{
+				"alice":  alice.ID,
+				"boss":   e.addUser(t, "boss").ID,
+				"bob":    e.addUser(t, "bob").ID,
+				"root":   e.addAdmin(t, "root").ID,
+				"nobody": "missing",
+			}
+			boss, _ := e.users.GetByID(ctx, users["boss"])
+			e.reportsTo(t, alice, boss)
+			request := e.addRequest(t, alice.ID, tt.stage.Status())
+
+			got, err := e.approval.Decide(ctx, request.ID, users[tt.decider], domain.DecisionRejected, nil)
+			expectErr(t, err, tt.wantErr)
+			if err == nil && !got.IsRejected() {
+				t.Errorf("status = %s, want rejected", got.Status)
+			}
+		})
+	}
+}
+
+func TestApprovalService_DecideInvalid(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	admin := e.addAdmin(t, "root")
+	approved := e.addRequest(t, e.addUser(t, "alice").ID, domain.RequestApproved)
+	pending := e.addRequest(t, approved.UserID, domain.RequestPending)
+
+	_, err := e.approval.Decide(ctx, approved.ID, admin.ID, domain.DecisionRejected, nil)
+	expectErr(t, err, domain.ErrRequestNotAwaitingApproval)
+	_, err = e.approval.Decide(ctx, pending.ID, admin.ID, "maybe", nil)
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.approval.Decide(ctx, "missing", admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, domain.ErrRequestNotFound)
+
+	// Approving at the last stage with no free mentor leaves the request
+	// in the queue
+	request, err := e.approval.Decide(ctx, pending.ID, admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, nil)
+	if !request.IsQueued() || request.QueuePosition != 1 {
+		t.Errorf("request = %s at position %d, want queued first", request.Status, request.QueuePosition)
+	}
+}
+
+func TestApprovalService_GetTeamRequests(t *testing.T) {
+	e := newEnvWithChain(t, domain.StageManager, domain.StageAdmin)
+	ctx := defaultCtx()
+	boss := e.addUser(t, "boss")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	e.reportsTo(t, alice, boss)
+
+	waiting, err := e.request.CreateRequest(ctx, alice.ID, "Go", "Generics", nil)
+	expectErr(t, err, nil)
+	e.addRequest(t, alice.ID, domain.RequestApproved)
+	e.addRequest(t, bob.ID, domain.RequestPending)
+
+	team, err := e.approval.GetTeamRequests(ctx, boss.ID, nil)
+	expectErr(t, err, nil)
+	if len(team) != 2 {
+		t.Errorf("team requests = %d, want 2", len(team))
+	}
+
+	status := string(domain.RequestAwaitingManager)
+	team, err = e.approval.GetTeamRequests(ctx, boss.ID, &status)
+	expectErr(t, err, nil)
+	if len(team) != 1 || team[0].ID != waiting.ID {
+		t.Errorf("awaiting team requests = %d, want alice's new request", len(team))
+	}
+
+	isManager, err := e.approval.IsManagerOf(ctx, boss.ID, alice.ID)
+	if err != nil || !isManager {
+		t.Errorf("IsManagerOf(boss, alice) = %v, %v", isManager, err)
+	}
+	isManager, err = e.approval.IsManagerOf(ctx, boss.ID, bob.ID)
+	if err != nil || isManager {
+		t.Errorf("IsManagerOf(boss, bob) = %v, %v", isManager, err)
+	}
+}
+
+func TestUserService_SetManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		manager string // empty clears the manager
+		wantErr error
+	}{
+		{name: "set", user: "alice", manager: "boss"},
+		{name: "clear", user: "alice"},
+		{name: "self", user: "alice", manager: "alice", wantErr: domain.ErrManagerCycle},
+		{name: "cycle", user: "ceo", manager: "alice", wantErr: domain.ErrManagerCycle},
+		{name: "deactivated manager", user: "alice", manager: "gone", wantErr: domain.ErrUserDeactivated},
+		{name: "missing manager", user: "alice", manager: "nobody", wantErr: domain.ErrUserNotFound},
+		{name: "missing user", user: "nobody", manager: "boss", wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			ceo := e.addUser(t, "ceo")
+			boss := e.addUser(t, "boss")
+			alice := e.addUser(t, "alice")
+			gone := e.addUser(t, "gone")
+			now := time.Now()
+			if err := e.users.UpdateDeactivatedAt(ctx, gone.ID, &now); err != nil {
+				t.Fatalf("deactivate: %v", err)
+			}
+			e.reportsTo(t, boss, ceo)
+			e.reportsTo(t, alice, boss)
+			ids := map[string]string{"ceo": ceo.ID, "boss": boss.ID, "alice": alice.ID, "gone": gone.ID, "nobody": "missing"}
+
+			var managerID *string
+			if tt.manager != "" {
+				managerID = ptr(ids[tt.manager])
+			}
+
+			user, err := e.user.SetManager(ctx, ids[tt.user], managerID)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+			if (user.ManagerID == nil) != (managerID == nil) || (managerID != nil && *user.ManagerID != *managerID) {
+				t.Errorf("ManagerID = %v, want %v", user.ManagerID, managerID)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// AttachmentService stores files attached to learnings, their plan items
+// and comments. Files are open to the participants of the learning or of
+// the comment's thread, as in CommentService. The content type is sniffed
+// from the content rather than trusted from the client.
+type AttachmentService struct {
+	attachmentRepo domain.AttachmentRepository
+	learningRepo   domain.LearningRepository
+	blobs          domain.BlobStore
+	scanner        domain.VirusScanner // nil disables scanning
+	comments       *CommentService
+	maxSize        int64
+	allowedTypes   map[string]bool
+}
+
+func NewAttachmentService(
+	attachmentRepo domain.AttachmentRepository,
+	learningRepo domain.LearningRepository,
+	blobs domain.BlobStore,
+	scanner domain.VirusScanner,
+	comments *CommentService,
+	maxSize int64,
+	allowedTypes []string,
+) *AttachmentService {
+	allowed := make(map[string]bool, len(allowedTypes))
+	for _, t := range allowedTypes {
+		allowed[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		learningRepo:   learningRepo,
+		blobs:          blobs,
+		scanner:        scanner,
+		comments:       comments,
+		maxSize:        maxSize,
+		allowedTypes:   allowed,
+	}
+}
+
+// MaxSize is the largest file accepted, in bytes
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// AttachToLearning uploads a file to a learning, or to one of its plan
+// items when planItemID is set
+func (s *AttachmentService) AttachToLearning(
+	ctx context.Context,
+	learningID string,
+	planItemID *string,
+	uploaderID, fileName string,
+	content io.Reader,
+) (*domain.Attachment, error) {
+	_, uploader, err := s.comments.join(ctx, domain.CommentOnLearning, learningID, uploaderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if planItemID != nil {
+		learning, err := s.learningRepo.GetByID(ctx, learningID)
+		if err != nil {
+			return nil, err
+		}
+		if !hasPlanItem(learning, *planItemID) {
+			return nil, fmt.Errorf("%w: plan item %q not found", domain.ErrInvalidInput, *planItemID)
+		}
+	}
+
+	return s.upload(ctx, &domain.Attachment{
+		LearningID: &learningID,
+		PlanItemID: planItemID,
+		UploaderID: &uploader.ID,
+	}, fileName, content)
+}
+
+// AttachToComment uploads a file to the user's own comment
+func (s *AttachmentService) AttachToComment(ctx context.Context, commentID, uploaderID, fileName string, content io.Reader) (*domain.Attachment, error) {
+	comment, uploader, err := s.comment(ctx, commentID, uploaderID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.IsDeleted() {
+		return nil, domain.ErrCommentDeleted
+	}
+	if !comment.IsAuthor(uploader.ID) {
+		return nil, domain.ErrNotCommentAuthor
+	}
+
+	return s.upload(ctx, &domain.Attachment{
+		CommentID:  &commentID,
+		UploaderID: &uploader.ID,
+	}, fileName, content)
+}
+
+// GetLearningAttachments lists the files of a learning and its plan items,
+// oldest first
+func (s *AttachmentService) GetLearningAttachments(ctx context.Context, learningID, viewerID string) ([]*domain.Attachment, error) {
+	if _, _, err := s.comments.join(ctx, domain.CommentOnLearning, learningID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.attachmentRepo.GetByLearningID(ctx, learningID)
+}
+
+// GetCommentAttachments lists the files of a comment, oldest first
+func (s *AttachmentService) GetCommentAttachments(ctx context.Context, commentID, viewerID string) ([]*domain.Attachment, error) {
+	comment, _, err := s.comment(ctx, commentID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.IsDeleted() {
+		return nil, domain.ErrCommentDeleted
+	}
+	return s.attachmentRepo.GetByCommentID(ctx, commentID)
+}
+
+// Download opens the content of an attachment; the caller closes it
+func (s *AttachmentService) Download(ctx context.Context, id, viewerID string) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, comment, _, err := s.get(ctx, id, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if comment != nil && comment.IsDeleted() {
+		return nil, nil, domain.ErrCommentDeleted
+	}
+
+	content, err := s.blobs.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes a file. Uploaders delete their own files,
+// moderators any; files of deleted comments can still be removed.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, id, userID string) error {
+	attachment, _, user, err := s.get(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !attachment.IsUploader(user.ID) && !user.Can(domain.PermCommentsModerate) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	// The metadata is gone, so a blob left behind is only wasted space
+	if err := s.blobs.Delete(ctx, attachment.StorageKey); err != nil {
+		slog.WarnContext(ctx, "failed to delete attachment content", "key", attachment.StorageKey, "error", err)
+	}
+	return nil
+}
+
+// upload checks, scans and stores a file, then records its metadata
+func (s *AttachmentService) upload(ctx context.Context, attachment *domain.Attachment, fileName string, content io.Reader) (*domain.Attachment, error) {
+	data, err := io.ReadAll(io.LimitReader(content, s.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, domain.ErrFileTooLarge
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", domain.ErrInvalidInput)
+	}
+
+	contentType := http.DetectContentType(data)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !s.allowedTypes[mediaType] {
+		return nil, fmt.Errorf("%w: %s", domain.ErrFileTypeNotAllowed, contentType)
+	}
+
+	attachment.FileName = domain.CleanFileName(fileName)
+	if s.scanner != nil {
+		if err := s.scanner.Scan(ctx, attachment.FileName, data); err != nil {
+			return nil, err
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	attachment.ContentType = contentType
+	attachment.Size = int64(len(data))
+	attachment.Checksum = hex.EncodeToString(sum[:])
+	attachment.StorageKey = "attachments/" + uuid.New().String()
+
+	if err := s.blobs.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		if delErr := s.blobs.Delete(ctx, attachment.StorageKey); delErr != nil {
+			slog.WarnContext(ctx, "failed to delete orphaned attachment content", "key", attachment.StorageKey, "error", delErr)
+		}
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return s.attachmentRepo.GetByID(ctx, attachment.ID)
+}
+
+// get loads an attachment, and its comment if it has one, and checks that
+// the user can see it
+func (s *AttachmentService) get(ctx context.Context, id, userID string) (*domain.Attachment, *domain.Comment, *domain.User, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if attachment.LearningID != nil {
+		_, user, err := s.comments.join(ctx, domain.CommentOnLearning, *attachment.LearningID, userID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return attachment, nil, user, nil
+	}
+
+	comment, user, err := s.comment(ctx, *attachment.CommentID, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return attachment, comment, user, nil
+}
+
+// comment loads a comment, deleted or not, and checks that the user can see
+// it
+func (s *AttachmentService) comment(ctx context.Context, commentID, userID string) (*domain.Comment, *domain.User, error) {
+	comment, err := s.comments.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, user, err := s.comments.join(ctx, comment.TargetType, comment.TargetID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if comment.IsInternal() && !user.Can(domain.PermCommentsInternal) {
+		return nil, nil, domain.ErrCommentNotFound
+	}
+	return comment, user, nil
+}
+
+// hasPlanItem checks if the learning's plan has the item
+func hasPlanItem(learning *domain.LearningProcess, itemID string) bool {
+	for _, item := range learning.Plan {
+		if item.ID == itemID {
+			return true
+		}
+	}
+	return false
+}
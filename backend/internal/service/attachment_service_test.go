@@ -0,0 +1,239 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+var testAllowedTypes = []string{"application/pdf", "image/png", "text/plain"}
+
+const pdf = "%PDF-1.4 certificate of completion"
+
+// download reads the content of an attachment
+func (e *env) download(t *testing.T, id, userID string) string {
+	t.Helper()
+
+	_, content, err := e.attachment.Download(defaultCtx(), id, userID)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatalf("read download: %v", err)
+	}
+	return string(data)
+}
+
+func TestAttachmentService_Learning(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	admin := e.addAdmin(t, "admin")
+	mentor := e.addMentor(t, "mentor", 0)
+	account := e.mentorAccount(t, mentor)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	item, _ := domain.NewLearningPlanItem("Read the spec")
+	learning.Plan = []domain.LearningPlanItem{*item}
+	if err := e.learnings.Update(ctx, learning.ID, learning.Status, learning); err != nil {
+		t.Fatalf("update plan: %v", err)
+	}
+
+	evidence, err := e.attachment.AttachToLearning(ctx, learning.ID, &item.ID, alice.ID, `C:\Users\alice\cert.pdf`, strings.NewReader(pdf))
+	expectErr(t, err, nil)
+	if evidence.FileName != "cert.pdf" || evidence.ContentType != "application/pdf" || evidence.Size != int64(len(pdf)) ||
+		len(evidence.Checksum) != 64 || evidence.UploaderName != "alice" || *evidence.PlanItemID != item.ID {
+		t.Errorf("AttachToLearning = %+v", evidence)
+	}
+
+	// Mentors attach materials to the learning itself
+	material, err := e.attachment.AttachToLearning(ctx, learning.ID, nil, account.ID, "notes.txt", strings.NewReader("Chapter 3 first"))
+	expectErr(t, err, nil)
+	if material.ContentType != "text/plain; charset=utf-8" || material.PlanItemID != nil {
+		t.Errorf("AttachToLearning = %+v", material)
+	}
+
+	_, err = e.attachment.AttachToLearning(ctx, learning.ID, nil, bob.ID, "x.pdf", strings.NewReader(pdf))
+	expectErr(t, err, domain.ErrNotParticipant)
+	_, err = e.attachment.AttachToLearning(ctx, learning.ID, ptr("missing"), alice.ID, "x.pdf", strings.NewReader(pdf))
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.attachment.AttachToLearning(ctx, "missing", nil, alice.ID, "x.pdf", strings.NewReader(pdf))
+	expectErr(t, err, domain.ErrLearningNotFound)
+
+	attachments, err := e.attachment.GetLearningAttachments(ctx, learning.ID, admin.ID)
+	expectErr(t, err, nil)
+	if len(attachments) != 2 || attachments[0].ID != evidence.ID || attachments[1].ID != material.ID {
+		t.Fatalf("GetLearningAttachments = %+v", attachments)
+	}
+	_, err = e.attachment.GetLearningAttachments(ctx, learning.ID, bob.ID)
+	expectErr(t, err, domain.ErrNotParticipant)
+
+	if got := e.download(t, evidence.ID, account.ID); got != pdf {
+		t.Errorf("download = %q", got)
+	}
+	_, _, err = e.attachment.Download(ctx, evidence.ID, bob.ID)
+	expectErr(t, err, domain.ErrNotParticipant)
+	_, _, err = e.attachment.Download(ctx, "missing", alice.ID)
+	expectErr(t, err, domain.ErrAttachmentNotFound)
+}
+
+func TestAttachmentService_Limits(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	learning := e.addLearning(t, alice.ID, e.addMentor(t, "mentor", 0), domain.LearningActive)
+
+	small := service.NewAttachmentService(e.attachments, e.learnings, e.blobs, nil, e.comment, 16, testAllowedTypes)
+	_, err := small.AttachToLearning(ctx, learning.ID, nil, alice.ID, "big.txt", strings.NewReader(strings.Repeat("x", 17)))
+	expectErr(t, err, domain.ErrFileTooLarge)
+	_, err = small.AttachToLearning(ctx, learning.ID, nil, alice.ID, "fits.txt", strings.NewReader(strings.Repeat("x", 16)))
+	expectErr(t, err, nil)
+
+	// The type is sniffed, whatever the name says
+	_, err = e.attachment.AttachToLearning(ctx, learning.ID, nil, alice.ID, "page.pdf", strings.NewReader("<html><script>alert(1)</script>"))
+	expectErr(t, err, domain.ErrFileTypeNotAllowed)
+	_, err = e.attachment.AttachToLearning(ctx, learning.ID, nil, alice.ID, "tool.pdf", bytes.NewReader([]byte("MZ\x90\x00\x03\x00\x00\x00")))
+	expectErr(t, err, domain.ErrFileTypeNotAllowed)
+	_, err = e.attachment.AttachToLearning(ctx, learning.ID, nil, alice.ID, "empty.txt", strings.NewReader(""))
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	attachments, _ := e.attachment.GetLearningAttachments(ctx, learning.ID, alice.ID)
+	if len(attachments) != 1 {
+		t.Errorf("%d attachments stored, want only the one within limits", len(attachments))
+	}
+}
+
+// fakeScanner rejects files containing the EICAR test signature
+type fakeScanner struct {
+	scanned []string
+	err     error
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, fileName string, content []byte) error {
+	s.scanned = append(s.scanned, fileName)
+	if s.err != nil {
+		return s.err
+	}
+	if bytes.Contains(content, []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+		return fmt.Errorf("%w: Eicar-Test-Signature", domain.ErrFileInfected)
+	}
+	return nil
+}
+
+func TestAttachmentService_VirusScan(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	learning := e.addLearning(t, alice.ID, e.addMentor(t, "mentor", 0), domain.LearningActive)
+	scanner := &fakeScanner{}
+	scanned := service.NewAttachmentService(e.attachments, e.learnings, e.blobs, scanner, e.comment, 1<<20, testAllowedTypes)
+
+	_, err := scanned.AttachToLearning(ctx, learning.ID, nil, alice.ID, "clean.txt", strings.NewReader("all good"))
+	expectErr(t, err, nil)
+	_, err = scanned.AttachToLearning(ctx, learning.ID, nil, alice.ID, "eicar.txt",
+		strings.NewReader(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`))
+	expectErr(t, err, domain.ErrFileInfected)
+	if len(scanner.scanned) != 2 || scanner.scanned[1] != "eicar.txt" {
+		t.Errorf("scanned %v", scanner.scanned)
+	}
+
+	// A scanner outage rejects the upload rather than letting it through
+	scanner.err = errors.New("clamd unreachable")
+	_, err = scanned.AttachToLearning(ctx, learning.ID, nil, alice.ID, "late.txt", strings.NewReader("all good"))
+	expectErr(t, err, errAny)
+
+	attachments, _ := scanned.GetLearningAttachments(ctx, learning.ID, alice.ID)
+	if len(attachments) != 1 || attachments[0].FileName != "clean.txt" {
+		t.Errorf("attachments after scanning = %+v", attachments)
+	}
+}
+
+func TestAttachmentService_Comment(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	boss := e.addUser(t, "boss")
+	bob := e.addUser(t, "bob")
+	admin := e.addAdmin(t, "admin")
+	e.reportsTo(t, alice, boss)
+	request := e.addRequest(t, alice.ID, domain.RequestPending)
+	comment, _ := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, nil, "Quote attached", "")
+	internal, _ := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, admin.ID, nil, "Invoice", domain.VisibilityInternal)
+
+	quote, err := e.attachment.AttachToComment(ctx, comment.ID, alice.ID, "quote.pdf", strings.NewReader(pdf))
+	expectErr(t, err, nil)
+	if quote.CommentID == nil || *quote.CommentID != comment.ID || quote.LearningID != nil {
+		t.Errorf("AttachToComment = %+v", quote)
+	}
+
+	// Only the author attaches to a comment
+	_, err = e.attachment.AttachToComment(ctx, comment.ID, boss.ID, "x.pdf", strings.NewReader(pdf))
+	expectErr(t, err, domain.ErrNotCommentAuthor)
+	_, err = e.attachment.AttachToComment(ctx, comment.ID, bob.ID, "x.pdf", strings.NewReader(pdf))
+	expectErr(t, err, domain.ErrNotParticipant)
+
+	// Files of internal comments stay hidden from employees
+	invoice, err := e.attachment.AttachToComment(ctx, internal.ID, admin.ID, "invoice.pdf", strings.NewReader(pdf))
+	expectErr(t, err, nil)
+	_, _, err = e.attachment.Download(ctx, invoice.ID, alice.ID)
+	expectErr(t, err, domain.ErrCommentNotFound)
+	_, err = e.attachment.GetCommentAttachments(ctx, internal.ID, alice.ID)
+	expectErr(t, err, domain.ErrCommentNotFound)
+
+	attachments, err := e.attachment.GetCommentAttachments(ctx, comment.ID, boss.ID)
+	expectErr(t, err, nil)
+	if len(attachments) != 1 || attachments[0].ID != quote.ID {
+		t.Errorf("GetCommentAttachments = %+v", attachments)
+	}
+	if got := e.download(t, quote.ID, boss.ID); got != pdf {
+		t.Errorf("download = %q", got)
+	}
+
+	// A deleted comment hides its files, but the uploader can still remove them
+	expectErr(t, e.comment.DeleteComment(ctx, comment.ID, alice.ID), nil)
+	_, err = e.attachment.GetCommentAttachments(ctx, comment.ID, alice.ID)
+	expectErr(t, err, domain.ErrCommentDeleted)
+	_, _, err = e.attachment.Download(ctx, quote.ID, alice.ID)
+	expectErr(t, err, domain.ErrCommentDeleted)
+	_, err = e.attachment.AttachToComment(ctx, comment.ID, alice.ID, "x.pdf", strings.NewReader(pdf))
+	expectErr(t, err, domain.ErrCommentDeleted)
+	expectErr(t, e.attachment.DeleteAttachment(ctx, quote.ID, alice.ID), nil)
+}
+
+func TestAttachmentService_Delete(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	admin := e.addAdmin(t, "admin")
+	mentor := e.addMentor(t, "mentor", 0)
+	account := e.mentorAccount(t, mentor)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+
+	first, _ := e.attachment.AttachToLearning(ctx, learning.ID, nil, alice.ID, "a.txt", strings.NewReader("a"))
+	second, _ := e.attachment.AttachToLearning(ctx, learning.ID, nil, alice.ID, "b.txt", strings.NewReader("b"))
+
+	// Uploaders delete their own files, admins any
+	expectErr(t, e.attachment.DeleteAttachment(ctx, first.ID, account.ID), domain.ErrForbidden)
+	expectErr(t, e.attachment.DeleteAttachment(ctx, first.ID, alice.ID), nil)
+	expectErr(t, e.attachment.DeleteAttachment(ctx, second.ID, admin.ID), nil)
+	expectErr(t, e.attachment.DeleteAttachment(ctx, first.ID, alice.ID), domain.ErrAttachmentNotFound)
+
+	// The content goes with the metadata
+	stored, _ := e.attachments.GetByLearningID(ctx, learning.ID)
+	if len(stored) != 0 {
+		t.Errorf("%d attachments left", len(stored))
+	}
+	if _, err := e.blobs.Get(ctx, first.StorageKey); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("blob after delete: %v", err)
+	}
+}
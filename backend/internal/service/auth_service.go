@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -11,20 +14,22 @@ import (
 )
 
 type AuthService struct {
-	userRepo  domain.UserRepository
-	jwtSecret string
-	tokenTTL  time.Duration
+	userRepo       domain.UserRepository
+	departmentRepo domain.DepartmentRepository
+	jwtSecret      string
+	tokenTTL       time.Duration
 }
 
-func NewAuthService(userRepo domain.UserRepository, jwtSecret string, tokenTTL time.Duration) *AuthService {
+func NewAuthService(userRepo domain.UserRepository, departmentRepo domain.DepartmentRepository, jwtSecret string, tokenTTL time.Duration) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		tokenTTL:  tokenTTL,
+		userRepo:       userRepo,
+		departmentRepo: departmentRepo,
+		jwtSecret:      jwtSecret,
+		tokenTTL:       tokenTTL,
 	}
 }
 
-// Register creates a new user
+// Register creates a new user; the department, when given, must exist
 func (s *AuthService) Register(ctx context.Context, name, email, password string, department, jobTitle, telegram *string) (*domain.User, error) {
 	// Check if user already exists
 	existingUser, _ := s.userRepo.GetByEmail(ctx, email)
@@ -32,6 +37,11 @@ func (s *AuthService) Register(ctx context.Context, name, email, password string
 		return nil, domain.ErrUserAlreadyExists
 	}
 
+	departmentID, err := lookupDepartment(ctx, s.departmentRepo, orEmpty(department))
+	if err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -44,7 +54,7 @@ func (s *AuthService) Register(ctx context.Context, name, email, password string
 		Email:        email,
 		PasswordHash: string(hashedPassword),
 		Role:         domain.RoleEmployee, // Default role
-		Department:   department,
+		DepartmentID: departmentID,
 		JobTitle:     jobTitle,
 		Telegram:     telegram,
 	}
@@ -72,8 +82,12 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (string
 		return "", nil, domain.ErrInvalidCredentials
 	}
 
+	if !user.IsActive() {
+		return "", nil, domain.ErrUserDeactivated
+	}
+
 	// Generate JWT token
-	token, err := s.generateToken(user)
+	token, err := s.generateToken(ctx, user)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -84,14 +98,121 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (string
 	return token, user, nil
 }
 
-// generateToken creates a JWT token for the user
-func (s *AuthService) generateToken(user *domain.User) (string, error) {
+// CheckUserActive verifies that a token's user still exists and has not
+// been deactivated since the token was issued
+func (s *AuthService) CheckUserActive(ctx context.Context, userID string) error {
+	_, err := s.ActiveUser(ctx, userID)
+	return err
+}
+
+// ActiveUser loads a token's user with the permissions of their current
+// role, failing when they no longer exist or have been deactivated
+func (s *AuthService) ActiveUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return nil, domain.ErrUserDeactivated
+	}
+	return user, nil
+}
+
+// InvitationToken issues a link token that lets the user of the context's
+// tenant choose a password. It is signed with a key of its own, so it cannot
+// be used as an access token, and it stops working once the password
+// changes.
+func (s *AuthService) InvitationToken(ctx context.Context, user *domain.User) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":   user.ID,
+		"tenant_id": domain.TenantID(ctx),
+		"password":  passwordFingerprint(user.PasswordHash),
+		"exp":       time.Now().Add(domain.InvitationTTL).Unix(),
+		"iat":       time.Now().Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.invitationKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to sign invitation: %w", err)
+	}
+	return token, nil
+}
+
+// AcceptInvitation sets the password of an invited user and signs them in
+// to the tenant the invitation was issued in
+func (s *AuthService) AcceptInvitation(ctx context.Context, invitation, password string) (string, *domain.User, error) {
+	if len(password) < 8 {
+		return "", nil, domain.ErrWeakPassword
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(invitation, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.invitationKey(), nil
+	})
+	if err != nil {
+		return "", nil, domain.ErrInvalidInvitation
+	}
+	userID, _ := claims["user_id"].(string)
+	fingerprint, _ := claims["password"].(string)
+	if tenantID, _ := claims["tenant_id"].(string); tenantID != "" {
+		ctx = domain.WithTenant(ctx, tenantID)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", nil, domain.ErrInvalidInvitation
+	}
+	if fingerprint != passwordFingerprint(user.PasswordHash) {
+		return "", nil, domain.ErrInvalidInvitation
+	}
+	if !user.IsActive() {
+		return "", nil, domain.ErrUserDeactivated
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = string(hashedPassword)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return "", nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	token, err := s.generateToken(ctx, user)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	user.PasswordHash = ""
+	return token, user, nil
+}
+
+// invitationKey derives the signing key of invitations from the JWT secret
+func (s *AuthService) invitationKey() []byte {
+	mac := hmac.New(sha256.New, []byte(s.jwtSecret))
+	mac.Write([]byte("invitation"))
+	return mac.Sum(nil)
+}
+
+// passwordFingerprint identifies a password hash without revealing it
+func passwordFingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// generateToken creates a JWT token for the user of the context's tenant
+func (s *AuthService) generateToken(ctx context.Context, user *domain.User) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"role":    string(user.Role),
-		"exp":     time.Now().Add(s.tokenTTL).Unix(),
-		"iat":     time.Now().Unix(),
+		"user_id":   user.ID,
+		"tenant_id": domain.TenantID(ctx),
+		"email":     user.Email,
+		"role":      string(user.Role),
+		"exp":       time.Now().Add(s.tokenTTL).Unix(),
+		"iat":       time.Now().Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
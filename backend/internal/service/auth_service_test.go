@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+func TestAuthService_Register(t *testing.T) {
+	tests := []struct {
+		name       string
+		email      string
+		department string
+		wantErr    error
+	}{
+		{name: "new user", email: "new@example.com", department: "r&d"},
+		{name: "duplicate email", email: "taken@example.com", department: "R&D", wantErr: domain.ErrUserAlreadyExists},
+		{name: "unknown department", email: "new@example.com", department: "Legal", wantErr: domain.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			e.addUser(t, "taken")
+			rd := e.addDepartment(t, "R&D", nil)
+
+			user, err := e.auth.Register(defaultCtx(), "Name", tt.email, "password123", ptr(tt.department), nil, nil)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if user.ID == "" || user.Role != domain.RoleEmployee || user.PasswordHash != "" {
+				t.Errorf("Register returned %+v", user)
+			}
+			stored, err := e.users.GetByEmail(defaultCtx(), tt.email)
+			if err != nil {
+				t.Fatalf("stored user: %v", err)
+			}
+			if stored.PasswordHash == "" || stored.PasswordHash == "password123" {
+				t.Errorf("password not hashed: %q", stored.PasswordHash)
+			}
+			if stored.DepartmentID == nil || *stored.DepartmentID != rd.ID || *stored.Department != "R&D" {
+				t.Errorf("department = %v %v", stored.DepartmentID, stored.Department)
+			}
+		})
+	}
+}
+
+func TestAuthService_Login(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "valid credentials", email: "alice@example.com", password: "password123"},
+		{name: "wrong password", email: "alice@example.com", password: "wrong-password", wantErr: domain.ErrInvalidCredentials},
+		{name: "unknown email", email: "bob@example.com", password: "password123", wantErr: domain.ErrInvalidCredentials},
+		{name: "deactivated user", email: "carol@example.com", password: "password123", wantErr: domain.ErrUserDeactivated},
+		{name: "deactivated user with wrong password", email: "carol@example.com", password: "wrong-password", wantErr: domain.ErrInvalidCredentials},
+	}
+
+	e := newEnv(t)
+	registered, err := e.auth.Register(defaultCtx(), "Alice", "alice@example.com", "password123", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	carol, err := e.auth.Register(defaultCtx(), "Carol", "carol@example.com", "password123", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, err := e.user.DeactivateUser(defaultCtx(), carol.ID, registered.ID); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, user, err := e.auth.Login(defaultCtx(), tt.email, tt.password)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if user.ID != registered.ID || user.PasswordHash != "" {
+				t.Errorf("Login returned %+v", user)
+			}
+			userID, err := e.auth.ValidateToken(token)
+			if err != nil {
+				t.Fatalf("ValidateToken: %v", err)
+			}
+			if userID != registered.ID {
+				t.Errorf("token user = %s, want %s", userID, registered.ID)
+			}
+		})
+	}
+}
+
+func TestAuthService_ValidateToken(t *testing.T) {
+	e := newEnv(t)
+	if _, err := e.auth.Register(defaultCtx(), "Alice", "alice@example.com", "password123", nil, nil, nil); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	token, _, err := e.auth.Login(defaultCtx(), "alice@example.com", "password123")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	otherSecret := service.NewAuthService(e.users, e.departments, "other-secret", time.Hour)
+	expired := service.NewAuthService(e.users, e.departments, "test-secret", -time.Hour)
+	expiredToken, _, err := expired.Login(defaultCtx(), "alice@example.com", "password123")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		auth    *service.AuthService
+		token   string
+		wantErr bool
+	}{
+		{name: "valid", auth: e.auth, token: token},
+		{name: "garbage", auth: e.auth, token: "not-a-token", wantErr: true},
+		{name: "wrong secret", auth: otherSecret, token: token, wantErr: true},
+		{name: "expired", auth: e.auth, token: expiredToken, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.auth.ValidateToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
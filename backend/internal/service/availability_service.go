@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// maxSlotRange bounds how far ahead free slots are listed at once
+const maxSlotRange = 31 * 24 * time.Hour
+
+type AvailabilityService struct {
+	availabilityRepo domain.AvailabilityRepository
+	mentorRepo       domain.MentorRepository
+	queue            *QueueService
+}
+
+func NewAvailabilityService(availabilityRepo domain.AvailabilityRepository, mentorRepo domain.MentorRepository, queue *QueueService) *AvailabilityService {
+	return &AvailabilityService{
+		availabilityRepo: availabilityRepo,
+		mentorRepo:       mentorRepo,
+		queue:            queue,
+	}
+}
+
+// GetSchedule retrieves a mentor's weekly windows and their current and
+// upcoming absences
+func (s *AvailabilityService) GetSchedule(ctx context.Context, mentorID string) (*domain.MentorSchedule, error) {
+	if _, err := s.mentorRepo.GetByID(ctx, mentorID); err != nil {
+		return nil, err
+	}
+
+	return loadSchedule(ctx, s.availabilityRepo, mentorID, time.Now(), nil)
+}
+
+// AddWindow declares a recurring weekly window in which the mentor is
+// available; windows of the same day may not overlap
+func (s *AvailabilityService) AddWindow(ctx context.Context, mentorID string, weekday time.Weekday, startTime, endTime string) (*domain.AvailabilityWindow, error) {
+	if _, err := s.mentorRepo.GetByID(ctx, mentorID); err != nil {
+		return nil, err
+	}
+
+	window := &domain.AvailabilityWindow{
+		MentorID:  mentorID,
+		Weekday:   weekday,
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+	if err := window.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.availabilityRepo.GetWindows(ctx, mentorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if window.Overlaps(other) {
+			return nil, fmt.Errorf("%w: %s-%s", domain.ErrWindowOverlaps, other.StartTime, other.EndTime)
+		}
+	}
+
+	if err := s.availabilityRepo.CreateWindow(ctx, window); err != nil {
+		return nil, fmt.Errorf("failed to create availability window: %w", err)
+	}
+
+	return window, nil
+}
+
+// UpdateWindow moves a weekly window of the mentor; it may not overlap the
+// mentor's other windows
+func (s *AvailabilityService) UpdateWindow(ctx context.Context, mentorID, windowID string, weekday time.Weekday, startTime, endTime string) (*domain.AvailabilityWindow, error) {
+	if _, err := s.mentorRepo.GetByID(ctx, mentorID); err != nil {
+		return nil, err
+	}
+
+	window := &domain.AvailabilityWindow{
+		ID:        windowID,
+		MentorID:  mentorID,
+		Weekday:   weekday,
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+	if err := window.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.availabilityRepo.GetWindows(ctx, mentorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if other.ID != windowID && window.Overlaps(other) {
+			return nil, fmt.Errorf("%w: %s-%s", domain.ErrWindowOverlaps, other.StartTime, other.EndTime)
+		}
+	}
+
+	if err := s.availabilityRepo.UpdateWindow(ctx, window); err != nil {
+		return nil, err
+	}
+
+	return window, nil
+}
+
+// RemoveWindow deletes a weekly window of the mentor
+func (s *AvailabilityService) RemoveWindow(ctx context.Context, mentorID, windowID string) error {
+	return s.availabilityRepo.DeleteWindow(ctx, windowID, mentorID)
+}
+
+// AddAbsence records a period during which the mentor is out of office
+func (s *AvailabilityService) AddAbsence(ctx context.Context, mentorID string, kind domain.AbsenceKind, startsAt, endsAt time.Time, note string) (*domain.Absence, error) {
+	if _, err := s.mentorRepo.GetByID(ctx, mentorID); err != nil {
+		return nil, err
+	}
+
+	absence := &domain.Absence{
+		MentorID: mentorID,
+		Kind:     kind,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Note:     stringToPtr(note),
+	}
+	if err := absence.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.availabilityRepo.CreateAbsence(ctx, absence); err != nil {
+		return nil, fmt.Errorf("failed to create absence: %w", err)
+	}
+
+	return absence, nil
+}
+
+// UpdateAbsence changes an absence of the mentor; a mentor whose absence
+// was shortened to end already picks up queued requests
+func (s *AvailabilityService) UpdateAbsence(ctx context.Context, mentorID, absenceID string, kind domain.AbsenceKind, startsAt, endsAt time.Time, note string) (*domain.Absence, error) {
+	if _, err := s.mentorRepo.GetByID(ctx, mentorID); err != nil {
+		return nil, err
+	}
+
+	absence := &domain.Absence{
+		ID:       absenceID,
+		MentorID: mentorID,
+		Kind:     kind,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Note:     stringToPtr(note),
+	}
+	if err := absence.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.availabilityRepo.UpdateAbsence(ctx, absence); err != nil {
+		return nil, err
+	}
+	s.queue.serveQueue(ctx)
+
+	return absence, nil
+}
+
+// RemoveAbsence deletes an absence of the mentor; a mentor back in the
+// office picks up queued requests
+func (s *AvailabilityService) RemoveAbsence(ctx context.Context, mentorID, absenceID string) error {
+	if err := s.availabilityRepo.DeleteAbsence(ctx, absenceID, mentorID); err != nil {
+		return err
+	}
+	s.queue.serveQueue(ctx)
+	return nil
+}
+
+// GetFreeSlots lists the periods in [from, to) in which the mentor can hold
+// sessions: their weekly windows minus absences
+func (s *AvailabilityService) GetFreeSlots(ctx context.Context, mentorID string, from, to time.Time) ([]domain.TimeSlot, error) {
+	if err := validateRange(from, to); err != nil {
+		return nil, err
+	}
+	if _, err := s.mentorRepo.GetByID(ctx, mentorID); err != nil {
+		return nil, err
+	}
+
+	schedule, err := loadSchedule(ctx, s.availabilityRepo, mentorID, from, &to)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule.FreeSlots(from, to), nil
+}
+
+// validateRange checks that a date range is ordered and not too long
+func validateRange(from, to time.Time) error {
+	if to.Before(from) {
+		return fmt.Errorf("%w: range must end after it starts", domain.ErrInvalidInput)
+	}
+	if to.Sub(from) > maxSlotRange {
+		return fmt.Errorf("%w: range may not exceed %d days", domain.ErrInvalidInput, int(maxSlotRange.Hours()/24))
+	}
+	return nil
+}
+
+// loadSchedule reads a mentor's windows and the absences overlapping
+// [from, to]; a nil to means no upper bound
+func loadSchedule(ctx context.Context, repo domain.AvailabilityRepository, mentorID string, from time.Time, to *time.Time) (*domain.MentorSchedule, error) {
+	windows, err := repo.GetWindows(ctx, mentorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability windows: %w", err)
+	}
+	absences, err := repo.GetAbsences(ctx, domain.AbsenceFilter{MentorID: mentorID, From: &from, To: to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+
+	return &domain.MentorSchedule{MentorID: mentorID, Windows: windows, Absences: absences}, nil
+}
+
+// absentMentors returns the IDs of mentors who are out of office right now;
+// an empty mentorID checks every mentor
+func absentMentors(ctx context.Context, repo domain.AvailabilityRepository, mentorID string) (map[string]bool, error) {
+	now := time.Now()
+	absences, err := repo.GetAbsences(ctx, domain.AbsenceFilter{MentorID: mentorID, From: &now, To: &now})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+
+	absent := make(map[string]bool, len(absences))
+	for _, absence := range absences {
+		if absence.Covers(now) {
+			absent[absence.MentorID] = true
+		}
+	}
+	return absent, nil
+}
+
+// ensurePresent fails with ErrMentorAbsent if the mentor is out of office
+// right now
+func ensurePresent(ctx context.Context, repo domain.AvailabilityRepository, mentorID string) error {
+	absent, err := absentMentors(ctx, repo, mentorID)
+	if err != nil {
+		return err
+	}
+	if absent[mentorID] {
+		return domain.ErrMentorAbsent
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// monday is the start of a week far enough ahead to have no past absences
+var monday = time.Date(2030, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+func TestAvailabilityService_AddWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		weekday time.Weekday
+		start   string
+		end     string
+		missing bool
+		wantErr error
+	}{
+		{name: "free morning", weekday: time.Monday, start: "08:00", end: "09:00"},
+		{name: "same time other day", weekday: time.Tuesday, start: "09:00", end: "12:00"},
+		{name: "until midnight", weekday: time.Sunday, start: "20:00", end: "24:00"},
+		{name: "touches existing window", weekday: time.Monday, start: "12:00", end: "13:00"},
+		{name: "overlaps existing window", weekday: time.Monday, start: "11:00", end: "13:00", wantErr: domain.ErrWindowOverlaps},
+		{name: "ends before it starts", weekday: time.Friday, start: "12:00", end: "09:00", wantErr: domain.ErrInvalidInput},
+		{name: "malformed time", weekday: time.Friday, start: "9:00", end: "12:00", wantErr: domain.ErrInvalidInput},
+		{name: "out of range time", weekday: time.Friday, start: "09:00", end: "24:30", wantErr: domain.ErrInvalidInput},
+		{name: "unknown weekday", weekday: 7, start: "09:00", end: "12:00", wantErr: domain.ErrInvalidInput},
+		{name: "missing mentor", missing: true, weekday: time.Friday, start: "09:00", end: "12:00", wantErr: domain.ErrMentorNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			mentor := e.addMentor(t, "ann", 0)
+			if _, err := e.availability.AddWindow(ctx, mentor.ID, time.Monday, "09:00", "12:00"); err != nil {
+				t.Fatalf("add first window: %v", err)
+			}
+			id := mentor.ID
+			if tt.missing {
+				id = "missing"
+			}
+
+			window, err := e.availability.AddWindow(ctx, id, tt.weekday, tt.start, tt.end)
+			expectErr(t, err, tt.wantErr)
+
+			windows, _ := e.availabilities.GetWindows(ctx, mentor.ID)
+			if err != nil {
+				if len(windows) != 1 {
+					t.Errorf("failed call stored a window, %d windows", len(windows))
+				}
+				return
+			}
+			if window.ID == "" || window.MentorID != mentor.ID || len(windows) != 2 {
+				t.Errorf("AddWindow returned %+v, %d windows stored", window, len(windows))
+			}
+		})
+	}
+}
+
+func TestAvailabilityService_AddAbsence(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     domain.AbsenceKind
+		startsAt time.Time
+		endsAt   time.Time
+		missing  bool
+		wantErr  error
+	}{
+		{name: "vacation", kind: domain.AbsenceVacation, startsAt: monday, endsAt: monday.AddDate(0, 0, 14)},
+		{name: "sick leave", kind: domain.AbsenceSickLeave, startsAt: monday, endsAt: monday.Add(time.Hour)},
+		{name: "empty period", kind: domain.AbsenceOther, startsAt: monday, endsAt: monday, wantErr: domain.ErrInvalidInput},
+		{name: "ends before it starts", kind: domain.AbsenceOther, startsAt: monday, endsAt: monday.Add(-time.Hour), wantErr: domain.ErrInvalidInput},
+		{name: "unknown kind", kind: "sabbatical", startsAt: monday, endsAt: monday.Add(time.Hour), wantErr: domain.ErrInvalidInput},
+		{name: "missing mentor", missing: true, kind: domain.AbsenceVacation, startsAt: monday, endsAt: monday.Add(time.Hour), wantErr: domain.ErrMentorNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			mentor := e.addMentor(t, "ann", 0)
+			id := mentor.ID
+			if tt.missing {
+				id = "missing"
+			}
+
+			absence, err := e.availability.AddAbsence(ctx, id, tt.kind, tt.startsAt, tt.endsAt, "conference")
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			schedule, err := e.availability.GetSchedule(ctx, mentor.ID)
+			if err != nil {
+				t.Fatalf("GetSchedule: %v", err)
+			}
+			if len(schedule.Absences) != 1 || schedule.Absences[0].ID != absence.ID || *schedule.Absences[0].Note != "conference" {
+				t.Errorf("schedule absences = %+v", schedule.Absences)
+			}
+		})
+	}
+}
+
+func TestAvailabilityService_UpdateWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		weekday time.Weekday
+		start   string
+		end     string
+		other   bool // the window of another mentor
+		missing bool
+		wantErr error
+	}{
+		{name: "longer", weekday: time.Monday, start: "08:00", end: "12:00"},
+		{name: "other day", weekday: time.Friday, start: "14:00", end: "16:00"},
+		{name: "touches other window", weekday: time.Monday, start: "11:00", end: "14:00"},
+		{name: "overlaps other window", weekday: time.Monday, start: "11:00", end: "15:00", wantErr: domain.ErrWindowOverlaps},
+		{name: "ends before it starts", weekday: time.Monday, start: "12:00", end: "09:00", wantErr: domain.ErrInvalidInput},
+		{name: "window of another mentor", other: true, weekday: time.Monday, start: "09:00", end: "10:00", wantErr: domain.ErrWindowNotFound},
+		{name: "missing mentor", missing: true, weekday: time.Monday, start: "09:00", end: "10:00", wantErr: domain.ErrMentorNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			ann := e.addMentor(t, "ann", 0)
+			max := e.addMentor(t, "max", 0)
+			window, err := e.availability.AddWindow(ctx, ann.ID, time.Monday, "09:00", "11:00")
+			expectErr(t, err, nil)
+			_, err = e.availability.AddWindow(ctx, ann.ID, time.Monday, "14:00", "16:00")
+			expectErr(t, err, nil)
+			id := ann.ID
+			switch {
+			case tt.other:
+				id = max.ID
+			case tt.missing:
+				id = "missing"
+			}
+
+			updated, err := e.availability.UpdateWindow(ctx, id, window.ID, tt.weekday, tt.start, tt.end)
+			expectErr(t, err, tt.wantErr)
+
+			windows, _ := e.availabilities.GetWindows(ctx, ann.ID)
+			var stored *domain.AvailabilityWindow
+			for _, w := range windows {
+				if w.ID == window.ID {
+					stored = w
+				}
+			}
+			want := window
+			if err == nil {
+				want = updated
+			}
+			if stored == nil || stored.Weekday != want.Weekday || stored.StartTime != want.StartTime || stored.EndTime != want.EndTime {
+				t.Errorf("stored window = %+v, want %+v", stored, want)
+			}
+		})
+	}
+}
+
+func TestAvailabilityService_UpdateAbsence(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     domain.AbsenceKind
+		startsAt time.Time
+		endsAt   time.Time
+		other    bool
+		missing  bool
+		wantErr  error
+	}{
+		{name: "extended", kind: domain.AbsenceVacation, startsAt: monday, endsAt: monday.AddDate(0, 0, 21)},
+		{name: "other kind", kind: domain.AbsenceSickLeave, startsAt: monday, endsAt: monday.AddDate(0, 0, 2)},
+		{name: "empty period", kind: domain.AbsenceVacation, startsAt: monday, endsAt: monday, wantErr: domain.ErrInvalidInput},
+		{name: "unknown kind", kind: "sabbatical", startsAt: monday, endsAt: monday.AddDate(0, 0, 1), wantErr: domain.ErrInvalidInput},
+		{name: "absence of another mentor", other: true, kind: domain.AbsenceVacation, startsAt: monday, endsAt: monday.AddDate(0, 0, 1), wantErr: domain.ErrAbsenceNotFound},
+		{name: "missing mentor", missing: true, kind: domain.AbsenceVacation, startsAt: monday, endsAt: monday.AddDate(0, 0, 1), wantErr: domain.ErrMentorNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			ann := e.addMentor(t, "ann", 0)
+			max := e.addMentor(t, "max", 0)
+			absence, err := e.availability.AddAbsence(ctx, ann.ID, domain.AbsenceVacation, monday, monday.AddDate(0, 0, 7), "beach")
+			expectErr(t, err, nil)
+			id := ann.ID
+			switch {
+			case tt.other:
+				id = max.ID
+			case tt.missing:
+				id = "missing"
+			}
+
+			updated, err := e.availability.UpdateAbsence(ctx, id, absence.ID, tt.kind, tt.startsAt, tt.endsAt, "")
+			expectErr(t, err, tt.wantErr)
+
+			stored, _ := e.availabilities.GetAbsences(ctx, domain.AbsenceFilter{MentorID: ann.ID})
+			want := absence
+			if err == nil {
+				want = updated
+			}
+			if len(stored) != 1 || stored[0].Kind != want.Kind || !stored[0].EndsAt.Equal(want.EndsAt) || (stored[0].Note == nil) != (want.Note == nil) {
+				t.Errorf("stored absences = %+v, want %+v", stored, want)
+			}
+		})
+	}
+}
+
+func TestAvailabilityService_UpdateAbsence_ServesQueue(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	ann := e.addMentor(t, "ann", 0)
+	absence := e.absent(t, ann)
+	request := e.queueRequest(t, "alice", 0)
+
+	// Moving the return to next month keeps the request waiting
+	_, err := e.availability.UpdateAbsence(ctx, ann.ID, absence.ID, domain.AbsenceVacation, absence.StartsAt, time.Now().AddDate(0, 1, 0), "")
+	expectErr(t, err, nil)
+	if got, _ := e.requests.GetByID(ctx, request.ID); !got.IsQueued() {
+		t.Fatalf("request status = %s while ann is away, want queued", got.Status)
+	}
+
+	// Back early: the absence ended a minute ago
+	_, err = e.availability.UpdateAbsence(ctx, ann.ID, absence.ID, domain.AbsenceVacation, absence.StartsAt.Add(-time.Hour), time.Now().Add(-time.Minute), "")
+	expectErr(t, err, nil)
+	if got, _ := e.requests.GetByID(ctx, request.ID); got.Status != domain.RequestApproved {
+		t.Errorf("request status = %s after ann returned, want approved", got.Status)
+	}
+	if e.workload(t, ann.ID) != 1 {
+		t.Errorf("workload = %d, want 1", e.workload(t, ann.ID))
+	}
+}
+
+func TestAvailabilityService_Remove(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	ann := e.addMentor(t, "ann", 0)
+	max := e.addMentor(t, "max", 0)
+	window, _ := e.availability.AddWindow(ctx, ann.ID, time.Monday, "09:00", "12:00")
+	absence, _ := e.availability.AddAbsence(ctx, ann.ID, domain.AbsenceVacation, monday, monday.AddDate(0, 0, 7), "")
+	past, _ := e.availability.AddAbsence(ctx, ann.ID, domain.AbsenceSickLeave, time.Now().AddDate(0, 0, -7), time.Now().AddDate(0, 0, -6), "")
+
+	schedule, err := e.availability.GetSchedule(ctx, ann.ID)
+	if err != nil || len(schedule.Windows) != 1 || len(schedule.Absences) != 1 {
+		t.Fatalf("GetSchedule = %+v, %v; want one window and one upcoming absence", schedule, err)
+	}
+
+	expectErr(t, e.availability.RemoveWindow(ctx, max.ID, window.ID), domain.ErrWindowNotFound)
+	expectErr(t, e.availability.RemoveAbsence(ctx, max.ID, absence.ID), domain.ErrAbsenceNotFound)
+	expectErr(t, e.availability.RemoveWindow(ctx, ann.ID, window.ID), nil)
+	expectErr(t, e.availability.RemoveAbsence(ctx, ann.ID, absence.ID), nil)
+	expectErr(t, e.availability.RemoveAbsence(ctx, ann.ID, past.ID), nil)
+	expectErr(t, e.availability.RemoveWindow(ctx, ann.ID, window.ID), domain.ErrWindowNotFound)
+
+	schedule, _ = e.availability.GetSchedule(ctx, ann.ID)
+	if len(schedule.Windows) != 0 || len(schedule.Absences) != 0 {
+		t.Errorf("schedule after removal = %+v", schedule)
+	}
+	if _, err := e.availability.GetSchedule(ctx, "missing"); err == nil {
+		t.Error("GetSchedule for a missing mentor succeeded")
+	}
+}
+
+func TestAvailabilityService_GetFreeSlots(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	e := newEnv(t)
+	ctx := defaultCtx()
+	ann := e.addMentor(t, "ann", 0)
+	for _, w := range []struct {
+		weekday    time.Weekday
+		start, end string
+	}{
+		{time.Monday, "14:00", "16:00"},
+		{time.Monday, "09:00", "12:00"},
+		{time.Wednesday, "09:00", "12:00"},
+	} {
+		if _, err := e.availability.AddWindow(ctx, ann.ID, w.weekday, w.start, w.end); err != nil {
+			t.Fatalf("add window: %v", err)
+		}
+	}
+	// A dentist appointment on Monday and sick leave for all of Wednesday
+	if _, err := e.availability.AddAbsence(ctx, ann.ID, domain.AbsenceOther, at(0, 10, 0), at(0, 11, 0), "dentist"); err != nil {
+		t.Fatalf("add absence: %v", err)
+	}
+	if _, err := e.availability.AddAbsence(ctx, ann.ID, domain.AbsenceSickLeave, at(2, 0, 0), at(3, 0, 0), ""); err != nil {
+		t.Fatalf("add absence: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		want    []domain.TimeSlot
+		wantErr error
+	}{
+		{
+			name: "whole week",
+			from: monday,
+			to:   monday.AddDate(0, 0, 7),
+			want: []domain.TimeSlot{
+				{Start: at(0, 9, 0), End: at(0, 10, 0)},
+				{Start: at(0, 11, 0), End: at(0, 12, 0)},
+				{Start: at(0, 14, 0), End: at(0, 16, 0)},
+			},
+		},
+		{
+			name: "range cuts windows",
+			from: at(0, 11, 30),
+			to:   at(0, 15, 0),
+			want: []domain.TimeSlot{
+				{Start: at(0, 11, 30), End: at(0, 12, 0)},
+				{Start: at(0, 14, 0), End: at(0, 15, 0)},
+			},
+		},
+		{
+			name: "next week is free again",
+			from: monday.AddDate(0, 0, 9),
+			to:   monday.AddDate(0, 0, 10),
+			want: []domain.TimeSlot{{Start: at(9, 9, 0), End: at(9, 12, 0)}},
+		},
+		{name: "nothing on tuesday", from: at(1, 0, 0), to: at(2, 0, 0), want: []domain.TimeSlot{}},
+		{name: "reversed range", from: at(1, 0, 0), to: at(0, 0, 0), wantErr: domain.ErrInvalidInput},
+		{name: "range too long", from: monday, to: monday.AddDate(0, 2, 0), wantErr: domain.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots, err := e.availability.GetFreeSlots(ctx, ann.ID, tt.from, tt.to)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if len(slots) != len(tt.want) {
+				t.Fatalf("got %d slots %v, want %d", len(slots), slots, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !slots[i].Start.Equal(want.Start) || !slots[i].End.Equal(want.End) {
+					t.Errorf("slot %d = %v-%v, want %v-%v", i, slots[i].Start, slots[i].End, want.Start, want.End)
+				}
+			}
+		})
+	}
+}
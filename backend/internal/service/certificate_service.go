@@ -0,0 +1,153 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// certificateAlphabet leaves out the letters and digits easily confused
+// when a code is typed from paper
+const certificateAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// CertificateService issues PDF certificates for completed learnings. The
+// PDF is rendered once and kept in the blob store; its code lets anyone
+// confirm the certificate without signing in.
+type CertificateService struct {
+	certificateRepo domain.CertificateRepository
+	learningRepo    domain.LearningRepository
+	userRepo        domain.UserRepository
+	blobs           domain.BlobStore
+	renderer        domain.CertificateRenderer
+}
+
+func NewCertificateService(
+	certificateRepo domain.CertificateRepository,
+	learningRepo domain.LearningRepository,
+	userRepo domain.UserRepository,
+	blobs domain.BlobStore,
+	renderer domain.CertificateRenderer,
+) *CertificateService {
+	return &CertificateService{
+		certificateRepo: certificateRepo,
+		learningRepo:    learningRepo,
+		userRepo:        userRepo,
+		blobs:           blobs,
+		renderer:        renderer,
+	}
+}
+
+// GetLearningCertificate opens the certificate of a completed learning for
+// the learner or users with learnings.view; the caller closes the content. Certificates
+// that failed to issue on completion are issued now.
+func (s *CertificateService) GetLearningCertificate(ctx context.Context, learningID, userID string) (*domain.Certificate, io.ReadCloser, error) {
+	learning, err := s.learningRepo.GetByID(ctx, learningID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if learning.UserID != userID {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !user.Can(domain.PermLearningsView) {
+			return nil, nil, domain.ErrForbidden
+		}
+	}
+	if !learning.IsCompleted() {
+		return nil, nil, domain.ErrLearningNotCompleted
+	}
+
+	certificate, err := s.certificateRepo.GetByLearningID(ctx, learningID)
+	if errors.Is(err, domain.ErrCertificateNotFound) {
+		certificate, err = s.issue(ctx, learning)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.blobs.Get(ctx, certificate.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	return certificate, content, nil
+}
+
+// Verify looks a certificate up by the code printed on it
+func (s *CertificateService) Verify(ctx context.Context, code string) (*domain.Certificate, error) {
+	return s.certificateRepo.GetByCode(ctx, domain.NormalizeCertificateCode(code))
+}
+
+// issueOnCompletion issues the certificate of a learning that was just
+// completed. The completion is already saved, so a failure is only logged;
+// the certificate is issued again on the first download.
+func (s *CertificateService) issueOnCompletion(ctx context.Context, learningID string) {
+	learning, err := s.learningRepo.GetByID(ctx, learningID)
+	if err == nil {
+		_, err = s.issue(ctx, learning)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to issue certificate", "learning_id", learningID, "error", err)
+	}
+}
+
+// issue renders and stores the certificate of a completed learning. When
+// another request issued it first, that certificate is returned.
+func (s *CertificateService) issue(ctx context.Context, learning *domain.LearningProcess) (*domain.Certificate, error) {
+	code, err := newCertificateCode()
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := domain.NewCertificate(learning, code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.renderer.Render(certificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render certificate: %w", err)
+	}
+
+	certificate.StorageKey = "certificates/" + uuid.New().String()
+	if err := s.blobs.Put(ctx, certificate.StorageKey, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		return nil, fmt.Errorf("failed to store certificate: %w", err)
+	}
+	if err := s.certificateRepo.Create(ctx, certificate); err != nil {
+		if delErr := s.blobs.Delete(ctx, certificate.StorageKey); delErr != nil {
+			slog.WarnContext(ctx, "failed to delete orphaned certificate content", "key", certificate.StorageKey, "error", delErr)
+		}
+		if errors.Is(err, domain.ErrCertificateExists) {
+			return s.certificateRepo.GetByLearningID(ctx, learning.ID)
+		}
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// newCertificateCode generates a random code like 7KQM-X2HD-R9PA
+func newCertificateCode() (string, error) {
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate certificate code: %w", err)
+	}
+
+	var code strings.Builder
+	for i, b := range random {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(certificateAlphabet[int(b)%len(certificateAlphabet)])
+	}
+	return code.String(), nil
+}
@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// certificatePDF reads the certificate of a learning
+func (e *env) certificatePDF(t *testing.T, learningID, userID string) (*domain.Certificate, []byte) {
+	t.Helper()
+
+	certificate, content, err := e.certificate.GetLearningCertificate(defaultCtx(), learningID, userID)
+	if err != nil {
+		t.Fatalf("get certificate: %v", err)
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatalf("read certificate: %v", err)
+	}
+	return certificate, data
+}
+
+func TestCertificateService_IssuedOnCompletion(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	mentor := e.addMentor(t, "ann", 0)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+
+	_, _, err := e.certificate.GetLearningCertificate(ctx, learning.ID, alice.ID)
+	expectErr(t, err, domain.ErrLearningNotCompleted)
+
+	_, err = e.learning.UpdatePlan(ctx, learning.ID, []domain.LearningPlanItem{
+		{ID: "1", Text: "Read the memory model", Completed: true},
+		{ID: "2", Text: "Profile a service", Completed: false},
+	})
+	expectErr(t, err, nil)
+	_, err = e.learning.CompleteLearning(ctx, learning.ID, 5, "Great")
+	expectErr(t, err, nil)
+
+	issued, err := e.certificates.GetByLearningID(ctx, learning.ID)
+	expectErr(t, err, nil)
+	if issued.LearnerName != "alice" || issued.MentorName != "ann" || issued.Topic != "Go" ||
+		len(issued.CompletedItems) != 1 || issued.CompletedItems[0] != "Read the memory model" {
+		t.Errorf("issued certificate = %+v", issued)
+	}
+
+	// The stored PDF is served, to the learner and to admins only
+	certificate, data := e.certificatePDF(t, learning.ID, alice.ID)
+	if certificate.Code != issued.Code || !bytes.HasPrefix(data, []byte("%PDF-")) ||
+		!bytes.Contains(data, []byte("https://learning.example.com/api/certificates/"+issued.Code)) {
+		t.Errorf("certificate %+v, content %q", certificate, data)
+	}
+	if again, _ := e.certificatePDF(t, learning.ID, admin.ID); again.ID != issued.ID {
+		t.Errorf("admin got certificate %+v, want the issued one", again)
+	}
+	_, _, err = e.certificate.GetLearningCertificate(ctx, learning.ID, bob.ID)
+	expectErr(t, err, domain.ErrForbidden)
+
+	// Anyone can check a code, typed in any case
+	verified, err := e.certificate.Verify(ctx, " "+strings.ToLower(issued.Code)+" ")
+	expectErr(t, err, nil)
+	if verified.ID != issued.ID {
+		t.Errorf("Verify = %+v", verified)
+	}
+	_, err = e.certificate.Verify(ctx, "AAAA-BBBB-CCCC")
+	expectErr(t, err, domain.ErrCertificateNotFound)
+}
+
+func TestCertificateService_IssuedOnFirstDownload(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	mentor := e.addMentor(t, "ann", 0)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+
+	// Completed without a certificate, as when issuing failed
+	if err := e.learnings.Complete(ctx, learning.ID, domain.Feedback{Rating: 4}); err != nil {
+		t.Fatalf("complete learning: %v", err)
+	}
+	_, err := e.certificates.GetByLearningID(ctx, learning.ID)
+	expectErr(t, err, domain.ErrCertificateNotFound)
+
+	first, data := e.certificatePDF(t, learning.ID, alice.ID)
+	if !bytes.HasPrefix(data, []byte("%PDF-")) || len(first.CompletedItems) != 0 {
+		t.Errorf("certificate %+v, content %q", first, data)
+	}
+	second, _ := e.certificatePDF(t, learning.ID, alice.ID)
+	if second.ID != first.ID || second.Code != first.Code {
+		t.Errorf("second download issued %+v, want %+v", second, first)
+	}
+}
+
+func TestCertificateService_Verify(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	learning := e.addLearning(t, e.addUser(t, "alice").ID, e.addMentor(t, "ann", 0), domain.LearningActive)
+	_, err := e.learning.CompleteLearning(ctx, learning.ID, 5, "Great")
+	expectErr(t, err, nil)
+	issued, err := e.certificates.GetByLearningID(ctx, learning.ID)
+	expectErr(t, err, nil)
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{name: "as printed", code: issued.Code},
+		{name: "lower case", code: strings.ToLower(issued.Code)},
+		{name: "surrounding spaces", code: "\t " + issued.Code + " \n"},
+		{name: "unknown code", code: "AAAA-BBBB-CCCC", wantErr: domain.ErrCertificateNotFound},
+		{name: "without dashes", code: strings.ReplaceAll(issued.Code, "-", ""), wantErr: domain.ErrCertificateNotFound},
+		{name: "empty", code: "", wantErr: domain.ErrCertificateNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := e.certificate.Verify(ctx, tt.code)
+			expectErr(t, err, tt.wantErr)
+			if err == nil && (verified.ID != issued.ID || verified.LearnerName != "alice") {
+				t.Errorf("Verify(%q) = %+v", tt.code, verified)
+			}
+		})
+	}
+}
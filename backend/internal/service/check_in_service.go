@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// CheckInService sends periodic check-ins to the learner and the mentor of
+// every active learning, and flags learnings that stalled: no plan progress
+// or no activity at all for a while, or a check-in reporting trouble. Every
+// step is safe to run on several app instances at once.
+type CheckInService struct {
+	tx            domain.TxManager
+	checkInRepo   domain.CheckInRepository
+	learningRepo  domain.LearningRepository
+	mentorRepo    domain.MentorRepository
+	userRepo      domain.UserRepository
+	notifications *NotificationService
+	interval      time.Duration // between check-ins; 0 sends none
+	stalledAfter  time.Duration // without progress before a learning is at risk
+	notifyMentor  bool          // tell the mentor when a learning stalls
+}
+
+func NewCheckInService(
+	tx domain.TxManager,
+	checkInRepo domain.CheckInRepository,
+	learningRepo domain.LearningRepository,
+	mentorRepo domain.MentorRepository,
+	userRepo domain.UserRepository,
+	notifications *NotificationService,
+	interval, stalledAfter time.Duration,
+	notifyMentor bool,
+) *CheckInService {
+	return &CheckInService{
+		tx:            tx,
+		checkInRepo:   checkInRepo,
+		learningRepo:  learningRepo,
+		mentorRepo:    mentorRepo,
+		userRepo:      userRepo,
+		notifications: notifications,
+		interval:      interval,
+		stalledAfter:  stalledAfter,
+		notifyMentor:  notifyMentor,
+	}
+}
+
+// StalledAfter returns the default stall period of the at-risk report
+func (s *CheckInService) StalledAfter() time.Duration {
+	return s.stalledAfter
+}
+
+// RunJob is the JobCheckIns handler: it sends what is due now
+func (s *CheckInService) RunJob(ctx context.Context, _ *domain.Job) error {
+	return s.RunDue(ctx, time.Now())
+}
+
+// RunDue sends the check-ins due at now and alerts mentors about learnings
+// that stalled since their last alert. A failure on one learning does not
+// stop the others; all failures are returned together.
+func (s *CheckInService) RunDue(ctx context.Context, now time.Time) error {
+	learnings, err := s.learningRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get learnings: %w", err)
+	}
+
+	var errs []error
+	for _, learning := range learnings {
+		if !learning.IsActive() {
+			continue
+		}
+		mentorAccount, err := s.mentorAccount(ctx, learning.MentorID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.sendDue(ctx, learning, mentorAccount, now); err != nil {
+			errs = append(errs, fmt.Errorf("learning %s: %w", learning.ID, err))
+		}
+		if s.notifyMentor && mentorAccount != nil {
+			if err := s.alertStalled(ctx, learning, mentorAccount, now); err != nil {
+				errs = append(errs, fmt.Errorf("learning %s: %w", learning.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mentorAccount finds the user account with the mentor's email; mentors
+// without an account get no check-ins or alerts
+func (s *CheckInService) mentorAccount(ctx context.Context, mentorID string) (*domain.User, error) {
+	mentor, err := s.mentorRepo.GetByID(ctx, mentorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentor: %w", err)
+	}
+	user, err := s.userRepo.GetByEmail(ctx, mentor.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentor account: %w", err)
+	}
+	if !user.IsActive() {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// sendDue sends this round's check-in to each side that has not had it yet
+func (s *CheckInService) sendDue(ctx context.Context, learning *domain.LearningProcess, mentorAccount *domain.User, now time.Time) error {
+	round := domain.CheckInRound(learning.StartDate, now, s.interval)
+	if round < 1 {
+		return nil
+	}
+
+	type recipient struct {
+		userID string
+		role   domain.CheckInRole
+	}
+	recipients := []recipient{{learning.UserID, domain.CheckInLearner}}
+	if mentorAccount != nil && mentorAccount.ID != learning.UserID {
+		recipients = append(recipients, recipient{mentorAccount.ID, domain.CheckInMentor})
+	}
+
+	for _, r := range recipients {
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			checkIn := &domain.CheckIn{
+				LearningID: learning.ID,
+				UserID:     r.userID,
+				Role:       r.role,
+				Round:      round,
+			}
+			if err := s.checkInRepo.Create(ctx, checkIn); err != nil {
+				return err
+			}
+			return s.notifications.Notify(
+				ctx, r.userID, domain.NotificationCheckInDue,
+				"How is your learning going?",
+				fmt.Sprintf("Tell us how %q is going: on track, behind or blocked.", learning.RequestTopic),
+			)
+		})
+		// Already sent by an earlier run or another instance
+		if err != nil && !errors.Is(err, domain.ErrCheckInExists) {
+			return fmt.Errorf("failed to send check-in: %w", err)
+		}
+	}
+	return nil
+}
+
+// alertStalled tells the mentor about a learning without plan progress,
+// once per stall
+func (s *CheckInService) alertStalled(ctx context.Context, learning *domain.LearningProcess, mentorAccount *domain.User, now time.Time) error {
+	if s.stalledAfter <= 0 {
+		return nil
+	}
+	lastChange := learning.LastPlanChange()
+	if !lastChange.Before(now.Add(-s.stalledAfter)) {
+		return nil
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		claimed, err := s.checkInRepo.ClaimStallAlert(ctx, learning.ID, lastChange)
+		if err != nil || !claimed {
+			return err
+		}
+		return s.notifications.Notify(
+			ctx, mentorAccount.ID, domain.NotificationLearningStalled,
+			"Learning stalled",
+			fmt.Sprintf("%s has not progressed on %q since %s.", learning.UserName, learning.RequestTopic, lastChange.Format("2006-01-02")),
+		)
+	})
+}
+
+// GetAtRisk lists the active learnings of users in scope without plan
+// progress or activity for stalledAfter, or whose latest check-in reports
+// trouble, longest idle first
+func (s *CheckInService) GetAtRisk(ctx context.Context, stalledAfter time.Duration, now time.Time, scope domain.Scope) ([]*domain.AtRiskLearning, error) {
+	learnings, err := s.learningRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inScope, err := usersIn(ctx, s.userRepo, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	atRisk := make([]*domain.AtRiskLearning, 0)
+	for _, learning := range learnings {
+		if !learning.IsActive() || (inScope != nil && !inScope[learning.UserID]) {
+			continue
+		}
+		checkIns, err := s.checkInRepo.GetByLearningID(ctx, learning.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get check-ins: %w", err)
+		}
+		if risk := domain.AssessRisk(learning, checkIns, now.Add(-stalledAfter), now); risk != nil {
+			atRisk = append(atRisk, risk)
+		}
+	}
+
+	sort.SliceStable(atRisk, func(i, j int) bool {
+		return atRisk[i].LastActivityAt.Before(atRisk[j].LastActivityAt)
+	})
+	return atRisk, nil
+}
+
+// GetPending lists the user's unanswered check-ins, newest first
+func (s *CheckInService) GetPending(ctx context.Context, userID string) ([]*domain.CheckIn, error) {
+	return s.checkInRepo.GetPending(ctx, userID)
+}
+
+// GetLearningCheckIns lists the check-ins of a learning for its learner, its
+// mentor or users with learnings.view, newest first
+func (s *CheckInService) GetLearningCheckIns(ctx context.Context, learningID, viewerID string) ([]*domain.CheckIn, error) {
+	learning, err := s.learningRepo.GetByID(ctx, learningID)
+	if err != nil {
+		return nil, err
+	}
+	viewer, err := s.userRepo.GetByID(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if learning.UserID != viewer.ID && !viewer.Can(domain.PermLearningsView) {
+		mentor, err := s.mentorRepo.GetByID(ctx, learning.MentorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mentor: %w", err)
+		}
+		if !strings.EqualFold(mentor.Email, viewer.Email) {
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	return s.checkInRepo.GetByLearningID(ctx, learningID)
+}
+
+// Answer records the user's reply to their own check-in
+func (s *CheckInService) Answer(ctx context.Context, id, userID string, pulse domain.CheckInPulse, comment string) (*domain.CheckIn, error) {
+	if !pulse.IsValid() {
+		return nil, fmt.Errorf("%w: pulse must be on_track, behind or blocked", domain.ErrInvalidInput)
+	}
+	comment = strings.TrimSpace(comment)
+	if len([]rune(comment)) > domain.MaxCommentLength {
+		return nil, fmt.Errorf("%w: comment is longer than %d characters", domain.ErrInvalidInput, domain.MaxCommentLength)
+	}
+
+	checkIn, err := s.checkInRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if checkIn.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	if err := s.checkInRepo.Answer(ctx, id, pulse, comment); err != nil {
+		return nil, err
+	}
+
+	return s.checkInRepo.GetByID(ctx, id)
+}
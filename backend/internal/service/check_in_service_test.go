@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+const (
+	day                 = 24 * time.Hour
+	testCheckInInterval = 7 * day
+	testStalledAfter    = 14 * day
+)
+
+// notificationsOf returns the user's notifications of a kind
+func (e *env) notificationsOf(t *testing.T, userID string, kind domain.NotificationKind) []*domain.Notification {
+	t.Helper()
+
+	all, err := e.notification.GetUserNotifications(defaultCtx(), userID, false)
+	if err != nil {
+		t.Fatalf("get notifications: %v", err)
+	}
+	var matching []*domain.Notification
+	for _, n := range all {
+		if n.Kind == kind {
+			matching = append(matching, n)
+		}
+	}
+	return matching
+}
+
+func TestCheckInService_SendsOneCheckInPerRound(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	mentor := e.addMentor(t, "ann", 0)
+	account := e.mentorAccount(t, mentor)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	e.addLearning(t, alice.ID, mentor, domain.LearningCompleted)
+	start := time.Now()
+
+	expectErr(t, e.checkIn.RunDue(ctx, start.Add(day)), nil)
+	if pending, _ := e.checkIn.GetPending(ctx, alice.ID); len(pending) != 0 {
+		t.Fatalf("check-ins before the first round: %+v", pending)
+	}
+
+	for i := 0; i < 2; i++ {
+		expectErr(t, e.checkIn.RunDue(ctx, start.Add(8*day)), nil)
+	}
+	learnerPending, err := e.checkIn.GetPending(ctx, alice.ID)
+	expectErr(t, err, nil)
+	if len(learnerPending) != 1 || learnerPending[0].LearningID != learning.ID || learnerPending[0].Role != domain.CheckInLearner ||
+		learnerPending[0].Round != 1 || learnerPending[0].Topic != "Go" {
+		t.Fatalf("learner check-ins = %+v, want one for round 1", learnerPending)
+	}
+	mentorPending, err := e.checkIn.GetPending(ctx, account.ID)
+	expectErr(t, err, nil)
+	if len(mentorPending) != 1 || mentorPending[0].Role != domain.CheckInMentor {
+		t.Fatalf("mentor check-ins = %+v, want one", mentorPending)
+	}
+	if got := e.notificationsOf(t, alice.ID, domain.NotificationCheckInDue); len(got) != 1 {
+		t.Errorf("learner got %d check-in notifications, want 1", len(got))
+	}
+
+	expectErr(t, e.checkIn.RunDue(ctx, start.Add(15*day)), nil)
+	learnerPending, _ = e.checkIn.GetPending(ctx, alice.ID)
+	if len(learnerPending) != 2 || learnerPending[0].Round != 2 {
+		t.Errorf("learner check-ins after two intervals = %+v, want rounds 2 and 1", learnerPending)
+	}
+}
+
+func TestCheckInService_Answer(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	mentor := e.addMentor(t, "ann", 0)
+	account := e.mentorAccount(t, mentor)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	expectErr(t, e.checkIn.RunDue(ctx, time.Now().Add(8*day)), nil)
+
+	pending, err := e.checkIn.GetPending(ctx, alice.ID)
+	expectErr(t, err, nil)
+	id := pending[0].ID
+
+	_, err = e.checkIn.Answer(ctx, id, alice.ID, "fine", "")
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.checkIn.Answer(ctx, id, bob.ID, domain.PulseOnTrack, "")
+	expectErr(t, err, domain.ErrForbidden)
+	_, err = e.checkIn.Answer(ctx, "missing", alice.ID, domain.PulseOnTrack, "")
+	expectErr(t, err, domain.ErrCheckInNotFound)
+
+	answered, err := e.checkIn.Answer(ctx, id, alice.ID, domain.PulseBehind, "  Busy sprint  ")
+	expectErr(t, err, nil)
+	if !answered.IsAnswered() || answered.Pulse != domain.PulseBehind || answered.Comment != "Busy sprint" {
+		t.Errorf("answered check-in = %+v", answered)
+	}
+	_, err = e.checkIn.Answer(ctx, id, alice.ID, domain.PulseOnTrack, "")
+	expectErr(t, err, domain.ErrCheckInAnswered)
+
+	for _, viewer := range []string{alice.ID, account.ID} {
+		checkIns, err := e.checkIn.GetLearningCheckIns(ctx, learning.ID, viewer)
+		expectErr(t, err, nil)
+		if len(checkIns) != 2 {
+			t.Errorf("check-ins seen by %s = %d, want 2", viewer, len(checkIns))
+		}
+	}
+	_, err = e.checkIn.GetLearningCheckIns(ctx, learning.ID, bob.ID)
+	expectErr(t, err, domain.ErrForbidden)
+}
+
+func TestCheckInService_GetAtRisk(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	mentor := e.addMentor(t, "ann", 0)
+	stalled := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	blocked := e.addLearning(t, bob.ID, mentor, domain.LearningActive)
+	e.addLearning(t, alice.ID, mentor, domain.LearningCompleted)
+	now := time.Now()
+
+	atRisk, err := e.checkIn.GetAtRisk(ctx, testStalledAfter, now.Add(day), domain.Scope{All: true})
+	expectErr(t, err, nil)
+	if len(atRisk) != 0 {
+		t.Fatalf("at risk after a day = %+v, want none", atRisk)
+	}
+
+	expectErr(t, e.checkIn.RunDue(ctx, now.Add(8*day)), nil)
+	pending, _ := e.checkIn.GetPending(ctx, bob.ID)
+	_, err = e.checkIn.Answer(ctx, pending[0].ID, bob.ID, domain.PulseBlocked, "No access to the cluster")
+	expectErr(t, err, nil)
+
+	atRisk, err = e.checkIn.GetAtRisk(ctx, testStalledAfter, now.Add(day), domain.Scope{All: true})
+	expectErr(t, err, nil)
+	if len(atRisk) != 1 || atRisk[0].LearningID != blocked.ID || atRisk[0].Pulse != domain.PulseBlocked ||
+		len(atRisk[0].Reasons) != 1 || atRisk[0].Reasons[0] != domain.AtRiskBlocked {
+		t.Fatalf("at risk = %+v, want the blocked learning", atRisk)
+	}
+
+	atRisk, err = e.checkIn.GetAtRisk(ctx, testStalledAfter, now.Add(15*day), domain.Scope{All: true})
+	expectErr(t, err, nil)
+	if len(atRisk) != 2 {
+		t.Fatalf("at risk after the stall period = %+v, want both learnings", atRisk)
+	}
+	// The blocked learning had an answer, so the other one idled longer
+	first := atRisk[0]
+	if first.LearningID != stalled.ID || first.UserName != "alice" || first.Topic != "Go" || first.IdleDays != 15 ||
+		len(first.Reasons) != 2 || first.Reasons[0] != domain.AtRiskNoProgress || first.Reasons[1] != domain.AtRiskInactive {
+		t.Errorf("stalled learning = %+v", first)
+	}
+	second := atRisk[1]
+	if len(second.Reasons) != 3 || second.Reasons[2] != domain.AtRiskBlocked {
+		t.Errorf("blocked learning reasons = %v, want no_progress, inactive and blocked", second.Reasons)
+	}
+
+	// A department head sees the learnings of their departments only
+	sales := e.addDepartment(t, "Sales", nil)
+	e.placeIn(t, bob, "Sales", "Account Manager")
+	atRisk, err = e.checkIn.GetAtRisk(ctx, testStalledAfter, now.Add(15*day), domain.Scope{Departments: []string{sales.ID}})
+	expectErr(t, err, nil)
+	if len(atRisk) != 1 || atRisk[0].LearningID != blocked.ID {
+		t.Errorf("at risk in Sales = %+v, want bob's learning", atRisk)
+	}
+}
+
+func TestCheckInService_AlertsMentorOncePerStall(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	mentor := e.addMentor(t, "ann", 0)
+	account := e.mentorAccount(t, mentor)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	now := time.Now()
+
+	expectErr(t, e.checkIn.RunDue(ctx, now.Add(10*day)), nil)
+	if got := e.notificationsOf(t, account.ID, domain.NotificationLearningStalled); len(got) != 0 {
+		t.Fatalf("alerts before the stall period = %d, want 0", len(got))
+	}
+
+	expectErr(t, e.checkIn.RunDue(ctx, now.Add(15*day)), nil)
+	expectErr(t, e.checkIn.RunDue(ctx, now.Add(16*day)), nil)
+	alerts := e.notificationsOf(t, account.ID, domain.NotificationLearningStalled)
+	if len(alerts) != 1 {
+		t.Fatalf("alerts for one stall = %d, want 1", len(alerts))
+	}
+
+	// Progress ends the stall; stalling again alerts again
+	expectErr(t, e.learnings.UpdatePlan(ctx, learning.ID, []domain.LearningPlanItem{{ID: "1", Text: "Read docs", Completed: true}}), nil)
+	expectErr(t, e.checkIn.RunDue(ctx, now.Add(30*day)), nil)
+	if got := e.notificationsOf(t, account.ID, domain.NotificationLearningStalled); len(got) != 2 {
+		t.Errorf("alerts after a second stall = %d, want 2", len(got))
+	}
+	if got := e.notificationsOf(t, alice.ID, domain.NotificationLearningStalled); len(got) != 0 {
+		t.Errorf("the learner got %d stall alerts, want none", len(got))
+	}
+}
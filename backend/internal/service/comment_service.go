@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// CommentService keeps the comment threads of training requests and
+// learning processes. A request thread is open to the requester and their
+// manager, a learning thread to the learner and the mentor. Users with
+// comments.moderate take part in every thread; only users with
+// comments.internal read and write internal comments.
+type CommentService struct {
+	tx            domain.TxManager
+	commentRepo   domain.CommentRepository
+	requestRepo   domain.RequestRepository
+	learningRepo  domain.LearningRepository
+	mentorRepo    domain.MentorRepository
+	userRepo      domain.UserRepository
+	notifications *NotificationService
+}
+
+func NewCommentService(
+	tx domain.TxManager,
+	commentRepo domain.CommentRepository,
+	requestRepo domain.RequestRepository,
+	learningRepo domain.LearningRepository,
+	mentorRepo domain.MentorRepository,
+	userRepo domain.UserRepository,
+	notifications *NotificationService,
+) *CommentService {
+	return &CommentService{
+		tx:            tx,
+		commentRepo:   commentRepo,
+		requestRepo:   requestRepo,
+		learningRepo:  learningRepo,
+		mentorRepo:    mentorRepo,
+		userRepo:      userRepo,
+		notifications: notifications,
+	}
+}
+
+// thread describes who takes part in a comment thread
+type thread struct {
+	target      domain.CommentTarget
+	topic       string
+	ownerID     string  // requester or learner
+	managerID   *string // requester's manager, request threads only
+	mentorEmail string  // mentor's sign-in email, learning threads only
+}
+
+// allows checks if the user takes part in the thread
+func (t *thread) allows(user *domain.User) bool {
+	switch {
+	case user.Can(domain.PermCommentsModerate), user.ID == t.ownerID:
+		return true
+	case t.managerID != nil && *t.managerID == user.ID:
+		return true
+	case t.mentorEmail != "" && strings.EqualFold(t.mentorEmail, user.Email):
+		return true
+	}
+	return false
+}
+
+// GetComments lists the thread of a request or learning, oldest first.
+// Deleted comments stay in the list without their body; internal comments
+// are only listed for users with comments.internal.
+func (s *CommentService) GetComments(ctx context.Context, target domain.CommentTarget, targetID, viewerID string) ([]*domain.Comment, error) {
+	_, viewer, err := s.join(ctx, target, targetID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.commentRepo.GetByTarget(ctx, target, targetID, viewer.Can(domain.PermCommentsInternal))
+}
+
+// AddComment posts a comment, or a reply when parentID is set, and notifies
+// the participants mentioned in it
+func (s *CommentService) AddComment(
+	ctx context.Context,
+	target domain.CommentTarget,
+	targetID, authorID string,
+	parentID *string,
+	body string,
+	visibility domain.CommentVisibility,
+) (*domain.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if visibility == "" {
+		visibility = domain.VisibilityPublic
+	}
+	if !visibility.IsValid() {
+		return nil, fmt.Errorf("%w: unknown visibility %q", domain.ErrInvalidInput, visibility)
+	}
+
+	t, author, err := s.join(ctx, target, targetID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if visibility == domain.VisibilityInternal && !author.Can(domain.PermCommentsInternal) {
+		return nil, domain.ErrForbidden
+	}
+
+	if parentID != nil {
+		parent, err := s.commentRepo.GetByID(ctx, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.TargetType != target || parent.TargetID != targetID || (parent.IsInternal() && !author.Can(domain.PermCommentsInternal)) {
+			return nil, domain.ErrCommentNotFound
+		}
+		if parent.IsInternal() && visibility != domain.VisibilityInternal {
+			return nil, fmt.Errorf("%w: replies to internal comments must be internal", domain.ErrInvalidInput)
+		}
+	}
+
+	comment := &domain.Comment{
+		TargetType: target,
+		TargetID:   targetID,
+		ParentID:   parentID,
+		AuthorID:   &author.ID,
+		Body:       body,
+		Visibility: visibility,
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.commentRepo.Create(ctx, comment); err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+		return s.notifyMentions(ctx, t, comment, author, domain.Mentions(body))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentRepo.GetByID(ctx, comment.ID)
+}
+
+// EditComment replaces the body of the user's own comment; only people
+// mentioned for the first time are notified
+func (s *CommentService) EditComment(ctx context.Context, id, userID, body string) (*domain.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, t, user, err := s.getComment(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !comment.IsAuthor(user.ID) {
+		return nil, domain.ErrNotCommentAuthor
+	}
+
+	seen := make(map[string]bool)
+	for _, email := range domain.Mentions(comment.Body) {
+		seen[strings.ToLower(email)] = true
+	}
+	var mentions []string
+	for _, email := range domain.Mentions(body) {
+		if !seen[strings.ToLower(email)] {
+			mentions = append(mentions, email)
+		}
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.commentRepo.UpdateBody(ctx, id, body); err != nil {
+			return err
+		}
+		comment.Body = body
+		return s.notifyMentions(ctx, t, comment, user, mentions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.commentRepo.GetByID(ctx, id)
+}
+
+// DeleteComment removes the body of a comment, keeping its place in the
+// thread for the replies. Authors delete their own comments, moderators any.
+func (s *CommentService) DeleteComment(ctx context.Context, id, userID string) error {
+	comment, _, user, err := s.getComment(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !comment.IsAuthor(user.ID) && !user.Can(domain.PermCommentsModerate) {
+		return domain.ErrNotCommentAuthor
+	}
+	return s.commentRepo.Delete(ctx, id)
+}
+
+// getComment loads a comment that is not deleted together with its thread
+// and checks that the user can see it
+func (s *CommentService) getComment(ctx context.Context, id, userID string) (*domain.Comment, *thread, *domain.User, error) {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	t, user, err := s.join(ctx, comment.TargetType, comment.TargetID, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if comment.IsInternal() && !user.Can(domain.PermCommentsInternal) {
+		return nil, nil, nil, domain.ErrCommentNotFound
+	}
+	if comment.IsDeleted() {
+		return nil, nil, nil, domain.ErrCommentDeleted
+	}
+	return comment, t, user, nil
+}
+
+// join loads the thread and the user and checks that the user takes part
+// in it
+func (s *CommentService) join(ctx context.Context, target domain.CommentTarget, targetID, userID string) (*thread, *domain.User, error) {
+	t, err := s.thread(ctx, target, targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !t.allows(user) {
+		return nil, nil, domain.ErrNotParticipant
+	}
+	return t, user, nil
+}
+
+// thread loads the request or learning a thread belongs to
+func (s *CommentService) thread(ctx context.Context, target domain.CommentTarget, targetID string) (*thread, error) {
+	switch target {
+	case domain.CommentOnRequest:
+		request, err := s.requestRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		requester, err := s.userRepo.GetByID(ctx, request.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get requester: %w", err)
+		}
+		return &thread{
+			target:    target,
+			topic:     request.Topic,
+			ownerID:   request.UserID,
+			managerID: requester.ManagerID,
+		}, nil
+
+	case domain.CommentOnLearning:
+		learning, err := s.learningRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		mentor, err := s.mentorRepo.GetByID(ctx, learning.MentorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mentor: %w", err)
+		}
+		return &thread{
+			target:      target,
+			topic:       learning.RequestTopic,
+			ownerID:     learning.UserID,
+			mentorEmail: mentor.Email,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown comment target %q", domain.ErrInvalidInput, target)
+}
+
+// notifyMentions notifies the mentioned users who can read the comment.
+// Unknown addresses, the author, deactivated users and outsiders are skipped
+// silently so a mention does not reveal who exists.
+func (s *CommentService) notifyMentions(ctx context.Context, t *thread, comment *domain.Comment, author *domain.User, emails []string) error {
+	for _, email := range emails {
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve mention: %w", err)
+		}
+		if user.ID == author.ID || !user.IsActive() || !t.allows(user) {
+			continue
+		}
+		if comment.IsInternal() && !user.Can(domain.PermCommentsInternal) {
+			continue
+		}
+
+		err = s.notifications.Notify(
+			ctx, user.ID, domain.NotificationMentioned,
+			fmt.Sprintf("%s mentioned you", author.Name),
+			fmt.Sprintf("On the %s %q: %s", t.target, t.topic, comment.Body),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCommentBody trims a comment body and checks its length
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", domain.ErrEmptyField
+	}
+	if len([]rune(body)) > domain.MaxCommentLength {
+		return "", fmt.Errorf("%w: comment is longer than %d characters", domain.ErrInvalidInput, domain.MaxCommentLength)
+	}
+	return body, nil
+}
@@ -0,0 +1,250 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// mentorAccount stores the user a mentor signs in with
+func (e *env) mentorAccount(t *testing.T, mentor *domain.Mentor) *domain.User {
+	t.Helper()
+
+	user := &domain.User{
+		Name:         mentor.Name,
+		Email:        mentor.Email,
+		PasswordHash: "hash",
+		Role:         domain.RoleEmployee,
+	}
+	if err := e.users.Create(defaultCtx(), user); err != nil {
+		t.Fatalf("add mentor account: %v", err)
+	}
+	return user
+}
+
+// mentions lists the titles of the mention notifications of a user
+func (e *env) mentions(t *testing.T, userID string) []string {
+	t.Helper()
+
+	notifications, err := e.notifications.GetByUserID(defaultCtx(), userID, false)
+	if err != nil {
+		t.Fatalf("get notifications: %v", err)
+	}
+	var titles []string
+	for _, n := range notifications {
+		if n.Kind == domain.NotificationMentioned {
+			titles = append(titles, n.Title)
+		}
+	}
+	return titles
+}
+
+func TestCommentService_RequestThread(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	boss := e.addUser(t, "boss")
+	bob := e.addUser(t, "bob")
+	admin := e.addAdmin(t, "admin")
+	e.reportsTo(t, alice, boss)
+	request := e.addRequest(t, alice.ID, domain.RequestPending)
+
+	first, err := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, nil, "  Can this start in May?  ", "")
+	expectErr(t, err, nil)
+	if first.Body != "Can this start in May?" || first.Visibility != domain.VisibilityPublic || first.AuthorName != "alice" {
+		t.Errorf("AddComment = %+v", first)
+	}
+
+	// The manager and admins take part, other employees do not
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, boss.ID, &first.ID, "Fine by me", "")
+	expectErr(t, err, nil)
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, admin.ID, &first.ID, "Scheduled", "")
+	expectErr(t, err, nil)
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, bob.ID, nil, "Me too", "")
+	expectErr(t, err, domain.ErrNotParticipant)
+	_, err = e.comment.GetComments(ctx, domain.CommentOnRequest, request.ID, bob.ID)
+	expectErr(t, err, domain.ErrNotParticipant)
+
+	comments, err := e.comment.GetComments(ctx, domain.CommentOnRequest, request.ID, alice.ID)
+	expectErr(t, err, nil)
+	if len(comments) != 3 || comments[0].ID != first.ID {
+		t.Fatalf("GetComments returned %d comments", len(comments))
+	}
+	for _, reply := range comments[1:] {
+		if reply.ParentID == nil || *reply.ParentID != first.ID {
+			t.Errorf("reply %q has parent %v, want %s", reply.Body, reply.ParentID, first.ID)
+		}
+	}
+
+	_, err = e.comment.GetComments(ctx, domain.CommentOnRequest, "missing", alice.ID)
+	expectErr(t, err, domain.ErrRequestNotFound)
+}
+
+func TestCommentService_LearningThread(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	boss := e.addUser(t, "boss")
+	e.reportsTo(t, alice, boss)
+	mentor := e.addMentor(t, "mentor", 0)
+	account := e.mentorAccount(t, mentor)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+
+	_, err := e.comment.AddComment(ctx, domain.CommentOnLearning, learning.ID, alice.ID, nil, "Chapter 1 done", "")
+	expectErr(t, err, nil)
+	_, err = e.comment.AddComment(ctx, domain.CommentOnLearning, learning.ID, account.ID, nil, "Great, on to chapter 2", "")
+	expectErr(t, err, nil)
+
+	// The manager follows the request, not the learning
+	_, err = e.comment.GetComments(ctx, domain.CommentOnLearning, learning.ID, boss.ID)
+	expectErr(t, err, domain.ErrNotParticipant)
+
+	comments, err := e.comment.GetComments(ctx, domain.CommentOnLearning, learning.ID, account.ID)
+	expectErr(t, err, nil)
+	if len(comments) != 2 || comments[1].AuthorName != "mentor" {
+		t.Fatalf("GetComments = %+v", comments)
+	}
+
+	// Notes are untouched by the thread
+	stored, _ := e.learnings.GetByID(ctx, learning.ID)
+	if stored.Notes != nil {
+		t.Errorf("notes = %q, want none", *stored.Notes)
+	}
+}
+
+func TestCommentService_Internal(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	admin := e.addAdmin(t, "admin")
+	request := e.addRequest(t, alice.ID, domain.RequestPending)
+
+	public, _ := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, nil, "Any news?", "")
+
+	_, err := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, nil, "secret", domain.VisibilityInternal)
+	expectErr(t, err, domain.ErrForbidden)
+
+	internal, err := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, admin.ID, nil, "Budget is tight", domain.VisibilityInternal)
+	expectErr(t, err, nil)
+
+	// Replies to internal comments stay internal and hidden from employees
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, admin.ID, &internal.ID, "Agreed", domain.VisibilityPublic)
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, admin.ID, &internal.ID, "Agreed", domain.VisibilityInternal)
+	expectErr(t, err, nil)
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, &internal.ID, "What budget?", "")
+	expectErr(t, err, domain.ErrCommentNotFound)
+	_, err = e.comment.EditComment(ctx, internal.ID, alice.ID, "x")
+	expectErr(t, err, domain.ErrCommentNotFound)
+
+	comments, _ := e.comment.GetComments(ctx, domain.CommentOnRequest, request.ID, alice.ID)
+	if len(comments) != 1 || comments[0].ID != public.ID {
+		t.Errorf("employee sees %d comments, want only the public one", len(comments))
+	}
+	comments, _ = e.comment.GetComments(ctx, domain.CommentOnRequest, request.ID, admin.ID)
+	if len(comments) != 3 {
+		t.Errorf("admin sees %d comments, want 3", len(comments))
+	}
+
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, admin.ID, nil, "x", "secret")
+	expectErr(t, err, domain.ErrInvalidInput)
+}
+
+func TestCommentService_EditDelete(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	boss := e.addUser(t, "boss")
+	admin := e.addAdmin(t, "admin")
+	e.reportsTo(t, alice, boss)
+	request := e.addRequest(t, alice.ID, domain.RequestPending)
+
+	comment, _ := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, nil, "draft", "")
+	reply, _ := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, boss.ID, &comment.ID, "ok", "")
+
+	edited, err := e.comment.EditComment(ctx, comment.ID, alice.ID, "final")
+	expectErr(t, err, nil)
+	if edited.Body != "final" || edited.EditedAt == nil {
+		t.Errorf("EditComment = %+v", edited)
+	}
+
+	// Only the author edits, even admins cannot
+	_, err = e.comment.EditComment(ctx, comment.ID, boss.ID, "hijack")
+	expectErr(t, err, domain.ErrNotCommentAuthor)
+	_, err = e.comment.EditComment(ctx, comment.ID, admin.ID, "hijack")
+	expectErr(t, err, domain.ErrNotCommentAuthor)
+	_, err = e.comment.EditComment(ctx, comment.ID, alice.ID, "   ")
+	expectErr(t, err, domain.ErrEmptyField)
+	_, err = e.comment.EditComment(ctx, comment.ID, alice.ID, strings.Repeat("x", domain.MaxCommentLength+1))
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	// Authors delete their own comments, admins any
+	expectErr(t, e.comment.DeleteComment(ctx, comment.ID, boss.ID), domain.ErrNotCommentAuthor)
+	expectErr(t, e.comment.DeleteComment(ctx, comment.ID, alice.ID), nil)
+	expectErr(t, e.comment.DeleteComment(ctx, reply.ID, admin.ID), nil)
+	expectErr(t, e.comment.DeleteComment(ctx, comment.ID, alice.ID), domain.ErrCommentDeleted)
+	_, err = e.comment.EditComment(ctx, comment.ID, alice.ID, "again")
+	expectErr(t, err, domain.ErrCommentDeleted)
+	expectErr(t, e.comment.DeleteComment(ctx, "missing", alice.ID), domain.ErrCommentNotFound)
+
+	// Deleted comments keep their place in the thread
+	comments, _ := e.comment.GetComments(ctx, domain.CommentOnRequest, request.ID, alice.ID)
+	if len(comments) != 2 || !comments[0].IsDeleted() || comments[0].Body != "" || comments[1].ParentID == nil {
+		t.Errorf("thread after delete = %+v", comments)
+	}
+}
+
+func TestCommentService_Mentions(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	boss := e.addUser(t, "boss")
+	bob := e.addUser(t, "bob")
+	admin := e.addAdmin(t, "admin")
+	e.reportsTo(t, alice, boss)
+	request := e.addRequest(t, alice.ID, domain.RequestPending)
+
+	// Outsiders, the author and unknown addresses are skipped
+	comment, err := e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, nil,
+		"@boss@example.com please approve, cc @bob@example.com @alice@example.com @nobody@example.com", "")
+	expectErr(t, err, nil)
+	if got := e.mentions(t, boss.ID); len(got) != 1 || got[0] != "alice mentioned you" {
+		t.Errorf("boss mentions = %v", got)
+	}
+	if got := e.mentions(t, bob.ID); len(got) != 0 {
+		t.Errorf("outsider was notified: %v", got)
+	}
+	if got := e.mentions(t, alice.ID); len(got) != 0 {
+		t.Errorf("author was notified: %v", got)
+	}
+
+	// A plain email address is not a mention
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, alice.ID, nil, "mail admin@example.com", "")
+	expectErr(t, err, nil)
+	if got := e.mentions(t, admin.ID); len(got) != 0 {
+		t.Errorf("admin was notified without a mention: %v", got)
+	}
+
+	// Editing notifies only people mentioned for the first time
+	_, err = e.comment.EditComment(ctx, comment.ID, alice.ID, "@Boss@example.com and @admin@example.com please approve")
+	expectErr(t, err, nil)
+	if got := e.mentions(t, boss.ID); len(got) != 1 {
+		t.Errorf("boss notified %d times, want once", len(got))
+	}
+	if got := e.mentions(t, admin.ID); len(got) != 1 {
+		t.Errorf("admin mentions = %v", got)
+	}
+
+	// Internal comments only reach admins
+	other := e.addAdmin(t, "other")
+	_, err = e.comment.AddComment(ctx, domain.CommentOnRequest, request.ID, admin.ID, nil,
+		"@alice@example.com @other@example.com", domain.VisibilityInternal)
+	expectErr(t, err, nil)
+	if got := e.mentions(t, alice.ID); len(got) != 0 {
+		t.Errorf("employee notified about an internal comment: %v", got)
+	}
+	if got := e.mentions(t, other.ID); len(got) != 1 {
+		t.Errorf("other admin mentions = %v", got)
+	}
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// CompetencyService manages the competency model: the skills, the levels
+// expected per department and job title, and the levels employees hold.
+// Gaps between the two drive training; completed learnings close them.
+type CompetencyService struct {
+	tx             domain.TxManager
+	skillRepo      domain.SkillRepository
+	competencyRepo domain.CompetencyRepository
+	userRepo       domain.UserRepository
+	departmentRepo domain.DepartmentRepository
+}
+
+func NewCompetencyService(
+	tx domain.TxManager,
+	skillRepo domain.SkillRepository,
+	competencyRepo domain.CompetencyRepository,
+	userRepo domain.UserRepository,
+	departmentRepo domain.DepartmentRepository,
+) *CompetencyService {
+	return &CompetencyService{
+		tx:             tx,
+		skillRepo:      skillRepo,
+		competencyRepo: competencyRepo,
+		userRepo:       userRepo,
+		departmentRepo: departmentRepo,
+	}
+}
+
+// GetSkills lists the competency model ordered by name
+func (s *CompetencyService) GetSkills(ctx context.Context) ([]*domain.Skill, error) {
+	return s.skillRepo.GetAll(ctx)
+}
+
+// CreateSkill adds a skill to the competency model
+func (s *CompetencyService) CreateSkill(ctx context.Context, skill *domain.Skill) (*domain.Skill, error) {
+	if err := skill.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.skillRepo.Create(ctx, skill); err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
+
+// UpdateSkill renames or redescribes a skill. Skill tags of existing
+// requests are not rewritten, so renaming a skill detaches it from them.
+func (s *CompetencyService) UpdateSkill(ctx context.Context, skill *domain.Skill) (*domain.Skill, error) {
+	if err := skill.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.skillRepo.Update(ctx, skill); err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
+
+// DeleteSkill removes a skill with its targets and the levels users hold
+func (s *CompetencyService) DeleteSkill(ctx context.Context, id string) error {
+	return s.skillRepo.Delete(ctx, id)
+}
+
+// GetTargets lists the skill targets ordered by skill name
+func (s *CompetencyService) GetTargets(ctx context.Context) ([]*domain.SkillTarget, error) {
+	return s.competencyRepo.GetTargets(ctx)
+}
+
+// CreateTarget sets the level expected in a skill from a department, a job
+// title or a job title within a department
+func (s *CompetencyService) CreateTarget(ctx context.Context, target *domain.SkillTarget) (*domain.SkillTarget, error) {
+	if err := target.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.competencyRepo.CreateTarget(ctx, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// DeleteTarget removes a skill target
+func (s *CompetencyService) DeleteTarget(ctx context.Context, id string) error {
+	return s.competencyRepo.DeleteTarget(ctx, id)
+}
+
+// GetUserSkills lists the levels a user holds; the user, their manager and
+// users with skills.manage may look
+func (s *CompetencyService) GetUserSkills(ctx context.Context, userID, viewerID string) ([]*domain.UserSkill, error) {
+	if _, err := s.assessor(ctx, userID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.competencyRepo.GetUserSkills(ctx, userID)
+}
+
+// AssessSkill records assessorID's view of the user's level in a skill. A
+// user assessing themselves gives the self assessment; their manager, or a
+// user with skills.manage standing in, gives the manager assessment, which
+// takes precedence.
+func (s *CompetencyService) AssessSkill(ctx context.Context, userID, skillID, assessorID string, level int) (*domain.UserSkill, error) {
+	self, err := s.assessor(ctx, userID, assessorID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.skillRepo.GetByID(ctx, skillID); err != nil {
+		return nil, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		userSkill, err := s.userSkill(ctx, userID, skillID)
+		if err != nil {
+			return err
+		}
+
+		if self {
+			err = userSkill.AssessSelf(level)
+		} else {
+			err = userSkill.AssessByManager(level, assessorID)
+		}
+		if err != nil {
+			return err
+		}
+
+		return s.competencyRepo.SaveUserSkill(ctx, userSkill)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload to get the skill name
+	return s.userSkill(ctx, userID, skillID)
+}
+
+// SkillGapReport lists the active users in scope below the target level in
+// a skill, for one department and its subdepartments or for everyone when
+// department is empty. A user's target in a skill is the highest of the
+// targets that apply to them; skills without a recorded level count as
+// level 0.
+func (s *CompetencyService) SkillGapReport(ctx context.Context, department string, scope domain.Scope) (*domain.SkillGapReport, error) {
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	targets, err := s.competencyRepo.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userSkills, err := s.competencyRepo.GetAllUserSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make(map[string]int, len(userSkills))
+	for _, us := range userSkills {
+		levels[us.UserID+"/"+us.SkillID] = us.Level
+	}
+
+	report := &domain.SkillGapReport{
+		Skills: make([]domain.SkillGapSummary, 0),
+		Gaps:   make([]domain.SkillGap, 0),
+	}
+	if department = strings.TrimSpace(department); department != "" {
+		d, err := s.departmentRepo.GetByName(ctx, department)
+		if err != nil {
+			return nil, err
+		}
+		departments, err := s.departmentRepo.GetAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get departments: %w", err)
+		}
+		report.Department = &d.Name
+		scope = scope.Within(domain.DepartmentSubtree(departments, d.ID))
+	}
+
+	// One summary per targeted skill, sorted by name below
+	summaries := make(map[string]*domain.SkillGapSummary)
+	var order []string
+	totalGaps := make(map[string]int)
+
+	for _, user := range users {
+		if !user.IsActive() || !scope.Includes(user) {
+			continue
+		}
+
+		wanted := make(map[string]*domain.SkillTarget)
+		for _, target := range targets {
+			if target.Applies(user) && (wanted[target.SkillID] == nil || target.Level > wanted[target.SkillID].Level) {
+				wanted[target.SkillID] = target
+			}
+		}
+
+		for skillID, target := range wanted {
+			summary, ok := summaries[skillID]
+			if !ok {
+				summary = &domain.SkillGapSummary{SkillID: skillID, SkillName: target.SkillName}
+				summaries[skillID] = summary
+				order = append(order, skillID)
+			}
+			summary.Employees++
+
+			level := levels[user.ID+"/"+skillID]
+			if level >= target.Level {
+				continue
+			}
+			summary.BelowTarget++
+			totalGaps[skillID] += target.Level - level
+			report.Gaps = append(report.Gaps, domain.SkillGap{
+				UserID:      user.ID,
+				UserName:    user.Name,
+				Department:  user.Department,
+				JobTitle:    user.JobTitle,
+				SkillID:     skillID,
+				SkillName:   target.SkillName,
+				TargetLevel: target.Level,
+				Level:       level,
+				Gap:         target.Level - level,
+			})
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		return strings.ToLower(summaries[order[i]].SkillName) < strings.ToLower(summaries[order[j]].SkillName)
+	})
+	for _, skillID := range order {
+		summary := summaries[skillID]
+		if summary.BelowTarget > 0 {
+			summary.AverageGap = float64(totalGaps[skillID]) / float64(summary.BelowTarget)
+		}
+		report.Skills = append(report.Skills, *summary)
+	}
+
+	// Largest gaps first within a skill
+	sort.SliceStable(report.Gaps, func(i, j int) bool {
+		a, b := report.Gaps[i], report.Gaps[j]
+		if an, bn := strings.ToLower(a.SkillName), strings.ToLower(b.SkillName); an != bn {
+			return an < bn
+		}
+		if a.Gap != b.Gap {
+			return a.Gap > b.Gap
+		}
+		return a.UserName < b.UserName
+	})
+
+	return report, nil
+}
+
+// recordLearning raises the user's level by one in each skill the tags name
+// after a positively rated learning; tags that are not skills of the model
+// are ignored
+func (s *CompetencyService) recordLearning(ctx context.Context, userID string, tags []string, rating int) error {
+	if rating < domain.PositiveRating || len(tags) == 0 {
+		return nil
+	}
+
+	skills, err := s.skillRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*domain.Skill, len(skills))
+	for _, skill := range skills {
+		byName[strings.ToLower(skill.Name)] = skill
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, tag := range domain.CleanSkills(tags) {
+			skill, ok := byName[strings.ToLower(tag)]
+			if !ok {
+				continue
+			}
+
+			userSkill, err := s.userSkill(ctx, userID, skill.ID)
+			if err != nil {
+				return err
+			}
+			if !userSkill.RaiseLevel() {
+				continue
+			}
+			if err := s.competencyRepo.SaveUserSkill(ctx, userSkill); err != nil {
+				return fmt.Errorf("failed to raise skill level: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// userSkill loads the user's standing in a skill, or a blank one when
+// nothing is recorded yet
+func (s *CompetencyService) userSkill(ctx context.Context, userID, skillID string) (*domain.UserSkill, error) {
+	userSkills, err := s.competencyRepo.GetUserSkills(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, us := range userSkills {
+		if us.SkillID == skillID {
+			return us, nil
+		}
+	}
+	return &domain.UserSkill{UserID: userID, SkillID: skillID}, nil
+}
+
+// assessor checks that actorID may see and assess the user's skills and
+// reports whether it is the user themselves
+func (s *CompetencyService) assessor(ctx context.Context, userID, actorID string) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if actorID == user.ID {
+		return true, nil
+	}
+	if user.ManagerID != nil && *user.ManagerID == actorID {
+		return false, nil
+	}
+
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return false, err
+	}
+	if !actor.Can(domain.PermSkillsManage) {
+		return false, domain.ErrForbidden
+	}
+	return false, nil
+}
@@ -0,0 +1,220 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// addSkill stores a skill of the competency model through the service
+func (e *env) addSkill(t *testing.T, name string) *domain.Skill {
+	t.Helper()
+
+	skill, err := e.competency.CreateSkill(defaultCtx(), &domain.Skill{Name: name})
+	if err != nil {
+		t.Fatalf("add skill: %v", err)
+	}
+	return skill
+}
+
+// placeIn sets the user's department, added when missing, and job title
+func (e *env) placeIn(t *testing.T, user *domain.User, department, jobTitle string) {
+	t.Helper()
+
+	d, err := e.departments.GetByName(defaultCtx(), department)
+	if errors.Is(err, domain.ErrDepartmentNotFound) {
+		d = e.addDepartment(t, department, nil)
+	} else if err != nil {
+		t.Fatalf("get department: %v", err)
+	}
+	user.DepartmentID = &d.ID
+	user.JobTitle = ptr(jobTitle)
+	if err := e.users.Update(defaultCtx(), user); err != nil {
+		t.Fatalf("place user: %v", err)
+	}
+}
+
+// levelOf reads the user's recorded level in a skill, 0 when there is none
+func (e *env) levelOf(t *testing.T, userID, skillID string) int {
+	t.Helper()
+
+	levels, err := e.competencies.GetUserSkills(defaultCtx(), userID)
+	if err != nil {
+		t.Fatalf("get user skills: %v", err)
+	}
+	for _, us := range levels {
+		if us.SkillID == skillID {
+			return us.Level
+		}
+	}
+	return 0
+}
+
+func TestCompetencyService_Assess(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	admin := e.addAdmin(t, "root")
+	boss := e.addUser(t, "boss")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	e.reportsTo(t, alice, boss)
+	goSkill := e.addSkill(t, "Go")
+
+	// The user's own assessment is the level until the manager has a say
+	us, err := e.competency.AssessSkill(ctx, alice.ID, goSkill.ID, alice.ID, 4)
+	expectErr(t, err, nil)
+	if us.Level != 4 || us.SelfLevel == nil || *us.SelfLevel != 4 || us.ManagerLevel != nil || us.SkillName != "Go" {
+		t.Fatalf("self assessment = %+v", us)
+	}
+
+	us, err = e.competency.AssessSkill(ctx, alice.ID, goSkill.ID, boss.ID, 2)
+	expectErr(t, err, nil)
+	if us.Level != 2 || us.ManagerLevel == nil || *us.ManagerLevel != 2 || us.AssessedBy == nil || *us.AssessedBy != boss.ID {
+		t.Fatalf("manager assessment = %+v", us)
+	}
+
+	us, err = e.competency.AssessSkill(ctx, alice.ID, goSkill.ID, alice.ID, 5)
+	expectErr(t, err, nil)
+	if us.Level != 2 || *us.SelfLevel != 5 {
+		t.Fatalf("self assessment after the manager's = %+v, want the manager's level to hold", us)
+	}
+
+	// Admins stand in for the manager
+	us, err = e.competency.AssessSkill(ctx, alice.ID, goSkill.ID, admin.ID, 3)
+	expectErr(t, err, nil)
+	if us.Level != 3 || *us.AssessedBy != admin.ID {
+		t.Fatalf("admin assessment = %+v", us)
+	}
+
+	_, err = e.competency.AssessSkill(ctx, alice.ID, goSkill.ID, bob.ID, 1)
+	expectErr(t, err, domain.ErrForbidden)
+	_, err = e.competency.GetUserSkills(ctx, alice.ID, bob.ID)
+	expectErr(t, err, domain.ErrForbidden)
+	_, err = e.competency.AssessSkill(ctx, alice.ID, goSkill.ID, alice.ID, 6)
+	expectErr(t, err, domain.ErrInvalidSkillLevel)
+	_, err = e.competency.AssessSkill(ctx, alice.ID, "00000000-0000-0000-0000-000000000000", alice.ID, 3)
+	expectErr(t, err, domain.ErrSkillNotFound)
+
+	levels, err := e.competency.GetUserSkills(ctx, alice.ID, boss.ID)
+	expectErr(t, err, nil)
+	if len(levels) != 1 || levels[0].Level != 3 {
+		t.Errorf("GetUserSkills = %+v", levels)
+	}
+}
+
+func TestCompetencyService_SkillGapReport(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	carol := e.addUser(t, "carol")
+	dave := e.addUser(t, "dave")
+	e.placeIn(t, alice, "Backend", "Senior Engineer")
+	e.placeIn(t, bob, "backend", "Engineer")
+	e.placeIn(t, carol, "Frontend", "Engineer")
+	e.placeIn(t, dave, "Backend", "Engineer")
+	goSkill := e.addSkill(t, "Go")
+	sql := e.addSkill(t, "SQL")
+
+	for _, target := range []*domain.SkillTarget{
+		{SkillID: goSkill.ID, Department: ptr("Backend"), Level: 3},
+		{SkillID: goSkill.ID, Department: ptr("Backend"), JobTitle: ptr("Senior Engineer"), Level: 5},
+		{SkillID: sql.ID, JobTitle: ptr("Engineer"), Level: 2},
+	} {
+		_, err := e.competency.CreateTarget(ctx, target)
+		expectErr(t, err, nil)
+	}
+	_, err := e.competency.CreateTarget(ctx, &domain.SkillTarget{SkillID: sql.ID, Department: ptr(" "), Level: 2})
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	_, err = e.competency.AssessSkill(ctx, alice.ID, goSkill.ID, admin.ID, 4)
+	expectErr(t, err, nil)
+	_, err = e.competency.AssessSkill(ctx, bob.ID, goSkill.ID, admin.ID, 3)
+	expectErr(t, err, nil)
+	_, err = e.user.DeactivateUser(ctx, dave.ID, admin.ID)
+	expectErr(t, err, nil)
+
+	report, err := e.competency.SkillGapReport(ctx, "BACKEND", domain.Scope{All: true})
+	expectErr(t, err, nil)
+	if report.Department == nil || *report.Department != "Backend" || len(report.Skills) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	// Alice is held to the senior target; Bob meets the department one;
+	// Dave is inactive
+	goSummary, sqlSummary := report.Skills[0], report.Skills[1]
+	if goSummary.SkillName != "Go" || goSummary.Employees != 2 || goSummary.BelowTarget != 1 || goSummary.AverageGap != 1 {
+		t.Errorf("Go summary = %+v", goSummary)
+	}
+	if sqlSummary.SkillName != "SQL" || sqlSummary.Employees != 1 || sqlSummary.BelowTarget != 1 || sqlSummary.AverageGap != 2 {
+		t.Errorf("SQL summary = %+v", sqlSummary)
+	}
+	if len(report.Gaps) != 2 || report.Gaps[0].UserID != alice.ID || report.Gaps[0].TargetLevel != 5 || report.Gaps[0].Gap != 1 ||
+		report.Gaps[1].UserID != bob.ID || report.Gaps[1].Level != 0 {
+		t.Errorf("gaps = %+v", report.Gaps)
+	}
+
+	_, err = e.competency.SkillGapReport(ctx, "Marketing", domain.Scope{All: true})
+	expectErr(t, err, domain.ErrDepartmentNotFound)
+
+	everyone, err := e.competency.SkillGapReport(ctx, "", domain.Scope{All: true})
+	expectErr(t, err, nil)
+	if everyone.Department != nil || len(everyone.Gaps) != 3 || everyone.Skills[1].Employees != 2 {
+		t.Errorf("company-wide report = %+v", everyone)
+	}
+}
+
+func TestCompetencyService_PositiveRatingRaisesLevels(t *testing.T) {
+	e := newEnvWithChain(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	goSkill := e.addSkill(t, "Go")
+	sql := e.addSkill(t, "SQL")
+	e.addMentor(t, "ann", 0)
+
+	_, err := e.competency.AssessSkill(ctx, alice.ID, sql.ID, alice.ID, 5)
+	expectErr(t, err, nil)
+
+	tests := []struct {
+		name   string
+		skills []string
+		rating int
+		goWant int
+	}{
+		{"positive rating", []string{" go ", "SQL", "Haskell"}, 4, 1},
+		{"lukewarm rating", []string{"Go"}, 3, 1},
+		{"untagged request", nil, 5, 1},
+		{"second positive rating", []string{"Go"}, 5, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			learning, _, err := e.learning.CreateLearningFromRequest(ctx, alice.ID, "Go", "Profiling", tt.skills)
+			expectErr(t, err, nil)
+			_, err = e.learning.CompleteLearning(ctx, learning.ID, tt.rating, "Helpful")
+			expectErr(t, err, nil)
+
+			if got := e.levelOf(t, alice.ID, goSkill.ID); got != tt.goWant {
+				t.Errorf("Go level = %d, want %d", got, tt.goWant)
+			}
+			// Experts stay at the top of the scale
+			if got := e.levelOf(t, alice.ID, sql.ID); got != domain.MaxSkillLevel {
+				t.Errorf("SQL level = %d, want %d", got, domain.MaxSkillLevel)
+			}
+		})
+	}
+
+	// Course enrollments count too
+	course := e.addCourse(t, "Go in Practice", nil)
+	_, err = e.course.RequestEnrollment(ctx, course.ID, alice.ID, "")
+	expectErr(t, err, nil)
+	enrollments, err := e.course.GetUserEnrollments(ctx, alice.ID)
+	expectErr(t, err, nil)
+	_, err = e.course.CompleteEnrollment(ctx, enrollments[0].ID, alice.ID, 5, "Great")
+	expectErr(t, err, nil)
+	if got := e.levelOf(t, alice.ID, goSkill.ID); got != 3 {
+		t.Errorf("Go level after the course = %d, want 3", got)
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// CourseService manages the course catalog and enrollments. Employees ask
+// for a seat with a training request that goes through the approval chain
+// like any other; ApprovalService enrolls them once it clears the last
+// stage.
+type CourseService struct {
+	courseRepo     domain.CourseRepository
+	enrollmentRepo domain.EnrollmentRepository
+	requestRepo    domain.RequestRepository
+	userRepo       domain.UserRepository
+	approvals      *ApprovalService
+	competencies   *CompetencyService
+}
+
+func NewCourseService(
+	courseRepo domain.CourseRepository,
+	enrollmentRepo domain.EnrollmentRepository,
+	requestRepo domain.RequestRepository,
+	userRepo domain.UserRepository,
+	approvals *ApprovalService,
+	competencies *CompetencyService,
+) *CourseService {
+	return &CourseService{
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		requestRepo:    requestRepo,
+		userRepo:       userRepo,
+		approvals:      approvals,
+		competencies:   competencies,
+	}
+}
+
+// CreateCourse adds a course to the catalog
+func (s *CourseService) CreateCourse(ctx context.Context, course *domain.Course) (*domain.Course, error) {
+	if err := course.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.courseRepo.Create(ctx, course); err != nil {
+		return nil, fmt.Errorf("failed to create course: %w", err)
+	}
+	return s.courseRepo.GetByID(ctx, course.ID)
+}
+
+// UpdateCourse replaces the catalog fields of a course. Lowering the
+// capacity below the seats taken keeps the current enrollments but lets
+// nobody else in.
+func (s *CourseService) UpdateCourse(ctx context.Context, course *domain.Course) (*domain.Course, error) {
+	if err := course.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.courseRepo.Update(ctx, course); err != nil {
+		return nil, err
+	}
+	return s.courseRepo.GetByID(ctx, course.ID)
+}
+
+// DeleteCourse removes a course nobody has asked to enroll in
+func (s *CourseService) DeleteCourse(ctx context.Context, id string) error {
+	return s.courseRepo.Delete(ctx, id)
+}
+
+// GetCourse retrieves a course of the catalog
+func (s *CourseService) GetCourse(ctx context.Context, id string) (*domain.Course, error) {
+	return s.courseRepo.GetByID(ctx, id)
+}
+
+// SearchCourses lists the catalog courses matching the filter
+func (s *CourseService) SearchCourses(ctx context.Context, filter domain.CourseFilter) ([]*domain.Course, error) {
+	if filter.Format != nil && !filter.Format.IsValid() {
+		return nil, fmt.Errorf("%w: unknown course format %q", domain.ErrInvalidInput, *filter.Format)
+	}
+	return s.courseRepo.Search(ctx, filter)
+}
+
+// RequestEnrollment submits a request for a seat on the course. The window
+// and the seats are checked now so nobody waits for approvals that cannot
+// succeed; the seat itself is only taken on the final approval.
+func (s *CourseService) RequestEnrollment(ctx context.Context, courseID, userID, description string) (*domain.TrainingRequest, error) {
+	course, err := s.courseRepo.GetByID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !course.EnrollmentOpen(time.Now()) {
+		return nil, domain.ErrEnrollmentClosed
+	}
+	if course.IsFull() {
+		return nil, domain.ErrCourseFull
+	}
+	if err := s.ensureNotEnrolled(ctx, courseID, userID); err != nil {
+		return nil, err
+	}
+
+	chain, err := s.approvals.chainFor(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if description == "" {
+		description = fmt.Sprintf("Enrollment in the %q course", course.Title)
+	}
+	request := &domain.TrainingRequest{
+		UserID:        userID,
+		Topic:         course.Title,
+		Description:   description,
+		CourseID:      &course.ID,
+		Skills:        course.Skills,
+		ApprovalChain: chain,
+	}
+	if err := s.approvals.submit(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return s.approvals.queue.getRequest(ctx, request.ID)
+}
+
+// GetUserEnrollments lists the user's enrollments, newest first
+func (s *CourseService) GetUserEnrollments(ctx context.Context, userID string) ([]*domain.CourseEnrollment, error) {
+	return s.enrollmentRepo.GetByUserID(ctx, userID)
+}
+
+// GetCourseEnrollments lists the enrollments of a course, oldest first
+func (s *CourseService) GetCourseEnrollments(ctx context.Context, courseID string) ([]*domain.CourseEnrollment, error) {
+	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
+		return nil, err
+	}
+	return s.enrollmentRepo.GetByCourseID(ctx, courseID)
+}
+
+// CompleteEnrollment marks the enrollment as completed with the
+// employee's feedback; the employee or an admin completes it. Like a
+// learning, a positive rating raises the employee's level in the course's
+// skills.
+func (s *CourseService) CompleteEnrollment(ctx context.Context, id, userID string, rating int, comment string) (*domain.CourseEnrollment, error) {
+	enrollment, err := s.ownEnrollment(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.IsActive() {
+		return nil, domain.ErrEnrollmentNotActive
+	}
+
+	if rating < 1 || rating > 5 {
+		return nil, domain.ErrInvalidRating
+	}
+
+	feedback := domain.Feedback{Rating: rating, Comment: comment}
+	if err := s.enrollmentRepo.Complete(ctx, id, feedback); err != nil {
+		return nil, err
+	}
+
+	request, err := s.requestRepo.GetByID(ctx, enrollment.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.competencies.recordLearning(ctx, enrollment.UserID, request.Skills, rating); err != nil {
+		return nil, err
+	}
+	return s.enrollmentRepo.GetByID(ctx, id)
+}
+
+// CancelEnrollment gives the seat back; the employee or an admin cancels
+func (s *CourseService) CancelEnrollment(ctx context.Context, id, userID string) (*domain.CourseEnrollment, error) {
+	enrollment, err := s.ownEnrollment(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.IsActive() {
+		return nil, domain.ErrEnrollmentNotActive
+	}
+
+	if err := s.enrollmentRepo.Cancel(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.enrollmentRepo.GetByID(ctx, id)
+}
+
+// ensureNotEnrolled rejects a second request while the user holds a seat on
+// the course or still waits for approval; finished courses can be retaken
+func (s *CourseService) ensureNotEnrolled(ctx context.Context, courseID, userID string) error {
+	requests, err := s.requestRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, request := range requests {
+		if _, waiting := request.Stage(); waiting && request.CourseID != nil && *request.CourseID == courseID {
+			return domain.ErrAlreadyEnrolled
+		}
+	}
+
+	enrollments, err := s.enrollmentRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, enrollment := range enrollments {
+		if enrollment.IsActive() && enrollment.CourseID == courseID {
+			return domain.ErrAlreadyEnrolled
+		}
+	}
+	return nil
+}
+
+// ownEnrollment loads an enrollment the user holds, or any enrollment for
+// users with courses.manage
+func (s *CourseService) ownEnrollment(ctx context.Context, id, userID string) (*domain.CourseEnrollment, error) {
+	enrollment, err := s.enrollmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.UserID == userID {
+		return enrollment, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Can(domain.PermCoursesManage) {
+		return nil, domain.ErrForbidden
+	}
+	return enrollment, nil
+}
@@ -0,0 +1,243 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// addCourse stores a course through the service
+func (e *env) addCourse(t *testing.T, title string, capacity *int) *domain.Course {
+	t.Helper()
+
+	course, err := e.course.CreateCourse(defaultCtx(), &domain.Course{
+		Title:         title,
+		Format:        domain.CourseOnline,
+		DurationHours: 8,
+		Skills:        []string{"Go"},
+		Capacity:      capacity,
+	})
+	if err != nil {
+		t.Fatalf("add course: %v", err)
+	}
+	return course
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestCourseService_CreateValidates(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	opens := time.Now()
+
+	tests := []struct {
+		name   string
+		course domain.Course
+		want   error
+	}{
+		{"valid", domain.Course{Title: " Go ", Format: domain.CourseBlended, DurationHours: 4, Skills: []string{" Go ", "go", ""}}, nil},
+		{"no title", domain.Course{Title: " ", Format: domain.CourseOnline, DurationHours: 4}, domain.ErrInvalidInput},
+		{"unknown format", domain.Course{Title: "Go", Format: "webinar", DurationHours: 4}, domain.ErrInvalidInput},
+		{"no duration", domain.Course{Title: "Go", Format: domain.CourseOnline}, domain.ErrInvalidInput},
+		{"zero capacity", domain.Course{Title: "Go", Format: domain.CourseOnline, DurationHours: 4, Capacity: intPtr(0)}, domain.ErrInvalidInput},
+		{"window closes first", domain.Course{Title: "Go", Format: domain.CourseOnline, DurationHours: 4, EnrollmentOpensAt: &opens, EnrollmentClosesAt: &opens}, domain.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			course := tt.course
+			created, err := e.course.CreateCourse(ctx, &course)
+			expectErr(t, err, tt.want)
+			if tt.want == nil && (created.Title != "Go" || len(created.Skills) != 1 || created.Skills[0] != "Go") {
+				t.Errorf("CreateCourse = %+v, want a trimmed title and deduplicated skills", created)
+			}
+		})
+	}
+}
+
+func TestCourseService_EnrollThroughApprovals(t *testing.T) {
+	e := newEnvWithChain(t, domain.StageManager, domain.StageAdmin)
+	ctx := defaultCtx()
+	admin := e.addAdmin(t, "root")
+	boss := e.addUser(t, "boss")
+	alice := e.addUser(t, "alice")
+	e.reportsTo(t, alice, boss)
+	course := e.addCourse(t, "Kubernetes Basics", intPtr(5))
+
+	request, err := e.course.RequestEnrollment(ctx, course.ID, alice.ID, "")
+	expectErr(t, err, nil)
+	if request.Status != domain.RequestAwaitingManager || request.CourseID == nil || *request.CourseID != course.ID ||
+		request.Topic != "Kubernetes Basics" || request.Description == "" {
+		t.Fatalf("RequestEnrollment = %+v", request)
+	}
+
+	// A second request waits for the first one
+	_, err = e.course.RequestEnrollment(ctx, course.ID, alice.ID, "")
+	expectErr(t, err, domain.ErrAlreadyEnrolled)
+
+	// Course requests never get a mentor
+	mentor := e.addMentor(t, "ann", 0)
+	_, err = e.queue.Enqueue(ctx, request.ID, 1)
+	expectErr(t, err, domain.ErrRequestNotPending)
+
+	_, err = e.approval.Decide(ctx, request.ID, boss.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, nil)
+	_, err = e.request.AssignMentor(ctx, request.ID, mentor.ID)
+	expectErr(t, err, domain.ErrCourseRequest)
+	_, err = e.queue.Enqueue(ctx, request.ID, 1)
+	expectErr(t, err, domain.ErrCourseRequest)
+
+	request, err = e.approval.Decide(ctx, request.ID, admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, nil)
+	if !request.IsApproved() || e.workload(t, mentor.ID) != 0 {
+		t.Fatalf("after the last approval status = %s, workload %d; want approved without a mentor", request.Status, e.workload(t, mentor.ID))
+	}
+
+	enrollments, err := e.course.GetUserEnrollments(ctx, alice.ID)
+	expectErr(t, err, nil)
+	if len(enrollments) != 1 || enrollments[0].RequestID != request.ID || !enrollments[0].IsActive() ||
+		enrollments[0].CourseTitle != "Kubernetes Basics" {
+		t.Fatalf("enrollments = %+v", enrollments)
+	}
+	if got, _ := e.course.GetCourse(ctx, course.ID); got.SeatsTaken != 1 {
+		t.Errorf("SeatsTaken = %d, want 1", got.SeatsTaken)
+	}
+
+	_, err = e.course.RequestEnrollment(ctx, course.ID, alice.ID, "")
+	expectErr(t, err, domain.ErrAlreadyEnrolled)
+	expectErr(t, e.course.DeleteCourse(ctx, course.ID), domain.ErrCourseInUse)
+}
+
+func TestCourseService_EmptyChainEnrollsRightAway(t *testing.T) {
+	e := newEnvWithChain(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	course := e.addCourse(t, "Go", intPtr(1))
+
+	request, err := e.course.RequestEnrollment(ctx, course.ID, alice.ID, "For the new service")
+	expectErr(t, err, nil)
+	if !request.IsApproved() || request.Description != "For the new service" {
+		t.Fatalf("RequestEnrollment = %s %q, want approved", request.Status, request.Description)
+	}
+
+	// The only seat is taken
+	_, err = e.course.RequestEnrollment(ctx, course.ID, bob.ID, "")
+	expectErr(t, err, domain.ErrCourseFull)
+}
+
+func TestCourseService_FullCourseBlocksFinalApproval(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	course := e.addCourse(t, "Go", intPtr(1))
+
+	first, err := e.course.RequestEnrollment(ctx, course.ID, alice.ID, "")
+	expectErr(t, err, nil)
+	second, err := e.course.RequestEnrollment(ctx, course.ID, bob.ID, "")
+	expectErr(t, err, nil)
+
+	_, err = e.approval.Decide(ctx, first.ID, admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, nil)
+	_, err = e.approval.Decide(ctx, second.ID, admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, domain.ErrCourseFull)
+
+	// Nothing of the failed approval is kept, so the admin can still reject
+	request, err := e.request.GetRequestByID(ctx, second.ID)
+	expectErr(t, err, nil)
+	decisions, _ := e.approval.GetDecisions(ctx, second.ID)
+	if !request.IsPending() || len(decisions) != 0 {
+		t.Fatalf("after a failed approval status = %s with %d decisions, want pending with none", request.Status, len(decisions))
+	}
+	_, err = e.approval.Decide(ctx, second.ID, admin.ID, domain.DecisionRejected, ptr("Course is full"))
+	expectErr(t, err, nil)
+}
+
+func TestCourseService_EnrollmentWindow(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	closed, err := e.course.CreateCourse(ctx, &domain.Course{Title: "Closed", Format: domain.CourseOnline, DurationHours: 1, EnrollmentClosesAt: &past})
+	expectErr(t, err, nil)
+	upcoming, err := e.course.CreateCourse(ctx, &domain.Course{Title: "Upcoming", Format: domain.CourseOnline, DurationHours: 1, EnrollmentOpensAt: &future})
+	expectErr(t, err, nil)
+
+	_, err = e.course.RequestEnrollment(ctx, closed.ID, alice.ID, "")
+	expectErr(t, err, domain.ErrEnrollmentClosed)
+	_, err = e.course.RequestEnrollment(ctx, upcoming.ID, alice.ID, "")
+	expectErr(t, err, domain.ErrEnrollmentClosed)
+	_, err = e.course.RequestEnrollment(ctx, missingCourseID, alice.ID, "")
+	expectErr(t, err, domain.ErrCourseNotFound)
+
+	now := time.Now()
+	open, err := e.course.SearchCourses(ctx, domain.CourseFilter{OpenAt: &now})
+	expectErr(t, err, nil)
+	if len(open) != 0 {
+		t.Errorf("SearchCourses open now returned %d courses, want none", len(open))
+	}
+	webinar := domain.CourseFormat("webinar")
+	_, err = e.course.SearchCourses(ctx, domain.CourseFilter{Format: &webinar})
+	expectErr(t, err, domain.ErrInvalidInput)
+}
+
+func TestCourseService_CompleteAndCancel(t *testing.T) {
+	e := newEnvWithChain(t)
+	ctx := defaultCtx()
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	goCourse := e.addCourse(t, "Go", intPtr(1))
+	k8s := e.addCourse(t, "Kubernetes", nil)
+
+	_, err := e.course.RequestEnrollment(ctx, goCourse.ID, alice.ID, "")
+	expectErr(t, err, nil)
+	_, err = e.course.RequestEnrollment(ctx, k8s.ID, alice.ID, "")
+	expectErr(t, err, nil)
+	enrollments, _ := e.course.GetUserEnrollments(ctx, alice.ID)
+	k8sEnrollment, goEnrollment := enrollments[0], enrollments[1]
+
+	_, err = e.course.CompleteEnrollment(ctx, goEnrollment.ID, bob.ID, 5, "")
+	expectErr(t, err, domain.ErrForbidden)
+	_, err = e.course.CompleteEnrollment(ctx, goEnrollment.ID, alice.ID, 6, "")
+	expectErr(t, err, domain.ErrInvalidRating)
+
+	completed, err := e.course.CompleteEnrollment(ctx, goEnrollment.ID, alice.ID, 5, "Great labs")
+	expectErr(t, err, nil)
+	if completed.Status != domain.EnrollmentCompleted || completed.Feedback == nil || completed.Feedback.Rating != 5 || completed.CompletedAt == nil {
+		t.Fatalf("CompleteEnrollment = %+v", completed)
+	}
+	_, err = e.course.CancelEnrollment(ctx, goEnrollment.ID, alice.ID)
+	expectErr(t, err, domain.ErrEnrollmentNotActive)
+
+	// A finished course can be taken again once a seat is free
+	_, err = e.course.RequestEnrollment(ctx, goCourse.ID, alice.ID, "")
+	expectErr(t, err, domain.ErrCourseFull)
+
+	_, err = e.course.CancelEnrollment(ctx, k8sEnrollment.ID, bob.ID)
+	expectErr(t, err, domain.ErrForbidden)
+	cancelled, err := e.course.CancelEnrollment(ctx, k8sEnrollment.ID, admin.ID)
+	expectErr(t, err, nil)
+	if cancelled.Status != domain.EnrollmentCancelled {
+		t.Errorf("CancelEnrollment status = %s, want cancelled", cancelled.Status)
+	}
+	_, err = e.course.RequestEnrollment(ctx, k8s.ID, alice.ID, "")
+	expectErr(t, err, nil)
+
+	roster, err := e.course.GetCourseEnrollments(ctx, k8s.ID)
+	expectErr(t, err, nil)
+	if len(roster) != 2 || roster[0].ID != k8sEnrollment.ID || !roster[1].IsActive() {
+		t.Errorf("GetCourseEnrollments = %+v", roster)
+	}
+	_, err = e.course.GetCourseEnrollments(ctx, missingCourseID)
+	expectErr(t, err, domain.ErrCourseNotFound)
+}
+
+// missingCourseID is a well-formed ID no course has
+const missingCourseID = "00000000-0000-0000-0000-000000000000"
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// DepartmentService manages the department tree and works out how far the
+// permissions of department-scoped roles reach: to the members of the
+// departments the user heads and of everything below them.
+type DepartmentService struct {
+	tx             domain.TxManager
+	departmentRepo domain.DepartmentRepository
+	userRepo       domain.UserRepository
+}
+
+func NewDepartmentService(
+	tx domain.TxManager,
+	departmentRepo domain.DepartmentRepository,
+	userRepo domain.UserRepository,
+) *DepartmentService {
+	return &DepartmentService{
+		tx:             tx,
+		departmentRepo: departmentRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// ListDepartments lists all departments ordered by name
+func (s *DepartmentService) ListDepartments(ctx context.Context) ([]*domain.Department, error) {
+	return s.departmentRepo.GetAll(ctx)
+}
+
+// GetDepartment retrieves a department by ID
+func (s *DepartmentService) GetDepartment(ctx context.Context, id string) (*domain.Department, error) {
+	return s.departmentRepo.GetByID(ctx, id)
+}
+
+// CreateDepartment adds a department below parentID, or at the top when it
+// is nil, headed by headID when set
+func (s *DepartmentService) CreateDepartment(ctx context.Context, name string, parentID, headID *string) (*domain.Department, error) {
+	department := &domain.Department{Name: name, ParentID: parentID, HeadID: headID}
+	if err := department.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkPlacement(ctx, department); err != nil {
+			return err
+		}
+		return s.departmentRepo.Create(ctx, department)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// UpdateDepartment renames a department, moves it below parentID and sets
+// its head. A department cannot be moved below itself.
+func (s *DepartmentService) UpdateDepartment(ctx context.Context, id, name string, parentID, headID *string) (*domain.Department, error) {
+	var department *domain.Department
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		department, err = s.departmentRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		department.Name = name
+		department.ParentID = parentID
+		department.HeadID = headID
+		if err := department.Validate(); err != nil {
+			return err
+		}
+		if err := s.checkPlacement(ctx, department); err != nil {
+			return err
+		}
+		return s.departmentRepo.Update(ctx, department)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// DeleteDepartment removes a department without members or subdepartments
+func (s *DepartmentService) DeleteDepartment(ctx context.Context, id string) error {
+	return s.departmentRepo.Delete(ctx, id)
+}
+
+// Scope works out how far the user's permission reaches
+func (s *DepartmentService) Scope(ctx context.Context, userID string, p domain.Permission) (domain.Scope, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return domain.Scope{}, err
+	}
+	return permissionScope(ctx, s.departmentRepo, user, p)
+}
+
+// Reaches checks if the actor's permission reaches the user; an unknown
+// user is out of reach
+func (s *DepartmentService) Reaches(ctx context.Context, actorID string, p domain.Permission, userID string) (bool, error) {
+	scope, err := s.Scope(ctx, actorID, p)
+	if err != nil || scope.All {
+		return scope.All, err
+	}
+	if scope.IsEmpty() {
+		return false, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return scope.Includes(user), nil
+}
+
+// Covers checks if the actor's role grants everything the user's role
+// grants, see domain.User.Covers. An unknown user is let through for the
+// caller to reject.
+func (s *DepartmentService) Covers(ctx context.Context, actorID, userID string) (bool, error) {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return false, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return actor.Covers(user), nil
+}
+
+// ReachesDepartment checks if the actor's permission reaches the members of
+// the named department, so they may move users into it; leaving users
+// without a department takes organisation-wide reach. An unknown name is
+// reached by nobody and fails with ErrInvalidInput.
+func (s *DepartmentService) ReachesDepartment(ctx context.Context, actorID string, p domain.Permission, name string) (bool, error) {
+	departmentID, err := lookupDepartment(ctx, s.departmentRepo, name)
+	if err != nil {
+		return false, err
+	}
+	scope, err := s.Scope(ctx, actorID, p)
+	if err != nil || scope.All {
+		return scope.All, err
+	}
+	return scope.IncludesDepartment(departmentID), nil
+}
+
+// checkPlacement checks that the parent and the head exist and, walking up
+// from the parent, that the department does not end up below itself
+func (s *DepartmentService) checkPlacement(ctx context.Context, department *domain.Department) error {
+	if department.HeadID != nil {
+		head, err := s.userRepo.GetByID(ctx, *department.HeadID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("%w: head %s does not exist", domain.ErrInvalidInput, *department.HeadID)
+		}
+		if err != nil {
+			return err
+		}
+		if !head.IsActive() {
+			return fmt.Errorf("head: %w", domain.ErrUserDeactivated)
+		}
+	}
+
+	seen := map[string]bool{}
+	for parentID := department.ParentID; parentID != nil && !seen[*parentID]; {
+		if *parentID == department.ID {
+			return domain.ErrDepartmentCycle
+		}
+		seen[*parentID] = true
+
+		parent, err := s.departmentRepo.GetByID(ctx, *parentID)
+		if errors.Is(err, domain.ErrDepartmentNotFound) {
+			return fmt.Errorf("%w: parent department %s does not exist", domain.ErrInvalidInput, *parentID)
+		}
+		if err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// permissionScope works out how far the user's permission reaches: the
+// whole organisation when their role grants it organisation-wide, the
+// subtrees of the departments they head when it is department-scoped, and
+// nobody otherwise
+func permissionScope(ctx context.Context, departmentRepo domain.DepartmentRepository, user *domain.User, p domain.Permission) (domain.Scope, error) {
+	if user.Can(p) {
+		return domain.Scope{All: true}, nil
+	}
+	if !user.Grants(p) {
+		return domain.Scope{}, nil
+	}
+
+	departments, err := departmentRepo.GetAll(ctx)
+	if err != nil {
+		return domain.Scope{}, fmt.Errorf("failed to get departments: %w", err)
+	}
+	var headed []string
+	for _, d := range departments {
+		if d.HeadID != nil && *d.HeadID == user.ID {
+			headed = append(headed, d.ID)
+		}
+	}
+	return domain.Scope{Departments: domain.DepartmentSubtree(departments, headed...)}, nil
+}
+
+// usersIn collects the IDs of the users in scope, or returns nil when the
+// scope is the whole organisation
+func usersIn(ctx context.Context, userRepo domain.UserRepository, scope domain.Scope) (map[string]bool, error) {
+	if scope.All {
+		return nil, nil
+	}
+	users, err := userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	ids := make(map[string]bool)
+	for _, u := range users {
+		if scope.Includes(u) {
+			ids[u.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// lookupDepartment resolves the name of an existing department, ignoring
+// case; a blank name means no department
+func lookupDepartment(ctx context.Context, departmentRepo domain.DepartmentRepository, name string) (*string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	department, err := departmentRepo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrDepartmentNotFound) {
+		return nil, fmt.Errorf("%w: department %q does not exist", domain.ErrInvalidInput, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &department.ID, nil
+}
+
+// provisionDepartment resolves a department name like lookupDepartment,
+// creating a top-level department when there is none; imports and identity
+// providers bring their own departments
+func provisionDepartment(ctx context.Context, departmentRepo domain.DepartmentRepository, name string) (*string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	department, err := departmentRepo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrDepartmentNotFound) {
+		department = &domain.Department{Name: name}
+		if err := department.Validate(); err != nil {
+			return nil, err
+		}
+		err = departmentRepo.Create(ctx, department)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &department.ID, nil
+}
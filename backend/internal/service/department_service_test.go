@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// addDepartmentHead stores a department head heading the departments
+func (e *env) addDepartmentHead(t *testing.T, name string, departments ...*domain.Department) *domain.User {
+	t.Helper()
+	ctx := defaultCtx()
+
+	head := e.addUser(t, name)
+	if _, err := e.user.ChangeRole(ctx, head.ID, domain.RoleDepartmentHead); err != nil {
+		t.Fatalf("promote department head: %v", err)
+	}
+	for _, d := range departments {
+		if _, err := e.department.UpdateDepartment(ctx, d.ID, d.Name, d.ParentID, &head.ID); err != nil {
+			t.Fatalf("set department head: %v", err)
+		}
+	}
+	return head
+}
+
+func TestDepartmentService_Tree(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	engineering := e.addDepartment(t, "Engineering", nil)
+	platform := e.addDepartment(t, "Platform", engineering)
+	sre := e.addDepartment(t, "SRE", platform)
+
+	_, err := e.department.CreateDepartment(ctx, "  ", nil, nil)
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.department.CreateDepartment(ctx, "platform", nil, nil)
+	expectErr(t, err, domain.ErrDepartmentExists)
+	_, err = e.department.CreateDepartment(ctx, "Legal", ptr("missing"), nil)
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.department.CreateDepartment(ctx, "Legal", nil, ptr("missing"))
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	// A department cannot move below itself, directly or further down
+	_, err = e.department.UpdateDepartment(ctx, engineering.ID, "Engineering", &engineering.ID, nil)
+	expectErr(t, err, domain.ErrDepartmentCycle)
+	_, err = e.department.UpdateDepartment(ctx, engineering.ID, "Engineering", &sre.ID, nil)
+	expectErr(t, err, domain.ErrDepartmentCycle)
+
+	moved, err := e.department.UpdateDepartment(ctx, sre.ID, " Site Reliability ", &engineering.ID, nil)
+	expectErr(t, err, nil)
+	if moved.Name != "Site Reliability" || *moved.ParentID != engineering.ID {
+		t.Fatalf("moved department = %+v", moved)
+	}
+
+	gone := e.addUser(t, "gone")
+	_, err = e.user.DeactivateUser(ctx, gone.ID, e.addAdmin(t, "root").ID)
+	expectErr(t, err, nil)
+	_, err = e.department.UpdateDepartment(ctx, platform.ID, "Platform", &engineering.ID, &gone.ID)
+	expectErr(t, err, domain.ErrUserDeactivated)
+
+	expectErr(t, e.department.DeleteDepartment(ctx, engineering.ID), domain.ErrDepartmentInUse)
+	expectErr(t, e.department.DeleteDepartment(ctx, platform.ID), nil)
+	_, err = e.department.GetDepartment(ctx, platform.ID)
+	expectErr(t, err, domain.ErrDepartmentNotFound)
+}
+
+func TestDepartmentService_Scope(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	engineering := e.addDepartment(t, "Engineering", nil)
+	platform := e.addDepartment(t, "Platform", engineering)
+	sales := e.addDepartment(t, "Sales", nil)
+	hank := e.addDepartmentHead(t, "hank", engineering)
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	carol := e.addUser(t, "carol")
+	e.placeIn(t, alice, "Platform", "Engineer")
+	e.placeIn(t, bob, "Sales", "Account Manager")
+
+	scope, err := e.department.Scope(ctx, hank.ID, domain.PermUsersView)
+	expectErr(t, err, nil)
+	if scope.All || !slices.Contains(scope.Departments, engineering.ID) || !slices.Contains(scope.Departments, platform.ID) ||
+		slices.Contains(scope.Departments, sales.ID) {
+		t.Fatalf("hank's scope = %+v, want Engineering and Platform", scope)
+	}
+	if scope, _ := e.department.Scope(ctx, hank.ID, domain.PermRolesManage); !scope.IsEmpty() {
+		t.Errorf("scope of a permission hank lacks = %+v", scope)
+	}
+	if scope, _ := e.department.Scope(ctx, admin.ID, domain.PermUsersView); !scope.All {
+		t.Errorf("admin's scope = %+v, want everyone", scope)
+	}
+
+	for _, tt := range []struct {
+		user *domain.User
+		want bool
+	}{{alice, true}, {bob, false}, {carol, false}} {
+		got, err := e.department.Reaches(ctx, hank.ID, domain.PermUsersManage, tt.user.ID)
+		expectErr(t, err, nil)
+		if got != tt.want {
+			t.Errorf("hank reaches %s = %v, want %v", tt.user.Name, got, tt.want)
+		}
+	}
+	// Hank may administer the employees but not a user whose role grants more
+	e.placeIn(t, admin, "Platform", "CTO")
+	otherHead := e.addDepartmentHead(t, "olga", platform)
+	for _, tt := range []struct {
+		actor, user *domain.User
+		want        bool
+	}{{hank, alice, true}, {hank, otherHead, true}, {hank, admin, false}, {admin, hank, true}, {alice, hank, false}} {
+		got, err := e.department.Covers(ctx, tt.actor.ID, tt.user.ID)
+		expectErr(t, err, nil)
+		if got != tt.want {
+			t.Errorf("%s covers %s = %v, want %v", tt.actor.Name, tt.user.Name, got, tt.want)
+		}
+	}
+	if ok, err := e.department.Covers(ctx, hank.ID, "missing"); err != nil || !ok {
+		t.Errorf("hank covers an unknown user = %v, %v", ok, err)
+	}
+
+	ok, err := e.department.ReachesDepartment(ctx, hank.ID, domain.PermUsersManage, "platform")
+	if err != nil || !ok {
+		t.Errorf("hank reaches Platform = %v, %v", ok, err)
+	}
+	ok, err = e.department.ReachesDepartment(ctx, hank.ID, domain.PermUsersManage, "")
+	if err != nil || ok {
+		t.Errorf("hank may clear departments = %v, %v", ok, err)
+	}
+	for _, actor := range []*domain.User{hank, admin} {
+		ok, err = e.department.ReachesDepartment(ctx, actor.ID, domain.PermUsersManage, "Marketing")
+		expectErr(t, err, domain.ErrInvalidInput)
+		if ok {
+			t.Errorf("%s reaches an unknown department", actor.Name)
+		}
+	}
+
+	// Hank decides every stage of the requests of his departments
+	request := e.addRequest(t, alice.ID, domain.StageAdmin.Status())
+	_, err = e.approval.Decide(ctx, request.ID, hank.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, nil)
+	request = e.addRequest(t, bob.ID, domain.StageAdmin.Status())
+	_, err = e.approval.Decide(ctx, request.ID, hank.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, domain.ErrNotApprover)
+
+	requests, err := e.request.GetAllRequests(ctx, nil, scope)
+	expectErr(t, err, nil)
+	if len(requests) != 1 || requests[0].UserID != alice.ID {
+		t.Errorf("requests in hank's scope = %+v", requests)
+	}
+
+	// and reports on them only
+	goSkill := e.addSkill(t, "Go")
+	_, err = e.competency.CreateTarget(ctx, &domain.SkillTarget{SkillID: goSkill.ID, JobTitle: ptr("Engineer"), Level: 3})
+	expectErr(t, err, nil)
+	e.placeIn(t, carol, "Sales", "Engineer")
+	report, err := e.competency.SkillGapReport(ctx, "", scope)
+	expectErr(t, err, nil)
+	if len(report.Gaps) != 1 || report.Gaps[0].UserID != alice.ID {
+		t.Errorf("hank's skill gaps = %+v", report.Gaps)
+	}
+	report, err = e.competency.SkillGapReport(ctx, "Sales", scope)
+	expectErr(t, err, nil)
+	if len(report.Gaps) != 0 {
+		t.Errorf("hank's skill gaps in Sales = %+v, want none", report.Gaps)
+	}
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// FeedbackService collects structured feedback on completed learnings: the
+// learner answers the active learner questionnaire about the mentor, the
+// mentor the active mentor questionnaire about the learner. Learners may
+// answer anonymously, which hides their answers from the mentor except in
+// the per-mentor aggregate.
+type FeedbackService struct {
+	tx                domain.TxManager
+	questionnaireRepo domain.QuestionnaireRepository
+	feedbackRepo      domain.FeedbackRepository
+	learningRepo      domain.LearningRepository
+	mentorRepo        domain.MentorRepository
+	userRepo          domain.UserRepository
+}
+
+func NewFeedbackService(
+	tx domain.TxManager,
+	questionnaireRepo domain.QuestionnaireRepository,
+	feedbackRepo domain.FeedbackRepository,
+	learningRepo domain.LearningRepository,
+	mentorRepo domain.MentorRepository,
+	userRepo domain.UserRepository,
+) *FeedbackService {
+	return &FeedbackService{
+		tx:                tx,
+		questionnaireRepo: questionnaireRepo,
+		feedbackRepo:      feedbackRepo,
+		learningRepo:      learningRepo,
+		mentorRepo:        mentorRepo,
+		userRepo:          userRepo,
+	}
+}
+
+// GetQuestionnaires lists all questionnaires by audience, newest first
+func (s *FeedbackService) GetQuestionnaires(ctx context.Context) ([]*domain.Questionnaire, error) {
+	return s.questionnaireRepo.GetAll(ctx)
+}
+
+// GetQuestionnaire retrieves a questionnaire by its ID
+func (s *FeedbackService) GetQuestionnaire(ctx context.Context, id string) (*domain.Questionnaire, error) {
+	return s.questionnaireRepo.GetByID(ctx, id)
+}
+
+// CreateQuestionnaire adds a questionnaire, making it the active one of
+// its audience when it is created active
+func (s *FeedbackService) CreateQuestionnaire(ctx context.Context, questionnaire *domain.Questionnaire) (*domain.Questionnaire, error) {
+	if err := questionnaire.Validate(); err != nil {
+		return nil, err
+	}
+
+	active := questionnaire.Active
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.questionnaireRepo.Create(ctx, questionnaire); err != nil {
+			return err
+		}
+		if active {
+			return s.questionnaireRepo.Activate(ctx, questionnaire.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.questionnaireRepo.GetByID(ctx, questionnaire.ID)
+}
+
+// UpdateQuestionnaire renames a questionnaire or changes its criteria.
+// Answered questionnaires cannot change, so that old answers keep their
+// meaning; a new questionnaire replaces them.
+func (s *FeedbackService) UpdateQuestionnaire(ctx context.Context, questionnaire *domain.Questionnaire) (*domain.Questionnaire, error) {
+	existing, err := s.questionnaireRepo.GetByID(ctx, questionnaire.ID)
+	if err != nil {
+		return nil, err
+	}
+	questionnaire.Audience = existing.Audience
+	if err := questionnaire.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.questionnaireRepo.Update(ctx, questionnaire); err != nil {
+		return nil, err
+	}
+	return questionnaire, nil
+}
+
+// DeleteQuestionnaire removes a questionnaire nobody has answered
+func (s *FeedbackService) DeleteQuestionnaire(ctx context.Context, id string) error {
+	return s.questionnaireRepo.Delete(ctx, id)
+}
+
+// ActivateQuestionnaire makes a questionnaire the one new feedback of its
+// audience answers
+func (s *FeedbackService) ActivateQuestionnaire(ctx context.Context, id string) (*domain.Questionnaire, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.questionnaireRepo.Activate(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.questionnaireRepo.GetByID(ctx, id)
+}
+
+// participant describes how a user takes part in a learning
+type participant struct {
+	learning *domain.LearningProcess
+	user     *domain.User
+	audience domain.FeedbackAudience // empty for admins and bystanders
+}
+
+// join loads the learning and says which feedback the user gives on it
+func (s *FeedbackService) join(ctx context.Context, learningID, userID string) (*participant, error) {
+	learning, err := s.learningRepo.GetByID(ctx, learningID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &participant{learning: learning, user: user}
+	if learning.UserID == user.ID {
+		p.audience = domain.FeedbackFromLearner
+		return p, nil
+	}
+	mentor, err := s.mentorRepo.GetByID(ctx, learning.MentorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentor: %w", err)
+	}
+	if strings.EqualFold(mentor.Email, user.Email) {
+		p.audience = domain.FeedbackFromMentor
+	}
+	return p, nil
+}
+
+// GetLearningFeedback lists the feedback on a learning with the
+// questionnaire the viewer still has to answer. The learner and users with
+// feedback.view see every response; the mentor does not see anonymous ones.
+func (s *FeedbackService) GetLearningFeedback(ctx context.Context, learningID, viewerID string) (*domain.LearningFeedback, error) {
+	p, err := s.join(ctx, learningID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if p.audience == "" && !p.user.Can(domain.PermFeedbackView) {
+		return nil, domain.ErrForbidden
+	}
+
+	responses, err := s.feedbackRepo.GetByLearningID(ctx, learningID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.LearningFeedback{Responses: make([]*domain.FeedbackResponse, 0, len(responses))}
+	answered := false
+	for _, response := range responses {
+		if response.Audience == p.audience {
+			answered = true
+		}
+		if response.Anonymous && p.audience == domain.FeedbackFromMentor && !p.user.Can(domain.PermFeedbackView) {
+			continue
+		}
+		result.Responses = append(result.Responses, response)
+	}
+
+	if p.audience != "" && !answered && p.learning.IsCompleted() {
+		pending, err := s.questionnaireRepo.GetActive(ctx, p.audience)
+		if err != nil && !errors.Is(err, domain.ErrQuestionnaireNotFound) {
+			return nil, err
+		}
+		result.Pending = pending
+	}
+
+	return result, nil
+}
+
+// SubmitFeedback records the author's answers to the active questionnaire
+// for their side of a completed learning. Only learners may stay anonymous.
+func (s *FeedbackService) SubmitFeedback(
+	ctx context.Context,
+	learningID, authorID string,
+	answers map[string]int,
+	comment string,
+	anonymous bool,
+) (*domain.FeedbackResponse, error) {
+	p, err := s.join(ctx, learningID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if p.audience == "" {
+		return nil, domain.ErrForbidden
+	}
+	if !p.learning.IsCompleted() {
+		return nil, domain.ErrLearningNotCompleted
+	}
+	if anonymous && p.audience != domain.FeedbackFromLearner {
+		return nil, fmt.Errorf("%w: only learners can give anonymous feedback", domain.ErrInvalidInput)
+	}
+
+	comment = strings.TrimSpace(comment)
+	if len([]rune(comment)) > domain.MaxCommentLength {
+		return nil, fmt.Errorf("%w: comment is longer than %d characters", domain.ErrInvalidInput, domain.MaxCommentLength)
+	}
+
+	questionnaire, err := s.questionnaireRepo.GetActive(ctx, p.audience)
+	if err != nil {
+		if errors.Is(err, domain.ErrQuestionnaireNotFound) {
+			return nil, domain.ErrNoQuestionnaire
+		}
+		return nil, err
+	}
+	if err := questionnaire.Check(answers); err != nil {
+		return nil, err
+	}
+
+	response := &domain.FeedbackResponse{
+		LearningID:      learningID,
+		QuestionnaireID: questionnaire.ID,
+		Audience:        p.audience,
+		AuthorID:        &p.user.ID,
+		MentorID:        p.learning.MentorID,
+		Answers:         answers,
+		Comment:         comment,
+		Anonymous:       anonymous,
+	}
+	if err := s.feedbackRepo.Create(ctx, response); err != nil {
+		return nil, err
+	}
+	response.AuthorName = p.user.Name
+
+	return response, nil
+}
+
+// GetMentorFeedback aggregates what learners said about a mentor for users
+// with feedback.view and the mentor themselves. Criteria are listed in the order of the
+// most recent questionnaire that asked them.
+func (s *FeedbackService) GetMentorFeedback(ctx context.Context, mentorID, viewerID string) (*domain.MentorFeedbackSummary, error) {
+	mentor, err := s.mentorRepo.GetByID(ctx, mentorID)
+	if err != nil {
+		return nil, err
+	}
+	viewer, err := s.userRepo.GetByID(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !viewer.Can(domain.PermFeedbackView) && !strings.EqualFold(mentor.Email, viewer.Email) {
+		return nil, domain.ErrForbidden
+	}
+
+	summary := &domain.MentorFeedbackSummary{
+		MentorID: mentorID,
+		Criteria: make([]domain.CriterionSummary, 0),
+		Comments: make([]string, 0),
+	}
+
+	learnings, err := s.learningRepo.GetByMentorID(ctx, mentorID)
+	if err != nil {
+		return nil, err
+	}
+	ratingSum := 0
+	for _, learning := range learnings {
+		if learning.Feedback != nil {
+			summary.RatedLearnings++
+			ratingSum += learning.Feedback.Rating
+		}
+	}
+	if summary.RatedLearnings > 0 {
+		summary.AverageRating = float64(ratingSum) / float64(summary.RatedLearnings)
+	}
+
+	responses, err := s.feedbackRepo.GetByMentorID(ctx, mentorID)
+	if err != nil {
+		return nil, err
+	}
+
+	questionnaires := make(map[string]*domain.Questionnaire)
+	index := make(map[string]int) // criterion key -> position in summary.Criteria
+	totals := make(map[string]int)
+	for _, response := range responses {
+		if response.Audience != domain.FeedbackFromLearner {
+			continue
+		}
+		summary.Responses++
+		if response.Comment != "" {
+			summary.Comments = append(summary.Comments, response.Comment)
+		}
+
+		questionnaire, ok := questionnaires[response.QuestionnaireID]
+		if !ok {
+			questionnaire, err = s.questionnaireRepo.GetByID(ctx, response.QuestionnaireID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get questionnaire: %w", err)
+			}
+			questionnaires[response.QuestionnaireID] = questionnaire
+		}
+		// Responses come newest first, so the labels and scales of the
+		// latest questionnaire win
+		for _, c := range questionnaire.Criteria {
+			if _, ok := index[c.Key]; !ok {
+				index[c.Key] = len(summary.Criteria)
+				summary.Criteria = append(summary.Criteria, domain.CriterionSummary{Key: c.Key, Label: c.Label, Min: c.Min, Max: c.Max})
+			}
+		}
+		for key, score := range response.Answers {
+			if i, ok := index[key]; ok {
+				summary.Criteria[i].Responses++
+				totals[key] += score
+			}
+		}
+	}
+
+	for i := range summary.Criteria {
+		c := &summary.Criteria[i]
+		if c.Responses > 0 {
+			c.Average = float64(totals[c.Key]) / float64(c.Responses)
+		}
+	}
+
+	return summary, nil
+}
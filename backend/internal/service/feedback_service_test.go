@@ -0,0 +1,306 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// addQuestionnaires stores the active questionnaires of both audiences,
+// shaped like the default ones
+func (e *env) addQuestionnaires(t *testing.T) (learner, mentor *domain.Questionnaire) {
+	t.Helper()
+	ctx := defaultCtx()
+
+	learner, err := e.feedback.CreateQuestionnaire(ctx, &domain.Questionnaire{
+		Name:     "Feedback on the mentor",
+		Audience: domain.FeedbackFromLearner,
+		Active:   true,
+		Criteria: []domain.FeedbackCriterion{
+			{Key: "clarity", Label: "Clarity", Min: 1, Max: 5, Required: true},
+			{Key: "availability", Label: "Availability", Min: 1, Max: 5, Required: true},
+			{Key: "usefulness", Label: "Usefulness", Min: 1, Max: 5},
+			{Key: "recommend", Label: "Recommend", Min: 0, Max: 10, Required: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("add learner questionnaire: %v", err)
+	}
+	mentor, err = e.feedback.CreateQuestionnaire(ctx, &domain.Questionnaire{
+		Name:     "Assessment of the learner",
+		Audience: domain.FeedbackFromMentor,
+		Active:   true,
+		Criteria: []domain.FeedbackCriterion{
+			{Key: "engagement", Label: "Engagement", Min: 1, Max: 5, Required: true},
+			{Key: "skill_growth", Label: "Skill growth", Min: 1, Max: 5, Required: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("add mentor questionnaire: %v", err)
+	}
+	return learner, mentor
+}
+
+// completedLearning stores a learning of the user and completes it with a
+// rating
+func (e *env) completedLearning(t *testing.T, userID string, mentor *domain.Mentor, rating int) *domain.LearningProcess {
+	t.Helper()
+
+	learning := e.addLearning(t, userID, mentor, domain.LearningActive)
+	learning, err := e.learning.CompleteLearning(defaultCtx(), learning.ID, rating, "Done")
+	if err != nil {
+		t.Fatalf("complete learning: %v", err)
+	}
+	return learning
+}
+
+func TestFeedbackService_Questionnaires(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	mentor := e.addMentor(t, "ann", 0)
+
+	invalid := []struct {
+		name     string
+		criteria []domain.FeedbackCriterion
+	}{
+		{"no criteria", nil},
+		{"duplicate key", []domain.FeedbackCriterion{{Key: "clarity", Label: "A", Min: 1, Max: 5}, {Key: "clarity", Label: "B", Min: 1, Max: 5}}},
+		{"bad key", []domain.FeedbackCriterion{{Key: "Clarity!", Label: "A", Min: 1, Max: 5}}},
+		{"empty scale", []domain.FeedbackCriterion{{Key: "clarity", Label: "A", Min: 3, Max: 3}}},
+		{"scale above ten", []domain.FeedbackCriterion{{Key: "clarity", Label: "A", Min: 0, Max: 11}}},
+		{"missing label", []domain.FeedbackCriterion{{Key: "clarity", Label: " ", Min: 1, Max: 5}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.feedback.CreateQuestionnaire(ctx, &domain.Questionnaire{Name: "Form", Audience: domain.FeedbackFromLearner, Criteria: tt.criteria})
+			expectErr(t, err, domain.ErrInvalidInput)
+		})
+	}
+	_, err := e.feedback.CreateQuestionnaire(ctx, &domain.Questionnaire{
+		Name:     "Form",
+		Audience: "manager",
+		Criteria: []domain.FeedbackCriterion{{Key: "clarity", Label: "A", Min: 1, Max: 5}},
+	})
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	learnerForm, _ := e.addQuestionnaires(t)
+
+	// A new active questionnaire retires the previous one
+	replacement, err := e.feedback.CreateQuestionnaire(ctx, &domain.Questionnaire{
+		Name:     " Short form ",
+		Audience: domain.FeedbackFromLearner,
+		Active:   true,
+		Criteria: []domain.FeedbackCriterion{{Key: "recommend", Label: "Recommend", Min: 0, Max: 10, Required: true}},
+	})
+	expectErr(t, err, nil)
+	if !replacement.Active || replacement.Name != "Short form" {
+		t.Errorf("replacement = %+v", replacement)
+	}
+	old, err := e.feedback.GetQuestionnaire(ctx, learnerForm.ID)
+	expectErr(t, err, nil)
+	if old.Active {
+		t.Error("the replaced questionnaire is still active")
+	}
+
+	// Unanswered questionnaires can change, but not their audience
+	replacement.Name = "Very short form"
+	replacement.Audience = domain.FeedbackFromMentor
+	updated, err := e.feedback.UpdateQuestionnaire(ctx, replacement)
+	expectErr(t, err, nil)
+	if updated.Name != "Very short form" || updated.Audience != domain.FeedbackFromLearner {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	learning := e.completedLearning(t, alice.ID, mentor, 5)
+	_, err = e.feedback.SubmitFeedback(ctx, learning.ID, alice.ID, map[string]int{"recommend": 9}, "", false)
+	expectErr(t, err, nil)
+
+	_, err = e.feedback.UpdateQuestionnaire(ctx, replacement)
+	expectErr(t, err, domain.ErrQuestionnaireInUse)
+	expectErr(t, e.feedback.DeleteQuestionnaire(ctx, replacement.ID), domain.ErrQuestionnaireInUse)
+
+	// The old one can come back, or go
+	reactivated, err := e.feedback.ActivateQuestionnaire(ctx, learnerForm.ID)
+	expectErr(t, err, nil)
+	if !reactivated.Active {
+		t.Errorf("reactivated = %+v", reactivated)
+	}
+	expectErr(t, e.feedback.DeleteQuestionnaire(ctx, learnerForm.ID), nil)
+	_, err = e.feedback.ActivateQuestionnaire(ctx, learnerForm.ID)
+	expectErr(t, err, domain.ErrQuestionnaireNotFound)
+
+	all, err := e.feedback.GetQuestionnaires(ctx)
+	expectErr(t, err, nil)
+	if len(all) != 2 {
+		t.Errorf("questionnaires = %+v", all)
+	}
+}
+
+func TestFeedbackService_SubmitFeedback(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	mentor := e.addMentor(t, "ann", 0)
+	account := e.mentorAccount(t, mentor)
+	active := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	learning := e.completedLearning(t, alice.ID, mentor, 4)
+
+	// Nothing to answer before admins set up the questionnaires
+	_, err := e.feedback.SubmitFeedback(ctx, learning.ID, alice.ID, map[string]int{"clarity": 5}, "", false)
+	expectErr(t, err, domain.ErrNoQuestionnaire)
+
+	learnerForm, mentorForm := e.addQuestionnaires(t)
+	answers := map[string]int{"clarity": 5, "availability": 4, "recommend": 10}
+
+	tests := []struct {
+		name       string
+		learningID string
+		authorID   string
+		answers    map[string]int
+		anonymous  bool
+		want       error
+	}{
+		{"learning not completed", active.ID, alice.ID, answers, false, domain.ErrLearningNotCompleted},
+		{"bystander", learning.ID, bob.ID, answers, false, domain.ErrForbidden},
+		{"admin", learning.ID, admin.ID, answers, false, domain.ErrForbidden},
+		{"missing learning", "00000000-0000-0000-0000-000000000000", alice.ID, answers, false, domain.ErrLearningNotFound},
+		{"missing required criterion", learning.ID, alice.ID, map[string]int{"clarity": 5, "availability": 4}, false, domain.ErrInvalidFeedback},
+		{"score off the scale", learning.ID, alice.ID, map[string]int{"clarity": 6, "availability": 4, "recommend": 10}, false, domain.ErrInvalidFeedback},
+		{"unknown criterion", learning.ID, alice.ID, map[string]int{"clarity": 5, "availability": 4, "recommend": 10, "speed": 3}, false, domain.ErrInvalidFeedback},
+		{"anonymous mentor", learning.ID, account.ID, map[string]int{"engagement": 5, "skill_growth": 4}, true, domain.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.feedback.SubmitFeedback(ctx, tt.learningID, tt.authorID, tt.answers, "", tt.anonymous)
+			expectErr(t, err, tt.want)
+		})
+	}
+
+	fromLearner, err := e.feedback.SubmitFeedback(ctx, learning.ID, alice.ID, answers, "  Patient and clear  ", true)
+	expectErr(t, err, nil)
+	if fromLearner.Audience != domain.FeedbackFromLearner || fromLearner.QuestionnaireID != learnerForm.ID ||
+		fromLearner.MentorID != mentor.ID || !fromLearner.Anonymous || fromLearner.Comment != "Patient and clear" {
+		t.Errorf("learner feedback = %+v", fromLearner)
+	}
+	_, err = e.feedback.SubmitFeedback(ctx, learning.ID, alice.ID, answers, "", false)
+	expectErr(t, err, domain.ErrFeedbackExists)
+
+	fromMentor, err := e.feedback.SubmitFeedback(ctx, learning.ID, account.ID, map[string]int{"engagement": 5, "skill_growth": 3}, "", false)
+	expectErr(t, err, nil)
+	if fromMentor.Audience != domain.FeedbackFromMentor || fromMentor.QuestionnaireID != mentorForm.ID {
+		t.Errorf("mentor feedback = %+v", fromMentor)
+	}
+}
+
+func TestFeedbackService_GetLearningFeedback(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	mentor := e.addMentor(t, "ann", 0)
+	account := e.mentorAccount(t, mentor)
+	learnerForm, mentorForm := e.addQuestionnaires(t)
+	learning := e.completedLearning(t, alice.ID, mentor, 4)
+
+	// Both sides still owe their answers
+	feedback, err := e.feedback.GetLearningFeedback(ctx, learning.ID, alice.ID)
+	expectErr(t, err, nil)
+	if len(feedback.Responses) != 0 || feedback.Pending == nil || feedback.Pending.ID != learnerForm.ID {
+		t.Errorf("learner view before feedback = %+v", feedback)
+	}
+	feedback, err = e.feedback.GetLearningFeedback(ctx, learning.ID, account.ID)
+	expectErr(t, err, nil)
+	if feedback.Pending == nil || feedback.Pending.ID != mentorForm.ID {
+		t.Errorf("mentor view before feedback = %+v", feedback)
+	}
+
+	_, err = e.feedback.SubmitFeedback(ctx, learning.ID, alice.ID, map[string]int{"clarity": 2, "availability": 2, "recommend": 3}, "Often late", true)
+	expectErr(t, err, nil)
+	_, err = e.feedback.SubmitFeedback(ctx, learning.ID, account.ID, map[string]int{"engagement": 5, "skill_growth": 4}, "", false)
+	expectErr(t, err, nil)
+
+	tests := []struct {
+		name      string
+		viewerID  string
+		responses int
+		want      error
+	}{
+		{"learner", alice.ID, 2, nil},
+		{"mentor does not see anonymous feedback", account.ID, 1, nil},
+		{"admin", admin.ID, 2, nil},
+		{"bystander", bob.ID, 0, domain.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feedback, err := e.feedback.GetLearningFeedback(ctx, learning.ID, tt.viewerID)
+			expectErr(t, err, tt.want)
+			if err != nil {
+				return
+			}
+			if len(feedback.Responses) != tt.responses || feedback.Pending != nil {
+				t.Errorf("feedback = %+v, want %d responses and nothing pending", feedback, tt.responses)
+			}
+		})
+	}
+}
+
+func TestFeedbackService_GetMentorFeedback(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	admin := e.addAdmin(t, "root")
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	mentor := e.addMentor(t, "ann", 0)
+	account := e.mentorAccount(t, mentor)
+	other := e.addMentor(t, "olga", 0)
+	e.addQuestionnaires(t)
+
+	first := e.completedLearning(t, alice.ID, mentor, 5)
+	second := e.completedLearning(t, bob.ID, mentor, 2)
+	e.completedLearning(t, bob.ID, other, 1)
+
+	_, err := e.feedback.SubmitFeedback(ctx, first.ID, alice.ID, map[string]int{"clarity": 5, "availability": 3, "usefulness": 4, "recommend": 9}, "Great explanations", false)
+	expectErr(t, err, nil)
+	_, err = e.feedback.SubmitFeedback(ctx, second.ID, bob.ID, map[string]int{"clarity": 2, "availability": 2, "recommend": 4}, "Hard to reach", true)
+	expectErr(t, err, nil)
+	// The mentor's assessments of learners do not count toward their own
+	_, err = e.feedback.SubmitFeedback(ctx, first.ID, account.ID, map[string]int{"engagement": 1, "skill_growth": 1}, "Rarely prepared", false)
+	expectErr(t, err, nil)
+
+	_, err = e.feedback.GetMentorFeedback(ctx, mentor.ID, alice.ID)
+	expectErr(t, err, domain.ErrForbidden)
+	_, err = e.feedback.GetMentorFeedback(ctx, other.ID, account.ID)
+	expectErr(t, err, domain.ErrForbidden)
+	_, err = e.feedback.GetMentorFeedback(ctx, "00000000-0000-0000-0000-000000000000", admin.ID)
+	expectErr(t, err, domain.ErrMentorNotFound)
+
+	for _, viewer := range []*domain.User{admin, account} {
+		summary, err := e.feedback.GetMentorFeedback(ctx, mentor.ID, viewer.ID)
+		expectErr(t, err, nil)
+
+		if summary.RatedLearnings != 2 || summary.AverageRating != 3.5 || summary.Responses != 2 {
+			t.Fatalf("summary for %s = %+v", viewer.Name, summary)
+		}
+		want := []domain.CriterionSummary{
+			{Key: "clarity", Label: "Clarity", Min: 1, Max: 5, Responses: 2, Average: 3.5},
+			{Key: "availability", Label: "Availability", Min: 1, Max: 5, Responses: 2, Average: 2.5},
+			{Key: "usefulness", Label: "Usefulness", Min: 1, Max: 5, Responses: 1, Average: 4},
+			{Key: "recommend", Label: "Recommend", Min: 0, Max: 10, Responses: 2, Average: 6.5},
+		}
+		if len(summary.Criteria) != len(want) {
+			t.Fatalf("criteria = %+v", summary.Criteria)
+		}
+		for i := range want {
+			if summary.Criteria[i] != want[i] {
+				t.Errorf("criterion %d = %+v, want %+v", i, summary.Criteria[i], want[i])
+			}
+		}
+		if len(summary.Comments) != 2 || summary.Comments[0] != "Hard to reach" || summary.Comments[1] != "Great explanations" {
+			t.Errorf("comments = %q, want newest first", summary.Comments)
+		}
+	}
+}
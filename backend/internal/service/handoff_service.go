@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// HandoffService moves all active learnings of a departing mentor to other
+// mentors in one transaction
+type HandoffService struct {
+	tx               domain.TxManager
+	mentorRepo       domain.MentorRepository
+	learningRepo     domain.LearningRepository
+	availabilityRepo domain.AvailabilityRepository
+	notifications    *NotificationService
+	outbox           *OutboxService
+}
+
+func NewHandoffService(
+	tx domain.TxManager,
+	mentorRepo domain.MentorRepository,
+	learningRepo domain.LearningRepository,
+	availabilityRepo domain.AvailabilityRepository,
+	notifications *NotificationService,
+	outbox *OutboxService,
+) *HandoffService {
+	return &HandoffService{
+		tx:               tx,
+		mentorRepo:       mentorRepo,
+		learningRepo:     learningRepo,
+		availabilityRepo: availabilityRepo,
+		notifications:    notifications,
+		outbox:           outbox,
+	}
+}
+
+// HandoffAssignment is the replacement mentor proposed for one learning;
+// MentorID is empty when no mentor has capacity left
+type HandoffAssignment struct {
+	LearningID string `json:"learningId"`
+	UserID     string `json:"userId"`
+	UserName   string `json:"userName"`
+	Topic      string `json:"topic"`
+	MentorID   string `json:"mentorId,omitempty"`
+	MentorName string `json:"mentorName,omitempty"`
+}
+
+// HandoffPlan lists the active learnings of a mentor with their replacements
+type HandoffPlan struct {
+	MentorID    string              `json:"mentorId"`
+	MentorName  string              `json:"mentorName"`
+	Assignments []HandoffAssignment `json:"assignments"`
+}
+
+// Unassigned returns the learnings without a replacement mentor
+func (p *HandoffPlan) Unassigned() []string {
+	var ids []string
+	for _, a := range p.Assignments {
+		if a.MentorID == "" {
+			ids = append(ids, a.LearningID)
+		}
+	}
+	return ids
+}
+
+// HandoffOptions tune how a handoff is executed
+type HandoffOptions struct {
+	Overrides  map[string]string // learning ID -> mentor ID chosen by an admin
+	Note       string            // appended to the notes of every learning
+	Deactivate bool              // deactivate the mentor once their learnings are moved
+}
+
+// PlanHandoff proposes a replacement for every active learning of a mentor,
+// least loaded mentors first, without changing anything
+func (s *HandoffService) PlanHandoff(ctx context.Context, mentorID string) (*HandoffPlan, error) {
+	plan, _, err := s.plan(ctx, mentorID, nil)
+	return plan, err
+}
+
+// ExecuteHandoff reassigns every active learning of a mentor according to
+// the proposal, with admin overrides applied, appends a handoff note to each
+// learning and notifies the learners. Nothing changes unless every learning
+// gets a mentor; in that case the incomplete plan is returned with
+// ErrNoReplacement.
+func (s *HandoffService) ExecuteHandoff(ctx context.Context, mentorID string, opts HandoffOptions) (*HandoffPlan, error) {
+	var plan *HandoffPlan
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, learnings, err := s.plan(ctx, mentorID, opts.Overrides)
+		if err != nil {
+			return err
+		}
+		plan = p
+
+		if unassigned := p.Unassigned(); len(unassigned) > 0 {
+			return fmt.Errorf("%w: %d of %d learnings left", domain.ErrNoReplacement, len(unassigned), len(p.Assignments))
+		}
+
+		taken := make(map[string]int)
+		for _, a := range p.Assignments {
+			if err := s.handOver(ctx, learnings[a.LearningID], p.MentorName, a, opts.Note); err != nil {
+				return err
+			}
+			taken[a.MentorID]++
+		}
+
+		// The increments fail rather than overbook a mentor whose slots were
+		// taken since the plan was made, which rolls the handoff back
+		for id, count := range taken {
+			if err := s.mentorRepo.IncrementWorkload(ctx, id, count); err != nil {
+				return fmt.Errorf("failed to update workload of mentor %s: %w", id, err)
+			}
+		}
+		if err := s.mentorRepo.DecrementWorkload(ctx, mentorID, len(p.Assignments)); err != nil {
+			return fmt.Errorf("failed to update mentor workload: %w", err)
+		}
+
+		if opts.Deactivate {
+			mentor, err := s.mentorRepo.GetByID(ctx, mentorID)
+			if err != nil {
+				return err
+			}
+			if mentor.IsActive() {
+				now := time.Now()
+				if err := s.mentorRepo.UpdateDeactivatedAt(ctx, mentorID, &now); err != nil {
+					return fmt.Errorf("failed to deactivate mentor: %w", err)
+				}
+			}
+		}
+
+		return nil
+	})
+	return plan, err
+}
+
+// handOver moves one learning, records the handoff in its notes and tells
+// the learner
+func (s *HandoffService) handOver(ctx context.Context, learning *domain.LearningProcess, from string, a HandoffAssignment, note string) error {
+	if err := s.learningRepo.UpdateMentor(ctx, learning.ID, learning.MentorID, a.MentorID); err != nil {
+		return fmt.Errorf("failed to update learning mentor: %w", err)
+	}
+
+	entry := fmt.Sprintf("Handoff on %s: mentor changed from %s to %s.", time.Now().Format("2006-01-02"), from, a.MentorName)
+	if note != "" {
+		entry += " " + note
+	}
+	notes := entry
+	if learning.Notes != nil && *learning.Notes != "" {
+		notes = *learning.Notes + "\n\n" + entry
+	}
+	if err := s.learningRepo.UpdateNotes(ctx, learning.ID, notes); err != nil {
+		return fmt.Errorf("failed to update learning notes: %w", err)
+	}
+
+	event := learningEvent(learning)
+	event.MentorID = a.MentorID
+	event.PreviousMentorID = learning.MentorID
+	if err := s.outbox.Publish(ctx, domain.EventLearningMentorChanged, event); err != nil {
+		return err
+	}
+
+	return s.notifications.Notify(
+		ctx, learning.UserID, domain.NotificationMentorChanged,
+		"Your mentor has changed",
+		fmt.Sprintf("%s is now your mentor for %q, taking over from %s.", a.MentorName, learning.RequestTopic, from),
+	)
+}
+
+// plan builds the handoff plan and returns the active learnings by ID.
+// Overrides are checked and placed first; the remaining learnings, oldest
+// first, go to the least loaded active mentor with a free slot.
+func (s *HandoffService) plan(ctx context.Context, mentorID string, overrides map[string]string) (*HandoffPlan, map[string]*domain.LearningProcess, error) {
+	mentor, err := s.mentorRepo.GetByID(ctx, mentorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	all, err := s.learningRepo.GetByMentorID(ctx, mentorID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get learnings of mentor: %w", err)
+	}
+
+	// GetByMentorID is newest first; the longest running learnings pick first
+	var active []*domain.LearningProcess
+	learnings := make(map[string]*domain.LearningProcess)
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].IsActive() {
+			active = append(active, all[i])
+			learnings[all[i].ID] = all[i]
+		}
+	}
+
+	for learningID := range overrides {
+		if _, ok := learnings[learningID]; !ok {
+			return nil, nil, fmt.Errorf("%w: learning %s is not an active learning of this mentor", domain.ErrInvalidInput, learningID)
+		}
+	}
+
+	candidates, err := s.mentorRepo.GetAll(ctx, domain.MentorFilter{ActiveOnly: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get mentors: %w", err)
+	}
+	byID := make(map[string]*domain.Mentor, len(candidates))
+	for _, candidate := range candidates {
+		byID[candidate.ID] = candidate
+	}
+	absent, err := absentMentors(ctx, s.availabilityRepo, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan := &HandoffPlan{MentorID: mentor.ID, MentorName: mentor.Name, Assignments: []HandoffAssignment{}}
+	chosen := make(map[string]*domain.Mentor)
+
+	for _, learning := range active {
+		targetID, ok := overrides[learning.ID]
+		if !ok {
+			continue
+		}
+		target, err := s.overrideTarget(ctx, mentorID, targetID, byID, absent)
+		if err != nil {
+			return nil, nil, err
+		}
+		target.Workload++
+		chosen[learning.ID] = target
+	}
+
+	for _, learning := range active {
+		if _, ok := chosen[learning.ID]; ok {
+			continue
+		}
+		var best *domain.Mentor
+		for _, candidate := range candidates {
+			if candidate.ID == mentorID || !candidate.CanTakeStudent() || absent[candidate.ID] {
+				continue
+			}
+			if best == nil || candidate.Workload < best.Workload {
+				best = candidate
+			}
+		}
+		if best != nil {
+			best.Workload++
+			chosen[learning.ID] = best
+		}
+	}
+
+	for _, learning := range active {
+		a := HandoffAssignment{
+			LearningID: learning.ID,
+			UserID:     learning.UserID,
+			UserName:   learning.UserName,
+			Topic:      learning.RequestTopic,
+		}
+		if target, ok := chosen[learning.ID]; ok {
+			a.MentorID = target.ID
+			a.MentorName = target.Name
+		}
+		plan.Assignments = append(plan.Assignments, a)
+	}
+
+	return plan, learnings, nil
+}
+
+// overrideTarget validates a mentor picked by an admin, counting the slots
+// already planned for them
+func (s *HandoffService) overrideTarget(ctx context.Context, mentorID, targetID string, active map[string]*domain.Mentor, absent map[string]bool) (*domain.Mentor, error) {
+	if targetID == mentorID {
+		return nil, fmt.Errorf("%w: cannot hand a learning over to the same mentor", domain.ErrInvalidInput)
+	}
+
+	target, ok := active[targetID]
+	if !ok {
+		// Not in the active list: tell a missing mentor from a deactivated
+		// one, keeping ErrMentorNotFound for the departing mentor
+		if _, err := s.mentorRepo.GetByID(ctx, targetID); errors.Is(err, domain.ErrMentorNotFound) {
+			return nil, fmt.Errorf("%w: replacement mentor %s not found", domain.ErrInvalidInput, targetID)
+		} else if err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrMentorDeactivated
+	}
+
+	if absent[targetID] {
+		return nil, fmt.Errorf("%w: %s", domain.ErrMentorAbsent, target.Name)
+	}
+	if !target.CanTakeStudent() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrMentorNotAvailable, target.Name)
+	}
+	return target, nil
+}
@@ -0,0 +1,302 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// handoffFixture is ann leading three active learnings and one completed
+// one, plus mentors with a few free slots between them and an idle mentor
+// who is on vacation
+type handoffFixture struct {
+	*env
+	ann, max, zoe, full, gone, away *domain.Mentor
+	learners                        []*domain.User
+	active                          []*domain.LearningProcess
+}
+
+func newHandoffFixture(t *testing.T) *handoffFixture {
+	t.Helper()
+
+	f := &handoffFixture{env: newEnv(t)}
+	f.ann = f.addMentor(t, "ann", 0)
+	f.max = f.addMentor(t, "max", 3)
+	f.zoe = f.addMentor(t, "zoe", 4)
+	f.full = f.addMentor(t, "full", 5)
+	f.gone = f.addMentor(t, "gone", 0)
+	f.deactivate(t, f.gone)
+	f.away = f.addMentor(t, "away", 0)
+	f.absent(t, f.away)
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		learner := f.addUser(t, name)
+		f.learners = append(f.learners, learner)
+		f.active = append(f.active, f.addLearning(t, learner.ID, f.ann, domain.LearningActive))
+	}
+	f.addLearning(t, f.learners[0].ID, f.ann, domain.LearningCompleted)
+	return f
+}
+
+func TestHandoffService_PlanHandoff(t *testing.T) {
+	f := newHandoffFixture(t)
+	ctx := defaultCtx()
+
+	plan, err := f.handoff.PlanHandoff(ctx, f.ann.ID)
+	if err != nil {
+		t.Fatalf("PlanHandoff: %v", err)
+	}
+
+	// Oldest learning first, each to the least loaded mentor with a free slot
+	want := []struct{ learning, mentor string }{
+		{f.active[0].ID, f.max.ID},
+		{f.active[1].ID, f.max.ID},
+		{f.active[2].ID, f.zoe.ID},
+	}
+	if len(plan.Assignments) != len(want) {
+		t.Fatalf("plan has %d assignments, want %d", len(plan.Assignments), len(want))
+	}
+	for i, w := range want {
+		a := plan.Assignments[i]
+		if a.LearningID != w.learning || a.MentorID != w.mentor {
+			t.Errorf("assignment %d = %s -> %s, want %s -> %s", i, a.LearningID, a.MentorName, w.learning, w.mentor)
+		}
+	}
+	if a := plan.Assignments[0]; a.UserName != "alice" || a.Topic != "Go" || a.MentorName != "max" {
+		t.Errorf("assignment details = %+v", a)
+	}
+
+	// Planning changes nothing
+	if got := f.workload(t, f.max.ID); got != 3 {
+		t.Errorf("max workload = %d after planning, want 3", got)
+	}
+	learning, _ := f.learnings.GetByID(ctx, f.active[0].ID)
+	if learning.MentorID != f.ann.ID {
+		t.Errorf("learning moved to %s while planning", learning.MentorID)
+	}
+
+	if _, err := f.handoff.PlanHandoff(ctx, "missing"); !errors.Is(err, domain.ErrMentorNotFound) {
+		t.Errorf("PlanHandoff(missing) error = %v, want ErrMentorNotFound", err)
+	}
+}
+
+func TestHandoffService_ExecuteHandoff(t *testing.T) {
+	f := newHandoffFixture(t)
+	ctx := defaultCtx()
+	if _, err := f.learning.UpdateNotes(ctx, f.active[0].ID, "week 1 done"); err != nil {
+		t.Fatalf("UpdateNotes: %v", err)
+	}
+
+	plan, err := f.handoff.ExecuteHandoff(ctx, f.ann.ID, service.HandoffOptions{
+		Note:       "Ann is on parental leave.",
+		Deactivate: true,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteHandoff: %v", err)
+	}
+	if len(plan.Assignments) != 3 || len(plan.Unassigned()) != 0 {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	for _, a := range plan.Assignments {
+		learning, err := f.learnings.GetByID(ctx, a.LearningID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if learning.MentorID != a.MentorID {
+			t.Errorf("learning %s mentor = %s, want %s", learning.ID, learning.MentorID, a.MentorID)
+		}
+		if learning.Notes == nil || !strings.Contains(*learning.Notes, "from ann to "+a.MentorName+". Ann is on parental leave.") {
+			t.Errorf("learning %s notes = %v", learning.ID, learning.Notes)
+		}
+	}
+	first, _ := f.learnings.GetByID(ctx, f.active[0].ID)
+	if !strings.HasPrefix(*first.Notes, "week 1 done\n\nHandoff on ") {
+		t.Errorf("existing notes not kept: %q", *first.Notes)
+	}
+
+	for mentor, want := range map[*domain.Mentor]int{f.ann: 0, f.max: 5, f.zoe: 5, f.full: 5} {
+		if got := f.workload(t, mentor.ID); got != want {
+			t.Errorf("%s workload = %d, want %d", mentor.Name, got, want)
+		}
+	}
+	ann, _ := f.mentors.GetByID(ctx, f.ann.ID)
+	if ann.IsActive() {
+		t.Error("ann not deactivated")
+	}
+
+	for _, learner := range f.learners {
+		notifications, _ := f.notification.GetUserNotifications(ctx, learner.ID, true)
+		if len(notifications) != 1 || notifications[0].Kind != domain.NotificationMentorChanged {
+			t.Errorf("%s got notifications %+v", learner.Name, notifications)
+		}
+	}
+}
+
+func TestHandoffService_ExecuteHandoffOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides func(f *handoffFixture) map[string]string
+		wantErr   error
+		wantMax   int // max workload afterwards
+	}{
+		{
+			name: "override fills the rest automatically",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{f.active[2].ID: f.max.ID}
+			},
+			wantMax: 5,
+		},
+		{
+			name: "unknown learning",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{"missing": f.max.ID}
+			},
+			wantErr: domain.ErrInvalidInput,
+			wantMax: 3,
+		},
+		{
+			name: "same mentor",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{f.active[0].ID: f.ann.ID}
+			},
+			wantErr: domain.ErrInvalidInput,
+			wantMax: 3,
+		},
+		{
+			name: "missing replacement",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{f.active[0].ID: "missing"}
+			},
+			wantErr: domain.ErrInvalidInput,
+			wantMax: 3,
+		},
+		{
+			name: "deactivated replacement",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{f.active[0].ID: f.gone.ID}
+			},
+			wantErr: domain.ErrMentorDeactivated,
+			wantMax: 3,
+		},
+		{
+			name: "absent replacement",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{f.active[0].ID: f.away.ID}
+			},
+			wantErr: domain.ErrMentorAbsent,
+			wantMax: 3,
+		},
+		{
+			name: "full replacement",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{f.active[0].ID: f.full.ID}
+			},
+			wantErr: domain.ErrMentorNotAvailable,
+			wantMax: 3,
+		},
+		{
+			name: "overrides exceed capacity",
+			overrides: func(f *handoffFixture) map[string]string {
+				return map[string]string{f.active[0].ID: f.zoe.ID, f.active[1].ID: f.zoe.ID}
+			},
+			wantErr: domain.ErrMentorNotAvailable,
+			wantMax: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHandoffFixture(t)
+			ctx := defaultCtx()
+
+			_, err := f.handoff.ExecuteHandoff(ctx, f.ann.ID, service.HandoffOptions{Overrides: tt.overrides(f)})
+			expectErr(t, err, tt.wantErr)
+			if got := f.workload(t, f.max.ID); got != tt.wantMax {
+				t.Errorf("max workload = %d, want %d", got, tt.wantMax)
+			}
+			if err != nil {
+				return
+			}
+
+			learning, _ := f.learnings.GetByID(ctx, f.active[2].ID)
+			if learning.MentorID != f.max.ID {
+				t.Errorf("override ignored: learning went to %s", learning.MentorName)
+			}
+		})
+	}
+}
+
+func TestHandoffService_ExecuteHandoffIsAtomic(t *testing.T) {
+	t.Run("not enough capacity", func(t *testing.T) {
+		f := newHandoffFixture(t)
+		ctx := defaultCtx()
+		if err := f.mentors.UpdateWorkload(ctx, f.max.ID, 5); err != nil {
+			t.Fatalf("fill max: %v", err)
+		}
+
+		plan, err := f.handoff.ExecuteHandoff(ctx, f.ann.ID, service.HandoffOptions{Deactivate: true})
+		expectErr(t, err, domain.ErrNoReplacement)
+		if plan == nil || len(plan.Unassigned()) != 2 {
+			t.Fatalf("plan = %+v, want 2 unassigned learnings", plan)
+		}
+		assertUntouched(t, f)
+	})
+
+	t.Run("failure halfway", func(t *testing.T) {
+		f := newHandoffFixture(t)
+		ctx := defaultCtx()
+		notifications := &failingNotifications{NotificationRepository: f.notifications, failAt: 2}
+		handoff := service.NewHandoffService(f.tx, f.mentors, f.learnings, f.availabilities, service.NewNotificationService(notifications), f.outbox)
+
+		_, err := handoff.ExecuteHandoff(ctx, f.ann.ID, service.HandoffOptions{Deactivate: true})
+		expectErr(t, err, errFailingNotifications)
+		assertUntouched(t, f)
+	})
+}
+
+// assertUntouched checks that a failed handoff left no trace
+func assertUntouched(t *testing.T, f *handoffFixture) {
+	t.Helper()
+	ctx := defaultCtx()
+
+	for _, active := range f.active {
+		learning, _ := f.learnings.GetByID(ctx, active.ID)
+		if learning.MentorID != f.ann.ID || learning.Notes != nil {
+			t.Errorf("learning %s changed: mentor %s notes %v", learning.ID, learning.MentorName, learning.Notes)
+		}
+	}
+	if got := f.workload(t, f.ann.ID); got != 3 {
+		t.Errorf("ann workload = %d, want 3", got)
+	}
+	ann, _ := f.mentors.GetByID(ctx, f.ann.ID)
+	if !ann.IsActive() {
+		t.Error("ann deactivated")
+	}
+	for _, learner := range f.learners {
+		if notifications, _ := f.notifications.GetByUserID(ctx, learner.ID, false); len(notifications) != 0 {
+			t.Errorf("%s notified", learner.Name)
+		}
+	}
+}
+
+var errFailingNotifications = errors.New("notification store unavailable")
+
+// failingNotifications fails the failAt-th Create call
+type failingNotifications struct {
+	domain.NotificationRepository
+	failAt int
+	calls  int
+}
+
+func (r *failingNotifications) Create(ctx context.Context, notification *domain.Notification) error {
+	r.calls++
+	if r.calls == r.failAt {
+		return errFailingNotifications
+	}
+	return r.NotificationRepository.Create(ctx, notification)
+}
@@ -0,0 +1,345 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/certificate"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/blob"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/memory"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// env wires every service to one in-memory store
+type env struct {
+	users          *memory.UserRepository
+	roles          *memory.RoleRepository
+	departments    *memory.DepartmentRepository
+	requests       *memory.RequestRepository
+	mentors        *memory.MentorRepository
+	learnings      *memory.LearningRepository
+	notifications  *memory.NotificationRepository
+	availabilities *memory.AvailabilityRepository
+	approvals      *memory.ApprovalRepository
+	comments       *memory.CommentRepository
+	attachments    *memory.AttachmentRepository
+	courses        *memory.CourseRepository
+	enrollments    *memory.EnrollmentRepository
+	skills         *memory.SkillRepository
+	competencies   *memory.CompetencyRepository
+	certificates   *memory.CertificateRepository
+	questionnaires *memory.QuestionnaireRepository
+	responses      *memory.FeedbackRepository
+	checkIns       *memory.CheckInRepository
+	jobs           *memory.JobRepository
+	outboxEvents   *memory.OutboxRepository
+	webhooks       *memory.WebhookRepository
+	tenants        *memory.TenantRepository
+	blobs          *blob.LocalStore
+	tx             *memory.TxManager
+
+	auth         *service.AuthService
+	user         *service.UserService
+	role         *service.RoleService
+	department   *service.DepartmentService
+	request      *service.RequestService
+	mentor       *service.MentorService
+	learning     *service.LearningService
+	notification *service.NotificationService
+	handoff      *service.HandoffService
+	availability *service.AvailabilityService
+	queue        *service.QueueService
+	approval     *service.ApprovalService
+	comment      *service.CommentService
+	attachment   *service.AttachmentService
+	course       *service.CourseService
+	competency   *service.CompetencyService
+	certificate  *service.CertificateService
+	feedback     *service.FeedbackService
+	checkIn      *service.CheckInService
+	job          *service.JobService
+	outbox       *service.OutboxService
+	webhook      *service.WebhookService
+	userImport   *service.UserImportService
+	scim         *service.SCIMService
+	tenant       *service.TenantService
+	mail         *mailbox
+}
+
+// newEnv builds an env where new requests wait for an admin
+func newEnv(t *testing.T) *env {
+	t.Helper()
+	return newEnvWithChain(t, domain.StageAdmin)
+}
+
+// newEnvWithChain builds an env with the given approval chain
+func newEnvWithChain(t *testing.T, chain ...domain.ApprovalStage) *env {
+	t.Helper()
+
+	store := memory.NewStore()
+	e := &env{
+		users:          memory.NewUserRepository(store),
+		roles:          memory.NewRoleRepository(store),
+		departments:    memory.NewDepartmentRepository(store),
+		requests:       memory.NewRequestRepository(store),
+		mentors:        memory.NewMentorRepository(store),
+		learnings:      memory.NewLearningRepository(store),
+		notifications:  memory.NewNotificationRepository(store),
+		availabilities: memory.NewAvailabilityRepository(store),
+		approvals:      memory.NewApprovalRepository(store),
+		comments:       memory.NewCommentRepository(store),
+		attachments:    memory.NewAttachmentRepository(store),
+		courses:        memory.NewCourseRepository(store),
+		enrollments:    memory.NewEnrollmentRepository(store),
+		skills:         memory.NewSkillRepository(store),
+		competencies:   memory.NewCompetencyRepository(store),
+		certificates:   memory.NewCertificateRepository(store),
+		questionnaires: memory.NewQuestionnaireRepository(store),
+		responses:      memory.NewFeedbackRepository(store),
+		checkIns:       memory.NewCheckInRepository(store),
+		jobs:           memory.NewJobRepository(store),
+		outboxEvents:   memory.NewOutboxRepository(store),
+		webhooks:       memory.NewWebhookRepository(store),
+		tenants:        memory.NewTenantRepository(store),
+		tx:             memory.NewTxManager(store),
+	}
+	e.auth = service.NewAuthService(e.users, e.departments, "test-secret", time.Hour)
+	e.user = service.NewUserService(e.users, e.roles, e.departments)
+	e.role = service.NewRoleService(e.tx, e.roles, e.users)
+	e.department = service.NewDepartmentService(e.tx, e.departments, e.users)
+	e.notification = service.NewNotificationService(e.notifications)
+	e.outbox = service.NewOutboxService(e.outboxEvents)
+	e.queue = service.NewQueueService(e.tx, e.requests, e.mentors, e.learnings, e.availabilities, e.notification, e.outbox)
+	e.approval = service.NewApprovalService(e.tx, e.requests, e.users, e.departments, e.approvals, e.enrollments, e.queue, e.notification, e.outbox, chain)
+	e.request = service.NewRequestService(e.tx, e.requests, e.users, e.mentors, e.learnings, e.availabilities, e.approval, e.outbox)
+	e.mentor = service.NewMentorService(e.mentors, e.learnings, e.availabilities, e.tenants, e.queue)
+	e.competency = service.NewCompetencyService(e.tx, e.skills, e.competencies, e.users, e.departments)
+	e.handoff = service.NewHandoffService(e.tx, e.mentors, e.learnings, e.availabilities, e.notification, e.outbox)
+	e.availability = service.NewAvailabilityService(e.availabilities, e.mentors, e.queue)
+	e.comment = service.NewCommentService(e.tx, e.comments, e.requests, e.learnings, e.mentors, e.users, e.notification)
+	e.course = service.NewCourseService(e.courses, e.enrollments, e.requests, e.users, e.approval, e.competency)
+	e.feedback = service.NewFeedbackService(e.tx, e.questionnaires, e.responses, e.learnings, e.mentors, e.users)
+	e.checkIn = service.NewCheckInService(e.tx, e.checkIns, e.learnings, e.mentors, e.users, e.notification, testCheckInInterval, testStalledAfter, true)
+	e.job = service.NewJobService(e.tx, e.jobs, e.tenants, "worker-1", testJobLease)
+	e.webhook = service.NewWebhookService(e.tx, e.outboxEvents, e.webhooks, &http.Client{Timeout: 5 * time.Second}, testWebhookAttempts)
+	e.mail = &mailbox{}
+	e.userImport = service.NewUserImportService(e.users, e.roles, e.departments, e.tenants, e.auth, e.job, e.mail, testInviteURL)
+	e.job.Register(service.JobUserInvite, e.userImport.RunInviteJob)
+	e.scim = service.NewSCIMService(e.tx, e.users, e.roles, e.departments, e.user)
+	e.tenant = service.NewTenantService(e.tx, e.tenants, e.roles, e.users, e.questionnaires)
+
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	e.blobs = blobs
+	e.attachment = service.NewAttachmentService(e.attachments, e.learnings, e.blobs, nil, e.comment, 1<<20, testAllowedTypes)
+
+	renderer, err := certificate.NewRenderer("", "https://learning.example.com")
+	if err != nil {
+		t.Fatalf("certificate renderer: %v", err)
+	}
+	e.certificate = service.NewCertificateService(e.certificates, e.learnings, e.users, e.blobs, renderer)
+	e.learning = service.NewLearningService(e.tx, e.learnings, e.mentors, e.users, e.requests, e.availabilities, e.queue, e.approval, e.competency, e.outbox, e.certificate)
+	return e
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// addUser stores an employee with a placeholder password hash
+func (e *env) addUser(t *testing.T, name string) *domain.User {
+	t.Helper()
+
+	user := &domain.User{
+		Name:         name,
+		Email:        name + "@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleEmployee,
+	}
+	if err := e.users.Create(defaultCtx(), user); err != nil {
+		t.Fatalf("add user: %v", err)
+	}
+	return user
+}
+
+// addDepartment stores a department below parent, or at the top when it is
+// nil
+func (e *env) addDepartment(t *testing.T, name string, parent *domain.Department) *domain.Department {
+	t.Helper()
+
+	var parentID *string
+	if parent != nil {
+		parentID = &parent.ID
+	}
+	department, err := e.department.CreateDepartment(defaultCtx(), name, parentID, nil)
+	if err != nil {
+		t.Fatalf("add department: %v", err)
+	}
+	return department
+}
+
+// addMentor stores a mentor with the given workload
+func (e *env) addMentor(t *testing.T, name string, workload int) *domain.Mentor {
+	t.Helper()
+
+	mentor := &domain.Mentor{
+		Name:     name,
+		JobTitle: "Senior Engineer",
+		Workload: workload,
+		Capacity: domain.DefaultMentorCapacity,
+		Email:    name + "@mentors.example.com",
+	}
+	if err := e.mentors.Create(defaultCtx(), mentor); err != nil {
+		t.Fatalf("add mentor: %v", err)
+	}
+	return mentor
+}
+
+// addRequest stores a request in the given status
+func (e *env) addRequest(t *testing.T, userID string, status domain.RequestStatus) *domain.TrainingRequest {
+	t.Helper()
+
+	request := &domain.TrainingRequest{
+		UserID:      userID,
+		Topic:       "Go",
+		Description: "Concurrency patterns",
+		Status:      status,
+	}
+	if err := e.requests.Create(defaultCtx(), request); err != nil {
+		t.Fatalf("add request: %v", err)
+	}
+	return request
+}
+
+// addLearning stores a learning in the given status and takes a mentor slot
+// for active ones, as the services do
+func (e *env) addLearning(t *testing.T, userID string, mentor *domain.Mentor, status domain.LearningStatus) *domain.LearningProcess {
+	t.Helper()
+	ctx := defaultCtx()
+
+	request := e.addRequest(t, userID, domain.RequestApproved)
+	learning := &domain.LearningProcess{
+		RequestID: request.ID,
+		UserID:    userID,
+		MentorID:  mentor.ID,
+		Status:    status,
+		StartDate: time.Now(),
+		Plan:      []domain.LearningPlanItem{},
+	}
+	if err := e.learnings.Create(ctx, learning); err != nil {
+		t.Fatalf("add learning: %v", err)
+	}
+	if status == domain.LearningActive {
+		if err := e.mentors.UpdateWorkload(ctx, mentor.ID, mentor.Workload+1); err != nil {
+			t.Fatalf("take mentor slot: %v", err)
+		}
+		mentor.Workload++
+	}
+	return learning
+}
+
+// deactivate marks a stored mentor as deactivated
+func (e *env) deactivate(t *testing.T, mentor *domain.Mentor) {
+	t.Helper()
+
+	ctx := defaultCtx()
+	now := time.Now()
+	if err := e.mentors.UpdateDeactivatedAt(ctx, mentor.ID, &now); err != nil {
+		t.Fatalf("deactivate mentor: %v", err)
+	}
+	stored, err := e.mentors.GetByID(ctx, mentor.ID)
+	if err != nil {
+		t.Fatalf("get mentor: %v", err)
+	}
+	mentor.DeactivatedAt = stored.DeactivatedAt
+}
+
+// absent records a vacation of the mentor covering the current day
+func (e *env) absent(t *testing.T, mentor *domain.Mentor) *domain.Absence {
+	t.Helper()
+
+	absence := &domain.Absence{
+		MentorID: mentor.ID,
+		Kind:     domain.AbsenceVacation,
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(24 * time.Hour),
+	}
+	if err := e.availabilities.CreateAbsence(defaultCtx(), absence); err != nil {
+		t.Fatalf("add absence: %v", err)
+	}
+	return absence
+}
+
+// workload reads the stored workload of a mentor
+func (e *env) workload(t *testing.T, mentorID string) int {
+	t.Helper()
+
+	mentor, err := e.mentors.GetByID(defaultCtx(), mentorID)
+	if err != nil {
+		t.Fatalf("get mentor: %v", err)
+	}
+	return mentor.Workload
+}
+
+// countEvents counts the undispatched outbox events of a type
+func (e *env) countEvents(t *testing.T, eventType domain.EventType) int {
+	t.Helper()
+
+	events, err := e.outboxEvents.ListUndispatched(defaultCtx(), 1000)
+	if err != nil {
+		t.Fatalf("list outbox events: %v", err)
+	}
+	count := 0
+	for _, event := range events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+// race runs fn n times at once and returns the errors
+func race(n int, fn func() error) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn()
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// expectErr fails unless err matches want; a nil want expects success and a
+// non-nil want that is not a sentinel only expects some error
+func expectErr(t *testing.T, err, want error) {
+	t.Helper()
+
+	switch {
+	case want == nil && err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case want != nil && err == nil:
+		t.Fatalf("expected error %v, got nil", want)
+	case want != nil && want != errAny && !errors.Is(err, want):
+		t.Fatalf("error = %v, want %v", err, want)
+	}
+}
+
+// errAny matches any non-nil error
+var errAny = errors.New("any error")
+
+// defaultCtx is a context in the default tenant, where the fixtures of the
+// env live
+func defaultCtx() context.Context {
+	return domain.WithTenant(context.Background(), domain.DefaultTenantID)
+}
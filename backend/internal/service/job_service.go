@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/cron"
+)
+
+// Built-in job kinds
+const (
+	JobCleanup       = "jobs.cleanup"
+	JobCheckIns      = "check_ins.run"
+	JobWebhooks      = "webhooks.dispatch"
+	JobOutboxCleanup = "outbox.cleanup"
+	JobUserInvite    = "users.invite"
+	JobHRISSync      = "users.hris_sync"
+)
+
+// JobHandler runs one attempt of a job. An error schedules a retry with
+// backoff until the job runs out of attempts. Jobs run at least once, so
+// handlers must be safe to repeat.
+type JobHandler func(ctx context.Context, job *domain.Job) error
+
+type jobSchedule struct {
+	name     string
+	schedule cron.Schedule
+	kind     string
+	payload  json.RawMessage
+}
+
+// JobService runs deferred and recurring background work on a queue shared
+// by every app instance. Handlers and schedules are registered at startup,
+// before Run. Jobs run in the tenant that enqueued them; a schedule enqueues
+// one job in every tenant, so each tenant retries and sees only its own run.
+type JobService struct {
+	tx         domain.TxManager
+	jobRepo    domain.JobRepository
+	tenantRepo domain.TenantRepository
+	workerID   string        // identifies this instance in job locks
+	lease      time.Duration // how long an attempt may run before others take over
+
+	mu        sync.RWMutex
+	handlers  map[string]JobHandler
+	schedules []jobSchedule
+}
+
+func NewJobService(
+	tx domain.TxManager,
+	jobRepo domain.JobRepository,
+	tenantRepo domain.TenantRepository,
+	workerID string,
+	lease time.Duration,
+) *JobService {
+	return &JobService{
+		tx:         tx,
+		jobRepo:    jobRepo,
+		tenantRepo: tenantRepo,
+		workerID:   workerID,
+		lease:      lease,
+		handlers:   make(map[string]JobHandler),
+	}
+}
+
+// Register sets the handler of a job kind; this instance only claims jobs of
+// registered kinds
+func (s *JobService) Register(kind string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+}
+
+// Schedule enqueues a job of the kind in every tenant at every fire time of
+// the cron spec. Whichever instance reaches a fire time first enqueues the
+// jobs; a tenant's run is skipped while its previous one is still pending or
+// running.
+func (s *JobService) Schedule(name, spec, kind string, payload json.RawMessage) error {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("%w: cron spec %q never fires", domain.ErrInvalidInput, spec)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.handlers[kind]; !ok {
+		return fmt.Errorf("%w: no handler for job kind %q", domain.ErrInvalidInput, kind)
+	}
+	for _, existing := range s.schedules {
+		if existing.name == name {
+			return fmt.Errorf("%w: schedule %q is already registered", domain.ErrInvalidInput, name)
+		}
+	}
+	s.schedules = append(s.schedules, jobSchedule{name: name, schedule: schedule, kind: kind, payload: payload})
+	return nil
+}
+
+// RegisterCleanup deletes finished jobs older than retention on the cron spec
+func (s *JobService) RegisterCleanup(spec string, retention time.Duration) error {
+	s.Register(JobCleanup, func(ctx context.Context, job *domain.Job) error {
+		deleted, err := s.jobRepo.DeleteFinished(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "deleted finished jobs", "count", deleted)
+		return nil
+	})
+	return s.Schedule(JobCleanup, spec, JobCleanup, nil)
+}
+
+// Enqueue adds a job of a registered kind. RunAt defaults to now and
+// MaxAttempts to DefaultJobMaxAttempts. It fails with ErrJobExists when a
+// job with the same unique key is pending or running.
+func (s *JobService) Enqueue(ctx context.Context, job *domain.Job) (*domain.Job, error) {
+	if !s.handles(job.Kind) {
+		return nil, fmt.Errorf("%w: no handler for job kind %q", domain.ErrInvalidInput, job.Kind)
+	}
+	if job.Payload != nil && !json.Valid(job.Payload) {
+		return nil, fmt.Errorf("%w: job payload is not valid JSON", domain.ErrInvalidInput)
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = domain.DefaultJobMaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+
+	if err := s.jobRepo.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Run polls for due schedules and jobs every period until ctx is cancelled,
+// running up to concurrency jobs at a time. Schedules fire on their own
+// ticker, so a long batch of jobs does not hold them up.
+func (s *JobService) Run(ctx context.Context, every time.Duration, concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var schedules sync.WaitGroup
+	schedules.Add(1)
+	go func() {
+		defer schedules.Done()
+		s.runSchedules(ctx, every)
+	}()
+	defer schedules.Wait()
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var wg sync.WaitGroup
+			for i := 0; i < concurrency; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for ctx.Err() == nil {
+						ran, err := s.runNext(ctx, time.Now())
+						if err != nil {
+							slog.ErrorContext(ctx, "failed to run job", "error", err)
+						}
+						if !ran || err != nil {
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		}
+	}
+}
+
+// runSchedules enqueues the jobs of due schedules every period until ctx is
+// cancelled
+func (s *JobService) runSchedules(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.fireSchedules(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "failed to enqueue scheduled jobs", "error", err)
+			}
+		}
+	}
+}
+
+// RunDue enqueues the scheduled jobs due at now, then runs every job due at
+// now one by one
+func (s *JobService) RunDue(ctx context.Context, now time.Time) error {
+	if err := s.fireSchedules(ctx, now); err != nil {
+		return err
+	}
+	for {
+		ran, err := s.runNext(ctx, now)
+		if err != nil || !ran {
+			return err
+		}
+	}
+}
+
+// fireSchedules enqueues a job in every tenant for each schedule whose fire
+// time has come
+func (s *JobService) fireSchedules(ctx context.Context, now time.Time) error {
+	s.mu.RLock()
+	schedules := s.schedules
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, sched := range schedules {
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			claimed, err := s.jobRepo.ClaimSchedule(ctx, sched.name, now, sched.schedule.Next(now))
+			if err != nil || !claimed {
+				return err
+			}
+			tenants, err := s.tenantRepo.GetAll(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, tenant := range tenants {
+				err := s.jobRepo.Enqueue(domain.WithTenant(ctx, tenant.ID), &domain.Job{
+					Kind:        sched.kind,
+					Payload:     sched.payload,
+					UniqueKey:   "schedule:" + sched.name,
+					MaxAttempts: domain.DefaultJobMaxAttempts,
+					RunAt:       now,
+				})
+				// The tenant is still busy with the previous run; skip this one
+				if errors.Is(err, domain.ErrJobExists) {
+					slog.WarnContext(ctx, "skipped scheduled job, previous run not finished",
+						"schedule", sched.name, "tenant", tenant.Slug)
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("tenant %s: %w", tenant.Slug, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("schedule %s: %w", sched.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runNext claims and runs one due job; it reports false when none was due
+func (s *JobService) runNext(ctx context.Context, now time.Time) (bool, error) {
+	job, err := s.jobRepo.Claim(ctx, s.workerID, s.kinds(), now, s.lease)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	started := time.Now()
+	runErr := s.runHandler(ctx, job)
+	finished := now.Add(time.Since(started))
+
+	// Record the outcome even if the app is shutting down, so the job does
+	// not wait for its lease to expire
+	ctx = context.WithoutCancel(ctx)
+	if runErr == nil {
+		return true, s.jobRepo.Complete(ctx, job.ID, s.workerID, finished)
+	}
+
+	message := runErr.Error()
+	if len(message) > domain.MaxJobErrorLength {
+		message = strings.ToValidUTF8(message[:domain.MaxJobErrorLength], "")
+	}
+	var retryAt *time.Time
+	if job.Attempts < job.MaxAttempts {
+		at := finished.Add(domain.JobBackoff(job.Attempts))
+		retryAt = &at
+		slog.WarnContext(ctx, "job attempt failed, will retry",
+			"jobID", job.ID, "kind", job.Kind, "attempt", job.Attempts, "retryAt", at, "error", runErr)
+	} else {
+		slog.ErrorContext(ctx, "job failed",
+			"jobID", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", runErr)
+	}
+	return true, s.jobRepo.Fail(ctx, job.ID, s.workerID, message, finished, retryAt)
+}
+
+// runHandler runs the handler in the tenant of the job within the lease,
+// turning panics into errors
+func (s *JobService) runHandler(ctx context.Context, job *domain.Job) (err error) {
+	s.mu.RLock()
+	handler := s.handlers[job.Kind]
+	s.mu.RUnlock()
+
+	ctx = domain.WithTenant(ctx, job.TenantID)
+	ctx, cancel := context.WithTimeout(ctx, s.lease)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// kinds lists the registered job kinds
+func (s *JobService) kinds() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	kinds := make([]string, 0, len(s.handlers))
+	for kind := range s.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func (s *JobService) handles(kind string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.handlers[kind]
+	return ok
+}
+
+// CheckLastRun reports an error when the latest job of the kind in any
+// tenant ran out of attempts, so readiness shows integrations whose jobs
+// keep failing. Probes run often, so tenants are only looked up to name
+// the failing ones.
+func (s *JobService) CheckLastRun(ctx context.Context, kind string) error {
+	jobs, err := s.jobRepo.LatestByKind(ctx, kind)
+	if err != nil {
+		return err
+	}
+
+	var failed []*domain.Job
+	for _, job := range jobs {
+		if job.Status == domain.JobFailed {
+			failed = append(failed, job)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	tenants, err := s.tenantRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	slugs := make(map[string]string, len(tenants))
+	for _, tenant := range tenants {
+		slugs[tenant.ID] = tenant.Slug
+	}
+
+	errs := make([]error, 0, len(failed))
+	for _, job := range failed {
+		errs = append(errs, fmt.Errorf("tenant %s: last %s job failed: %s", slugs[job.TenantID], kind, job.LastError))
+	}
+	return errors.Join(errs...)
+}
+
+// ListJobs lists jobs for admins, newest first
+func (s *JobService) ListJobs(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown job status %q", domain.ErrInvalidInput, filter.Status)
+	}
+	if filter.Limit <= 0 || filter.Limit > domain.DefaultJobListLimit {
+		filter.Limit = domain.DefaultJobListLimit
+	}
+	return s.jobRepo.List(ctx, filter)
+}
+
+// GetJob returns a job by ID
+func (s *JobService) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	return s.jobRepo.GetByID(ctx, id)
+}
+
+// RetryJob runs a failed or cancelled job again as soon as possible, with
+// all its attempts
+func (s *JobService) RetryJob(ctx context.Context, id string) (*domain.Job, error) {
+	if err := s.jobRepo.Retry(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.jobRepo.GetByID(ctx, id)
+}
+
+// CancelJob stops a pending job from running. Running jobs cannot be
+// cancelled: their handler is already under way on some instance.
+func (s *JobService) CancelJob(ctx context.Context, id string) (*domain.Job, error) {
+	if err := s.jobRepo.Cancel(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.jobRepo.GetByID(ctx, id)
+}
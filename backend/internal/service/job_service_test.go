@@ -0,0 +1,371 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+const testJobLease = time.Minute
+
+// recorder is a job handler that fails the first failures calls
+type recorder struct {
+	calls    int
+	failures int
+	payloads []string
+}
+
+func (r *recorder) handle(ctx context.Context, job *domain.Job) error {
+	r.calls++
+	r.payloads = append(r.payloads, string(job.Payload))
+	if r.calls <= r.failures {
+		return errors.New("mail server down")
+	}
+	return nil
+}
+
+func TestJobService_RunsEnqueuedJobs(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	mail := &recorder{}
+	e.job.Register("email", mail.handle)
+	now := time.Now()
+
+	_, err := e.job.Enqueue(ctx, &domain.Job{Kind: "unknown"})
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.job.Enqueue(ctx, &domain.Job{Kind: "email", Payload: json.RawMessage("{")})
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	job, err := e.job.Enqueue(ctx, &domain.Job{Kind: "email", Payload: json.RawMessage(`{"to":"alice"}`), UniqueKey: "welcome:alice"})
+	expectErr(t, err, nil)
+	if job.MaxAttempts != domain.DefaultJobMaxAttempts || job.Status != domain.JobPending {
+		t.Errorf("enqueued job = %+v", job)
+	}
+	_, err = e.job.Enqueue(ctx, &domain.Job{Kind: "email", UniqueKey: "welcome:alice"})
+	expectErr(t, err, domain.ErrJobExists)
+	later, err := e.job.Enqueue(ctx, &domain.Job{Kind: "email", RunAt: now.Add(time.Hour)})
+	expectErr(t, err, nil)
+
+	expectErr(t, e.job.RunDue(ctx, now.Add(time.Second)), nil)
+	if mail.calls != 1 || mail.payloads[0] != `{"to":"alice"}` {
+		t.Fatalf("handler calls = %d %v, want the due job once", mail.calls, mail.payloads)
+	}
+	done, err := e.job.GetJob(ctx, job.ID)
+	expectErr(t, err, nil)
+	if done.Status != domain.JobSucceeded || done.Attempts != 1 || done.FinishedAt == nil {
+		t.Errorf("finished job = %+v", done)
+	}
+
+	expectErr(t, e.job.RunDue(ctx, now.Add(time.Hour)), nil)
+	if got, _ := e.job.GetJob(ctx, later.ID); mail.calls != 2 || got.Status != domain.JobSucceeded {
+		t.Errorf("delayed job = %+v after %d calls", got, mail.calls)
+	}
+}
+
+func TestJobService_RetriesWithBackoff(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	mail := &recorder{failures: 10}
+	e.job.Register("email", mail.handle)
+	// The repositories keep microseconds, as Postgres does
+	now := time.Now().Truncate(time.Microsecond)
+
+	job, err := e.job.Enqueue(ctx, &domain.Job{Kind: "email", MaxAttempts: 3, RunAt: now})
+	expectErr(t, err, nil)
+
+	expectErr(t, e.job.RunDue(ctx, now), nil)
+	got, _ := e.job.GetJob(ctx, job.ID)
+	if got.Status != domain.JobPending || got.Attempts != 1 || got.LastError != "mail server down" {
+		t.Fatalf("job after a failure = %+v", got)
+	}
+	if wait := got.RunAt.Sub(now); wait < domain.JobBackoff(1) || wait > domain.JobBackoff(1)+time.Second {
+		t.Errorf("retry in %v, want %v", wait, domain.JobBackoff(1))
+	}
+
+	// Not due again until the backoff is over
+	expectErr(t, e.job.RunDue(ctx, now.Add(10*time.Second)), nil)
+	if mail.calls != 1 {
+		t.Fatalf("handler ran %d times during the backoff", mail.calls)
+	}
+
+	expectErr(t, e.job.RunDue(ctx, now.Add(time.Minute)), nil)
+	expectErr(t, e.job.RunDue(ctx, now.Add(time.Hour)), nil)
+	got, _ = e.job.GetJob(ctx, job.ID)
+	if mail.calls != 3 || got.Status != domain.JobFailed || got.Attempts != 3 {
+		t.Fatalf("job after running out of attempts = %+v, %d calls", got, mail.calls)
+	}
+	expectErr(t, e.job.RunDue(ctx, now.Add(24*time.Hour)), nil)
+	if mail.calls != 3 {
+		t.Errorf("failed job ran again on its own")
+	}
+
+	// An admin retry gets the job all its attempts back
+	mail.failures = 0
+	retried, err := e.job.RetryJob(ctx, job.ID)
+	expectErr(t, err, nil)
+	if retried.Status != domain.JobPending || retried.Attempts != 0 {
+		t.Errorf("retried job = %+v", retried)
+	}
+	expectErr(t, e.job.RunDue(ctx, time.Now().Add(time.Second)), nil)
+	if got, _ := e.job.GetJob(ctx, job.ID); got.Status != domain.JobSucceeded {
+		t.Errorf("job after the retry = %+v", got)
+	}
+	_, err = e.job.RetryJob(ctx, job.ID)
+	expectErr(t, err, domain.ErrJobNotRetryable)
+}
+
+func TestJobService_RecoversPanics(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	e.job.Register("boom", func(context.Context, *domain.Job) error { panic("nil map") })
+
+	job, err := e.job.Enqueue(ctx, &domain.Job{Kind: "boom", MaxAttempts: 1})
+	expectErr(t, err, nil)
+	expectErr(t, e.job.RunDue(ctx, time.Now()), nil)
+
+	got, _ := e.job.GetJob(ctx, job.ID)
+	if got.Status != domain.JobFailed || got.LastError != "panic: nil map" {
+		t.Errorf("job after a panic = %+v", got)
+	}
+}
+
+func TestJobService_Cancel(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	mail := &recorder{}
+	e.job.Register("email", mail.handle)
+
+	job, err := e.job.Enqueue(ctx, &domain.Job{Kind: "email"})
+	expectErr(t, err, nil)
+	cancelled, err := e.job.CancelJob(ctx, job.ID)
+	expectErr(t, err, nil)
+	if cancelled.Status != domain.JobCancelled {
+		t.Errorf("cancelled job = %+v", cancelled)
+	}
+	expectErr(t, e.job.RunDue(ctx, time.Now()), nil)
+	if mail.calls != 0 {
+		t.Errorf("cancelled job ran")
+	}
+	_, err = e.job.CancelJob(ctx, job.ID)
+	expectErr(t, err, domain.ErrJobNotCancellable)
+	_, err = e.job.CancelJob(ctx, "missing")
+	expectErr(t, err, domain.ErrJobNotFound)
+
+	_, err = e.job.ListJobs(ctx, domain.JobFilter{Status: "done"})
+	expectErr(t, err, domain.ErrInvalidInput)
+	jobs, err := e.job.ListJobs(ctx, domain.JobFilter{Status: domain.JobCancelled})
+	expectErr(t, err, nil)
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("cancelled jobs = %+v", jobs)
+	}
+}
+
+func TestJobService_Schedules(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	digest := &recorder{}
+	e.job.Register("digest", digest.handle)
+
+	expectErr(t, e.job.Schedule("digest", "0 9 * * *", "unknown", nil), domain.ErrInvalidInput)
+	expectErr(t, e.job.Schedule("digest", "0 25 * * *", "digest", nil), domain.ErrInvalidInput)
+	expectErr(t, e.job.Schedule("digest", "0 9 * * *", "digest", json.RawMessage(`{"period":"day"}`)), nil)
+	expectErr(t, e.job.Schedule("digest", "0 9 * * *", "digest", nil), domain.ErrInvalidInput)
+
+	// A second instance shares the queue and the schedule
+	other := service.NewJobService(e.tx, e.jobs, e.tenants, "worker-2", testJobLease)
+	other.Register("digest", digest.handle)
+	expectErr(t, other.Schedule("digest", "0 9 * * *", "digest", json.RawMessage(`{"period":"day"}`)), nil)
+
+	day := time.Date(2030, 3, 4, 8, 0, 0, 0, time.Local)
+	expectErr(t, e.job.RunDue(ctx, day), nil)
+	expectErr(t, e.job.RunDue(ctx, day.Add(30*time.Minute)), nil)
+	if digest.calls != 0 {
+		t.Fatalf("digest ran %d times before 9:00", digest.calls)
+	}
+
+	for _, s := range []*service.JobService{e.job, other} {
+		expectErr(t, s.RunDue(ctx, day.Add(time.Hour)), nil)
+	}
+	if digest.calls != 1 || digest.payloads[0] != `{"period":"day"}` {
+		t.Fatalf("digest ran %d times at 9:00 on two instances, want once", digest.calls)
+	}
+
+	expectErr(t, other.RunDue(ctx, day.Add(25*time.Hour)), nil)
+	if digest.calls != 2 {
+		t.Errorf("digest ran %d times by the next day, want 2", digest.calls)
+	}
+}
+
+func TestJobService_SkipsOverlappingScheduledRuns(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	slow := &recorder{failures: 1}
+	e.job.Register("report", slow.handle)
+	expectErr(t, e.job.Schedule("report", "@every 1m", "report", nil), nil)
+
+	start := time.Date(2030, 3, 4, 8, 0, 30, 0, time.Local)
+	expectErr(t, e.job.RunDue(ctx, start), nil)
+	expectErr(t, e.job.RunDue(ctx, start.Add(time.Minute)), nil)
+	if slow.calls != 1 {
+		t.Fatalf("report ran %d times, want 1", slow.calls)
+	}
+
+	// The failed run waits for its retry; the next fire time is skipped
+	// rather than queued behind it
+	expectErr(t, e.job.RunDue(ctx, start.Add(2*time.Minute)), nil)
+	jobs, err := e.job.ListJobs(ctx, domain.JobFilter{Kind: "report"})
+	expectErr(t, err, nil)
+	if len(jobs) != 1 || jobs[0].Attempts != 2 || jobs[0].Status != domain.JobSucceeded {
+		t.Errorf("report jobs = %+v, want one retried run", jobs)
+	}
+}
+
+func TestJobService_RunFiresSchedulesWhileJobsRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(defaultCtx())
+	e := newEnv(t)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	e.job.Register("export", func(ctx context.Context, job *domain.Job) error {
+		close(started)
+		<-release
+		return nil
+	})
+	e.job.Register("report", (&recorder{}).handle)
+	expectErr(t, e.job.Schedule("report", "@every 1m", "report", nil), nil)
+
+	// The schedule comes due once a long job is running
+	_, err := e.jobs.ClaimSchedule(ctx, "report", time.Now(), time.Now().Add(200*time.Millisecond))
+	expectErr(t, err, nil)
+	_, err = e.job.Enqueue(ctx, &domain.Job{Kind: "export"})
+	expectErr(t, err, nil)
+
+	done := make(chan struct{})
+	go func() {
+		e.job.Run(ctx, 10*time.Millisecond, 1)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	defer close(release)
+
+	<-started
+	deadline := time.After(5 * time.Second)
+	for {
+		jobs, err := e.job.ListJobs(ctx, domain.JobFilter{Kind: "report"})
+		expectErr(t, err, nil)
+		if len(jobs) == 1 {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("schedule did not fire while the export job was running")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestJobService_CleanupDeletesOldJobs(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	mail := &recorder{}
+	e.job.Register("email", mail.handle)
+	expectErr(t, e.job.RegisterCleanup("@every 1h", 24*time.Hour), nil)
+
+	now := time.Now()
+	old, err := e.job.Enqueue(ctx, &domain.Job{Kind: "email", RunAt: now.Add(-72 * time.Hour)})
+	expectErr(t, err, nil)
+	recent, err := e.job.Enqueue(ctx, &domain.Job{Kind: "email", RunAt: now})
+	expectErr(t, err, nil)
+	expectErr(t, e.job.RunDue(ctx, now.Add(-72*time.Hour)), nil)
+
+	expectErr(t, e.job.RunDue(ctx, now.Add(time.Hour)), nil)
+	_, err = e.job.GetJob(ctx, old.ID)
+	expectErr(t, err, domain.ErrJobNotFound)
+	if got, err := e.job.GetJob(ctx, recent.ID); err != nil || got.Status != domain.JobSucceeded {
+		t.Errorf("recent job = %+v, %v, want it kept", got, err)
+	}
+}
+
+func TestJobService_SchedulesEveryTenant(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	acme, acmeCtx := e.addTenant(t, "acme")
+
+	var seen []string
+	e.job.Register("report", func(ctx context.Context, job *domain.Job) error {
+		seen = append(seen, domain.TenantID(ctx))
+		if job.TenantID == acme.ID {
+			return errors.New("acme export down")
+		}
+		return nil
+	})
+	expectErr(t, e.job.Schedule("report", "0 9 * * *", "report", nil), nil)
+
+	day := time.Date(2030, 3, 4, 8, 0, 0, 0, time.Local)
+	expectErr(t, e.job.RunDue(ctx, day), nil)
+	expectErr(t, e.job.RunDue(ctx, day.Add(time.Hour)), nil)
+	slices.Sort(seen)
+	if want := []string{domain.DefaultTenantID, acme.ID}; !slices.Equal(seen, want) {
+		t.Errorf("report ran in %v, want %v", seen, want)
+	}
+
+	// Each tenant sees and retries only its own run
+	jobs, err := e.job.ListJobs(ctx, domain.JobFilter{Kind: "report"})
+	expectErr(t, err, nil)
+	if len(jobs) != 1 || jobs[0].Status != domain.JobSucceeded {
+		t.Errorf("default tenant jobs = %+v, want its succeeded run", jobs)
+	}
+	jobs, err = e.job.ListJobs(acmeCtx, domain.JobFilter{Kind: "report"})
+	expectErr(t, err, nil)
+	if len(jobs) != 1 || jobs[0].Status != domain.JobPending || jobs[0].LastError != "acme export down" {
+		t.Fatalf("acme jobs = %+v, want its failed run pending a retry", jobs)
+	}
+
+	seen = nil
+	expectErr(t, e.job.RunDue(ctx, day.Add(time.Hour+domain.JobBackoff(1)+time.Second)), nil)
+	if !slices.Equal(seen, []string{acme.ID}) {
+		t.Errorf("retry ran in %v, want only acme", seen)
+	}
+}
+
+func TestJobService_CheckLastRun(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	sync := &recorder{failures: 1}
+	e.job.Register("sync", sync.handle)
+
+	// Nothing ran yet
+	expectErr(t, e.job.CheckLastRun(ctx, "sync"), nil)
+
+	_, err := e.job.Enqueue(ctx, &domain.Job{Kind: "sync", MaxAttempts: 1})
+	expectErr(t, err, nil)
+	expectErr(t, e.job.RunDue(ctx, time.Now()), nil)
+	expectErr(t, e.job.CheckLastRun(ctx, "sync"), errAny)
+	expectErr(t, e.job.CheckLastRun(ctx, "other"), nil)
+
+	// A later run that succeeds clears the failure
+	_, err = e.job.Enqueue(ctx, &domain.Job{Kind: "sync"})
+	expectErr(t, err, nil)
+	expectErr(t, e.job.RunDue(ctx, time.Now().Add(time.Second)), nil)
+	expectErr(t, e.job.CheckLastRun(ctx, "sync"), nil)
+
+	// Failures of other tenants count too, whatever the probe's tenant
+	sync.failures = sync.calls + 1
+	_, acme := e.addTenant(t, "acme")
+	_, err = e.job.Enqueue(acme, &domain.Job{Kind: "sync", MaxAttempts: 1})
+	expectErr(t, err, nil)
+	expectErr(t, e.job.RunDue(ctx, time.Now().Add(2*time.Second)), nil)
+	err = e.job.CheckLastRun(ctx, "sync")
+	if err == nil || !strings.Contains(err.Error(), "tenant acme") {
+		t.Errorf("CheckLastRun = %v, want the failure of acme", err)
+	}
+}
@@ -3,26 +3,51 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 )
 
 type LearningService struct {
-	learningRepo domain.LearningRepository
-	mentorRepo   domain.MentorRepository
-	requestRepo  domain.RequestRepository
+	tx               domain.TxManager
+	learningRepo     domain.LearningRepository
+	mentorRepo       domain.MentorRepository
+	userRepo         domain.UserRepository
+	requestRepo      domain.RequestRepository
+	availabilityRepo domain.AvailabilityRepository
+	queue            *QueueService
+	approvals        *ApprovalService
+	competencies     *CompetencyService
+	outbox           *OutboxService
+	certificates     *CertificateService // nil leaves certificates to the first download
 }
 
 func NewLearningService(
+	tx domain.TxManager,
 	learningRepo domain.LearningRepository,
 	mentorRepo domain.MentorRepository,
+	userRepo domain.UserRepository,
 	requestRepo domain.RequestRepository,
+	availabilityRepo domain.AvailabilityRepository,
+	queue *QueueService,
+	approvals *ApprovalService,
+	competencies *CompetencyService,
+	outbox *OutboxService,
+	certificates *CertificateService,
 ) *LearningService {
 	return &LearningService{
-		learningRepo: learningRepo,
-		mentorRepo:   mentorRepo,
-		requestRepo:  requestRepo,
+		tx:               tx,
+		learningRepo:     learningRepo,
+		mentorRepo:       mentorRepo,
+		userRepo:         userRepo,
+		requestRepo:      requestRepo,
+		availabilityRepo: availabilityRepo,
+		queue:            queue,
+		approvals:        approvals,
+		competencies:     competencies,
+		outbox:           outbox,
+		certificates:     certificates,
 	}
 }
 
@@ -41,47 +66,91 @@ func (s *LearningService) GetLearningByID(ctx context.Context, id string) (*doma
 	return s.learningRepo.GetByID(ctx, id)
 }
 
-// CreateLearningFromRequest creates a learning process from topic and description
-func (s *LearningService) CreateLearningFromRequest(ctx context.Context, userID, topic, description string) (*domain.LearningProcess, error) {
-	// First, create a training request
-	request := &domain.TrainingRequest{
-		UserID:      userID,
-		Topic:       topic,
-		Description: description,
-		Status:      domain.RequestApproved, // Auto-approve
+// IsMentorOf checks if the user is the mentor of the learning; mentors are
+// linked to their user account by email
+func (s *LearningService) IsMentorOf(ctx context.Context, learning *domain.LearningProcess, userID string) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
 	}
-
-	if err := s.requestRepo.Create(ctx, request); err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	mentor, err := s.mentorRepo.GetByID(ctx, learning.MentorID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get mentor: %w", err)
 	}
+	return strings.EqualFold(mentor.Email, user.Email), nil
+}
 
-	// Get available mentor (with lowest workload)
-	mentors, err := s.mentorRepo.GetAll(ctx, nil)
+// CreateLearningFromRequest creates a learning process from topic and
+// description with the least loaded available mentor who teaches the topic
+// or skills, as the queue picks them. When no such mentor has a free slot the
+// request is queued instead and returned without a learning;
+// it is assigned as soon as capacity frees up. When the user's manager has
+// to sign off first, the request is returned waiting for them instead.
+func (s *LearningService) CreateLearningFromRequest(ctx context.Context, userID, topic, description string, skills []string) (*domain.LearningProcess, *domain.TrainingRequest, error) {
+	chain, err := s.approvals.chainFor(ctx, userID, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get mentors: %w", err)
-	}
+		return nil, nil, err
+	}
+	if len(chain) > 0 {
+		request := &domain.TrainingRequest{
+			UserID:        userID,
+			Topic:         topic,
+			Description:   description,
+			Skills:        skills,
+			ApprovalChain: chain,
+		}
+		if err := s.approvals.submit(ctx, request); err != nil {
+			return nil, nil, err
+		}
 
-	if len(mentors) == 0 {
-		return nil, fmt.Errorf("no mentors available")
+		awaiting, err := s.queue.getRequest(ctx, request.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, awaiting, nil
 	}
 
-	// Find mentor with lowest workload
-	var selectedMentor *domain.Mentor
-	minWorkload := 6 // Max is 5, so start with 6
-	for _, mentor := range mentors {
-		if mentor.Workload < minWorkload {
-			minWorkload = mentor.Workload
-			selectedMentor = mentor
-		}
+	// Pick the mentor before touching anything
+	mentors, err := freeMentors(ctx, s.mentorRepo, s.availabilityRepo, nil)
+	if err != nil {
+		return nil, nil, err
 	}
+	selectedMentor := mentorFor(mentors, &domain.TrainingRequest{Topic: topic, Skills: skills})
 
 	if selectedMentor == nil {
-		return nil, fmt.Errorf("no suitable mentor found")
+		request := &domain.TrainingRequest{
+			UserID:      userID,
+			Topic:       topic,
+			Description: description,
+			Skills:      skills,
+			Status:      domain.RequestQueued,
+		}
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := s.requestRepo.Create(ctx, request); err != nil {
+				return fmt.Errorf("failed to create request: %w", err)
+			}
+			return s.outbox.Publish(ctx, domain.EventRequestCreated, requestEvent(request))
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		queued, err := s.queue.getRequest(ctx, request.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, queued, nil
 	}
 
-	// Create learning process
+	// Create an auto-approved training request
+	request := &domain.TrainingRequest{
+		UserID:      userID,
+		Topic:       topic,
+		Description: description,
+		Skills:      skills,
+		Status:      domain.RequestApproved, // Auto-approve
+	}
 	learning := &domain.LearningProcess{
-		RequestID: request.ID,
 		UserID:    userID,
 		MentorID:  selectedMentor.ID,
 		Status:    domain.LearningActive,
@@ -90,26 +159,41 @@ func (s *LearningService) CreateLearningFromRequest(ctx context.Context, userID,
 		Notes:     nil,
 	}
 
-	if err := s.learningRepo.Create(ctx, learning); err != nil {
-		return nil, fmt.Errorf("failed to create learning process: %w", err)
-	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.requestRepo.Create(ctx, request); err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if err := s.outbox.Publish(ctx, domain.EventRequestCreated, requestEvent(request)); err != nil {
+			return err
+		}
+
+		// Create learning process
+		learning.RequestID = request.ID
+		if err := s.learningRepo.Create(ctx, learning); err != nil {
+			return fmt.Errorf("failed to create learning process: %w", err)
+		}
+
+		// Take a slot of the mentor; fails if others took the last one since
+		if err := s.mentorRepo.IncrementWorkload(ctx, selectedMentor.ID, 1); err != nil {
+			return fmt.Errorf("failed to update mentor workload: %w", err)
+		}
 
-	// Update mentor workload
-	if err := s.mentorRepo.UpdateWorkload(ctx, selectedMentor.ID, selectedMentor.Workload+1); err != nil {
-		return nil, fmt.Errorf("failed to update mentor workload: %w", err)
+		return s.outbox.Publish(ctx, domain.EventLearningStarted, learningEvent(learning))
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Reload to get full data with JOINs
-	return s.learningRepo.GetByID(ctx, learning.ID)
+	learning, err = s.learningRepo.GetByID(ctx, learning.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return learning, request, nil
 }
 
 // UpdateLearning updates full learning process (admin only)
 func (s *LearningService) UpdateLearning(ctx context.Context, id string, topic string, description string, status domain.LearningStatus, plan []domain.LearningPlanItem, feedback *domain.Feedback, notes *string) (*domain.LearningProcess, error) {
-	_, err := s.learningRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
 	// Create learning object for update
 	learning := &domain.LearningProcess{
 		Status:   status,
@@ -124,8 +208,67 @@ func (s *LearningService) UpdateLearning(ctx context.Context, id string, topic s
 		learning.EndDate = &now
 	}
 
-	if err := s.learningRepo.Update(ctx, id, learning); err != nil {
-		return nil, fmt.Errorf("failed to update learning: %w", err)
+	// Reopening a learning takes a mentor slot again, completing it frees one
+	var wasActive bool
+	isActive := status == domain.LearningActive
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.learningRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !status.IsValid() {
+			return fmt.Errorf("%w: unknown status %q", domain.ErrInvalidInput, status)
+		}
+
+		wasActive = existing.IsActive()
+		if wasActive != isActive {
+			mentor, err := s.mentorRepo.GetByID(ctx, existing.MentorID)
+			if err != nil {
+				return fmt.Errorf("failed to get mentor: %w", err)
+			}
+			if isActive && !mentor.IsActive() {
+				return domain.ErrMentorDeactivated
+			}
+			if isActive && !mentor.CanTakeStudent() {
+				return domain.ErrMentorNotAvailable
+			}
+		}
+
+		// Fails if someone changed the status since it was read, so only one
+		// of two concurrent updates adjusts the workload
+		if err := s.learningRepo.Update(ctx, id, existing.Status, learning); err != nil {
+			return fmt.Errorf("failed to update learning: %w", err)
+		}
+
+		if wasActive != isActive {
+			if err := s.adjustWorkload(ctx, existing.MentorID, isActive); err != nil {
+				return err
+			}
+		}
+
+		if status == domain.LearningCompleted && existing.Status != domain.LearningCompleted {
+			event := learningEvent(existing)
+			event.Status = status
+			if feedback != nil {
+				event.Rating = feedback.Rating
+			}
+			return s.outbox.Publish(ctx, domain.EventLearningCompleted, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if wasActive != isActive {
+		if !isActive {
+			s.queue.serveQueue(ctx)
+			if s.certificates != nil {
+				s.certificates.issueOnCompletion(ctx, id)
+			}
+		}
 	}
 
 	// Note: topic and description are in the request, they don't change
@@ -174,75 +317,135 @@ func (s *LearningService) UpdateNotes(ctx context.Context, id string, notes stri
 
 // AssignMentor assigns a mentor to a learning process (admin only)
 func (s *LearningService) AssignMentor(ctx context.Context, learningID, mentorID string) (*domain.LearningProcess, error) {
-	// Get existing learning
-	learning, err := s.learningRepo.GetByID(ctx, learningID)
-	if err != nil {
-		return nil, err
-	}
+	var learning *domain.LearningProcess
+	var moved bool
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Get existing learning
+		var err error
+		learning, err = s.learningRepo.GetByID(ctx, learningID)
+		if err != nil {
+			return err
+		}
 
-	// Get old mentor to decrement workload
-	oldMentor, err := s.mentorRepo.GetByID(ctx, learning.MentorID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get old mentor: %w", err)
-	}
+		if !learning.IsActive() {
+			return domain.ErrLearningNotActive
+		}
 
-	// Get new mentor to increment workload
-	newMentor, err := s.mentorRepo.GetByID(ctx, mentorID)
-	if err != nil {
-		return nil, fmt.Errorf("mentor not found: %w", err)
-	}
+		// Get new mentor to increment workload
+		newMentor, err := s.mentorRepo.GetByID(ctx, mentorID)
+		if err != nil {
+			return fmt.Errorf("mentor not found: %w", err)
+		}
 
-	// Check if new mentor workload is not too high
-	if newMentor.Workload >= 5 {
-		return nil, fmt.Errorf("mentor has reached maximum workload")
-	}
+		// Reassigning to the current mentor changes nothing
+		if newMentor.ID == learning.MentorID {
+			return nil
+		}
 
-	// Update mentor workloads
-	if err := s.mentorRepo.UpdateWorkload(ctx, oldMentor.ID, oldMentor.Workload-1); err != nil {
-		return nil, fmt.Errorf("failed to update old mentor workload: %w", err)
-	}
+		if !newMentor.IsActive() {
+			return domain.ErrMentorDeactivated
+		}
+		if err := ensurePresent(ctx, s.availabilityRepo, newMentor.ID); err != nil {
+			return err
+		}
 
-	if err := s.mentorRepo.UpdateWorkload(ctx, newMentor.ID, newMentor.Workload+1); err != nil {
-		// Rollback old mentor workload
-		_ = s.mentorRepo.UpdateWorkload(ctx, oldMentor.ID, oldMentor.Workload)
-		return nil, fmt.Errorf("failed to update new mentor workload: %w", err)
-	}
+		// Check if new mentor workload is not too high
+		if !newMentor.CanTakeStudent() {
+			return domain.ErrMentorNotAvailable
+		}
 
-	// Update learning process
-	if err := s.learningRepo.UpdateMentor(ctx, learningID, mentorID); err != nil {
-		// Rollback workload changes
-		_ = s.mentorRepo.UpdateWorkload(ctx, oldMentor.ID, oldMentor.Workload)
-		_ = s.mentorRepo.UpdateWorkload(ctx, newMentor.ID, newMentor.Workload)
-		return nil, fmt.Errorf("failed to update learning mentor: %w", err)
-	}
+		// Move the learning first: it fails if a concurrent reassignment got
+		// there before us, so no other mentor's workload is touched
+		oldMentorID := learning.MentorID
+		if err := s.learningRepo.UpdateMentor(ctx, learningID, oldMentorID, newMentor.ID); err != nil {
+			return fmt.Errorf("failed to update learning mentor: %w", err)
+		}
 
-	// Reload to get updated data with JOINs
-	return s.learningRepo.GetByID(ctx, learningID)
-}
+		// Update mentor workloads
+		if err := s.mentorRepo.DecrementWorkload(ctx, oldMentorID, 1); err != nil {
+			return fmt.Errorf("failed to update old mentor workload: %w", err)
+		}
 
-// CompleteLearning marks learning as completed with feedback
-func (s *LearningService) CompleteLearning(ctx context.Context, id string, rating int, comment string) (*domain.LearningProcess, error) {
-	learning, err := s.learningRepo.GetByID(ctx, id)
+		if err := s.mentorRepo.IncrementWorkload(ctx, newMentor.ID, 1); err != nil {
+			return fmt.Errorf("failed to update new mentor workload: %w", err)
+		}
+
+		moved = true
+		event := learningEvent(learning)
+		event.MentorID = newMentor.ID
+		event.PreviousMentorID = oldMentorID
+		return s.outbox.Publish(ctx, domain.EventLearningMentorChanged, event)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Validate
-	if !learning.IsActive() {
-		return nil, domain.ErrLearningNotActive
+	if !moved {
+		return learning, nil
 	}
 
+	// Reload to get updated data with JOINs
+	return s.learningRepo.GetByID(ctx, learningID)
+}
+
+// CompleteLearning marks learning as completed with feedback and issues the
+// learner's certificate; a positive rating raises the learner's level in the
+// request's skills
+func (s *LearningService) CompleteLearning(ctx context.Context, id string, rating int, comment string) (*domain.LearningProcess, error) {
 	feedback := domain.Feedback{
 		Rating:  rating,
 		Comment: comment,
 	}
 
-	if err := feedback.Validate(); err != nil {
+	var learning *domain.LearningProcess
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		learning, err = s.learningRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Validate
+		if !learning.IsActive() {
+			return domain.ErrLearningNotActive
+		}
+
+		if err := feedback.Validate(); err != nil {
+			return err
+		}
+
+		// Fails with ErrLearningNotActive if a concurrent call completed it
+		// first, so the slot is freed once
+		if err := s.learningRepo.Complete(ctx, id, feedback); err != nil {
+			return fmt.Errorf("failed to complete learning: %w", err)
+		}
+
+		// Free the mentor's slot
+		if err := s.adjustWorkload(ctx, learning.MentorID, false); err != nil {
+			return err
+		}
+
+		event := learningEvent(learning)
+		event.Status = domain.LearningCompleted
+		event.Rating = rating
+		return s.outbox.Publish(ctx, domain.EventLearningCompleted, event)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.learningRepo.Complete(ctx, id, feedback); err != nil {
-		return nil, fmt.Errorf("failed to complete learning: %w", err)
+	// Hand the freed slot to the next queued request
+	s.queue.serveQueue(ctx)
+
+	request, err := s.requestRepo.GetByID(ctx, learning.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.competencies.recordLearning(ctx, learning.UserID, request.Skills, rating); err != nil {
+		return nil, err
+	}
+	if s.certificates != nil {
+		s.certificates.issueOnCompletion(ctx, id)
 	}
 
 	// Reload to get updated data
@@ -256,6 +459,9 @@ func (s *LearningService) CreateLearningProcess(ctx context.Context, requestID,
 	if err != nil {
 		return nil, fmt.Errorf("request not found: %w", err)
 	}
+	if request.IsCourseEnrollment() {
+		return nil, domain.ErrCourseRequest
+	}
 
 	// Get mentor
 	mentor, err := s.mentorRepo.GetByID(ctx, mentorID)
@@ -263,6 +469,16 @@ func (s *LearningService) CreateLearningProcess(ctx context.Context, requestID,
 		return nil, fmt.Errorf("mentor not found: %w", err)
 	}
 
+	if !mentor.IsActive() {
+		return nil, domain.ErrMentorDeactivated
+	}
+	if err := ensurePresent(ctx, s.availabilityRepo, mentorID); err != nil {
+		return nil, err
+	}
+	if !mentor.CanTakeStudent() {
+		return nil, domain.ErrMentorNotAvailable
+	}
+
 	// Create learning process
 	learning := &domain.LearningProcess{
 		RequestID: requestID,
@@ -274,20 +490,42 @@ func (s *LearningService) CreateLearningProcess(ctx context.Context, requestID,
 		Notes:     nil,
 	}
 
-	if err := s.learningRepo.Create(ctx, learning); err != nil {
-		return nil, fmt.Errorf("failed to create learning process: %w", err)
-	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.learningRepo.Create(ctx, learning); err != nil {
+			return fmt.Errorf("failed to create learning process: %w", err)
+		}
 
-	// Update request status to approved
-	if err := s.requestRepo.UpdateStatus(ctx, requestID, "approved"); err != nil {
-		return nil, fmt.Errorf("failed to update request status: %w", err)
-	}
+		// Update request status to approved
+		if err := s.requestRepo.UpdateStatus(ctx, requestID, request.Status, domain.RequestApproved); err != nil {
+			return fmt.Errorf("failed to update request status: %w", err)
+		}
+
+		// Update mentor workload
+		if err := s.mentorRepo.IncrementWorkload(ctx, mentorID, 1); err != nil {
+			return fmt.Errorf("failed to update mentor workload: %w", err)
+		}
 
-	// Update mentor workload
-	if err := s.mentorRepo.UpdateWorkload(ctx, mentorID, mentor.Workload+1); err != nil {
-		return nil, fmt.Errorf("failed to update mentor workload: %w", err)
+		return s.outbox.Publish(ctx, domain.EventLearningStarted, learningEvent(learning))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload to get full data with JOINs
 	return s.learningRepo.GetByID(ctx, learning.ID)
 }
+
+// adjustWorkload takes (increment) or frees one slot of the mentor's
+// workload, staying within 0 and the mentor's capacity
+func (s *LearningService) adjustWorkload(ctx context.Context, mentorID string, increment bool) error {
+	var err error
+	if increment {
+		err = s.mentorRepo.IncrementWorkload(ctx, mentorID, 1)
+	} else {
+		err = s.mentorRepo.DecrementWorkload(ctx, mentorID, 1)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update mentor workload: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,541 @@
+package service_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func TestLearningService_Getters(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	mentor := e.addMentor(t, "ann", 0)
+	mine := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	e.addLearning(t, bob.ID, mentor, domain.LearningActive)
+
+	all, err := e.learning.GetAllLearnings(ctx)
+	if err != nil || len(all) != 2 {
+		t.Errorf("GetAllLearnings returned %d learnings, %v", len(all), err)
+	}
+	userLearnings, err := e.learning.GetUserLearnings(ctx, alice.ID)
+	if err != nil || len(userLearnings) != 1 || userLearnings[0].ID != mine.ID {
+		t.Errorf("GetUserLearnings returned %d learnings, %v", len(userLearnings), err)
+	}
+	got, err := e.learning.GetLearningByID(ctx, mine.ID)
+	if err != nil || got.MentorName != "ann" || got.UserName != "alice" {
+		t.Errorf("GetLearningByID = %+v, %v", got, err)
+	}
+	if _, err := e.learning.GetLearningByID(ctx, "missing"); err == nil {
+		t.Error("GetLearningByID for a missing learning succeeded")
+	}
+}
+
+func TestLearningService_CreateLearningFromRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		workloads     map[string]int
+		absent        []string
+		wantMentor    string // empty when the request is queued
+		wantWorkloads map[string]int
+	}{
+		{
+			name:          "picks least loaded mentor",
+			workloads:     map[string]int{"ann": 3, "max": 1, "zoe": 2},
+			wantMentor:    "max",
+			wantWorkloads: map[string]int{"ann": 3, "max": 2, "zoe": 2},
+		},
+		{
+			name:          "skips full mentors",
+			workloads:     map[string]int{"ann": 5, "max": 4},
+			wantMentor:    "max",
+			wantWorkloads: map[string]int{"ann": 5, "max": 5},
+		},
+		{
+			name:          "skips absent mentors",
+			workloads:     map[string]int{"ann": 0, "max": 3},
+			absent:        []string{"ann"},
+			wantMentor:    "max",
+			wantWorkloads: map[string]int{"ann": 0, "max": 4},
+		},
+		{
+			name:          "all mentors full queues the request",
+			workloads:     map[string]int{"ann": 5, "max": 5},
+			wantWorkloads: map[string]int{"ann": 5, "max": 5},
+		},
+		{
+			name:          "only absent mentors queues the request",
+			workloads:     map[string]int{"ann": 0},
+			absent:        []string{"ann"},
+			wantWorkloads: map[string]int{"ann": 0},
+		},
+		{
+			name: "no mentors queues the request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			user := e.addUser(t, "alice")
+			mentors := map[string]*domain.Mentor{}
+			for name, workload := range tt.workloads {
+				mentors[name] = e.addMentor(t, name, workload)
+			}
+			for _, name := range tt.absent {
+				e.absent(t, mentors[name])
+			}
+
+			learning, request, err := e.learning.CreateLearningFromRequest(ctx, user.ID, "Go", "Profiling", nil)
+			expectErr(t, err, nil)
+
+			for name, want := range tt.wantWorkloads {
+				if got := e.workload(t, mentors[name].ID); got != want {
+					t.Errorf("workload of %s = %d, want %d", name, got, want)
+				}
+			}
+			requests, _ := e.requests.GetByUserID(ctx, user.ID)
+			if len(requests) != 1 || requests[0].ID != request.ID {
+				t.Fatalf("expected the returned request to be stored, got %d requests", len(requests))
+			}
+
+			if tt.wantMentor == "" {
+				if learning != nil {
+					t.Fatalf("expected the request to be queued, got learning %+v", learning)
+				}
+				if request.Status != domain.RequestQueued || request.QueuePosition != 1 || request.QueuedAt == nil {
+					t.Errorf("queued request = %+v", request)
+				}
+				return
+			}
+
+			if learning == nil || learning.MentorName != tt.wantMentor || learning.RequestTopic != "Go" || learning.Status != domain.LearningActive {
+				t.Fatalf("CreateLearningFromRequest returned %+v", learning)
+			}
+			if requests[0].Status != domain.RequestApproved {
+				t.Errorf("request status = %s, want approved", requests[0].Status)
+			}
+		})
+	}
+}
+
+func TestLearningService_CreateLearningProcess(t *testing.T) {
+	tests := []struct {
+		name           string
+		workload       int
+		missingRequest bool
+		missingMentor  bool
+		absent         bool
+		wantErr        error
+		wantWorkload   int
+	}{
+		{name: "idle mentor", workload: 0, wantWorkload: 1},
+		{name: "last slot", workload: 4, wantWorkload: 5},
+		{name: "full mentor", workload: 5, wantErr: domain.ErrMentorNotAvailable, wantWorkload: 5},
+		{name: "missing request", missingRequest: true, wantErr: domain.ErrRequestNotFound},
+		{name: "missing mentor", missingMentor: true, wantErr: domain.ErrMentorNotFound},
+		{name: "absent mentor", absent: true, wantErr: domain.ErrMentorAbsent, wantWorkload: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			request := e.addRequest(t, e.addUser(t, "alice").ID, domain.RequestPending)
+			mentor := e.addMentor(t, "ann", tt.workload)
+			if tt.absent {
+				e.absent(t, mentor)
+			}
+			requestID, mentorID := request.ID, mentor.ID
+			if tt.missingRequest {
+				requestID = "missing"
+			}
+			if tt.missingMentor {
+				mentorID = "missing"
+			}
+
+			learning, err := e.learning.CreateLearningProcess(ctx, requestID, mentorID)
+			expectErr(t, err, tt.wantErr)
+			if got := e.workload(t, mentor.ID); got != tt.wantWorkload {
+				t.Errorf("workload = %d, want %d", got, tt.wantWorkload)
+			}
+			if err != nil {
+				return
+			}
+
+			stored, _ := e.requests.GetByID(ctx, request.ID)
+			if stored.Status != domain.RequestApproved {
+				t.Errorf("request status = %s, want approved", stored.Status)
+			}
+			if learning.RequestID != request.ID || learning.MentorID != mentor.ID {
+				t.Errorf("CreateLearningProcess returned %+v", learning)
+			}
+		})
+	}
+
+	t.Run("second learning for a request", func(t *testing.T) {
+		e := newEnv(t)
+		request := e.addRequest(t, e.addUser(t, "alice").ID, domain.RequestPending)
+		mentor := e.addMentor(t, "ann", 0)
+
+		if _, err := e.learning.CreateLearningProcess(defaultCtx(), request.ID, mentor.ID); err != nil {
+			t.Fatalf("first CreateLearningProcess: %v", err)
+		}
+		if _, err := e.learning.CreateLearningProcess(defaultCtx(), request.ID, mentor.ID); err == nil {
+			t.Fatal("second CreateLearningProcess succeeded")
+		}
+		if got := e.workload(t, mentor.ID); got != 1 {
+			t.Errorf("workload = %d, want 1", got)
+		}
+	})
+}
+
+func TestLearningService_UpdateLearning(t *testing.T) {
+	tests := []struct {
+		name         string
+		from         domain.LearningStatus
+		to           domain.LearningStatus
+		workload     int // mentor workload before the learning is added
+		missing      bool
+		deactivated  bool
+		wantErr      error
+		wantWorkload int
+	}{
+		{name: "active stays active", from: domain.LearningActive, to: domain.LearningActive, workload: 1, wantWorkload: 2},
+		{name: "completing frees a slot", from: domain.LearningActive, to: domain.LearningCompleted, workload: 1, wantWorkload: 1},
+		{name: "reopening takes a slot", from: domain.LearningCompleted, to: domain.LearningActive, workload: 2, wantWorkload: 3},
+		{name: "reopening with full mentor", from: domain.LearningCompleted, to: domain.LearningActive, workload: 5, wantErr: domain.ErrMentorNotAvailable, wantWorkload: 5},
+		{name: "reopening with deactivated mentor", from: domain.LearningCompleted, to: domain.LearningActive, workload: 0, deactivated: true, wantErr: domain.ErrMentorDeactivated, wantWorkload: 0},
+		{name: "completed stays completed", from: domain.LearningCompleted, to: domain.LearningCompleted, workload: 0, wantWorkload: 0},
+		{name: "unknown status", from: domain.LearningActive, to: "paused", workload: 0, wantErr: domain.ErrInvalidInput, wantWorkload: 1},
+		{name: "missing learning", missing: true, to: domain.LearningActive, wantErr: domain.ErrLearningNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			mentor := e.addMentor(t, "ann", tt.workload)
+			id := "missing"
+			if !tt.missing {
+				id = e.addLearning(t, e.addUser(t, "alice").ID, mentor, tt.from).ID
+			}
+			if tt.deactivated {
+				e.deactivate(t, mentor)
+			}
+
+			plan := []domain.LearningPlanItem{{ID: "1", Text: "Read the spec"}}
+			notes := "reviewed"
+			learning, err := e.learning.UpdateLearning(ctx, id, "", "", tt.to, plan, nil, &notes)
+			expectErr(t, err, tt.wantErr)
+			if got := e.workload(t, mentor.ID); got != tt.wantWorkload {
+				t.Errorf("workload = %d, want %d", got, tt.wantWorkload)
+			}
+			if err != nil {
+				return
+			}
+
+			if learning.Status != tt.to || len(learning.Plan) != 1 || learning.Notes == nil || *learning.Notes != notes {
+				t.Errorf("UpdateLearning returned %+v", learning)
+			}
+			if tt.to == domain.LearningCompleted && learning.EndDate == nil {
+				t.Error("completed learning has no end date")
+			}
+		})
+	}
+
+	t.Run("concurrent status changes free one slot", func(t *testing.T) {
+		e := newEnv(t)
+		mentor := e.addMentor(t, "ann", 2)
+		learning := e.addLearning(t, e.addUser(t, "alice").ID, mentor, domain.LearningActive)
+
+		errs := race(8, func() error {
+			_, err := e.learning.UpdateLearning(defaultCtx(), learning.ID, "", "", domain.LearningCompleted, nil, nil, nil)
+			return err
+		})
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, domain.ErrLearningChanged) {
+				t.Errorf("UpdateLearning error = %v, want nil or ErrLearningChanged", err)
+			}
+		}
+		if got := e.workload(t, mentor.ID); got != 2 {
+			t.Errorf("workload = %d, want 2", got)
+		}
+		if got := e.countEvents(t, domain.EventLearningCompleted); got != 1 {
+			t.Errorf("published %d learning.completed events, want 1", got)
+		}
+	})
+}
+
+func TestLearningService_UpdatePlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing bool
+		plan    []domain.LearningPlanItem
+		wantErr error
+	}{
+		{name: "valid plan", plan: []domain.LearningPlanItem{{ID: "1", Text: "Read"}, {ID: "2", Text: "Write", Completed: true}}},
+		{name: "empty plan", plan: []domain.LearningPlanItem{}},
+		{name: "item without id", plan: []domain.LearningPlanItem{{Text: "Read"}}, wantErr: errAny},
+		{name: "item without text", plan: []domain.LearningPlanItem{{ID: "1"}}, wantErr: errAny},
+		{name: "missing learning", missing: true, wantErr: domain.ErrLearningNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			id := "missing"
+			if !tt.missing {
+				id = e.addLearning(t, e.addUser(t, "alice").ID, e.addMentor(t, "ann", 0), domain.LearningActive).ID
+			}
+
+			learning, err := e.learning.UpdatePlan(defaultCtx(), id, tt.plan)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if len(learning.Plan) != len(tt.plan) {
+				t.Errorf("plan has %d items, want %d", len(learning.Plan), len(tt.plan))
+			}
+		})
+	}
+}
+
+func TestLearningService_UpdateNotes(t *testing.T) {
+	tests := []struct {
+		name      string
+		missing   bool
+		notes     string
+		wantNotes *string
+		wantErr   error
+	}{
+		{name: "set notes", notes: "good progress", wantNotes: ptr("good progress")},
+		{name: "empty clears notes", notes: ""},
+		{name: "missing learning", missing: true, notes: "x", wantErr: domain.ErrLearningNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			id := "missing"
+			if !tt.missing {
+				id = e.addLearning(t, e.addUser(t, "alice").ID, e.addMentor(t, "ann", 0), domain.LearningActive).ID
+				if err := e.learnings.UpdateNotes(defaultCtx(), id, "previous"); err != nil {
+					t.Fatalf("seed notes: %v", err)
+				}
+			}
+
+			learning, err := e.learning.UpdateNotes(defaultCtx(), id, tt.notes)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if (learning.Notes == nil) != (tt.wantNotes == nil) || (learning.Notes != nil && *learning.Notes != *tt.wantNotes) {
+				t.Errorf("notes = %v, want %v", learning.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestLearningService_AssignMentor(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        domain.LearningStatus
+		oldWorkload   int // before the learning is added
+		newWorkload   int
+		sameMentor    bool
+		missing       bool
+		missingMentor bool
+		deactivated   bool
+		absent        bool
+		wantErr       error
+		wantOld       int
+		wantNew       int
+	}{
+		{name: "moves the slot", status: domain.LearningActive, oldWorkload: 2, newWorkload: 1, wantOld: 2, wantNew: 2},
+		{name: "new mentor takes last slot", status: domain.LearningActive, oldWorkload: 0, newWorkload: 4, wantOld: 0, wantNew: 5},
+		{name: "new mentor full", status: domain.LearningActive, oldWorkload: 0, newWorkload: 5, wantErr: domain.ErrMentorNotAvailable, wantOld: 1, wantNew: 5},
+		{name: "same mentor is a no-op", status: domain.LearningActive, oldWorkload: 4, sameMentor: true, wantOld: 5},
+		{name: "completed learning", status: domain.LearningCompleted, oldWorkload: 0, newWorkload: 0, wantErr: domain.ErrLearningNotActive, wantOld: 0, wantNew: 0},
+		{name: "missing mentor", status: domain.LearningActive, oldWorkload: 0, missingMentor: true, wantErr: domain.ErrMentorNotFound, wantOld: 1},
+		{name: "missing learning", missing: true, wantErr: domain.ErrLearningNotFound},
+		{name: "deactivated mentor", status: domain.LearningActive, oldWorkload: 0, newWorkload: 0, deactivated: true, wantErr: domain.ErrMentorDeactivated, wantOld: 1, wantNew: 0},
+		{name: "absent mentor", status: domain.LearningActive, oldWorkload: 0, newWorkload: 0, absent: true, wantErr: domain.ErrMentorAbsent, wantOld: 1, wantNew: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			oldMentor := e.addMentor(t, "ann", tt.oldWorkload)
+			newMentor := e.addMentor(t, "max", tt.newWorkload)
+			if tt.deactivated {
+				e.deactivate(t, newMentor)
+			}
+			if tt.absent {
+				e.absent(t, newMentor)
+			}
+			id := "missing"
+			if !tt.missing {
+				id = e.addLearning(t, e.addUser(t, "alice").ID, oldMentor, tt.status).ID
+			}
+			target := newMentor.ID
+			switch {
+			case tt.sameMentor:
+				target = oldMentor.ID
+			case tt.missingMentor:
+				target = "missing"
+			}
+
+			learning, err := e.learning.AssignMentor(ctx, id, target)
+			expectErr(t, err, tt.wantErr)
+			if got := e.workload(t, oldMentor.ID); got != tt.wantOld {
+				t.Errorf("old mentor workload = %d, want %d", got, tt.wantOld)
+			}
+			if !tt.sameMentor {
+				if got := e.workload(t, newMentor.ID); got != tt.wantNew {
+					t.Errorf("new mentor workload = %d, want %d", got, tt.wantNew)
+				}
+			}
+			if err != nil {
+				return
+			}
+
+			if learning.MentorID != target {
+				t.Errorf("learning mentor = %s, want %s", learning.MentorID, target)
+			}
+		})
+	}
+
+	t.Run("stale zero workload is not decremented below zero", func(t *testing.T) {
+		e := newEnv(t)
+		ctx := defaultCtx()
+		oldMentor := e.addMentor(t, "ann", 0)
+		newMentor := e.addMentor(t, "max", 0)
+		learning := e.addLearning(t, e.addUser(t, "alice").ID, oldMentor, domain.LearningActive)
+		if err := e.mentors.UpdateWorkload(ctx, oldMentor.ID, 0); err != nil {
+			t.Fatalf("reset workload: %v", err)
+		}
+
+		if _, err := e.learning.AssignMentor(ctx, learning.ID, newMentor.ID); err != nil {
+			t.Fatalf("AssignMentor: %v", err)
+		}
+		if got := e.workload(t, oldMentor.ID); got != 0 {
+			t.Errorf("old mentor workload = %d, want 0", got)
+		}
+	})
+
+	t.Run("concurrent reassignments keep workloads in step", func(t *testing.T) {
+		e := newEnv(t)
+		ctx := defaultCtx()
+		ann := e.addMentor(t, "ann", 0)
+		targets := []*domain.Mentor{e.addMentor(t, "max", 0), e.addMentor(t, "zoe", 0)}
+		learning := e.addLearning(t, e.addUser(t, "alice").ID, ann, domain.LearningActive)
+
+		var next int
+		var mu sync.Mutex
+		errs := race(8, func() error {
+			mu.Lock()
+			target := targets[next%len(targets)]
+			next++
+			mu.Unlock()
+			_, err := e.learning.AssignMentor(ctx, learning.ID, target.ID)
+			return err
+		})
+		for _, err := range errs {
+			expectErr(t, err, nil)
+		}
+
+		got, err := e.learnings.GetByID(ctx, learning.ID)
+		if err != nil {
+			t.Fatalf("get learning: %v", err)
+		}
+		for _, mentor := range append(targets, ann) {
+			want := 0
+			if mentor.ID == got.MentorID {
+				want = 1
+			}
+			if workload := e.workload(t, mentor.ID); workload != want {
+				t.Errorf("workload of %s = %d, want %d", mentor.Name, workload, want)
+			}
+		}
+	})
+}
+
+func TestLearningService_CompleteLearning(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       domain.LearningStatus
+		missing      bool
+		rating       int
+		comment      string
+		wantErr      error
+		wantWorkload int
+	}{
+		{name: "frees the mentor slot", status: domain.LearningActive, rating: 5, comment: "great", wantWorkload: 0},
+		{name: "lowest rating", status: domain.LearningActive, rating: 1, comment: "meh", wantWorkload: 0},
+		{name: "rating too low", status: domain.LearningActive, rating: 0, comment: "x", wantErr: errAny, wantWorkload: 1},
+		{name: "rating too high", status: domain.LearningActive, rating: 6, comment: "x", wantErr: errAny, wantWorkload: 1},
+		{name: "empty comment", status: domain.LearningActive, rating: 4, wantErr: errAny, wantWorkload: 1},
+		{name: "already completed", status: domain.LearningCompleted, rating: 5, comment: "again", wantErr: domain.ErrLearningNotActive, wantWorkload: 0},
+		{name: "missing learning", missing: true, rating: 5, comment: "x", wantErr: domain.ErrLearningNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			mentor := e.addMentor(t, "ann", 0)
+			id := "missing"
+			if !tt.missing {
+				id = e.addLearning(t, e.addUser(t, "alice").ID, mentor, tt.status).ID
+			}
+
+			learning, err := e.learning.CompleteLearning(defaultCtx(), id, tt.rating, tt.comment)
+			expectErr(t, err, tt.wantErr)
+			if got := e.workload(t, mentor.ID); got != tt.wantWorkload {
+				t.Errorf("workload = %d, want %d", got, tt.wantWorkload)
+			}
+			if err != nil {
+				return
+			}
+
+			if learning.Status != domain.LearningCompleted || learning.EndDate == nil || learning.Feedback == nil || learning.Feedback.Rating != tt.rating {
+				t.Errorf("CompleteLearning returned %+v", learning)
+			}
+		})
+	}
+
+	t.Run("concurrent completes free one slot", func(t *testing.T) {
+		e := newEnv(t)
+		mentor := e.addMentor(t, "ann", 2)
+		learning := e.addLearning(t, e.addUser(t, "alice").ID, mentor, domain.LearningActive)
+
+		var completed int
+		for _, err := range race(8, func() error {
+			_, err := e.learning.CompleteLearning(defaultCtx(), learning.ID, 5, "great")
+			return err
+		}) {
+			switch {
+			case err == nil:
+				completed++
+			case !errors.Is(err, domain.ErrLearningNotActive):
+				t.Errorf("CompleteLearning error = %v, want nil or ErrLearningNotActive", err)
+			}
+		}
+		if completed != 1 {
+			t.Errorf("%d calls completed the learning, want 1", completed)
+		}
+		if got := e.workload(t, mentor.ID); got != 2 {
+			t.Errorf("workload = %d, want 2", got)
+		}
+		if got := e.countEvents(t, domain.EventLearningCompleted); got != 1 {
+			t.Errorf("published %d learning.completed events, want 1", got)
+		}
+	})
+}
@@ -3,41 +3,94 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 )
 
 type MentorService struct {
-	mentorRepo domain.MentorRepository
+	mentorRepo       domain.MentorRepository
+	learningRepo     domain.LearningRepository
+	availabilityRepo domain.AvailabilityRepository
+	tenantRepo       domain.TenantRepository
+	queue            *QueueService
 }
 
-func NewMentorService(mentorRepo domain.MentorRepository) *MentorService {
+func NewMentorService(
+	mentorRepo domain.MentorRepository,
+	learningRepo domain.LearningRepository,
+	availabilityRepo domain.AvailabilityRepository,
+	tenantRepo domain.TenantRepository,
+	queue *QueueService,
+) *MentorService {
 	return &MentorService{
-		mentorRepo: mentorRepo,
+		mentorRepo:       mentorRepo,
+		learningRepo:     learningRepo,
+		availabilityRepo: availabilityRepo,
+		tenantRepo:       tenantRepo,
+		queue:            queue,
 	}
 }
 
-// CreateMentor creates a new mentor
-func (s *MentorService) CreateMentor(ctx context.Context, name, jobTitle, experience, email, telegram string) (*domain.Mentor, error) {
+// ActiveLearningsError lists the active learnings that keep a mentor from
+// being deactivated
+type ActiveLearningsError struct {
+	LearningIDs []string
+}
+
+func (e *ActiveLearningsError) Error() string {
+	return fmt.Sprintf("%s (%d active)", domain.ErrMentorHasLearnings, len(e.LearningIDs))
+}
+
+func (e *ActiveLearningsError) Unwrap() error {
+	return domain.ErrMentorHasLearnings
+}
+
+// WorkloadCorrection describes a mentor whose stored workload was fixed
+type WorkloadCorrection struct {
+	MentorID   string `json:"mentorId"`
+	MentorName string `json:"mentorName"`
+	Stored     int    `json:"stored"`
+	Actual     int    `json:"actual"`
+}
+
+// CreateMentor creates a new mentor, who starts with queued requests if
+// there are any. A zero capacity takes the default of the tenant; a mentor
+// without skills takes requests on any subject.
+func (s *MentorService) CreateMentor(ctx context.Context, name, jobTitle, experience, email, telegram string, skills []string, capacity int) (*domain.Mentor, error) {
 	// Validate input
 	if name == "" || jobTitle == "" || email == "" {
 		return nil, fmt.Errorf("%w: name, jobTitle, and email are required", domain.ErrInvalidInput)
 	}
+	if capacity == 0 {
+		tenant, err := s.tenantRepo.GetByID(ctx, domain.TenantID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tenant settings: %w", err)
+		}
+		capacity = tenant.Settings.MentorCapacity
+	}
+	if err := validateCapacity(capacity); err != nil {
+		return nil, err
+	}
 
 	mentor := &domain.Mentor{
 		Name:       name,
 		JobTitle:   jobTitle,
 		Experience: stringToPtr(experience),
 		Workload:   0,
+		Capacity:   capacity,
 		Email:      email,
 		Telegram:   stringToPtr(telegram),
+		Skills:     domain.CleanSkills(skills),
 	}
 
 	if err := s.mentorRepo.Create(ctx, mentor); err != nil {
 		return nil, fmt.Errorf("failed to create mentor: %w", err)
 	}
 
-	return mentor, nil
+	s.queue.serveQueue(ctx)
+	return s.mentorRepo.GetByID(ctx, mentor.ID)
 }
 
 // GetMentorByID retrieves a specific mentor
@@ -45,15 +98,43 @@ func (s *MentorService) GetMentorByID(ctx context.Context, mentorID string) (*do
 	return s.mentorRepo.GetByID(ctx, mentorID)
 }
 
-// GetAvailableMentors retrieves mentors with workload less than maximum
-func (s *MentorService) GetAvailableMentors(ctx context.Context) ([]*domain.Mentor, error) {
-	maxWorkload := 4 // Only mentors with workload <= 4 can accept new students
-	return s.mentorRepo.GetAll(ctx, &maxWorkload)
+// GetAvailableMentors retrieves mentors with workload less than maximum who
+// have a free slot in [from, to). Equal from and to check a single moment,
+// zero values mean right now.
+func (s *MentorService) GetAvailableMentors(ctx context.Context, from, to time.Time) ([]*domain.Mentor, error) {
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = from
+	}
+	if err := validateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	mentors, err := s.mentorRepo.GetAll(ctx, domain.MentorFilter{HasCapacity: true, ActiveOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]*domain.Mentor, 0, len(mentors))
+	for _, mentor := range mentors {
+		schedule, err := loadSchedule(ctx, s.availabilityRepo, mentor.ID, from, &to)
+		if err != nil {
+			return nil, err
+		}
+		if schedule.AvailableBetween(from, to) {
+			available = append(available, mentor)
+		}
+	}
+
+	return available, nil
 }
 
-// GetAllMentors retrieves all mentors
-func (s *MentorService) GetAllMentors(ctx context.Context) ([]*domain.Mentor, error) {
-	return s.mentorRepo.GetAll(ctx, nil)
+// GetAllMentors retrieves all mentors, skipping deactivated ones unless
+// includeInactive is set
+func (s *MentorService) GetAllMentors(ctx context.Context, includeInactive bool) ([]*domain.Mentor, error) {
+	return s.mentorRepo.GetAll(ctx, domain.MentorFilter{ActiveOnly: !includeInactive})
 }
 
 // IncrementMentorWorkload increases a mentor's workload
@@ -63,35 +144,42 @@ func (s *MentorService) IncrementMentorWorkload(ctx context.Context, mentorID st
 		return err
 	}
 
-	if !mentor.CanTakeStudent() {
-		return domain.ErrMentorNotAvailable
+	if !mentor.IsActive() {
+		return domain.ErrMentorDeactivated
 	}
 
-	mentor.IncrementWorkload()
-	return s.mentorRepo.UpdateWorkload(ctx, mentor.ID, mentor.Workload)
+	return s.mentorRepo.IncrementWorkload(ctx, mentor.ID, 1)
 }
 
 // DecrementMentorWorkload decreases a mentor's workload
 func (s *MentorService) DecrementMentorWorkload(ctx context.Context, mentorID string) error {
-	mentor, err := s.mentorRepo.GetByID(ctx, mentorID)
-	if err != nil {
-		return err
-	}
-
-	mentor.DecrementWorkload()
-	return s.mentorRepo.UpdateWorkload(ctx, mentor.ID, mentor.Workload)
+	return s.mentorRepo.DecrementWorkload(ctx, mentorID, 1)
 }
 
-// UpdateMentor updates an existing mentor (admin only)
-func (s *MentorService) UpdateMentor(ctx context.Context, id string, name, jobTitle, experience, email, telegram string, workload int) (*domain.Mentor, error) {
+// UpdateMentor updates an existing mentor (admin only); a zero capacity
+// keeps the current one and nil skills keep the current skills
+func (s *MentorService) UpdateMentor(ctx context.Context, id string, name, jobTitle, experience, email, telegram string, skills []string, workload, capacity int) (*domain.Mentor, error) {
 	mentor, err := s.mentorRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Validate workload
-	if workload < 0 || workload > 5 {
-		return nil, fmt.Errorf("workload must be between 0 and 5")
+	if capacity == 0 {
+		capacity = mentor.Capacity
+	}
+	if err := validateCapacity(capacity); err != nil {
+		return nil, err
+	}
+	if workload < 0 || workload > capacity {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, domain.ErrInvalidWorkload)
+	}
+
+	freed := workload < mentor.Workload || capacity > mentor.Capacity
+	if skills != nil {
+		skills = domain.CleanSkills(skills)
+		// New skills may match queued requests no one could take so far
+		freed = freed || !slices.Equal(skills, mentor.Skills)
+		mentor.Skills = skills
 	}
 
 	// Update fields
@@ -101,14 +189,120 @@ func (s *MentorService) UpdateMentor(ctx context.Context, id string, name, jobTi
 	mentor.Email = email
 	mentor.Telegram = &telegram
 	mentor.Workload = workload
+	mentor.Capacity = capacity
 
 	if err := s.mentorRepo.Update(ctx, mentor); err != nil {
 		return nil, fmt.Errorf("failed to update mentor: %w", err)
 	}
 
+	// A lower workload or new skills leave room for queued requests
+	if freed {
+		s.queue.serveQueue(ctx)
+		return s.mentorRepo.GetByID(ctx, id)
+	}
+
 	return mentor, nil
 }
 
+// DeactivateMentor hides a mentor from assignment. Their active learnings
+// must be reassigned first; history stays attached to the mentor.
+func (s *MentorService) DeactivateMentor(ctx context.Context, id string) (*domain.Mentor, error) {
+	mentor, err := s.mentorRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !mentor.IsActive() {
+		return mentor, nil
+	}
+
+	learnings, err := s.learningRepo.GetByMentorID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learnings of mentor: %w", err)
+	}
+
+	var active []string
+	for _, learning := range learnings {
+		if learning.IsActive() {
+			active = append(active, learning.ID)
+		}
+	}
+	if len(active) > 0 {
+		return nil, &ActiveLearningsError{LearningIDs: active}
+	}
+
+	now := time.Now()
+	if err := s.mentorRepo.UpdateDeactivatedAt(ctx, id, &now); err != nil {
+		return nil, fmt.Errorf("failed to deactivate mentor: %w", err)
+	}
+
+	return s.mentorRepo.GetByID(ctx, id)
+}
+
+// ReactivateMentor makes a deactivated mentor available for assignment again
+func (s *MentorService) ReactivateMentor(ctx context.Context, id string) (*domain.Mentor, error) {
+	if _, err := s.mentorRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.mentorRepo.UpdateDeactivatedAt(ctx, id, nil); err != nil {
+		return nil, fmt.Errorf("failed to reactivate mentor: %w", err)
+	}
+	s.queue.serveQueue(ctx)
+
+	return s.mentorRepo.GetByID(ctx, id)
+}
+
+// RecalculateWorkloads resets each mentor's workload to the number of
+// active learning processes they lead and returns the mentors that drifted
+func (s *MentorService) RecalculateWorkloads(ctx context.Context, dryRun bool) ([]WorkloadCorrection, error) {
+	mentors, err := s.mentorRepo.GetAll(ctx, domain.MentorFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	corrections := []WorkloadCorrection{}
+	for _, mentor := range mentors {
+		learnings, err := s.learningRepo.GetByMentorID(ctx, mentor.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get learnings of mentor %s: %w", mentor.ID, err)
+		}
+
+		active := 0
+		for _, learning := range learnings {
+			if learning.IsActive() {
+				active++
+			}
+		}
+		if active > mentor.Capacity {
+			active = mentor.Capacity
+		}
+
+		if active == mentor.Workload {
+			continue
+		}
+
+		corrections = append(corrections, WorkloadCorrection{
+			MentorID:   mentor.ID,
+			MentorName: mentor.Name,
+			Stored:     mentor.Workload,
+			Actual:     active,
+		})
+
+		if dryRun {
+			continue
+		}
+		if err := s.mentorRepo.UpdateWorkload(ctx, mentor.ID, active); err != nil {
+			return nil, fmt.Errorf("failed to update workload of mentor %s: %w", mentor.ID, err)
+		}
+	}
+
+	if !dryRun && len(corrections) > 0 {
+		s.queue.serveQueue(ctx)
+	}
+
+	return corrections, nil
+}
+
 // stringToPtr converts string to *string, returns nil for empty strings
 func stringToPtr(s string) *string {
 	if s == "" {
@@ -116,3 +310,11 @@ func stringToPtr(s string) *string {
 	}
 	return &s
 }
+
+// validateCapacity checks how many active learnings a mentor may take on
+func validateCapacity(capacity int) error {
+	if capacity < 1 || capacity > domain.MaxMentorCapacity {
+		return fmt.Errorf("%w: capacity must be between 1 and %d", domain.ErrInvalidInput, domain.MaxMentorCapacity)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+func TestMentorService_CreateMentor(t *testing.T) {
+	tests := []struct {
+		name       string
+		mentorName string
+		jobTitle   string
+		email      string
+		experience string
+		capacity   int
+		wantErr    error
+	}{
+		{name: "all fields", mentorName: "Ann", jobTitle: "Lead", email: "ann@example.com", experience: "10 years"},
+		{name: "no experience", mentorName: "Ann", jobTitle: "Lead", email: "ann@example.com"},
+		{name: "own capacity", mentorName: "Ann", jobTitle: "Lead", email: "ann@example.com", capacity: 8},
+		{name: "capacity above max", mentorName: "Ann", jobTitle: "Lead", email: "ann@example.com", capacity: 21, wantErr: domain.ErrInvalidInput},
+		{name: "negative capacity", mentorName: "Ann", jobTitle: "Lead", email: "ann@example.com", capacity: -1, wantErr: domain.ErrInvalidInput},
+		{name: "missing name", jobTitle: "Lead", email: "ann@example.com", wantErr: domain.ErrInvalidInput},
+		{name: "missing job title", mentorName: "Ann", email: "ann@example.com", wantErr: domain.ErrInvalidInput},
+		{name: "missing email", mentorName: "Ann", jobTitle: "Lead", wantErr: domain.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+
+			mentor, err := e.mentor.CreateMentor(defaultCtx(), tt.mentorName, tt.jobTitle, tt.experience, tt.email, "", nil, tt.capacity)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			wantCapacity := tt.capacity
+			if wantCapacity == 0 {
+				wantCapacity = domain.DefaultMentorCapacity // from the tenant settings
+			}
+			if mentor.Capacity != wantCapacity {
+				t.Errorf("capacity = %d, want %d", mentor.Capacity, wantCapacity)
+			}
+
+			if mentor.ID == "" || mentor.Workload != 0 || mentor.Telegram != nil {
+				t.Errorf("CreateMentor returned %+v", mentor)
+			}
+			if (mentor.Experience == nil) != (tt.experience == "") {
+				t.Errorf("experience = %v, want %q", mentor.Experience, tt.experience)
+			}
+		})
+	}
+}
+
+func TestMentorService_Getters(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	idle := e.addMentor(t, "idle", 0)
+	e.addMentor(t, "busy", 4)
+	e.addMentor(t, "full", 5)
+	e.deactivate(t, e.addMentor(t, "gone", 0))
+
+	got, err := e.mentor.GetMentorByID(ctx, idle.ID)
+	if err != nil || got.Name != "idle" {
+		t.Errorf("GetMentorByID = %+v, %v", got, err)
+	}
+	active, err := e.mentor.GetAllMentors(ctx, false)
+	if err != nil || len(active) != 3 {
+		t.Errorf("GetAllMentors returned %d mentors, %v", len(active), err)
+	}
+	all, err := e.mentor.GetAllMentors(ctx, true)
+	if err != nil || len(all) != 4 {
+		t.Errorf("GetAllMentors(includeInactive) returned %d mentors, %v", len(all), err)
+	}
+	available, err := e.mentor.GetAvailableMentors(ctx, time.Time{}, time.Time{})
+	if err != nil || len(available) != 2 {
+		t.Errorf("GetAvailableMentors returned %d mentors, %v", len(available), err)
+	}
+	for _, mentor := range available {
+		if !mentor.CanTakeStudent() {
+			t.Errorf("GetAvailableMentors returned full mentor %s", mentor.Name)
+		}
+	}
+}
+
+func TestMentorService_GetAvailableMentors(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	e.addMentor(t, "anytime", 0)
+	weekender := e.addMentor(t, "weekender", 0)
+	if _, err := e.availability.AddWindow(ctx, weekender.ID, time.Saturday, "10:00", "12:00"); err != nil {
+		t.Fatalf("add window: %v", err)
+	}
+	e.absent(t, e.addMentor(t, "away", 0))
+	traveller := e.addMentor(t, "traveller", 0)
+	if _, err := e.availability.AddAbsence(ctx, traveller.ID, domain.AbsenceVacation, monday, monday.AddDate(0, 0, 7), ""); err != nil {
+		t.Fatalf("add absence: %v", err)
+	}
+	e.addMentor(t, "full", 5)
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		want    []string
+		wantErr error
+	}{
+		{name: "right now", want: []string{"anytime", "weekender", "traveller"}},
+		{name: "whole week", from: monday, to: monday.AddDate(0, 0, 7), want: []string{"anytime", "weekender", "away"}},
+		{name: "working days", from: monday, to: monday.AddDate(0, 0, 5), want: []string{"anytime", "away"}},
+		{name: "week after vacation", from: monday.AddDate(0, 0, 7), to: monday.AddDate(0, 0, 12), want: []string{"anytime", "away", "traveller"}},
+		{name: "reversed range", from: monday.AddDate(0, 0, 1), to: monday, wantErr: domain.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mentors, err := e.mentor.GetAvailableMentors(ctx, tt.from, tt.to)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			got := map[string]bool{}
+			for _, mentor := range mentors {
+				got[mentor.Name] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got mentors %v, want %v", got, tt.want)
+			}
+			for _, name := range tt.want {
+				if !got[name] {
+					t.Errorf("%s missing from %v", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMentorService_IncrementMentorWorkload(t *testing.T) {
+	tests := []struct {
+		name         string
+		workload     int
+		wantErr      error
+		wantWorkload int
+	}{
+		{name: "idle", workload: 0, wantWorkload: 1},
+		{name: "last slot", workload: 4, wantWorkload: 5},
+		{name: "full", workload: 5, wantErr: domain.ErrMentorNotAvailable, wantWorkload: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			mentor := e.addMentor(t, "ann", tt.workload)
+
+			err := e.mentor.IncrementMentorWorkload(defaultCtx(), mentor.ID)
+			expectErr(t, err, tt.wantErr)
+			if got := e.workload(t, mentor.ID); got != tt.wantWorkload {
+				t.Errorf("workload = %d, want %d", got, tt.wantWorkload)
+			}
+		})
+	}
+}
+
+func TestMentorService_DecrementMentorWorkload(t *testing.T) {
+	tests := []struct {
+		name         string
+		workload     int
+		wantWorkload int
+	}{
+		{name: "full", workload: 5, wantWorkload: 4},
+		{name: "one", workload: 1, wantWorkload: 0},
+		{name: "idle stays at zero", workload: 0, wantWorkload: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			mentor := e.addMentor(t, "ann", tt.workload)
+
+			if err := e.mentor.DecrementMentorWorkload(defaultCtx(), mentor.ID); err != nil {
+				t.Fatalf("DecrementMentorWorkload: %v", err)
+			}
+			if got := e.workload(t, mentor.ID); got != tt.wantWorkload {
+				t.Errorf("workload = %d, want %d", got, tt.wantWorkload)
+			}
+		})
+	}
+
+	e := newEnv(t)
+	if err := e.mentor.DecrementMentorWorkload(defaultCtx(), "missing"); err == nil {
+		t.Error("DecrementMentorWorkload for a missing mentor succeeded")
+	}
+}
+
+func TestMentorService_UpdateMentor(t *testing.T) {
+	tests := []struct {
+		name     string
+		missing  bool
+		workload int
+		capacity int
+		wantErr  error
+	}{
+		{name: "valid", workload: 3},
+		{name: "zero workload", workload: 0},
+		{name: "max workload", workload: 5},
+		{name: "negative workload", workload: -1, wantErr: errAny},
+		{name: "workload above capacity", workload: 6, wantErr: domain.ErrInvalidInput},
+		{name: "raised capacity", workload: 7, capacity: 8},
+		{name: "lowered capacity", workload: 2, capacity: 1, wantErr: domain.ErrInvalidInput},
+		{name: "capacity above max", workload: 1, capacity: 21, wantErr: domain.ErrInvalidInput},
+		{name: "missing mentor", missing: true, workload: 1, wantErr: domain.ErrMentorNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			id := e.addMentor(t, "ann", 1).ID
+			if tt.missing {
+				id = "missing"
+			}
+
+			_, err := e.mentor.UpdateMentor(defaultCtx(), id, "Anna", "Principal", "15 years", "anna@example.com", "@anna", nil, tt.workload, tt.capacity)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			stored, _ := e.mentors.GetByID(defaultCtx(), id)
+			if stored.Name != "Anna" || stored.Email != "anna@example.com" || stored.Workload != tt.workload {
+				t.Errorf("stored mentor = %+v", stored)
+			}
+		})
+	}
+}
+
+func TestMentorService_RecalculateWorkloads(t *testing.T) {
+	tests := []struct {
+		name            string
+		dryRun          bool
+		stored          int
+		active          int
+		completed       int
+		wantCorrections int
+		wantWorkload    int
+	}{
+		{name: "in sync", stored: 2, active: 2, completed: 1, wantCorrections: 0, wantWorkload: 2},
+		{name: "drifted up", stored: 5, active: 1, completed: 3, wantCorrections: 1, wantWorkload: 1},
+		{name: "drifted down", stored: 0, active: 3, wantCorrections: 1, wantWorkload: 3},
+		{name: "dry run keeps stored value", dryRun: true, stored: 4, active: 1, wantCorrections: 1, wantWorkload: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			user := e.addUser(t, "alice")
+			mentor := e.addMentor(t, "ann", 0)
+			for i := 0; i < tt.active; i++ {
+				e.addLearning(t, user.ID, mentor, domain.LearningActive)
+			}
+			for i := 0; i < tt.completed; i++ {
+				e.addLearning(t, user.ID, mentor, domain.LearningCompleted)
+			}
+			if err := e.mentors.UpdateWorkload(ctx, mentor.ID, tt.stored); err != nil {
+				t.Fatalf("set stored workload: %v", err)
+			}
+
+			corrections, err := e.mentor.RecalculateWorkloads(ctx, tt.dryRun)
+			if err != nil {
+				t.Fatalf("RecalculateWorkloads: %v", err)
+			}
+			if len(corrections) != tt.wantCorrections {
+				t.Fatalf("got %d corrections, want %d", len(corrections), tt.wantCorrections)
+			}
+			if len(corrections) == 1 && (corrections[0].Stored != tt.stored || corrections[0].Actual != tt.active) {
+				t.Errorf("correction = %+v", corrections[0])
+			}
+			if got := e.workload(t, mentor.ID); got != tt.wantWorkload {
+				t.Errorf("workload = %d, want %d", got, tt.wantWorkload)
+			}
+		})
+	}
+}
+
+func TestMentorService_DeactivateMentor(t *testing.T) {
+	tests := []struct {
+		name        string
+		active      int
+		completed   int
+		deactivated bool
+		missing     bool
+		wantErr     error
+	}{
+		{name: "idle mentor", completed: 2},
+		{name: "already deactivated", deactivated: true},
+		{name: "active learnings", active: 2, completed: 1, wantErr: domain.ErrMentorHasLearnings},
+		{name: "missing mentor", missing: true, wantErr: domain.ErrMentorNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			user := e.addUser(t, "alice")
+			mentor := e.addMentor(t, "ann", 0)
+			var activeIDs []string
+			for i := 0; i < tt.active; i++ {
+				activeIDs = append(activeIDs, e.addLearning(t, user.ID, mentor, domain.LearningActive).ID)
+			}
+			for i := 0; i < tt.completed; i++ {
+				e.addLearning(t, user.ID, mentor, domain.LearningCompleted)
+			}
+			if tt.deactivated {
+				e.deactivate(t, mentor)
+			}
+			id := mentor.ID
+			if tt.missing {
+				id = "missing"
+			}
+
+			got, err := e.mentor.DeactivateMentor(ctx, id)
+			expectErr(t, err, tt.wantErr)
+
+			var activeErr *service.ActiveLearningsError
+			if errors.As(err, &activeErr) && len(activeErr.LearningIDs) != len(activeIDs) {
+				t.Errorf("error lists %v, want %v", activeErr.LearningIDs, activeIDs)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.IsActive() {
+				t.Errorf("DeactivateMentor returned active mentor %+v", got)
+			}
+			if tt.deactivated && !got.DeactivatedAt.Equal(*mentor.DeactivatedAt) {
+				t.Errorf("repeated deactivation moved DeactivatedAt to %v", got.DeactivatedAt)
+			}
+			available, _ := e.mentor.GetAvailableMentors(ctx, time.Time{}, time.Time{})
+			if len(available) != 0 {
+				t.Errorf("deactivated mentor still available")
+			}
+
+			got, err = e.mentor.ReactivateMentor(ctx, id)
+			if err != nil || !got.IsActive() {
+				t.Fatalf("ReactivateMentor = %+v, %v", got, err)
+			}
+			available, _ = e.mentor.GetAvailableMentors(ctx, time.Time{}, time.Time{})
+			if len(available) != 1 {
+				t.Errorf("reactivated mentor not available")
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+type NotificationService struct {
+	notificationRepo domain.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo domain.NotificationRepository) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo}
+}
+
+// Notify stores an in-app notification for a user
+func (s *NotificationService) Notify(ctx context.Context, userID string, kind domain.NotificationKind, title, body string) error {
+	notification := &domain.Notification{
+		UserID: userID,
+		Kind:   kind,
+		Title:  title,
+		Body:   body,
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to notify user %s: %w", userID, err)
+	}
+
+	return nil
+}
+
+// GetUserNotifications retrieves notifications of a user, newest first
+func (s *NotificationService) GetUserNotifications(ctx context.Context, userID string, unreadOnly bool) ([]*domain.Notification, error) {
+	return s.notificationRepo.GetByUserID(ctx, userID, unreadOnly)
+}
+
+// MarkRead marks a notification of the user as read
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID string) error {
+	return s.notificationRepo.MarkRead(ctx, id, userID)
+}
@@ -0,0 +1,39 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func TestNotificationService(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+
+	if err := e.notification.Notify(ctx, alice.ID, domain.NotificationMentorChanged, "Your mentor has changed", "Max takes over"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err := e.notification.Notify(ctx, "missing", domain.NotificationMentorChanged, "t", "b"); err == nil {
+		t.Error("Notify for a missing user succeeded")
+	}
+
+	unread, err := e.notification.GetUserNotifications(ctx, alice.ID, true)
+	if err != nil || len(unread) != 1 {
+		t.Fatalf("GetUserNotifications = %v, %v", unread, err)
+	}
+	if err := e.notification.MarkRead(ctx, unread[0].ID, bob.ID); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("MarkRead by another user error = %v, want ErrNotificationNotFound", err)
+	}
+	if err := e.notification.MarkRead(ctx, unread[0].ID, alice.ID); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+
+	unread, _ = e.notification.GetUserNotifications(ctx, alice.ID, true)
+	all, _ := e.notification.GetUserNotifications(ctx, alice.ID, false)
+	if len(unread) != 0 || len(all) != 1 || !all[0].IsRead() {
+		t.Errorf("after MarkRead: %d unread, %d total", len(unread), len(all))
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// OutboxService records training events for other systems. Publish must run
+// in the transaction of the change the event describes: the event is then
+// stored if and only if the change is committed, and WebhookService picks it
+// up afterwards.
+type OutboxService struct {
+	outboxRepo domain.OutboxRepository
+}
+
+func NewOutboxService(outboxRepo domain.OutboxRepository) *OutboxService {
+	return &OutboxService{outboxRepo: outboxRepo}
+}
+
+// Publish writes an event with the JSON encoding of data to the outbox
+func (s *OutboxService) Publish(ctx context.Context, eventType domain.EventType, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	if err := s.outboxRepo.Append(ctx, &domain.OutboxEvent{Type: eventType, Payload: payload}); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// requestEvent describes a request in its current state
+func requestEvent(request *domain.TrainingRequest) domain.RequestEvent {
+	return domain.RequestEvent{
+		RequestID: request.ID,
+		UserID:    request.UserID,
+		Topic:     request.Topic,
+		Status:    request.Status,
+		CourseID:  request.CourseID,
+	}
+}
+
+// learningEvent describes a learning in its current state
+func learningEvent(learning *domain.LearningProcess) domain.LearningEvent {
+	return domain.LearningEvent{
+		LearningID: learning.ID,
+		RequestID:  learning.RequestID,
+		UserID:     learning.UserID,
+		MentorID:   learning.MentorID,
+		Status:     learning.Status,
+	}
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// QueueService keeps the training requests no mentor could take yet and
+// hands them out, highest priority and oldest first, as soon as a mentor
+// has a free slot again
+type QueueService struct {
+	tx               domain.TxManager
+	requestRepo      domain.RequestRepository
+	mentorRepo       domain.MentorRepository
+	learningRepo     domain.LearningRepository
+	availabilityRepo domain.AvailabilityRepository
+	notifications    *NotificationService
+	outbox           *OutboxService
+}
+
+func NewQueueService(
+	tx domain.TxManager,
+	requestRepo domain.RequestRepository,
+	mentorRepo domain.MentorRepository,
+	learningRepo domain.LearningRepository,
+	availabilityRepo domain.AvailabilityRepository,
+	notifications *NotificationService,
+	outbox *OutboxService,
+) *QueueService {
+	return &QueueService{
+		tx:               tx,
+		requestRepo:      requestRepo,
+		mentorRepo:       mentorRepo,
+		learningRepo:     learningRepo,
+		availabilityRepo: availabilityRepo,
+		notifications:    notifications,
+		outbox:           outbox,
+	}
+}
+
+// GetQueue lists the queued requests in the order they will be served
+func (s *QueueService) GetQueue(ctx context.Context) ([]*domain.TrainingRequest, error) {
+	queue, err := s.requestRepo.GetQueue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, request := range queue {
+		request.QueuePosition = i + 1
+	}
+	return queue, nil
+}
+
+// Enqueue puts a pending request in the queue, or changes the priority of a
+// queued one, and serves the queue right away in case a mentor is free
+func (s *QueueService) Enqueue(ctx context.Context, requestID string, priority int) (*domain.TrainingRequest, error) {
+	request, err := s.requestRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !request.IsPending() && !request.IsQueued() {
+		return nil, domain.ErrRequestNotPending
+	}
+	if request.IsCourseEnrollment() {
+		return nil, domain.ErrCourseRequest
+	}
+
+	if err := s.requestRepo.Enqueue(ctx, requestID, request.Status, priority); err != nil {
+		if errors.Is(err, domain.ErrRequestChanged) {
+			return nil, domain.ErrRequestNotPending
+		}
+		return nil, fmt.Errorf("failed to enqueue request: %w", err)
+	}
+
+	if _, err := s.Dispatch(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.getRequest(ctx, requestID)
+}
+
+// Dequeue takes a request out of the queue; it goes back to pending so an
+// admin can assign a mentor by hand
+func (s *QueueService) Dequeue(ctx context.Context, requestID string) (*domain.TrainingRequest, error) {
+	request, err := s.requestRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !request.IsQueued() {
+		return nil, domain.ErrRequestNotQueued
+	}
+
+	if err := s.requestRepo.UpdateStatus(ctx, requestID, domain.RequestQueued, domain.RequestPending); err != nil {
+		if errors.Is(err, domain.ErrRequestChanged) {
+			return nil, domain.ErrRequestNotQueued
+		}
+		return nil, fmt.Errorf("failed to dequeue request: %w", err)
+	}
+
+	return s.requestRepo.GetByID(ctx, requestID)
+}
+
+// Dispatch assigns queued requests in queue order, each to an active mentor
+// who is in the office, has a free slot and teaches the request's topic or
+// one of its skills, the least loaded of them first. Mentors without skills
+// take any subject and get a request only when no such specialist is free.
+// A request no free mentor matches stays queued for a later dispatch, and
+// the requests behind it are served. The dispatch ends when the queue is
+// done or no mentor has capacity left.
+//
+// Every assignment is its own transaction. A request whose assignment fails
+// is logged and left in the queue for the next dispatch, so it does not hold
+// up the requests behind it; one that left the queue in the meantime is
+// passed over. A mentor whose slots turn out to be taken is
+// left out for the rest of the dispatch. Only failures to read the queue or
+// the mentors and a cancelled context end the dispatch early. The learnings
+// started so far are returned even on error.
+func (s *QueueService) Dispatch(ctx context.Context) ([]*domain.LearningProcess, error) {
+	started := []*domain.LearningProcess{}
+	skipped := map[string]bool{}
+	full := map[string]bool{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return started, err
+		}
+
+		var (
+			learning  *domain.LearningProcess
+			mentor    *domain.Mentor
+			unmatched *domain.TrainingRequest
+			failed    *domain.TrainingRequest
+			assignErr error
+		)
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			queue, err := s.requestRepo.GetQueue(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get request queue: %w", err)
+			}
+			request := nextQueued(queue, skipped)
+			if request == nil {
+				return nil
+			}
+
+			mentors, err := freeMentors(ctx, s.mentorRepo, s.availabilityRepo, full)
+			if err != nil || len(mentors) == 0 {
+				return err
+			}
+			if mentor = mentorFor(mentors, request); mentor == nil {
+				unmatched = request
+				return nil
+			}
+
+			learning, assignErr = s.assign(ctx, request, mentor)
+			if assignErr != nil {
+				failed = request
+			}
+			return assignErr
+		})
+		switch {
+		case unmatched != nil:
+			// No free mentor teaches it; wait for one
+			skipped[unmatched.ID] = true
+			continue
+		case failed != nil && errors.Is(assignErr, domain.ErrMentorNotAvailable):
+			// Someone else took the mentor's last slot; pick another one
+			full[mentor.ID] = true
+			continue
+		case failed != nil && errors.Is(assignErr, domain.ErrRequestChanged):
+			// Dequeued, cancelled or assigned by someone else meanwhile
+			skipped[failed.ID] = true
+			continue
+		case failed != nil:
+			slog.WarnContext(ctx, "failed to dispatch queued request, leaving it queued",
+				"request", failed.ID, "error", assignErr)
+			skipped[failed.ID] = true
+			continue
+		case err != nil:
+			return started, err
+		case learning == nil:
+			return started, nil
+		}
+		started = append(started, learning)
+	}
+}
+
+// nextQueued returns the first request of the queue that was not skipped,
+// or nil if there is none
+func nextQueued(queue []*domain.TrainingRequest, skipped map[string]bool) *domain.TrainingRequest {
+	for _, request := range queue {
+		if !skipped[request.ID] {
+			return request
+		}
+	}
+	return nil
+}
+
+// serveQueue dispatches the queue after a change that may have freed a
+// mentor slot. The change itself is already saved, so a failure is only
+// logged; the next change or a manual dispatch picks the queue up again.
+func (s *QueueService) serveQueue(ctx context.Context) {
+	if _, err := s.Dispatch(ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to dispatch request queue", "error", err)
+	}
+}
+
+// assign approves a queued request, starts its learning with the mentor and
+// tells the learner. It fails with ErrRequestChanged when the request is no
+// longer queued.
+func (s *QueueService) assign(ctx context.Context, request *domain.TrainingRequest, mentor *domain.Mentor) (*domain.LearningProcess, error) {
+	if err := s.requestRepo.UpdateStatus(ctx, request.ID, domain.RequestQueued, domain.RequestApproved); err != nil {
+		return nil, fmt.Errorf("failed to approve request: %w", err)
+	}
+
+	learning := &domain.LearningProcess{
+		RequestID: request.ID,
+		UserID:    request.UserID,
+		MentorID:  mentor.ID,
+		Status:    domain.LearningActive,
+		StartDate: time.Now(),
+		Plan:      []domain.LearningPlanItem{},
+	}
+	if err := s.learningRepo.Create(ctx, learning); err != nil {
+		return nil, fmt.Errorf("failed to create learning process: %w", err)
+	}
+
+	if err := s.mentorRepo.IncrementWorkload(ctx, mentor.ID, 1); err != nil {
+		return nil, fmt.Errorf("failed to update mentor workload: %w", err)
+	}
+
+	if err := s.outbox.Publish(ctx, domain.EventLearningStarted, learningEvent(learning)); err != nil {
+		return nil, err
+	}
+
+	if err := s.notifications.Notify(
+		ctx, request.UserID, domain.NotificationRequestAssigned,
+		"Your request has a mentor",
+		fmt.Sprintf("%s will be your mentor for %q.", mentor.Name, request.Topic),
+	); err != nil {
+		return nil, err
+	}
+
+	return s.learningRepo.GetByID(ctx, learning.ID)
+}
+
+// getRequest reloads a request with its queue position
+func (s *QueueService) getRequest(ctx context.Context, requestID string) (*domain.TrainingRequest, error) {
+	request, err := s.requestRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := setQueuePositions(ctx, s.requestRepo, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// freeMentors returns the active mentors who are in the office and can take
+// one more student, least loaded first. Mentors in exclude are passed over.
+func freeMentors(ctx context.Context, mentorRepo domain.MentorRepository, availabilityRepo domain.AvailabilityRepository, exclude map[string]bool) ([]*domain.Mentor, error) {
+	mentors, err := mentorRepo.GetAll(ctx, domain.MentorFilter{HasCapacity: true, ActiveOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentors: %w", err)
+	}
+	absent, err := absentMentors(ctx, availabilityRepo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	free := mentors[:0]
+	for _, mentor := range mentors {
+		if !absent[mentor.ID] && !exclude[mentor.ID] && mentor.CanTakeStudent() {
+			free = append(free, mentor)
+		}
+	}
+	return free, nil
+}
+
+// mentorFor picks the mentor for a request among free mentors: the least
+// loaded one who teaches its topic or skills, otherwise the least loaded one
+// without skills. It returns nil if neither is free.
+func mentorFor(mentors []*domain.Mentor, request *domain.TrainingRequest) *domain.Mentor {
+	var specialist, generalist *domain.Mentor
+	for _, mentor := range mentors {
+		switch {
+		case mentor.Teaches(request):
+			if specialist == nil || mentor.Workload < specialist.Workload {
+				specialist = mentor
+			}
+		case len(mentor.Skills) == 0:
+			if generalist == nil || mentor.Workload < generalist.Workload {
+				generalist = mentor
+			}
+		}
+	}
+	if specialist != nil {
+		return specialist
+	}
+	return generalist
+}
+
+// setQueuePositions fills QueuePosition of the queued requests among the
+// given ones
+func setQueuePositions(ctx context.Context, repo domain.RequestRepository, requests ...*domain.TrainingRequest) error {
+	queued := false
+	for _, request := range requests {
+		queued = queued || request.IsQueued()
+	}
+	if !queued {
+		return nil
+	}
+
+	queue, err := repo.GetQueue(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get request queue: %w", err)
+	}
+
+	positions := make(map[string]int, len(queue))
+	for i, request := range queue {
+		positions[request.ID] = i + 1
+	}
+	for _, request := range requests {
+		request.QueuePosition = positions[request.ID]
+	}
+	return nil
+}
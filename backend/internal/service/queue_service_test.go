@@ -0,0 +1,512 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/memory"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// queueRequest stores a queued request of a new user with the given
+// priority
+func (e *env) queueRequest(t *testing.T, name string, priority int) *domain.TrainingRequest {
+	t.Helper()
+
+	request := e.addRequest(t, e.addUser(t, name).ID, domain.RequestPending)
+	if err := e.requests.Enqueue(defaultCtx(), request.ID, domain.RequestPending, priority); err != nil {
+		t.Fatalf("enqueue request: %v", err)
+	}
+	return request
+}
+
+func TestQueueService_Dispatch(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	ann := e.addMentor(t, "ann", 4)
+	ben := e.addMentor(t, "ben", 5)
+	first := e.queueRequest(t, "alice", 0)
+	second := e.queueRequest(t, "bob", 0)
+	urgent := e.queueRequest(t, "carol", 3)
+
+	// ann has one free slot; it goes to the highest priority
+	started, err := e.queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	if len(started) != 1 || started[0].RequestID != urgent.ID || started[0].MentorID != ann.ID {
+		t.Fatalf("Dispatch started %d learnings, want the urgent request with ann", len(started))
+	}
+	if got := e.workload(t, ann.ID); got != 5 {
+		t.Errorf("workload of ann = %d, want 5", got)
+	}
+
+	queue, err := e.queue.GetQueue(ctx)
+	expectErr(t, err, nil)
+	if len(queue) != 2 || queue[0].ID != first.ID || queue[0].QueuePosition != 1 || queue[1].ID != second.ID || queue[1].QueuePosition != 2 {
+		t.Fatalf("queue after dispatch is out of order")
+	}
+
+	notifications, _ := e.notification.GetUserNotifications(ctx, urgent.UserID, false)
+	if len(notifications) != 1 || notifications[0].Kind != domain.NotificationRequestAssigned {
+		t.Errorf("learner got %d notifications, want one request_assigned", len(notifications))
+	}
+
+	// Nobody has capacity: nothing changes
+	started, err = e.queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	if len(started) != 0 {
+		t.Errorf("Dispatch without capacity started %d learnings", len(started))
+	}
+
+	// Two free slots serve the rest of the queue in FIFO order
+	if err := e.mentors.UpdateWorkload(ctx, ben.ID, 3); err != nil {
+		t.Fatalf("UpdateWorkload: %v", err)
+	}
+	started, err = e.queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	if len(started) != 2 || started[0].RequestID != first.ID || started[1].RequestID != second.ID {
+		t.Fatalf("Dispatch started %d learnings, want first and second in order", len(started))
+	}
+	request, _ := e.requests.GetByID(ctx, second.ID)
+	if request.Status != domain.RequestApproved {
+		t.Errorf("dispatched request status = %s, want approved", request.Status)
+	}
+}
+
+// teach sets the skills of a mentor
+func (e *env) teach(t *testing.T, mentor *domain.Mentor, skills ...string) {
+	t.Helper()
+
+	mentor.Skills = skills
+	if err := e.mentors.Update(defaultCtx(), mentor); err != nil {
+		t.Fatalf("set mentor skills: %v", err)
+	}
+}
+
+// retopic sets the topic and skill tags of a request
+func (e *env) retopic(t *testing.T, request *domain.TrainingRequest, topic string, skills ...string) {
+	t.Helper()
+
+	request.Topic = topic
+	request.Skills = skills
+	if err := e.requests.Update(defaultCtx(), request); err != nil {
+		t.Fatalf("set request topic: %v", err)
+	}
+}
+
+func TestQueueService_Dispatch_MatchesTopicAndSkills(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	gopher := e.addMentor(t, "gopher", 3)
+	rustacean := e.addMentor(t, "rustacean", 0)
+	anyone := e.addMentor(t, "anyone", 0)
+	e.teach(t, gopher, "go")
+	e.teach(t, rustacean, "Rust")
+	golang := e.queueRequest(t, "alice", 0)
+	tagged := e.queueRequest(t, "bob", 0)
+	e.retopic(t, tagged, "Memory safety", "rust")
+	other := e.queueRequest(t, "carol", 0)
+	e.retopic(t, other, "Kotlin")
+
+	started, err := e.queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	mentors := map[string]string{}
+	for _, learning := range started {
+		mentors[learning.RequestID] = learning.MentorID
+	}
+	if len(started) != 3 {
+		t.Fatalf("Dispatch started %d learnings, want 3", len(started))
+	}
+	// The topic and the skill tags match specialists even when others are
+	// less loaded; mentors without skills take what nobody else teaches
+	if mentors[golang.ID] != gopher.ID {
+		t.Errorf("Go request went to %s, want gopher", mentors[golang.ID])
+	}
+	if mentors[tagged.ID] != rustacean.ID {
+		t.Errorf("rust-tagged request went to %s, want rustacean", mentors[tagged.ID])
+	}
+	if mentors[other.ID] != anyone.ID {
+		t.Errorf("Kotlin request went to %s, want anyone", mentors[other.ID])
+	}
+}
+
+func TestQueueService_Dispatch_LeavesUnmatchedRequestsQueued(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	rustacean := e.addMentor(t, "rustacean", 0)
+	e.teach(t, rustacean, "Rust")
+	golang := e.queueRequest(t, "alice", 0)
+	rust := e.queueRequest(t, "bob", 0)
+	e.retopic(t, rust, "rust")
+
+	// The Go request waits for a mentor who teaches it; the one behind it
+	// is served meanwhile
+	started, err := e.queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	if len(started) != 1 || started[0].RequestID != rust.ID || started[0].MentorID != rustacean.ID {
+		t.Fatalf("Dispatch started %d learnings, want only the Rust request", len(started))
+	}
+	got, _ := e.requests.GetByID(ctx, golang.ID)
+	if !got.IsQueued() {
+		t.Errorf("unmatched request status = %s, want queued", got.Status)
+	}
+
+	// Teaching Go picks it up
+	_, err = e.mentor.UpdateMentor(ctx, rustacean.ID, rustacean.Name, rustacean.JobTitle, "", rustacean.Email, "", []string{"Rust", "Go"}, 1, 0)
+	expectErr(t, err, nil)
+	got, _ = e.requests.GetByID(ctx, golang.ID)
+	if got.Status != domain.RequestApproved {
+		t.Errorf("request status after mentor learned Go = %s, want approved", got.Status)
+	}
+}
+
+func TestQueueService_Dispatch_SkipsUnavailableMentors(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	away := e.addMentor(t, "away", 0)
+	gone := e.addMentor(t, "gone", 0)
+	e.absent(t, away)
+	e.deactivate(t, gone)
+	request := e.queueRequest(t, "alice", 0)
+
+	started, err := e.queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	if len(started) != 0 {
+		t.Fatalf("Dispatch assigned the request to an unavailable mentor")
+	}
+
+	got, _ := e.requests.GetByID(ctx, request.ID)
+	if !got.IsQueued() {
+		t.Errorf("request status = %s, want queued", got.Status)
+	}
+}
+
+func TestQueueService_Dispatch_SkipsFailingRequest(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	mentor := e.addMentor(t, "ann", 3)
+	broken := e.queueRequest(t, "alice", 5)
+	next := e.queueRequest(t, "bob", 0)
+
+	// A learning already exists for the head of the queue, so starting
+	// another one for it fails
+	other := e.addMentor(t, "ben", 5)
+	if err := e.learnings.Create(ctx, &domain.LearningProcess{
+		RequestID: broken.ID,
+		UserID:    broken.UserID,
+		MentorID:  other.ID,
+		Status:    domain.LearningActive,
+		StartDate: time.Now(),
+	}); err != nil {
+		t.Fatalf("create learning: %v", err)
+	}
+
+	started, err := e.queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	if len(started) != 1 || started[0].RequestID != next.ID {
+		t.Fatalf("Dispatch started %d learnings, want only the request behind the failing one", len(started))
+	}
+
+	got, _ := e.requests.GetByID(ctx, broken.ID)
+	if !got.IsQueued() {
+		t.Errorf("failing request status = %s, want it left queued", got.Status)
+	}
+	if got := e.workload(t, mentor.ID); got != 4 {
+		t.Errorf("workload = %d, want 4", got)
+	}
+}
+
+// staleQueue still lists requests that left the queue, as a dispatch sees
+// them when it read the queue just before they left
+type staleQueue struct {
+	*memory.RequestRepository
+	stale []*domain.TrainingRequest
+}
+
+func (r *staleQueue) GetQueue(ctx context.Context) ([]*domain.TrainingRequest, error) {
+	queue, err := r.RequestRepository.GetQueue(ctx)
+	return append(slices.Clone(r.stale), queue...), err
+}
+
+func TestQueueService_Dispatch_PassesOverRequestsThatLeftTheQueue(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	mentor := e.addMentor(t, "ann", 0)
+	dequeued := e.queueRequest(t, "alice", 5)
+	next := e.queueRequest(t, "bob", 0)
+	_, err := e.queue.Dequeue(ctx, dequeued.ID)
+	expectErr(t, err, nil)
+
+	requests := &staleQueue{RequestRepository: e.requests, stale: []*domain.TrainingRequest{dequeued}}
+	queue := service.NewQueueService(e.tx, requests, e.mentors, e.learnings, e.availabilities, e.notification, e.outbox)
+	started, err := queue.Dispatch(ctx)
+	expectErr(t, err, nil)
+	if len(started) != 1 || started[0].RequestID != next.ID {
+		t.Fatalf("Dispatch started %d learnings, want only the request still queued", len(started))
+	}
+
+	got, _ := e.requests.GetByID(ctx, dequeued.ID)
+	if !got.IsPending() {
+		t.Errorf("dequeued request status = %s, want it left pending", got.Status)
+	}
+	if got := e.workload(t, mentor.ID); got != 1 {
+		t.Errorf("workload = %d, want 1", got)
+	}
+}
+
+// overbookedMentors disagrees with the capacity it reports: every slot a
+// mentor seems to have is already taken when the dispatch goes for it
+type overbookedMentors struct {
+	*memory.MentorRepository
+	increments int
+}
+
+func (r *overbookedMentors) IncrementWorkload(ctx context.Context, id string, n int) error {
+	r.increments++
+	return domain.ErrMentorNotAvailable
+}
+
+func TestQueueService_Dispatch_GivesUpOnFullMentors(t *testing.T) {
+	e := newEnv(t)
+	e.addMentor(t, "ann", 0)
+	e.addMentor(t, "ben", 2)
+	request := e.queueRequest(t, "alice", 0)
+	mentors := &overbookedMentors{MentorRepository: e.mentors}
+	queue := service.NewQueueService(e.tx, e.requests, mentors, e.learnings, e.availabilities, e.notification, e.outbox)
+
+	done := make(chan error, 1)
+	go func() {
+		started, err := queue.Dispatch(defaultCtx())
+		if err == nil && len(started) != 0 {
+			err = fmt.Errorf("started %d learnings", len(started))
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		expectErr(t, err, nil)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Dispatch keeps retrying mentors that are full")
+	}
+
+	// Each mentor is tried once, then the request waits for the next dispatch
+	if mentors.increments != 2 {
+		t.Errorf("tried %d assignments, want one per mentor", mentors.increments)
+	}
+	got, _ := e.requests.GetByID(defaultCtx(), request.ID)
+	if !got.IsQueued() {
+		t.Errorf("request status = %s, want it left queued", got.Status)
+	}
+}
+
+func TestQueueService_Dispatch_StopsWhenCancelled(t *testing.T) {
+	e := newEnv(t)
+	mentor := e.addMentor(t, "ann", 0)
+	e.queueRequest(t, "alice", 0)
+	ctx, cancel := context.WithCancel(defaultCtx())
+	cancel()
+
+	started, err := e.queue.Dispatch(ctx)
+	expectErr(t, err, context.Canceled)
+	if len(started) != 0 || e.workload(t, mentor.ID) != 0 {
+		t.Errorf("cancelled Dispatch started %d learnings", len(started))
+	}
+}
+
+func TestQueueService_ServedWhenCapacityFreesUp(t *testing.T) {
+	tests := []struct {
+		name string
+		free func(t *testing.T, e *env, mentor *domain.Mentor, learning *domain.LearningProcess)
+	}{
+		{
+			name: "learning completed",
+			free: func(t *testing.T, e *env, _ *domain.Mentor, learning *domain.LearningProcess) {
+				_, err := e.learning.CompleteLearning(defaultCtx(), learning.ID, 5, "great")
+				expectErr(t, err, nil)
+			},
+		},
+		{
+			name: "learning closed by update",
+			free: func(t *testing.T, e *env, _ *domain.Mentor, learning *domain.LearningProcess) {
+				_, err := e.learning.UpdateLearning(defaultCtx(), learning.ID, "", "", domain.LearningCompleted, nil, nil, nil)
+				expectErr(t, err, nil)
+			},
+		},
+		{
+			name: "workload lowered",
+			free: func(t *testing.T, e *env, mentor *domain.Mentor, _ *domain.LearningProcess) {
+				_, err := e.mentor.UpdateMentor(defaultCtx(), mentor.ID, mentor.Name, mentor.JobTitle, "", mentor.Email, "", nil, 4, 0)
+				expectErr(t, err, nil)
+			},
+		},
+		{
+			name: "new mentor",
+			free: func(t *testing.T, e *env, _ *domain.Mentor, _ *domain.LearningProcess) {
+				_, err := e.mentor.CreateMentor(defaultCtx(), "zoe", "Engineer", "", "zoe@mentors.example.com", "", nil, 0)
+				expectErr(t, err, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			mentor := e.addMentor(t, "ann", 4)
+			learning := e.addLearning(t, e.addUser(t, "bob").ID, mentor, domain.LearningActive)
+			request := e.queueRequest(t, "alice", 0)
+
+			tt.free(t, e, mentor, learning)
+
+			got, _ := e.requests.GetByID(ctx, request.ID)
+			if got.Status != domain.RequestApproved {
+				t.Fatalf("queued request status = %s, want approved", got.Status)
+			}
+			learnings, _ := e.learnings.GetByUserID(ctx, request.UserID)
+			if len(learnings) != 1 || !learnings[0].IsActive() {
+				t.Errorf("queued request got %d learnings, want one active", len(learnings))
+			}
+		})
+	}
+}
+
+func TestQueueService_ServedWhenMentorReturns(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	ann := e.addMentor(t, "ann", 4)
+	ben := e.addMentor(t, "ben", 0)
+	absence := e.absent(t, ann)
+	e.deactivate(t, ben)
+	first := e.queueRequest(t, "alice", 0)
+	second := e.queueRequest(t, "bob", 0)
+
+	expectErr(t, e.availability.RemoveAbsence(ctx, ann.ID, absence.ID), nil)
+	_, err := e.mentor.ReactivateMentor(ctx, ben.ID)
+	expectErr(t, err, nil)
+
+	for _, request := range []*domain.TrainingRequest{first, second} {
+		got, _ := e.requests.GetByID(ctx, request.ID)
+		if got.Status != domain.RequestApproved {
+			t.Errorf("request %s status = %s, want approved", got.UserName, got.Status)
+		}
+	}
+	if e.workload(t, ann.ID) != 5 || e.workload(t, ben.ID) != 1 {
+		t.Errorf("workloads = %d, %d, want 5, 1", e.workload(t, ann.ID), e.workload(t, ben.ID))
+	}
+}
+
+func TestQueueService_Enqueue(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   domain.RequestStatus
+		missing  bool
+		priority int
+		wantErr  error
+	}{
+		{name: "pending request", status: domain.RequestPending, priority: 2},
+		{name: "queued request changes priority", status: domain.RequestQueued, priority: 7},
+		{name: "approved request", status: domain.RequestApproved, wantErr: domain.ErrRequestNotPending},
+		{name: "rejected request", status: domain.RequestRejected, wantErr: domain.ErrRequestNotPending},
+		{name: "missing request", missing: true, wantErr: domain.ErrRequestNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			e.addMentor(t, "ann", 5)
+			ahead := e.queueRequest(t, "bob", 5)
+			id := "missing"
+			if !tt.missing {
+				id = e.addRequest(t, e.addUser(t, "alice").ID, tt.status).ID
+			}
+
+			request, err := e.queue.Enqueue(ctx, id, tt.priority)
+			expectErr(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+
+			wantPosition := 2
+			if tt.priority > 5 {
+				wantPosition = 1
+			}
+			if !request.IsQueued() || request.Priority != tt.priority || request.QueuePosition != wantPosition {
+				t.Errorf("Enqueue returned status=%s priority=%d position=%d, want queued/%d/%d",
+					request.Status, request.Priority, request.QueuePosition, tt.priority, wantPosition)
+			}
+			if got, _ := e.request.GetRequestByID(ctx, ahead.ID); got.QueuePosition != 3-wantPosition {
+				t.Errorf("position of the other request = %d, want %d", got.QueuePosition, 3-wantPosition)
+			}
+		})
+	}
+}
+
+func TestQueueService_EnqueueServesFreeMentor(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	mentor := e.addMentor(t, "ann", 0)
+	pending := e.addRequest(t, e.addUser(t, "alice").ID, domain.RequestPending)
+
+	request, err := e.queue.Enqueue(ctx, pending.ID, 0)
+	expectErr(t, err, nil)
+	if request.Status != domain.RequestApproved || request.QueuePosition != 0 {
+		t.Errorf("request = %s at position %d, want approved right away", request.Status, request.QueuePosition)
+	}
+	if got := e.workload(t, mentor.ID); got != 1 {
+		t.Errorf("workload = %d, want 1", got)
+	}
+}
+
+func TestQueueService_Dequeue(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	queued := e.queueRequest(t, "alice", 0)
+	pending := e.addRequest(t, queued.UserID, domain.RequestPending)
+
+	request, err := e.queue.Dequeue(ctx, queued.ID)
+	expectErr(t, err, nil)
+	if !request.IsPending() || request.QueuePosition != 0 {
+		t.Errorf("dequeued request = %s at position %d, want pending", request.Status, request.QueuePosition)
+	}
+
+	_, err = e.queue.Dequeue(ctx, pending.ID)
+	expectErr(t, err, domain.ErrRequestNotQueued)
+	_, err = e.queue.Dequeue(ctx, "missing")
+	expectErr(t, err, domain.ErrRequestNotFound)
+}
+
+func TestRequestService_QueuePositions(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	e.queueRequest(t, "bob", 0)
+	alice := e.addUser(t, "alice")
+	pending := e.addRequest(t, alice.ID, domain.RequestPending)
+	queued := e.addRequest(t, alice.ID, domain.RequestPending)
+	if err := e.requests.Enqueue(ctx, queued.ID, domain.RequestPending, 0); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	requests, err := e.request.GetUserRequests(ctx, alice.ID)
+	expectErr(t, err, nil)
+	positions := map[string]int{}
+	for _, r := range requests {
+		positions[r.ID] = r.QueuePosition
+	}
+	if positions[queued.ID] != 2 || positions[pending.ID] != 0 {
+		t.Errorf("queue positions = %v, want 2 for the queued request and 0 otherwise", positions)
+	}
+
+	// An admin may still assign a queued request by hand
+	mentor := e.addMentor(t, "ann", 0)
+	_, err = e.request.AssignMentor(ctx, queued.ID, mentor.ID)
+	expectErr(t, err, nil)
+	got, err := e.request.GetRequestByID(ctx, queued.ID)
+	expectErr(t, err, nil)
+	if got.Status != domain.RequestApproved || got.QueuePosition != 0 {
+		t.Errorf("assigned request = %s at position %d", got.Status, got.QueuePosition)
+	}
+}
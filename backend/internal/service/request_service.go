@@ -3,70 +3,107 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 )
 
 type RequestService struct {
-	requestRepo  domain.RequestRepository
-	userRepo     domain.UserRepository
-	mentorRepo   domain.MentorRepository
-	learningRepo domain.LearningRepository
+	tx               domain.TxManager
+	requestRepo      domain.RequestRepository
+	userRepo         domain.UserRepository
+	mentorRepo       domain.MentorRepository
+	learningRepo     domain.LearningRepository
+	availabilityRepo domain.AvailabilityRepository
+	approvals        *ApprovalService
+	outbox           *OutboxService
 }
 
 func NewRequestService(
+	tx domain.TxManager,
 	requestRepo domain.RequestRepository,
 	userRepo domain.UserRepository,
 	mentorRepo domain.MentorRepository,
 	learningRepo domain.LearningRepository,
+	availabilityRepo domain.AvailabilityRepository,
+	approvals *ApprovalService,
+	outbox *OutboxService,
 ) *RequestService {
 	return &RequestService{
-		requestRepo:  requestRepo,
-		userRepo:     userRepo,
-		mentorRepo:   mentorRepo,
-		learningRepo: learningRepo,
+		tx:               tx,
+		requestRepo:      requestRepo,
+		userRepo:         userRepo,
+		mentorRepo:       mentorRepo,
+		learningRepo:     learningRepo,
+		availabilityRepo: availabilityRepo,
+		approvals:        approvals,
+		outbox:           outbox,
 	}
 }
 
-// CreateRequest creates a new training request
-func (s *RequestService) CreateRequest(ctx context.Context, userID, topic, description string) (*domain.TrainingRequest, error) {
-	// Verify user exists
-	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+// CreateRequest creates a new training request at the first stage of the
+// approval chain
+func (s *RequestService) CreateRequest(ctx context.Context, userID, topic, description string, skills []string) (*domain.TrainingRequest, error) {
+	chain, err := s.approvals.chainFor(ctx, userID, false)
+	if err != nil {
+		return nil, err
 	}
 
 	request := &domain.TrainingRequest{
-		UserID:      userID,
-		Topic:       topic,
-		Description: description,
-		Status:      domain.RequestPending,
+		UserID:        userID,
+		Topic:         topic,
+		Description:   description,
+		Skills:        skills,
+		ApprovalChain: chain,
 	}
 
-	if err := s.requestRepo.Create(ctx, request); err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if err := s.approvals.submit(ctx, request); err != nil {
+		return nil, err
 	}
 
-	return request, nil
+	// Reload to get full data with JOINs
+	return s.GetRequestByID(ctx, request.ID)
 }
 
-// GetAllRequests retrieves all training requests with optional status filter
-func (s *RequestService) GetAllRequests(ctx context.Context, status *string) ([]*domain.TrainingRequest, error) {
-	return s.requestRepo.GetAll(ctx, status)
+// GetAllRequests retrieves the training requests of the users in scope
+// with optional status filter
+func (s *RequestService) GetAllRequests(ctx context.Context, status *string, scope domain.Scope) ([]*domain.TrainingRequest, error) {
+	requests, err := s.requestRepo.GetAll(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	inScope, err := usersIn(ctx, s.userRepo, scope)
+	if err != nil {
+		return nil, err
+	}
+	if inScope != nil {
+		requests = slices.DeleteFunc(requests, func(r *domain.TrainingRequest) bool { return !inScope[r.UserID] })
+	}
+	return requests, setQueuePositions(ctx, s.requestRepo, requests...)
 }
 
 // GetUserRequests retrieves all requests for a specific user
 func (s *RequestService) GetUserRequests(ctx context.Context, userID string) ([]*domain.TrainingRequest, error) {
-	return s.requestRepo.GetByUserID(ctx, userID)
+	requests, err := s.requestRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return requests, setQueuePositions(ctx, s.requestRepo, requests...)
 }
 
 // GetRequestByID retrieves a specific request by ID
 func (s *RequestService) GetRequestByID(ctx context.Context, id string) (*domain.TrainingRequest, error) {
-	return s.requestRepo.GetByID(ctx, id)
+	request, err := s.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return request, setQueuePositions(ctx, s.requestRepo, request)
 }
 
-// UpdateRequest updates an existing training request
-func (s *RequestService) UpdateRequest(ctx context.Context, id string, topic, description string) (*domain.TrainingRequest, error) {
+// UpdateRequest updates an existing training request; nil skills keep the
+// current skill tags
+func (s *RequestService) UpdateRequest(ctx context.Context, id string, topic, description string, skills []string) (*domain.TrainingRequest, error) {
 	request, err := s.requestRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -75,15 +112,19 @@ func (s *RequestService) UpdateRequest(ctx context.Context, id string, topic, de
 	// Update fields
 	request.Topic = topic
 	request.Description = description
+	if skills != nil {
+		request.Skills = domain.CleanSkills(skills)
+	}
 
 	if err := s.requestRepo.Update(ctx, request); err != nil {
 		return nil, fmt.Errorf("failed to update request: %w", err)
 	}
 
-	return request, nil
+	return request, setQueuePositions(ctx, s.requestRepo, request)
 }
 
-// AssignMentor assigns a mentor to a request and creates learning process
+// AssignMentor assigns a mentor to a pending or queued request and creates
+// learning process
 func (s *RequestService) AssignMentor(ctx context.Context, requestID, mentorID string) (*domain.LearningProcess, error) {
 	// Get request
 	request, err := s.requestRepo.GetByID(ctx, requestID)
@@ -91,9 +132,12 @@ func (s *RequestService) AssignMentor(ctx context.Context, requestID, mentorID s
 		return nil, err
 	}
 
-	// Check if request is pending
-	if request.Status != domain.RequestPending {
-		return nil, fmt.Errorf("request is not pending")
+	// Check if request is pending; a queued request may be assigned by hand
+	if !request.IsPending() && !request.IsQueued() {
+		return nil, domain.ErrRequestNotPending
+	}
+	if request.IsCourseEnrollment() {
+		return nil, domain.ErrCourseRequest
 	}
 
 	// Get mentor
@@ -102,17 +146,19 @@ func (s *RequestService) AssignMentor(ctx context.Context, requestID, mentorID s
 		return nil, fmt.Errorf("mentor not found: %w", err)
 	}
 
-	// Check mentor workload
-	if mentor.Workload >= 5 {
-		return nil, fmt.Errorf("mentor has reached maximum workload")
+	if !mentor.IsActive() {
+		return nil, domain.ErrMentorDeactivated
+	}
+	if err := ensurePresent(ctx, s.availabilityRepo, mentorID); err != nil {
+		return nil, err
 	}
 
-	// Approve request
-	if err := s.requestRepo.UpdateStatus(ctx, requestID, string(domain.RequestApproved)); err != nil {
-		return nil, fmt.Errorf("failed to approve request: %w", err)
+	// Check mentor workload
+	if !mentor.CanTakeStudent() {
+		return nil, fmt.Errorf("mentor has reached maximum workload")
 	}
 
-	// Create learning process
+	// Approve the request and start its learning together
 	learning := &domain.LearningProcess{
 		RequestID: requestID,
 		UserID:    request.UserID,
@@ -122,16 +168,24 @@ func (s *RequestService) AssignMentor(ctx context.Context, requestID, mentorID s
 		Plan:      []domain.LearningPlanItem{},
 		Notes:     nil,
 	}
-
-	if err := s.learningRepo.Create(ctx, learning); err != nil {
-		// Rollback request status
-		_ = s.requestRepo.UpdateStatus(ctx, requestID, string(domain.RequestPending))
-		return nil, fmt.Errorf("failed to create learning process: %w", err)
-	}
-
-	// Update mentor workload
-	if err := s.mentorRepo.UpdateWorkload(ctx, mentorID, mentor.Workload+1); err != nil {
-		return nil, fmt.Errorf("failed to update mentor workload: %w", err)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.requestRepo.UpdateStatus(ctx, requestID, request.Status, domain.RequestApproved); err != nil {
+			return fmt.Errorf("failed to approve request: %w", err)
+		}
+
+		if err := s.learningRepo.Create(ctx, learning); err != nil {
+			return fmt.Errorf("failed to create learning process: %w", err)
+		}
+
+		// Update mentor workload
+		if err := s.mentorRepo.IncrementWorkload(ctx, mentorID, 1); err != nil {
+			return fmt.Errorf("failed to update mentor workload: %w", err)
+		}
+
+		return s.outbox.Publish(ctx, domain.EventLearningStarted, learningEvent(learning))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload to get full data with JOINs
@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func TestRequestService_CreateRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing bool
+		wantErr error
+	}{
+		{name: "existing user"},
+		{name: "missing user", missing: true, wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			userID := e.addUser(t, "alice").ID
+			if tt.missing {
+				userID = "missing"
+			}
+
+			request, err := e.request.CreateRequest(defaultCtx(), userID, "Go", "Generics", nil)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if request.ID == "" || request.Status != domain.RequestPending {
+				t.Errorf("CreateRequest returned %+v", request)
+			}
+		})
+	}
+}
+
+func TestRequestService_Getters(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	pending := e.addRequest(t, alice.ID, domain.RequestPending)
+	e.addRequest(t, bob.ID, domain.RequestApproved)
+
+	all, err := e.request.GetAllRequests(ctx, nil, domain.Scope{All: true})
+	if err != nil || len(all) != 2 {
+		t.Errorf("GetAllRequests returned %d requests, %v", len(all), err)
+	}
+	status := string(domain.RequestPending)
+	filtered, err := e.request.GetAllRequests(ctx, &status, domain.Scope{All: true})
+	if err != nil || len(filtered) != 1 || filtered[0].ID != pending.ID {
+		t.Errorf("GetAllRequests(pending) returned %d requests, %v", len(filtered), err)
+	}
+	mine, err := e.request.GetUserRequests(ctx, alice.ID)
+	if err != nil || len(mine) != 1 || mine[0].UserName != "alice" {
+		t.Errorf("GetUserRequests returned %d requests, %v", len(mine), err)
+	}
+	got, err := e.request.GetRequestByID(ctx, pending.ID)
+	if err != nil || got.ID != pending.ID {
+		t.Errorf("GetRequestByID = %+v, %v", got, err)
+	}
+	if _, err := e.request.GetRequestByID(ctx, "missing"); err == nil {
+		t.Error("GetRequestByID for a missing request succeeded")
+	}
+}
+
+func TestRequestService_UpdateRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing bool
+		wantErr error
+	}{
+		{name: "existing request"},
+		{name: "missing request", missing: true, wantErr: domain.ErrRequestNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			id := e.addRequest(t, e.addUser(t, "alice").ID, domain.RequestPending).ID
+			if tt.missing {
+				id = "missing"
+			}
+
+			_, err := e.request.UpdateRequest(defaultCtx(), id, "Rust", "Ownership", nil)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			stored, _ := e.requests.GetByID(defaultCtx(), id)
+			if stored.Topic != "Rust" || stored.Description != "Ownership" {
+				t.Errorf("stored request = %+v", stored)
+			}
+		})
+	}
+}
+
+func TestRequestService_AssignMentor(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        domain.RequestStatus
+		workload      int
+		missingMentor bool
+		deactivated   bool
+		absent        bool
+		wantErr       error
+		wantWorkload  int
+	}{
+		{name: "idle mentor", status: domain.RequestPending, workload: 0, wantWorkload: 1},
+		{name: "last free slot", status: domain.RequestPending, workload: 4, wantWorkload: 5},
+		{name: "full mentor", status: domain.RequestPending, workload: 5, wantErr: errAny, wantWorkload: 5},
+		{name: "already approved", status: domain.RequestApproved, workload: 0, wantErr: errAny, wantWorkload: 0},
+		{name: "rejected", status: domain.RequestRejected, workload: 0, wantErr: errAny, wantWorkload: 0},
+		{name: "missing mentor", status: domain.RequestPending, missingMentor: true, wantErr: domain.ErrMentorNotFound},
+		{name: "deactivated mentor", status: domain.RequestPending, deactivated: true, wantErr: domain.ErrMentorDeactivated, wantWorkload: 0},
+		{name: "absent mentor", status: domain.RequestPending, absent: true, wantErr: domain.ErrMentorAbsent, wantWorkload: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			request := e.addRequest(t, e.addUser(t, "alice").ID, tt.status)
+			mentor := e.addMentor(t, "ann", tt.workload)
+			if tt.deactivated {
+				e.deactivate(t, mentor)
+			}
+			if tt.absent {
+				e.absent(t, mentor)
+			}
+			mentorID := mentor.ID
+			if tt.missingMentor {
+				mentorID = "missing"
+			}
+
+			learning, err := e.request.AssignMentor(ctx, request.ID, mentorID)
+			expectErr(t, err, tt.wantErr)
+
+			if !tt.missingMentor {
+				if got := e.workload(t, mentor.ID); got != tt.wantWorkload {
+					t.Errorf("workload = %d, want %d", got, tt.wantWorkload)
+				}
+			}
+			stored, _ := e.requests.GetByID(ctx, request.ID)
+			if err != nil {
+				if stored.Status != tt.status {
+					t.Errorf("request status changed to %s on failure", stored.Status)
+				}
+				return
+			}
+
+			if stored.Status != domain.RequestApproved {
+				t.Errorf("request status = %s, want approved", stored.Status)
+			}
+			if learning.MentorID != mentor.ID || learning.Status != domain.LearningActive || learning.MentorName != "ann" {
+				t.Errorf("AssignMentor returned %+v", learning)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// RoleService manages the roles users are assigned. Built-in roles can be
+// tuned but not deleted, except for admin, which always grants every
+// permission so that somebody can repair a broken setup. Roles are assigned
+// through UserService.ChangeRole.
+type RoleService struct {
+	tx       domain.TxManager
+	roleRepo domain.RoleRepository
+	userRepo domain.UserRepository
+}
+
+func NewRoleService(
+	tx domain.TxManager,
+	roleRepo domain.RoleRepository,
+	userRepo domain.UserRepository,
+) *RoleService {
+	return &RoleService{
+		tx:       tx,
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// ListRoles lists all roles, built-in ones first
+func (s *RoleService) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	return s.roleRepo.GetAll(ctx)
+}
+
+// GetRole retrieves a role by its name
+func (s *RoleService) GetRole(ctx context.Context, name domain.UserRole) (*domain.Role, error) {
+	return s.roleRepo.GetByName(ctx, name)
+}
+
+// CreateRole defines a custom role; its permissions apply organisation-wide
+// unless scope says otherwise
+func (s *RoleService) CreateRole(ctx context.Context, name domain.UserRole, description string, permissions []domain.Permission, scope domain.RoleScope) (*domain.Role, error) {
+	if !name.IsValid() {
+		return nil, fmt.Errorf("%w: role names are lowercase letters, digits and underscores, starting with a letter", domain.ErrInvalidInput)
+	}
+	permissions, err := cleanPermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	scope, err = cleanScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &domain.Role{
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Permissions: permissions,
+		Scope:       scope,
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole replaces the description, permissions and scope of a role. The
+// admin role cannot be changed, and the last active users who can manage
+// roles keep that permission.
+func (s *RoleService) UpdateRole(ctx context.Context, name domain.UserRole, description string, permissions []domain.Permission, scope domain.RoleScope) (*domain.Role, error) {
+	if name == domain.RoleAdmin {
+		return nil, domain.ErrRoleProtected
+	}
+	permissions, err := cleanPermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	scope, err = cleanScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var role *domain.Role
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		role, err = s.roleRepo.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		managedRoles := role.Has(domain.PermRolesManage) && role.Scope == domain.ScopeOrganization
+		managesRoles := slices.Contains(permissions, domain.PermRolesManage) && scope == domain.ScopeOrganization
+		revokesRoles := managedRoles && !managesRoles
+		role.Description = strings.TrimSpace(description)
+		role.Permissions = permissions
+		role.Scope = scope
+		if err := s.roleRepo.Update(ctx, role); err != nil {
+			return err
+		}
+		if revokesRoles {
+			return s.ensureRoleManager(ctx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole removes a custom role nobody is assigned
+func (s *RoleService) DeleteRole(ctx context.Context, name domain.UserRole) error {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return domain.ErrRoleProtected
+	}
+	return s.roleRepo.Delete(ctx, name)
+}
+
+// ensureRoleManager fails with ErrLastAdmin unless an active user can still
+// manage roles
+func (s *RoleService) ensureRoleManager(ctx context.Context) error {
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, u := range users {
+		if u.IsActive() && u.Can(domain.PermRolesManage) {
+			return nil
+		}
+	}
+	return domain.ErrLastAdmin
+}
+
+// cleanScope checks the scope of a role; organisation-wide is the default
+func cleanScope(scope domain.RoleScope) (domain.RoleScope, error) {
+	if scope == "" {
+		return domain.ScopeOrganization, nil
+	}
+	if !scope.IsValid() {
+		return "", fmt.Errorf("%w: unknown scope %q", domain.ErrInvalidInput, scope)
+	}
+	return scope, nil
+}
+
+// cleanPermissions checks that the permissions are known and puts them in
+// the order of domain.Permissions without duplicates
+func cleanPermissions(permissions []domain.Permission) ([]domain.Permission, error) {
+	for _, p := range permissions {
+		if !p.IsValid() {
+			return nil, fmt.Errorf("%w: unknown permission %q", domain.ErrInvalidInput, p)
+		}
+	}
+	cleaned := make([]domain.Permission, 0, len(permissions))
+	for _, p := range domain.Permissions {
+		if slices.Contains(permissions, p) {
+			cleaned = append(cleaned, p)
+		}
+	}
+	return cleaned, nil
+}
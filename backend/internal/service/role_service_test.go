@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func TestRoleService_ListRoles(t *testing.T) {
+	e := newEnv(t)
+
+	roles, err := e.role.ListRoles(defaultCtx())
+	expectErr(t, err, nil)
+	var names []domain.UserRole
+	for _, r := range roles {
+		names = append(names, r.Name)
+	}
+	want := []domain.UserRole{
+		domain.RoleAdmin, domain.RoleLDManager, domain.RoleDepartmentHead, domain.RoleMentor, domain.RoleEmployee,
+	}
+	if !slices.Equal(names, want) {
+		t.Fatalf("roles %v, want %v", names, want)
+	}
+	if !slices.Equal(roles[0].Permissions, domain.Permissions) {
+		t.Errorf("admin permissions %v", roles[0].Permissions)
+	}
+}
+
+func TestRoleService_CreateRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        domain.UserRole
+		permissions []domain.Permission
+		want        []domain.Permission
+		wantErr     error
+	}{
+		{
+			name:        "sorted and deduplicated",
+			role:        "auditor",
+			permissions: []domain.Permission{domain.PermAnalyticsView, domain.PermUsersView, domain.PermAnalyticsView},
+			want:        []domain.Permission{domain.PermUsersView, domain.PermAnalyticsView},
+		},
+		{name: "no permissions", role: "guest", want: []domain.Permission{}},
+		{name: "invalid name", role: "Auditor", wantErr: domain.ErrInvalidInput},
+		{name: "unknown permission", role: "auditor", permissions: []domain.Permission{"users.delete"}, wantErr: domain.ErrInvalidInput},
+		{name: "existing role", role: domain.RoleMentor, wantErr: domain.ErrRoleExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+
+			role, err := e.role.CreateRole(ctx, tt.role, " Read only ", tt.permissions, "")
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			stored, err := e.role.GetRole(ctx, tt.role)
+			expectErr(t, err, nil)
+			if stored.BuiltIn || stored.Description != "Read only" || !slices.Equal(stored.Permissions, tt.want) {
+				t.Errorf("stored role %+v", stored)
+			}
+			if !slices.Equal(role.Permissions, tt.want) {
+				t.Errorf("returned permissions %v", role.Permissions)
+			}
+		})
+	}
+}
+
+func TestRoleService_UpdateRole(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+
+	role, err := e.role.UpdateRole(ctx, domain.RoleEmployee, "Everybody", []domain.Permission{domain.PermCoursesManage}, "")
+	expectErr(t, err, nil)
+	if !slices.Equal(role.Permissions, []domain.Permission{domain.PermCoursesManage}) {
+		t.Fatalf("permissions %v", role.Permissions)
+	}
+
+	// Users pick up the change on their next read
+	stored, _ := e.users.GetByID(ctx, alice.ID)
+	if !stored.Can(domain.PermCoursesManage) || stored.Can(domain.PermUsersView) {
+		t.Errorf("alice permissions %v", stored.Permissions)
+	}
+
+	_, err = e.role.UpdateRole(ctx, domain.RoleAdmin, "", nil, "")
+	expectErr(t, err, domain.ErrRoleProtected)
+	_, err = e.role.UpdateRole(ctx, "owner", "", nil, "")
+	expectErr(t, err, domain.ErrRoleNotFound)
+	_, err = e.role.UpdateRole(ctx, domain.RoleMentor, "", []domain.Permission{"everything"}, "")
+	expectErr(t, err, domain.ErrInvalidInput)
+}
+
+func TestRoleService_UpdateRoleKeepsRoleManager(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+
+	_, err := e.role.CreateRole(ctx, "security", "", []domain.Permission{domain.PermRolesManage}, "")
+	expectErr(t, err, nil)
+	alice := e.addUser(t, "alice")
+	_, err = e.user.ChangeRole(ctx, alice.ID, "security")
+	expectErr(t, err, nil)
+
+	// alice is the only one left who could give the permission back
+	_, err = e.role.UpdateRole(ctx, "security", "", nil, "")
+	expectErr(t, err, domain.ErrLastAdmin)
+	role, _ := e.role.GetRole(ctx, "security")
+	if !role.Has(domain.PermRolesManage) {
+		t.Error("failed update was not rolled back")
+	}
+
+	bob := e.addUser(t, "bob")
+	_, err = e.user.ChangeRole(ctx, bob.ID, domain.RoleAdmin)
+	expectErr(t, err, nil)
+	_, err = e.role.UpdateRole(ctx, "security", "", nil, "")
+	expectErr(t, err, nil)
+}
+
+func TestRoleService_DeleteRole(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+
+	_, err := e.role.CreateRole(ctx, "auditor", "", nil, "")
+	expectErr(t, err, nil)
+	alice := e.addUser(t, "alice")
+	_, err = e.user.ChangeRole(ctx, alice.ID, "auditor")
+	expectErr(t, err, nil)
+
+	expectErr(t, e.role.DeleteRole(ctx, "auditor"), domain.ErrRoleInUse)
+	expectErr(t, e.role.DeleteRole(ctx, domain.RoleMentor), domain.ErrRoleProtected)
+	expectErr(t, e.role.DeleteRole(ctx, "owner"), domain.ErrRoleNotFound)
+
+	_, err = e.user.ChangeRole(ctx, alice.ID, domain.RoleEmployee)
+	expectErr(t, err, nil)
+	expectErr(t, e.role.DeleteRole(ctx, "auditor"), nil)
+	_, err = e.role.GetRole(ctx, "auditor")
+	expectErr(t, err, domain.ErrRoleNotFound)
+}
+
+func TestUserService_ChangeRoleKeepsRoleManager(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	_, err := e.user.ChangeRole(ctx, alice.ID, domain.RoleAdmin)
+	expectErr(t, err, nil)
+
+	_, err = e.user.ChangeRole(ctx, alice.ID, domain.RoleLDManager)
+	expectErr(t, err, domain.ErrLastAdmin)
+
+	_, err = e.role.CreateRole(ctx, "security", "", []domain.Permission{domain.PermRolesManage}, "")
+	expectErr(t, err, nil)
+	_, err = e.user.ChangeRole(ctx, alice.ID, "security")
+	expectErr(t, err, nil)
+
+	_, err = e.user.ChangeRole(ctx, alice.ID, domain.RoleLDManager)
+	expectErr(t, err, domain.ErrLastAdmin)
+	bob := e.addUser(t, "bob")
+	_, err = e.user.ChangeRole(ctx, bob.ID, domain.RoleAdmin)
+	expectErr(t, err, nil)
+	user, err := e.user.ChangeRole(ctx, alice.ID, domain.RoleLDManager)
+	expectErr(t, err, nil)
+	if !user.Can(domain.PermCoursesManage) || user.Can(domain.PermRolesManage) {
+		t.Errorf("ld_manager permissions %v", user.Permissions)
+	}
+}
@@ -0,0 +1,788 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/scim"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SCIMMaxResults caps the page size of SCIM queries
+const SCIMMaxResults = 200
+
+// Group IDs carry the role or department name, so that groups need no
+// storage of their own beyond the roles and departments
+const (
+	roleGroupPrefix       = "role-"
+	departmentGroupPrefix = "department-"
+)
+
+// SCIMService provisions users and groups for identity providers over SCIM
+// 2.0. Users map to accounts keyed by userName, the sign-in email. Groups
+// are derived from the roles and departments: one per role ("role:admin",
+// "role:ld_manager", ...) and one per department, so group membership sets
+// roles and departments. Departments the identity provider names are
+// created as top-level departments.
+type SCIMService struct {
+	tx             domain.TxManager
+	userRepo       domain.UserRepository
+	roleRepo       domain.RoleRepository
+	departmentRepo domain.DepartmentRepository
+	userService    *UserService
+}
+
+func NewSCIMService(
+	tx domain.TxManager,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	departmentRepo domain.DepartmentRepository,
+	userService *UserService,
+) *SCIMService {
+	return &SCIMService{
+		tx:             tx,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		departmentRepo: departmentRepo,
+		userService:    userService,
+	}
+}
+
+// ListUsers returns a page of the users matching the filter, oldest first;
+// startIndex is 1-based
+func (s *SCIMService) ListUsers(ctx context.Context, filter string, startIndex, count int) (*scim.ListResponse[scim.User], error) {
+	match, err := parseSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(users)
+	byID := make(map[string]*domain.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	var resources []scim.User
+	for _, u := range users {
+		resource := scimUser(u, byID)
+		ok, err := matches(match, resource)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resources = append(resources, resource)
+		}
+	}
+	startIndex, count = scimPage(startIndex, count)
+	return scim.NewListResponse(resources, startIndex, count), nil
+}
+
+// GetUser returns a user
+func (s *SCIMService) GetUser(ctx context.Context, id string) (*scim.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.userResource(ctx, user)
+}
+
+// CreateUser provisions an account. Without a password it gets a random one;
+// the user signs in once a password is set, e.g. by a password reset.
+func (s *SCIMService) CreateUser(ctx context.Context, resource scim.User) (*scim.User, error) {
+	fields, err := userFields(resource)
+	if err != nil {
+		return nil, err
+	}
+	if fields.password == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate password: %w", err)
+		}
+		fields.password = hex.EncodeToString(random)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(fields.password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var created *domain.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkUnique(ctx, "", fields); err != nil {
+			return err
+		}
+		if fields.managerID != nil {
+			manager, err := s.userRepo.GetByID(ctx, *fields.managerID)
+			if errors.Is(err, domain.ErrUserNotFound) {
+				return fmt.Errorf("%w: manager %s does not exist", domain.ErrInvalidInput, *fields.managerID)
+			}
+			if err != nil {
+				return err
+			}
+			if !manager.IsActive() {
+				return fmt.Errorf("manager: %w", domain.ErrUserDeactivated)
+			}
+		}
+
+		user := &domain.User{
+			PasswordHash: string(hashedPassword),
+			Role:         domain.RoleEmployee,
+		}
+		if err := s.applyFields(ctx, user, fields); err != nil {
+			return err
+		}
+		user.ManagerID = fields.managerID
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if fields.active != nil && !*fields.active {
+			now := time.Now()
+			if err := s.userRepo.UpdateDeactivatedAt(ctx, user.ID, &now); err != nil {
+				return fmt.Errorf("failed to deactivate user: %w", err)
+			}
+		}
+		created = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, created.ID)
+}
+
+// ReplaceUser sets every attribute of a user; attributes left out are
+// cleared, except active and the password, which stay as they are
+func (s *SCIMService) ReplaceUser(ctx context.Context, id string, resource scim.User) (*scim.User, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.replaceUser(ctx, user, resource)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, id)
+}
+
+// PatchUser applies PATCH operations to a user
+func (s *SCIMService) PatchUser(ctx context.Context, id string, ops []scim.PatchOperation) (*scim.User, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		current, err := s.userResource(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		var patched scim.User
+		if err := patchResource(current, ops, &patched); err != nil {
+			return err
+		}
+		if patched.ID != user.ID {
+			return fmt.Errorf("%w: %w: id", domain.ErrInvalidInput, scim.ErrMutability)
+		}
+		return s.replaceUser(ctx, user, patched)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, id)
+}
+
+// DeprovisionUser deactivates a user: they can no longer sign in, and their
+// requests and learnings are kept
+func (s *SCIMService) DeprovisionUser(ctx context.Context, id string) error {
+	_, err := s.userService.DeactivateUser(ctx, id, "")
+	return err
+}
+
+// replaceUser writes the attributes of a resource to a user
+func (s *SCIMService) replaceUser(ctx context.Context, user *domain.User, resource scim.User) error {
+	fields, err := userFields(resource)
+	if err != nil {
+		return err
+	}
+	if err := s.checkUnique(ctx, user.ID, fields); err != nil {
+		return err
+	}
+
+	if err := s.applyFields(ctx, user, fields); err != nil {
+		return err
+	}
+	if fields.password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(fields.password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		user.PasswordHash = string(hashedPassword)
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if orEmpty(fields.managerID) != orEmpty(user.ManagerID) {
+		if _, err := s.userService.SetManager(ctx, user.ID, fields.managerID); err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				return fmt.Errorf("%w: manager %s does not exist", domain.ErrInvalidInput, *fields.managerID)
+			}
+			return err
+		}
+	}
+
+	if fields.active != nil && *fields.active != user.IsActive() {
+		if *fields.active {
+			_, err = s.userService.ReactivateUser(ctx, user.ID)
+		} else {
+			_, err = s.userService.DeactivateUser(ctx, user.ID, "")
+		}
+	}
+	return err
+}
+
+// checkUnique checks that the email and external ID of a user are free
+func (s *SCIMService) checkUnique(ctx context.Context, id string, fields scimUserFields) error {
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.ID == id {
+			continue
+		}
+		if strings.EqualFold(u.Email, fields.email) {
+			return fmt.Errorf("%w: userName %s is taken", domain.ErrUserAlreadyExists, fields.email)
+		}
+		if fields.externalID != nil && orEmpty(u.ExternalID) == *fields.externalID {
+			return fmt.Errorf("%w: externalId %s is taken", domain.ErrUserAlreadyExists, *fields.externalID)
+		}
+	}
+	return nil
+}
+
+// userResource converts a user, looking up their manager
+func (s *SCIMService) userResource(ctx context.Context, user *domain.User) (*scim.User, error) {
+	byID := map[string]*domain.User{user.ID: user}
+	if user.ManagerID != nil {
+		manager, err := s.userRepo.GetByID(ctx, *user.ManagerID)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		if manager != nil {
+			byID[manager.ID] = manager
+		}
+	}
+	resource := scimUser(user, byID)
+	return &resource, nil
+}
+
+// ListGroups returns a page of the groups matching the filter: the role
+// groups, then the departments by name
+func (s *SCIMService) ListGroups(ctx context.Context, filter string, startIndex, count int) (*scim.ListResponse[scim.Group], error) {
+	match, err := parseSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]scimGroup, 0, len(roles))
+	for _, r := range roles {
+		groups = append(groups, scimGroup{role: r.Name})
+	}
+	departments, err := s.departmentRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range departments {
+		groups = append(groups, scimGroup{department: d.Name, departmentID: d.ID})
+	}
+
+	var resources []scim.Group
+	for _, group := range groups {
+		resource := group.resource(users)
+		ok, err := matches(match, resource)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resources = append(resources, resource)
+		}
+	}
+	startIndex, count = scimPage(startIndex, count)
+	return scim.NewListResponse(resources, startIndex, count), nil
+}
+
+// GetGroup returns a group. A department group exists for every
+// department, with no members when nobody is in it.
+func (s *SCIMService) GetGroup(ctx context.Context, id string) (*scim.Group, error) {
+	group, err := s.group(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resource := group.resource(users)
+	return &resource, nil
+}
+
+// CreateGroup creates a department group: the department is created and its
+// members are moved to it. Role groups exist already.
+func (s *SCIMService) CreateGroup(ctx context.Context, resource scim.Group) (*scim.Group, error) {
+	name := strings.TrimSpace(resource.DisplayName)
+	if name == "" {
+		return nil, fmt.Errorf("%w: displayName is required", domain.ErrInvalidInput)
+	}
+	if strings.HasPrefix(name, "role:") {
+		if _, err := s.group(ctx, roleGroupPrefix+strings.TrimPrefix(name, "role:")); err == nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrGroupExists, name)
+		}
+		return nil, fmt.Errorf("%w: %s is not a role; names starting with role: are reserved", domain.ErrInvalidInput, name)
+	}
+
+	var group scimGroup
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		department := &domain.Department{Name: name}
+		if err := department.Validate(); err != nil {
+			return err
+		}
+		if err := s.departmentRepo.Create(ctx, department); err != nil {
+			if errors.Is(err, domain.ErrDepartmentExists) {
+				return fmt.Errorf("%w: %s", domain.ErrGroupExists, name)
+			}
+			return err
+		}
+		group = scimGroup{department: department.Name, departmentID: department.ID}
+
+		users, err := s.userRepo.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		return s.setMembers(ctx, group, users, resource.Members)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetGroup(ctx, group.id())
+}
+
+// ReplaceGroup sets the members of a group
+func (s *SCIMService) ReplaceGroup(ctx context.Context, id string, resource scim.Group) (*scim.Group, error) {
+	return s.updateGroup(ctx, id, func(current scim.Group) (scim.Group, error) {
+		return resource, nil
+	})
+}
+
+// PatchGroup applies PATCH operations to a group, usually adding or
+// removing members
+func (s *SCIMService) PatchGroup(ctx context.Context, id string, ops []scim.PatchOperation) (*scim.Group, error) {
+	return s.updateGroup(ctx, id, func(current scim.Group) (scim.Group, error) {
+		var patched scim.Group
+		err := patchResource(&current, ops, &patched)
+		return patched, err
+	})
+}
+
+// DeleteGroup clears the department of every member of a department group
+// and deletes the department, unless it has subdepartments. Role groups
+// cannot be deleted.
+func (s *SCIMService) DeleteGroup(ctx context.Context, id string) error {
+	group, err := s.group(ctx, id)
+	if err != nil {
+		return err
+	}
+	if group.department == "" {
+		return fmt.Errorf("%w: %w: role groups cannot be deleted", domain.ErrInvalidInput, scim.ErrMutability)
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		users, err := s.userRepo.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.setMembers(ctx, group, users, nil); err != nil {
+			return err
+		}
+		err = s.departmentRepo.Delete(ctx, group.departmentID)
+		if errors.Is(err, domain.ErrDepartmentInUse) {
+			return nil
+		}
+		return err
+	})
+}
+
+// updateGroup replaces the members of a group with those of the updated
+// resource. Renaming a group is not supported, since the name is what
+// identifies its role or department.
+func (s *SCIMService) updateGroup(ctx context.Context, id string, update func(scim.Group) (scim.Group, error)) (*scim.Group, error) {
+	group, err := s.group(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		users, err := s.userRepo.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		updated, err := update(group.resource(users))
+		if err != nil {
+			return err
+		}
+		if updated.DisplayName != group.displayName() {
+			return fmt.Errorf("%w: %w: groups cannot be renamed", domain.ErrInvalidInput, scim.ErrMutability)
+		}
+		return s.setMembers(ctx, group, users, updated.Members)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetGroup(ctx, id)
+}
+
+// setMembers makes the given users the members of a group. Leaving a
+// department clears the user's department and leaving a role group makes
+// them an employee; leaving the employee group changes nothing, since every
+// user has a role. Roles change through UserService.ChangeRole, joiners
+// first, so the last user who can manage roles cannot be dropped.
+func (s *SCIMService) setMembers(ctx context.Context, group scimGroup, users []*domain.User, members []scim.MultiValue) error {
+	byID := make(map[string]*domain.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	wanted := make(map[string]bool, len(members))
+	for _, m := range members {
+		if _, ok := byID[m.Value]; !ok {
+			return fmt.Errorf("%w: member %s does not exist", domain.ErrInvalidInput, m.Value)
+		}
+		wanted[m.Value] = true
+	}
+
+	if group.department != "" {
+		for _, u := range users {
+			if wanted[u.ID] == group.has(u) {
+				continue
+			}
+			if wanted[u.ID] {
+				u.DepartmentID = &group.departmentID
+			} else {
+				u.DepartmentID = nil
+			}
+			if err := s.userRepo.Update(ctx, u); err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+		return nil
+	}
+
+	for _, joining := range []bool{true, false} {
+		for _, u := range users {
+			if wanted[u.ID] == group.has(u) || wanted[u.ID] != joining {
+				continue
+			}
+			role := group.role
+			if !joining {
+				if group.role == domain.RoleEmployee {
+					continue
+				}
+				role = domain.RoleEmployee
+			}
+			if _, err := s.userService.ChangeRole(ctx, u.ID, role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scimGroup is a role or a department
+type scimGroup struct {
+	role         domain.UserRole
+	department   string
+	departmentID string
+}
+
+func roleGroupID(role domain.UserRole) string {
+	return roleGroupPrefix + string(role)
+}
+
+func departmentGroupID(department string) string {
+	return departmentGroupPrefix + base64.RawURLEncoding.EncodeToString([]byte(department))
+}
+
+// group resolves a group ID; there is a role group for every role and a
+// department group for every department
+func (s *SCIMService) group(ctx context.Context, id string) (scimGroup, error) {
+	group, err := parseGroupID(id)
+	if err != nil {
+		return group, err
+	}
+	if group.department != "" {
+		department, err := s.departmentRepo.GetByName(ctx, group.department)
+		if errors.Is(err, domain.ErrDepartmentNotFound) {
+			return scimGroup{}, domain.ErrGroupNotFound
+		}
+		if err != nil {
+			return scimGroup{}, err
+		}
+		return scimGroup{department: department.Name, departmentID: department.ID}, nil
+	}
+	if _, err := s.roleRepo.GetByName(ctx, group.role); err != nil {
+		if errors.Is(err, domain.ErrRoleNotFound) {
+			return scimGroup{}, domain.ErrGroupNotFound
+		}
+		return scimGroup{}, err
+	}
+	return group, nil
+}
+
+// parseGroupID decodes a group ID without checking that its role or
+// department exists
+func parseGroupID(id string) (scimGroup, error) {
+	if role, ok := strings.CutPrefix(id, roleGroupPrefix); ok && domain.UserRole(role).IsValid() {
+		return scimGroup{role: domain.UserRole(role)}, nil
+	}
+	if encoded, ok := strings.CutPrefix(id, departmentGroupPrefix); ok {
+		name, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err == nil && len(name) > 0 {
+			return scimGroup{department: string(name)}, nil
+		}
+	}
+	return scimGroup{}, domain.ErrGroupNotFound
+}
+
+func (g scimGroup) id() string {
+	if g.department != "" {
+		return departmentGroupID(g.department)
+	}
+	return roleGroupID(g.role)
+}
+
+func (g scimGroup) displayName() string {
+	if g.department != "" {
+		return g.department
+	}
+	return "role:" + string(g.role)
+}
+
+// has reports whether a user is a member
+func (g scimGroup) has(u *domain.User) bool {
+	if g.department != "" {
+		return u.DepartmentID != nil && *u.DepartmentID == g.departmentID
+	}
+	return u.Role == g.role
+}
+
+// resource converts the group with its members among users. Its meta dates
+// are the earliest creation and latest change of its members.
+func (g scimGroup) resource(users []*domain.User) scim.Group {
+	resource := scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          g.id(),
+		DisplayName: g.displayName(),
+		Members:     []scim.MultiValue{},
+		Meta:        &scim.Meta{ResourceType: "Group"},
+	}
+	for _, u := range users {
+		if !g.has(u) {
+			continue
+		}
+		resource.Members = append(resource.Members, scim.MultiValue{Value: u.ID, Display: u.Name, Type: "User"})
+		if resource.Meta.Created.IsZero() || u.CreatedAt.Before(resource.Meta.Created) {
+			resource.Meta.Created = u.CreatedAt
+		}
+		if u.UpdatedAt.After(resource.Meta.LastModified) {
+			resource.Meta.LastModified = u.UpdatedAt
+		}
+	}
+	return resource
+}
+
+// scimUser converts a user; byID holds their manager when they have one
+func scimUser(u *domain.User, byID map[string]*domain.User) scim.User {
+	active := scim.Boolean(u.IsActive())
+	given, family, _ := strings.Cut(u.Name, " ")
+	resource := scim.User{
+		Schemas:     []string{scim.SchemaUser, scim.SchemaEnterpriseUser},
+		ID:          u.ID,
+		ExternalID:  orEmpty(u.ExternalID),
+		UserName:    u.Email,
+		Name:        &scim.Name{Formatted: u.Name, GivenName: given, FamilyName: family},
+		DisplayName: u.Name,
+		Title:       orEmpty(u.JobTitle),
+		Emails:      []scim.MultiValue{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta:        &scim.Meta{ResourceType: "User", Created: u.CreatedAt, LastModified: u.UpdatedAt},
+	}
+
+	for _, g := range []scimGroup{{role: u.Role}, {department: orEmpty(u.Department), departmentID: orEmpty(u.DepartmentID)}} {
+		if (g.department != "" || g.role != "") && g.has(u) {
+			resource.Groups = append(resource.Groups, scim.MultiValue{Value: g.id(), Display: g.displayName(), Type: "direct"})
+		}
+	}
+
+	if u.Department != nil || u.ManagerID != nil {
+		resource.Enterprise = &scim.EnterpriseUser{Department: orEmpty(u.Department)}
+		if u.ManagerID != nil {
+			resource.Enterprise.Manager = &scim.Manager{Value: *u.ManagerID}
+			if manager, ok := byID[*u.ManagerID]; ok {
+				resource.Enterprise.Manager.DisplayName = manager.Name
+			}
+		}
+	}
+	return resource
+}
+
+// scimUserFields are the writable attributes of a user resource
+type scimUserFields struct {
+	name       string
+	email      string
+	externalID *string
+	jobTitle   *string
+	department *string
+	managerID  *string
+	active     *bool
+	password   string
+}
+
+// userFields validates a user resource. userName is the email; the name is
+// name.formatted, the given and family names, or displayName.
+func userFields(r scim.User) (scimUserFields, error) {
+	var f scimUserFields
+	f.email = strings.TrimSpace(r.UserName)
+	if f.email == "" {
+		return f, fmt.Errorf("%w: userName is required", domain.ErrInvalidInput)
+	}
+	if addr, err := mail.ParseAddress(f.email); err != nil || addr.Address != f.email {
+		return f, fmt.Errorf("%w: userName must be an email address", domain.ErrInvalidEmail)
+	}
+
+	if r.Name != nil {
+		f.name = strings.TrimSpace(r.Name.Formatted)
+		if f.name == "" {
+			f.name = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+		}
+	}
+	if f.name == "" {
+		f.name = strings.TrimSpace(r.DisplayName)
+	}
+	if f.name == "" {
+		return f, fmt.Errorf("%w: name or displayName is required", domain.ErrInvalidInput)
+	}
+
+	if r.Password != "" && len(r.Password) < 8 {
+		return f, domain.ErrWeakPassword
+	}
+	f.password = r.Password
+	f.externalID = optional(r.ExternalID)
+	f.jobTitle = optional(r.Title)
+	if r.Enterprise != nil {
+		f.department = optional(r.Enterprise.Department)
+		if r.Enterprise.Manager != nil {
+			f.managerID = optional(r.Enterprise.Manager.Value)
+		}
+	}
+	if r.Active != nil {
+		active := bool(*r.Active)
+		f.active = &active
+	}
+	return f, nil
+}
+
+// applyFields copies the profile fields to a user, creating their
+// department when it does not exist yet
+func (s *SCIMService) applyFields(ctx context.Context, u *domain.User, f scimUserFields) error {
+	departmentID, err := provisionDepartment(ctx, s.departmentRepo, orEmpty(f.department))
+	if err != nil {
+		return err
+	}
+	u.Name = f.name
+	u.Email = f.email
+	u.ExternalID = f.externalID
+	u.JobTitle = f.jobTitle
+	u.DepartmentID = departmentID
+	return nil
+}
+
+// optional returns nil for a blank string
+func optional(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// parseSCIMFilter parses a filter query parameter; empty matches everything
+func parseSCIMFilter(filter string) (scim.Filter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+	match, err := scim.ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidInput, err)
+	}
+	return match, nil
+}
+
+// matches applies a filter, nil matching everything, to a resource
+func matches(filter scim.Filter, resource any) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	m, err := scim.ToMap(resource)
+	if err != nil {
+		return false, err
+	}
+	return filter.Match(m), nil
+}
+
+// patchResource applies PATCH operations to a resource and decodes the
+// result into patched
+func patchResource(resource any, ops []scim.PatchOperation, patched any) error {
+	if len(ops) == 0 {
+		return fmt.Errorf("%w: %w: no operations", domain.ErrInvalidInput, scim.ErrInvalidSyntax)
+	}
+	m, err := scim.ToMap(resource)
+	if err != nil {
+		return err
+	}
+	if err := scim.Patch(m, ops); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidInput, err)
+	}
+	if err := scim.FromMap(m, patched); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// scimPage applies the defaults and limits of startIndex and count
+func scimPage(startIndex, count int) (int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	return startIndex, min(count, SCIMMaxResults)
+}
@@ -0,0 +1,302 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/scim"
+)
+
+// patchOp builds a PATCH operation with a JSON value
+func patchOp(op, path, value string) scim.PatchOperation {
+	o := scim.PatchOperation{Op: op, Path: path}
+	if value != "" {
+		o.Value = json.RawMessage(value)
+	}
+	return o
+}
+
+// scimUserIDs lists the IDs of the resources of a page
+func scimUserIDs(list *scim.ListResponse[scim.User]) []string {
+	ids := make([]string, len(list.Resources))
+	for i, u := range list.Resources {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+// memberIDs lists the member IDs of a group
+func memberIDs(group *scim.Group) map[string]bool {
+	ids := map[string]bool{}
+	for _, m := range group.Members {
+		ids[m.Value] = true
+	}
+	return ids
+}
+
+func TestSCIMService_CreateAndFilterUsers(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	boss := e.addUser(t, "boss")
+
+	active := scim.Boolean(true)
+	ann, err := e.scim.CreateUser(ctx, scim.User{
+		ExternalID: "00u1",
+		UserName:   "ann@example.com",
+		Name:       &scim.Name{GivenName: "Ann", FamilyName: "Lee"},
+		Title:      "Engineer",
+		Active:     &active,
+		Enterprise: &scim.EnterpriseUser{Department: "R&D", Manager: &scim.Manager{Value: boss.ID}},
+	})
+	expectErr(t, err, nil)
+	if ann.Name.Formatted != "Ann Lee" || ann.Enterprise.Manager.DisplayName != "boss" || !bool(*ann.Active) {
+		t.Fatalf("created %+v", ann)
+	}
+	stored, err := e.users.GetByID(ctx, ann.ID)
+	expectErr(t, err, nil)
+	if stored.Role != domain.RoleEmployee || *stored.Department != "R&D" || *stored.ManagerID != boss.ID || *stored.ExternalID != "00u1" {
+		t.Fatalf("stored %+v", stored)
+	}
+
+	inactive := scim.Boolean(false)
+	bo, err := e.scim.CreateUser(ctx, scim.User{UserName: "bo@example.com", DisplayName: "Bo", Active: &inactive})
+	expectErr(t, err, nil)
+	if bool(*bo.Active) {
+		t.Fatal("user created inactive is active")
+	}
+
+	_, err = e.scim.CreateUser(ctx, scim.User{UserName: "ANN@example.com", DisplayName: "Ann again"})
+	expectErr(t, err, domain.ErrUserAlreadyExists)
+	_, err = e.scim.CreateUser(ctx, scim.User{UserName: "other@example.com", DisplayName: "Other", ExternalID: "00u1"})
+	expectErr(t, err, domain.ErrUserAlreadyExists)
+	_, err = e.scim.CreateUser(ctx, scim.User{UserName: "ann", DisplayName: "Ann"})
+	expectErr(t, err, domain.ErrInvalidEmail)
+	_, err = e.scim.CreateUser(ctx, scim.User{UserName: "nameless@example.com"})
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	for filter, want := range map[string][]string{
+		`userName eq "ANN@example.com"`:            {ann.ID},
+		`externalId eq "00u1"`:                     {ann.ID},
+		`emails[type eq "work" and value sw "bo"]`: {boss.ID, bo.ID},
+		`active eq false`:                          {bo.ID},
+		`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq "r&d"`: {ann.ID},
+		`name.familyName pr and not (title eq "Manager")`:                                {ann.ID},
+		``: {boss.ID, ann.ID, bo.ID},
+	} {
+		list, err := e.scim.ListUsers(ctx, filter, 1, 10)
+		expectErr(t, err, nil)
+		if got := scimUserIDs(list); !slices.Equal(got, want) {
+			t.Errorf("filter %q = %v, want %v", filter, got, want)
+		}
+	}
+
+	page, err := e.scim.ListUsers(ctx, "", 2, 1)
+	expectErr(t, err, nil)
+	if page.TotalResults != 3 || page.StartIndex != 2 || !slices.Equal(scimUserIDs(page), []string{ann.ID}) {
+		t.Fatalf("page %+v", page)
+	}
+
+	_, err = e.scim.ListUsers(ctx, `userName eq`, 1, 10)
+	expectErr(t, err, scim.ErrInvalidFilter)
+}
+
+func TestSCIMService_PatchUser(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	boss := e.addUser(t, "boss")
+	ann, err := e.scim.CreateUser(ctx, scim.User{UserName: "ann@example.com", DisplayName: "Ann Lee"})
+	expectErr(t, err, nil)
+
+	// Operations as sent by Azure AD: paths, string booleans and extension
+	// attributes addressed by their URN
+	patched, err := e.scim.PatchUser(ctx, ann.ID, []scim.PatchOperation{
+		patchOp("Replace", "name.formatted", `"Ann Smith"`),
+		patchOp("Add", "title", `"Lead"`),
+		patchOp("Add", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager", `"`+boss.ID+`"`),
+		patchOp("Replace", "active", `"False"`),
+	})
+	expectErr(t, err, nil)
+	if patched.Name.Formatted != "Ann Smith" || patched.Title != "Lead" || patched.Enterprise.Manager.Value != boss.ID || bool(*patched.Active) {
+		t.Fatalf("patched %+v", patched)
+	}
+	stored, err := e.users.GetByID(ctx, ann.ID)
+	expectErr(t, err, nil)
+	if stored.Name != "Ann Smith" || *stored.ManagerID != boss.ID || stored.IsActive() {
+		t.Fatalf("stored %+v", stored)
+	}
+
+	// Operations as sent by Okta: an object without a path
+	patched, err = e.scim.PatchUser(ctx, ann.ID, []scim.PatchOperation{
+		patchOp("replace", "", `{"active": true, "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "Sales"}}`),
+	})
+	expectErr(t, err, nil)
+	if !bool(*patched.Active) || patched.Enterprise.Department != "Sales" || patched.Enterprise.Manager.Value != boss.ID {
+		t.Fatalf("patched %+v", patched)
+	}
+
+	patched, err = e.scim.PatchUser(ctx, ann.ID, []scim.PatchOperation{
+		patchOp("remove", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager", ""),
+		patchOp("replace", `emails[type eq "work"].value`, `"ignored@example.com"`),
+	})
+	expectErr(t, err, nil)
+	if patched.Enterprise.Manager != nil || patched.UserName != "ann@example.com" {
+		t.Fatalf("patched %+v", patched)
+	}
+
+	_, err = e.scim.PatchUser(ctx, ann.ID, []scim.PatchOperation{patchOp("replace", "id", `"other"`)})
+	expectErr(t, err, scim.ErrMutability)
+	_, err = e.scim.PatchUser(ctx, ann.ID, []scim.PatchOperation{patchOp("replace", `emails[type eq "home"].value`, `"x@example.com"`)})
+	expectErr(t, err, scim.ErrNoTarget)
+	_, err = e.scim.PatchUser(ctx, ann.ID, []scim.PatchOperation{patchOp("move", "title", `"x"`)})
+	expectErr(t, err, scim.ErrInvalidSyntax)
+	_, err = e.scim.PatchUser(ctx, ann.ID, []scim.PatchOperation{patchOp("replace", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager", `"`+ann.ID+`"`)})
+	expectErr(t, err, domain.ErrManagerCycle)
+	_, err = e.scim.PatchUser(ctx, "missing", []scim.PatchOperation{patchOp("replace", "title", `"x"`)})
+	expectErr(t, err, domain.ErrUserNotFound)
+}
+
+func TestSCIMService_ReplaceAndDeprovisionUser(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	ann, err := e.scim.CreateUser(ctx, scim.User{
+		UserName:    "ann@example.com",
+		DisplayName: "Ann",
+		Password:    "secret-password",
+		Title:       "Engineer",
+	})
+	expectErr(t, err, nil)
+	request := e.addRequest(t, ann.ID, domain.RequestPending)
+
+	replaced, err := e.scim.ReplaceUser(ctx, ann.ID, scim.User{UserName: "ann.lee@example.com", DisplayName: "Ann Lee"})
+	expectErr(t, err, nil)
+	if replaced.UserName != "ann.lee@example.com" || replaced.Title != "" || !bool(*replaced.Active) {
+		t.Fatalf("replaced %+v", replaced)
+	}
+	_, _, err = e.auth.Login(ctx, "ann.lee@example.com", "secret-password")
+	expectErr(t, err, nil)
+
+	expectErr(t, e.scim.DeprovisionUser(ctx, ann.ID), nil)
+	got, err := e.scim.GetUser(ctx, ann.ID)
+	expectErr(t, err, nil)
+	if bool(*got.Active) {
+		t.Fatal("deprovisioned user is active")
+	}
+	_, _, err = e.auth.Login(ctx, "ann.lee@example.com", "secret-password")
+	expectErr(t, err, domain.ErrUserDeactivated)
+	if _, err := e.requests.GetByID(ctx, request.ID); err != nil {
+		t.Fatalf("request of deprovisioned user: %v", err)
+	}
+
+	expectErr(t, e.scim.DeprovisionUser(ctx, ann.ID), nil)
+	expectErr(t, e.scim.DeprovisionUser(ctx, "missing"), domain.ErrUserNotFound)
+}
+
+func TestSCIMService_Groups(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	admin := e.addUser(t, "admin")
+	_, err := e.user.ChangeRole(ctx, admin.ID, domain.RoleAdmin)
+	expectErr(t, err, nil)
+	ann := e.addUser(t, "ann")
+	bo := e.addUser(t, "bo")
+
+	sales, err := e.scim.CreateGroup(ctx, scim.Group{
+		DisplayName: "Sales",
+		Members:     []scim.MultiValue{{Value: ann.ID}, {Value: bo.ID}},
+	})
+	expectErr(t, err, nil)
+	if ids := memberIDs(sales); len(ids) != 2 || !ids[ann.ID] || !ids[bo.ID] {
+		t.Fatalf("members %+v", sales.Members)
+	}
+	stored, err := e.users.GetByID(ctx, ann.ID)
+	expectErr(t, err, nil)
+	if stored.Department == nil || *stored.Department != "Sales" {
+		t.Fatalf("department %v", stored.Department)
+	}
+
+	_, err = e.scim.CreateGroup(ctx, scim.Group{DisplayName: "Sales"})
+	expectErr(t, err, domain.ErrGroupExists)
+	_, err = e.scim.CreateGroup(ctx, scim.Group{DisplayName: "role:admin"})
+	expectErr(t, err, domain.ErrGroupExists)
+	_, err = e.scim.CreateGroup(ctx, scim.Group{DisplayName: "role:owner"})
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.scim.CreateGroup(ctx, scim.Group{DisplayName: "R&D", Members: []scim.MultiValue{{Value: "missing"}}})
+	expectErr(t, err, domain.ErrInvalidInput)
+
+	list, err := e.scim.ListGroups(ctx, "", 1, 10)
+	expectErr(t, err, nil)
+	var names []string
+	for _, g := range list.Resources {
+		names = append(names, g.DisplayName)
+	}
+	if !slices.Equal(names, []string{
+		"role:admin", "role:ld_manager", "role:department_head", "role:mentor", "role:employee", "Sales",
+	}) {
+		t.Fatalf("groups %v", names)
+	}
+	list, err = e.scim.ListGroups(ctx, `displayName eq "sales"`, 1, 10)
+	expectErr(t, err, nil)
+	if len(list.Resources) != 1 || list.Resources[0].ID != sales.ID {
+		t.Fatalf("filtered groups %+v", list.Resources)
+	}
+
+	// Removing a member as Azure AD does, by a filter on members
+	sales, err = e.scim.PatchGroup(ctx, sales.ID, []scim.PatchOperation{
+		patchOp("Remove", `members[value eq "`+bo.ID+`"]`, ""),
+	})
+	expectErr(t, err, nil)
+	if ids := memberIDs(sales); len(ids) != 1 || !ids[ann.ID] {
+		t.Fatalf("members %+v", sales.Members)
+	}
+	stored, err = e.users.GetByID(ctx, bo.ID)
+	expectErr(t, err, nil)
+	if stored.Department != nil {
+		t.Fatalf("department of removed member %v", *stored.Department)
+	}
+
+	// Group membership sets the role
+	adminGroup, err := e.scim.PatchGroup(ctx, "role-admin", []scim.PatchOperation{
+		patchOp("add", "members", `[{"value": "`+ann.ID+`"}]`),
+	})
+	expectErr(t, err, nil)
+	if ids := memberIDs(adminGroup); len(ids) != 2 || !ids[ann.ID] {
+		t.Fatalf("admins %+v", adminGroup.Members)
+	}
+	user, err := e.scim.GetUser(ctx, ann.ID)
+	expectErr(t, err, nil)
+	if len(user.Groups) != 2 || user.Groups[0].Display != "role:admin" || user.Groups[1].Display != "Sales" {
+		t.Fatalf("groups of user %+v", user.Groups)
+	}
+	_, err = e.scim.PatchGroup(ctx, "role-admin", []scim.PatchOperation{
+		patchOp("remove", "members", `[{"value": "`+ann.ID+`"}]`),
+	})
+	expectErr(t, err, nil)
+	stored, err = e.users.GetByID(ctx, ann.ID)
+	expectErr(t, err, nil)
+	if stored.Role != domain.RoleEmployee {
+		t.Fatalf("role %s", stored.Role)
+	}
+	_, err = e.scim.ReplaceGroup(ctx, "role-admin", scim.Group{DisplayName: "role:admin"})
+	expectErr(t, err, domain.ErrLastAdmin)
+
+	_, err = e.scim.PatchGroup(ctx, sales.ID, []scim.PatchOperation{patchOp("replace", "displayName", `"Marketing"`)})
+	expectErr(t, err, scim.ErrMutability)
+	expectErr(t, e.scim.DeleteGroup(ctx, "role-employee"), scim.ErrMutability)
+	_, err = e.scim.GetGroup(ctx, "role-owner")
+	expectErr(t, err, domain.ErrGroupNotFound)
+
+	expectErr(t, e.scim.DeleteGroup(ctx, sales.ID), nil)
+	stored, err = e.users.GetByID(ctx, ann.ID)
+	expectErr(t, err, nil)
+	if stored.Department != nil {
+		t.Fatalf("department after delete %v", *stored.Department)
+	}
+	_, err = e.scim.GetGroup(ctx, sales.ID)
+	expectErr(t, err, domain.ErrGroupNotFound)
+	if _, err := e.departments.GetByName(ctx, "Sales"); !errors.Is(err, domain.ErrDepartmentNotFound) {
+		t.Fatalf("department of deleted group: %v", err)
+	}
+}
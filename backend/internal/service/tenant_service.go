@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// TenantAdmin is the first administrator of a new tenant
+type TenantAdmin struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+// TenantService manages the organisations hosted on the deployment. Super
+// admins create and update tenants; the admins of a tenant change its
+// settings.
+type TenantService struct {
+	tx                domain.TxManager
+	tenantRepo        domain.TenantRepository
+	roleRepo          domain.RoleRepository
+	userRepo          domain.UserRepository
+	questionnaireRepo domain.QuestionnaireRepository
+}
+
+func NewTenantService(
+	tx domain.TxManager,
+	tenantRepo domain.TenantRepository,
+	roleRepo domain.RoleRepository,
+	userRepo domain.UserRepository,
+	questionnaireRepo domain.QuestionnaireRepository,
+) *TenantService {
+	return &TenantService{
+		tx:                tx,
+		tenantRepo:        tenantRepo,
+		roleRepo:          roleRepo,
+		userRepo:          userRepo,
+		questionnaireRepo: questionnaireRepo,
+	}
+}
+
+// CreateTenant adds a tenant with the built-in roles, the default feedback
+// questionnaires and its first admin
+func (s *TenantService) CreateTenant(ctx context.Context, tenant *domain.Tenant, admin TenantAdmin) (*domain.Tenant, *domain.User, error) {
+	if err := tenant.Validate(); err != nil {
+		return nil, nil, err
+	}
+	admin.Name = strings.TrimSpace(admin.Name)
+	if admin.Name == "" {
+		return nil, nil, fmt.Errorf("%w: admin name is required", domain.ErrInvalidInput)
+	}
+	if addr, err := mail.ParseAddress(admin.Email); err != nil || addr.Address != admin.Email {
+		return nil, nil, domain.ErrInvalidEmail
+	}
+	if len(admin.Password) < 8 {
+		return nil, nil, domain.ErrWeakPassword
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(admin.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user := &domain.User{
+		Name:         admin.Name,
+		Email:        admin.Email,
+		PasswordHash: string(hashedPassword),
+		Role:         domain.RoleAdmin,
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+			return err
+		}
+
+		ctx = domain.WithTenant(ctx, tenant.ID)
+		for _, role := range domain.DefaultRoles() {
+			if err := s.roleRepo.Create(ctx, role); err != nil {
+				return fmt.Errorf("failed to create role %s: %w", role.Name, err)
+			}
+		}
+		for _, questionnaire := range domain.DefaultQuestionnaires() {
+			if err := s.questionnaireRepo.Create(ctx, questionnaire); err != nil {
+				return fmt.Errorf("failed to create questionnaire: %w", err)
+			}
+			if err := s.questionnaireRepo.Activate(ctx, questionnaire.ID); err != nil {
+				return fmt.Errorf("failed to activate questionnaire: %w", err)
+			}
+		}
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create admin: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user.PasswordHash = ""
+	return tenant, user, nil
+}
+
+// ListTenants lists every tenant, oldest first
+func (s *TenantService) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	return s.tenantRepo.GetAll(ctx)
+}
+
+// GetTenant retrieves a tenant by ID
+func (s *TenantService) GetTenant(ctx context.Context, id string) (*domain.Tenant, error) {
+	return s.tenantRepo.GetByID(ctx, id)
+}
+
+// UpdateTenant renames a tenant and replaces its hosts and settings, keeping
+// their secrets, see domain.TenantSettings.KeepSecrets; the slug never
+// changes
+func (s *TenantService) UpdateTenant(ctx context.Context, id, name string, hosts []string, settings domain.TenantSettings) (*domain.Tenant, error) {
+	var tenant *domain.Tenant
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		tenant, err = s.tenantRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		settings.KeepSecrets(tenant.Settings)
+		tenant.Name = name
+		tenant.Hosts = hosts
+		tenant.Settings = settings
+		if err := tenant.Validate(); err != nil {
+			return err
+		}
+		return s.tenantRepo.Update(ctx, tenant)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// ResolveHost returns the tenant a Host header belongs to
+func (s *TenantService) ResolveHost(ctx context.Context, host string) (*domain.Tenant, error) {
+	return s.tenantRepo.GetByHost(ctx, domain.NormalizeHost(host))
+}
+
+// CurrentTenant returns the tenant of the context
+func (s *TenantService) CurrentTenant(ctx context.Context) (*domain.Tenant, error) {
+	return s.tenantRepo.GetByID(ctx, domain.TenantID(ctx))
+}
+
+// UpdateSettings replaces the settings of the context's tenant, keeping
+// their secrets. A new mentor capacity applies to mentors created from then
+// on, a new HRIS export from the next sync.
+func (s *TenantService) UpdateSettings(ctx context.Context, settings domain.TenantSettings) (*domain.Tenant, error) {
+	var tenant *domain.Tenant
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		tenant, err = s.tenantRepo.GetByIDForUpdate(ctx, domain.TenantID(ctx))
+		if err != nil {
+			return err
+		}
+
+		settings.KeepSecrets(tenant.Settings)
+		if err := settings.Validate(); err != nil {
+			return err
+		}
+		tenant.Settings = settings
+		return s.tenantRepo.Update(ctx, tenant)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// IssueSCIMToken issues a new SCIM bearer token for the context's tenant,
+// replacing the one before. Only its hash is kept, so the token is returned
+// this once.
+func (s *TenantService) IssueSCIMToken(ctx context.Context) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate SCIM token: %w", err)
+	}
+	token := "scim_" + hex.EncodeToString(random)
+
+	if err := s.setSCIMTokenHash(ctx, domain.HashSCIMToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeSCIMToken turns SCIM provisioning off for the context's tenant
+func (s *TenantService) RevokeSCIMToken(ctx context.Context) error {
+	return s.setSCIMTokenHash(ctx, "")
+}
+
+// ResolveSCIMToken returns the tenant a SCIM bearer token was issued for,
+// looking it up by the hash of the token
+func (s *TenantService) ResolveSCIMToken(ctx context.Context, token string) (*domain.Tenant, error) {
+	if token == "" {
+		return nil, domain.ErrTenantNotFound
+	}
+	return s.tenantRepo.GetBySCIMTokenHash(ctx, domain.HashSCIMToken(token))
+}
+
+func (s *TenantService) setSCIMTokenHash(ctx context.Context, hash string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tenant, err := s.tenantRepo.GetByIDForUpdate(ctx, domain.TenantID(ctx))
+		if err != nil {
+			return err
+		}
+		tenant.Settings.SCIM.TokenHash = hash
+		return s.tenantRepo.Update(ctx, tenant)
+	})
+}
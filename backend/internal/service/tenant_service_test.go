@@ -0,0 +1,268 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+var acmeAdmin = service.TenantAdmin{Name: "Jane", Email: "jane@acme.example", Password: "s3cret-pass"}
+
+// addTenant creates a tenant through the service and returns a context
+// confined to it
+func (e *env) addTenant(t *testing.T, slug string, hosts ...string) (*domain.Tenant, context.Context) {
+	t.Helper()
+
+	tenant, _, err := e.tenant.CreateTenant(defaultCtx(), &domain.Tenant{
+		Slug:     slug,
+		Name:     slug + " Corp",
+		Hosts:    hosts,
+		Settings: domain.TenantSettings{MentorCapacity: 3},
+	}, acmeAdmin)
+	if err != nil {
+		t.Fatalf("add tenant: %v", err)
+	}
+	return tenant, domain.WithTenant(defaultCtx(), tenant.ID)
+}
+
+func TestTenantService_CreateTenant(t *testing.T) {
+	tests := []struct {
+		name    string
+		slug    string
+		hosts   []string
+		admin   service.TenantAdmin
+		wantErr error
+	}{
+		{name: "valid", slug: "acme", hosts: []string{"Learning.Acme.example"}, admin: acmeAdmin},
+		{name: "invalid slug", slug: "Acme Corp", admin: acmeAdmin, wantErr: domain.ErrInvalidInput},
+		{name: "taken slug", slug: "default", admin: acmeAdmin, wantErr: domain.ErrTenantExists},
+		{name: "missing admin name", slug: "acme", admin: service.TenantAdmin{Email: "jane@acme.example", Password: "s3cret-pass"}, wantErr: domain.ErrInvalidInput},
+		{name: "invalid admin email", slug: "acme", admin: service.TenantAdmin{Name: "Jane", Email: "jane", Password: "s3cret-pass"}, wantErr: domain.ErrInvalidEmail},
+		{name: "weak admin password", slug: "acme", admin: service.TenantAdmin{Name: "Jane", Email: "jane@acme.example", Password: "short"}, wantErr: domain.ErrWeakPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+
+			tenant, admin, err := e.tenant.CreateTenant(ctx, &domain.Tenant{Slug: tt.slug, Name: "Acme", Hosts: tt.hosts}, tt.admin)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				tenants, _ := e.tenant.ListTenants(ctx)
+				if len(tenants) != 1 {
+					t.Errorf("%d tenants after a failed create, want only the default", len(tenants))
+				}
+				return
+			}
+
+			if !slices.Equal(tenant.Hosts, []string{"learning.acme.example"}) || tenant.Settings.MentorCapacity != domain.DefaultMentorCapacity {
+				t.Errorf("tenant %+v", tenant)
+			}
+			if admin.PasswordHash != "" || admin.Role != domain.RoleAdmin {
+				t.Errorf("admin %+v", admin)
+			}
+
+			// The tenant starts with its own roles, questionnaires and admin
+			tctx := domain.WithTenant(ctx, tenant.ID)
+			roles, err := e.roles.GetAll(tctx)
+			expectErr(t, err, nil)
+			if len(roles) != len(domain.DefaultRoles()) {
+				t.Errorf("%d roles in the new tenant", len(roles))
+			}
+			for _, audience := range []domain.FeedbackAudience{domain.FeedbackFromLearner, domain.FeedbackFromMentor} {
+				if _, err := e.questionnaires.GetActive(tctx, audience); err != nil {
+					t.Errorf("active %s questionnaire: %v", audience, err)
+				}
+			}
+			if _, _, err := e.auth.Login(tctx, acmeAdmin.Email, acmeAdmin.Password); err != nil {
+				t.Errorf("admin login: %v", err)
+			}
+			if _, _, err := e.auth.Login(ctx, acmeAdmin.Email, acmeAdmin.Password); err == nil {
+				t.Error("admin logged in to the default tenant")
+			}
+		})
+	}
+}
+
+func TestTenantService_UpdateTenant(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	acme, _ := e.addTenant(t, "acme", "acme.example")
+	e.addTenant(t, "globex", "globex.example")
+
+	updated, err := e.tenant.UpdateTenant(ctx, acme.ID, " Acme Inc ", []string{"learn.acme.example"}, domain.TenantSettings{})
+	expectErr(t, err, nil)
+	if updated.Slug != "acme" || updated.Name != "Acme Inc" || !slices.Equal(updated.Hosts, []string{"learn.acme.example"}) {
+		t.Errorf("updated tenant %+v", updated)
+	}
+	if updated.Settings.MentorCapacity != domain.DefaultMentorCapacity {
+		t.Errorf("capacity %d, want the default", updated.Settings.MentorCapacity)
+	}
+
+	_, err = e.tenant.UpdateTenant(ctx, acme.ID, "Acme", []string{"globex.example"}, domain.TenantSettings{})
+	expectErr(t, err, domain.ErrTenantExists)
+	_, err = e.tenant.UpdateTenant(ctx, acme.ID, "", nil, domain.TenantSettings{})
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.tenant.UpdateTenant(ctx, "missing", "Acme", nil, domain.TenantSettings{})
+	expectErr(t, err, domain.ErrTenantNotFound)
+}
+
+func TestTenantService_ResolveHost(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	acme, _ := e.addTenant(t, "acme", "acme.example")
+
+	for _, host := range []string{"acme.example", "ACME.example:8080", "acme.example."} {
+		tenant, err := e.tenant.ResolveHost(ctx, host)
+		if err != nil || tenant.ID != acme.ID {
+			t.Errorf("ResolveHost(%q) = %+v, %v", host, tenant, err)
+		}
+	}
+	_, err := e.tenant.ResolveHost(ctx, "localhost:8080")
+	expectErr(t, err, domain.ErrTenantNotFound)
+}
+
+func TestTenantService_UpdateSettings(t *testing.T) {
+	e := newEnv(t)
+	_, tctx := e.addTenant(t, "acme")
+
+	// New mentors take the capacity of their tenant
+	mentor, err := e.mentor.CreateMentor(tctx, "Ann", "Lead", "", "ann@acme.example", "", nil, 0)
+	expectErr(t, err, nil)
+	if mentor.Capacity != 3 {
+		t.Errorf("capacity %d, want the tenant's 3", mentor.Capacity)
+	}
+
+	_, err = e.tenant.UpdateSettings(tctx, domain.TenantSettings{
+		MentorCapacity: 7,
+		Branding:       domain.Branding{DisplayName: " Acme Academy ", PrimaryColor: "#1a2b3c"},
+	})
+	expectErr(t, err, nil)
+	tenant, err := e.tenant.CurrentTenant(tctx)
+	expectErr(t, err, nil)
+	if tenant.Settings.MentorCapacity != 7 || tenant.Settings.Branding.DisplayName != "Acme Academy" {
+		t.Errorf("settings %+v", tenant.Settings)
+	}
+
+	mentor, err = e.mentor.CreateMentor(tctx, "Bob", "Lead", "", "bob@acme.example", "", nil, 0)
+	expectErr(t, err, nil)
+	if mentor.Capacity != 7 {
+		t.Errorf("capacity %d after the update, want 7", mentor.Capacity)
+	}
+
+	// The default tenant keeps its own settings
+	def, err := e.tenant.CurrentTenant(defaultCtx())
+	expectErr(t, err, nil)
+	if def.ID != domain.DefaultTenantID || def.Settings.MentorCapacity != domain.DefaultMentorCapacity {
+		t.Errorf("default tenant %+v", def)
+	}
+
+	_, err = e.tenant.UpdateSettings(tctx, domain.TenantSettings{Branding: domain.Branding{PrimaryColor: "red"}})
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.tenant.UpdateSettings(tctx, domain.TenantSettings{Branding: domain.Branding{LogoURL: "ftp://acme.example/logo.png"}})
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = e.tenant.UpdateSettings(tctx, domain.TenantSettings{MentorCapacity: 21})
+	expectErr(t, err, domain.ErrInvalidInput)
+}
+
+func TestTenantService_SCIMToken(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	acme, tctx := e.addTenant(t, "acme")
+
+	token, err := e.tenant.IssueSCIMToken(tctx)
+	expectErr(t, err, nil)
+	if !strings.HasPrefix(token, "scim_") {
+		t.Errorf("token %q", token)
+	}
+	tenant, err := e.tenant.ResolveSCIMToken(ctx, token)
+	if err != nil || tenant.ID != acme.ID {
+		t.Fatalf("ResolveSCIMToken = %+v, %v", tenant, err)
+	}
+	if tenant.Settings.SCIM.TokenHash == token {
+		t.Error("the token is stored in the clear")
+	}
+
+	// Updating the settings keeps the token
+	_, err = e.tenant.UpdateSettings(tctx, domain.TenantSettings{MentorCapacity: 4})
+	expectErr(t, err, nil)
+	_, err = e.tenant.UpdateTenant(ctx, acme.ID, "Acme", nil, domain.TenantSettings{})
+	expectErr(t, err, nil)
+	_, err = e.tenant.ResolveSCIMToken(ctx, token)
+	expectErr(t, err, nil)
+
+	// A new token replaces the one before
+	next, err := e.tenant.IssueSCIMToken(tctx)
+	expectErr(t, err, nil)
+	_, err = e.tenant.ResolveSCIMToken(ctx, token)
+	expectErr(t, err, domain.ErrTenantNotFound)
+	_, err = e.tenant.ResolveSCIMToken(ctx, next)
+	expectErr(t, err, nil)
+
+	expectErr(t, e.tenant.RevokeSCIMToken(tctx), nil)
+	_, err = e.tenant.ResolveSCIMToken(ctx, next)
+	expectErr(t, err, domain.ErrTenantNotFound)
+	_, err = e.tenant.ResolveSCIMToken(ctx, "")
+	expectErr(t, err, domain.ErrTenantNotFound)
+}
+
+func TestTenantService_ConcurrentSettingsUpdates(t *testing.T) {
+	e := newEnv(t)
+	_, tctx := e.addTenant(t, "acme")
+	token, err := e.tenant.IssueSCIMToken(tctx)
+	expectErr(t, err, nil)
+
+	// A settings update racing the revocation must not bring the token back
+	var calls atomic.Int32
+	errs := race(10, func() error {
+		if calls.Add(1) == 5 {
+			return e.tenant.RevokeSCIMToken(tctx)
+		}
+		_, err := e.tenant.UpdateSettings(tctx, domain.TenantSettings{MentorCapacity: 4})
+		return err
+	})
+	for _, err := range errs {
+		expectErr(t, err, nil)
+	}
+	_, err = e.tenant.ResolveSCIMToken(defaultCtx(), token)
+	expectErr(t, err, domain.ErrTenantNotFound)
+}
+
+func TestTenantService_HRISSettings(t *testing.T) {
+	e := newEnv(t)
+	_, tctx := e.addTenant(t, "acme")
+
+	_, err := e.tenant.UpdateSettings(tctx, domain.TenantSettings{HRIS: domain.HRISSettings{
+		URL:   " https://hris.acme.example/export ",
+		Token: "hris-token",
+	}})
+	expectErr(t, err, nil)
+	tenant, err := e.tenant.CurrentTenant(tctx)
+	expectErr(t, err, nil)
+	if h := tenant.Settings.HRIS; h.URL != "https://hris.acme.example/export" || h.Token != "hris-token" {
+		t.Errorf("HRIS settings %+v", h)
+	}
+
+	// Clients see the token redacted and send it back to keep it
+	redacted := tenant.Settings.Redacted()
+	if redacted.HRIS.Token != domain.RedactedSecret || tenant.Settings.HRIS.Token != "hris-token" {
+		t.Errorf("redacted %+v of %+v", redacted.HRIS, tenant.Settings.HRIS)
+	}
+	redacted.MentorCapacity = 4
+	tenant, err = e.tenant.UpdateSettings(tctx, redacted)
+	expectErr(t, err, nil)
+	if tenant.Settings.HRIS.Token != "hris-token" || tenant.Settings.MentorCapacity != 4 {
+		t.Errorf("settings %+v after sending the redacted ones back", tenant.Settings)
+	}
+
+	for _, url := range []string{"/etc/passwd", "file:///etc/passwd", "https://", "hris.acme.example"} {
+		_, err = e.tenant.UpdateSettings(tctx, domain.TenantSettings{HRIS: domain.HRISSettings{URL: url}})
+		expectErr(t, err, domain.ErrInvalidInput)
+	}
+}
@@ -0,0 +1,712 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxHRISExportSize caps the size of a fetched HRIS export
+const maxHRISExportSize = 32 << 20
+
+// HRISSource describes where the scheduled sync reads employees from
+type HRISSource struct {
+	// URL is the http(s) URL or the file path of a JSON export
+	URL string
+	// Token is sent as a bearer token with URL requests when set
+	Token string
+	// RecordsKey is the key of the employee array in the export; the export
+	// is the array itself when empty
+	RecordsKey string
+	// Fields maps row fields (name, email, department, jobTitle, telegram,
+	// managerEmail, role) to the keys used by the export; unmapped fields
+	// use their own name
+	Fields map[string]string
+	// Invite emails an invitation to each created user
+	Invite bool
+	Client *http.Client
+	// TenantClient, when set, fetches the exports of tenant settings instead
+	// of Client; tenant admins choose those URLs, so it should only reach
+	// public addresses
+	TenantClient *http.Client
+}
+
+// UserImportService creates and updates users in bulk from CSV files and
+// HRIS exports, keyed by email
+type UserImportService struct {
+	userRepo       domain.UserRepository
+	roleRepo       domain.RoleRepository
+	departmentRepo domain.DepartmentRepository
+	tenantRepo     domain.TenantRepository
+	auth           *AuthService
+	jobs           *JobService
+	mailer         domain.Mailer
+	inviteURL      string
+}
+
+func NewUserImportService(
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	departmentRepo domain.DepartmentRepository,
+	tenantRepo domain.TenantRepository,
+	auth *AuthService,
+	jobs *JobService,
+	mailer domain.Mailer,
+	inviteURL string,
+) *UserImportService {
+	return &UserImportService{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		departmentRepo: departmentRepo,
+		tenantRepo:     tenantRepo,
+		auth:           auth,
+		jobs:           jobs,
+		mailer:         mailer,
+		inviteURL:      inviteURL,
+	}
+}
+
+// importPlan is what the import does with one row
+type importPlan struct {
+	row    domain.UserImportRow
+	result *domain.UserImportResult
+	user   *domain.User // the existing user, nil for a new one
+}
+
+func (p *importPlan) failed() bool {
+	return p.result.Action == domain.ImportFailed
+}
+
+func (p *importPlan) fail(err error) {
+	p.result.Action = domain.ImportFailed
+	p.result.Error = err.Error()
+	p.result.Changes = nil
+}
+
+// Import creates the users of new emails and updates the others. Rows are
+// validated together first: a rejected row is reported and skipped while
+// the other rows go through. Managers may be existing users or other rows of
+// the import. With DryRun nothing is written and the report shows what the
+// import would do.
+func (s *UserImportService) Import(ctx context.Context, rows []domain.UserImportRow, opts domain.UserImportOptions) (*domain.UserImportReport, error) {
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]*domain.User, len(users))
+	byID := make(map[string]*domain.User, len(users))
+	for _, u := range users {
+		byEmail[emailKey(u.Email)] = u
+		byID[u.ID] = u
+	}
+	roles, err := s.roleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roleNames := make(map[domain.UserRole]bool, len(roles))
+	for _, r := range roles {
+		roleNames[r.Name] = true
+	}
+
+	report := &domain.UserImportReport{DryRun: opts.DryRun, Rows: make([]domain.UserImportResult, len(rows))}
+	plans := make([]*importPlan, len(rows))
+	inFile := make(map[string]*importPlan, len(rows))
+	for i, row := range rows {
+		row = trimImportRow(row)
+		report.Rows[i] = domain.UserImportResult{Row: i + 1, Email: row.Email}
+		p := &importPlan{row: row, result: &report.Rows[i]}
+		plans[i] = p
+
+		// Invalid rows are kept in inFile too, so rows they manage are told
+		// why their manager is missing
+		key := emailKey(row.Email)
+		if first, ok := inFile[key]; ok && key != "" {
+			p.fail(fmt.Errorf("%w: duplicate of row %d", domain.ErrInvalidInput, first.result.Row))
+			continue
+		}
+		inFile[key] = p
+		p.user = byEmail[key]
+
+		if err := validateImportRow(row, roleNames); err != nil {
+			p.fail(err)
+			continue
+		}
+		if p.user == nil && row.Name == "" {
+			p.fail(fmt.Errorf("%w: name is required for new users", domain.ErrInvalidInput))
+		}
+	}
+
+	// Rejecting a row can strand the rows it manages, so check until stable
+	for rejected := true; rejected; {
+		rejected = false
+		managerOf := reportingLines(users, byID, plans)
+		for _, p := range plans {
+			if p.failed() || p.row.ManagerEmail == "" {
+				continue
+			}
+			if err := checkImportManager(p, inFile, byEmail, managerOf); err != nil {
+				p.fail(err)
+				rejected = true
+			}
+		}
+	}
+
+	for _, p := range plans {
+		if p.failed() {
+			continue
+		}
+		if p.user == nil {
+			p.result.Action = domain.ImportCreate
+			continue
+		}
+		p.result.UserID = p.user.ID
+		p.result.Changes = importChanges(p.user, p.row, byID)
+		p.result.Action = domain.ImportUpdate
+		if len(p.result.Changes) == 0 {
+			p.result.Action = domain.ImportUnchanged
+		}
+	}
+
+	if !opts.DryRun {
+		s.apply(ctx, plans, byEmail, byID)
+		if opts.Invite {
+			s.invite(ctx, plans)
+		}
+	}
+
+	for _, r := range report.Rows {
+		switch r.Action {
+		case domain.ImportCreate:
+			report.Created++
+		case domain.ImportUpdate:
+			report.Updated++
+		case domain.ImportUnchanged:
+			report.Unchanged++
+		case domain.ImportFailed:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// apply writes the planned rows: users first, then reporting lines, so rows
+// can report to users created further down the file. Each row is written on
+// its own; a failed write only fails its row.
+func (s *UserImportService) apply(ctx context.Context, plans []*importPlan, byEmail, byID map[string]*domain.User) {
+	for _, p := range plans {
+		var err error
+		switch p.result.Action {
+		case domain.ImportCreate:
+			var user *domain.User
+			if user, err = s.createImported(ctx, p.row); err == nil {
+				p.result.UserID = user.ID
+				byEmail[emailKey(user.Email)] = user
+				byID[user.ID] = user
+			}
+		case domain.ImportUpdate:
+			if err = s.applyImportRow(ctx, p.user, p.row); err == nil {
+				err = s.userRepo.Update(ctx, p.user)
+			}
+		}
+		if err != nil {
+			p.fail(err)
+		}
+	}
+
+	for _, p := range plans {
+		if p.failed() || p.row.ManagerEmail == "" {
+			continue
+		}
+		user := byEmail[emailKey(p.row.Email)]
+		manager, ok := byEmail[emailKey(p.row.ManagerEmail)]
+		if !ok {
+			p.fail(fmt.Errorf("manager %s was not imported", p.row.ManagerEmail))
+			continue
+		}
+		if user.ManagerID != nil && *user.ManagerID == manager.ID {
+			continue
+		}
+		if err := s.userRepo.UpdateManager(ctx, user.ID, &manager.ID); err != nil {
+			p.fail(err)
+			continue
+		}
+		user.ManagerID = &manager.ID
+	}
+}
+
+// createImported stores a new user with an unusable random password; they
+// get in through an invitation or a password reset
+func (s *UserImportService) createImported(ctx context.Context, row domain.UserImportRow) (*domain.User, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	// Nobody knows the password, so the cheapest cost keeps large imports
+	// fast without weakening anything
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(random)), bcrypt.MinCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &domain.User{
+		Email:        row.Email,
+		PasswordHash: string(hashedPassword),
+		Role:         domain.RoleEmployee,
+	}
+	if err := s.applyImportRow(ctx, user, row); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// invite queues an invitation email for every created user
+func (s *UserImportService) invite(ctx context.Context, plans []*importPlan) {
+	for _, p := range plans {
+		if p.result.Action != domain.ImportCreate {
+			continue
+		}
+		payload, _ := json.Marshal(map[string]string{"userId": p.result.UserID})
+		_, err := s.jobs.Enqueue(ctx, &domain.Job{
+			Kind:      JobUserInvite,
+			Payload:   payload,
+			UniqueKey: "invite:" + p.result.UserID,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to queue invitation", "userID", p.result.UserID, "error", err)
+			continue
+		}
+		p.result.Invited = true
+	}
+}
+
+// RunInviteJob is the handler of invitation jobs: it emails the user a link
+// to choose their password
+func (s *UserImportService) RunInviteJob(ctx context.Context, job *domain.Job) error {
+	var payload struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid invitation payload: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, payload.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive() {
+		return nil
+	}
+
+	token, err := s.auth.InvitationToken(ctx, user)
+	if err != nil {
+		return err
+	}
+	link, err := url.Parse(s.inviteURL)
+	if err != nil {
+		return fmt.Errorf("invalid invitation url: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	body := fmt.Sprintf(
+		"Hello %s,\n\nAn account was created for you in the corporate learning portal. "+
+			"Follow the link to choose your password; it is valid for %d days:\n\n%s\n",
+		user.Name, int(domain.InvitationTTL.Hours()/24), link,
+	)
+	return s.mailer.Send(ctx, user.Email, "Your corporate learning account", body)
+}
+
+// RegisterSync imports the HRIS export of every tenant that has one on the
+// cron spec, see TenantHRISSource
+func (s *UserImportService) RegisterSync(jobs *JobService, spec string, fallback HRISSource) error {
+	jobs.Register(JobHRISSync, func(ctx context.Context, job *domain.Job) error {
+		source, err := s.TenantHRISSource(ctx, fallback)
+		if errors.Is(err, domain.ErrNoHRIS) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = s.Sync(ctx, source)
+		return err
+	})
+	return jobs.Schedule(JobHRISSync, spec, JobHRISSync, nil)
+}
+
+// TenantHRISSource returns where the employees of the context's tenant are
+// read from: the HRIS export of its settings or, for the default tenant
+// without one, fallback, the export of the configuration. Requests go
+// through the client of fallback.
+func (s *UserImportService) TenantHRISSource(ctx context.Context, fallback HRISSource) (HRISSource, error) {
+	tenant, err := s.tenantRepo.GetByID(ctx, domain.TenantID(ctx))
+	if err != nil {
+		return HRISSource{}, err
+	}
+	source, ok := hrisSourceOf(tenant, fallback)
+	if !ok {
+		return HRISSource{}, domain.ErrNoHRIS
+	}
+	return source, nil
+}
+
+// Sync imports the current HRIS export. Rejected rows are logged rather than
+// failing the sync, since retrying would not fix them.
+func (s *UserImportService) Sync(ctx context.Context, source HRISSource) (*domain.UserImportReport, error) {
+	rows, err := fetchHRISExport(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.Import(ctx, rows, domain.UserImportOptions{Invite: source.Invite})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range report.Rows {
+		if r.Action == domain.ImportFailed {
+			slog.WarnContext(ctx, "HRIS sync rejected a row", "row", r.Row, "email", r.Email, "error", r.Error)
+		}
+	}
+	slog.InfoContext(ctx, "synced users from HRIS",
+		"created", report.Created, "updated", report.Updated, "unchanged", report.Unchanged, "failed", report.Failed)
+	return report, nil
+}
+
+// hrisSourceOf builds the HRIS source of a tenant, see TenantHRISSource
+func hrisSourceOf(tenant *domain.Tenant, fallback HRISSource) (HRISSource, bool) {
+	h := tenant.Settings.HRIS
+	switch {
+	case h.URL != "":
+		return HRISSource{
+			URL:        h.URL,
+			Token:      h.Token,
+			RecordsKey: h.RecordsKey,
+			Fields:     h.Fields,
+			Invite:     h.Invite,
+			Client:     cmp.Or(fallback.TenantClient, fallback.Client),
+		}, true
+	case tenant.ID == domain.DefaultTenantID && fallback.URL != "":
+		return fallback, true
+	default:
+		return HRISSource{}, false
+	}
+}
+
+// fetchHRISExport reads the export from its URL or file
+func fetchHRISExport(ctx context.Context, source HRISSource) ([]domain.UserImportRow, error) {
+	if !isHTTPURL(source.URL) {
+		f, err := os.Open(source.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open HRIS export: %w", err)
+		}
+		defer f.Close()
+		return ParseUserJSON(f, source.RecordsKey, source.Fields)
+	}
+
+	resp, err := requestHRISExport(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ParseUserJSON(io.LimitReader(resp.Body, maxHRISExportSize), source.RecordsKey, source.Fields)
+}
+
+// requestHRISExport requests the export from its URL; the caller closes the
+// body of the 200 response
+func requestHRISExport(ctx context.Context, source HRISSource) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build HRIS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if source.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+source.Token)
+	}
+
+	client := source.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch HRIS export: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch HRIS export: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// ParseUserCSV reads import rows from CSV with a header row. Headers are
+// matched ignoring case, spaces and underscores: name, email, department,
+// job title, telegram, manager email and role; other columns are ignored.
+func ParseUserCSV(r io.Reader) ([]domain.UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse CSV: %v", domain.ErrInvalidInput, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: CSV file is empty", domain.ErrInvalidInput)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[headerKey(name)] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("%w: CSV header has no email column", domain.ErrInvalidInput)
+	}
+
+	rows := make([]domain.UserImportRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		field := func(name string) string {
+			if i, ok := columns[headerKey(name)]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		rows = append(rows, domain.UserImportRow{
+			Name:         field("name"),
+			Email:        field("email"),
+			Department:   field("department"),
+			JobTitle:     field("jobTitle"),
+			Telegram:     field("telegram"),
+			ManagerEmail: field("managerEmail"),
+			Role:         field("role"),
+		})
+	}
+	return rows, nil
+}
+
+// ParseUserJSON reads import rows from a JSON array of employee objects, or
+// from the array under recordsKey. fields maps row fields to the keys of the
+// objects, for exports that name them differently.
+func ParseUserJSON(r io.Reader, recordsKey string, fields map[string]string) ([]domain.UserImportRow, error) {
+	var records []map[string]any
+	if recordsKey == "" {
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse JSON: %v", domain.ErrInvalidInput, err)
+		}
+	} else {
+		var export map[string]json.RawMessage
+		if err := json.NewDecoder(r).Decode(&export); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse JSON: %v", domain.ErrInvalidInput, err)
+		}
+		if err := json.Unmarshal(export[recordsKey], &records); err != nil {
+			return nil, fmt.Errorf("%w: %q is not an array of employees", domain.ErrInvalidInput, recordsKey)
+		}
+	}
+
+	rows := make([]domain.UserImportRow, 0, len(records))
+	for _, record := range records {
+		field := func(name string) string {
+			key := name
+			if mapped, ok := fields[name]; ok && mapped != "" {
+				key = mapped
+			}
+			switch v := record[key].(type) {
+			case nil:
+				return ""
+			case string:
+				return v
+			default:
+				return fmt.Sprint(v)
+			}
+		}
+		rows = append(rows, domain.UserImportRow{
+			Name:         field("name"),
+			Email:        field("email"),
+			Department:   field("department"),
+			JobTitle:     field("jobTitle"),
+			Telegram:     field("telegram"),
+			ManagerEmail: field("managerEmail"),
+			Role:         field("role"),
+		})
+	}
+	return rows, nil
+}
+
+// validateImportRow checks a row on its own against the existing roles
+func validateImportRow(row domain.UserImportRow, roles map[domain.UserRole]bool) error {
+	if row.Email == "" {
+		return fmt.Errorf("%w: email is required", domain.ErrInvalidInput)
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		return domain.ErrInvalidEmail
+	}
+	if row.ManagerEmail != "" {
+		if addr, err := mail.ParseAddress(row.ManagerEmail); err != nil || addr.Address != row.ManagerEmail {
+			return fmt.Errorf("%w: manager email", domain.ErrInvalidEmail)
+		}
+		if emailKey(row.ManagerEmail) == emailKey(row.Email) {
+			return domain.ErrManagerCycle
+		}
+	}
+	if row.Role != "" && !roles[domain.UserRole(row.Role)] {
+		return domain.ErrInvalidRole
+	}
+	return nil
+}
+
+// checkImportManager checks that the manager of a row exists, is active and
+// does not report to the row's user
+func checkImportManager(p *importPlan, inFile map[string]*importPlan, byEmail map[string]*domain.User, managerOf map[string]string) error {
+	key := emailKey(p.row.ManagerEmail)
+	if row, ok := inFile[key]; ok && row.failed() && row.user == nil {
+		return fmt.Errorf("manager %s is rejected in row %d", p.row.ManagerEmail, row.result.Row)
+	}
+	if manager, ok := byEmail[key]; ok && !manager.IsActive() {
+		return fmt.Errorf("manager %s: %w", p.row.ManagerEmail, domain.ErrUserDeactivated)
+	}
+	if _, ok := byEmail[key]; !ok {
+		if _, ok := inFile[key]; !ok {
+			return fmt.Errorf("manager %s: %w", p.row.ManagerEmail, domain.ErrUserNotFound)
+		}
+	}
+
+	own := emailKey(p.row.Email)
+	seen := map[string]bool{}
+	for current := key; current != "" && !seen[current]; current = managerOf[current] {
+		if current == own {
+			return domain.ErrManagerCycle
+		}
+		seen[current] = true
+	}
+	return nil
+}
+
+// reportingLines maps the email of every user to their manager's email as
+// they would be after the import
+func reportingLines(users []*domain.User, byID map[string]*domain.User, plans []*importPlan) map[string]string {
+	managerOf := make(map[string]string, len(users))
+	for _, u := range users {
+		if u.ManagerID == nil {
+			continue
+		}
+		if manager, ok := byID[*u.ManagerID]; ok {
+			managerOf[emailKey(u.Email)] = emailKey(manager.Email)
+		}
+	}
+	for _, p := range plans {
+		if !p.failed() && p.row.ManagerEmail != "" {
+			managerOf[emailKey(p.row.Email)] = emailKey(p.row.ManagerEmail)
+		}
+	}
+	return managerOf
+}
+
+// importChanges lists the fields the row changes on an existing user
+func importChanges(user *domain.User, row domain.UserImportRow, byID map[string]*domain.User) []string {
+	var changes []string
+	if row.Name != "" && row.Name != user.Name {
+		changes = append(changes, "name")
+	}
+	if row.Department != "" && !strings.EqualFold(row.Department, orEmpty(user.Department)) {
+		changes = append(changes, "department")
+	}
+	if row.JobTitle != "" && row.JobTitle != orEmpty(user.JobTitle) {
+		changes = append(changes, "jobTitle")
+	}
+	if row.Telegram != "" && row.Telegram != orEmpty(user.Telegram) {
+		changes = append(changes, "telegram")
+	}
+	if row.Role != "" && domain.UserRole(row.Role) != user.Role {
+		changes = append(changes, "role")
+	}
+	if row.ManagerEmail != "" {
+		current := ""
+		if user.ManagerID != nil {
+			if manager, ok := byID[*user.ManagerID]; ok {
+				current = emailKey(manager.Email)
+			}
+		}
+		if current != emailKey(row.ManagerEmail) {
+			changes = append(changes, "manager")
+		}
+	}
+	return changes
+}
+
+// applyImportRow copies the non-empty fields of a row to a user, except the
+// manager. Departments are matched by name and created when missing.
+func (s *UserImportService) applyImportRow(ctx context.Context, user *domain.User, row domain.UserImportRow) error {
+	if row.Name != "" {
+		user.Name = row.Name
+	}
+	if row.Department != "" {
+		departmentID, err := provisionDepartment(ctx, s.departmentRepo, row.Department)
+		if err != nil {
+			return err
+		}
+		user.DepartmentID = departmentID
+	}
+	if row.JobTitle != "" {
+		user.JobTitle = &row.JobTitle
+	}
+	if row.Telegram != "" {
+		user.Telegram = &row.Telegram
+	}
+	if row.Role != "" {
+		user.Role = domain.UserRole(row.Role)
+	}
+	return nil
+}
+
+// trimImportRow trims the fields of a row and lowercases the role
+func trimImportRow(row domain.UserImportRow) domain.UserImportRow {
+	return domain.UserImportRow{
+		Name:         strings.TrimSpace(row.Name),
+		Email:        strings.TrimSpace(row.Email),
+		Department:   strings.TrimSpace(row.Department),
+		JobTitle:     strings.TrimSpace(row.JobTitle),
+		Telegram:     strings.TrimSpace(row.Telegram),
+		ManagerEmail: strings.TrimSpace(row.ManagerEmail),
+		Role:         strings.ToLower(strings.TrimSpace(row.Role)),
+	}
+}
+
+// orEmpty returns the value of an optional field, or "" when unset
+func orEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// emailKey is the form emails are matched in
+func emailKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// headerKey normalizes a CSV header or row field name for matching
+func headerKey(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.TrimSpace(name)))
+}
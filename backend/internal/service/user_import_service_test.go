@@ -0,0 +1,417 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+const testInviteURL = "https://learning.example.com/invite"
+
+// mailbox is a mailer that keeps the emails it is given
+type mailbox struct {
+	mu   sync.Mutex
+	sent []sentMail
+}
+
+type sentMail struct {
+	to, subject, body string
+}
+
+func (m *mailbox) Send(ctx context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentMail{to: to, subject: subject, body: body})
+	return nil
+}
+
+// inviteToken extracts the token from the link in an invitation email
+func inviteToken(t *testing.T, mail sentMail) string {
+	t.Helper()
+
+	for _, field := range strings.Fields(mail.body) {
+		if strings.HasPrefix(field, testInviteURL) {
+			link, err := url.Parse(field)
+			if err != nil {
+				t.Fatalf("invitation link: %v", err)
+			}
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no invitation link in %q", mail.body)
+	return ""
+}
+
+// importActions lists the action of every row of a report
+func importActions(report *domain.UserImportReport) []domain.ImportAction {
+	actions := make([]domain.ImportAction, len(report.Rows))
+	for i, r := range report.Rows {
+		actions[i] = r.Action
+	}
+	return actions
+}
+
+func TestUserImportService_DryRunThenImport(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	alice := e.addUser(t, "alice")
+	bob := e.addUser(t, "bob")
+	bob.DepartmentID = &e.addDepartment(t, "Sales", nil).ID
+	expectErr(t, e.users.Update(ctx, bob), nil)
+
+	rows := []domain.UserImportRow{
+		{Email: "ALICE@example.com", Department: "R&D", JobTitle: "Engineer", ManagerEmail: "carol@example.com"},
+		{Email: "bob@example.com", Department: "Sales"},
+		{Name: "Carol", Email: " carol@example.com ", Role: "Admin"},
+	}
+
+	dry, err := e.userImport.Import(ctx, rows, domain.UserImportOptions{DryRun: true})
+	expectErr(t, err, nil)
+	want := []domain.ImportAction{domain.ImportUpdate, domain.ImportUnchanged, domain.ImportCreate}
+	if got := importActions(dry); !reflect.DeepEqual(got, want) {
+		t.Fatalf("dry run actions = %v, want %v", got, want)
+	}
+	if !dry.DryRun || dry.Created != 1 || dry.Updated != 1 || dry.Unchanged != 1 || dry.Failed != 0 {
+		t.Errorf("dry run counts = %+v", dry)
+	}
+	if got := dry.Rows[0].Changes; !reflect.DeepEqual(got, []string{"department", "jobTitle", "manager"}) {
+		t.Errorf("dry run changes = %v", got)
+	}
+	if _, err := e.users.GetByEmail(ctx, "carol@example.com"); err == nil {
+		t.Fatal("dry run created a user")
+	}
+
+	report, err := e.userImport.Import(ctx, rows, domain.UserImportOptions{})
+	expectErr(t, err, nil)
+	if got := importActions(report); !reflect.DeepEqual(got, want) {
+		t.Fatalf("import actions = %v, want %v", got, want)
+	}
+
+	carol, err := e.users.GetByEmail(ctx, "carol@example.com")
+	expectErr(t, err, nil)
+	if carol.ID != report.Rows[2].UserID || carol.Name != "Carol" || carol.Role != domain.RoleAdmin {
+		t.Errorf("created user = %+v", carol)
+	}
+	stored, err := e.users.GetByID(ctx, alice.ID)
+	expectErr(t, err, nil)
+	if stored.Name != "alice" || *stored.Department != "R&D" || *stored.JobTitle != "Engineer" {
+		t.Errorf("updated user = %+v", stored)
+	}
+	if stored.ManagerID == nil || *stored.ManagerID != carol.ID {
+		t.Errorf("manager = %v, want %s", stored.ManagerID, carol.ID)
+	}
+	if departments, _ := e.departments.GetAll(ctx); len(departments) != 2 {
+		t.Errorf("departments = %+v, want R&D added next to Sales", departments)
+	}
+
+	again, err := e.userImport.Import(ctx, rows, domain.UserImportOptions{})
+	expectErr(t, err, nil)
+	if again.Unchanged != 3 {
+		t.Errorf("reimport = %+v, want every row unchanged", importActions(again))
+	}
+}
+
+func TestUserImportService_RejectsRows(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	boss := e.addUser(t, "boss")
+	gone := e.addUser(t, "gone")
+	_, err := e.user.DeactivateUser(ctx, gone.ID, boss.ID)
+	expectErr(t, err, nil)
+	dave := e.addUser(t, "dave")
+	_, err = e.user.SetManager(ctx, boss.ID, &dave.ID)
+	expectErr(t, err, nil)
+
+	report, err := e.userImport.Import(ctx, []domain.UserImportRow{
+		{Name: "No Email"},
+		{Name: "Bad", Email: "not-an-email"},
+		{Email: "new@example.com"},
+		{Name: "Root", Email: "root@example.com", Role: "owner"},
+		{Name: "Ok", Email: "ok@example.com"},
+		{Name: "Ok Again", Email: "OK@example.com"},
+		{Name: "Orphan", Email: "orphan@example.com", ManagerEmail: "nobody@example.com"},
+		{Name: "Stray", Email: "stray@example.com", ManagerEmail: "gone@example.com"},
+		{Name: "Loop", Email: "dave@example.com", ManagerEmail: "boss@example.com"},
+		{Name: "Under", Email: "under@example.com", ManagerEmail: "root@example.com"},
+		{Name: "Self", Email: "self@example.com", ManagerEmail: "self@example.com"},
+	}, domain.UserImportOptions{})
+	expectErr(t, err, nil)
+
+	want := []domain.ImportAction{
+		domain.ImportFailed, domain.ImportFailed, domain.ImportFailed, domain.ImportFailed,
+		domain.ImportCreate, domain.ImportFailed, domain.ImportFailed, domain.ImportFailed,
+		domain.ImportFailed, domain.ImportFailed, domain.ImportFailed,
+	}
+	if got := importActions(report); !reflect.DeepEqual(got, want) {
+		t.Fatalf("actions = %v, want %v", got, want)
+	}
+	if report.Created != 1 || report.Failed != 10 {
+		t.Errorf("counts = %+v", report)
+	}
+	for i, wantErr := range map[int]string{
+		0:  "email is required",
+		2:  "name is required",
+		3:  domain.ErrInvalidRole.Error(),
+		5:  "duplicate of row 5",
+		6:  domain.ErrUserNotFound.Error(),
+		7:  domain.ErrUserDeactivated.Error(),
+		8:  domain.ErrManagerCycle.Error(),
+		9:  "rejected in row 4",
+		10: domain.ErrManagerCycle.Error(),
+	} {
+		if !strings.Contains(report.Rows[i].Error, wantErr) {
+			t.Errorf("row %d error = %q, want %q", i+1, report.Rows[i].Error, wantErr)
+		}
+	}
+
+	stored, err := e.users.GetByID(ctx, dave.ID)
+	expectErr(t, err, nil)
+	if stored.Name != "dave" || stored.ManagerID != nil {
+		t.Errorf("rejected row changed the user: %+v", stored)
+	}
+}
+
+func TestUserImportService_Invitations(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	e.addUser(t, "alice")
+
+	report, err := e.userImport.Import(ctx, []domain.UserImportRow{
+		{Email: "alice@example.com", JobTitle: "Engineer"},
+		{Name: "Carol", Email: "carol@example.com"},
+	}, domain.UserImportOptions{Invite: true})
+	expectErr(t, err, nil)
+	if report.Rows[0].Invited || !report.Rows[1].Invited {
+		t.Fatalf("invited = %v %v, want only the created user", report.Rows[0].Invited, report.Rows[1].Invited)
+	}
+
+	expectErr(t, e.job.RunDue(ctx, time.Now().Add(time.Second)), nil)
+	if len(e.mail.sent) != 1 || e.mail.sent[0].to != "carol@example.com" {
+		t.Fatalf("sent = %+v, want one invitation to carol", e.mail.sent)
+	}
+	token := inviteToken(t, e.mail.sent[0])
+
+	// The random password of an imported user cannot be guessed
+	_, _, err = e.auth.Login(ctx, "carol@example.com", "")
+	expectErr(t, err, domain.ErrInvalidCredentials)
+
+	_, _, err = e.auth.AcceptInvitation(ctx, token, "short")
+	expectErr(t, err, domain.ErrWeakPassword)
+	_, _, err = e.auth.AcceptInvitation(ctx, "garbage", "password123")
+	expectErr(t, err, domain.ErrInvalidInvitation)
+
+	access, user, err := e.auth.AcceptInvitation(ctx, token, "password123")
+	expectErr(t, err, nil)
+	if access == "" || user.Email != "carol@example.com" || user.PasswordHash != "" {
+		t.Errorf("AcceptInvitation = %q, %+v", access, user)
+	}
+	if _, err := e.auth.ValidateToken(token); err == nil {
+		t.Error("an invitation was accepted as an access token")
+	}
+	_, _, err = e.auth.Login(ctx, "carol@example.com", "password123")
+	expectErr(t, err, nil)
+
+	// Setting the password used the invitation up
+	_, _, err = e.auth.AcceptInvitation(ctx, token, "another-password")
+	expectErr(t, err, domain.ErrInvalidInvitation)
+}
+
+func TestUserImportService_InvitationOfDeactivatedUser(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	admin := e.addUser(t, "admin")
+
+	report, err := e.userImport.Import(ctx, []domain.UserImportRow{{Name: "Carol", Email: "carol@example.com"}}, domain.UserImportOptions{})
+	expectErr(t, err, nil)
+	carol, err := e.users.GetByID(ctx, report.Rows[0].UserID)
+	expectErr(t, err, nil)
+	token, err := e.auth.InvitationToken(ctx, carol)
+	expectErr(t, err, nil)
+
+	_, err = e.user.DeactivateUser(ctx, carol.ID, admin.ID)
+	expectErr(t, err, nil)
+	_, _, err = e.auth.AcceptInvitation(ctx, token, "password123")
+	expectErr(t, err, domain.ErrUserDeactivated)
+}
+
+func TestParseUserCSV(t *testing.T) {
+	rows, err := service.ParseUserCSV(strings.NewReader(
+		"Email,Full Name,Job_Title,Manager Email,Role,Extra\n" +
+			"carol@example.com,Carol,Engineer,boss@example.com,admin,x\n" +
+			"dave@example.com,Dave\n"))
+	expectErr(t, err, nil)
+	want := []domain.UserImportRow{
+		{Email: "carol@example.com", JobTitle: "Engineer", ManagerEmail: "boss@example.com", Role: "admin"},
+		{Email: "dave@example.com"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %+v, want %+v", rows, want)
+	}
+
+	_, err = service.ParseUserCSV(strings.NewReader("name,department\nCarol,R&D\n"))
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = service.ParseUserCSV(strings.NewReader(""))
+	expectErr(t, err, domain.ErrInvalidInput)
+}
+
+func TestParseUserJSON(t *testing.T) {
+	export := `{"employees": [{"fullName": "Carol", "workEmail": "carol@example.com", "department": "R&D", "grade": 3}]}`
+	rows, err := service.ParseUserJSON(strings.NewReader(export), "employees", map[string]string{
+		"name":     "fullName",
+		"email":    "workEmail",
+		"jobTitle": "grade",
+	})
+	expectErr(t, err, nil)
+	want := []domain.UserImportRow{{Name: "Carol", Email: "carol@example.com", Department: "R&D", JobTitle: "3"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %+v, want %+v", rows, want)
+	}
+
+	rows, err = service.ParseUserJSON(strings.NewReader(`[{"name": "Dave", "email": "dave@example.com"}]`), "", nil)
+	expectErr(t, err, nil)
+	if len(rows) != 1 || rows[0].Email != "dave@example.com" {
+		t.Errorf("rows = %+v", rows)
+	}
+
+	_, err = service.ParseUserJSON(strings.NewReader(export), "people", nil)
+	expectErr(t, err, domain.ErrInvalidInput)
+	_, err = service.ParseUserJSON(strings.NewReader(`{`), "", nil)
+	expectErr(t, err, domain.ErrInvalidInput)
+}
+
+func TestUserImportService_SyncFromURL(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [{"name": "Carol", "mail": "carol@example.com"}, {"name": "Bad"}]}`))
+	}))
+	defer server.Close()
+
+	report, err := e.userImport.Sync(ctx, service.HRISSource{
+		URL:        server.URL,
+		Token:      "hris-token",
+		RecordsKey: "data",
+		Fields:     map[string]string{"email": "mail"},
+		Invite:     true,
+		Client:     server.Client(),
+	})
+	expectErr(t, err, nil)
+	if auth != "Bearer hris-token" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if report.Created != 1 || report.Failed != 1 || !report.Rows[0].Invited {
+		t.Errorf("report = %+v", report)
+	}
+	if _, err := e.users.GetByEmail(ctx, "carol@example.com"); err != nil {
+		t.Errorf("synced user: %v", err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	_, err = e.userImport.Sync(ctx, service.HRISSource{URL: failing.URL, Client: failing.Client()})
+	expectErr(t, err, errAny)
+}
+
+func TestUserImportService_ScheduledSyncFromFile(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	path := filepath.Join(t.TempDir(), "hris.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "Carol", "email": "carol@example.com"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	expectErr(t, e.userImport.RegisterSync(e.job, "@every 1h", service.HRISSource{URL: path}), nil)
+	now := time.Now()
+	expectErr(t, e.job.RunDue(ctx, now), nil)
+	if _, err := e.users.GetByEmail(ctx, "carol@example.com"); err == nil {
+		t.Fatal("synced before the first fire time")
+	}
+	expectErr(t, e.job.RunDue(ctx, now.Add(2*time.Hour)), nil)
+
+	if _, err := e.users.GetByEmail(ctx, "carol@example.com"); err != nil {
+		t.Errorf("synced user: %v", err)
+	}
+	if len(e.mail.sent) != 0 {
+		t.Errorf("sent %d invitations without Invite", len(e.mail.sent))
+	}
+
+	expectErr(t, e.userImport.RegisterSync(e.job, "not a schedule", service.HRISSource{URL: path}), errAny)
+}
+
+func TestUserImportService_ScheduledSyncPerTenant(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	_, acme := e.addTenant(t, "acme")
+	_, globex := e.addTenant(t, "globex")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/default":
+			w.Write([]byte(`[{"name": "Dave", "email": "dave@example.com"}]`))
+		case "/acme":
+			w.Write([]byte(`{"staff": [{"name": "Carol", "email": "carol@acme.example"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	_, err := e.tenant.UpdateSettings(acme, domain.TenantSettings{HRIS: domain.HRISSettings{URL: server.URL + "/acme", RecordsKey: "staff"}})
+	expectErr(t, err, nil)
+
+	// The configured HRIS fills the default tenant, the tenant's own HRIS
+	// the others; tenants without one are left alone
+	fallback := service.HRISSource{URL: server.URL + "/default", Client: server.Client()}
+	expectErr(t, e.userImport.RegisterSync(e.job, "@every 1h", fallback), nil)
+	now := time.Now()
+	expectErr(t, e.job.RunDue(ctx, now), nil)
+	expectErr(t, e.job.RunDue(ctx, now.Add(2*time.Hour)), nil)
+
+	if _, err := e.users.GetByEmail(ctx, "dave@example.com"); err != nil {
+		t.Errorf("default tenant user: %v", err)
+	}
+	if _, err := e.users.GetByEmail(acme, "carol@acme.example"); err != nil {
+		t.Errorf("acme user: %v", err)
+	}
+	for _, c := range []context.Context{ctx, globex} {
+		if _, err := e.users.GetByEmail(c, "carol@acme.example"); err == nil {
+			t.Errorf("acme user synced into tenant %s", domain.TenantID(c))
+		}
+	}
+	if _, err := e.users.GetByEmail(acme, "dave@example.com"); err == nil {
+		t.Error("default tenant user synced into acme")
+	}
+
+	_, err = e.userImport.TenantHRISSource(globex, fallback)
+	expectErr(t, err, domain.ErrNoHRIS)
+	source, err := e.userImport.TenantHRISSource(acme, fallback)
+	if err != nil || source.URL != server.URL+"/acme" || source.Client != server.Client() {
+		t.Errorf("acme source %+v, %v", source, err)
+	}
+
+	// Exports chosen by tenant admins go through the tenant client
+	fallback.TenantClient = &http.Client{}
+	source, err = e.userImport.TenantHRISSource(acme, fallback)
+	if err != nil || source.Client != fallback.TenantClient {
+		t.Errorf("acme source client %p, %v, want the tenant client", source.Client, err)
+	}
+	source, err = e.userImport.TenantHRISSource(ctx, fallback)
+	if err != nil || source.Client != server.Client() {
+		t.Errorf("default tenant source client %p, %v, want the configured client", source.Client, err)
+	}
+}
@@ -2,33 +2,222 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
-	userRepo domain.UserRepository
+	userRepo       domain.UserRepository
+	roleRepo       domain.RoleRepository
+	departmentRepo domain.DepartmentRepository
 }
 
-func NewUserService(userRepo domain.UserRepository) *UserService {
+func NewUserService(userRepo domain.UserRepository, roleRepo domain.RoleRepository, departmentRepo domain.DepartmentRepository) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		departmentRepo: departmentRepo,
 	}
 }
 
-// GetUserByID retrieves a user by ID (admin only)
+// GetUserByID retrieves a user by ID
 func (s *UserService) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
 	return s.userRepo.GetByID(ctx, id)
 }
 
-// GetAllUsers retrieves all users (admin only)
+// GetUserByEmail retrieves a user by email
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return s.userRepo.GetByEmail(ctx, email)
+}
+
+// GetAllUsers retrieves all users
 func (s *UserService) GetAllUsers(ctx context.Context) ([]*domain.User, error) {
 	return s.userRepo.GetAll(ctx)
 }
 
-// UpdateUser updates user information (admin only)
+// CreateUser creates a user with the given role (admin tooling); the
+// department, when given, must exist
+func (s *UserService) CreateUser(ctx context.Context, name, email, password string, role domain.UserRole, department, jobTitle, telegram *string) (*domain.User, error) {
+	if name == "" || email == "" {
+		return nil, fmt.Errorf("%w: name and email are required", domain.ErrInvalidInput)
+	}
+	if _, err := s.role(ctx, role); err != nil {
+		return nil, err
+	}
+	if len(password) < 8 {
+		return nil, domain.ErrWeakPassword
+	}
+
+	existingUser, _ := s.userRepo.GetByEmail(ctx, email)
+	if existingUser != nil {
+		return nil, domain.ErrUserAlreadyExists
+	}
+
+	departmentID, err := lookupDepartment(ctx, s.departmentRepo, orEmpty(department))
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &domain.User{
+		Name:         name,
+		Email:        email,
+		PasswordHash: string(hashedPassword),
+		Role:         role,
+		DepartmentID: departmentID,
+		JobTitle:     jobTitle,
+		Telegram:     telegram,
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ChangeRole sets a user's role, e.g. promoting an employee to admin. The
+// last active user who can manage roles keeps a role that can.
+func (s *UserService) ChangeRole(ctx context.Context, id string, role domain.UserRole) (*domain.User, error) {
+	granted, err := s.role(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !granted.Has(domain.PermRolesManage) {
+		if err := s.keepRoleManager(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ResetPassword replaces a user's password
+func (s *UserService) ResetPassword(ctx context.Context, id, password string) (*domain.User, error) {
+	if len(password) < 8 {
+		return nil, domain.ErrWeakPassword
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = string(hashedPassword)
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// DeactivateUser blocks a user from signing in while keeping their requests
+// and learnings; actorID is the admin performing the change
+func (s *UserService) DeactivateUser(ctx context.Context, id, actorID string) (*domain.User, error) {
+	if id == actorID {
+		return nil, domain.ErrCannotDeactivateSelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return user, nil
+	}
+	if err := s.keepRoleManager(ctx, user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.userRepo.UpdateDeactivatedAt(ctx, id, &now); err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// ReactivateUser lets a deactivated user sign in again
+func (s *UserService) ReactivateUser(ctx context.Context, id string) (*domain.User, error) {
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateDeactivatedAt(ctx, id, nil); err != nil {
+		return nil, fmt.Errorf("failed to reactivate user: %w", err)
+	}
+
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// SetManager sets the line manager who signs off the user's training
+// requests, or clears it when managerID is nil. The manager must be active
+// and reporting lines may not loop back to the user.
+func (s *UserService) SetManager(ctx context.Context, id string, managerID *string) (*domain.User, error) {
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if managerID != nil {
+		manager, err := s.userRepo.GetByID(ctx, *managerID)
+		if err != nil {
+			return nil, fmt.Errorf("manager not found: %w", err)
+		}
+		if !manager.IsActive() {
+			return nil, domain.ErrUserDeactivated
+		}
+
+		// Walk up from the new manager; meeting the user means a cycle
+		seen := map[string]bool{}
+		for current := manager; ; {
+			if current.ID == id {
+				return nil, domain.ErrManagerCycle
+			}
+			if current.ManagerID == nil || seen[current.ID] {
+				break
+			}
+			seen[current.ID] = true
+
+			current, err = s.userRepo.GetByID(ctx, *current.ManagerID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get manager: %w", err)
+			}
+		}
+	}
+
+	if err := s.userRepo.UpdateManager(ctx, id, managerID); err != nil {
+		return nil, fmt.Errorf("failed to update manager: %w", err)
+	}
+
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// UpdateUser updates user information. The department is picked by name
+// among the existing ones; a blank name removes the user from their
+// department.
 func (s *UserService) UpdateUser(ctx context.Context, id string, name, email, department, jobTitle, telegram *string, password *string) (*domain.User, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
@@ -43,7 +232,10 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, name, email, de
 		user.Email = *email
 	}
 	if department != nil {
-		user.Department = department
+		user.DepartmentID, err = lookupDepartment(ctx, s.departmentRepo, *department)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if jobTitle != nil {
 		user.JobTitle = jobTitle
@@ -73,3 +265,34 @@ func (s *UserService) UpdateCurrentUser(ctx context.Context, userID string, name
 	// Same as UpdateUser but for current user
 	return s.UpdateUser(ctx, userID, name, email, department, jobTitle, telegram, password)
 }
+
+// role looks up an assignable role, answering ErrInvalidRole for unknown ones
+func (s *UserService) role(ctx context.Context, name domain.UserRole) (*domain.Role, error) {
+	if !name.IsValid() {
+		return nil, domain.ErrInvalidRole
+	}
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrRoleNotFound) {
+		return nil, fmt.Errorf("%w: role %q does not exist", domain.ErrInvalidRole, name)
+	}
+	return role, err
+}
+
+// keepRoleManager fails with ErrLastAdmin when user is the only active user
+// who can manage roles, so that nobody would be left to grant access
+func (s *UserService) keepRoleManager(ctx context.Context, user *domain.User) error {
+	if !user.IsActive() || !user.Can(domain.PermRolesManage) {
+		return nil
+	}
+
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, u := range users {
+		if u.ID != user.ID && u.IsActive() && u.Can(domain.PermRolesManage) {
+			return nil
+		}
+	}
+	return domain.ErrLastAdmin
+}
@@ -0,0 +1,263 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+func TestUserService_Getters(t *testing.T) {
+	e := newEnv(t)
+	ctx := defaultCtx()
+	alice := e.addUser(t, "alice")
+	e.addUser(t, "bob")
+
+	byID, err := e.user.GetUserByID(ctx, alice.ID)
+	if err != nil || byID.Email != alice.Email {
+		t.Errorf("GetUserByID = %+v, %v", byID, err)
+	}
+	byEmail, err := e.user.GetUserByEmail(ctx, alice.Email)
+	if err != nil || byEmail.ID != alice.ID {
+		t.Errorf("GetUserByEmail = %+v, %v", byEmail, err)
+	}
+	all, err := e.user.GetAllUsers(ctx)
+	if err != nil || len(all) != 2 {
+		t.Errorf("GetAllUsers returned %d users, %v", len(all), err)
+	}
+	if _, err := e.user.GetUserByID(ctx, "missing"); err == nil {
+		t.Error("GetUserByID for a missing user succeeded")
+	}
+}
+
+func TestUserService_CreateUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		email    string
+		password string
+		role     domain.UserRole
+		wantErr  error
+	}{
+		{name: "admin", userName: "Root", email: "root@example.com", password: "password123", role: domain.RoleAdmin},
+		{name: "employee", userName: "Eve", email: "eve@example.com", password: "password123", role: domain.RoleEmployee},
+		{name: "missing name", email: "x@example.com", password: "password123", role: domain.RoleEmployee, wantErr: domain.ErrInvalidInput},
+		{name: "missing email", userName: "X", password: "password123", role: domain.RoleEmployee, wantErr: domain.ErrInvalidInput},
+		{name: "unknown role", userName: "X", email: "x@example.com", password: "password123", role: "owner", wantErr: domain.ErrInvalidRole},
+		{name: "short password", userName: "X", email: "x@example.com", password: "short", role: domain.RoleEmployee, wantErr: domain.ErrWeakPassword},
+		{name: "duplicate email", userName: "X", email: "taken@example.com", password: "password123", role: domain.RoleEmployee, wantErr: domain.ErrUserAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			e.addUser(t, "taken")
+
+			user, err := e.user.CreateUser(defaultCtx(), tt.userName, tt.email, tt.password, tt.role, nil, ptr("Lead"), nil)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if user.Role != tt.role || user.JobTitle == nil || *user.JobTitle != "Lead" {
+				t.Errorf("CreateUser returned %+v", user)
+			}
+			if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(tt.password)) != nil {
+				t.Error("password hash does not match")
+			}
+		})
+	}
+}
+
+func TestUserService_ChangeRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing bool
+		role    domain.UserRole
+		wantErr error
+	}{
+		{name: "promote", role: domain.RoleAdmin},
+		{name: "demote", role: domain.RoleEmployee},
+		{name: "unknown role", role: "owner", wantErr: domain.ErrInvalidRole},
+		{name: "missing user", missing: true, role: domain.RoleAdmin, wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			id := e.addUser(t, "alice").ID
+			e.addDepartment(t, "QA", nil)
+			if tt.missing {
+				id = "missing"
+			}
+
+			_, err := e.user.ChangeRole(defaultCtx(), id, tt.role)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			stored, _ := e.users.GetByID(defaultCtx(), id)
+			if stored.Role != tt.role {
+				t.Errorf("role = %s, want %s", stored.Role, tt.role)
+			}
+		})
+	}
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		missing  bool
+		password string
+		wantErr  error
+	}{
+		{name: "valid", password: "new-password"},
+		{name: "too short", password: "1234567", wantErr: domain.ErrWeakPassword},
+		{name: "missing user", missing: true, password: "new-password", wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			id := e.addUser(t, "alice").ID
+			e.addDepartment(t, "QA", nil)
+			if tt.missing {
+				id = "missing"
+			}
+
+			_, err := e.user.ResetPassword(defaultCtx(), id, tt.password)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			stored, _ := e.users.GetByID(defaultCtx(), id)
+			if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte(tt.password)) != nil {
+				t.Error("stored hash does not match the new password")
+			}
+		})
+	}
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		missing      bool
+		userName     *string
+		email        *string
+		department   *string
+		password     *string
+		wantName     string
+		wantEmail    string
+		wantPassword bool
+		wantErr      error
+	}{
+		{name: "no changes", wantName: "alice", wantEmail: "alice@example.com"},
+		{name: "rename", userName: ptr("Alice"), wantName: "Alice", wantEmail: "alice@example.com"},
+		{name: "change email and department", email: ptr("a@example.com"), department: ptr("QA"), wantName: "alice", wantEmail: "a@example.com"},
+		{name: "unknown department", department: ptr("Legal"), wantErr: domain.ErrInvalidInput},
+		{name: "change password", password: ptr("new-password"), wantName: "alice", wantEmail: "alice@example.com", wantPassword: true},
+		{name: "empty password keeps hash", password: ptr(""), wantName: "alice", wantEmail: "alice@example.com"},
+		{name: "missing user", missing: true, wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			id := e.addUser(t, "alice").ID
+			e.addDepartment(t, "QA", nil)
+			if tt.missing {
+				id = "missing"
+			}
+
+			_, err := e.user.UpdateUser(defaultCtx(), id, tt.userName, tt.email, tt.department, nil, nil, tt.password)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			stored, _ := e.users.GetByID(defaultCtx(), id)
+			if stored.Name != tt.wantName || stored.Email != tt.wantEmail {
+				t.Errorf("stored name/email = %q/%q, want %q/%q", stored.Name, stored.Email, tt.wantName, tt.wantEmail)
+			}
+			if tt.department != nil && (stored.Department == nil || *stored.Department != *tt.department) {
+				t.Errorf("department = %v, want %s", stored.Department, *tt.department)
+			}
+			if changed := stored.PasswordHash != "hash"; changed != tt.wantPassword {
+				t.Errorf("password changed = %v, want %v", changed, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestUserService_UpdateCurrentUser(t *testing.T) {
+	e := newEnv(t)
+	user := e.addUser(t, "alice")
+
+	updated, err := e.user.UpdateCurrentUser(defaultCtx(), user.ID, nil, nil, nil, ptr("Engineer"), ptr("@alice"), nil)
+	if err != nil {
+		t.Fatalf("UpdateCurrentUser: %v", err)
+	}
+	if updated.JobTitle == nil || *updated.JobTitle != "Engineer" || updated.Telegram == nil || *updated.Telegram != "@alice" {
+		t.Errorf("UpdateCurrentUser returned %+v", updated)
+	}
+}
+
+func TestUserService_DeactivateUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		self        bool
+		missing     bool
+		deactivated bool
+		wantErr     error
+	}{
+		{name: "active user"},
+		{name: "already deactivated", deactivated: true},
+		{name: "self", self: true, wantErr: domain.ErrCannotDeactivateSelf},
+		{name: "missing user", missing: true, wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t)
+			ctx := defaultCtx()
+			admin := e.addUser(t, "admin")
+			id := e.addUser(t, "alice").ID
+			switch {
+			case tt.self:
+				id = admin.ID
+			case tt.missing:
+				id = "missing"
+			}
+			if tt.deactivated {
+				if _, err := e.user.DeactivateUser(ctx, id, admin.ID); err != nil {
+					t.Fatalf("first deactivation: %v", err)
+				}
+			}
+
+			user, err := e.user.DeactivateUser(ctx, id, admin.ID)
+			expectErr(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if user.IsActive() {
+				t.Errorf("DeactivateUser returned active user %+v", user)
+			}
+			if err := e.auth.CheckUserActive(ctx, id); !errors.Is(err, domain.ErrUserDeactivated) {
+				t.Errorf("CheckUserActive = %v, want ErrUserDeactivated", err)
+			}
+
+			user, err = e.user.ReactivateUser(ctx, id)
+			if err != nil || !user.IsActive() {
+				t.Fatalf("ReactivateUser = %+v, %v", user, err)
+			}
+			if err := e.auth.CheckUserActive(ctx, id); err != nil {
+				t.Errorf("CheckUserActive after reactivation = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// Headers of a webhook request
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// outboxBatchSize is how many events are fanned out per transaction
+	outboxBatchSize = 100
+	// deliveryBatchSize is how many deliveries are sent at once
+	deliveryBatchSize = 20
+	// maxResponseSnippet caps the part of an error response kept with the
+	// failed attempt
+	maxResponseSnippet = 500
+)
+
+// WebhookService fans the events of the outbox out to the subscribed
+// endpoints of other systems and delivers them with a signature. Each
+// delivery is attempted until the endpoint answers 2xx, with exponential
+// backoff between attempts; deliveries that run out of attempts are dead
+// and wait for an admin to redeliver them.
+type WebhookService struct {
+	tx          domain.TxManager
+	outboxRepo  domain.OutboxRepository
+	webhookRepo domain.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+}
+
+func NewWebhookService(
+	tx domain.TxManager,
+	outboxRepo domain.OutboxRepository,
+	webhookRepo domain.WebhookRepository,
+	client *http.Client,
+	maxAttempts int,
+) *WebhookService {
+	if maxAttempts < 1 {
+		maxAttempts = domain.DefaultWebhookMaxAttempts
+	}
+	return &WebhookService{
+		tx:          tx,
+		outboxRepo:  outboxRepo,
+		webhookRepo: webhookRepo,
+		client:      client,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// RegisterJobs dispatches the outbox of every tenant on the dispatch cron
+// spec and deletes delivered events older than retention on the cleanup spec
+func (s *WebhookService) RegisterJobs(jobs *JobService, dispatchSpec, cleanupSpec string, retention time.Duration) error {
+	jobs.Register(JobWebhooks, s.RunJob)
+	jobs.Register(JobOutboxCleanup, func(ctx context.Context, job *domain.Job) error {
+		deleted, err := s.outboxRepo.DeleteDispatched(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "deleted delivered outbox events", "count", deleted)
+		return nil
+	})
+
+	if err := jobs.Schedule(JobWebhooks, dispatchSpec, JobWebhooks, nil); err != nil {
+		return err
+	}
+	return jobs.Schedule(JobOutboxCleanup, cleanupSpec, JobOutboxCleanup, nil)
+}
+
+// RunJob is the handler of the dispatch job
+func (s *WebhookService) RunJob(ctx context.Context, _ *domain.Job) error {
+	return s.DispatchDue(ctx, time.Now())
+}
+
+// DispatchDue turns new outbox events into deliveries, then sends every
+// delivery due at now. A failing endpoint only fails its own deliveries;
+// the returned error is about the outbox and delivery bookkeeping.
+func (s *WebhookService) DispatchDue(ctx context.Context, now time.Time) error {
+	if err := s.fanOut(ctx, now); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		deliveries, err := s.webhookRepo.ClaimDeliveries(ctx, now, s.lease(), deliveryBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		if err := s.deliverAll(ctx, now, deliveries); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// fanOut creates a delivery of each undispatched event for every active
+// subscription to its type. Subscriptions only receive events published
+// after they were created or resumed.
+func (s *WebhookService) fanOut(ctx context.Context, now time.Time) error {
+	for {
+		var batch int
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			events, err := s.outboxRepo.ListUndispatched(ctx, outboxBatchSize)
+			if err != nil || len(events) == 0 {
+				return err
+			}
+			batch = len(events)
+
+			subs, err := s.webhookRepo.ListSubscriptions(ctx)
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				for _, sub := range subs {
+					if !sub.Wants(event.Type) {
+						continue
+					}
+					if err := s.webhookRepo.CreateDelivery(ctx, &domain.WebhookDelivery{
+						SubscriptionID: sub.ID,
+						EventID:        event.ID,
+						NextAttemptAt:  now,
+					}); err != nil {
+						return err
+					}
+				}
+				if err := s.outboxRepo.MarkDispatched(ctx, event.ID, now); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to dispatch outbox: %w", err)
+		}
+		if batch < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliverAll sends a batch of claimed deliveries in parallel
+func (s *WebhookService) deliverAll(ctx context.Context, now time.Time, deliveries []*domain.WebhookDelivery) error {
+	subs := make(map[string]*domain.WebhookSubscription)
+	for _, d := range deliveries {
+		if _, ok := subs[d.SubscriptionID]; ok {
+			continue
+		}
+		sub, err := s.webhookRepo.GetSubscription(ctx, d.SubscriptionID)
+		if err != nil && !errors.Is(err, domain.ErrWebhookNotFound) {
+			return err
+		}
+		subs[d.SubscriptionID] = sub // nil once deleted; its deliveries are gone too
+	}
+
+	errs := make([]error, len(deliveries))
+	var wg sync.WaitGroup
+	for i, d := range deliveries {
+		sub := subs[d.SubscriptionID]
+		if sub == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.deliver(ctx, now, sub, d)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// deliver makes one attempt of a claimed delivery and records the outcome
+func (s *WebhookService) deliver(ctx context.Context, now time.Time, sub *domain.WebhookSubscription, d *domain.WebhookDelivery) error {
+	started := time.Now()
+	statusCode, sendErr := s.send(ctx, sub, d)
+	finished := now.Add(time.Since(started))
+
+	// Record the outcome even if the app is shutting down, so the delivery
+	// does not wait for its lease to expire
+	ctx = context.WithoutCancel(ctx)
+	var err error
+	switch {
+	case sendErr == nil:
+		err = s.webhookRepo.MarkDelivered(ctx, d.ID, *statusCode, finished)
+	case d.Attempts < s.maxAttempts:
+		retryAt := finished.Add(domain.WebhookBackoff(d.Attempts))
+		slog.WarnContext(ctx, "webhook delivery failed, will retry",
+			"deliveryID", d.ID, "url", sub.URL, "event", d.EventType, "attempt", d.Attempts, "retryAt", retryAt, "error", sendErr)
+		err = s.webhookRepo.MarkFailed(ctx, d.ID, statusCode, sendErr.Error(), &retryAt)
+	default:
+		slog.ErrorContext(ctx, "webhook delivery is dead",
+			"deliveryID", d.ID, "url", sub.URL, "event", d.EventType, "attempts", d.Attempts, "error", sendErr)
+		err = s.webhookRepo.MarkFailed(ctx, d.ID, statusCode, sendErr.Error(), nil)
+	}
+
+	// The subscription was deleted while the delivery was on its way
+	if errors.Is(err, domain.ErrDeliveryNotFound) {
+		return nil
+	}
+	return err
+}
+
+// send posts the event to the endpoint. It returns the response status, if
+// there was a response, and an error unless the status is 2xx.
+func (s *WebhookService) send(ctx context.Context, sub *domain.WebhookSubscription, d *domain.WebhookDelivery) (*int, error) {
+	body, err := json.Marshal(domain.OutboxEvent{
+		ID:        d.EventID,
+		Type:      d.EventType,
+		Payload:   d.Payload,
+		CreatedAt: d.EventCreatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "corporate-learning-webhooks/1")
+	req.Header.Set(WebhookEventHeader, string(d.EventType))
+	req.Header.Set(WebhookDeliveryHeader, d.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(sub.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	statusCode := resp.StatusCode
+	if statusCode >= 200 && statusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		return &statusCode, nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSnippet))
+	message := resp.Status
+	if text := strings.TrimSpace(strings.ToValidUTF8(string(snippet), "")); text != "" {
+		message += ": " + text
+	}
+	return &statusCode, errors.New(message)
+}
+
+// lease is how long a claimed batch is left alone by other instances; the
+// batch is sent in parallel, so it needs about one request timeout
+func (s *WebhookService) lease() time.Duration {
+	if s.client.Timeout <= 0 {
+		return time.Minute
+	}
+	return 2 * s.client.Timeout
+}
+
+// SignWebhook returns the signature header of a webhook request: the
+// hex-encoded HMAC-SHA256 of "<timestamp>.<body>" keyed with the
+// subscription secret, prefixed with "sha256="
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateSubscription registers an endpoint. A signing secret is generated
+// when none is given; the returned subscription is the only place it is
+// shown.
+func (s *WebhookService) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if sub.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		sub.Secret = secret
+	}
+	sub.EventTypes = cleanEventTypes(sub.EventTypes)
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions lists the subscriptions, newest first, without secrets
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	subs, err := s.webhookRepo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+	return subs, nil
+}
+
+// GetSubscription returns a subscription without its secret
+func (s *WebhookService) GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// UpdateSubscription replaces the endpoint, event types, description and
+// active flag of a subscription; an empty secret keeps the current one
+func (s *WebhookService) UpdateSubscription(ctx context.Context, update *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetSubscription(ctx, update.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.URL = update.URL
+	sub.EventTypes = cleanEventTypes(update.EventTypes)
+	sub.Description = update.Description
+	sub.Active = update.Active
+	if update.Secret != "" {
+		sub.Secret = update.Secret
+	}
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookRepo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// DeleteSubscription removes a subscription with its pending and dead
+// deliveries
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	return s.webhookRepo.DeleteSubscription(ctx, id)
+}
+
+// ListDeliveries lists deliveries for admins, newest first; filtering by
+// the dead status gives the dead-letter list
+func (s *WebhookService) ListDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown delivery status %q", domain.ErrInvalidInput, filter.Status)
+	}
+	if filter.Limit <= 0 || filter.Limit > domain.DefaultDeliveryListLimit {
+		filter.Limit = domain.DefaultDeliveryListLimit
+	}
+	return s.webhookRepo.ListDeliveries(ctx, filter)
+}
+
+// GetDelivery returns a delivery by ID
+func (s *WebhookService) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	return s.webhookRepo.GetDelivery(ctx, id)
+}
+
+// Redeliver sends a dead delivery again on the next dispatch, with a fresh
+// set of attempts
+func (s *WebhookService) Redeliver(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	if err := s.webhookRepo.Redeliver(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetDelivery(ctx, id)
+}
+
+// cleanEventTypes drops duplicate event types, keeping the first of each
+func cleanEventTypes(types []domain.EventType) []domain.EventType {
+	cleaned := make([]domain.EventType, 0, len(types))
+	seen := make(map[domain.EventType]bool)
+	for _, t := range types {
+		if !seen[t] {
+			seen[t] = true
+			cleaned = append(cleaned, t)
+		}
+	}
+	return cleaned
+}
+
+// newWebhookSecret generates a random signing secret
+func newWebhookSecret() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(random), nil
+}
@@ -0,0 +1,364 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/netguard"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+const testWebhookAttempts = 3
+
+const testWebhookSecret = "test-webhook-secret"
+
+// receiver is a webhook endpoint that answers with status and records the
+// requests it gets
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	r := &receiver{status: http.StatusNoContent}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+		if r.status >= 300 {
+			_, _ = w.Write([]byte("endpoint unavailable"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) respond(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// events returns the sorted types of the received events; deliveries are
+// sent in parallel, so they arrive in any order
+func (r *receiver) events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]string, len(r.requests))
+	for i, req := range r.requests {
+		types[i] = req.Header.Get(service.WebhookEventHeader)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// subscribe registers the receiver for the event types
+func (e *env) subscribe(t *testing.T, url string, types ...domain.EventType) *domain.WebhookSubscription {
+	t.Helper()
+
+	sub, err := e.webhook.CreateSubscription(defaultCtx(), &domain.WebhookSubscription{
+		URL:        url,
+		Secret:     testWebhookSecret,
+		EventTypes: types,
+		Active:     true,
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	return sub
+}
+
+func TestWebhookService_DeliversSignedEvents(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	hook, server := newReceiver(t)
+	sub := e.subscribe(t, server.URL)
+	alice := e.addUser(t, "alice")
+	admin := e.addAdmin(t, "admin")
+
+	request, err := e.request.CreateRequest(ctx, alice.ID, "Go", "Generics", nil)
+	expectErr(t, err, nil)
+	_, err = e.approval.Decide(ctx, request.ID, admin.ID, domain.DecisionApproved, nil)
+	expectErr(t, err, nil)
+
+	expectErr(t, e.webhook.DispatchDue(ctx, time.Now()), nil)
+	if got := strings.Join(hook.events(), ","); got != "request.created,request.decided" {
+		t.Fatalf("received events = %s", got)
+	}
+
+	for i, req := range hook.requests {
+		body := hook.bodies[i]
+		want := service.SignWebhook(testWebhookSecret, req.Header.Get(service.WebhookTimestampHeader), body)
+		if got := req.Header.Get(service.WebhookSignatureHeader); got != want {
+			t.Errorf("signature = %s, want %s", got, want)
+		}
+		if got := service.SignWebhook("another-secret-value", req.Header.Get(service.WebhookTimestampHeader), body); got == want {
+			t.Error("signature does not depend on the secret")
+		}
+	}
+
+	var event struct {
+		ID   string              `json:"id"`
+		Type domain.EventType    `json:"type"`
+		Data domain.RequestEvent `json:"data"`
+	}
+	decided := slices.IndexFunc(hook.requests, func(req *http.Request) bool {
+		return req.Header.Get(service.WebhookEventHeader) == string(domain.EventRequestDecided)
+	})
+	if err := json.Unmarshal(hook.bodies[decided], &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event.ID == "" || event.Data.RequestID != request.ID || event.Data.DeciderID != admin.ID ||
+		event.Data.Decision != domain.DecisionApproved || event.Data.Status != domain.RequestQueued {
+		t.Errorf("request.decided event = %+v", event)
+	}
+
+	deliveries, err := e.webhook.ListDeliveries(ctx, domain.WebhookDeliveryFilter{SubscriptionID: sub.ID})
+	expectErr(t, err, nil)
+	for _, d := range deliveries {
+		if d.Status != domain.DeliveryDelivered || d.Attempts != 1 || d.DeliveredAt == nil {
+			t.Errorf("delivery = %+v, want delivered on the first attempt", d)
+		}
+	}
+
+	// Dispatched events are not sent again
+	expectErr(t, e.webhook.DispatchDue(ctx, time.Now().Add(time.Hour)), nil)
+	if got := len(hook.events()); got != 2 {
+		t.Errorf("received %d events after a second dispatch, want 2", got)
+	}
+}
+
+func TestWebhookService_FiltersByEventType(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	completions, completionServer := newReceiver(t)
+	paused, pausedServer := newReceiver(t)
+	e.subscribe(t, completionServer.URL, domain.EventLearningCompleted)
+	pausedSub := e.subscribe(t, pausedServer.URL)
+	pausedSub.Active = false
+	_, err := e.webhook.UpdateSubscription(ctx, pausedSub)
+	expectErr(t, err, nil)
+
+	alice := e.addUser(t, "alice")
+	mentor := e.addMentor(t, "bob", 0)
+	learning := e.addLearning(t, alice.ID, mentor, domain.LearningActive)
+	_, err = e.request.CreateRequest(ctx, alice.ID, "Rust", "Ownership", nil)
+	expectErr(t, err, nil)
+	_, err = e.learning.CompleteLearning(ctx, learning.ID, 5, "Great")
+	expectErr(t, err, nil)
+
+	expectErr(t, e.webhook.DispatchDue(ctx, time.Now()), nil)
+	if got := strings.Join(completions.events(), ","); got != "learning.completed" {
+		t.Errorf("filtered subscription received %s, want learning.completed", got)
+	}
+	if got := paused.events(); len(got) != 0 {
+		t.Errorf("paused subscription received %v", got)
+	}
+
+	var event struct {
+		Data domain.LearningEvent `json:"data"`
+	}
+	if err := json.Unmarshal(completions.bodies[0], &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event.Data.LearningID != learning.ID || event.Data.Rating != 5 || event.Data.Status != domain.LearningCompleted {
+		t.Errorf("learning.completed event = %+v", event.Data)
+	}
+}
+
+func TestWebhookService_RetriesThenDeadLetters(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	hook, server := newReceiver(t)
+	hook.respond(http.StatusServiceUnavailable)
+	sub := e.subscribe(t, server.URL)
+	expectErr(t, e.outbox.Publish(ctx, domain.EventRequestCreated, domain.RequestEvent{RequestID: "r1"}), nil)
+	now := time.Now()
+
+	delivery := func() *domain.WebhookDelivery {
+		t.Helper()
+		deliveries, err := e.webhook.ListDeliveries(ctx, domain.WebhookDeliveryFilter{SubscriptionID: sub.ID})
+		expectErr(t, err, nil)
+		if len(deliveries) != 1 {
+			t.Fatalf("got %d deliveries, want 1", len(deliveries))
+		}
+		return deliveries[0]
+	}
+
+	expectErr(t, e.webhook.DispatchDue(ctx, now), nil)
+	d := delivery()
+	if d.Status != domain.DeliveryPending || d.Attempts != 1 || d.LastStatusCode == nil ||
+		*d.LastStatusCode != http.StatusServiceUnavailable || !strings.Contains(d.LastError, "endpoint unavailable") {
+		t.Fatalf("failed delivery = %+v", d)
+	}
+	if !d.NextAttemptAt.After(now.Add(domain.WebhookBackoff(1) - time.Second)) {
+		t.Errorf("next attempt at %s, want a backoff of %s", d.NextAttemptAt, domain.WebhookBackoff(1))
+	}
+
+	// Not retried before the backoff is over
+	expectErr(t, e.webhook.DispatchDue(ctx, now.Add(time.Second)), nil)
+	if got := len(hook.events()); got != 1 {
+		t.Fatalf("received %d attempts during the backoff, want 1", got)
+	}
+
+	expectErr(t, e.webhook.DispatchDue(ctx, now.Add(time.Hour)), nil)
+	expectErr(t, e.webhook.DispatchDue(ctx, now.Add(2*time.Hour)), nil)
+	if d = delivery(); d.Status != domain.DeliveryDead || d.Attempts != testWebhookAttempts {
+		t.Fatalf("delivery after %d attempts = %+v, want dead", testWebhookAttempts, d)
+	}
+	dead, err := e.webhook.ListDeliveries(ctx, domain.WebhookDeliveryFilter{Status: domain.DeliveryDead})
+	expectErr(t, err, nil)
+	if len(dead) != 1 || dead[0].ID != d.ID {
+		t.Errorf("dead letters = %v", dead)
+	}
+	expectErr(t, e.webhook.DispatchDue(ctx, now.Add(24*time.Hour)), nil)
+	if got := len(hook.events()); got != testWebhookAttempts {
+		t.Errorf("received %d attempts, want %d", got, testWebhookAttempts)
+	}
+
+	hook.respond(http.StatusOK)
+	redelivered, err := e.webhook.Redeliver(ctx, d.ID)
+	expectErr(t, err, nil)
+	if redelivered.Status != domain.DeliveryPending || redelivered.Attempts != 0 {
+		t.Errorf("redelivered = %+v", redelivered)
+	}
+	expectErr(t, e.webhook.DispatchDue(ctx, time.Now().Add(time.Second)), nil)
+	if d = delivery(); d.Status != domain.DeliveryDelivered || d.Attempts != 1 {
+		t.Errorf("delivery after redelivery = %+v", d)
+	}
+
+	_, err = e.webhook.Redeliver(ctx, d.ID)
+	expectErr(t, err, domain.ErrDeliveryNotRetryable)
+	_, err = e.webhook.Redeliver(ctx, "missing")
+	expectErr(t, err, domain.ErrDeliveryNotFound)
+	_, err = e.webhook.ListDeliveries(ctx, domain.WebhookDeliveryFilter{Status: "lost"})
+	expectErr(t, err, domain.ErrInvalidInput)
+}
+
+func TestWebhookService_RefusesPrivateAddresses(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	hook, server := newReceiver(t)
+	webhooks := service.NewWebhookService(e.tx, e.outboxEvents, e.webhooks, netguard.NewClient(5*time.Second), testWebhookAttempts)
+
+	// The receiver listens on loopback, like the metadata endpoint or an
+	// internal service would on their addresses
+	sub := e.subscribe(t, server.URL)
+	expectErr(t, e.outbox.Publish(ctx, domain.EventRequestCreated, domain.RequestEvent{RequestID: "r1"}), nil)
+	expectErr(t, webhooks.DispatchDue(ctx, time.Now()), nil)
+
+	deliveries, err := webhooks.ListDeliveries(ctx, domain.WebhookDeliveryFilter{SubscriptionID: sub.ID})
+	expectErr(t, err, nil)
+	if len(deliveries) != 1 || deliveries[0].Status != domain.DeliveryPending || !strings.Contains(deliveries[0].LastError, netguard.ErrPrivateAddress.Error()) {
+		t.Fatalf("deliveries = %+v, want one refused attempt", deliveries)
+	}
+	if got := hook.events(); len(got) != 0 {
+		t.Errorf("receiver got %v", got)
+	}
+}
+
+func TestWebhookService_OutboxFollowsTransaction(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+	failure := errors.New("boom")
+
+	err := e.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := e.outbox.Publish(ctx, domain.EventRequestCreated, domain.RequestEvent{RequestID: "r1"}); err != nil {
+			return err
+		}
+		return failure
+	})
+	expectErr(t, err, failure)
+	events, err := e.outboxEvents.ListUndispatched(ctx, 10)
+	expectErr(t, err, nil)
+	if len(events) != 0 {
+		t.Fatalf("rolled back transaction left %d events", len(events))
+	}
+
+	// A committed assignment publishes its event
+	alice := e.addUser(t, "alice")
+	request := e.addRequest(t, alice.ID, domain.RequestPending)
+	mentor := e.addMentor(t, "carol", 0)
+	_, err = e.request.AssignMentor(ctx, request.ID, mentor.ID)
+	expectErr(t, err, nil)
+	events, err = e.outboxEvents.ListUndispatched(ctx, 10)
+	expectErr(t, err, nil)
+	if len(events) != 1 || events[0].Type != domain.EventLearningStarted {
+		t.Fatalf("events after assignment = %v, want learning.started", events)
+	}
+}
+
+func TestWebhookService_Subscriptions(t *testing.T) {
+	ctx := defaultCtx()
+	e := newEnv(t)
+
+	sub, err := e.webhook.CreateSubscription(ctx, &domain.WebhookSubscription{
+		URL:        "https://hris.example.com/hooks",
+		EventTypes: []domain.EventType{domain.EventLearningCompleted, domain.EventLearningCompleted},
+		Active:     true,
+	})
+	expectErr(t, err, nil)
+	if !strings.HasPrefix(sub.Secret, "whsec_") || len(sub.EventTypes) != 1 {
+		t.Errorf("created subscription = %+v, want a generated secret and unique types", sub)
+	}
+	secret := sub.Secret
+
+	for _, bad := range []*domain.WebhookSubscription{
+		{URL: "hris.example.com/hooks"},
+		{URL: "ftp://hris.example.com/hooks"},
+		{URL: "https://hris.example.com/hooks", EventTypes: []domain.EventType{"learning.paused"}},
+		{URL: "https://hris.example.com/hooks", Secret: "short"},
+	} {
+		_, err := e.webhook.CreateSubscription(ctx, bad)
+		expectErr(t, err, domain.ErrInvalidInput)
+	}
+
+	got, err := e.webhook.GetSubscription(ctx, sub.ID)
+	expectErr(t, err, nil)
+	if got.Secret != "" {
+		t.Error("GetSubscription returned the secret")
+	}
+	list, err := e.webhook.ListSubscriptions(ctx)
+	expectErr(t, err, nil)
+	if len(list) != 1 || list[0].Secret != "" {
+		t.Errorf("ListSubscriptions = %+v", list)
+	}
+
+	updated, err := e.webhook.UpdateSubscription(ctx, &domain.WebhookSubscription{
+		ID:          sub.ID,
+		URL:         "https://lms.example.com/hooks",
+		Description: "LMS",
+	})
+	expectErr(t, err, nil)
+	if updated.Active || updated.URL != "https://lms.example.com/hooks" || len(updated.EventTypes) != 0 {
+		t.Errorf("updated subscription = %+v", updated)
+	}
+	stored, err := e.webhooks.GetSubscription(ctx, sub.ID)
+	expectErr(t, err, nil)
+	if stored.Secret != secret {
+		t.Error("an update without a secret replaced it")
+	}
+
+	expectErr(t, e.webhook.DeleteSubscription(ctx, sub.ID), nil)
+	_, err = e.webhook.GetSubscription(ctx, sub.ID)
+	expectErr(t, err, domain.ErrWebhookNotFound)
+	_, err = e.webhook.UpdateSubscription(ctx, &domain.WebhookSubscription{ID: sub.ID, URL: "https://lms.example.com/hooks"})
+	expectErr(t, err, domain.ErrWebhookNotFound)
+}
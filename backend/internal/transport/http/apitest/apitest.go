@@ -0,0 +1,417 @@
+// Package apitest runs the real HTTP router over in-memory repositories so
+// routing, middleware and access control can be exercised end to end.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/certificate"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/health"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/blob"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/repository/memory"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	transport "github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http"
+)
+
+// Secret signs every token minted by the harness
+const Secret = "apitest-secret"
+
+// SCIMToken authenticates SCIM requests to the harness
+const SCIMToken = "apitest-scim-token"
+
+// SuperAdminToken authenticates tenant management requests to the harness
+const SuperAdminToken = "apitest-super-admin-token"
+
+// MaxUploadSize is the largest attachment the harness accepts
+const MaxUploadSize = 64 << 10
+
+// Server is a router wired to fresh in-memory repositories
+type Server struct {
+	Router *gin.Engine
+
+	Users         *memory.UserRepository
+	Roles         *memory.RoleRepository
+	Departments   *memory.DepartmentRepository
+	Requests      *memory.RequestRepository
+	Mentors       *memory.MentorRepository
+	Learnings     *memory.LearningRepository
+	Notifications *memory.NotificationRepository
+	Availability  *memory.AvailabilityRepository
+	Approvals     *memory.ApprovalRepository
+	Comments      *memory.CommentRepository
+	Attachments   *memory.AttachmentRepository
+	Courses       *memory.CourseRepository
+	Enrollments   *memory.EnrollmentRepository
+	Skills        *memory.SkillRepository
+	Competencies  *memory.CompetencyRepository
+	Certificates  *memory.CertificateRepository
+
+	Questionnaires *memory.QuestionnaireRepository
+	Feedback       *memory.FeedbackRepository
+	CheckIns       *memory.CheckInRepository
+	Jobs           *memory.JobRepository
+	Outbox         *memory.OutboxRepository
+	Webhooks       *memory.WebhookRepository
+	Tenants        *memory.TenantRepository
+
+	// JobService runs the jobs enqueued by the handlers on demand
+	JobService *service.JobService
+	// WebhookService delivers the events published by the handlers on demand
+	WebhookService *service.WebhookService
+	// Mail holds the emails sent by jobs
+	Mail *Mailbox
+}
+
+// Email is a message recorded by a Mailbox
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailbox is a mailer that keeps what it sends
+type Mailbox struct {
+	mu   sync.Mutex
+	sent []Email
+}
+
+// Send records the email
+func (m *Mailbox) Send(ctx context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, Email{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// Sent returns the emails sent so far
+func (m *Mailbox) Sent() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Email(nil), m.sent...)
+}
+
+// Persona is a user account together with a valid token for it
+type Persona struct {
+	User  *domain.User
+	Token string
+
+	// Mentor is the mentor record sharing the user's email, if any
+	Mentor *domain.Mentor
+}
+
+// New builds a server over an empty store where requests wait for an admin
+func New(t *testing.T) *Server {
+	t.Helper()
+	return NewWithApprovals(t, []domain.ApprovalStage{domain.StageAdmin})
+}
+
+// NewWithApprovals builds a server over an empty store with the given
+// approval chain
+func NewWithApprovals(t *testing.T, chain []domain.ApprovalStage) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	s := &Server{
+		Router:        gin.New(),
+		Users:         memory.NewUserRepository(store),
+		Roles:         memory.NewRoleRepository(store),
+		Departments:   memory.NewDepartmentRepository(store),
+		Requests:      memory.NewRequestRepository(store),
+		Mentors:       memory.NewMentorRepository(store),
+		Learnings:     memory.NewLearningRepository(store),
+		Notifications: memory.NewNotificationRepository(store),
+		Availability:  memory.NewAvailabilityRepository(store),
+		Approvals:     memory.NewApprovalRepository(store),
+		Comments:      memory.NewCommentRepository(store),
+		Attachments:   memory.NewAttachmentRepository(store),
+		Courses:       memory.NewCourseRepository(store),
+		Enrollments:   memory.NewEnrollmentRepository(store),
+		Skills:        memory.NewSkillRepository(store),
+		Competencies:  memory.NewCompetencyRepository(store),
+		Certificates:  memory.NewCertificateRepository(store),
+
+		Questionnaires: memory.NewQuestionnaireRepository(store),
+		Feedback:       memory.NewFeedbackRepository(store),
+		CheckIns:       memory.NewCheckInRepository(store),
+		Jobs:           memory.NewJobRepository(store),
+		Outbox:         memory.NewOutboxRepository(store),
+		Webhooks:       memory.NewWebhookRepository(store),
+		Tenants:        memory.NewTenantRepository(store),
+		Mail:           &Mailbox{},
+	}
+
+	authService := service.NewAuthService(s.Users, s.Departments, Secret, time.Hour)
+	userService := service.NewUserService(s.Users, s.Roles, s.Departments)
+	txManager := memory.NewTxManager(store)
+	notificationService := service.NewNotificationService(s.Notifications)
+	outboxService := service.NewOutboxService(s.Outbox)
+	queueService := service.NewQueueService(txManager, s.Requests, s.Mentors, s.Learnings, s.Availability, notificationService, outboxService)
+	approvalService := service.NewApprovalService(txManager, s.Requests, s.Users, s.Departments, s.Approvals, s.Enrollments, queueService, notificationService, outboxService, chain)
+	requestService := service.NewRequestService(txManager, s.Requests, s.Users, s.Mentors, s.Learnings, s.Availability, approvalService, outboxService)
+	competencyService := service.NewCompetencyService(txManager, s.Skills, s.Competencies, s.Users, s.Departments)
+	mentorService := service.NewMentorService(s.Mentors, s.Learnings, s.Availability, s.Tenants, queueService)
+	availabilityService := service.NewAvailabilityService(s.Availability, s.Mentors, queueService)
+	handoffService := service.NewHandoffService(txManager, s.Mentors, s.Learnings, s.Availability, notificationService, outboxService)
+	commentService := service.NewCommentService(txManager, s.Comments, s.Requests, s.Learnings, s.Mentors, s.Users, notificationService)
+
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	renderer, err := certificate.NewRenderer("", "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("certificate renderer: %v", err)
+	}
+	certificateService := service.NewCertificateService(s.Certificates, s.Learnings, s.Users, blobs, renderer)
+	learningService := service.NewLearningService(txManager, s.Learnings, s.Mentors, s.Users, s.Requests, s.Availability, queueService, approvalService, competencyService, outboxService, certificateService)
+	attachmentService := service.NewAttachmentService(s.Attachments, s.Learnings, blobs, nil, commentService, MaxUploadSize, []string{"application/pdf", "image/png", "text/plain"})
+	courseService := service.NewCourseService(s.Courses, s.Enrollments, s.Requests, s.Users, approvalService, competencyService)
+	feedbackService := service.NewFeedbackService(txManager, s.Questionnaires, s.Feedback, s.Learnings, s.Mentors, s.Users)
+	checkInService := service.NewCheckInService(txManager, s.CheckIns, s.Learnings, s.Mentors, s.Users, notificationService, 7*24*time.Hour, 14*24*time.Hour, true)
+	s.JobService = service.NewJobService(txManager, s.Jobs, s.Tenants, "apitest", time.Minute)
+	s.JobService.Register(service.JobCheckIns, checkInService.RunJob)
+	s.WebhookService = service.NewWebhookService(txManager, s.Outbox, s.Webhooks, &http.Client{Timeout: 5 * time.Second}, 3)
+	userImportService := service.NewUserImportService(s.Users, s.Roles, s.Departments, s.Tenants, authService, s.JobService, s.Mail, "http://localhost:3000/invite")
+	s.JobService.Register(service.JobUserInvite, userImportService.RunInviteJob)
+	scimService := service.NewSCIMService(txManager, s.Users, s.Roles, s.Departments, userService)
+	roleService := service.NewRoleService(txManager, s.Roles, s.Users)
+	departmentService := service.NewDepartmentService(txManager, s.Departments, s.Users)
+	tenantService := service.NewTenantService(txManager, s.Tenants, s.Roles, s.Users, s.Questionnaires)
+
+	handler := transport.NewHandler(
+		authService, userService, requestService, learningService, mentorService,
+		availabilityService, handoffService, notificationService, queueService, approvalService,
+		commentService, attachmentService, courseService, competencyService, certificateService,
+		feedbackService, checkInService, s.JobService, s.WebhookService, userImportService,
+		scimService, roleService, departmentService, tenantService, health.NewMonitor(time.Second),
+	)
+	handler.InitRoutes(s.Router, slog.New(slog.NewTextHandler(io.Discard, nil)), Secret, SCIMToken, SuperAdminToken)
+
+	return s
+}
+
+// Employee creates an employee account
+func (s *Server) Employee(t *testing.T, name string) *Persona {
+	t.Helper()
+	return s.persona(t, name, name+"@example.com", domain.RoleEmployee)
+}
+
+// Admin creates an admin account
+func (s *Server) Admin(t *testing.T, name string) *Persona {
+	t.Helper()
+	return s.persona(t, name, name+"@example.com", domain.RoleAdmin)
+}
+
+// WithRole creates an account holding role, which must exist
+func (s *Server) WithRole(t *testing.T, name string, role domain.UserRole) *Persona {
+	t.Helper()
+	return s.persona(t, name, name+"@example.com", role)
+}
+
+// Department creates a top-level department headed by head, when set, and
+// moves the members into it
+func (s *Server) Department(t *testing.T, name string, head *domain.User, members ...*domain.User) *domain.Department {
+	t.Helper()
+	ctx := defaultCtx()
+
+	department := &domain.Department{Name: name}
+	if head != nil {
+		department.HeadID = &head.ID
+	}
+	if err := s.Departments.Create(ctx, department); err != nil {
+		t.Fatalf("create department: %v", err)
+	}
+	for _, member := range members {
+		member.DepartmentID = &department.ID
+		if err := s.Users.Update(ctx, member); err != nil {
+			t.Fatalf("move user to department: %v", err)
+		}
+	}
+	return department
+}
+
+// Mentor creates a mentor record and an employee account with the same
+// email, which is how a mentor signs in to the application
+func (s *Server) Mentor(t *testing.T, name string, workload int) *Persona {
+	t.Helper()
+
+	email := name + "@mentors.example.com"
+	mentor := &domain.Mentor{
+		Name:     name,
+		JobTitle: "Senior Engineer",
+		Workload: workload,
+		Capacity: domain.DefaultMentorCapacity,
+		Email:    email,
+	}
+	if err := s.Mentors.Create(defaultCtx(), mentor); err != nil {
+		t.Fatalf("create mentor: %v", err)
+	}
+
+	p := s.persona(t, name, email, domain.RoleEmployee)
+	p.Mentor = mentor
+	return p
+}
+
+func (s *Server) persona(t *testing.T, name, email string, role domain.UserRole) *Persona {
+	t.Helper()
+
+	user := &domain.User{
+		Name:         name,
+		Email:        email,
+		PasswordHash: "unused",
+		Role:         role,
+	}
+	if err := s.Users.Create(defaultCtx(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	return &Persona{User: user, Token: Token(t, user.ID, string(role), time.Hour)}
+}
+
+// Token signs a token with the claims the auth middleware expects; a
+// negative ttl yields an expired token
+func Token(t *testing.T, userID, role string, ttl time.Duration) string {
+	t.Helper()
+	return SignToken(t, Secret, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"exp":     time.Now().Add(ttl).Unix(),
+		"iat":     time.Now().Unix(),
+	})
+}
+
+// SignToken signs arbitrary claims, for malformed-token cases
+func SignToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// MissingID returns a well-formed ID that does not exist
+func MissingID() string {
+	return uuid.New().String()
+}
+
+// Response is a recorded HTTP response
+type Response struct {
+	Code   int
+	Body   []byte
+	Header http.Header
+}
+
+// Decode unmarshals the body into v
+func (r *Response) Decode(t *testing.T, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		t.Fatalf("decode %q: %v", r.Body, err)
+	}
+}
+
+// Do sends a request with an optional bearer token and JSON body
+func (s *Server) Do(t *testing.T, method, path, token string, body any) *Response {
+	t.Helper()
+	return s.DoOnHost(t, "", method, path, token, body)
+}
+
+// DoOnHost is Do with the Host header set, which selects the tenant; an
+// empty host keeps the default of httptest
+func (s *Server) DoOnHost(t *testing.T, host, method, path, token string, body any) *Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if host != "" {
+		req.Host = host
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return s.serve(req, token)
+}
+
+// Upload sends a multipart form with a file part named "file" and the given
+// fields
+func (s *Server) Upload(t *testing.T, path, token string, fields map[string]string, fileName string, content []byte) *Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			t.Fatalf("write field: %v", err)
+		}
+	}
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatalf("create file part: %v", err)
+	}
+	part.Write(content)
+	if err := form.Close(); err != nil {
+		t.Fatalf("close form: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return s.serve(req, token)
+}
+
+func (s *Server) serve(req *http.Request, token string) *Response {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	return &Response{Code: rec.Code, Body: rec.Body.Bytes(), Header: rec.Header()}
+}
+
+// Expect sends a request and fails unless it answers with status
+func (s *Server) Expect(t *testing.T, status int, method, path, token string, body any) *Response {
+	t.Helper()
+	return s.ExpectOnHost(t, status, "", method, path, token, body)
+}
+
+// ExpectOnHost is Expect with the Host header set
+func (s *Server) ExpectOnHost(t *testing.T, status int, host, method, path, token string, body any) *Response {
+	t.Helper()
+
+	resp := s.DoOnHost(t, host, method, path, token, body)
+	if resp.Code != status {
+		t.Fatalf("%s %s = %d, want %d: %s", method, path, resp.Code, status, resp.Body)
+	}
+	return resp
+}
+
+// defaultCtx is a context in the default tenant, where the personas live
+func defaultCtx() context.Context {
+	return domain.WithTenant(context.Background(), domain.DefaultTenantID)
+}
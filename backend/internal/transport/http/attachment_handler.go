@@ -0,0 +1,184 @@
+package http
+
+import (
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// multipartOverhead leaves room for the multipart framing and form fields
+// on top of the largest accepted file
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+	}
+}
+
+// UploadLearningAttachment handles POST /api/learnings/:id/attachments
+// (multipart "file", optional "planItemId")
+func (h *AttachmentHandler) UploadLearningAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	file, fileName, ok := h.formFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	var planItemID *string
+	if id := c.PostForm("planItemId"); id != "" {
+		planItemID = &id
+	}
+
+	attachment, err := h.attachmentService.AttachToLearning(
+		c.Request.Context(),
+		c.Param("id"),
+		planItemID,
+		userID.(string),
+		fileName,
+		file,
+	)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetLearningAttachments handles GET /api/learnings/:id/attachments
+func (h *AttachmentHandler) GetLearningAttachments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	attachments, err := h.attachmentService.GetLearningAttachments(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+// UploadCommentAttachment handles POST /api/comments/:id/attachments
+// (multipart "file", comment author only)
+func (h *AttachmentHandler) UploadCommentAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	file, fileName, ok := h.formFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.AttachToComment(c.Request.Context(), c.Param("id"), userID.(string), fileName, file)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetCommentAttachments handles GET /api/comments/:id/attachments
+func (h *AttachmentHandler) GetCommentAttachments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	attachments, err := h.attachmentService.GetCommentAttachments(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+// DownloadAttachment handles GET /api/attachments/:id
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	attachment, content, err := h.attachmentService.Download(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	// Always download, never render, so uploads cannot run in the app's origin
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment handles DELETE /api/attachments/:id (uploader or comments.moderate)
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.attachmentService.DeleteAttachment(c.Request.Context(), c.Param("id"), userID.(string)); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// formFile opens the "file" part of a multipart body, bounding the body
+// size so oversized uploads are cut off early
+func (h *AttachmentHandler) formFile(c *gin.Context) (multipart.File, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachmentService.MaxSize()+multipartOverhead)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondAttachmentError(c, domain.ErrFileTooLarge)
+			return nil, "", false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart form with a file field is required"})
+		return nil, "", false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, "", false
+	}
+	return file, header.Filename, true
+}
+
+// respondAttachmentError maps attachment errors to HTTP status codes
+func respondAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrLearningNotFound),
+		errors.Is(err, domain.ErrCommentNotFound),
+		errors.Is(err, domain.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotParticipant),
+		errors.Is(err, domain.ErrNotCommentAuthor),
+		errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCommentDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrFileTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrFileInfected):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
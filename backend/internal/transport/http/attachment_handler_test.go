@@ -0,0 +1,110 @@
+package http_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestAttachments(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+	ann := srv.Mentor(t, "ann", 0)
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/learnings/"+learning.ID+"/plan", alice.Token, map[string]any{
+		"plan": []map[string]any{{"id": "cert", "text": "Pass the exam"}},
+	})
+	learningAttachments := "/api/learnings/" + learning.ID + "/attachments"
+
+	// The learner attaches evidence to a plan item, the mentor materials
+	certificate := []byte("%PDF-1.4 certificate")
+	var evidence domain.Attachment
+	resp := srv.Upload(t, learningAttachments, alice.Token, map[string]string{"planItemId": "cert"}, "certificate.pdf", certificate)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("upload = %d: %s", resp.Code, resp.Body)
+	}
+	resp.Decode(t, &evidence)
+	if evidence.PlanItemID == nil || *evidence.PlanItemID != "cert" || evidence.ContentType != "application/pdf" ||
+		evidence.Size != int64(len(certificate)) || evidence.UploaderName != "alice" {
+		t.Fatalf("uploaded %+v", evidence)
+	}
+	if bytes.Contains(resp.Body, []byte("storageKey")) || bytes.Contains(resp.Body, []byte("attachments/")) {
+		t.Errorf("storage key leaked: %s", resp.Body)
+	}
+	if resp := srv.Upload(t, learningAttachments, ann.Token, nil, "slides.txt", []byte("Week 1: interfaces")); resp.Code != http.StatusCreated {
+		t.Fatalf("mentor upload = %d: %s", resp.Code, resp.Body)
+	}
+
+	// Limits are enforced on the content, not the name
+	for _, tt := range []struct {
+		name    string
+		fields  map[string]string
+		content []byte
+		want    int
+	}{
+		{"too large", nil, bytes.Repeat([]byte("x"), apitest.MaxUploadSize+1), http.StatusRequestEntityTooLarge},
+		{"body too large", nil, bytes.Repeat([]byte("x"), apitest.MaxUploadSize+2<<20), http.StatusRequestEntityTooLarge},
+		{"html", nil, []byte("<!DOCTYPE html><script>alert(1)</script>"), http.StatusUnsupportedMediaType},
+		{"unknown plan item", map[string]string{"planItemId": "nope"}, certificate, http.StatusBadRequest},
+	} {
+		if resp := srv.Upload(t, learningAttachments, alice.Token, tt.fields, "file.pdf", tt.content); resp.Code != tt.want {
+			t.Errorf("%s: upload = %d, want %d: %s", tt.name, resp.Code, tt.want, resp.Body)
+		}
+	}
+	if resp := srv.Upload(t, learningAttachments, bob.Token, nil, "x.pdf", certificate); resp.Code != http.StatusForbidden {
+		t.Errorf("outsider upload = %d, want 403", resp.Code)
+	}
+
+	var list struct {
+		Attachments []domain.Attachment `json:"attachments"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, learningAttachments, ann.Token, nil).Decode(t, &list)
+	if len(list.Attachments) != 2 || list.Attachments[0].ID != evidence.ID {
+		t.Fatalf("attachments = %+v", list.Attachments)
+	}
+
+	// Downloads are served as attachments with the sniffed type
+	resp = srv.Expect(t, http.StatusOK, http.MethodGet, "/api/attachments/"+evidence.ID, ann.Token, nil)
+	if !bytes.Equal(resp.Body, certificate) || resp.Header.Get("Content-Type") != "application/pdf" ||
+		resp.Header.Get("Content-Disposition") != `attachment; filename=certificate.pdf` ||
+		resp.Header.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("download = %q with headers %v", resp.Body, resp.Header)
+	}
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/attachments/"+evidence.ID, bob.Token, nil)
+
+	// Comment authors attach files to their comments
+	var comment domain.Comment
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings/"+learning.ID+"/comments", alice.Token, map[string]string{
+		"body": "Exam results attached",
+	}).Decode(t, &comment)
+	commentAttachments := "/api/comments/" + comment.ID + "/attachments"
+	if resp := srv.Upload(t, commentAttachments, ann.Token, nil, "x.pdf", certificate); resp.Code != http.StatusForbidden {
+		t.Errorf("upload to someone else's comment = %d, want 403", resp.Code)
+	}
+	var results domain.Attachment
+	resp = srv.Upload(t, commentAttachments, alice.Token, nil, "results.pdf", certificate)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("comment upload = %d: %s", resp.Code, resp.Body)
+	}
+	resp.Decode(t, &results)
+	list.Attachments = nil
+	srv.Expect(t, http.StatusOK, http.MethodGet, commentAttachments, ann.Token, nil).Decode(t, &list)
+	if len(list.Attachments) != 1 || list.Attachments[0].ID != results.ID {
+		t.Fatalf("comment attachments = %+v", list.Attachments)
+	}
+
+	// Only the uploader or an admin deletes
+	srv.Expect(t, http.StatusForbidden, http.MethodDelete, "/api/attachments/"+evidence.ID, ann.Token, nil)
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, "/api/attachments/"+evidence.ID, alice.Token, nil)
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/attachments/"+evidence.ID, alice.Token, nil)
+}
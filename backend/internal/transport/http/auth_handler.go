@@ -1,9 +1,11 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
 )
@@ -65,6 +67,36 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// AcceptInvitation handles POST /api/auth/accept-invitation: an invited
+// user chooses their password and is signed in
+func (h *AuthHandler) AcceptInvitation(c *gin.Context) {
+	var req dto.AcceptInvitationDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, user, err := h.authService.AcceptInvitation(c.Request.Context(), req.Token, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidInvitation):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrUserDeactivated):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": token,
+		"user":  user,
+	})
+}
+
 // GetMe handles GET /api/auth/me
 func (h *AuthHandler) GetMe(c *gin.Context) {
 	userID, _ := c.Get("userID")
@@ -0,0 +1,85 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	srv := apitest.New(t)
+	credentials := map[string]string{"email": "new@example.com", "password": "password123"}
+
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/auth/register", "", map[string]string{
+		"name":     "New Hire",
+		"email":    "new@example.com",
+		"password": "password123",
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/auth/register", "", map[string]string{
+		"name":     "Impostor",
+		"email":    "new@example.com",
+		"password": "password123",
+	})
+	srv.Expect(t, http.StatusUnauthorized, http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    "new@example.com",
+		"password": "wrong-password",
+	})
+
+	var login struct {
+		Token string `json:"token"`
+		User  struct {
+			ID   string `json:"id"`
+			Role string `json:"role"`
+		} `json:"user"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/auth/login", "", credentials).Decode(t, &login)
+	if login.Token == "" || login.User.Role != "employee" {
+		t.Fatalf("login = %+v", login)
+	}
+
+	var me struct {
+		ID           string `json:"id"`
+		PasswordHash string `json:"passwordHash"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/auth/me", login.Token, nil).Decode(t, &me)
+	if me.ID != login.User.ID || me.PasswordHash != "" {
+		t.Errorf("me = %+v", me)
+	}
+}
+
+func TestInvalidTokens(t *testing.T) {
+	f := newFixture(t)
+	userID := f.alice.User.ID
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "missing bearer prefix", header: f.alice.Token},
+		{name: "basic scheme", header: "Basic " + f.alice.Token},
+		{name: "extra parts", header: "Bearer " + f.alice.Token + " extra"},
+		{name: "garbage", header: "Bearer not-a-jwt"},
+		{name: "wrong secret", header: "Bearer " + apitest.SignToken(t, "other-secret", jwt.MapClaims{"user_id": userID, "role": "admin", "exp": exp})},
+		{name: "expired", header: "Bearer " + apitest.Token(t, userID, "employee", -time.Minute)},
+		{name: "no user id", header: "Bearer " + apitest.SignToken(t, apitest.Secret, jwt.MapClaims{"role": "admin", "exp": exp})},
+		{name: "no role", header: "Bearer " + apitest.SignToken(t, apitest.Secret, jwt.MapClaims{"user_id": userID, "exp": exp})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/auth/me", nil)
+			req.Header.Set("Authorization", tt.header)
+			rec := httptest.NewRecorder()
+			f.srv.Router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401: %s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// defaultSlotRange is how far ahead free slots are listed without ?to
+const defaultSlotRange = 7 * 24 * time.Hour
+
+type AvailabilityHandler struct {
+	availabilityService *service.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService *service.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		availabilityService: availabilityService,
+	}
+}
+
+// CreateWindowDTO represents a weekly availability window input
+type CreateWindowDTO struct {
+	Weekday   *int   `json:"weekday" binding:"required,min=0,max=6"`
+	StartTime string `json:"startTime" binding:"required"`
+	EndTime   string `json:"endTime" binding:"required"`
+}
+
+// CreateAbsenceDTO represents an out-of-office period input
+type CreateAbsenceDTO struct {
+	Kind     string    `json:"kind" binding:"required,oneof=vacation sick_leave other"`
+	StartsAt time.Time `json:"startsAt" binding:"required"`
+	EndsAt   time.Time `json:"endsAt" binding:"required"`
+	Note     string    `json:"note"`
+}
+
+// GetSchedule handles GET /api/mentors/:id/availability
+func (h *AvailabilityHandler) GetSchedule(c *gin.Context) {
+	schedule, err := h.availabilityService.GetSchedule(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetFreeSlots handles GET /api/mentors/:id/availability/slots
+func (h *AvailabilityHandler) GetFreeSlots(c *gin.Context) {
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = from.Add(defaultSlotRange)
+	}
+
+	slots, err := h.availabilityService.GetFreeSlots(c.Request.Context(), c.Param("id"), from, to)
+	if err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": slots})
+}
+
+// CreateWindow handles POST /api/mentors/:id/availability/windows (mentors.manage)
+func (h *AvailabilityHandler) CreateWindow(c *gin.Context) {
+	var req CreateWindowDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window, err := h.availabilityService.AddWindow(
+		c.Request.Context(), c.Param("id"),
+		time.Weekday(*req.Weekday), req.StartTime, req.EndTime,
+	)
+	if err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
+
+// UpdateWindow handles PUT /api/mentors/:id/availability/windows/:windowId (mentors.manage)
+func (h *AvailabilityHandler) UpdateWindow(c *gin.Context) {
+	var req CreateWindowDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window, err := h.availabilityService.UpdateWindow(
+		c.Request.Context(), c.Param("id"), c.Param("windowId"),
+		time.Weekday(*req.Weekday), req.StartTime, req.EndTime,
+	)
+	if err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// DeleteWindow handles DELETE /api/mentors/:id/availability/windows/:windowId (mentors.manage)
+func (h *AvailabilityHandler) DeleteWindow(c *gin.Context) {
+	if err := h.availabilityService.RemoveWindow(c.Request.Context(), c.Param("id"), c.Param("windowId")); err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateAbsence handles POST /api/mentors/:id/availability/absences (mentors.manage)
+func (h *AvailabilityHandler) CreateAbsence(c *gin.Context) {
+	var req CreateAbsenceDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	absence, err := h.availabilityService.AddAbsence(
+		c.Request.Context(), c.Param("id"),
+		domain.AbsenceKind(req.Kind), req.StartsAt, req.EndsAt, req.Note,
+	)
+	if err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, absence)
+}
+
+// UpdateAbsence handles PUT /api/mentors/:id/availability/absences/:absenceId (mentors.manage)
+func (h *AvailabilityHandler) UpdateAbsence(c *gin.Context) {
+	var req CreateAbsenceDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	absence, err := h.availabilityService.UpdateAbsence(
+		c.Request.Context(), c.Param("id"), c.Param("absenceId"),
+		domain.AbsenceKind(req.Kind), req.StartsAt, req.EndsAt, req.Note,
+	)
+	if err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, absence)
+}
+
+// DeleteAbsence handles DELETE /api/mentors/:id/availability/absences/:absenceId (mentors.manage)
+func (h *AvailabilityHandler) DeleteAbsence(c *gin.Context) {
+	if err := h.availabilityService.RemoveAbsence(c.Request.Context(), c.Param("id"), c.Param("absenceId")); err != nil {
+		respondAvailabilityError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondAvailabilityError maps availability errors to status codes
+func respondAvailabilityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrMentorNotFound),
+		errors.Is(err, domain.ErrWindowNotFound),
+		errors.Is(err, domain.ErrAbsenceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrWindowOverlaps):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parseTimeQuery reads an RFC 3339 timestamp or a YYYY-MM-DD date (midnight
+// UTC) from the query string; a missing parameter yields the zero time
+func parseTimeQuery(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp or YYYY-MM-DD date", key)
+}
@@ -0,0 +1,130 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestMentorAvailability(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	alice := srv.Employee(t, "alice")
+	ann := srv.Mentor(t, "ann", 0)
+	max := srv.Mentor(t, "max", 2)
+	annPath := "/api/mentors/" + ann.Mentor.ID + "/availability"
+
+	// Ann works Monday mornings and is away for the first half of July 2030
+	var window struct {
+		ID string `json:"id"`
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, annPath+"/windows", admin.Token, map[string]any{
+		"weekday": 1, "startTime": "09:00", "endTime": "12:00",
+	}).Decode(t, &window)
+	srv.Expect(t, http.StatusConflict, http.MethodPost, annPath+"/windows", admin.Token, map[string]any{
+		"weekday": 1, "startTime": "11:00", "endTime": "13:00",
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, annPath+"/windows", admin.Token, map[string]any{
+		"weekday": 2, "startTime": "13:00", "endTime": "11:00",
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, annPath+"/absences", admin.Token, map[string]any{
+		"kind": "sabbatical", "startsAt": "2030-07-01T00:00:00Z", "endsAt": "2030-07-15T00:00:00Z",
+	})
+	var summer struct {
+		ID string `json:"id"`
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, annPath+"/absences", admin.Token, map[string]any{
+		"kind": "vacation", "startsAt": "2030-07-01T00:00:00Z", "endsAt": "2030-07-15T00:00:00Z", "note": "Summer",
+	}).Decode(t, &summer)
+
+	var schedule struct {
+		Windows  []struct{ StartTime string } `json:"windows"`
+		Absences []struct{ Kind string }      `json:"absences"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, annPath, alice.Token, nil).Decode(t, &schedule)
+	if len(schedule.Windows) != 1 || schedule.Windows[0].StartTime != "09:00" || len(schedule.Absences) != 1 || schedule.Absences[0].Kind != "vacation" {
+		t.Errorf("schedule = %+v", schedule)
+	}
+
+	var slots struct {
+		Slots []struct {
+			Start time.Time `json:"start"`
+		} `json:"slots"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, annPath+"/slots?from=2030-07-01&to=2030-07-22", alice.Token, nil).Decode(t, &slots)
+	if len(slots.Slots) != 1 || !slots.Slots[0].Start.Equal(time.Date(2030, time.July, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("free slots = %+v", slots.Slots)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, annPath+"/slots?from=tomorrow", alice.Token, nil)
+
+	available := func(query string) []string {
+		var mentors []struct {
+			Name string `json:"name"`
+		}
+		srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors?available=true"+query, alice.Token, nil).Decode(t, &mentors)
+		var names []string
+		for _, m := range mentors {
+			names = append(names, m.Name)
+		}
+		return names
+	}
+	if got := available("&from=2030-07-01&to=2030-07-08"); len(got) != 1 || got[0] != "max" {
+		t.Errorf("available during vacation = %v, want [max]", got)
+	}
+	if got := available("&from=2030-07-15&to=2030-07-22"); len(got) != 2 {
+		t.Errorf("available after vacation = %v, want both", got)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, "/api/mentors?available=true&from=2030-07-08&to=2030-07-01", alice.Token, nil)
+
+	// Ann cuts the vacation short and moves her window an hour later
+	srv.Expect(t, http.StatusOK, http.MethodPut, annPath+"/absences/"+summer.ID, admin.Token, map[string]any{
+		"kind": "vacation", "startsAt": "2030-07-01T00:00:00Z", "endsAt": "2030-07-08T00:00:00Z", "note": "Summer",
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPut, annPath+"/absences/"+summer.ID, admin.Token, map[string]any{
+		"kind": "vacation", "startsAt": "2030-07-08T00:00:00Z", "endsAt": "2030-07-01T00:00:00Z",
+	})
+	srv.Expect(t, http.StatusNotFound, http.MethodPut, "/api/mentors/"+max.Mentor.ID+"/availability/absences/"+summer.ID, admin.Token, map[string]any{
+		"kind": "vacation", "startsAt": "2030-07-01T00:00:00Z", "endsAt": "2030-07-08T00:00:00Z",
+	})
+	srv.Expect(t, http.StatusOK, http.MethodPut, annPath+"/windows/"+window.ID, admin.Token, map[string]any{
+		"weekday": 1, "startTime": "10:00", "endTime": "13:00",
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPut, annPath+"/windows/"+window.ID, admin.Token, map[string]any{
+		"weekday": 1, "startTime": "10:00",
+	})
+	srv.Expect(t, http.StatusOK, http.MethodGet, annPath+"/slots?from=2030-07-01&to=2030-07-22", alice.Token, nil).Decode(t, &slots)
+	if len(slots.Slots) != 2 || !slots.Slots[0].Start.Equal(time.Date(2030, time.July, 8, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("free slots after the changes = %+v", slots.Slots)
+	}
+
+	// Being away right now keeps ann out of automatic assignment
+	var sick struct {
+		ID string `json:"id"`
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, annPath+"/absences", admin.Token, map[string]any{
+		"kind":     "sick_leave",
+		"startsAt": time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		"endsAt":   time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+	}).Decode(t, &sick)
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+	if learning.Mentor.ID != max.Mentor.ID {
+		t.Errorf("learning assigned to %s while ann is away", learning.Mentor.Name)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/learnings/"+learning.ID+"/assign", admin.Token, map[string]string{
+		"mentorId": ann.Mentor.ID,
+	})
+
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, annPath+"/absences/"+sick.ID, admin.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/assign", admin.Token, map[string]string{
+		"mentorId": ann.Mentor.ID,
+	})
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, annPath+"/windows/"+window.ID, admin.Token, nil)
+	srv.Expect(t, http.StatusNotFound, http.MethodDelete, annPath+"/windows/"+window.ID, admin.Token, nil)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type CertificateHandler struct {
+	certificateService *service.CertificateService
+}
+
+func NewCertificateHandler(certificateService *service.CertificateService) *CertificateHandler {
+	return &CertificateHandler{
+		certificateService: certificateService,
+	}
+}
+
+// DownloadLearningCertificate handles GET /api/learnings/:id/certificate
+// (learner or learnings.view)
+func (h *CertificateHandler) DownloadLearningCertificate(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	certificate, content, err := h.certificateService.GetLearningCertificate(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondCertificateError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "application/pdf", content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": "certificate-" + certificate.Code + ".pdf"}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// VerifyCertificate handles GET /api/certificates/:code (public)
+func (h *CertificateHandler) VerifyCertificate(c *gin.Context) {
+	certificate, err := h.certificateService.Verify(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		respondCertificateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToCertificateVerificationDTO(certificate))
+}
+
+func respondCertificateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrLearningNotFound),
+		errors.Is(err, domain.ErrCertificateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrLearningNotCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
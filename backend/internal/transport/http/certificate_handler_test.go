@@ -0,0 +1,63 @@
+package http_test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestCertificates(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+	admin := srv.Admin(t, "root")
+	srv.Mentor(t, "ann", 0)
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/learnings/"+learning.ID+"/plan", alice.Token, map[string]any{
+		"plan": []map[string]any{
+			{"id": "1", "text": "Type parameters", "completed": true},
+			{"id": "2", "text": "Constraints", "completed": false},
+		},
+	})
+	certificatePath := "/api/learnings/" + learning.ID + "/certificate"
+	srv.Expect(t, http.StatusConflict, http.MethodGet, certificatePath, alice.Token, nil)
+
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/complete", alice.Token, map[string]any{
+		"rating": 5, "comment": "Thanks",
+	})
+
+	// The learner and admins download the PDF
+	resp := srv.Expect(t, http.StatusOK, http.MethodGet, certificatePath, alice.Token, nil)
+	disposition := resp.Header.Get("Content-Disposition")
+	if !bytes.HasPrefix(resp.Body, []byte("%PDF-")) || resp.Header.Get("Content-Type") != "application/pdf" ||
+		!strings.HasPrefix(disposition, "attachment; filename=certificate-") {
+		t.Fatalf("certificate download = %q with headers %v", resp.Body, resp.Header)
+	}
+	code := strings.TrimSuffix(strings.TrimPrefix(disposition, "attachment; filename=certificate-"), ".pdf")
+	if again := srv.Expect(t, http.StatusOK, http.MethodGet, certificatePath, admin.Token, nil); !bytes.Equal(again.Body, resp.Body) {
+		t.Error("admin download differs from the learner's")
+	}
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, certificatePath, bob.Token, nil)
+
+	// Third parties verify the code without an account
+	var verified dto.CertificateVerificationDTO
+	resp = srv.Expect(t, http.StatusOK, http.MethodGet, "/api/certificates/"+strings.ToLower(code), "", nil)
+	resp.Decode(t, &verified)
+	if verified.Code != code || verified.LearnerName != "alice" || verified.Topic != "Go" || verified.MentorName != "ann" ||
+		len(verified.CompletedItems) != 1 || verified.CompletedItems[0] != "Type parameters" {
+		t.Errorf("verification = %+v", verified)
+	}
+	if bytes.Contains(resp.Body, []byte(learning.ID)) || bytes.Contains(resp.Body, []byte("certificates/")) {
+		t.Errorf("verification leaked internal IDs: %s", resp.Body)
+	}
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/certificates/AAAA-BBBB-CCCC", "", nil)
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type CheckInHandler struct {
+	checkInService    *service.CheckInService
+	departmentService *service.DepartmentService
+}
+
+func NewCheckInHandler(checkInService *service.CheckInService, departmentService *service.DepartmentService) *CheckInHandler {
+	return &CheckInHandler{
+		checkInService:    checkInService,
+		departmentService: departmentService,
+	}
+}
+
+// GetAtRisk handles GET /api/admin/learnings/at-risk?days= (analytics.view);
+// days overrides the configured stall period; department heads see the
+// learnings of their departments
+func (h *CheckInHandler) GetAtRisk(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	stalledAfter := h.checkInService.StalledAfter()
+	if days := c.Query("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		stalledAfter = time.Duration(n) * 24 * time.Hour
+	}
+
+	scope, err := h.departmentService.Scope(c.Request.Context(), userID.(string), domain.PermAnalyticsView)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+	learnings, err := h.checkInService.GetAtRisk(c.Request.Context(), stalledAfter, time.Now(), scope)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"learnings": learnings})
+}
+
+// GetMyCheckIns handles GET /api/check-ins/my: the current user's
+// unanswered check-ins
+func (h *CheckInHandler) GetMyCheckIns(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	checkIns, err := h.checkInService.GetPending(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checkIns": checkIns})
+}
+
+// AnswerCheckIn handles POST /api/check-ins/:id/answer (the recipient)
+func (h *CheckInHandler) AnswerCheckIn(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.AnswerCheckInDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checkIn, err := h.checkInService.Answer(
+		c.Request.Context(), c.Param("id"), userID.(string),
+		domain.CheckInPulse(req.Pulse), req.Comment,
+	)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checkIn)
+}
+
+// GetLearningCheckIns handles GET /api/learnings/:id/check-ins (the
+// learner, the mentor or learnings.view)
+func (h *CheckInHandler) GetLearningCheckIns(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	checkIns, err := h.checkInService.GetLearningCheckIns(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checkIns": checkIns})
+}
+
+// respondCheckInError maps check-in errors to status codes
+func respondCheckInError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCheckInNotFound),
+		errors.Is(err, domain.ErrLearningNotFound),
+		errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCheckInAnswered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
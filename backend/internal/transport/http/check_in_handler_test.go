@@ -0,0 +1,68 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestCheckIns(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+	admin := srv.Admin(t, "root")
+	ann := srv.Mentor(t, "ann", 0)
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+
+	// The scheduler sends check-ins; here one is due for each side
+	for _, c := range []*domain.CheckIn{
+		{LearningID: learning.ID, UserID: alice.User.ID, Role: domain.CheckInLearner, Round: 1},
+		{LearningID: learning.ID, UserID: ann.User.ID, Role: domain.CheckInMentor, Round: 1},
+	} {
+		if err := srv.CheckIns.Create(defaultCtx(), c); err != nil {
+			t.Fatalf("create check-in: %v", err)
+		}
+	}
+
+	var pending struct {
+		CheckIns []domain.CheckIn `json:"checkIns"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/check-ins/my", alice.Token, nil).Decode(t, &pending)
+	if len(pending.CheckIns) != 1 || pending.CheckIns[0].Topic != "Go" {
+		t.Fatalf("pending check-ins = %+v", pending.CheckIns)
+	}
+	answerPath := "/api/check-ins/" + pending.CheckIns[0].ID + "/answer"
+
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, answerPath, alice.Token, map[string]string{"pulse": "fine"})
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, answerPath, bob.Token, map[string]string{"pulse": "on_track"})
+	srv.Expect(t, http.StatusOK, http.MethodPost, answerPath, alice.Token, map[string]string{
+		"pulse": "blocked", "comment": "No access to the cluster",
+	})
+	srv.Expect(t, http.StatusConflict, http.MethodPost, answerPath, alice.Token, map[string]string{"pulse": "on_track"})
+
+	var history struct {
+		CheckIns []domain.CheckIn `json:"checkIns"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/learnings/"+learning.ID+"/check-ins", ann.Token, nil).Decode(t, &history)
+	if len(history.CheckIns) != 2 {
+		t.Errorf("learning check-ins = %+v, want both sides", history.CheckIns)
+	}
+
+	// A blocked pulse puts the learning at risk before it stalls
+	var report struct {
+		Learnings []domain.AtRiskLearning `json:"learnings"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/learnings/at-risk", admin.Token, nil).Decode(t, &report)
+	if len(report.Learnings) != 1 || report.Learnings[0].LearningID != learning.ID || report.Learnings[0].Pulse != domain.PulseBlocked {
+		t.Errorf("at-risk learnings = %+v", report.Learnings)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, "/api/admin/learnings/at-risk?days=0", admin.Token, nil)
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type CommentHandler struct {
+	commentService *service.CommentService
+}
+
+func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// GetRequestComments handles GET /api/requests/:id/comments
+func (h *CommentHandler) GetRequestComments(c *gin.Context) {
+	h.getComments(c, domain.CommentOnRequest)
+}
+
+// AddRequestComment handles POST /api/requests/:id/comments
+func (h *CommentHandler) AddRequestComment(c *gin.Context) {
+	h.addComment(c, domain.CommentOnRequest)
+}
+
+// GetLearningComments handles GET /api/learnings/:id/comments
+func (h *CommentHandler) GetLearningComments(c *gin.Context) {
+	h.getComments(c, domain.CommentOnLearning)
+}
+
+// AddLearningComment handles POST /api/learnings/:id/comments
+func (h *CommentHandler) AddLearningComment(c *gin.Context) {
+	h.addComment(c, domain.CommentOnLearning)
+}
+
+// UpdateComment handles PUT /api/comments/:id (author only)
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.UpdateCommentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.EditComment(c.Request.Context(), c.Param("id"), userID.(string), req.Body)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /api/comments/:id (author or comments.moderate)
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.commentService.DeleteComment(c.Request.Context(), c.Param("id"), userID.(string)); err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CommentHandler) getComments(c *gin.Context, target domain.CommentTarget) {
+	userID, _ := c.Get("userID")
+
+	comments, err := h.commentService.GetComments(c.Request.Context(), target, c.Param("id"), userID.(string))
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+func (h *CommentHandler) addComment(c *gin.Context, target domain.CommentTarget) {
+	userID, _ := c.Get("userID")
+
+	var req dto.CreateCommentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.AddComment(
+		c.Request.Context(),
+		target,
+		c.Param("id"),
+		userID.(string),
+		req.ParentID,
+		req.Body,
+		domain.CommentVisibility(req.Visibility),
+	)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// respondCommentError maps comment errors to HTTP status codes
+func respondCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRequestNotFound),
+		errors.Is(err, domain.ErrLearningNotFound),
+		errors.Is(err, domain.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotParticipant),
+		errors.Is(err, domain.ErrNotCommentAuthor),
+		errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCommentDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrEmptyField):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,89 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestCommentThreads(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	admin := srv.Admin(t, "root")
+	ann := srv.Mentor(t, "ann", 0)
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+	learningComments := "/api/learnings/" + learning.ID + "/comments"
+
+	// Learner and mentor talk in the thread instead of overwriting notes
+	var question domain.Comment
+	srv.Expect(t, http.StatusCreated, http.MethodPost, learningComments, alice.Token, map[string]string{
+		"body": "@" + ann.User.Email + " is chapter 3 needed?",
+	}).Decode(t, &question)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, learningComments, ann.Token, map[string]any{
+		"body":     "Yes, read it before Friday",
+		"parentId": question.ID,
+	})
+
+	var notifications struct {
+		Notifications []domain.Notification `json:"notifications"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/notifications", ann.Token, nil).Decode(t, &notifications)
+	if len(notifications.Notifications) != 1 || notifications.Notifications[0].Kind != domain.NotificationMentioned {
+		t.Fatalf("mentor notifications = %+v", notifications.Notifications)
+	}
+
+	var edited domain.Comment
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, "/api/comments/"+question.ID, ann.Token, map[string]string{"body": "x"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/comments/"+question.ID, alice.Token, map[string]string{
+		"body": "Is chapter 3 needed?",
+	}).Decode(t, &edited)
+	if edited.EditedAt == nil || edited.Body != "Is chapter 3 needed?" {
+		t.Fatalf("edited comment = %+v", edited)
+	}
+
+	var thread struct {
+		Comments []domain.Comment `json:"comments"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, learningComments, ann.Token, nil).Decode(t, &thread)
+	if len(thread.Comments) != 2 || thread.Comments[1].ParentID == nil || *thread.Comments[1].ParentID != question.ID ||
+		thread.Comments[1].AuthorName != "ann" {
+		t.Fatalf("learning thread = %+v", thread.Comments)
+	}
+
+	// Admins keep internal notes on the request that the employee never sees
+	requestComments := "/api/requests/" + learning.Request.ID + "/comments"
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, requestComments, alice.Token, map[string]string{
+		"body":       "note to self",
+		"visibility": "internal",
+	})
+	var internal domain.Comment
+	srv.Expect(t, http.StatusCreated, http.MethodPost, requestComments, admin.Token, map[string]string{
+		"body":       "Approved without budget review",
+		"visibility": "internal",
+	}).Decode(t, &internal)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, requestComments, alice.Token, map[string]string{"body": "Thanks!"})
+
+	thread.Comments = nil
+	srv.Expect(t, http.StatusOK, http.MethodGet, requestComments, alice.Token, nil).Decode(t, &thread)
+	if len(thread.Comments) != 1 || thread.Comments[0].Body != "Thanks!" {
+		t.Fatalf("employee sees %+v", thread.Comments)
+	}
+	srv.Expect(t, http.StatusNotFound, http.MethodDelete, "/api/comments/"+internal.ID, alice.Token, nil)
+
+	// Deleted comments keep their place without a body
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, "/api/comments/"+internal.ID, admin.Token, nil)
+	srv.Expect(t, http.StatusConflict, http.MethodPut, "/api/comments/"+internal.ID, admin.Token, map[string]string{"body": "x"})
+	thread.Comments = nil
+	srv.Expect(t, http.StatusOK, http.MethodGet, requestComments, admin.Token, nil).Decode(t, &thread)
+	if len(thread.Comments) != 2 || thread.Comments[0].DeletedAt == nil || thread.Comments[0].Body != "" {
+		t.Fatalf("admin sees %+v", thread.Comments)
+	}
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type CompetencyHandler struct {
+	competencyService *service.CompetencyService
+	departmentService *service.DepartmentService
+}
+
+func NewCompetencyHandler(competencyService *service.CompetencyService, departmentService *service.DepartmentService) *CompetencyHandler {
+	return &CompetencyHandler{
+		competencyService: competencyService,
+		departmentService: departmentService,
+	}
+}
+
+// GetSkills handles GET /api/skills
+func (h *CompetencyHandler) GetSkills(c *gin.Context) {
+	skills, err := h.competencyService.GetSkills(c.Request.Context())
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"skills": skills})
+}
+
+// CreateSkill handles POST /api/skills (skills.manage)
+func (h *CompetencyHandler) CreateSkill(c *gin.Context) {
+	var req dto.SkillDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := h.competencyService.CreateSkill(c.Request.Context(), &domain.Skill{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, skill)
+}
+
+// UpdateSkill handles PUT /api/skills/:id (skills.manage)
+func (h *CompetencyHandler) UpdateSkill(c *gin.Context) {
+	var req dto.SkillDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := h.competencyService.UpdateSkill(c.Request.Context(), &domain.Skill{
+		ID:          c.Param("id"),
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, skill)
+}
+
+// DeleteSkill handles DELETE /api/skills/:id (skills.manage)
+func (h *CompetencyHandler) DeleteSkill(c *gin.Context) {
+	if err := h.competencyService.DeleteSkill(c.Request.Context(), c.Param("id")); err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTargets handles GET /api/admin/skill-targets (skills.manage)
+func (h *CompetencyHandler) GetTargets(c *gin.Context) {
+	targets, err := h.competencyService.GetTargets(c.Request.Context())
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"targets": targets})
+}
+
+// CreateTarget handles POST /api/admin/skill-targets (skills.manage)
+func (h *CompetencyHandler) CreateTarget(c *gin.Context) {
+	var req dto.SkillTargetDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := h.competencyService.CreateTarget(c.Request.Context(), &domain.SkillTarget{
+		SkillID:    req.SkillID,
+		Department: req.Department,
+		JobTitle:   req.JobTitle,
+		Level:      req.Level,
+	})
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, target)
+}
+
+// DeleteTarget handles DELETE /api/admin/skill-targets/:id (skills.manage)
+func (h *CompetencyHandler) DeleteTarget(c *gin.Context) {
+	if err := h.competencyService.DeleteTarget(c.Request.Context(), c.Param("id")); err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetUserSkills handles GET /api/users/:id/skills (the user, their manager or
+// skills.manage)
+func (h *CompetencyHandler) GetUserSkills(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	skills, err := h.competencyService.GetUserSkills(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"skills": skills})
+}
+
+// AssessSkill handles PUT /api/users/:id/skills/:skillId; the user records a
+// self assessment, their manager or a user with skills.manage a manager assessment
+func (h *CompetencyHandler) AssessSkill(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.AssessSkillDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := h.competencyService.AssessSkill(c.Request.Context(), c.Param("id"), c.Param("skillId"), userID.(string), req.Level)
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, skill)
+}
+
+// GetSkillGaps handles GET /api/admin/skill-gaps?department= (analytics.view);
+// department heads get the report of their departments
+func (h *CompetencyHandler) GetSkillGaps(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	scope, err := h.departmentService.Scope(c.Request.Context(), userID.(string), domain.PermAnalyticsView)
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+	report, err := h.competencyService.SkillGapReport(c.Request.Context(), c.Query("department"), scope)
+	if err != nil {
+		respondCompetencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// respondCompetencyError maps competency errors to status codes
+func respondCompetencyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSkillNotFound),
+		errors.Is(err, domain.ErrSkillTargetNotFound),
+		errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrDepartmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrSkillExists),
+		errors.Is(err, domain.ErrSkillTargetExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrInvalidSkillLevel):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,87 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestCompetencyMatrix(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+	admin := srv.Admin(t, "root")
+	srv.Mentor(t, "ann", 0)
+
+	var skill domain.Skill
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/skills", alice.Token, map[string]string{"name": "Go"})
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/skills", admin.Token, map[string]string{"name": " Go "}).Decode(t, &skill)
+	if skill.Name != "Go" {
+		t.Fatalf("created skill = %+v", skill)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/skills", admin.Token, map[string]string{"name": "go"})
+
+	// Backend engineers are expected to reach level 3
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/skill-targets", admin.Token, map[string]any{
+		"skillId": skill.ID, "level": 3,
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/skill-targets", admin.Token, map[string]any{
+		"skillId": skill.ID, "department": "Backend", "level": 6,
+	})
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/admin/skill-targets", admin.Token, map[string]any{
+		"skillId": skill.ID, "department": "Backend", "level": 3,
+	})
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/admin/skill-targets", admin.Token, map[string]any{
+		"skillId": skill.ID, "department": "backend", "level": 2,
+	})
+
+	// Departments are picked from the ones an admin set up
+	srv.Expect(t, http.StatusBadRequest, http.MethodPut, "/api/auth/me", alice.Token, map[string]string{"department": "Backend"})
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/departments", admin.Token, map[string]string{"name": "Backend"})
+	for _, p := range []*apitest.Persona{alice, bob} {
+		srv.Expect(t, http.StatusOK, http.MethodPut, "/api/auth/me", p.Token, map[string]string{"department": "backend"})
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/users/"+alice.User.ID+"/skills/"+skill.ID, alice.Token, map[string]int{"level": 1})
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, "/api/users/"+alice.User.ID+"/skills/"+skill.ID, bob.Token, map[string]int{"level": 5})
+
+	var report domain.SkillGapReport
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/admin/skill-gaps", alice.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/skill-gaps?department=backend", admin.Token, nil).Decode(t, &report)
+	if len(report.Skills) != 1 || report.Skills[0].Employees != 2 || report.Skills[0].BelowTarget != 2 ||
+		len(report.Gaps) != 2 || report.Gaps[0].UserID != bob.User.ID || report.Gaps[0].Gap != 3 || report.Gaps[1].Gap != 2 {
+		t.Fatalf("skill gaps = %+v", report)
+	}
+
+	// A well rated learning tagged with the skill closes part of the gap
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]any{
+		"topic":       "Go",
+		"description": "Concurrency patterns",
+		"skills":      []string{"GO"},
+	}).Decode(t, &learning)
+	var request dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests/"+learning.Request.ID, alice.Token, nil).Decode(t, &request)
+	if len(request.Skills) != 1 || request.Skills[0] != "GO" {
+		t.Fatalf("request skills = %v", request.Skills)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/complete", alice.Token, map[string]any{
+		"rating":  5,
+		"comment": "Great mentor",
+	})
+
+	var levels struct {
+		Skills []domain.UserSkill `json:"skills"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/users/"+alice.User.ID+"/skills", alice.Token, nil).Decode(t, &levels)
+	if len(levels.Skills) != 1 || levels.Skills[0].Level != 2 || levels.Skills[0].SkillName != "Go" {
+		t.Fatalf("alice's skills = %+v", levels.Skills)
+	}
+	var everyone domain.SkillGapReport
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/skill-gaps", admin.Token, nil).Decode(t, &everyone)
+	if everyone.Department != nil || len(everyone.Gaps) != 2 || everyone.Gaps[1].UserID != alice.User.ID || everyone.Gaps[1].Gap != 1 {
+		t.Fatalf("skill gaps after the learning = %+v", everyone)
+	}
+}
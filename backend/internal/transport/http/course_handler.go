@@ -0,0 +1,227 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type CourseHandler struct {
+	courseService *service.CourseService
+}
+
+func NewCourseHandler(courseService *service.CourseService) *CourseHandler {
+	return &CourseHandler{
+		courseService: courseService,
+	}
+}
+
+// SearchCourses handles GET /api/courses?q=&skill=&format=&open=true
+func (h *CourseHandler) SearchCourses(c *gin.Context) {
+	filter := domain.CourseFilter{
+		Query: c.Query("q"),
+		Skill: c.Query("skill"),
+	}
+	if format := c.Query("format"); format != "" {
+		f := domain.CourseFormat(format)
+		filter.Format = &f
+	}
+	if open := c.Query("open"); open != "" {
+		onlyOpen, err := strconv.ParseBool(open)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "open must be true or false"})
+			return
+		}
+		if onlyOpen {
+			now := time.Now()
+			filter.OpenAt = &now
+		}
+	}
+
+	courses, err := h.courseService.SearchCourses(c.Request.Context(), filter)
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"courses": courses})
+}
+
+// GetCourse handles GET /api/courses/:id
+func (h *CourseHandler) GetCourse(c *gin.Context) {
+	course, err := h.courseService.GetCourse(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// CreateCourse handles POST /api/courses (courses.manage)
+func (h *CourseHandler) CreateCourse(c *gin.Context) {
+	var req dto.CourseDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	course, err := h.courseService.CreateCourse(c.Request.Context(), courseFromDTO(req))
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, course)
+}
+
+// UpdateCourse handles PUT /api/courses/:id (courses.manage)
+func (h *CourseHandler) UpdateCourse(c *gin.Context) {
+	var req dto.CourseDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	course := courseFromDTO(req)
+	course.ID = c.Param("id")
+
+	course, err := h.courseService.UpdateCourse(c.Request.Context(), course)
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// DeleteCourse handles DELETE /api/courses/:id (courses.manage)
+func (h *CourseHandler) DeleteCourse(c *gin.Context) {
+	if err := h.courseService.DeleteCourse(c.Request.Context(), c.Param("id")); err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Enroll handles POST /api/courses/:id/enroll; it answers with the training
+// request that goes through the approval chain
+func (h *CourseHandler) Enroll(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.EnrollDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	request, err := h.courseService.RequestEnrollment(c.Request.Context(), c.Param("id"), userID.(string), req.Description)
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToRequestResponseDTO(request))
+}
+
+// GetCourseEnrollments handles GET /api/courses/:id/enrollments (courses.manage)
+func (h *CourseHandler) GetCourseEnrollments(c *gin.Context) {
+	enrollments, err := h.courseService.GetCourseEnrollments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enrollments": enrollments})
+}
+
+// GetMyEnrollments handles GET /api/enrollments/my
+func (h *CourseHandler) GetMyEnrollments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	enrollments, err := h.courseService.GetUserEnrollments(c.Request.Context(), userID.(string))
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enrollments": enrollments})
+}
+
+// CompleteEnrollment handles POST /api/enrollments/:id/complete (owner or courses.manage)
+func (h *CourseHandler) CompleteEnrollment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.CompleteLearningDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enrollment, err := h.courseService.CompleteEnrollment(c.Request.Context(), c.Param("id"), userID.(string), req.Rating, req.Comment)
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// CancelEnrollment handles POST /api/enrollments/:id/cancel (owner or courses.manage)
+func (h *CourseHandler) CancelEnrollment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	enrollment, err := h.courseService.CancelEnrollment(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondCourseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// courseFromDTO converts course input to a course
+func courseFromDTO(req dto.CourseDTO) *domain.Course {
+	return &domain.Course{
+		Title:              req.Title,
+		Description:        req.Description,
+		Format:             domain.CourseFormat(req.Format),
+		DurationHours:      req.DurationHours,
+		Provider:           req.Provider,
+		Skills:             req.Skills,
+		Capacity:           req.Capacity,
+		EnrollmentOpensAt:  req.EnrollmentOpensAt,
+		EnrollmentClosesAt: req.EnrollmentClosesAt,
+	}
+}
+
+// respondCourseError maps course and enrollment errors to status codes
+func respondCourseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCourseNotFound),
+		errors.Is(err, domain.ErrEnrollmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCourseInUse),
+		errors.Is(err, domain.ErrCourseFull),
+		errors.Is(err, domain.ErrEnrollmentClosed),
+		errors.Is(err, domain.ErrAlreadyEnrolled),
+		errors.Is(err, domain.ErrEnrollmentNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrInvalidRating):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
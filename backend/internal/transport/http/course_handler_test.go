@@ -0,0 +1,92 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestCourseCatalog(t *testing.T) {
+	srv := apitest.NewWithApprovals(t, []domain.ApprovalStage{domain.StageAdmin})
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+	admin := srv.Admin(t, "root")
+
+	course := map[string]any{
+		"title":         "Kubernetes Basics",
+		"format":        "online",
+		"durationHours": 16,
+		"skills":        []string{"Kubernetes", "kubernetes"},
+		"capacity":      1,
+	}
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/courses", alice.Token, course)
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/courses", admin.Token, map[string]any{
+		"title": "Webinar", "format": "webinar", "durationHours": 1,
+	})
+	var created domain.Course
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/courses", admin.Token, course).Decode(t, &created)
+	if len(created.Skills) != 1 || created.Capacity == nil || *created.Capacity != 1 {
+		t.Fatalf("created course = %+v", created)
+	}
+
+	var found struct {
+		Courses []domain.Course `json:"courses"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/courses?skill=KUBERNETES&open=true", alice.Token, nil).Decode(t, &found)
+	if len(found.Courses) != 1 || found.Courses[0].ID != created.ID {
+		t.Fatalf("search by skill = %+v", found.Courses)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/courses?q=rust", alice.Token, nil).Decode(t, &found)
+	if len(found.Courses) != 0 {
+		t.Fatalf("search by text = %+v, want none", found.Courses)
+	}
+
+	// Both ask for the only seat; the admin approves alice first
+	var request, other dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/courses/"+created.ID+"/enroll", alice.Token, nil).Decode(t, &request)
+	if request.Status != "pending" || request.CourseID == nil || *request.CourseID != created.ID {
+		t.Fatalf("enrollment request = %+v", request)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/courses/"+created.ID+"/enroll", alice.Token, nil)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/courses/"+created.ID+"/enroll", bob.Token, map[string]string{
+		"description": "Platform team onboarding",
+	}).Decode(t, &other)
+
+	approve := map[string]string{"decision": "approved"}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/requests/"+request.ID+"/decision", admin.Token, approve)
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/requests/"+other.ID+"/decision", admin.Token, approve)
+	srv.Expect(t, http.StatusConflict, http.MethodDelete, "/api/courses/"+created.ID, admin.Token, nil)
+
+	var mine struct {
+		Enrollments []domain.CourseEnrollment `json:"enrollments"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/enrollments/my", alice.Token, nil).Decode(t, &mine)
+	if len(mine.Enrollments) != 1 || mine.Enrollments[0].CourseTitle != "Kubernetes Basics" || mine.Enrollments[0].Status != domain.EnrollmentActive {
+		t.Fatalf("my enrollments = %+v", mine.Enrollments)
+	}
+	enrollment := mine.Enrollments[0]
+
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/courses/"+created.ID+"/enrollments", alice.Token, nil)
+	var roster struct {
+		Enrollments []domain.CourseEnrollment `json:"enrollments"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/courses/"+created.ID+"/enrollments", admin.Token, nil).Decode(t, &roster)
+	if len(roster.Enrollments) != 1 || roster.Enrollments[0].UserName != "alice" {
+		t.Fatalf("course roster = %+v", roster.Enrollments)
+	}
+
+	// Only alice or an admin may finish the enrollment
+	feedback := map[string]any{"rating": 4, "comment": "Good labs"}
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/enrollments/"+enrollment.ID+"/complete", bob.Token, feedback)
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/enrollments/"+enrollment.ID+"/complete", alice.Token, feedback).Decode(t, &enrollment)
+	if enrollment.Status != domain.EnrollmentCompleted || enrollment.Feedback == nil || enrollment.Feedback.Rating != 4 {
+		t.Fatalf("completed enrollment = %+v", enrollment)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/enrollments/"+enrollment.ID+"/cancel", alice.Token, nil)
+
+	// Course requests are never handed to a mentor
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/requests/"+other.ID+"/queue", admin.Token, map[string]int{"priority": 1})
+}
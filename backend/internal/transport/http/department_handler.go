@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type DepartmentHandler struct {
+	departmentService *service.DepartmentService
+}
+
+func NewDepartmentHandler(departmentService *service.DepartmentService) *DepartmentHandler {
+	return &DepartmentHandler{
+		departmentService: departmentService,
+	}
+}
+
+// ListDepartments handles GET /api/departments
+func (h *DepartmentHandler) ListDepartments(c *gin.Context) {
+	departments, err := h.departmentService.ListDepartments(c.Request.Context())
+	if err != nil {
+		respondDepartmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"departments": departments})
+}
+
+// GetDepartment handles GET /api/departments/:id
+func (h *DepartmentHandler) GetDepartment(c *gin.Context) {
+	department, err := h.departmentService.GetDepartment(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondDepartmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, department)
+}
+
+// CreateDepartment handles POST /api/departments (departments.manage)
+func (h *DepartmentHandler) CreateDepartment(c *gin.Context) {
+	var req dto.DepartmentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	department, err := h.departmentService.CreateDepartment(c.Request.Context(), req.Name, req.ParentID, req.HeadID)
+	if err != nil {
+		respondDepartmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, department)
+}
+
+// UpdateDepartment handles PUT /api/departments/:id (departments.manage)
+func (h *DepartmentHandler) UpdateDepartment(c *gin.Context) {
+	var req dto.DepartmentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	department, err := h.departmentService.UpdateDepartment(c.Request.Context(), c.Param("id"), req.Name, req.ParentID, req.HeadID)
+	if err != nil {
+		respondDepartmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, department)
+}
+
+// DeleteDepartment handles DELETE /api/departments/:id (departments.manage);
+// only departments without members or subdepartments can be deleted
+func (h *DepartmentHandler) DeleteDepartment(c *gin.Context) {
+	if err := h.departmentService.DeleteDepartment(c.Request.Context(), c.Param("id")); err != nil {
+		respondDepartmentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondDepartmentError maps department errors to status codes
+func respondDepartmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDepartmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDepartmentExists),
+		errors.Is(err, domain.ErrDepartmentInUse),
+		errors.Is(err, domain.ErrDepartmentCycle),
+		errors.Is(err, domain.ErrUserDeactivated):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,73 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestDepartmentScopedAdministration(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	hank := srv.WithRole(t, "hank", domain.RoleDepartmentHead)
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+
+	// Engineering, headed by hank, contains Platform; Sales is elsewhere
+	var engineering, platform domain.Department
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/departments", admin.Token, map[string]any{
+		"name": "Engineering", "headId": hank.User.ID,
+	}).Decode(t, &engineering)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/departments", admin.Token, map[string]any{
+		"name": "Platform", "parentId": engineering.ID,
+	}).Decode(t, &platform)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/departments", admin.Token, map[string]string{"name": "Sales"})
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/departments", admin.Token, map[string]string{"name": "sales"})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/departments", admin.Token, map[string]any{
+		"name": "Legal", "parentId": apitest.MissingID(),
+	})
+	srv.Expect(t, http.StatusConflict, http.MethodPut, "/api/departments/"+engineering.ID, admin.Token, map[string]any{
+		"name": "Engineering", "parentId": platform.ID, "headId": hank.User.ID,
+	})
+
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/users/"+alice.User.ID, admin.Token, map[string]string{"department": "Platform"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/users/"+bob.User.ID, admin.Token, map[string]string{"department": "Sales"})
+
+	// Hank sees the people of the whole Engineering subtree and no one else
+	var users struct {
+		Users []domain.User `json:"users"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/users", hank.Token, nil).Decode(t, &users)
+	if len(users.Users) != 1 || users.Users[0].ID != alice.User.ID || *users.Users[0].Department != "Platform" {
+		t.Fatalf("hank's users = %+v", users.Users)
+	}
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/users/"+bob.User.ID, hank.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/users/"+alice.User.ID, hank.Token, map[string]string{"department": "Engineering"})
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, "/api/users/"+alice.User.ID, hank.Token, map[string]string{"department": "Sales"})
+
+	// and approves their requests only
+	var aliceRequest, bobRequest dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests", alice.Token, map[string]string{
+		"topic": "Go", "description": "Generics",
+	}).Decode(t, &aliceRequest)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests", bob.Token, map[string]string{
+		"topic": "Negotiation", "description": "Closing deals",
+	}).Decode(t, &bobRequest)
+
+	var requests struct {
+		Requests []dto.TrainingRequestResponseDTO `json:"requests"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests", hank.Token, nil).Decode(t, &requests)
+	if len(requests.Requests) != 1 || requests.Requests[0].ID != aliceRequest.ID {
+		t.Fatalf("hank's requests = %+v", requests.Requests)
+	}
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/requests/"+bobRequest.ID, hank.Token, nil)
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/requests/"+bobRequest.ID+"/decision", hank.Token, map[string]string{"decision": "approved"})
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/requests/"+aliceRequest.ID+"/decision", hank.Token, map[string]string{"decision": "approved"})
+
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/departments", hank.Token, map[string]string{"name": "Research"})
+	srv.Expect(t, http.StatusConflict, http.MethodDelete, "/api/departments/"+engineering.ID, admin.Token, nil)
+}
@@ -21,3 +21,9 @@ type LoginResponse struct {
 	Token string      `json:"token"`
 	User  interface{} `json:"user"`
 }
+
+// AcceptInvitationDTO represents the password chosen by an invited user
+type AcceptInvitationDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"time"
+)
+
+// CertificateVerificationDTO represents what a certificate attests, as
+// shown to anyone holding its code
+type CertificateVerificationDTO struct {
+	Code           string    `json:"code"`
+	LearnerName    string    `json:"learnerName"`
+	Topic          string    `json:"topic"`
+	MentorName     string    `json:"mentorName"`
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	CompletedItems []string  `json:"completedItems"`
+	IssuedAt       time.Time `json:"issuedAt"`
+}
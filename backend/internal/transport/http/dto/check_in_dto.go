@@ -0,0 +1,7 @@
+package dto
+
+// AnswerCheckInDTO represents the reply to a check-in
+type AnswerCheckInDTO struct {
+	Pulse   string `json:"pulse" binding:"required" example:"behind"`
+	Comment string `json:"comment" example:"Waiting for access to the staging cluster"`
+}
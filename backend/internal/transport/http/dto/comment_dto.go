@@ -0,0 +1,14 @@
+package dto
+
+// CreateCommentDTO represents comment creation input; set parentId to reply
+// to a comment of the same thread
+type CreateCommentDTO struct {
+	Body       string  `json:"body" binding:"required"`
+	ParentID   *string `json:"parentId"`
+	Visibility string  `json:"visibility" binding:"omitempty,oneof=public internal" example:"public"`
+}
+
+// UpdateCommentDTO represents comment edit input
+type UpdateCommentDTO struct {
+	Body string `json:"body" binding:"required"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// SkillDTO represents skill create and update input
+type SkillDTO struct {
+	Name        string `json:"name" binding:"required" example:"Kubernetes"`
+	Description string `json:"description" example:"Deploying and operating workloads on Kubernetes"`
+}
+
+// SkillTargetDTO represents the level expected in a skill; give a
+// department, a job title or both
+type SkillTargetDTO struct {
+	SkillID    string  `json:"skillId" binding:"required"`
+	Department *string `json:"department" example:"Platform"`
+	JobTitle   *string `json:"jobTitle" example:"Backend Engineer"`
+	Level      int     `json:"level" binding:"required,min=1,max=5" example:"3"`
+}
+
+// AssessSkillDTO represents a self or manager assessment of a skill level
+type AssessSkillDTO struct {
+	Level int `json:"level" binding:"required,min=1,max=5" example:"3"`
+}
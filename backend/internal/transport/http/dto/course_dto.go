@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// CourseDTO represents course create and update input; leave capacity empty
+// for unlimited seats and either enrollment bound empty to leave it open
+type CourseDTO struct {
+	Title              string     `json:"title" binding:"required" example:"Kubernetes Basics"`
+	Description        string     `json:"description"`
+	Format             string     `json:"format" binding:"required,oneof=online classroom blended self_paced" example:"online"`
+	DurationHours      int        `json:"durationHours" binding:"required,min=1" example:"16"`
+	Provider           string     `json:"provider" example:"Cloud Academy"`
+	Skills             []string   `json:"skills"`
+	Capacity           *int       `json:"capacity" binding:"omitempty,min=1" example:"20"`
+	EnrollmentOpensAt  *time.Time `json:"enrollmentOpensAt"`
+	EnrollmentClosesAt *time.Time `json:"enrollmentClosesAt"`
+}
+
+// EnrollDTO represents an enrollment request; the description defaults to
+// the course title
+type EnrollDTO struct {
+	Description string `json:"description" example:"Our team moves to Kubernetes next quarter"`
+}
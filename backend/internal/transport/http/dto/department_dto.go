@@ -0,0 +1,10 @@
+package dto
+
+// DepartmentDTO represents department create and update input; leave
+// parentId out for a top-level department and headId out for one without a
+// head
+type DepartmentDTO struct {
+	Name     string  `json:"name" binding:"required" example:"Platform"`
+	ParentID *string `json:"parentId" example:"5f0c7a52-8d6e-4f43-9a0d-0e8b3c3f1a27"`
+	HeadID   *string `json:"headId"`
+}
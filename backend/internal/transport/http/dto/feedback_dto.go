@@ -0,0 +1,28 @@
+package dto
+
+// FeedbackCriterionDTO represents one question of a questionnaire, scored
+// between min and max
+type FeedbackCriterionDTO struct {
+	Key      string `json:"key" binding:"required" example:"clarity"`
+	Label    string `json:"label" binding:"required" example:"How clearly did the mentor explain things?"`
+	Min      int    `json:"min" example:"1"`
+	Max      int    `json:"max" binding:"required" example:"5"`
+	Required bool   `json:"required" example:"true"`
+}
+
+// QuestionnaireDTO represents questionnaire create and update input; the
+// audience cannot change after creation
+type QuestionnaireDTO struct {
+	Name     string                 `json:"name" binding:"required" example:"Feedback on the mentor"`
+	Audience string                 `json:"audience" example:"learner"`
+	Criteria []FeedbackCriterionDTO `json:"criteria" binding:"required,min=1,dive"`
+	Active   bool                   `json:"active" example:"true"`
+}
+
+// SubmitFeedbackDTO represents answers to the active questionnaire, keyed
+// by criterion
+type SubmitFeedbackDTO struct {
+	Answers   map[string]int `json:"answers" binding:"required"`
+	Comment   string         `json:"comment" example:"Always found time for my questions"`
+	Anonymous bool           `json:"anonymous" example:"false"`
+}
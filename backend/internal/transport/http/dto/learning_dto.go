@@ -4,11 +4,12 @@ import "github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 
 // CreateLearningDTO represents request to create learning process
 type CreateLearningDTO struct {
-	Topic       string `json:"topic" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Topic       string   `json:"topic" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	Skills      []string `json:"skills"`
 }
 
-// UpdateLearningDTO represents full learning update (admin only)
+// UpdateLearningDTO represents full learning update (learnings.manage)
 type UpdateLearningDTO struct {
 	Topic       string                `json:"topic" binding:"required" example:"Go Programming"`
 	Description string                `json:"description" binding:"required"`
@@ -6,6 +6,17 @@ import (
 
 // ToRequestResponseDTO converts domain TrainingRequest to response DTO
 func ToRequestResponseDTO(req *domain.TrainingRequest) TrainingRequestResponseDTO {
+	var position *int
+	if req.QueuePosition > 0 {
+		p := req.QueuePosition
+		position = &p
+	}
+
+	chain := make([]string, len(req.ApprovalChain))
+	for i, stage := range req.ApprovalChain {
+		chain[i] = string(stage)
+	}
+
 	return TrainingRequestResponseDTO{
 		ID: req.ID,
 		User: RequestUserDTO{
@@ -14,11 +25,17 @@ func ToRequestResponseDTO(req *domain.TrainingRequest) TrainingRequestResponseDT
 			JobTitle: req.UserJobTitle,
 			Telegram: req.UserTelegram,
 		},
-		Topic:       req.Topic,
-		Description: req.Description,
-		Status:      string(req.Status),
-		CreatedAt:   req.CreatedAt,
-		UpdatedAt:   req.UpdatedAt,
+		Topic:         req.Topic,
+		Description:   req.Description,
+		Status:        string(req.Status),
+		Priority:      req.Priority,
+		QueuePosition: position,
+		QueuedAt:      req.QueuedAt,
+		ApprovalChain: chain,
+		CourseID:      req.CourseID,
+		Skills:        req.Skills,
+		CreatedAt:     req.CreatedAt,
+		UpdatedAt:     req.UpdatedAt,
 	}
 }
 
@@ -87,3 +104,18 @@ func ToLearningResponseDTOs(learnings []*domain.LearningProcess) []LearningProce
 	}
 	return dtos
 }
+
+// ToCertificateVerificationDTO converts a domain Certificate to its public
+// verification, leaving out internal IDs
+func ToCertificateVerificationDTO(certificate *domain.Certificate) CertificateVerificationDTO {
+	return CertificateVerificationDTO{
+		Code:           certificate.Code,
+		LearnerName:    certificate.LearnerName,
+		Topic:          certificate.Topic,
+		MentorName:     certificate.MentorName,
+		StartDate:      certificate.StartDate,
+		EndDate:        certificate.EndDate,
+		CompletedItems: certificate.CompletedItems,
+		IssuedAt:       certificate.IssuedAt,
+	}
+}
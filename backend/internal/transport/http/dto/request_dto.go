@@ -2,11 +2,24 @@ package dto
 
 // CreateRequestDTO represents training request creation input
 type CreateRequestDTO struct {
-	Topic       string `json:"topic" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Topic       string   `json:"topic" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	Skills      []string `json:"skills"`
 }
 
 // AssignMentorDTO represents mentor assignment input
 type AssignMentorDTO struct {
 	MentorID string `json:"mentorId" binding:"required"`
 }
+
+// EnqueueRequestDTO represents queue placement input; higher priorities are
+// served first
+type EnqueueRequestDTO struct {
+	Priority int `json:"priority" binding:"min=0,max=100" example:"0"`
+}
+
+// DecideRequestDTO represents an approval decision input
+type DecideRequestDTO struct {
+	Decision string  `json:"decision" binding:"required,oneof=approved rejected" example:"approved"`
+	Comment  *string `json:"comment"`
+}
@@ -14,11 +14,17 @@ type RequestUserDTO struct {
 
 // TrainingRequestResponseDTO represents request response with embedded user
 type TrainingRequestResponseDTO struct {
-	ID          string         `json:"id"`
-	User        RequestUserDTO `json:"user"`
-	Topic       string         `json:"topic"`
-	Description string         `json:"description"`
-	Status      string         `json:"status"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
+	ID            string         `json:"id"`
+	User          RequestUserDTO `json:"user"`
+	Topic         string         `json:"topic"`
+	Description   string         `json:"description"`
+	Status        string         `json:"status"`
+	Priority      int            `json:"priority"`
+	QueuePosition *int           `json:"queuePosition,omitempty"` // 1-based, only for queued requests
+	QueuedAt      *time.Time     `json:"queuedAt,omitempty"`
+	ApprovalChain []string       `json:"approvalChain"`      // stages the request goes through before the queue
+	CourseID      *string        `json:"courseId,omitempty"` // set on course enrollment requests
+	Skills        []string       `json:"skills"`             // skill tags matched against the competency model
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
 }
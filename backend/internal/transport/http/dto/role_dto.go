@@ -0,0 +1,23 @@
+package dto
+
+// CreateRoleDTO represents custom role creation input; scope is
+// "organization" (the default) or "department"
+type CreateRoleDTO struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	Scope       string   `json:"scope"`
+}
+
+// UpdateRoleDTO represents role update input; permissions replace the
+// current set
+type UpdateRoleDTO struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	Scope       string   `json:"scope"`
+}
+
+// SetRoleDTO represents role assignment input
+type SetRoleDTO struct {
+	Role string `json:"role" binding:"required"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+// TenantSettingsDTO represents the settings of a tenant; a zero
+// mentorCapacity means the default of 5. The SCIM token is issued through
+// its own endpoint.
+type TenantSettingsDTO struct {
+	MentorCapacity int         `json:"mentorCapacity" binding:"omitempty,min=1,max=20" example:"5"`
+	Branding       BrandingDTO `json:"branding"`
+	HRIS           HRISDTO     `json:"hris"`
+}
+
+// HRISDTO represents the employee export the scheduled HRIS sync imports;
+// a token of "********" keeps the stored one
+type HRISDTO struct {
+	URL        string            `json:"url" example:"https://hris.acme.example/export/employees"`
+	Token      string            `json:"token"`
+	RecordsKey string            `json:"recordsKey" example:"employees"`
+	Fields     map[string]string `json:"fields"`
+	Invite     bool              `json:"invite"`
+}
+
+// SCIMTokenResponseDTO represents a newly issued SCIM bearer token, shown
+// this once
+type SCIMTokenResponseDTO struct {
+	Token string `json:"token" example:"scim_6f1c..."`
+}
+
+// BrandingDTO represents what the frontend shows on the pages of a tenant
+type BrandingDTO struct {
+	DisplayName  string `json:"displayName" example:"Acme Academy"`
+	LogoURL      string `json:"logoUrl" example:"https://cdn.acme.example/logo.svg"`
+	PrimaryColor string `json:"primaryColor" example:"#1a2b3c"`
+}
+
+// CreateTenantDTO represents tenant creation input with the first admin of
+// the tenant
+type CreateTenantDTO struct {
+	Slug     string            `json:"slug" binding:"required" example:"acme"`
+	Name     string            `json:"name" binding:"required" example:"Acme Corp"`
+	Hosts    []string          `json:"hosts" example:"learning.acme.example"`
+	Settings TenantSettingsDTO `json:"settings"`
+	Admin    TenantAdminDTO    `json:"admin" binding:"required"`
+}
+
+// TenantAdminDTO represents the first admin of a new tenant
+type TenantAdminDTO struct {
+	Name     string `json:"name" binding:"required" example:"Jane Doe"`
+	Email    string `json:"email" binding:"required,email" example:"jane@acme.example"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// UpdateTenantDTO represents tenant update input; the slug cannot change
+// and the hosts replace the current ones
+type UpdateTenantDTO struct {
+	Name     string            `json:"name" binding:"required" example:"Acme Corp"`
+	Hosts    []string          `json:"hosts" example:"learning.acme.example"`
+	Settings TenantSettingsDTO `json:"settings"`
+}
+
+// TenantBrandingResponseDTO represents the public face of the tenant a host
+// resolves to
+type TenantBrandingResponseDTO struct {
+	ID       string      `json:"id"`
+	Slug     string      `json:"slug"`
+	Name     string      `json:"name"`
+	Branding BrandingDTO `json:"branding"`
+}
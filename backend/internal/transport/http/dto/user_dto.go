@@ -9,3 +9,8 @@ type UpdateUserDTO struct {
 	JobTitle   *string `json:"jobTitle"`
 	Telegram   *string `json:"telegram"`
 }
+
+// SetManagerDTO represents line manager assignment input; null clears it
+type SetManagerDTO struct {
+	ManagerID *string `json:"managerId"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// WebhookSubscriptionDTO represents webhook subscription create and update
+// input. Leave eventTypes empty to receive every event type. A secret is
+// generated on create when none is given; on update an empty secret keeps
+// the current one. Active defaults to true.
+type WebhookSubscriptionDTO struct {
+	URL         string   `json:"url" binding:"required,url" example:"https://hris.example.com/hooks/learning"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16"`
+	EventTypes  []string `json:"eventTypes" example:"learning.started,learning.completed"`
+	Description string   `json:"description" example:"HRIS training records"`
+	Active      *bool    `json:"active"`
+}
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type FeedbackHandler struct {
+	feedbackService *service.FeedbackService
+}
+
+func NewFeedbackHandler(feedbackService *service.FeedbackService) *FeedbackHandler {
+	return &FeedbackHandler{
+		feedbackService: feedbackService,
+	}
+}
+
+// GetQuestionnaires handles GET /api/admin/questionnaires (feedback.manage)
+func (h *FeedbackHandler) GetQuestionnaires(c *gin.Context) {
+	questionnaires, err := h.feedbackService.GetQuestionnaires(c.Request.Context())
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"questionnaires": questionnaires})
+}
+
+// CreateQuestionnaire handles POST /api/admin/questionnaires (feedback.manage)
+func (h *FeedbackHandler) CreateQuestionnaire(c *gin.Context) {
+	var req dto.QuestionnaireDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	questionnaire, err := h.feedbackService.CreateQuestionnaire(c.Request.Context(), questionnaireFromDTO(req))
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, questionnaire)
+}
+
+// UpdateQuestionnaire handles PUT /api/admin/questionnaires/:id
+// (feedback.manage); answered questionnaires cannot change
+func (h *FeedbackHandler) UpdateQuestionnaire(c *gin.Context) {
+	var req dto.QuestionnaireDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	questionnaire := questionnaireFromDTO(req)
+	questionnaire.ID = c.Param("id")
+
+	questionnaire, err := h.feedbackService.UpdateQuestionnaire(c.Request.Context(), questionnaire)
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionnaire)
+}
+
+// DeleteQuestionnaire handles DELETE /api/admin/questionnaires/:id
+// (feedback.manage)
+func (h *FeedbackHandler) DeleteQuestionnaire(c *gin.Context) {
+	if err := h.feedbackService.DeleteQuestionnaire(c.Request.Context(), c.Param("id")); err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ActivateQuestionnaire handles POST /api/admin/questionnaires/:id/activate
+// (feedback.manage)
+func (h *FeedbackHandler) ActivateQuestionnaire(c *gin.Context) {
+	questionnaire, err := h.feedbackService.ActivateQuestionnaire(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionnaire)
+}
+
+// GetLearningFeedback handles GET /api/learnings/:id/feedback (the learner,
+// the mentor or feedback.view)
+func (h *FeedbackHandler) GetLearningFeedback(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	feedback, err := h.feedbackService.GetLearningFeedback(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// SubmitFeedback handles POST /api/learnings/:id/feedback; the learner
+// answers about the mentor, the mentor about the learner
+func (h *FeedbackHandler) SubmitFeedback(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.SubmitFeedbackDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.feedbackService.SubmitFeedback(
+		c.Request.Context(), c.Param("id"), userID.(string),
+		req.Answers, req.Comment, req.Anonymous,
+	)
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetMentorFeedback handles GET /api/mentors/:id/feedback (the mentor or
+// feedback.view)
+func (h *FeedbackHandler) GetMentorFeedback(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	summary, err := h.feedbackService.GetMentorFeedback(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// questionnaireFromDTO converts questionnaire input to a questionnaire
+func questionnaireFromDTO(req dto.QuestionnaireDTO) *domain.Questionnaire {
+	criteria := make([]domain.FeedbackCriterion, len(req.Criteria))
+	for i, c := range req.Criteria {
+		criteria[i] = domain.FeedbackCriterion{
+			Key:      c.Key,
+			Label:    c.Label,
+			Min:      c.Min,
+			Max:      c.Max,
+			Required: c.Required,
+		}
+	}
+	return &domain.Questionnaire{
+		Name:     req.Name,
+		Audience: domain.FeedbackAudience(req.Audience),
+		Criteria: criteria,
+		Active:   req.Active,
+	}
+}
+
+// respondFeedbackError maps feedback errors to status codes
+func respondFeedbackError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrQuestionnaireNotFound),
+		errors.Is(err, domain.ErrLearningNotFound),
+		errors.Is(err, domain.ErrMentorNotFound),
+		errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQuestionnaireInUse),
+		errors.Is(err, domain.ErrNoQuestionnaire),
+		errors.Is(err, domain.ErrFeedbackExists),
+		errors.Is(err, domain.ErrLearningNotCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrInvalidFeedback):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
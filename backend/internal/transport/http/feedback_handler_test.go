@@ -0,0 +1,82 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestFeedback(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	admin := srv.Admin(t, "root")
+	ann := srv.Mentor(t, "ann", 0)
+
+	// Admins set up what learners and mentors are asked
+	for _, form := range []map[string]any{
+		{"name": "Feedback on the mentor", "audience": "learner", "active": true, "criteria": []map[string]any{
+			{"key": "clarity", "label": "Clarity", "min": 1, "max": 5, "required": true},
+			{"key": "recommend", "label": "Recommend", "min": 0, "max": 10, "required": true},
+		}},
+		{"name": "Assessment of the learner", "audience": "mentor", "active": true, "criteria": []map[string]any{
+			{"key": "engagement", "label": "Engagement", "min": 1, "max": 5, "required": true},
+		}},
+	} {
+		srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/admin/questionnaires", admin.Token, form)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/questionnaires", admin.Token, map[string]any{
+		"name": "Broken", "audience": "learner", "criteria": []map[string]any{{"key": "clarity", "label": "Clarity", "min": 5, "max": 1}},
+	})
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+	feedbackPath := "/api/learnings/" + learning.ID + "/feedback"
+	answers := map[string]any{"answers": map[string]int{"clarity": 4, "recommend": 9}, "comment": "Clear", "anonymous": true}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, feedbackPath, alice.Token, answers)
+
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/complete", alice.Token, map[string]any{
+		"rating": 5, "comment": "Thanks",
+	})
+
+	var view domain.LearningFeedback
+	srv.Expect(t, http.StatusOK, http.MethodGet, feedbackPath, alice.Token, nil).Decode(t, &view)
+	if view.Pending == nil || view.Pending.Audience != domain.FeedbackFromLearner || len(view.Responses) != 0 {
+		t.Fatalf("learner view before feedback = %+v", view)
+	}
+
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, feedbackPath, alice.Token, map[string]any{"answers": map[string]int{"clarity": 4}})
+	srv.Expect(t, http.StatusCreated, http.MethodPost, feedbackPath, alice.Token, answers)
+	srv.Expect(t, http.StatusConflict, http.MethodPost, feedbackPath, alice.Token, answers)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, feedbackPath, ann.Token, map[string]any{"answers": map[string]int{"engagement": 5}})
+
+	// The anonymous answers stay out of the mentor's view of the learning
+	view = domain.LearningFeedback{}
+	srv.Expect(t, http.StatusOK, http.MethodGet, feedbackPath, ann.Token, nil).Decode(t, &view)
+	if len(view.Responses) != 1 || view.Responses[0].Audience != domain.FeedbackFromMentor || view.Pending != nil {
+		t.Errorf("mentor view = %+v", view)
+	}
+
+	// ...but count in the mentor's aggregate
+	var summary domain.MentorFeedbackSummary
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors/"+ann.Mentor.ID+"/feedback", ann.Token, nil).Decode(t, &summary)
+	if summary.RatedLearnings != 1 || summary.AverageRating != 5 || summary.Responses != 1 || len(summary.Criteria) != 2 ||
+		summary.Criteria[1].Key != "recommend" || summary.Criteria[1].Average != 9 || len(summary.Comments) != 1 {
+		t.Errorf("mentor summary = %+v", summary)
+	}
+
+	// Answered questionnaires are kept as they were
+	var list struct {
+		Questionnaires []domain.Questionnaire `json:"questionnaires"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/questionnaires", admin.Token, nil).Decode(t, &list)
+	if len(list.Questionnaires) != 2 {
+		t.Fatalf("questionnaires = %+v", list.Questionnaires)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodDelete, "/api/admin/questionnaires/"+list.Questionnaires[0].ID, admin.Token, nil)
+}
@@ -3,6 +3,8 @@ package http
 import (
 	"log/slog"
 
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/health"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/middleware"
 
@@ -11,11 +13,32 @@ import (
 )
 
 type Handler struct {
-	authHandler     *AuthHandler
-	userHandler     *UserHandler
-	requestHandler  *RequestHandler
-	learningHandler *LearningHandler
-	mentorHandler   *MentorHandler
+	authService       *service.AuthService
+	departmentService *service.DepartmentService
+
+	healthHandler       *HealthHandler
+	authHandler         *AuthHandler
+	userHandler         *UserHandler
+	requestHandler      *RequestHandler
+	learningHandler     *LearningHandler
+	mentorHandler       *MentorHandler
+	availabilityHandler *AvailabilityHandler
+	notificationHandler *NotificationHandler
+	commentHandler      *CommentHandler
+	attachmentHandler   *AttachmentHandler
+	courseHandler       *CourseHandler
+	competencyHandler   *CompetencyHandler
+	certificateHandler  *CertificateHandler
+	feedbackHandler     *FeedbackHandler
+	checkInHandler      *CheckInHandler
+	jobHandler          *JobHandler
+	webhookHandler      *WebhookHandler
+	userImportHandler   *UserImportHandler
+	scimHandler         *SCIMHandler
+	roleHandler         *RoleHandler
+	departmentHandler   *DepartmentHandler
+	tenantHandler       *TenantHandler
+	tenantService       *service.TenantService
 }
 
 func NewHandler(
@@ -24,92 +47,327 @@ func NewHandler(
 	requestService *service.RequestService,
 	learningService *service.LearningService,
 	mentorService *service.MentorService,
+	availabilityService *service.AvailabilityService,
+	handoffService *service.HandoffService,
+	notificationService *service.NotificationService,
+	queueService *service.QueueService,
+	approvalService *service.ApprovalService,
+	commentService *service.CommentService,
+	attachmentService *service.AttachmentService,
+	courseService *service.CourseService,
+	competencyService *service.CompetencyService,
+	certificateService *service.CertificateService,
+	feedbackService *service.FeedbackService,
+	checkInService *service.CheckInService,
+	jobService *service.JobService,
+	webhookService *service.WebhookService,
+	userImportService *service.UserImportService,
+	scimService *service.SCIMService,
+	roleService *service.RoleService,
+	departmentService *service.DepartmentService,
+	tenantService *service.TenantService,
+	monitor *health.Monitor,
 ) *Handler {
 	return &Handler{
-		authHandler:     NewAuthHandler(authService, userService),
-		userHandler:     NewUserHandler(userService, learningService, requestService),
-		requestHandler:  NewRequestHandler(requestService, learningService),
-		learningHandler: NewLearningHandler(learningService),
-		mentorHandler:   NewMentorHandler(mentorService),
+		authService:         authService,
+		departmentService:   departmentService,
+		tenantService:       tenantService,
+		healthHandler:       NewHealthHandler(monitor),
+		authHandler:         NewAuthHandler(authService, userService),
+		userHandler:         NewUserHandler(userService, learningService, requestService, departmentService),
+		requestHandler:      NewRequestHandler(requestService, learningService, queueService, approvalService, departmentService),
+		learningHandler:     NewLearningHandler(learningService, departmentService),
+		mentorHandler:       NewMentorHandler(mentorService, handoffService),
+		availabilityHandler: NewAvailabilityHandler(availabilityService),
+		notificationHandler: NewNotificationHandler(notificationService),
+		commentHandler:      NewCommentHandler(commentService),
+		attachmentHandler:   NewAttachmentHandler(attachmentService),
+		courseHandler:       NewCourseHandler(courseService),
+		competencyHandler:   NewCompetencyHandler(competencyService, departmentService),
+		certificateHandler:  NewCertificateHandler(certificateService),
+		feedbackHandler:     NewFeedbackHandler(feedbackService),
+		checkInHandler:      NewCheckInHandler(checkInService, departmentService),
+		jobHandler:          NewJobHandler(jobService),
+		webhookHandler:      NewWebhookHandler(webhookService),
+		userImportHandler:   NewUserImportHandler(userImportService),
+		scimHandler:         NewSCIMHandler(scimService),
+		roleHandler:         NewRoleHandler(roleService, userService),
+		departmentHandler:   NewDepartmentHandler(departmentService),
+		tenantHandler:       NewTenantHandler(tenantService),
 	}
 }
 
-// InitRoutes registers all HTTP routes
-func (h *Handler) InitRoutes(router *gin.Engine, logger *slog.Logger, jwtSecret string) {
+// InitRoutes registers all HTTP routes; scimToken, when set, provisions the
+// default tenant over SCIM besides the tokens tenants issue, and the tenant
+// management routes are only registered when superAdminToken is set
+func (h *Handler) InitRoutes(router *gin.Engine, logger *slog.Logger, jwtSecret, scimToken, superAdminToken string) {
 	// Global middleware
 	router.Use(middleware.RecoveryMiddleware(logger))
 	router.Use(middleware.LoggerMiddleware(logger))
 	router.Use(middleware.PrometheusMiddleware())
 
 	// Root level endpoints
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"service": "internal-training-system",
-		})
-	})
+	router.GET("/health", h.healthHandler.Health)
+	router.GET("/health/live", h.healthHandler.Live)
+	router.GET("/health/ready", h.healthHandler.Ready)
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	// API routes
+	// SCIM provisioning /scim/v2, for identity providers, in the tenant
+	// of the token
+	scimRoutes := router.Group("/scim/v2")
+	scimRoutes.Use(middleware.TenantMiddleware(h.tenantService))
+	scimRoutes.Use(middleware.SCIMAuth(h.tenantService, scimToken))
+	{
+		scimRoutes.GET("/Users", h.scimHandler.ListUsers)
+		scimRoutes.POST("/Users", h.scimHandler.CreateUser)
+		scimRoutes.GET("/Users/:id", h.scimHandler.GetUser)
+		scimRoutes.PUT("/Users/:id", h.scimHandler.ReplaceUser)
+		scimRoutes.PATCH("/Users/:id", h.scimHandler.PatchUser)
+		scimRoutes.DELETE("/Users/:id", h.scimHandler.DeleteUser)
+		scimRoutes.GET("/Groups", h.scimHandler.ListGroups)
+		scimRoutes.POST("/Groups", h.scimHandler.CreateGroup)
+		scimRoutes.GET("/Groups/:id", h.scimHandler.GetGroup)
+		scimRoutes.PUT("/Groups/:id", h.scimHandler.ReplaceGroup)
+		scimRoutes.PATCH("/Groups/:id", h.scimHandler.PatchGroup)
+		scimRoutes.DELETE("/Groups/:id", h.scimHandler.DeleteGroup)
+		scimRoutes.GET("/ServiceProviderConfig", h.scimHandler.GetServiceProviderConfig)
+		scimRoutes.GET("/ResourceTypes", h.scimHandler.GetResourceTypes)
+	}
+
+	// API routes, in the tenant of the host until a token says otherwise
 	api := router.Group("/api")
+	api.Use(middleware.TenantMiddleware(h.tenantService))
 	{
+		// Tenant /api/tenant (public, for branding the login page)
+		api.GET("/tenant", h.tenantHandler.GetCurrentTenant)
+
+		// Tenants /api/super/tenants, for operators of the deployment
+		if superAdminToken != "" {
+			super := api.Group("/super")
+			super.Use(middleware.SuperAdminAuth(superAdminToken))
+			{
+				super.GET("/tenants", h.tenantHandler.ListTenants)
+				super.POST("/tenants", h.tenantHandler.CreateTenant)
+				super.GET("/tenants/:id", h.tenantHandler.GetTenant)
+				super.PUT("/tenants/:id", h.tenantHandler.UpdateTenant)
+			}
+		}
+
 		// Auth /api/auth
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", h.authHandler.Register)
 			auth.POST("/login", h.authHandler.Login)
+			auth.POST("/accept-invitation", h.authHandler.AcceptInvitation)
 
 			// Protected auth routes
-			auth.GET("/me", middleware.AuthMiddleware(jwtSecret), h.authHandler.GetMe)
-			auth.PUT("/me", middleware.AuthMiddleware(jwtSecret), h.authHandler.UpdateMe)
+			auth.GET("/me", middleware.AuthMiddleware(jwtSecret, h.authService), h.authHandler.GetMe)
+			auth.PUT("/me", middleware.AuthMiddleware(jwtSecret, h.authService), h.authHandler.UpdateMe)
 		}
 
 		// Protected routes (require authentication)
 		// Users /api/users
 		users := api.Group("/users")
-		users.Use(middleware.AuthMiddleware(jwtSecret))
+		users.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			users.GET("", middleware.RequireScopedPermission(domain.PermUsersView), h.userHandler.GetAllUsers)
+			users.GET("/:id", middleware.OwnerOrPermission(domain.PermUsersView, h.departmentService), h.userHandler.GetUserByID)
+			users.PUT("/:id", middleware.OwnerOrPermission(domain.PermUsersManage, h.departmentService), h.userHandler.UpdateUserByID)
+			users.GET("/:id/requests", middleware.OwnerOrPermission(domain.PermUsersView, h.departmentService), h.userHandler.GetUserRequests)
+			users.GET("/:id/learnings", middleware.OwnerOrPermission(domain.PermUsersView, h.departmentService), h.userHandler.GetUserLearnings)
+			users.POST("/:id/deactivate", middleware.PermissionOverUser(domain.PermUsersManage, h.departmentService), h.userHandler.DeactivateUser)
+			users.POST("/:id/reactivate", middleware.PermissionOverUser(domain.PermUsersManage, h.departmentService), h.userHandler.ReactivateUser)
+			users.PUT("/:id/manager", middleware.PermissionOverUser(domain.PermUsersManage, h.departmentService), h.userHandler.SetManager)
+			users.GET("/:id/skills", h.competencyHandler.GetUserSkills)
+			users.PUT("/:id/skills/:skillId", h.competencyHandler.AssessSkill)
+		}
+
+		// Departments /api/departments
+		departments := api.Group("/departments")
+		departments.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
 		{
-			// Admin only
-			users.GET("", middleware.AdminOnly(), h.userHandler.GetAllUsers)
-			users.GET("/:id", middleware.OwnerOrAdminOnly(), h.userHandler.GetUserByID)
-			users.PUT("/:id", middleware.OwnerOrAdminOnly(), h.userHandler.UpdateUserByID)
-			users.GET("/:id/requests", middleware.OwnerOrAdminOnly(), h.userHandler.GetUserRequests)
-			users.GET("/:id/learnings", middleware.OwnerOrAdminOnly(), h.userHandler.GetUserLearnings)
+			departments.GET("", h.departmentHandler.ListDepartments)
+			departments.POST("", middleware.RequirePermission(domain.PermDepartmentsManage), h.departmentHandler.CreateDepartment)
+			departments.GET("/:id", h.departmentHandler.GetDepartment)
+			departments.PUT("/:id", middleware.RequirePermission(domain.PermDepartmentsManage), h.departmentHandler.UpdateDepartment)
+			departments.DELETE("/:id", middleware.RequirePermission(domain.PermDepartmentsManage), h.departmentHandler.DeleteDepartment)
 		}
 
 		// Requests /api/requests
 		requests := api.Group("/requests")
-		requests.Use(middleware.AuthMiddleware(jwtSecret))
+		requests.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
 		{
-			requests.GET("", middleware.AdminOnly(), h.requestHandler.GetAllRequests)
+			requests.GET("", middleware.RequireScopedPermission(domain.PermRequestsView), h.requestHandler.GetAllRequests)
 			requests.POST("", h.requestHandler.CreateRequest)
 			requests.GET("/my", h.requestHandler.GetMyRequests)
+			requests.GET("/team", h.requestHandler.GetTeamRequests)
+			requests.GET("/queue", middleware.RequirePermission(domain.PermRequestsAssign), h.requestHandler.GetQueue)
+			requests.POST("/queue/dispatch", middleware.RequirePermission(domain.PermRequestsAssign), h.requestHandler.DispatchQueue)
 			requests.GET("/:id", h.requestHandler.GetRequestByID)
 			requests.PUT("/:id", h.requestHandler.UpdateRequest)
-			requests.POST("/:id/assign", middleware.AdminOnly(), h.requestHandler.AssignMentor)
+			requests.POST("/:id/assign", middleware.RequirePermission(domain.PermRequestsAssign), h.requestHandler.AssignMentor)
+			requests.POST("/:id/queue", middleware.RequirePermission(domain.PermRequestsAssign), h.requestHandler.EnqueueRequest)
+			requests.DELETE("/:id/queue", middleware.RequirePermission(domain.PermRequestsAssign), h.requestHandler.DequeueRequest)
+			requests.GET("/:id/approvals", h.requestHandler.GetApprovals)
+			requests.POST("/:id/decision", h.requestHandler.DecideRequest)
+			requests.GET("/:id/comments", h.commentHandler.GetRequestComments)
+			requests.POST("/:id/comments", h.commentHandler.AddRequestComment)
 		}
 
 		// Mentors /api/mentors
 		mentors := api.Group("/mentors")
-		mentors.Use(middleware.AuthMiddleware(jwtSecret))
+		mentors.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
 		{
 			mentors.GET("", h.mentorHandler.GetAllMentors)
-			mentors.POST("", middleware.AdminOnly(), h.mentorHandler.CreateMentor)
+			mentors.POST("", middleware.RequirePermission(domain.PermMentorsManage), h.mentorHandler.CreateMentor)
 			mentors.GET("/:id", h.mentorHandler.GetMentorByID)
-			mentors.PUT("/:id", middleware.AdminOnly(), h.mentorHandler.UpdateMentor)
+			mentors.PUT("/:id", middleware.RequirePermission(domain.PermMentorsManage), h.mentorHandler.UpdateMentor)
+			mentors.POST("/:id/deactivate", middleware.RequirePermission(domain.PermMentorsManage), h.mentorHandler.DeactivateMentor)
+			mentors.POST("/:id/reactivate", middleware.RequirePermission(domain.PermMentorsManage), h.mentorHandler.ReactivateMentor)
+			mentors.GET("/:id/handoff", middleware.RequirePermission(domain.PermMentorsManage), h.mentorHandler.PlanHandoff)
+			mentors.POST("/:id/handoff", middleware.RequirePermission(domain.PermMentorsManage), h.mentorHandler.ExecuteHandoff)
+			mentors.GET("/:id/availability", h.availabilityHandler.GetSchedule)
+			mentors.GET("/:id/availability/slots", h.availabilityHandler.GetFreeSlots)
+			mentors.POST("/:id/availability/windows", middleware.RequirePermission(domain.PermMentorsManage), h.availabilityHandler.CreateWindow)
+			mentors.PUT("/:id/availability/windows/:windowId", middleware.RequirePermission(domain.PermMentorsManage), h.availabilityHandler.UpdateWindow)
+			mentors.DELETE("/:id/availability/windows/:windowId", middleware.RequirePermission(domain.PermMentorsManage), h.availabilityHandler.DeleteWindow)
+			mentors.POST("/:id/availability/absences", middleware.RequirePermission(domain.PermMentorsManage), h.availabilityHandler.CreateAbsence)
+			mentors.PUT("/:id/availability/absences/:absenceId", middleware.RequirePermission(domain.PermMentorsManage), h.availabilityHandler.UpdateAbsence)
+			mentors.DELETE("/:id/availability/absences/:absenceId", middleware.RequirePermission(domain.PermMentorsManage), h.availabilityHandler.DeleteAbsence)
+			mentors.GET("/:id/feedback", h.feedbackHandler.GetMentorFeedback)
 		}
 
 		// Learnings /api/learnings
 		learnings := api.Group("/learnings")
-		learnings.Use(middleware.AuthMiddleware(jwtSecret))
+		learnings.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
 		{
 			learnings.GET("", h.learningHandler.GetMyLearnings)
 			learnings.POST("", h.learningHandler.CreateLearning)
 			learnings.GET("/:id", h.learningHandler.GetLearningByID)
-			learnings.PUT("/:id", middleware.AdminOnly(), h.learningHandler.UpdateLearning)
+			learnings.PUT("/:id", middleware.RequirePermission(domain.PermLearningsManage), h.learningHandler.UpdateLearning)
+			learnings.POST("/:id/assign", middleware.RequirePermission(domain.PermLearningsManage), h.learningHandler.AssignMentor)
 			learnings.PUT("/:id/plan", h.learningHandler.UpdatePlan)
 			learnings.PUT("/:id/notes", h.learningHandler.UpdateNotes)
 			learnings.POST("/:id/complete", h.learningHandler.CompleteLearning)
+			learnings.GET("/:id/comments", h.commentHandler.GetLearningComments)
+			learnings.POST("/:id/comments", h.commentHandler.AddLearningComment)
+			learnings.GET("/:id/attachments", h.attachmentHandler.GetLearningAttachments)
+			learnings.POST("/:id/attachments", h.attachmentHandler.UploadLearningAttachment)
+			learnings.GET("/:id/certificate", h.certificateHandler.DownloadLearningCertificate)
+			learnings.GET("/:id/feedback", h.feedbackHandler.GetLearningFeedback)
+			learnings.POST("/:id/feedback", h.feedbackHandler.SubmitFeedback)
+			learnings.GET("/:id/check-ins", h.checkInHandler.GetLearningCheckIns)
+		}
+
+		// Check-ins /api/check-ins
+		checkIns := api.Group("/check-ins")
+		checkIns.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			checkIns.GET("/my", h.checkInHandler.GetMyCheckIns)
+			checkIns.POST("/:id/answer", h.checkInHandler.AnswerCheckIn)
+		}
+
+		// Certificates /api/certificates (public, for third parties checking
+		// a certificate)
+		api.GET("/certificates/:code", h.certificateHandler.VerifyCertificate)
+
+		// Comments /api/comments
+		comments := api.Group("/comments")
+		comments.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			comments.PUT("/:id", h.commentHandler.UpdateComment)
+			comments.DELETE("/:id", h.commentHandler.DeleteComment)
+			comments.GET("/:id/attachments", h.attachmentHandler.GetCommentAttachments)
+			comments.POST("/:id/attachments", h.attachmentHandler.UploadCommentAttachment)
+		}
+
+		// Attachments /api/attachments
+		attachments := api.Group("/attachments")
+		attachments.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			attachments.GET("/:id", h.attachmentHandler.DownloadAttachment)
+			attachments.DELETE("/:id", h.attachmentHandler.DeleteAttachment)
+		}
+
+		// Courses /api/courses
+		courses := api.Group("/courses")
+		courses.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			courses.GET("", h.courseHandler.SearchCourses)
+			courses.POST("", middleware.RequirePermission(domain.PermCoursesManage), h.courseHandler.CreateCourse)
+			courses.GET("/:id", h.courseHandler.GetCourse)
+			courses.PUT("/:id", middleware.RequirePermission(domain.PermCoursesManage), h.courseHandler.UpdateCourse)
+			courses.DELETE("/:id", middleware.RequirePermission(domain.PermCoursesManage), h.courseHandler.DeleteCourse)
+			courses.POST("/:id/enroll", h.courseHandler.Enroll)
+			courses.GET("/:id/enrollments", middleware.RequirePermission(domain.PermCoursesManage), h.courseHandler.GetCourseEnrollments)
+		}
+
+		// Enrollments /api/enrollments
+		enrollments := api.Group("/enrollments")
+		enrollments.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			enrollments.GET("/my", h.courseHandler.GetMyEnrollments)
+			enrollments.POST("/:id/complete", h.courseHandler.CompleteEnrollment)
+			enrollments.POST("/:id/cancel", h.courseHandler.CancelEnrollment)
+		}
+
+		// Skills /api/skills
+		skills := api.Group("/skills")
+		skills.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			skills.GET("", h.competencyHandler.GetSkills)
+			skills.POST("", middleware.RequirePermission(domain.PermSkillsManage), h.competencyHandler.CreateSkill)
+			skills.PUT("/:id", middleware.RequirePermission(domain.PermSkillsManage), h.competencyHandler.UpdateSkill)
+			skills.DELETE("/:id", middleware.RequirePermission(domain.PermSkillsManage), h.competencyHandler.DeleteSkill)
+		}
+
+		// Admin reports and settings /api/admin, each route requires its own
+		// permission
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			admin.GET("/skill-gaps", middleware.RequireScopedPermission(domain.PermAnalyticsView), h.competencyHandler.GetSkillGaps)
+			admin.GET("/skill-targets", middleware.RequirePermission(domain.PermSkillsManage), h.competencyHandler.GetTargets)
+			admin.POST("/skill-targets", middleware.RequirePermission(domain.PermSkillsManage), h.competencyHandler.CreateTarget)
+			admin.DELETE("/skill-targets/:id", middleware.RequirePermission(domain.PermSkillsManage), h.competencyHandler.DeleteTarget)
+			admin.GET("/questionnaires", middleware.RequirePermission(domain.PermFeedbackManage), h.feedbackHandler.GetQuestionnaires)
+			admin.POST("/questionnaires", middleware.RequirePermission(domain.PermFeedbackManage), h.feedbackHandler.CreateQuestionnaire)
+			admin.PUT("/questionnaires/:id", middleware.RequirePermission(domain.PermFeedbackManage), h.feedbackHandler.UpdateQuestionnaire)
+			admin.DELETE("/questionnaires/:id", middleware.RequirePermission(domain.PermFeedbackManage), h.feedbackHandler.DeleteQuestionnaire)
+			admin.POST("/questionnaires/:id/activate", middleware.RequirePermission(domain.PermFeedbackManage), h.feedbackHandler.ActivateQuestionnaire)
+			admin.GET("/learnings/at-risk", middleware.RequireScopedPermission(domain.PermAnalyticsView), h.checkInHandler.GetAtRisk)
+			admin.GET("/jobs", middleware.RequirePermission(domain.PermJobsManage), h.jobHandler.ListJobs)
+			admin.GET("/jobs/:id", middleware.RequirePermission(domain.PermJobsManage), h.jobHandler.GetJob)
+			admin.POST("/jobs/:id/retry", middleware.RequirePermission(domain.PermJobsManage), h.jobHandler.RetryJob)
+			admin.POST("/jobs/:id/cancel", middleware.RequirePermission(domain.PermJobsManage), h.jobHandler.CancelJob)
+			admin.GET("/webhooks", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.ListSubscriptions)
+			admin.POST("/webhooks", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.CreateSubscription)
+			admin.GET("/webhooks/:id", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.GetSubscription)
+			admin.PUT("/webhooks/:id", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.UpdateSubscription)
+			admin.DELETE("/webhooks/:id", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.DeleteSubscription)
+			admin.GET("/webhook-deliveries", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.ListDeliveries)
+			admin.GET("/webhook-deliveries/:id", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.GetDelivery)
+			admin.POST("/webhook-deliveries/:id/redeliver", middleware.RequirePermission(domain.PermWebhooksManage), h.webhookHandler.Redeliver)
+			admin.POST("/users/import", middleware.RequirePermission(domain.PermUsersManage), h.userImportHandler.ImportUsers)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(domain.PermRolesManage), h.roleHandler.SetUserRole)
+			admin.GET("/permissions", middleware.RequirePermission(domain.PermRolesManage), h.roleHandler.ListPermissions)
+			admin.GET("/roles", middleware.RequirePermission(domain.PermRolesManage), h.roleHandler.ListRoles)
+			admin.POST("/roles", middleware.RequirePermission(domain.PermRolesManage), h.roleHandler.CreateRole)
+			admin.GET("/roles/:name", middleware.RequirePermission(domain.PermRolesManage), h.roleHandler.GetRole)
+			admin.PUT("/roles/:name", middleware.RequirePermission(domain.PermRolesManage), h.roleHandler.UpdateRole)
+			admin.DELETE("/roles/:name", middleware.RequirePermission(domain.PermRolesManage), h.roleHandler.DeleteRole)
+			admin.GET("/settings", middleware.RequirePermission(domain.PermSettingsManage), h.tenantHandler.GetSettings)
+			admin.PUT("/settings", middleware.RequirePermission(domain.PermSettingsManage), h.tenantHandler.UpdateSettings)
+			admin.POST("/settings/scim-token", middleware.RequirePermission(domain.PermSettingsManage), h.tenantHandler.IssueSCIMToken)
+			admin.DELETE("/settings/scim-token", middleware.RequirePermission(domain.PermSettingsManage), h.tenantHandler.RevokeSCIMToken)
+		}
+
+		// Notifications /api/notifications
+		notifications := api.Group("/notifications")
+		notifications.Use(middleware.AuthMiddleware(jwtSecret, h.authService))
+		{
+			notifications.GET("", h.notificationHandler.GetMyNotifications)
+			notifications.POST("/:id/read", h.notificationHandler.MarkRead)
 		}
 	}
 }
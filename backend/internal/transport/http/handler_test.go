@@ -0,0 +1,535 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+// protectedRoutes are the endpoints handler.go registers behind authentication
+var protectedRoutes = map[string]route{
+	"get me":            {http.MethodGet, fixed("/api/auth/me"), nil},
+	"update me":         {http.MethodPut, fixed("/api/auth/me"), map[string]string{"name": "New"}},
+	"list users":        {http.MethodGet, fixed("/api/users"), nil},
+	"get user":          {http.MethodGet, aliceUser(""), nil},
+	"update user":       {http.MethodPut, aliceUser(""), map[string]string{"name": "New"}},
+	"user requests":     {http.MethodGet, aliceUser("/requests"), nil},
+	"user learnings":    {http.MethodGet, aliceUser("/learnings"), nil},
+	"list requests":     {http.MethodGet, fixed("/api/requests"), nil},
+	"create request":    {http.MethodPost, fixed("/api/requests"), requestBody},
+	"my requests":       {http.MethodGet, fixed("/api/requests/my"), nil},
+	"get request":       {http.MethodGet, aliceRequest(""), nil},
+	"update request":    {http.MethodPut, aliceRequest(""), requestBody},
+	"assign request":    {http.MethodPost, aliceRequest("/assign"), map[string]string{"mentorId": "x"}},
+	"list mentors":      {http.MethodGet, fixed("/api/mentors"), nil},
+	"create mentor":     {http.MethodPost, fixed("/api/mentors"), mentorBody},
+	"get mentor":        {http.MethodGet, annMentor, nil},
+	"update mentor":     {http.MethodPut, annMentor, mentorBody},
+	"my learnings":      {http.MethodGet, fixed("/api/learnings"), nil},
+	"create learning":   {http.MethodPost, fixed("/api/learnings"), requestBody},
+	"get learning":      {http.MethodGet, aliceLearning(""), nil},
+	"update learning":   {http.MethodPut, aliceLearning(""), learningBody},
+	"update plan":       {http.MethodPut, aliceLearning("/plan"), planBody},
+	"update notes":      {http.MethodPut, aliceLearning("/notes"), notesBody},
+	"complete learning": {http.MethodPost, aliceLearning("/complete"), completeBody},
+	"assign learning":   {http.MethodPost, aliceLearning("/assign"), map[string]string{"mentorId": "x"}},
+	"deactivate user":   {http.MethodPost, aliceUser("/deactivate"), nil},
+	"reactivate user":   {http.MethodPost, aliceUser("/reactivate"), nil},
+	"deactivate mentor": {http.MethodPost, func(f *fixture) string { return annMentor(f) + "/deactivate" }, nil},
+	"reactivate mentor": {http.MethodPost, func(f *fixture) string { return annMentor(f) + "/reactivate" }, nil},
+	"plan handoff":      {http.MethodGet, func(f *fixture) string { return annMentor(f) + "/handoff" }, nil},
+	"execute handoff":   {http.MethodPost, func(f *fixture) string { return annMentor(f) + "/handoff" }, nil},
+	"my notifications":  {http.MethodGet, fixed("/api/notifications"), nil},
+	"read notification": {http.MethodPost, fixed("/api/notifications/" + apitest.MissingID() + "/read"), nil},
+	"get availability":  {http.MethodGet, func(f *fixture) string { return annMentor(f) + "/availability" }, nil},
+	"free slots":        {http.MethodGet, func(f *fixture) string { return annMentor(f) + "/availability/slots" }, nil},
+	"add window":        {http.MethodPost, func(f *fixture) string { return annMentor(f) + "/availability/windows" }, windowBody},
+	"update window":     {http.MethodPut, func(f *fixture) string { return annMentor(f) + "/availability/windows/" + apitest.MissingID() }, windowBody},
+	"delete window":     {http.MethodDelete, func(f *fixture) string { return annMentor(f) + "/availability/windows/" + apitest.MissingID() }, nil},
+	"add absence":       {http.MethodPost, func(f *fixture) string { return annMentor(f) + "/availability/absences" }, absenceBody},
+	"update absence":    {http.MethodPut, func(f *fixture) string { return annMentor(f) + "/availability/absences/" + apitest.MissingID() }, absenceBody},
+	"delete absence":    {http.MethodDelete, func(f *fixture) string { return annMentor(f) + "/availability/absences/" + apitest.MissingID() }, nil},
+	"request queue":     {http.MethodGet, fixed("/api/requests/queue"), nil},
+	"dispatch queue":    {http.MethodPost, fixed("/api/requests/queue/dispatch"), nil},
+	"enqueue request":   {http.MethodPost, aliceRequest("/queue"), map[string]int{"priority": 1}},
+	"dequeue request":   {http.MethodDelete, aliceRequest("/queue"), nil},
+	"set manager":       {http.MethodPut, aliceUser("/manager"), map[string]any{"managerId": nil}},
+	"get bob":           {http.MethodGet, func(f *fixture) string { return "/api/users/" + f.bob.User.ID }, nil},
+	"deactivate bob":    {http.MethodPost, func(f *fixture) string { return "/api/users/" + f.bob.User.ID + "/deactivate" }, nil},
+	"move to sales":     {http.MethodPut, aliceUser(""), map[string]string{"department": "Sales"}},
+	"list departments":  {http.MethodGet, fixed("/api/departments"), nil},
+	"get department":    {http.MethodGet, func(f *fixture) string { return "/api/departments/" + f.backend.ID }, nil},
+	"create department": {http.MethodPost, fixed("/api/departments"), map[string]string{"name": "Legal"}},
+	"update department": {http.MethodPut, func(f *fixture) string { return "/api/departments/" + f.sales.ID }, map[string]string{"name": "Sales & Marketing"}},
+	"delete department": {http.MethodDelete, func(f *fixture) string { return "/api/departments/" + f.sales.ID }, nil},
+	"team requests":     {http.MethodGet, fixed("/api/requests/team"), nil},
+	"request approvals": {http.MethodGet, aliceRequest("/approvals"), nil},
+	"decide request":    {http.MethodPost, aliceRequest("/decision"), map[string]string{"decision": "approved"}},
+	"request comments":  {http.MethodGet, aliceRequest("/comments"), nil},
+	"comment request":   {http.MethodPost, aliceRequest("/comments"), commentBody},
+	"learning comments": {http.MethodGet, aliceLearning("/comments"), nil},
+	"comment learning":  {http.MethodPost, aliceLearning("/comments"), commentBody},
+	"edit comment":      {http.MethodPut, fixed("/api/comments/" + apitest.MissingID()), commentBody},
+	"delete comment":    {http.MethodDelete, fixed("/api/comments/" + apitest.MissingID()), nil},
+
+	"learning attachments": {http.MethodGet, aliceLearning("/attachments"), nil},
+	"attach to learning":   {http.MethodPost, aliceLearning("/attachments"), nil},
+	"comment attachments":  {http.MethodGet, fixed("/api/comments/" + apitest.MissingID() + "/attachments"), nil},
+	"attach to comment":    {http.MethodPost, fixed("/api/comments/" + apitest.MissingID() + "/attachments"), nil},
+	"download attachment":  {http.MethodGet, fixed("/api/attachments/" + apitest.MissingID()), nil},
+	"delete attachment":    {http.MethodDelete, fixed("/api/attachments/" + apitest.MissingID()), nil},
+	"learning certificate": {http.MethodGet, aliceLearning("/certificate"), nil},
+
+	"list courses":        {http.MethodGet, fixed("/api/courses"), nil},
+	"create course":       {http.MethodPost, fixed("/api/courses"), courseBody},
+	"get course":          {http.MethodGet, fixed("/api/courses/" + apitest.MissingID()), nil},
+	"update course":       {http.MethodPut, fixed("/api/courses/" + apitest.MissingID()), courseBody},
+	"delete course":       {http.MethodDelete, fixed("/api/courses/" + apitest.MissingID()), nil},
+	"enroll":              {http.MethodPost, fixed("/api/courses/" + apitest.MissingID() + "/enroll"), nil},
+	"course enrollments":  {http.MethodGet, fixed("/api/courses/" + apitest.MissingID() + "/enrollments"), nil},
+	"my enrollments":      {http.MethodGet, fixed("/api/enrollments/my"), nil},
+	"complete enrollment": {http.MethodPost, fixed("/api/enrollments/" + apitest.MissingID() + "/complete"), completeBody},
+	"cancel enrollment":   {http.MethodPost, fixed("/api/enrollments/" + apitest.MissingID() + "/cancel"), nil},
+
+	"list skills":   {http.MethodGet, fixed("/api/skills"), nil},
+	"create skill":  {http.MethodPost, fixed("/api/skills"), skillBody},
+	"update skill":  {http.MethodPut, fixed("/api/skills/" + apitest.MissingID()), skillBody},
+	"delete skill":  {http.MethodDelete, fixed("/api/skills/" + apitest.MissingID()), nil},
+	"user skills":   {http.MethodGet, aliceUser("/skills"), nil},
+	"assess skill":  {http.MethodPut, aliceUser("/skills/" + apitest.MissingID()), assessBody},
+	"skill gaps":    {http.MethodGet, fixed("/api/admin/skill-gaps?department=Backend"), nil},
+	"skill targets": {http.MethodGet, fixed("/api/admin/skill-targets"), nil},
+	"create target": {http.MethodPost, fixed("/api/admin/skill-targets"), targetBody},
+	"delete target": {http.MethodDelete, fixed("/api/admin/skill-targets/" + apitest.MissingID()), nil},
+
+	"learning feedback":      {http.MethodGet, aliceLearning("/feedback"), nil},
+	"give feedback":          {http.MethodPost, aliceLearning("/feedback"), feedbackBody},
+	"mentor feedback":        {http.MethodGet, func(f *fixture) string { return annMentor(f) + "/feedback" }, nil},
+	"questionnaires":         {http.MethodGet, fixed("/api/admin/questionnaires"), nil},
+	"create questionnaire":   {http.MethodPost, fixed("/api/admin/questionnaires"), formBody},
+	"update questionnaire":   {http.MethodPut, fixed("/api/admin/questionnaires/" + apitest.MissingID()), formBody},
+	"delete questionnaire":   {http.MethodDelete, fixed("/api/admin/questionnaires/" + apitest.MissingID()), nil},
+	"activate questionnaire": {http.MethodPost, fixed("/api/admin/questionnaires/" + apitest.MissingID() + "/activate"), nil},
+	"at-risk learnings":      {http.MethodGet, fixed("/api/admin/learnings/at-risk"), nil},
+	"learning check-ins":     {http.MethodGet, aliceLearning("/check-ins"), nil},
+	"my check-ins":           {http.MethodGet, fixed("/api/check-ins/my"), nil},
+	"answer check-in":        {http.MethodPost, fixed("/api/check-ins/" + apitest.MissingID() + "/answer"), pulseBody},
+	"list jobs":              {http.MethodGet, fixed("/api/admin/jobs"), nil},
+	"get job":                {http.MethodGet, fixed("/api/admin/jobs/" + apitest.MissingID()), nil},
+	"retry job":              {http.MethodPost, fixed("/api/admin/jobs/" + apitest.MissingID() + "/retry"), nil},
+	"cancel job":             {http.MethodPost, fixed("/api/admin/jobs/" + apitest.MissingID() + "/cancel"), nil},
+	"import users":           {http.MethodPost, fixed("/api/admin/users/import?dryRun=true"), importBody},
+	"list webhooks":          {http.MethodGet, fixed("/api/admin/webhooks"), nil},
+	"create webhook":         {http.MethodPost, fixed("/api/admin/webhooks"), webhookBody},
+	"get webhook":            {http.MethodGet, fixed("/api/admin/webhooks/" + apitest.MissingID()), nil},
+	"update webhook":         {http.MethodPut, fixed("/api/admin/webhooks/" + apitest.MissingID()), webhookBody},
+	"delete webhook":         {http.MethodDelete, fixed("/api/admin/webhooks/" + apitest.MissingID()), nil},
+	"webhook deliveries":     {http.MethodGet, fixed("/api/admin/webhook-deliveries?status=dead"), nil},
+	"get delivery":           {http.MethodGet, fixed("/api/admin/webhook-deliveries/" + apitest.MissingID()), nil},
+	"redeliver":              {http.MethodPost, fixed("/api/admin/webhook-deliveries/" + apitest.MissingID() + "/redeliver"), nil},
+	"list permissions":       {http.MethodGet, fixed("/api/admin/permissions"), nil},
+	"list roles":             {http.MethodGet, fixed("/api/admin/roles"), nil},
+	"create role":            {http.MethodPost, fixed("/api/admin/roles"), roleBody},
+	"get role":               {http.MethodGet, fixed("/api/admin/roles/mentor"), nil},
+	"update role":            {http.MethodPut, fixed("/api/admin/roles/mentor"), roleBody},
+	"delete role":            {http.MethodDelete, fixed("/api/admin/roles/auditor"), nil},
+	"set user role":          {http.MethodPut, func(f *fixture) string { return "/api/admin/users/" + f.alice.User.ID + "/role" }, map[string]string{"role": "mentor"}},
+	"get settings":           {http.MethodGet, fixed("/api/admin/settings"), nil},
+	"update settings":        {http.MethodPut, fixed("/api/admin/settings"), settingsBody},
+	"list tenants":           {http.MethodGet, fixed("/api/super/tenants"), nil},
+	"create tenant":          {http.MethodPost, fixed("/api/super/tenants"), tenantBody},
+	"get tenant":             {http.MethodGet, fixed("/api/super/tenants/" + domain.DefaultTenantID), nil},
+	"update tenant":          {http.MethodPut, fixed("/api/super/tenants/" + domain.DefaultTenantID), map[string]string{"name": "Default"}},
+	"scim list users":        {http.MethodGet, fixed("/scim/v2/Users"), nil},
+	"scim create user":       {http.MethodPost, fixed("/scim/v2/Users"), map[string]string{"userName": "x@example.com"}},
+	"scim delete user":       {http.MethodDelete, fixed("/scim/v2/Users/" + apitest.MissingID()), nil},
+	"scim list groups":       {http.MethodGet, fixed("/scim/v2/Groups"), nil},
+	"scim patch group":       {http.MethodPatch, fixed("/scim/v2/Groups/role-admin"), map[string]any{"Operations": []any{}}},
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	f := newFixture(t)
+
+	for name, r := range protectedRoutes {
+		t.Run(name, func(t *testing.T) {
+			f.srv.Expect(t, http.StatusUnauthorized, r.method, r.path(f), "", r.body)
+		})
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	type persona func(f *fixture) string
+	var (
+		alice   persona = func(f *fixture) string { return f.alice.Token }
+		bob     persona = func(f *fixture) string { return f.bob.Token }
+		admin   persona = func(f *fixture) string { return f.admin.Token }
+		ann     persona = func(f *fixture) string { return f.ann.Token }
+		boss    persona = func(f *fixture) string { return f.boss.Token }
+		legacy  persona = func(f *fixture) string { return apitest.Token(t, f.alice.User.ID, "user", time.Hour) }
+		spoofed persona = func(f *fixture) string { return apitest.Token(t, f.bob.User.ID, "superuser", time.Hour) }
+		lena    persona = func(f *fixture) string { return f.lena.Token }
+		hank    persona = func(f *fixture) string { return f.hank.Token }
+		max     persona = func(f *fixture) string { return f.srv.WithRole(t, "max", domain.RoleMentor).Token }
+	)
+
+	tests := []struct {
+		route string
+		as    persona
+		who   string
+		want  int
+	}{
+		// RequirePermission routes; the role comes from the database, not
+		// the token
+		{"list users", bob, "employee", http.StatusForbidden},
+		{"list users", ann, "mentor", http.StatusForbidden},
+		{"list users", spoofed, "unknown role", http.StatusForbidden},
+		{"list users", admin, "admin", http.StatusOK},
+		{"list requests", alice, "employee", http.StatusForbidden},
+		{"list requests", admin, "admin", http.StatusOK},
+		{"assign request", alice, "owner", http.StatusForbidden},
+		{"create mentor", ann, "mentor", http.StatusForbidden},
+		{"create mentor", admin, "admin", http.StatusCreated},
+		{"update mentor", ann, "mentor updating self", http.StatusForbidden},
+		{"update mentor", admin, "admin", http.StatusOK},
+		{"update learning", alice, "owner", http.StatusForbidden},
+		{"update learning", ann, "mentor", http.StatusForbidden},
+		{"update learning", admin, "admin", http.StatusOK},
+		{"assign learning", alice, "owner", http.StatusForbidden},
+		{"deactivate user", alice, "self", http.StatusForbidden},
+		{"deactivate user", admin, "admin", http.StatusOK},
+		{"reactivate user", bob, "employee", http.StatusForbidden},
+		{"reactivate user", admin, "admin", http.StatusOK},
+		{"deactivate mentor", ann, "mentor", http.StatusForbidden},
+		{"deactivate mentor", admin, "admin with active learnings", http.StatusConflict},
+		{"reactivate mentor", admin, "admin", http.StatusOK},
+		{"plan handoff", ann, "mentor", http.StatusForbidden},
+		{"plan handoff", admin, "admin", http.StatusOK},
+		{"execute handoff", alice, "mentee", http.StatusForbidden},
+		{"execute handoff", admin, "admin without other mentors", http.StatusConflict},
+		{"add window", ann, "mentor", http.StatusForbidden},
+		{"add window", admin, "admin", http.StatusCreated},
+		{"update window", ann, "mentor", http.StatusForbidden},
+		{"update window", admin, "admin", http.StatusNotFound},
+		{"delete window", ann, "mentor", http.StatusForbidden},
+		{"delete window", admin, "admin", http.StatusNotFound},
+		{"add absence", ann, "mentor", http.StatusForbidden},
+		{"add absence", admin, "admin", http.StatusCreated},
+		{"update absence", ann, "mentor", http.StatusForbidden},
+		{"update absence", admin, "admin", http.StatusNotFound},
+		{"delete absence", bob, "employee", http.StatusForbidden},
+		{"delete absence", admin, "admin", http.StatusNotFound},
+		{"request queue", alice, "employee", http.StatusForbidden},
+		{"request queue", admin, "admin", http.StatusOK},
+		{"dispatch queue", ann, "mentor", http.StatusForbidden},
+		{"dispatch queue", admin, "admin", http.StatusOK},
+		{"enqueue request", alice, "owner", http.StatusForbidden},
+		{"enqueue request", admin, "admin on approved request", http.StatusConflict},
+		{"dequeue request", bob, "employee", http.StatusForbidden},
+		{"dequeue request", admin, "admin on request not queued", http.StatusConflict},
+		{"set manager", alice, "self", http.StatusForbidden},
+		{"set manager", boss, "manager", http.StatusForbidden},
+		{"set manager", admin, "admin", http.StatusOK},
+		{"create course", alice, "employee", http.StatusForbidden},
+		{"create course", admin, "admin", http.StatusCreated},
+		{"update course", ann, "mentor", http.StatusForbidden},
+		{"update course", admin, "admin", http.StatusNotFound},
+		{"delete course", bob, "employee", http.StatusForbidden},
+		{"delete course", admin, "admin", http.StatusNotFound},
+		{"course enrollments", alice, "employee", http.StatusForbidden},
+		{"course enrollments", admin, "admin", http.StatusNotFound},
+		{"create skill", alice, "employee", http.StatusForbidden},
+		{"create skill", admin, "admin", http.StatusCreated},
+		{"update skill", ann, "mentor", http.StatusForbidden},
+		{"update skill", admin, "admin", http.StatusNotFound},
+		{"delete skill", bob, "employee", http.StatusForbidden},
+		{"delete skill", admin, "admin", http.StatusNotFound},
+		{"skill gaps", boss, "manager", http.StatusForbidden},
+		{"skill gaps", admin, "admin", http.StatusOK},
+		{"skill targets", bob, "employee", http.StatusForbidden},
+		{"skill targets", admin, "admin", http.StatusOK},
+		{"create target", boss, "manager", http.StatusForbidden},
+		{"create target", admin, "admin for a missing skill", http.StatusNotFound},
+		{"delete target", alice, "employee", http.StatusForbidden},
+		{"delete target", admin, "admin", http.StatusNotFound},
+		{"questionnaires", bob, "employee", http.StatusForbidden},
+		{"questionnaires", admin, "admin", http.StatusOK},
+		{"create questionnaire", alice, "employee", http.StatusForbidden},
+		{"create questionnaire", admin, "admin", http.StatusCreated},
+		{"update questionnaire", ann, "mentor", http.StatusForbidden},
+		{"update questionnaire", admin, "admin", http.StatusNotFound},
+		{"delete questionnaire", boss, "manager", http.StatusForbidden},
+		{"delete questionnaire", admin, "admin", http.StatusNotFound},
+		{"activate questionnaire", bob, "employee", http.StatusForbidden},
+		{"activate questionnaire", admin, "admin", http.StatusNotFound},
+		{"at-risk learnings", ann, "mentor", http.StatusForbidden},
+		{"at-risk learnings", admin, "admin", http.StatusOK},
+		{"list jobs", bob, "employee", http.StatusForbidden},
+		{"list jobs", admin, "admin", http.StatusOK},
+		{"get job", ann, "mentor", http.StatusForbidden},
+		{"get job", admin, "admin", http.StatusNotFound},
+		{"retry job", boss, "manager", http.StatusForbidden},
+		{"retry job", admin, "admin", http.StatusNotFound},
+		{"cancel job", alice, "employee", http.StatusForbidden},
+		{"cancel job", admin, "admin", http.StatusNotFound},
+		{"import users", boss, "manager", http.StatusForbidden},
+		{"import users", ann, "mentor", http.StatusForbidden},
+		{"import users", admin, "admin", http.StatusOK},
+		{"list webhooks", boss, "manager", http.StatusForbidden},
+		{"list webhooks", admin, "admin", http.StatusOK},
+		{"create webhook", alice, "employee", http.StatusForbidden},
+		{"create webhook", admin, "admin", http.StatusCreated},
+		{"get webhook", ann, "mentor", http.StatusForbidden},
+		{"get webhook", admin, "admin", http.StatusNotFound},
+		{"update webhook", bob, "employee", http.StatusForbidden},
+		{"update webhook", admin, "admin", http.StatusNotFound},
+		{"delete webhook", boss, "manager", http.StatusForbidden},
+		{"delete webhook", admin, "admin", http.StatusNotFound},
+		{"webhook deliveries", alice, "employee", http.StatusForbidden},
+		{"webhook deliveries", admin, "admin", http.StatusOK},
+		{"get delivery", ann, "mentor", http.StatusForbidden},
+		{"get delivery", admin, "admin", http.StatusNotFound},
+		{"redeliver", boss, "manager", http.StatusForbidden},
+		{"redeliver", admin, "admin", http.StatusNotFound},
+		{"list permissions", lena, "L&D manager", http.StatusForbidden},
+		{"list permissions", admin, "admin", http.StatusOK},
+		{"list roles", hank, "department head", http.StatusForbidden},
+		{"list roles", admin, "admin", http.StatusOK},
+		{"create role", lena, "L&D manager", http.StatusForbidden},
+		{"create role", admin, "admin", http.StatusCreated},
+		{"get role", alice, "employee", http.StatusForbidden},
+		{"get role", admin, "admin", http.StatusOK},
+		{"update role", lena, "L&D manager", http.StatusForbidden},
+		{"update role", admin, "admin", http.StatusOK},
+		{"delete role", boss, "manager", http.StatusForbidden},
+		{"delete role", admin, "admin", http.StatusNotFound},
+		{"set user role", lena, "L&D manager", http.StatusForbidden},
+		{"set user role", alice, "self", http.StatusForbidden},
+		{"set user role", admin, "admin", http.StatusOK},
+		{"get settings", lena, "L&D manager", http.StatusForbidden},
+		{"get settings", admin, "admin", http.StatusOK},
+		{"update settings", hank, "department head", http.StatusForbidden},
+		{"update settings", admin, "admin", http.StatusOK},
+
+		// Tenants are managed with the super admin token, never a user's
+		{"list tenants", admin, "admin", http.StatusUnauthorized},
+		{"create tenant", admin, "admin", http.StatusUnauthorized},
+		{"get tenant", admin, "admin", http.StatusUnauthorized},
+		{"update tenant", admin, "admin", http.StatusUnauthorized},
+
+		// Built-in roles between employee and admin
+		{"list users", lena, "L&D manager", http.StatusOK},
+		{"list users", hank, "department head", http.StatusOK},
+		{"deactivate user", lena, "L&D manager", http.StatusForbidden},
+		{"list requests", lena, "L&D manager", http.StatusOK},
+		{"list requests", hank, "department head", http.StatusOK},
+		{"assign request", hank, "department head", http.StatusForbidden},
+		{"request queue", lena, "L&D manager", http.StatusOK},
+		{"get request", hank, "department head", http.StatusOK},
+		{"update request", hank, "department head", http.StatusForbidden},
+		{"update request", lena, "L&D manager", http.StatusOK},
+		{"get learning", hank, "department head", http.StatusOK},
+		{"update plan", hank, "department head", http.StatusForbidden},
+		{"update plan", lena, "L&D manager", http.StatusOK},
+		{"create mentor", lena, "L&D manager", http.StatusCreated},
+		{"create mentor", hank, "department head", http.StatusForbidden},
+		{"create course", lena, "L&D manager", http.StatusCreated},
+		{"create course", hank, "department head", http.StatusForbidden},
+		{"skill gaps", hank, "department head", http.StatusOK},
+		{"at-risk learnings", hank, "department head", http.StatusOK},
+		{"create target", hank, "department head", http.StatusForbidden},
+		{"questionnaires", lena, "L&D manager", http.StatusOK},
+		{"list jobs", lena, "L&D manager", http.StatusForbidden},
+		{"list webhooks", lena, "L&D manager", http.StatusForbidden},
+		{"import users", lena, "L&D manager", http.StatusForbidden},
+		{"learning feedback", lena, "L&D manager", http.StatusOK},
+		{"learning feedback", hank, "department head", http.StatusForbidden},
+
+		// A department head reaches the members of their departments only
+		{"get user", hank, "head of the user's department", http.StatusOK},
+		{"get bob", hank, "department head elsewhere", http.StatusForbidden},
+		{"get bob", lena, "L&D manager", http.StatusOK},
+		{"update user", hank, "head of the user's department", http.StatusOK},
+		{"move to sales", hank, "department head", http.StatusForbidden},
+		{"move to sales", admin, "admin", http.StatusOK},
+		{"deactivate user", hank, "head of the user's department", http.StatusOK},
+		{"deactivate bob", hank, "department head elsewhere", http.StatusForbidden},
+		{"set manager", hank, "head of the user's department", http.StatusOK},
+		{"user learnings", hank, "head of the user's department", http.StatusOK},
+		{"list departments", alice, "employee", http.StatusOK},
+		{"get department", bob, "employee", http.StatusOK},
+		{"create department", hank, "department head", http.StatusForbidden},
+		{"create department", lena, "L&D manager", http.StatusForbidden},
+		{"create department", admin, "admin", http.StatusCreated},
+		{"update department", hank, "department head", http.StatusForbidden},
+		{"update department", admin, "admin", http.StatusOK},
+		{"delete department", admin, "admin with members left", http.StatusConflict},
+
+		// OwnerOrPermission compares the token's user ID with :id
+		{"get user", alice, "owner", http.StatusOK},
+		{"get user", legacy, "owner with user role", http.StatusOK},
+		{"get user", bob, "other employee", http.StatusForbidden},
+		{"get user", admin, "admin", http.StatusOK},
+		{"update user", bob, "other employee", http.StatusForbidden},
+		{"update user", alice, "owner", http.StatusOK},
+		{"user requests", bob, "other employee", http.StatusForbidden},
+		{"user requests", admin, "admin", http.StatusOK},
+		{"user learnings", ann, "mentor", http.StatusForbidden},
+		{"user learnings", alice, "owner", http.StatusOK},
+
+		// Ownership checked inside handlers
+		{"get request", bob, "other employee", http.StatusForbidden},
+		{"get request", alice, "owner", http.StatusOK},
+		{"get request", admin, "admin", http.StatusOK},
+		{"get request", boss, "manager", http.StatusOK},
+		{"request approvals", bob, "other employee", http.StatusForbidden},
+		{"request approvals", alice, "owner", http.StatusOK},
+		{"request approvals", boss, "manager", http.StatusOK},
+		{"request approvals", admin, "admin", http.StatusOK},
+		{"update request", boss, "manager", http.StatusForbidden},
+		{"update request", bob, "other employee", http.StatusForbidden},
+		{"update request", alice, "owner", http.StatusOK},
+		{"get learning", bob, "other employee", http.StatusForbidden},
+		{"get learning", alice, "owner", http.StatusOK},
+		{"get learning", admin, "admin", http.StatusOK},
+		{"update plan", bob, "other employee", http.StatusForbidden},
+		{"update plan", alice, "owner", http.StatusOK},
+		{"update notes", bob, "other employee", http.StatusForbidden},
+		{"update notes", admin, "admin", http.StatusOK},
+		{"complete learning", bob, "other employee", http.StatusForbidden},
+		{"complete learning", alice, "owner", http.StatusOK},
+
+		// Comment threads are checked by the service: requester, manager and
+		// admins on requests; learner, mentor and admins on learnings
+		{"request comments", alice, "owner", http.StatusOK},
+		{"request comments", boss, "manager", http.StatusOK},
+		{"request comments", admin, "admin", http.StatusOK},
+		{"request comments", bob, "other employee", http.StatusForbidden},
+		{"request comments", ann, "mentor", http.StatusForbidden},
+		{"comment request", boss, "manager", http.StatusCreated},
+		{"comment request", bob, "other employee", http.StatusForbidden},
+		{"learning comments", alice, "owner", http.StatusOK},
+		{"learning comments", ann, "mentor", http.StatusOK},
+		{"learning comments", boss, "manager", http.StatusForbidden},
+		{"comment learning", ann, "mentor", http.StatusCreated},
+		{"comment learning", bob, "other employee", http.StatusForbidden},
+		{"comment learning", admin, "admin", http.StatusCreated},
+		{"edit comment", bob, "employee", http.StatusNotFound},
+		{"delete comment", admin, "admin", http.StatusNotFound},
+
+		// Attachments follow the learning thread and the comment's thread
+		{"learning attachments", alice, "owner", http.StatusOK},
+		{"learning attachments", ann, "mentor", http.StatusOK},
+		{"learning attachments", admin, "admin", http.StatusOK},
+		{"learning attachments", boss, "manager", http.StatusForbidden},
+		{"learning attachments", bob, "other employee", http.StatusForbidden},
+		{"attach to learning", alice, "owner without a file", http.StatusBadRequest},
+		{"comment attachments", alice, "employee", http.StatusNotFound},
+		{"download attachment", bob, "employee", http.StatusNotFound},
+		{"delete attachment", admin, "admin", http.StatusNotFound},
+
+		// Certificates go to the learner and admins once the learning is
+		// completed
+		{"learning certificate", alice, "owner of an active learning", http.StatusConflict},
+		{"learning certificate", admin, "admin", http.StatusConflict},
+		{"learning certificate", ann, "mentor", http.StatusForbidden},
+		{"learning certificate", bob, "other employee", http.StatusForbidden},
+
+		// Feedback on a learning is read by its participants and admins and
+		// given by the learner and the mentor once it is completed; a
+		// mentor's aggregate is for the mentor and admins
+		{"learning feedback", alice, "owner", http.StatusOK},
+		{"learning feedback", ann, "mentor", http.StatusOK},
+		{"learning feedback", admin, "admin", http.StatusOK},
+		{"learning feedback", boss, "manager", http.StatusForbidden},
+		{"learning feedback", bob, "other employee", http.StatusForbidden},
+		{"give feedback", alice, "owner of an active learning", http.StatusConflict},
+		{"give feedback", ann, "mentor of an active learning", http.StatusConflict},
+		{"give feedback", admin, "admin", http.StatusForbidden},
+		{"give feedback", bob, "other employee", http.StatusForbidden},
+		{"mentor feedback", ann, "mentor", http.StatusOK},
+		{"mentor feedback", admin, "admin", http.StatusOK},
+		{"mentor feedback", alice, "mentee", http.StatusForbidden},
+		{"learning check-ins", alice, "owner", http.StatusOK},
+		{"learning check-ins", ann, "mentor", http.StatusOK},
+		{"learning check-ins", admin, "admin", http.StatusOK},
+		{"learning check-ins", boss, "manager", http.StatusForbidden},
+		{"learning check-ins", bob, "other employee", http.StatusForbidden},
+
+		// Mentors are matched to their account by email and curate the
+		// learnings they mentor; the mentor role alone reaches no others
+		{"get learning", ann, "mentor", http.StatusOK},
+		{"update plan", ann, "mentor", http.StatusOK},
+		{"update notes", ann, "mentor", http.StatusOK},
+		{"complete learning", ann, "mentor", http.StatusForbidden},
+		{"get learning", max, "someone else's mentor", http.StatusForbidden},
+		{"update plan", max, "someone else's mentor", http.StatusForbidden},
+		{"update notes", max, "someone else's mentor", http.StatusForbidden},
+
+		// Open to every authenticated user
+		{"list mentors", bob, "employee", http.StatusOK},
+		{"get mentor", bob, "employee", http.StatusOK},
+		{"my requests", ann, "mentor", http.StatusOK},
+		{"my learnings", ann, "mentor", http.StatusOK},
+		{"get me", legacy, "user role", http.StatusOK},
+		{"my notifications", ann, "mentor", http.StatusOK},
+		{"read notification", bob, "employee", http.StatusNotFound},
+		{"get availability", bob, "employee", http.StatusOK},
+		{"free slots", alice, "mentee", http.StatusOK},
+		{"team requests", ann, "mentor without reports", http.StatusOK},
+		{"team requests", boss, "manager", http.StatusOK},
+		{"list courses", bob, "employee", http.StatusOK},
+		{"get course", ann, "mentor", http.StatusNotFound},
+		{"enroll", alice, "employee", http.StatusNotFound},
+		{"my enrollments", ann, "mentor", http.StatusOK},
+		{"list skills", ann, "mentor", http.StatusOK},
+		{"my check-ins", bob, "employee", http.StatusOK},
+		{"answer check-in", alice, "employee", http.StatusNotFound},
+
+		// Enrollments are completed and cancelled by their holder or an admin
+		{"complete enrollment", bob, "employee", http.StatusNotFound},
+		{"cancel enrollment", admin, "admin", http.StatusNotFound},
+
+		// Skill levels are seen and assessed by the user, their manager and
+		// admins
+		{"user skills", alice, "owner", http.StatusOK},
+		{"user skills", boss, "manager", http.StatusOK},
+		{"user skills", admin, "admin", http.StatusOK},
+		{"user skills", bob, "other employee", http.StatusForbidden},
+		{"user skills", ann, "mentor", http.StatusForbidden},
+		{"assess skill", alice, "owner for a missing skill", http.StatusNotFound},
+		{"assess skill", boss, "manager for a missing skill", http.StatusNotFound},
+		{"assess skill", bob, "other employee", http.StatusForbidden},
+
+		// Deciders are checked by the service once the request awaits one
+		{"decide request", boss, "manager on approved request", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route+" as "+tt.who, func(t *testing.T) {
+			r, ok := protectedRoutes[tt.route]
+			if !ok {
+				t.Fatalf("unknown route %q", tt.route)
+			}
+
+			f := newFixture(t)
+			f.srv.Expect(t, tt.want, r.method, r.path(f), tt.as(f), r.body)
+		})
+	}
+}
+
+func TestNotFoundBeforeOwnership(t *testing.T) {
+	f := newFixture(t)
+	missing := apitest.MissingID()
+
+	f.srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/requests/"+missing, f.bob.Token, nil)
+	f.srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/learnings/"+missing, f.bob.Token, nil)
+	f.srv.Expect(t, http.StatusNotFound, http.MethodPut, "/api/learnings/"+missing+"/notes", f.bob.Token, notesBody)
+	f.srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/learnings/"+missing+"/certificate", f.bob.Token, nil)
+	f.srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/learnings/"+missing+"/feedback", f.bob.Token, nil)
+	f.srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/mentors/"+missing+"/feedback", f.bob.Token, nil)
+	f.srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/learnings/"+missing+"/check-ins", f.bob.Token, nil)
+	f.srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/users/"+missing, f.admin.Token, nil)
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/health"
+)
+
+type HealthHandler struct {
+	monitor *health.Monitor
+}
+
+func NewHealthHandler(monitor *health.Monitor) *HealthHandler {
+	return &HealthHandler{
+		monitor: monitor,
+	}
+}
+
+// Health handles GET /health (kept for backward compatibility)
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"service": "internal-training-system",
+	})
+}
+
+// Live handles GET /health/live: the process is up and serving HTTP
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+// Ready handles GET /health/ready: all dependencies are reachable and
+// the server is not draining
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.monitor.Readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
@@ -0,0 +1,16 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	srv := apitest.New(t)
+
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/health", "", nil)
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/health/live", "", nil)
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/health/ready", "", nil)
+}
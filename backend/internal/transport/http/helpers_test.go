@@ -0,0 +1,140 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+// fixture is a learning of alice mentored by ann, alice's manager boss,
+// plus bystanders, among them an L&D manager and hank, the head of alice's
+// department Backend; bob works in Sales
+type fixture struct {
+	srv      *apitest.Server
+	alice    *apitest.Persona
+	boss     *apitest.Persona
+	bob      *apitest.Persona
+	admin    *apitest.Persona
+	ann      *apitest.Persona
+	lena     *apitest.Persona
+	hank     *apitest.Persona
+	request  *domain.TrainingRequest
+	learning *domain.LearningProcess
+	backend  *domain.Department
+	sales    *domain.Department
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := defaultCtx()
+
+	srv := apitest.New(t)
+	f := &fixture{
+		srv:   srv,
+		alice: srv.Employee(t, "alice"),
+		boss:  srv.Employee(t, "boss"),
+		bob:   srv.Employee(t, "bob"),
+		admin: srv.Admin(t, "root"),
+		ann:   srv.Mentor(t, "ann", 0),
+		lena:  srv.WithRole(t, "lena", domain.RoleLDManager),
+		hank:  srv.WithRole(t, "hank", domain.RoleDepartmentHead),
+	}
+
+	if err := srv.Users.UpdateManager(ctx, f.alice.User.ID, &f.boss.User.ID); err != nil {
+		t.Fatalf("set manager: %v", err)
+	}
+	f.backend = srv.Department(t, "Backend", f.hank.User, f.alice.User)
+	f.sales = srv.Department(t, "Sales", nil, f.bob.User)
+
+	f.request = &domain.TrainingRequest{UserID: f.alice.User.ID, Topic: "Go", Description: "Generics", Status: domain.RequestApproved}
+	if err := srv.Requests.Create(ctx, f.request); err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	f.learning = &domain.LearningProcess{
+		RequestID: f.request.ID,
+		UserID:    f.alice.User.ID,
+		MentorID:  f.ann.Mentor.ID,
+		Status:    domain.LearningActive,
+		StartDate: time.Now(),
+		Plan:      []domain.LearningPlanItem{},
+	}
+	if err := srv.Learnings.Create(ctx, f.learning); err != nil {
+		t.Fatalf("create learning: %v", err)
+	}
+	if err := srv.Mentors.UpdateWorkload(ctx, f.ann.Mentor.ID, 1); err != nil {
+		t.Fatalf("update workload: %v", err)
+	}
+	return f
+}
+
+// route is a protected endpoint with a body that passes validation
+type route struct {
+	method string
+	path   func(f *fixture) string
+	body   any
+}
+
+var (
+	requestBody  = map[string]string{"topic": "Rust", "description": "Ownership"}
+	planBody     = map[string]any{"plan": []map[string]any{{"id": "1", "text": "Read the book"}}}
+	notesBody    = map[string]string{"notes": "note"}
+	completeBody = map[string]any{"rating": 5, "comment": "Thanks"}
+	mentorBody   = map[string]any{"name": "Max", "jobTitle": "Lead", "experience": "5 years", "email": "max@example.com", "workload": 1}
+	learningBody = map[string]any{"topic": "Go", "description": "Generics", "status": "active", "plan": []map[string]any{}}
+	windowBody   = map[string]any{"weekday": 1, "startTime": "09:00", "endTime": "12:00"}
+	absenceBody  = map[string]any{"kind": "vacation", "startsAt": "2030-07-01T00:00:00Z", "endsAt": "2030-07-15T00:00:00Z"}
+	commentBody  = map[string]string{"body": "Hello"}
+	courseBody   = map[string]any{"title": "Kubernetes Basics", "format": "online", "durationHours": 8}
+	skillBody    = map[string]string{"name": "Go"}
+	targetBody   = map[string]any{"skillId": apitest.MissingID(), "department": "Backend", "level": 3}
+	assessBody   = map[string]int{"level": 3}
+	feedbackBody = map[string]any{"answers": map[string]int{"clarity": 5}}
+	pulseBody    = map[string]string{"pulse": "on_track"}
+	importBody   = []map[string]any{{"name": "Imported", "email": "imported@example.com"}}
+	webhookBody  = map[string]any{"url": "https://hris.example.com/hooks", "eventTypes": []string{"learning.completed"}}
+	roleBody     = map[string]any{"name": "auditor", "permissions": []string{"users.view", "analytics.view"}}
+	formBody     = map[string]any{"name": "Form", "audience": "learner", "criteria": []map[string]any{{"key": "clarity", "label": "Clarity", "min": 1, "max": 5}}}
+	settingsBody = map[string]any{"mentorCapacity": 4, "branding": map[string]string{"primaryColor": "#112233"}}
+	tenantBody   = map[string]any{"slug": "acme", "name": "Acme", "admin": map[string]string{"name": "Jane", "email": "jane@acme.example", "password": "s3cret-pass"}}
+)
+
+func fixed(path string) func(*fixture) string {
+	return func(*fixture) string { return path }
+}
+
+func aliceUser(suffix string) func(*fixture) string {
+	return func(f *fixture) string { return "/api/users/" + f.alice.User.ID + suffix }
+}
+
+func aliceRequest(suffix string) func(*fixture) string {
+	return func(f *fixture) string { return "/api/requests/" + f.request.ID + suffix }
+}
+
+func aliceLearning(suffix string) func(*fixture) string {
+	return func(f *fixture) string { return "/api/learnings/" + f.learning.ID + suffix }
+}
+
+func annMentor(f *fixture) string {
+	return "/api/mentors/" + f.ann.Mentor.ID
+}
+
+func assertWorkload(t *testing.T, srv *apitest.Server, admin *apitest.Persona, mentorID string, want int) {
+	t.Helper()
+
+	var mentor struct {
+		Workload int `json:"workload"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors/"+mentorID, admin.Token, nil).Decode(t, &mentor)
+	if mentor.Workload != want {
+		t.Errorf("mentor workload = %d, want %d", mentor.Workload, want)
+	}
+}
+
+// defaultCtx is a context in the default tenant, where the personas live
+func defaultCtx() context.Context {
+	return domain.WithTenant(context.Background(), domain.DefaultTenantID)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// ListJobs handles GET /api/admin/jobs?status=&kind=&limit= (jobs.manage)
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	filter := domain.JobFilter{
+		Status: domain.JobStatus(c.Query("status")),
+		Kind:   c.Query("kind"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	jobs, err := h.jobService.ListJobs(c.Request.Context(), filter)
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// GetJob handles GET /api/admin/jobs/:id (jobs.manage)
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobService.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryJob handles POST /api/admin/jobs/:id/retry (jobs.manage)
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	job, err := h.jobService.RetryJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelJob handles POST /api/admin/jobs/:id/cancel (jobs.manage)
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	job, err := h.jobService.CancelJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// respondJobError maps job errors to status codes
+func respondJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrJobNotRetryable),
+		errors.Is(err, domain.ErrJobNotCancellable),
+		errors.Is(err, domain.ErrJobExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,57 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestBackgroundJobs(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	ctx := defaultCtx()
+
+	scheduled, err := srv.JobService.Enqueue(ctx, &domain.Job{Kind: service.JobCheckIns, RunAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	due, err := srv.JobService.Enqueue(ctx, &domain.Job{Kind: service.JobCheckIns})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := srv.JobService.RunDue(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("run due jobs: %v", err)
+	}
+
+	var list struct {
+		Jobs []domain.Job `json:"jobs"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/jobs?status=succeeded", admin.Token, nil).Decode(t, &list)
+	if len(list.Jobs) != 1 || list.Jobs[0].ID != due.ID || list.Jobs[0].Attempts != 1 {
+		t.Fatalf("succeeded jobs = %+v", list.Jobs)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, "/api/admin/jobs?status=done", admin.Token, nil)
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, "/api/admin/jobs?limit=0", admin.Token, nil)
+
+	// Pending jobs can be cancelled, and cancelled ones brought back
+	jobPath := "/api/admin/jobs/" + scheduled.ID
+	srv.Expect(t, http.StatusConflict, http.MethodPost, jobPath+"/retry", admin.Token, nil)
+	var job domain.Job
+	srv.Expect(t, http.StatusOK, http.MethodPost, jobPath+"/cancel", admin.Token, nil).Decode(t, &job)
+	if job.Status != domain.JobCancelled {
+		t.Errorf("cancelled job = %+v", job)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, jobPath+"/cancel", admin.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPost, jobPath+"/retry", admin.Token, nil).Decode(t, &job)
+	if job.Status != domain.JobPending || job.Attempts != 0 {
+		t.Errorf("retried job = %+v", job)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, jobPath, admin.Token, nil).Decode(t, &job)
+	if job.ID != scheduled.ID || job.Kind != service.JobCheckIns {
+		t.Errorf("job = %+v", job)
+	}
+}
@@ -7,15 +7,18 @@ import (
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/middleware"
 )
 
 type LearningHandler struct {
-	learningService *service.LearningService
+	learningService   *service.LearningService
+	departmentService *service.DepartmentService
 }
 
-func NewLearningHandler(learningService *service.LearningService) *LearningHandler {
+func NewLearningHandler(learningService *service.LearningService, departmentService *service.DepartmentService) *LearningHandler {
 	return &LearningHandler{
-		learningService: learningService,
+		learningService:   learningService,
+		departmentService: departmentService,
 	}
 }
 
@@ -48,7 +51,6 @@ func (h *LearningHandler) GetMyLearnings(c *gin.Context) {
 func (h *LearningHandler) GetLearningByID(c *gin.Context) {
 	learningID := c.Param("id")
 	userID, _ := c.Get("userID")
-	role, _ := c.Get("role")
 
 	learning, err := h.learningService.GetLearningByID(c.Request.Context(), learningID)
 	if err != nil {
@@ -56,10 +58,20 @@ func (h *LearningHandler) GetLearningByID(c *gin.Context) {
 		return
 	}
 
-	// Access control: owner or admin
-	if learning.UserID != userID.(string) && role.(string) != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
-		return
+	// Access control: owner, their mentor or learnings.view over the owner
+	if learning.UserID != userID.(string) {
+		ok, err := middleware.Reaches(c, h.departmentService, domain.PermLearningsView, learning.UserID)
+		if err == nil && !ok {
+			ok, err = h.learningService.IsMentorOf(c.Request.Context(), learning, userID.(string))
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
 	}
 
 	// Convert to response DTO
@@ -67,7 +79,8 @@ func (h *LearningHandler) GetLearningByID(c *gin.Context) {
 	c.JSON(http.StatusOK, responseDTO)
 }
 
-// CreateLearning handles POST /api/learnings
+// CreateLearning handles POST /api/learnings. It answers 201 with the new
+// learning, or 202 with the queued request when every mentor is busy.
 func (h *LearningHandler) CreateLearning(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
@@ -77,22 +90,30 @@ func (h *LearningHandler) CreateLearning(c *gin.Context) {
 		return
 	}
 
-	learning, err := h.learningService.CreateLearningFromRequest(
+	learning, request, err := h.learningService.CreateLearningFromRequest(
 		c.Request.Context(),
 		userID.(string),
 		req.Topic,
 		req.Description,
+		req.Skills,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// No mentor has a free slot, or the manager has to sign off first: the
+	// request waits
+	if learning == nil {
+		c.JSON(http.StatusAccepted, dto.ToRequestResponseDTO(request))
+		return
+	}
+
 	responseDTO := dto.ToLearningResponseDTO(learning)
 	c.JSON(http.StatusCreated, responseDTO)
 }
 
-// UpdateLearning handles PUT /api/learnings/:id (admin only)
+// UpdateLearning handles PUT /api/learnings/:id (learnings.manage)
 func (h *LearningHandler) UpdateLearning(c *gin.Context) {
 	learningID := c.Param("id")
 
@@ -135,7 +156,6 @@ func (h *LearningHandler) UpdateLearning(c *gin.Context) {
 func (h *LearningHandler) UpdatePlan(c *gin.Context) {
 	learningID := c.Param("id")
 	userID, _ := c.Get("userID")
-	role, _ := c.Get("role")
 
 	var req dto.UpdatePlanDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -143,16 +163,23 @@ func (h *LearningHandler) UpdatePlan(c *gin.Context) {
 		return
 	}
 
-	// Check access: owner or admin
+	// Check access: owner, their mentor or learnings.edit_plan
 	existingLearning, err := h.learningService.GetLearningByID(c.Request.Context(), learningID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	if existingLearning.UserID != userID.(string) && role.(string) != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
-		return
+	if existingLearning.UserID != userID.(string) && !middleware.Can(c, domain.PermLearningsEditPlan) {
+		ok, err := h.learningService.IsMentorOf(c.Request.Context(), existingLearning, userID.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
 	}
 
 	plan := dto.ToPlanItems(req.Plan)
@@ -171,7 +198,6 @@ func (h *LearningHandler) UpdatePlan(c *gin.Context) {
 func (h *LearningHandler) UpdateNotes(c *gin.Context) {
 	learningID := c.Param("id")
 	userID, _ := c.Get("userID")
-	role, _ := c.Get("role")
 
 	var req dto.UpdateNotesDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -179,16 +205,23 @@ func (h *LearningHandler) UpdateNotes(c *gin.Context) {
 		return
 	}
 
-	// Check access: owner or admin
+	// Check access: owner, their mentor or learnings.edit_plan
 	existingLearning, err := h.learningService.GetLearningByID(c.Request.Context(), learningID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	if existingLearning.UserID != userID.(string) && role.(string) != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
-		return
+	if existingLearning.UserID != userID.(string) && !middleware.Can(c, domain.PermLearningsEditPlan) {
+		ok, err := h.learningService.IsMentorOf(c.Request.Context(), existingLearning, userID.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
 	}
 
 	learning, err := h.learningService.UpdateNotes(c.Request.Context(), learningID, req.Notes)
@@ -202,7 +235,7 @@ func (h *LearningHandler) UpdateNotes(c *gin.Context) {
 	c.JSON(http.StatusOK, responseDTO)
 }
 
-// AssignMentor handles POST /api/learnings/:id/assign (admin only)
+// AssignMentor handles POST /api/learnings/:id/assign (learnings.manage)
 func (h *LearningHandler) AssignMentor(c *gin.Context) {
 	learningID := c.Param("id")
 
@@ -229,7 +262,6 @@ func (h *LearningHandler) AssignMentor(c *gin.Context) {
 func (h *LearningHandler) CompleteLearning(c *gin.Context) {
 	learningID := c.Param("id")
 	userID, _ := c.Get("userID")
-	role, _ := c.Get("role")
 
 	var req dto.CompleteLearningDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -237,14 +269,14 @@ func (h *LearningHandler) CompleteLearning(c *gin.Context) {
 		return
 	}
 
-	// Check access: owner or admin
+	// Check access: owner or learnings.manage
 	existingLearning, err := h.learningService.GetLearningByID(c.Request.Context(), learningID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	if existingLearning.UserID != userID.(string) && role.(string) != "admin" {
+	if existingLearning.UserID != userID.(string) && !middleware.Can(c, domain.PermLearningsManage) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
 		return
 	}
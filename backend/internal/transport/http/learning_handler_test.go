@@ -0,0 +1,49 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestSelfServiceLearningFlow(t *testing.T) {
+	srv := apitest.New(t)
+	employee := srv.Employee(t, "alice")
+	admin := srv.Admin(t, "root")
+	busy := srv.Mentor(t, "busy", 3)
+	idle := srv.Mentor(t, "idle", 1)
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", employee.Token, map[string]string{
+		"topic":       "Kubernetes",
+		"description": "Operators",
+	}).Decode(t, &learning)
+	if learning.Mentor.ID != idle.Mentor.ID {
+		t.Fatalf("assigned mentor %s, want the least loaded one", learning.Mentor.Name)
+	}
+	assertWorkload(t, srv, admin, idle.Mentor.ID, 2)
+
+	var requests struct {
+		Requests []dto.TrainingRequestResponseDTO `json:"requests"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests/my", employee.Token, nil).Decode(t, &requests)
+	if len(requests.Requests) != 1 || requests.Requests[0].Status != "approved" {
+		t.Fatalf("my requests = %+v", requests.Requests)
+	}
+
+	// Admin edits the learning and marks it completed
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/learnings/"+learning.ID, admin.Token, map[string]any{
+		"topic":       "Kubernetes",
+		"description": "Operators",
+		"status":      "completed",
+		"plan":        []map[string]any{{"id": "1", "text": "Write a controller", "completed": true}},
+		"feedback":    map[string]any{"rating": 4, "comment": "Solid"},
+	}).Decode(t, &learning)
+	if learning.Status != "completed" {
+		t.Fatalf("status = %s, want completed", learning.Status)
+	}
+	assertWorkload(t, srv, admin, idle.Mentor.ID, 1)
+	assertWorkload(t, srv, admin, busy.Mentor.ID, 3)
+}
@@ -1,55 +1,91 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/middleware"
 )
 
 type MentorHandler struct {
-	mentorService *service.MentorService
+	mentorService  *service.MentorService
+	handoffService *service.HandoffService
 }
 
-func NewMentorHandler(mentorService *service.MentorService) *MentorHandler {
+func NewMentorHandler(mentorService *service.MentorService, handoffService *service.HandoffService) *MentorHandler {
 	return &MentorHandler{
-		mentorService: mentorService,
+		mentorService:  mentorService,
+		handoffService: handoffService,
 	}
 }
 
 // CreateMentorDTO represents mentor creation input
 type CreateMentorDTO struct {
-	Name       string `json:"name" binding:"required"`
-	JobTitle   string `json:"jobTitle" binding:"required"`
-	Experience string `json:"experience"`
-	Email      string `json:"email" binding:"required,email"`
-	Telegram   string `json:"telegram"`
+	Name       string   `json:"name" binding:"required"`
+	JobTitle   string   `json:"jobTitle" binding:"required"`
+	Experience string   `json:"experience"`
+	Email      string   `json:"email" binding:"required,email"`
+	Telegram   string   `json:"telegram"`
+	Skills     []string `json:"skills"`
+	Capacity   int      `json:"capacity" binding:"omitempty,min=1,max=20"`
 }
 
 // UpdateMentorDTO represents mentor update input
 type UpdateMentorDTO struct {
-	Name       string `json:"name" binding:"required"`
-	JobTitle   string `json:"jobTitle" binding:"required"`
-	Experience string `json:"experience" binding:"required"`
-	Workload   int    `json:"workload" binding:"required,min=0,max=5"`
-	Email      string `json:"email" binding:"required,email"`
-	Telegram   string `json:"telegram"`
+	Name       string   `json:"name" binding:"required"`
+	JobTitle   string   `json:"jobTitle" binding:"required"`
+	Experience string   `json:"experience" binding:"required"`
+	Workload   int      `json:"workload" binding:"min=0,max=20"`
+	Email      string   `json:"email" binding:"required,email"`
+	Telegram   string   `json:"telegram"`
+	Skills     []string `json:"skills"` // omitted keeps the current skills
+	Capacity   int      `json:"capacity" binding:"omitempty,min=1,max=20"`
+}
+
+// HandoffDTO represents a mentor handoff request; assignments override the
+// proposed replacement for individual learnings
+type HandoffDTO struct {
+	Assignments []struct {
+		LearningID string `json:"learningId" binding:"required"`
+		MentorID   string `json:"mentorId" binding:"required"`
+	} `json:"assignments" binding:"dive"`
+	Note       string `json:"note"`
+	Deactivate bool   `json:"deactivate"`
 }
 
 func (h *MentorHandler) GetAllMentors(c *gin.Context) {
 	// Check if filtering by availability
 	availableOnly := c.Query("available") == "true"
+	includeInactive := c.Query("includeInactive") == "true" && middleware.Can(c, domain.PermMentorsManage)
 
 	var mentors interface{}
 	var err error
 
 	if availableOnly {
-		mentors, err = h.mentorService.GetAvailableMentors(c.Request.Context())
+		// Optional ?from=&to= range; without it availability means right now
+		var from, to time.Time
+		if from, err = parseTimeQuery(c, "from"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if to, err = parseTimeQuery(c, "to"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		mentors, err = h.mentorService.GetAvailableMentors(c.Request.Context(), from, to)
 	} else {
-		mentors, err = h.mentorService.GetAllMentors(c.Request.Context())
+		mentors, err = h.mentorService.GetAllMentors(c.Request.Context(), includeInactive)
 	}
 
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -79,7 +115,7 @@ func (h *MentorHandler) CreateMentor(c *gin.Context) {
 	mentor, err := h.mentorService.CreateMentor(
 		c.Request.Context(),
 		req.Name, req.JobTitle, req.Experience,
-		req.Email, req.Telegram,
+		req.Email, req.Telegram, req.Skills, req.Capacity,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -106,7 +142,9 @@ func (h *MentorHandler) UpdateMentor(c *gin.Context) {
 		req.Experience,
 		req.Email,
 		req.Telegram,
+		req.Skills,
 		req.Workload,
+		req.Capacity,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -115,3 +153,96 @@ func (h *MentorHandler) UpdateMentor(c *gin.Context) {
 
 	c.JSON(http.StatusOK, mentor)
 }
+
+// DeactivateMentor handles POST /api/mentors/:id/deactivate (mentors.manage)
+func (h *MentorHandler) DeactivateMentor(c *gin.Context) {
+	id := c.Param("id")
+
+	mentor, err := h.mentorService.DeactivateMentor(c.Request.Context(), id)
+	if err != nil {
+		var activeErr *service.ActiveLearningsError
+		switch {
+		case errors.As(err, &activeErr):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "learningIds": activeErr.LearningIDs})
+		case errors.Is(err, domain.ErrMentorNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, mentor)
+}
+
+// ReactivateMentor handles POST /api/mentors/:id/reactivate (mentors.manage)
+func (h *MentorHandler) ReactivateMentor(c *gin.Context) {
+	id := c.Param("id")
+
+	mentor, err := h.mentorService.ReactivateMentor(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrMentorNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, mentor)
+}
+
+// PlanHandoff handles GET /api/mentors/:id/handoff (mentors.manage)
+func (h *MentorHandler) PlanHandoff(c *gin.Context) {
+	plan, err := h.handoffService.PlanHandoff(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrMentorNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// ExecuteHandoff handles POST /api/mentors/:id/handoff (mentors.manage)
+func (h *MentorHandler) ExecuteHandoff(c *gin.Context) {
+	var req HandoffDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	opts := service.HandoffOptions{
+		Overrides:  make(map[string]string, len(req.Assignments)),
+		Note:       req.Note,
+		Deactivate: req.Deactivate,
+	}
+	for _, a := range req.Assignments {
+		opts.Overrides[a.LearningID] = a.MentorID
+	}
+
+	plan, err := h.handoffService.ExecuteHandoff(c.Request.Context(), c.Param("id"), opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNoReplacement):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "plan": plan})
+		case errors.Is(err, domain.ErrMentorNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidInput),
+			errors.Is(err, domain.ErrMentorDeactivated),
+			errors.Is(err, domain.ErrMentorAbsent),
+			errors.Is(err, domain.ErrMentorNotAvailable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
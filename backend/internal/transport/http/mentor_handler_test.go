@@ -0,0 +1,205 @@
+package http_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestMentorManagement(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	employee := srv.Employee(t, "alice")
+
+	var mentor struct {
+		ID       string `json:"id"`
+		Workload int    `json:"workload"`
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/mentors", admin.Token, map[string]string{
+		"name":     "Ann",
+		"jobTitle": "Staff Engineer",
+		"email":    "ann@example.com",
+	}).Decode(t, &mentor)
+
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/mentors/"+mentor.ID, admin.Token, map[string]any{
+		"name":       "Ann",
+		"jobTitle":   "Principal Engineer",
+		"experience": "12 years",
+		"email":      "ann@example.com",
+		"workload":   5,
+	})
+
+	var available []struct {
+		ID string `json:"id"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors?available=true", employee.Token, nil).Decode(t, &available)
+	if len(available) != 0 {
+		t.Errorf("full mentor listed as available")
+	}
+
+	// Workload can be reset to zero
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/mentors/"+mentor.ID, admin.Token, map[string]any{
+		"name":       "Ann",
+		"jobTitle":   "Principal Engineer",
+		"experience": "12 years",
+		"email":      "ann@example.com",
+		"workload":   0,
+	})
+	assertWorkload(t, srv, admin, mentor.ID, 0)
+
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/mentors/"+apitest.MissingID(), employee.Token, nil)
+}
+
+func TestMentorDeactivation(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	employee := srv.Employee(t, "alice")
+	leaving := srv.Mentor(t, "ann", 0)
+	staying := srv.Mentor(t, "max", 3)
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", employee.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+	if learning.Mentor.ID != leaving.Mentor.ID {
+		t.Fatalf("learning went to mentor %s, want the idle one", learning.Mentor.ID)
+	}
+
+	var conflict struct {
+		LearningIDs []string `json:"learningIds"`
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/mentors/"+leaving.Mentor.ID+"/deactivate", admin.Token, nil).Decode(t, &conflict)
+	if len(conflict.LearningIDs) != 1 || conflict.LearningIDs[0] != learning.ID {
+		t.Fatalf("conflict lists %v, want [%s]", conflict.LearningIDs, learning.ID)
+	}
+
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/assign", admin.Token, map[string]string{
+		"mentorId": staying.Mentor.ID,
+	})
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/mentors/"+leaving.Mentor.ID+"/deactivate", admin.Token, nil)
+	assertWorkload(t, srv, admin, leaving.Mentor.ID, 0)
+	assertWorkload(t, srv, admin, staying.Mentor.ID, 4)
+
+	// Deactivated mentors drop out of listings and cannot be assigned
+	var listed []struct {
+		ID string `json:"id"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors", employee.Token, nil).Decode(t, &listed)
+	if len(listed) != 1 || listed[0].ID != staying.Mentor.ID {
+		t.Errorf("listed mentors = %v", listed)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors?includeInactive=true", employee.Token, nil).Decode(t, &listed)
+	if len(listed) != 1 {
+		t.Errorf("includeInactive honoured for an employee: %v", listed)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors?includeInactive=true", admin.Token, nil).Decode(t, &listed)
+	if len(listed) != 2 {
+		t.Errorf("admin listing with includeInactive = %v", listed)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/learnings/"+learning.ID+"/assign", admin.Token, map[string]string{
+		"mentorId": leaving.Mentor.ID,
+	})
+
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/mentors/"+leaving.Mentor.ID+"/reactivate", admin.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/assign", admin.Token, map[string]string{
+		"mentorId": leaving.Mentor.ID,
+	})
+}
+
+func TestMentorHandoff(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+	leaving := srv.Mentor(t, "ann", 0)
+
+	var learnings []dto.LearningProcessResponseDTO
+	for _, learner := range []*apitest.Persona{alice, bob} {
+		var learning dto.LearningProcessResponseDTO
+		srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", learner.Token, map[string]string{
+			"topic":       "Go",
+			"description": "Generics",
+		}).Decode(t, &learning)
+		learnings = append(learnings, learning)
+	}
+
+	// Nobody can take over yet
+	var conflict struct {
+		Plan struct {
+			Assignments []struct {
+				MentorID string `json:"mentorId"`
+			} `json:"assignments"`
+		} `json:"plan"`
+	}
+	path := "/api/mentors/" + leaving.Mentor.ID + "/handoff"
+	srv.Expect(t, http.StatusConflict, http.MethodPost, path, admin.Token, nil).Decode(t, &conflict)
+	if len(conflict.Plan.Assignments) != 2 || conflict.Plan.Assignments[0].MentorID != "" {
+		t.Fatalf("conflict plan = %+v", conflict.Plan)
+	}
+
+	max := srv.Mentor(t, "max", 3)
+	zoe := srv.Mentor(t, "zoe", 0)
+
+	type plan struct {
+		Assignments []struct {
+			LearningID string `json:"learningId"`
+			MentorID   string `json:"mentorId"`
+		} `json:"assignments"`
+	}
+	var proposed plan
+	srv.Expect(t, http.StatusOK, http.MethodGet, path, admin.Token, nil).Decode(t, &proposed)
+	if len(proposed.Assignments) != 2 || proposed.Assignments[0].MentorID != zoe.Mentor.ID || proposed.Assignments[1].MentorID != zoe.Mentor.ID {
+		t.Fatalf("proposed plan = %+v", proposed)
+	}
+
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, path, admin.Token, map[string]any{
+		"assignments": []map[string]string{{"learningId": learnings[0].ID, "mentorId": leaving.Mentor.ID}},
+	})
+
+	var executed plan
+	srv.Expect(t, http.StatusOK, http.MethodPost, path, admin.Token, map[string]any{
+		"assignments": []map[string]string{{"learningId": learnings[1].ID, "mentorId": max.Mentor.ID}},
+		"note":        "Ann moved to another team.",
+		"deactivate":  true,
+	}).Decode(t, &executed)
+	if len(executed.Assignments) != 2 || executed.Assignments[1].MentorID != max.Mentor.ID {
+		t.Fatalf("executed plan = %+v", executed)
+	}
+	assertWorkload(t, srv, admin, leaving.Mentor.ID, 0)
+	assertWorkload(t, srv, admin, zoe.Mentor.ID, 1)
+	assertWorkload(t, srv, admin, max.Mentor.ID, 4)
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/learnings/"+learnings[1].ID, bob.Token, nil).Decode(t, &learning)
+	if learning.Mentor.ID != max.Mentor.ID || learning.Notes == nil || !strings.HasSuffix(*learning.Notes, "Ann moved to another team.") {
+		t.Errorf("handed-off learning = mentor %s notes %v", learning.Mentor.Name, learning.Notes)
+	}
+
+	var inbox struct {
+		Notifications []struct {
+			ID   string `json:"id"`
+			Kind string `json:"kind"`
+		} `json:"notifications"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/notifications?unread=true", bob.Token, nil).Decode(t, &inbox)
+	if len(inbox.Notifications) != 1 || inbox.Notifications[0].Kind != "mentor_changed" {
+		t.Fatalf("bob's notifications = %+v", inbox)
+	}
+	id := inbox.Notifications[0].ID
+	srv.Expect(t, http.StatusNotFound, http.MethodPost, "/api/notifications/"+id+"/read", alice.Token, nil)
+	srv.Expect(t, http.StatusNoContent, http.MethodPost, "/api/notifications/"+id+"/read", bob.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/notifications?unread=true", bob.Token, nil).Decode(t, &inbox)
+	if len(inbox.Notifications) != 0 {
+		t.Errorf("notification still unread")
+	}
+
+	// The departed mentor is deactivated and has nothing left to hand off
+	srv.Expect(t, http.StatusOK, http.MethodGet, path, admin.Token, nil).Decode(t, &proposed)
+	if len(proposed.Assignments) != 0 {
+		t.Errorf("learnings left after handoff: %+v", proposed)
+	}
+}
@@ -1,16 +1,30 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 )
 
-// AuthMiddleware validates JWT token and sets user info in context
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// ActiveUserLoader loads a token's user if they may still use the API
+type ActiveUserLoader interface {
+	ActiveUser(ctx context.Context, userID string) (*domain.User, error)
+}
+
+// AuthMiddleware validates JWT token, rejects deactivated users and sets
+// user info in context. The role and permissions are read from the database
+// on every request, so role changes apply to tokens issued before them.
+// The request runs in the tenant of the token, which must match the tenant
+// of the host if the host belongs to one; tokens from before multi-tenancy
+// carry no tenant and belong to the default one.
+func AuthMiddleware(jwtSecret string, users ActiveUserLoader) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -65,19 +79,47 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		role, ok := claims["role"].(string)
-		if !ok || role == "" {
+		if role, ok := claims["role"].(string); !ok || role == "" {
 			slog.Error("Invalid role in token", "claims", claims)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid role in token"})
 			c.Abort()
 			return
 		}
 
-		slog.Info("User authenticated", "userID", userID, "role", role)
+		tenantID, _ := claims["tenant_id"].(string)
+		if tenantID == "" {
+			tenantID = domain.DefaultTenantID
+		}
+		if hostTenantID, ok := c.Get("hostTenantID"); ok && hostTenantID != tenantID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": domain.ErrTenantMismatch.Error()})
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(domain.WithTenant(c.Request.Context(), tenantID))
+
+		// Tokens outlive deactivation, so check the account on every request
+		user, err := users.ActiveUser(c.Request.Context(), userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrUserDeactivated):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			case errors.Is(err, domain.ErrUserNotFound):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "user no longer exists"})
+			default:
+				slog.Error("Failed to check user status", "userID", userID, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check user status"})
+			}
+			c.Abort()
+			return
+		}
+
+		slog.Info("User authenticated", "userID", userID, "role", user.Role)
 
 		// Set user info in context
 		c.Set("userID", userID)
-		c.Set("role", role)
+		c.Set("role", string(user.Role))
+		c.Set("permissions", user.Permissions)
+		c.Set("permissionScope", user.PermissionScope)
 
 		c.Next()
 	}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// Can checks if the authenticated user's role grants the permission
+// organisation-wide; it is the handler-side counterpart of domain.User.Can
+func Can(c *gin.Context, perm domain.Permission) bool {
+	scope, _ := c.Get("permissionScope")
+	return Grants(c, perm) && scope != domain.ScopeDepartment
+}
+
+// Grants checks if the authenticated user's role grants the permission in
+// any scope, like domain.User.Grants
+func Grants(c *gin.Context, perm domain.Permission) bool {
+	permissions, _ := c.Get("permissions")
+	granted, _ := permissions.([]domain.Permission)
+	return slices.Contains(granted, perm)
+}
+
+// UserReach tells whether a permission of the actor reaches a user, either
+// organisation-wide or through the departments the actor heads, and whether
+// the actor's role covers everything the user's role grants
+type UserReach interface {
+	Reaches(ctx context.Context, actorID string, perm domain.Permission, userID string) (bool, error)
+	Covers(ctx context.Context, actorID, userID string) (bool, error)
+}
+
+// RequirePermission allows access only to users whose role grants perm
+func RequirePermission(perm domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("userID"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if !Can(c, perm) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permission " + string(perm) + " required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScopedPermission allows access to users whose role grants perm in
+// any scope; the handler narrows what department-scoped users see
+func RequireScopedPermission(perm domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("userID"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if !Grants(c, perm) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permission " + string(perm) + " required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OwnerOrPermission allows access to the user the :id parameter names and
+// to users whose perm reaches them
+func OwnerOrPermission(perm domain.Permission, reach UserReach) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if userID.(string) == c.Param("id") {
+			c.Next()
+			return
+		}
+		ok, err := Reaches(c, reach, perm, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access"})
+			c.Abort()
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PermissionOverUser allows access to users whose perm reaches the user the
+// :id parameter names and whose role covers that user's role
+func PermissionOverUser(perm domain.Permission, reach UserReach) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actorID, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if !Grants(c, perm) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permission " + string(perm) + " required"})
+			c.Abort()
+			return
+		}
+		ok, err := Reaches(c, reach, perm, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access"})
+			c.Abort()
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "user is outside your departments"})
+			c.Abort()
+			return
+		}
+		ok, err = reach.Covers(c.Request.Context(), actorID.(string), c.Param("id"))
+		if err != nil {
+			slog.Error("Failed to compare the roles of users", "userID", c.Param("id"), "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access"})
+			c.Abort()
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrRoleNotCovered.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Reaches checks if the authenticated user's perm reaches the user; the
+// departments are only looked up for department-scoped permissions
+func Reaches(c *gin.Context, reach UserReach, perm domain.Permission, userID string) (bool, error) {
+	if Can(c, perm) {
+		return true, nil
+	}
+	if !Grants(c, perm) {
+		return false, nil
+	}
+
+	actorID, _ := c.Get("userID")
+	ok, err := reach.Reaches(c.Request.Context(), actorID.(string), perm, userID)
+	if err != nil {
+		slog.Error("Failed to check the departments of a user", "userID", userID, "error", err)
+	}
+	return ok, err
+}
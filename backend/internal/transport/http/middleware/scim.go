@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/scim"
+)
+
+// SCIMTokenResolver finds the tenant a SCIM bearer token was issued for
+type SCIMTokenResolver interface {
+	ResolveSCIMToken(ctx context.Context, token string) (*domain.Tenant, error)
+}
+
+// SCIMAuth checks the bearer token of identity providers calling the SCIM
+// endpoints and confines the request to the tenant it was issued for. The
+// tokens are issued separately from user JWTs, so they grant provisioning
+// access only. defaultToken, the token of the configuration, provisions the
+// default tenant when set. Like AuthMiddleware, it runs after
+// TenantMiddleware and rejects tokens of another tenant than the host's.
+func SCIMAuth(tenants SCIMTokenResolver, defaultToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || given == "" {
+			abortSCIM(c, http.StatusUnauthorized, "invalid or missing bearer token")
+			return
+		}
+
+		var tenantID string
+		tenant, err := tenants.ResolveSCIMToken(c.Request.Context(), given)
+		switch {
+		case err == nil:
+			tenantID = tenant.ID
+		case !errors.Is(err, domain.ErrTenantNotFound):
+			slog.Error("Failed to resolve SCIM token", "error", err)
+			abortSCIM(c, http.StatusInternalServerError, "failed to check bearer token")
+			return
+		case defaultToken != "" && subtle.ConstantTimeCompare([]byte(given), []byte(defaultToken)) == 1:
+			tenantID = domain.DefaultTenantID
+		default:
+			abortSCIM(c, http.StatusUnauthorized, "invalid or missing bearer token")
+			return
+		}
+		if hostTenantID, ok := c.Get("hostTenantID"); ok && hostTenantID != tenantID {
+			abortSCIM(c, http.StatusUnauthorized, domain.ErrTenantMismatch.Error())
+			return
+		}
+
+		c.Request = c.Request.WithContext(domain.WithTenant(c.Request.Context(), tenantID))
+		c.Next()
+	}
+}
+
+func abortSCIM(c *gin.Context, status int, detail string) {
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer realm="scim"`)
+	}
+	// gin keeps a content type that is already set
+	c.Header("Content-Type", scim.MediaType+"; charset=utf-8")
+	c.JSON(status, scim.NewError(status, "", detail))
+	c.Abort()
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+)
+
+// TenantResolver finds the tenant a Host header belongs to
+type TenantResolver interface {
+	ResolveHost(ctx context.Context, host string) (*domain.Tenant, error)
+}
+
+// TenantMiddleware confines the request to the tenant its Host header
+// resolves to, or to the default tenant for hosts no tenant claims.
+// AuthMiddleware later switches to the tenant of the token where the host
+// does not decide it.
+func TenantMiddleware(tenants TenantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := domain.DefaultTenantID
+
+		tenant, err := tenants.ResolveHost(c.Request.Context(), c.Request.Host)
+		switch {
+		case err == nil:
+			tenantID = tenant.ID
+			c.Set("hostTenantID", tenant.ID)
+		case !errors.Is(err, domain.ErrTenantNotFound):
+			slog.Error("Failed to resolve tenant", "host", c.Request.Host, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve tenant"})
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(domain.WithTenant(c.Request.Context(), tenantID))
+		c.Next()
+	}
+}
+
+// SuperAdminAuth checks the bearer token of operators managing tenants.
+// The token is configured separately from user JWTs, which never reach
+// beyond their own tenant.
+func SuperAdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing bearer token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
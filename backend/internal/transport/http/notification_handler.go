@@ -0,0 +1,51 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetMyNotifications handles GET /api/notifications
+func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, err := h.notificationService.GetUserNotifications(c.Request.Context(), userID.(string), unreadOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
+
+// MarkRead handles POST /api/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	err := h.notificationService.MarkRead(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,36 +1,48 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type RequestHandler struct {
-	requestService  *service.RequestService
-	learningService *service.LearningService
+	requestService    *service.RequestService
+	learningService   *service.LearningService
+	queueService      *service.QueueService
+	approvalService   *service.ApprovalService
+	departmentService *service.DepartmentService
 }
 
-func NewRequestHandler(requestService *service.RequestService, learningService *service.LearningService) *RequestHandler {
+func NewRequestHandler(requestService *service.RequestService, learningService *service.LearningService, queueService *service.QueueService, approvalService *service.ApprovalService, departmentService *service.DepartmentService) *RequestHandler {
 	return &RequestHandler{
-		requestService:  requestService,
-		learningService: learningService,
+		requestService:    requestService,
+		learningService:   learningService,
+		queueService:      queueService,
+		approvalService:   approvalService,
+		departmentService: departmentService,
 	}
 }
 
 // CreateRequestDTO represents the input for creating a training request
 type CreateRequestDTO struct {
-	Topic       string `json:"topic" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Topic       string   `json:"topic" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	Skills      []string `json:"skills"`
 }
 
-// UpdateRequestDTO represents request update input
+// UpdateRequestDTO represents request update input; leave skills out to keep
+// the current skill tags
 type UpdateRequestDTO struct {
-	Topic       string `json:"topic" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Topic       string   `json:"topic" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	Skills      []string `json:"skills"`
 }
 
 func (h *RequestHandler) CreateRequest(c *gin.Context) {
@@ -47,6 +59,7 @@ func (h *RequestHandler) CreateRequest(c *gin.Context) {
 		userID.(string),
 		req.Topic,
 		req.Description,
+		req.Skills,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -58,14 +71,22 @@ func (h *RequestHandler) CreateRequest(c *gin.Context) {
 	c.JSON(http.StatusCreated, responseDTO)
 }
 
+// GetAllRequests handles GET /api/requests (requests.view); department heads
+// see the requests of the members of their departments
 func (h *RequestHandler) GetAllRequests(c *gin.Context) {
+	userID, _ := c.Get("userID")
 	status := c.Query("status")
 	var statusPtr *string
 	if status != "" {
 		statusPtr = &status
 	}
 
-	requests, err := h.requestService.GetAllRequests(c.Request.Context(), statusPtr)
+	scope, err := h.departmentService.Scope(c.Request.Context(), userID.(string), domain.PermRequestsView)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	requests, err := h.requestService.GetAllRequests(c.Request.Context(), statusPtr, scope)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -92,8 +113,6 @@ func (h *RequestHandler) GetMyRequests(c *gin.Context) {
 
 func (h *RequestHandler) GetRequestByID(c *gin.Context) {
 	requestID := c.Param("id")
-	userID, _ := c.Get("userID")
-	role, _ := c.Get("role")
 
 	request, err := h.requestService.GetRequestByID(c.Request.Context(), requestID)
 	if err != nil {
@@ -101,8 +120,13 @@ func (h *RequestHandler) GetRequestByID(c *gin.Context) {
 		return
 	}
 
-	// Access control: owner or admin
-	if request.UserID != userID.(string) && role.(string) != "admin" {
+	// Access control: owner, their manager or requests.view over the owner
+	ok, err := h.canSee(c, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
 		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
 		return
 	}
@@ -115,7 +139,6 @@ func (h *RequestHandler) GetRequestByID(c *gin.Context) {
 func (h *RequestHandler) UpdateRequest(c *gin.Context) {
 	requestID := c.Param("id")
 	userID, _ := c.Get("userID")
-	role, _ := c.Get("role")
 
 	var req UpdateRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -123,15 +146,15 @@ func (h *RequestHandler) UpdateRequest(c *gin.Context) {
 		return
 	}
 
-	// Check access: owner or admin
+	// Check access: owner or requests.assign
 	existingRequest, err := h.requestService.GetRequestByID(c.Request.Context(), requestID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Access control: owner or admin
-	if existingRequest.UserID != userID.(string) && role.(string) != "admin" {
+	// Access control: owner or requests.assign
+	if existingRequest.UserID != userID.(string) && !middleware.Can(c, domain.PermRequestsAssign) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
 		return
 	}
@@ -141,6 +164,7 @@ func (h *RequestHandler) UpdateRequest(c *gin.Context) {
 		requestID,
 		req.Topic,
 		req.Description,
+		req.Skills,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -152,7 +176,7 @@ func (h *RequestHandler) UpdateRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, responseDTO)
 }
 
-// AssignMentor handles POST /api/requests/:id/assign (admin only)
+// AssignMentor handles POST /api/requests/:id/assign (requests.assign)
 func (h *RequestHandler) AssignMentor(c *gin.Context) {
 	requestID := c.Param("id")
 
@@ -175,3 +199,175 @@ func (h *RequestHandler) AssignMentor(c *gin.Context) {
 	responseDTO := dto.ToLearningResponseDTO(learning)
 	c.JSON(http.StatusCreated, responseDTO)
 }
+
+// GetQueue handles GET /api/requests/queue (requests.assign)
+func (h *RequestHandler) GetQueue(c *gin.Context) {
+	queue, err := h.queueService.GetQueue(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": dto.ToRequestResponseDTOs(queue)})
+}
+
+// DispatchQueue handles POST /api/requests/queue/dispatch (requests.assign)
+func (h *RequestHandler) DispatchQueue(c *gin.Context) {
+	started, err := h.queueService.Dispatch(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"learnings": dto.ToLearningResponseDTOs(started)})
+}
+
+// EnqueueRequest handles POST /api/requests/:id/queue (requests.assign)
+func (h *RequestHandler) EnqueueRequest(c *gin.Context) {
+	var req dto.EnqueueRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.queueService.Enqueue(c.Request.Context(), c.Param("id"), req.Priority)
+	if err != nil {
+		respondQueueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToRequestResponseDTO(request))
+}
+
+// DequeueRequest handles DELETE /api/requests/:id/queue (requests.assign)
+func (h *RequestHandler) DequeueRequest(c *gin.Context) {
+	request, err := h.queueService.Dequeue(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondQueueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToRequestResponseDTO(request))
+}
+
+// GetTeamRequests handles GET /api/requests/team: the requests of the
+// caller's direct reports, optionally filtered by ?status
+func (h *RequestHandler) GetTeamRequests(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	status := c.Query("status")
+	var statusPtr *string
+	if status != "" {
+		statusPtr = &status
+	}
+
+	requests, err := h.approvalService.GetTeamRequests(c.Request.Context(), userID.(string), statusPtr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": dto.ToRequestResponseDTOs(requests)})
+}
+
+// GetApprovals handles GET /api/requests/:id/approvals (owner, their
+// manager or requests.view)
+func (h *RequestHandler) GetApprovals(c *gin.Context) {
+	requestID := c.Param("id")
+
+	request, err := h.requestService.GetRequestByID(c.Request.Context(), requestID)
+	if err != nil {
+		respondApprovalError(c, err)
+		return
+	}
+
+	ok, err := h.canSee(c, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	decisions, err := h.approvalService.GetDecisions(c.Request.Context(), requestID)
+	if err != nil {
+		respondApprovalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"approvals": decisions})
+}
+
+// DecideRequest handles POST /api/requests/:id/decision; the service checks
+// that the caller may decide the current stage
+func (h *RequestHandler) DecideRequest(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.DecideRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.approvalService.Decide(
+		c.Request.Context(),
+		c.Param("id"),
+		userID.(string),
+		domain.Decision(req.Decision),
+		req.Comment,
+	)
+	if err != nil {
+		respondApprovalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToRequestResponseDTO(request))
+}
+
+// respondApprovalError maps approval errors to HTTP status codes
+func respondApprovalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotApprover):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRequestNotAwaitingApproval),
+		errors.Is(err, domain.ErrCourseFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// respondQueueError maps queue errors to HTTP status codes
+func respondQueueError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRequestNotPending),
+		errors.Is(err, domain.ErrRequestNotQueued),
+		errors.Is(err, domain.ErrCourseRequest):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// canSee checks if the authenticated user may see a request: its owner, the
+// owner's line manager and users whose requests.view reaches the owner may
+func (h *RequestHandler) canSee(c *gin.Context, request *domain.TrainingRequest) (bool, error) {
+	userID, _ := c.Get("userID")
+	if request.UserID == userID.(string) {
+		return true, nil
+	}
+
+	ok, err := middleware.Reaches(c, h.departmentService, domain.PermRequestsView, request.UserID)
+	if err != nil || ok {
+		return ok, err
+	}
+	return h.approvalService.IsManagerOf(c.Request.Context(), userID.(string), request.UserID)
+}
@@ -0,0 +1,264 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestRequestToCompletionFlow(t *testing.T) {
+	srv := apitest.New(t)
+	employee := srv.Employee(t, "alice")
+	admin := srv.Admin(t, "root")
+	mentor := srv.Mentor(t, "ann", 0)
+
+	// Employee files a request
+	var request dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests", employee.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Concurrency patterns",
+	}).Decode(t, &request)
+	if request.Status != "pending" || request.User.ID != employee.User.ID {
+		t.Fatalf("created request = %+v", request)
+	}
+
+	// Admin sees it in the pending queue and assigns a mentor
+	var pending struct {
+		Requests []dto.TrainingRequestResponseDTO `json:"requests"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests?status=pending", admin.Token, nil).Decode(t, &pending)
+	if len(pending.Requests) != 1 || pending.Requests[0].ID != request.ID {
+		t.Fatalf("pending requests = %+v", pending.Requests)
+	}
+
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests/"+request.ID+"/assign", admin.Token, map[string]string{
+		"mentorId": mentor.Mentor.ID,
+	}).Decode(t, &learning)
+	if learning.Status != "active" || learning.Mentor.ID != mentor.Mentor.ID || learning.Request.Topic != "Go" {
+		t.Fatalf("assigned learning = %+v", learning)
+	}
+	assertWorkload(t, srv, admin, mentor.Mentor.ID, 1)
+
+	// The request can no longer be assigned
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/requests/"+request.ID+"/assign", admin.Token, map[string]string{
+		"mentorId": mentor.Mentor.ID,
+	})
+
+	// Employee works on the plan and notes
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/learnings/"+learning.ID+"/plan", employee.Token, map[string]any{
+		"plan": []map[string]any{
+			{"id": "1", "text": "Tour of Go", "completed": true},
+			{"id": "2", "text": "Write a worker pool", "completed": false},
+		},
+	}).Decode(t, &learning)
+	if len(learning.Plan) != 2 || !learning.Plan[0].Completed {
+		t.Fatalf("plan = %+v", learning.Plan)
+	}
+
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/learnings/"+learning.ID+"/notes", employee.Token, map[string]string{
+		"notes": "halfway there",
+	}).Decode(t, &learning)
+	if learning.Notes == nil || *learning.Notes != "halfway there" {
+		t.Fatalf("notes = %v", learning.Notes)
+	}
+
+	var mine struct {
+		Learnings []dto.LearningProcessResponseDTO `json:"learnings"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/learnings", employee.Token, nil).Decode(t, &mine)
+	if len(mine.Learnings) != 1 || mine.Learnings[0].ID != learning.ID {
+		t.Fatalf("my learnings = %+v", mine.Learnings)
+	}
+
+	// Employee completes it with feedback, which frees the mentor
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/complete", employee.Token, map[string]any{
+		"rating":  5,
+		"comment": "Great mentor",
+	}).Decode(t, &learning)
+	if learning.Status != "completed" || learning.EndDate == nil || learning.Feedback == nil || learning.Feedback.Rating != 5 {
+		t.Fatalf("completed learning = %+v", learning)
+	}
+	assertWorkload(t, srv, admin, mentor.Mentor.ID, 0)
+
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/learnings/"+learning.ID+"/complete", employee.Token, map[string]any{
+		"rating":  4,
+		"comment": "again",
+	})
+}
+
+func TestRequestQueue(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	bob := srv.Employee(t, "bob")
+	carol := srv.Employee(t, "carol")
+	admin := srv.Admin(t, "root")
+	mentor := srv.Mentor(t, "ann", 4)
+
+	// alice takes the last free slot
+	var learning dto.LearningProcessResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &learning)
+	assertWorkload(t, srv, admin, mentor.Mentor.ID, 5)
+
+	// bob is queued instead of turned away
+	var queued dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusAccepted, http.MethodPost, "/api/learnings", bob.Token, map[string]string{
+		"topic":       "Rust",
+		"description": "Ownership",
+	}).Decode(t, &queued)
+	if queued.Status != "queued" || queued.QueuePosition == nil || *queued.QueuePosition != 1 {
+		t.Fatalf("queued request = %+v", queued)
+	}
+
+	// An admin queues carol's request ahead of bob
+	var urgent dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests", carol.Token, map[string]string{
+		"topic":       "SQL",
+		"description": "Indexes",
+	}).Decode(t, &urgent)
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/requests/"+urgent.ID+"/queue", admin.Token, map[string]int{
+		"priority": 5,
+	}).Decode(t, &urgent)
+	if urgent.Status != "queued" || urgent.Priority != 5 || urgent.QueuePosition == nil || *urgent.QueuePosition != 1 {
+		t.Fatalf("prioritized request = %+v", urgent)
+	}
+
+	var mine struct {
+		Requests []dto.TrainingRequestResponseDTO `json:"requests"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests/my", bob.Token, nil).Decode(t, &mine)
+	if len(mine.Requests) != 1 || mine.Requests[0].QueuePosition == nil || *mine.Requests[0].QueuePosition != 2 {
+		t.Fatalf("bob's requests = %+v", mine.Requests)
+	}
+
+	var queue struct {
+		Requests []dto.TrainingRequestResponseDTO `json:"requests"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests/queue", admin.Token, nil).Decode(t, &queue)
+	if len(queue.Requests) != 2 || queue.Requests[0].ID != urgent.ID || queue.Requests[1].ID != queued.ID {
+		t.Fatalf("queue = %+v", queue.Requests)
+	}
+
+	// Completing alice's learning hands the slot to carol
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/learnings/"+learning.ID+"/complete", alice.Token, map[string]any{
+		"rating":  5,
+		"comment": "Thanks",
+	})
+	assertWorkload(t, srv, admin, mentor.Mentor.ID, 5)
+
+	var carols struct {
+		Learnings []dto.LearningProcessResponseDTO `json:"learnings"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/learnings", carol.Token, nil).Decode(t, &carols)
+	if len(carols.Learnings) != 1 || carols.Learnings[0].Request.ID != urgent.ID || carols.Learnings[0].Mentor.ID != mentor.Mentor.ID {
+		t.Fatalf("carol's learnings = %+v", carols.Learnings)
+	}
+
+	// bob moves up, and can be taken out of the queue for manual assignment
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests/"+queued.ID, bob.Token, nil).Decode(t, &queued)
+	if queued.QueuePosition == nil || *queued.QueuePosition != 1 {
+		t.Fatalf("bob's position = %v, want 1", queued.QueuePosition)
+	}
+	var dequeued dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusOK, http.MethodDelete, "/api/requests/"+queued.ID+"/queue", admin.Token, nil).Decode(t, &dequeued)
+	if dequeued.Status != "pending" || dequeued.QueuePosition != nil {
+		t.Fatalf("dequeued request = %+v", dequeued)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodDelete, "/api/requests/"+queued.ID+"/queue", admin.Token, nil)
+
+	var dispatched struct {
+		Learnings []dto.LearningProcessResponseDTO `json:"learnings"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/requests/queue/dispatch", admin.Token, nil).Decode(t, &dispatched)
+	if len(dispatched.Learnings) != 0 {
+		t.Fatalf("dispatch of an empty queue started %d learnings", len(dispatched.Learnings))
+	}
+}
+
+func TestManagerApproval(t *testing.T) {
+	srv := apitest.NewWithApprovals(t, []domain.ApprovalStage{domain.StageManager, domain.StageAdmin})
+	alice := srv.Employee(t, "alice")
+	boss := srv.Employee(t, "boss")
+	bob := srv.Employee(t, "bob")
+	admin := srv.Admin(t, "root")
+	mentor := srv.Mentor(t, "ann", 0)
+
+	var user domain.User
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/users/"+alice.User.ID+"/manager", admin.Token, map[string]string{
+		"managerId": boss.User.ID,
+	}).Decode(t, &user)
+	if user.ManagerID == nil || *user.ManagerID != boss.User.ID {
+		t.Fatalf("manager of alice = %v", user.ManagerID)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPut, "/api/users/"+boss.User.ID+"/manager", admin.Token, map[string]string{
+		"managerId": alice.User.ID,
+	})
+
+	var request dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests", alice.Token, map[string]string{
+		"topic":       "Go",
+		"description": "Generics",
+	}).Decode(t, &request)
+	if request.Status != "awaiting_manager" || len(request.ApprovalChain) != 2 {
+		t.Fatalf("new request = %+v", request)
+	}
+
+	// boss sees the request of their report; bob may not decide it
+	var team struct {
+		Requests []dto.TrainingRequestResponseDTO `json:"requests"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests/team?status=awaiting_manager", boss.Token, nil).Decode(t, &team)
+	if len(team.Requests) != 1 || team.Requests[0].ID != request.ID {
+		t.Fatalf("team requests = %+v", team.Requests)
+	}
+	decision := map[string]string{"decision": "approved", "comment": "Useful for the migration"}
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/requests/"+request.ID+"/decision", bob.Token, decision)
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/requests/"+request.ID+"/decision", boss.Token, map[string]string{
+		"decision": "maybe",
+	})
+
+	var decided dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/requests/"+request.ID+"/decision", boss.Token, decision).Decode(t, &decided)
+	if decided.Status != "pending" {
+		t.Fatalf("status after manager approval = %s, want pending", decided.Status)
+	}
+
+	// The admin stage is not the manager's
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/requests/"+request.ID+"/decision", boss.Token, decision)
+	var approved dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/requests/"+request.ID+"/decision", admin.Token, map[string]string{
+		"decision": "approved",
+	}).Decode(t, &approved)
+	if approved.Status != "approved" {
+		t.Fatalf("status after admin approval = %s, want approved", approved.Status)
+	}
+	assertWorkload(t, srv, admin, mentor.Mentor.ID, 1)
+
+	var history struct {
+		Approvals []domain.ApprovalDecision `json:"approvals"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/requests/"+request.ID+"/approvals", alice.Token, nil).Decode(t, &history)
+	if len(history.Approvals) != 2 || history.Approvals[0].DeciderName != "boss" || history.Approvals[1].Stage != domain.StageAdmin {
+		t.Fatalf("approvals = %+v", history.Approvals)
+	}
+
+	// Self-service learnings also wait for the manager
+	var selfService dto.TrainingRequestResponseDTO
+	srv.Expect(t, http.StatusAccepted, http.MethodPost, "/api/learnings", alice.Token, map[string]string{
+		"topic":       "Rust",
+		"description": "Ownership",
+	}).Decode(t, &selfService)
+	if selfService.Status != "awaiting_manager" || len(selfService.ApprovalChain) != 1 {
+		t.Fatalf("self-service request = %+v", selfService)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/requests/"+selfService.ID+"/decision", boss.Token, map[string]string{
+		"decision": "approved",
+	}).Decode(t, &decided)
+	assertWorkload(t, srv, admin, mentor.Mentor.ID, 2)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type RoleHandler struct {
+	roleService *service.RoleService
+	userService *service.UserService
+}
+
+func NewRoleHandler(roleService *service.RoleService, userService *service.UserService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		userService: userService,
+	}
+}
+
+// ListPermissions handles GET /api/admin/permissions (roles.manage)
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": domain.Permissions})
+}
+
+// ListRoles handles GET /api/admin/roles (roles.manage)
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// GetRole handles GET /api/admin/roles/:name (roles.manage)
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.roleService.GetRole(c.Request.Context(), domain.UserRole(c.Param("name")))
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// CreateRole handles POST /api/admin/roles (roles.manage)
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.CreateRole(
+		c.Request.Context(), domain.UserRole(req.Name), req.Description, permissionsFromDTO(req.Permissions), domain.RoleScope(req.Scope),
+	)
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole handles PUT /api/admin/roles/:name (roles.manage); the admin
+// role cannot change
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req dto.UpdateRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(
+		c.Request.Context(), domain.UserRole(c.Param("name")), req.Description, permissionsFromDTO(req.Permissions), domain.RoleScope(req.Scope),
+	)
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole handles DELETE /api/admin/roles/:name (roles.manage); only
+// custom roles nobody holds can be deleted
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleService.DeleteRole(c.Request.Context(), domain.UserRole(c.Param("name"))); err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetUserRole handles PUT /api/admin/users/:id/role (roles.manage)
+func (h *RoleHandler) SetUserRole(c *gin.Context) {
+	var req dto.SetRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.ChangeRole(c.Request.Context(), c.Param("id"), domain.UserRole(req.Role))
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func permissionsFromDTO(names []string) []domain.Permission {
+	permissions := make([]domain.Permission, len(names))
+	for i, name := range names {
+		permissions[i] = domain.Permission(name)
+	}
+	return permissions
+}
+
+// respondRoleError maps role errors to status codes
+func respondRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRoleNotFound),
+		errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRoleExists),
+		errors.Is(err, domain.ErrRoleInUse),
+		errors.Is(err, domain.ErrRoleProtected),
+		errors.Is(err, domain.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,74 @@
+package http_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestRoles(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	alice := srv.Employee(t, "alice")
+	rolePath := "/api/admin/users/" + alice.User.ID + "/role"
+
+	var permissions struct {
+		Permissions []domain.Permission `json:"permissions"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/permissions", admin.Token, nil).Decode(t, &permissions)
+	if !slices.Equal(permissions.Permissions, domain.Permissions) {
+		t.Fatalf("permissions = %v", permissions.Permissions)
+	}
+
+	var auditor domain.Role
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/admin/roles", admin.Token, map[string]any{
+		"name": "auditor", "description": "Read-only access", "permissions": []string{"users.view"},
+	}).Decode(t, &auditor)
+	if auditor.BuiltIn || !slices.Equal(auditor.Permissions, []domain.Permission{domain.PermUsersView}) {
+		t.Fatalf("created role = %+v", auditor)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/admin/roles", admin.Token, map[string]any{"name": "auditor"})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/roles", admin.Token, map[string]any{
+		"name": "spy", "permissions": []string{"users.spy"},
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/roles", admin.Token, map[string]any{
+		"name": "spy", "scope": "team",
+	})
+
+	// Assignments apply to tokens already issued, since the role is read
+	// from the database on every request
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/users", alice.Token, nil)
+	srv.Expect(t, http.StatusBadRequest, http.MethodPut, rolePath, admin.Token, map[string]string{"role": "owner"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, rolePath, admin.Token, map[string]string{"role": "auditor"})
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/users", alice.Token, nil)
+
+	var me struct {
+		Role        domain.UserRole     `json:"role"`
+		Permissions []domain.Permission `json:"permissions"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/auth/me", alice.Token, nil).Decode(t, &me)
+	if me.Role != "auditor" || !slices.Equal(me.Permissions, []domain.Permission{domain.PermUsersView}) {
+		t.Fatalf("me = %+v", me)
+	}
+
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/admin/skill-gaps", alice.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/admin/roles/auditor", admin.Token, map[string]any{
+		"permissions": []string{"users.view", "analytics.view"},
+	})
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/skill-gaps", alice.Token, nil)
+
+	// Built-in roles stay, the admin role keeps every permission and the
+	// last admin keeps the role
+	srv.Expect(t, http.StatusConflict, http.MethodDelete, "/api/admin/roles/mentor", admin.Token, nil)
+	srv.Expect(t, http.StatusConflict, http.MethodPut, "/api/admin/roles/admin", admin.Token, map[string]any{"permissions": []string{}})
+	srv.Expect(t, http.StatusConflict, http.MethodPut, "/api/admin/users/"+admin.User.ID+"/role", admin.Token, map[string]string{"role": "employee"})
+
+	srv.Expect(t, http.StatusConflict, http.MethodDelete, "/api/admin/roles/auditor", admin.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPut, rolePath, admin.Token, map[string]string{"role": "employee"})
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, "/api/admin/roles/auditor", admin.Token, nil)
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/admin/roles/auditor", admin.Token, nil)
+	srv.Expect(t, http.StatusForbidden, http.MethodGet, "/api/users", alice.Token, nil)
+}
@@ -0,0 +1,316 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/scim"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+type SCIMHandler struct {
+	scimService *service.SCIMService
+}
+
+func NewSCIMHandler(scimService *service.SCIMService) *SCIMHandler {
+	return &SCIMHandler{
+		scimService: scimService,
+	}
+}
+
+// ListUsers handles GET /scim/v2/Users (SCIM token)
+func (h *SCIMHandler) ListUsers(c *gin.Context) {
+	startIndex, count, ok := scimPageParams(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.scimService.ListUsers(c.Request.Context(), c.Query("filter"), startIndex, count)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	for i := range list.Resources {
+		setLocation(c, list.Resources[i].Meta, "Users", list.Resources[i].ID)
+	}
+
+	respondSCIM(c, http.StatusOK, list)
+}
+
+// CreateUser handles POST /scim/v2/Users (SCIM token)
+func (h *SCIMHandler) CreateUser(c *gin.Context) {
+	var req scim.User
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	user, err := h.scimService.CreateUser(c.Request.Context(), req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, user.Meta, "Users", user.ID)
+
+	c.Header("Location", user.Meta.Location)
+	respondSCIM(c, http.StatusCreated, user)
+}
+
+// GetUser handles GET /scim/v2/Users/:id (SCIM token)
+func (h *SCIMHandler) GetUser(c *gin.Context) {
+	user, err := h.scimService.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, user.Meta, "Users", user.ID)
+
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// ReplaceUser handles PUT /scim/v2/Users/:id (SCIM token)
+func (h *SCIMHandler) ReplaceUser(c *gin.Context) {
+	var req scim.User
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	user, err := h.scimService.ReplaceUser(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, user.Meta, "Users", user.ID)
+
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// PatchUser handles PATCH /scim/v2/Users/:id (SCIM token)
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+	var req scim.PatchRequest
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	user, err := h.scimService.PatchUser(c.Request.Context(), c.Param("id"), req.Operations)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, user.Meta, "Users", user.ID)
+
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// DeleteUser handles DELETE /scim/v2/Users/:id (SCIM token). The account is
+// deactivated rather than deleted, so its history is kept.
+func (h *SCIMHandler) DeleteUser(c *gin.Context) {
+	if err := h.scimService.DeprovisionUser(c.Request.Context(), c.Param("id")); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListGroups handles GET /scim/v2/Groups (SCIM token)
+func (h *SCIMHandler) ListGroups(c *gin.Context) {
+	startIndex, count, ok := scimPageParams(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.scimService.ListGroups(c.Request.Context(), c.Query("filter"), startIndex, count)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	for i := range list.Resources {
+		setLocation(c, list.Resources[i].Meta, "Groups", list.Resources[i].ID)
+	}
+
+	respondSCIM(c, http.StatusOK, list)
+}
+
+// CreateGroup handles POST /scim/v2/Groups (SCIM token)
+func (h *SCIMHandler) CreateGroup(c *gin.Context) {
+	var req scim.Group
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	group, err := h.scimService.CreateGroup(c.Request.Context(), req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, group.Meta, "Groups", group.ID)
+
+	c.Header("Location", group.Meta.Location)
+	respondSCIM(c, http.StatusCreated, group)
+}
+
+// GetGroup handles GET /scim/v2/Groups/:id (SCIM token)
+func (h *SCIMHandler) GetGroup(c *gin.Context) {
+	group, err := h.scimService.GetGroup(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, group.Meta, "Groups", group.ID)
+
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// ReplaceGroup handles PUT /scim/v2/Groups/:id (SCIM token)
+func (h *SCIMHandler) ReplaceGroup(c *gin.Context) {
+	var req scim.Group
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	group, err := h.scimService.ReplaceGroup(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, group.Meta, "Groups", group.ID)
+
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// PatchGroup handles PATCH /scim/v2/Groups/:id (SCIM token)
+func (h *SCIMHandler) PatchGroup(c *gin.Context) {
+	var req scim.PatchRequest
+	if !bindSCIM(c, &req) {
+		return
+	}
+
+	group, err := h.scimService.PatchGroup(c.Request.Context(), c.Param("id"), req.Operations)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	setLocation(c, group.Meta, "Groups", group.ID)
+
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// DeleteGroup handles DELETE /scim/v2/Groups/:id (SCIM token)
+func (h *SCIMHandler) DeleteGroup(c *gin.Context) {
+	if err := h.scimService.DeleteGroup(c.Request.Context(), c.Param("id")); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig
+// (SCIM token)
+func (h *SCIMHandler) GetServiceProviderConfig(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, gin.H{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": service.SCIMMaxResults},
+		"changePassword": gin.H{"supported": true},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "The SCIM token of the server configuration",
+		}},
+	})
+}
+
+// GetResourceTypes handles GET /scim/v2/ResourceTypes (SCIM token)
+func (h *SCIMHandler) GetResourceTypes(c *gin.Context) {
+	types := []gin.H{
+		{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   scim.SchemaUser,
+			"schemaExtensions": []gin.H{
+				{"schema": scim.SchemaEnterpriseUser, "required": false},
+			},
+		},
+		{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   scim.SchemaGroup,
+		},
+	}
+	respondSCIM(c, http.StatusOK, scim.NewListResponse(types, 1, len(types)))
+}
+
+// scimPageParams reads startIndex and count, answering 400 when malformed
+func scimPageParams(c *gin.Context) (int, int, bool) {
+	startIndex, count := 1, service.SCIMMaxResults
+	for name, target := range map[string]*int{"startIndex": &startIndex, "count": &count} {
+		if raw := c.Query(name); raw != "" {
+			v, err := strconv.Atoi(raw)
+			if err != nil {
+				respondSCIM(c, http.StatusBadRequest, scim.NewError(http.StatusBadRequest, "invalidValue", name+" must be an integer"))
+				return 0, 0, false
+			}
+			*target = v
+		}
+	}
+	return startIndex, count, true
+}
+
+// bindSCIM decodes a request body, answering 400 when malformed
+func bindSCIM(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		respondSCIM(c, http.StatusBadRequest, scim.NewError(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return false
+	}
+	return true
+}
+
+// setLocation sets the URL of a resource in its meta
+func setLocation(c *gin.Context, meta *scim.Meta, endpoint, id string) {
+	if meta == nil {
+		return
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	meta.Location = scheme + "://" + c.Request.Host + "/scim/v2/" + endpoint + "/" + id
+}
+
+// respondSCIM writes a JSON response with the SCIM content type
+func respondSCIM(c *gin.Context, status int, body any) {
+	// gin keeps a content type that is already set
+	c.Header("Content-Type", scim.MediaType+"; charset=utf-8")
+	c.JSON(status, body)
+}
+
+func respondSCIMError(c *gin.Context, err error) {
+	status, scimType := http.StatusInternalServerError, ""
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrGroupNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrUserAlreadyExists), errors.Is(err, domain.ErrGroupExists):
+		status, scimType = http.StatusConflict, "uniqueness"
+	case scim.ErrorType(err) != "":
+		status, scimType = http.StatusBadRequest, scim.ErrorType(err)
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrInvalidEmail),
+		errors.Is(err, domain.ErrWeakPassword),
+		errors.Is(err, domain.ErrManagerCycle),
+		errors.Is(err, domain.ErrUserDeactivated),
+		errors.Is(err, domain.ErrLastAdmin):
+		status, scimType = http.StatusBadRequest, "invalidValue"
+	}
+	respondSCIM(c, status, scim.NewError(status, scimType, err.Error()))
+}
@@ -0,0 +1,97 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/pkg/scim"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestSCIM(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	token := apitest.SCIMToken
+
+	// User JWTs, even of admins, do not grant provisioning access
+	resp := srv.Expect(t, http.StatusUnauthorized, http.MethodGet, "/scim/v2/Users", admin.Token, nil)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, scim.MediaType) {
+		t.Errorf("content type = %q", ct)
+	}
+
+	var created scim.User
+	resp = srv.Expect(t, http.StatusCreated, http.MethodPost, "/scim/v2/Users", token, map[string]any{
+		"schemas":    []string{scim.SchemaUser, scim.SchemaEnterpriseUser},
+		"userName":   "carol@example.com",
+		"externalId": "okta-1",
+		"name":       map[string]string{"givenName": "Carol", "familyName": "Ng"},
+		"active":     true,
+		scim.SchemaEnterpriseUser: map[string]any{
+			"department": "R&D",
+			"manager":    map[string]string{"value": admin.User.ID},
+		},
+	})
+	resp.Decode(t, &created)
+	if resp.Header.Get("Location") != "http://example.com/scim/v2/Users/"+created.ID || created.Meta.Location != resp.Header.Get("Location") {
+		t.Errorf("location = %q, meta = %+v", resp.Header.Get("Location"), created.Meta)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/scim/v2/Users", token, map[string]any{
+		"userName": "carol@example.com", "displayName": "Carol",
+	})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/scim/v2/Users", token, map[string]any{
+		"userName": "carol", "displayName": "Carol",
+	})
+
+	var list scim.ListResponse[scim.User]
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`externalId eq "okta-1"`), token, nil).Decode(t, &list)
+	if list.TotalResults != 1 || list.Resources[0].ID != created.ID || list.Resources[0].Enterprise.Department != "R&D" {
+		t.Fatalf("list = %+v", list)
+	}
+	var scimErr scim.Error
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`userName zz "x"`), token, nil).Decode(t, &scimErr)
+	if scimErr.ScimType != "invalidFilter" || scimErr.Status != "400" {
+		t.Errorf("error = %+v", scimErr)
+	}
+
+	srv.Expect(t, http.StatusOK, http.MethodPatch, "/scim/v2/Users/"+created.ID, token, map[string]any{
+		"schemas":    []string{scim.SchemaPatchOp},
+		"Operations": []map[string]any{{"op": "replace", "path": "title", "value": "Engineer"}},
+	})
+
+	var group scim.Group
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/scim/v2/Groups", token, map[string]any{
+		"schemas":     []string{scim.SchemaGroup},
+		"displayName": "Platform",
+		"members":     []map[string]string{{"value": created.ID}},
+	}).Decode(t, &group)
+	srv.Expect(t, http.StatusOK, http.MethodPatch, "/scim/v2/Groups/role-admin", token, map[string]any{
+		"Operations": []map[string]any{{"op": "add", "path": "members", "value": []map[string]string{{"value": created.ID}}}},
+	})
+
+	// The provisioned profile shows through the regular API
+	var carol struct {
+		Role       string  `json:"role"`
+		Department *string `json:"department"`
+		JobTitle   *string `json:"jobTitle"`
+		ManagerID  *string `json:"managerId"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/users/"+created.ID, admin.Token, nil).Decode(t, &carol)
+	if carol.Role != "admin" || *carol.Department != "Platform" || *carol.JobTitle != "Engineer" || *carol.ManagerID != admin.User.ID {
+		t.Fatalf("user = %+v", carol)
+	}
+
+	// Deprovisioning deactivates the account and keeps it
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, "/scim/v2/Users/"+created.ID, token, nil)
+	var got scim.User
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/scim/v2/Users/"+created.ID, token, nil).Decode(t, &got)
+	if got.Active == nil || bool(*got.Active) {
+		t.Fatalf("deprovisioned user = %+v", got)
+	}
+	srv.Expect(t, http.StatusNotFound, http.MethodDelete, "/scim/v2/Users/"+apitest.MissingID(), token, nil)
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/scim/v2/Groups/role-owner", token, nil)
+	srv.Expect(t, http.StatusBadRequest, http.MethodDelete, "/scim/v2/Groups/role-admin", token, nil)
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, "/scim/v2/Groups/"+group.ID, token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/scim/v2/ServiceProviderConfig", token, nil)
+}
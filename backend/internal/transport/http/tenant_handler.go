@@ -0,0 +1,207 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type TenantHandler struct {
+	tenantService *service.TenantService
+}
+
+func NewTenantHandler(tenantService *service.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+// ListTenants handles GET /api/super/tenants (super admin token)
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListTenants(c.Request.Context())
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	for i, tenant := range tenants {
+		tenants[i] = redactTenant(tenant)
+	}
+	c.JSON(http.StatusOK, gin.H{"tenants": tenants})
+}
+
+// CreateTenant handles POST /api/super/tenants (super admin token); the
+// tenant starts with the built-in roles, the default questionnaires and
+// the given admin
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req dto.CreateTenantDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant := &domain.Tenant{
+		Slug:     req.Slug,
+		Name:     req.Name,
+		Hosts:    req.Hosts,
+		Settings: settingsFromDTO(req.Settings),
+	}
+	tenant, admin, err := h.tenantService.CreateTenant(c.Request.Context(), tenant, service.TenantAdmin{
+		Name:     req.Admin.Name,
+		Email:    req.Admin.Email,
+		Password: req.Admin.Password,
+	})
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"tenant": redactTenant(tenant), "admin": admin})
+}
+
+// GetTenant handles GET /api/super/tenants/:id (super admin token)
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenant, err := h.tenantService.GetTenant(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, redactTenant(tenant))
+}
+
+// UpdateTenant handles PUT /api/super/tenants/:id (super admin token)
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	var req dto.UpdateTenantDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant, err := h.tenantService.UpdateTenant(c.Request.Context(), c.Param("id"), req.Name, req.Hosts, settingsFromDTO(req.Settings))
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, redactTenant(tenant))
+}
+
+// GetCurrentTenant handles GET /api/tenant (public); the frontend brands
+// its pages with it before anyone logs in
+func (h *TenantHandler) GetCurrentTenant(c *gin.Context) {
+	tenant, err := h.tenantService.CurrentTenant(c.Request.Context())
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	b := tenant.Settings.Branding
+	c.JSON(http.StatusOK, dto.TenantBrandingResponseDTO{
+		ID:   tenant.ID,
+		Slug: tenant.Slug,
+		Name: tenant.Name,
+		Branding: dto.BrandingDTO{
+			DisplayName:  b.DisplayName,
+			LogoURL:      b.LogoURL,
+			PrimaryColor: b.PrimaryColor,
+		},
+	})
+}
+
+// GetSettings handles GET /api/admin/settings (settings.manage)
+func (h *TenantHandler) GetSettings(c *gin.Context) {
+	tenant, err := h.tenantService.CurrentTenant(c.Request.Context())
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant.Settings.Redacted())
+}
+
+// UpdateSettings handles PUT /api/admin/settings (settings.manage)
+func (h *TenantHandler) UpdateSettings(c *gin.Context) {
+	var req dto.TenantSettingsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant, err := h.tenantService.UpdateSettings(c.Request.Context(), settingsFromDTO(req))
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant.Settings.Redacted())
+}
+
+// IssueSCIMToken handles POST /api/admin/settings/scim-token
+// (settings.manage); the token replaces the one before and is only shown
+// in this response
+func (h *TenantHandler) IssueSCIMToken(c *gin.Context) {
+	token, err := h.tenantService.IssueSCIMToken(c.Request.Context())
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SCIMTokenResponseDTO{Token: token})
+}
+
+// RevokeSCIMToken handles DELETE /api/admin/settings/scim-token
+// (settings.manage), turning SCIM provisioning off
+func (h *TenantHandler) RevokeSCIMToken(c *gin.Context) {
+	if err := h.tenantService.RevokeSCIMToken(c.Request.Context()); err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func settingsFromDTO(req dto.TenantSettingsDTO) domain.TenantSettings {
+	return domain.TenantSettings{
+		MentorCapacity: req.MentorCapacity,
+		Branding: domain.Branding{
+			DisplayName:  req.Branding.DisplayName,
+			LogoURL:      req.Branding.LogoURL,
+			PrimaryColor: req.Branding.PrimaryColor,
+		},
+		HRIS: domain.HRISSettings{
+			URL:        req.HRIS.URL,
+			Token:      req.HRIS.Token,
+			RecordsKey: req.HRIS.RecordsKey,
+			Fields:     req.HRIS.Fields,
+			Invite:     req.HRIS.Invite,
+		},
+	}
+}
+
+// redactTenant returns a copy of the tenant without the secrets of its
+// settings
+func redactTenant(tenant *domain.Tenant) *domain.Tenant {
+	redacted := *tenant
+	redacted.Settings = tenant.Settings.Redacted()
+	return &redacted
+}
+
+func respondTenantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrTenantExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrInvalidEmail),
+		errors.Is(err, domain.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
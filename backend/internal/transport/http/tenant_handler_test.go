@@ -0,0 +1,237 @@
+package http_test
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+func TestMultiTenancy(t *testing.T) {
+	srv := apitest.New(t)
+	root := srv.Admin(t, "root")
+	super := apitest.SuperAdminToken
+	const acmeHost = "learning.acme.example"
+
+	// Operators create tenants with the super admin token
+	acmeBody := map[string]any{
+		"slug":     "acme",
+		"name":     "Acme Corp",
+		"hosts":    []string{"Learning.Acme.example"},
+		"settings": map[string]any{"mentorCapacity": 3, "branding": map[string]string{"displayName": "Acme Academy"}},
+		"admin":    map[string]string{"name": "Jane", "email": "jane@acme.example", "password": "s3cret-pass"},
+	}
+	srv.Expect(t, http.StatusUnauthorized, http.MethodPost, "/api/super/tenants", "wrong-token", acmeBody)
+	var created struct {
+		Tenant domain.Tenant `json:"tenant"`
+		Admin  domain.User   `json:"admin"`
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/super/tenants", super, acmeBody).Decode(t, &created)
+	if created.Tenant.Slug != "acme" || !slices.Equal(created.Tenant.Hosts, []string{acmeHost}) || created.Admin.Role != domain.RoleAdmin {
+		t.Fatalf("created = %+v", created)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/super/tenants", super, acmeBody)
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/api/super/tenants", super, map[string]any{
+		"slug": "globex", "name": "Globex", "hosts": []string{acmeHost}, "admin": acmeBody["admin"],
+	})
+	var list struct {
+		Tenants []domain.Tenant `json:"tenants"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/super/tenants", super, nil).Decode(t, &list)
+	if len(list.Tenants) != 2 || list.Tenants[0].ID != domain.DefaultTenantID {
+		t.Errorf("tenants = %+v", list.Tenants)
+	}
+
+	// The host picks the tenant for pages shown before login
+	var branding dto.TenantBrandingResponseDTO
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost+":443", http.MethodGet, "/api/tenant", "", nil).Decode(t, &branding)
+	if branding.Slug != "acme" || branding.Branding.DisplayName != "Acme Academy" {
+		t.Errorf("acme branding = %+v", branding)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/tenant", "", nil).Decode(t, &branding)
+	if branding.ID != domain.DefaultTenantID {
+		t.Errorf("unknown host branding = %+v", branding)
+	}
+
+	// Accounts exist in one tenant only
+	credentials := map[string]string{"email": "jane@acme.example", "password": "s3cret-pass"}
+	srv.Expect(t, http.StatusUnauthorized, http.MethodPost, "/api/auth/login", "", credentials)
+	var login struct {
+		Token string `json:"token"`
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodPost, "/api/auth/login", "", credentials).Decode(t, &login)
+	jane := login.Token
+
+	var users struct {
+		Users []domain.User `json:"users"`
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodGet, "/api/users", jane, nil).Decode(t, &users)
+	if len(users.Users) != 1 || users.Users[0].ID != created.Admin.ID {
+		t.Errorf("acme users = %+v", users.Users)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/users", root.Token, nil).Decode(t, &users)
+	for _, u := range users.Users {
+		if u.ID == created.Admin.ID {
+			t.Error("the default tenant lists the acme admin")
+		}
+	}
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/users/"+root.User.ID, jane, nil)
+
+	// A token works on hosts no tenant claims, but not on another tenant's
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/auth/me", jane, nil)
+	resp := srv.ExpectOnHost(t, http.StatusUnauthorized, acmeHost, http.MethodGet, "/api/auth/me", root.Token, nil)
+	if !strings.Contains(string(resp.Body), domain.ErrTenantMismatch.Error()) {
+		t.Errorf("mismatch body = %s", resp.Body)
+	}
+
+	// New mentors take the capacity from the tenant settings
+	mentorBody := map[string]any{"name": "Max", "jobTitle": "Lead", "email": "max@acme.example"}
+	var mentor domain.Mentor
+	srv.ExpectOnHost(t, http.StatusCreated, acmeHost, http.MethodPost, "/api/mentors", jane, mentorBody).Decode(t, &mentor)
+	if mentor.Capacity != 3 {
+		t.Errorf("capacity = %d, want the tenant's 3", mentor.Capacity)
+	}
+	var settings domain.TenantSettings
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodPut, "/api/admin/settings", jane, map[string]any{
+		"mentorCapacity": 6,
+		"branding":       map[string]string{"displayName": "Acme Academy", "primaryColor": "#ff6600"},
+	}).Decode(t, &settings)
+	if settings.MentorCapacity != 6 || settings.Branding.PrimaryColor != "#ff6600" {
+		t.Errorf("settings = %+v", settings)
+	}
+	srv.ExpectOnHost(t, http.StatusBadRequest, acmeHost, http.MethodPut, "/api/admin/settings", jane, map[string]any{
+		"branding": map[string]string{"primaryColor": "orange"},
+	})
+	mentorBody["email"] = "mia@acme.example"
+	srv.ExpectOnHost(t, http.StatusCreated, acmeHost, http.MethodPost, "/api/mentors", jane, mentorBody).Decode(t, &mentor)
+	if mentor.Capacity != 6 {
+		t.Errorf("capacity = %d after the settings change, want 6", mentor.Capacity)
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodPut, "/api/mentors/"+mentor.ID, jane, map[string]any{
+		"name": "Mia", "jobTitle": "Lead", "experience": "8 years", "email": "mia@acme.example", "workload": 8, "capacity": 10,
+	})
+	srv.ExpectOnHost(t, http.StatusBadRequest, acmeHost, http.MethodPut, "/api/mentors/"+mentor.ID, jane, map[string]any{
+		"name": "Mia", "jobTitle": "Lead", "experience": "8 years", "email": "mia@acme.example", "workload": 9, "capacity": 8,
+	})
+
+	// The default tenant keeps its own settings and mentors
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/settings", root.Token, nil).Decode(t, &settings)
+	if settings.MentorCapacity != domain.DefaultMentorCapacity {
+		t.Errorf("default settings = %+v", settings)
+	}
+	var mentors []domain.Mentor
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/mentors", root.Token, nil).Decode(t, &mentors)
+	if len(mentors) != 0 {
+		t.Errorf("default tenant lists %d acme mentors", len(mentors))
+	}
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/mentors/"+mentor.ID, root.Token, nil)
+
+	// The tenant starts with its own questionnaires
+	var questionnaires struct {
+		Questionnaires []domain.Questionnaire `json:"questionnaires"`
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodGet, "/api/admin/questionnaires", jane, nil).Decode(t, &questionnaires)
+	if len(questionnaires.Questionnaires) != len(domain.DefaultQuestionnaires()) {
+		t.Errorf("%d acme questionnaires", len(questionnaires.Questionnaires))
+	}
+
+	// Moving a tenant to another host
+	var tenant domain.Tenant
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/super/tenants/"+created.Tenant.ID, super, map[string]any{
+		"name": "Acme Inc", "hosts": []string{"acme.example"}, "settings": settings,
+	}).Decode(t, &tenant)
+	if tenant.Slug != "acme" || tenant.Name != "Acme Inc" || !slices.Equal(tenant.Hosts, []string{"acme.example"}) {
+		t.Errorf("updated tenant = %+v", tenant)
+	}
+	srv.ExpectOnHost(t, http.StatusOK, "acme.example", http.MethodPost, "/api/auth/login", "", credentials)
+	srv.ExpectOnHost(t, http.StatusUnauthorized, acmeHost, http.MethodPost, "/api/auth/login", "", credentials)
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, "/api/super/tenants/"+apitest.MissingID(), super, nil)
+}
+
+func TestTenantIntegrations(t *testing.T) {
+	srv := apitest.New(t)
+	root := srv.Admin(t, "root")
+	employee := srv.Employee(t, "emma")
+	const acmeHost = "learning.acme.example"
+
+	var created struct {
+		Tenant domain.Tenant `json:"tenant"`
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/super/tenants", apitest.SuperAdminToken, map[string]any{
+		"slug":  "acme",
+		"name":  "Acme Corp",
+		"hosts": []string{acmeHost},
+		"admin": map[string]string{"name": "Jane", "email": "jane@acme.example", "password": "s3cret-pass"},
+	}).Decode(t, &created)
+	var login struct {
+		Token string `json:"token"`
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email": "jane@acme.example", "password": "s3cret-pass",
+	}).Decode(t, &login)
+	jane := login.Token
+
+	// Tenant admins issue the SCIM token of their tenant, shown once
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, "/api/admin/settings/scim-token", employee.Token, nil)
+	var issued dto.SCIMTokenResponseDTO
+	srv.ExpectOnHost(t, http.StatusCreated, acmeHost, http.MethodPost, "/api/admin/settings/scim-token", jane, nil).Decode(t, &issued)
+	if issued.Token == "" {
+		t.Fatal("no SCIM token issued")
+	}
+
+	// The token provisions its tenant, on any host but another tenant's
+	carol := map[string]any{"userName": "carol@acme.example", "displayName": "Carol"}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/scim/v2/Users", issued.Token, carol)
+	var users struct {
+		Users []domain.User `json:"users"`
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodGet, "/api/users", jane, nil).Decode(t, &users)
+	if len(users.Users) != 2 {
+		t.Errorf("acme users = %+v", users.Users)
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/users", root.Token, nil).Decode(t, &users)
+	for _, u := range users.Users {
+		if u.Email == "carol@acme.example" {
+			t.Error("SCIM provisioned acme's user into the default tenant")
+		}
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodGet, "/scim/v2/Users", issued.Token, nil)
+
+	// The configured token provisions the default tenant only
+	resp := srv.ExpectOnHost(t, http.StatusUnauthorized, acmeHost, http.MethodGet, "/scim/v2/Users", apitest.SCIMToken, nil)
+	if !strings.Contains(string(resp.Body), domain.ErrTenantMismatch.Error()) {
+		t.Errorf("mismatch body = %s", resp.Body)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, "/scim/v2/Users", issued.Token, carol)
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/scim/v2/Users", apitest.SCIMToken, carol)
+
+	// Settings show secrets redacted and keep them when sent back
+	var settings domain.TenantSettings
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodPut, "/api/admin/settings", jane, map[string]any{
+		"hris": map[string]any{"url": "https://hris.acme.example/export", "token": "hris-token", "recordsKey": "staff"},
+	}).Decode(t, &settings)
+	if settings.HRIS.Token != domain.RedactedSecret || settings.SCIM.TokenHash != domain.RedactedSecret {
+		t.Errorf("settings = %+v", settings)
+	}
+	srv.ExpectOnHost(t, http.StatusOK, acmeHost, http.MethodPut, "/api/admin/settings", jane, settings)
+	srv.ExpectOnHost(t, http.StatusBadRequest, acmeHost, http.MethodPut, "/api/admin/settings", jane, map[string]any{
+		"hris": map[string]any{"url": "/etc/passwd"},
+	})
+	var tenant domain.Tenant
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/super/tenants/"+created.Tenant.ID, apitest.SuperAdminToken, nil).Decode(t, &tenant)
+	if tenant.Settings.HRIS.Token != domain.RedactedSecret || tenant.Settings.SCIM.TokenHash != domain.RedactedSecret {
+		t.Errorf("tenant settings = %+v", tenant.Settings)
+	}
+	stored, err := srv.Tenants.GetByID(defaultCtx(), created.Tenant.ID)
+	if err != nil || stored.Settings.HRIS.Token != "hris-token" || stored.Settings.SCIM.TokenHash == "" {
+		t.Errorf("stored settings = %+v, %v", stored.Settings, err)
+	}
+
+	// Revoking the token turns provisioning off
+	srv.ExpectOnHost(t, http.StatusNoContent, acmeHost, http.MethodDelete, "/api/admin/settings/scim-token", jane, nil)
+	srv.Expect(t, http.StatusUnauthorized, http.MethodGet, "/scim/v2/Users", issued.Token, nil)
+}
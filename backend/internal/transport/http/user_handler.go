@@ -1,39 +1,55 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
 	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/middleware"
 )
 
 type UserHandler struct {
-	userService     *service.UserService
-	learningService *service.LearningService
-	requestService  *service.RequestService
+	userService       *service.UserService
+	learningService   *service.LearningService
+	requestService    *service.RequestService
+	departmentService *service.DepartmentService
 }
 
-func NewUserHandler(userService *service.UserService, learningService *service.LearningService, requestService *service.RequestService) *UserHandler {
+func NewUserHandler(userService *service.UserService, learningService *service.LearningService, requestService *service.RequestService, departmentService *service.DepartmentService) *UserHandler {
 	return &UserHandler{
-		userService:     userService,
-		learningService: learningService,
-		requestService:  requestService,
+		userService:       userService,
+		learningService:   learningService,
+		requestService:    requestService,
+		departmentService: departmentService,
 	}
 }
 
-// GetAllUsers handles GET /api/users (admin only)
+// GetAllUsers handles GET /api/users (users.view); department heads see the
+// members of their departments
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	scope, err := h.departmentService.Scope(c.Request.Context(), userID.(string), domain.PermUsersView)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	users, err := h.userService.GetAllUsers(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	users = slices.DeleteFunc(users, func(u *domain.User) bool { return !scope.Includes(u) })
 
 	c.JSON(http.StatusOK, gin.H{"users": users})
 }
 
-// GetUserByID handles GET /api/users/:id (admin only)
+// GetUserByID handles GET /api/users/:id (owner or users.view)
 func (h *UserHandler) GetUserByID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -46,9 +62,13 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// UpdateUserByID handles PUT /api/users/:id (admin only)
+// UpdateUserByID handles PUT /api/users/:id (owner or users.manage). Only
+// organisation-wide users.manage changes the email or password of others,
+// nobody edits a user whose role grants more than theirs, and a department
+// head cannot move a user out of their departments.
 func (h *UserHandler) UpdateUserByID(c *gin.Context) {
 	id := c.Param("id")
+	actorID, _ := c.Get("userID")
 
 	var req dto.UpdateUserDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -56,6 +76,35 @@ func (h *UserHandler) UpdateUserByID(c *gin.Context) {
 		return
 	}
 
+	target, err := h.userService.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if actorID.(string) != id {
+		credentials := (req.Email != nil && *req.Email != target.Email) || (req.Password != nil && *req.Password != "")
+		if credentials && !middleware.Can(c, domain.PermUsersManage) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "changing the email or password of another user requires organisation-wide " + string(domain.PermUsersManage)})
+			return
+		}
+		ok, err := h.departmentService.Covers(c.Request.Context(), actorID.(string), id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrRoleNotCovered.Error()})
+			return
+		}
+		if req.Department != nil && !allowDepartmentChange(c, h.departmentService, actorID.(string), target, *req.Department) {
+			return
+		}
+	}
+
 	user, err := h.userService.UpdateUser(
 		c.Request.Context(),
 		id,
@@ -74,7 +123,7 @@ func (h *UserHandler) UpdateUserByID(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// GetUserRequests handles GET /api/users/:id/requests (admin only)
+// GetUserRequests handles GET /api/users/:id/requests (owner or users.view)
 func (h *UserHandler) GetUserRequests(c *gin.Context) {
 	userID := c.Param("id")
 
@@ -88,7 +137,7 @@ func (h *UserHandler) GetUserRequests(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"requests": responseDTOs})
 }
 
-// GetUserLearnings handles GET /api/users/:id/learnings (admin only)
+// GetUserLearnings handles GET /api/users/:id/learnings (owner or users.view)
 func (h *UserHandler) GetUserLearnings(c *gin.Context) {
 	userID := c.Param("id")
 
@@ -101,3 +150,94 @@ func (h *UserHandler) GetUserLearnings(c *gin.Context) {
 	responseDTOs := dto.ToLearningResponseDTOs(learnings)
 	c.JSON(http.StatusOK, gin.H{"learnings": responseDTOs})
 }
+
+// DeactivateUser handles POST /api/users/:id/deactivate (users.manage over the user)
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	id := c.Param("id")
+	adminID, _ := c.Get("userID")
+
+	user, err := h.userService.DeactivateUser(c.Request.Context(), id, adminID.(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCannotDeactivateSelf):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrLastAdmin):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ReactivateUser handles POST /api/users/:id/reactivate (users.manage over the user)
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	id := c.Param("id")
+
+	user, err := h.userService.ReactivateUser(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// SetManager handles PUT /api/users/:id/manager (users.manage over the user)
+func (h *UserHandler) SetManager(c *gin.Context) {
+	var req dto.SetManagerDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.SetManager(c.Request.Context(), c.Param("id"), req.ManagerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrManagerCycle),
+			errors.Is(err, domain.ErrUserDeactivated):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// allowDepartmentChange checks that the actor's users.manage reaches the
+// department another user is to be moved into, answering 403 when it does
+// not and 400 for an unknown department; sending back the current
+// department is always fine.
+func allowDepartmentChange(c *gin.Context, departments *service.DepartmentService, actorID string, user *domain.User, department string) bool {
+	department = strings.TrimSpace(department)
+	if user.Department == nil && department == "" || user.Department != nil && strings.EqualFold(*user.Department, department) {
+		return true
+	}
+
+	ok, err := departments.ReachesDepartment(c.Request.Context(), actorID, domain.PermUsersManage, department)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "department is outside your departments"})
+		return false
+	}
+	return true
+}
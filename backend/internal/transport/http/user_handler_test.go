@@ -0,0 +1,99 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestUserDeactivation(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	credentials := map[string]string{"email": "leaver@example.com", "password": "password123"}
+
+	var leaver struct {
+		ID string `json:"id"`
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/auth/register", "", map[string]string{
+		"name":     "Leaver",
+		"email":    "leaver@example.com",
+		"password": "password123",
+	}).Decode(t, &leaver)
+	var login struct {
+		Token string `json:"token"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/auth/login", "", credentials).Decode(t, &login)
+
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/users/"+admin.User.ID+"/deactivate", admin.Token, nil)
+	srv.Expect(t, http.StatusNotFound, http.MethodPost, "/api/users/"+apitest.MissingID()+"/deactivate", admin.Token, nil)
+
+	var deactivated struct {
+		DeactivatedAt *time.Time `json:"deactivatedAt"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/users/"+leaver.ID+"/deactivate", admin.Token, nil).Decode(t, &deactivated)
+	if deactivated.DeactivatedAt == nil {
+		t.Fatal("deactivatedAt not set")
+	}
+
+	// Tokens issued before deactivation stop working immediately
+	srv.Expect(t, http.StatusUnauthorized, http.MethodGet, "/api/auth/me", login.Token, nil)
+	srv.Expect(t, http.StatusUnauthorized, http.MethodPost, "/api/auth/login", "", credentials)
+
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/users/"+leaver.ID+"/reactivate", admin.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/auth/me", login.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/auth/login", "", credentials)
+}
+
+func TestAdministeringUsersWithMorePermissions(t *testing.T) {
+	srv := apitest.New(t)
+	root := srv.Admin(t, "root")
+	boss := srv.Admin(t, "boss")
+	hank := srv.WithRole(t, "hank", domain.RoleDepartmentHead)
+	alice := srv.Employee(t, "alice")
+	srv.Department(t, "Engineering", hank.User, boss.User, alice.User)
+	srv.Department(t, "Sales", nil)
+
+	// Hank heads the department of an admin but cannot take the account over
+	bossPath := "/api/users/" + boss.User.ID
+	for _, body := range []map[string]string{
+		{"password": "hijacked-password"},
+		{"email": "hank+boss@example.com"},
+		{"name": "Boss"},
+	} {
+		srv.Expect(t, http.StatusForbidden, http.MethodPut, bossPath, hank.Token, body)
+	}
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, bossPath+"/deactivate", hank.Token, nil)
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, bossPath+"/reactivate", hank.Token, nil)
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, bossPath+"/manager", hank.Token, map[string]string{"managerId": hank.User.ID})
+
+	// He edits the profiles of the employees in it, but not how they sign in
+	alicePath := "/api/users/" + alice.User.ID
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, alicePath, hank.Token, map[string]string{"password": "hijacked-password"})
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, alicePath, hank.Token, map[string]string{"email": "hank+alice@example.com"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, alicePath, hank.Token, map[string]string{"jobTitle": "Engineer", "email": "alice@example.com"})
+	srv.Expect(t, http.StatusOK, http.MethodPost, alicePath+"/deactivate", hank.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPost, alicePath+"/reactivate", hank.Token, nil)
+
+	// Nobody moves someone else into a department out of their reach, but
+	// everyone picks their own department
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, alicePath, hank.Token, map[string]string{"department": "Sales"})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPut, alicePath, hank.Token, map[string]string{"department": "Marketing"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, alicePath, hank.Token, map[string]string{"department": "engineering", "name": "Alice"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, alicePath, alice.Token, map[string]string{"department": "Sales"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/auth/me", hank.Token, map[string]string{"department": "Sales"})
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/users/"+root.User.ID, root.Token, map[string]string{"department": "Sales"})
+
+	// Organisation-wide users.manage resets passwords, except of admins
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/admin/roles", root.Token, map[string]any{
+		"name": "people_ops", "permissions": []string{"users.view", "users.manage"},
+	})
+	pat := srv.WithRole(t, "pat", "people_ops")
+	srv.Expect(t, http.StatusOK, http.MethodPut, alicePath, pat.Token, map[string]string{"password": "new-password"})
+	srv.Expect(t, http.StatusForbidden, http.MethodPut, bossPath, pat.Token, map[string]string{"password": "hijacked-password"})
+	srv.Expect(t, http.StatusForbidden, http.MethodPost, bossPath+"/deactivate", pat.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPut, bossPath, root.Token, map[string]string{"password": "new-password"})
+	srv.Expect(t, http.StatusNotFound, http.MethodPut, "/api/users/"+apitest.MissingID(), pat.Token, map[string]string{"name": "Nobody"})
+}
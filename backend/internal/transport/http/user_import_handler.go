@@ -0,0 +1,113 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+)
+
+// maxImportSize caps the size of an uploaded user import
+const maxImportSize = 10 << 20
+
+type UserImportHandler struct {
+	importService *service.UserImportService
+}
+
+func NewUserImportHandler(importService *service.UserImportService) *UserImportHandler {
+	return &UserImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportUsers handles POST /api/admin/users/import?dryRun=&invite=
+// (users.manage). The rows are a CSV or JSON file in the "file" field of a
+// multipart form, or the request body with a text/csv or application/json
+// content type.
+func (h *UserImportHandler) ImportUsers(c *gin.Context) {
+	var opts domain.UserImportOptions
+	for name, target := range map[string]*bool{"dryRun": &opts.DryRun, "invite": &opts.Invite} {
+		if value := c.Query(name); value != "" {
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be true or false"})
+				return
+			}
+			*target = flag
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	content, isJSON, err := importContent(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": domain.ErrFileTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	var rows []domain.UserImportRow
+	if isJSON {
+		rows, err = service.ParseUserJSON(content, "", nil)
+	} else {
+		rows, err = service.ParseUserCSV(content)
+	}
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	report, err := h.importService.Import(c.Request.Context(), rows, opts)
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importContent opens the uploaded rows and tells whether they are JSON
+func importContent(c *gin.Context) (io.ReadCloser, bool, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, false, err
+		}
+		f, err := header.Open()
+		if err != nil {
+			return nil, false, err
+		}
+		return f, strings.EqualFold(filepath.Ext(header.Filename), ".json"), nil
+	}
+
+	switch c.ContentType() {
+	case "text/csv":
+		return c.Request.Body, false, nil
+	case "application/json":
+		return c.Request.Body, true, nil
+	}
+	return nil, false, errors.New("send a multipart form with a file field, or a text/csv or application/json body")
+}
+
+// respondImportError maps import errors to HTTP responses
+func respondImportError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": domain.ErrFileTooLarge.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
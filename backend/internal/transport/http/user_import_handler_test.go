@@ -0,0 +1,94 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestUserImport(t *testing.T) {
+	srv := apitest.New(t)
+	admin := srv.Admin(t, "root")
+	alice := srv.Employee(t, "alice")
+	ctx := defaultCtx()
+
+	csv := []byte("name,email,department,job title,manager email\n" +
+		"Carol,carol@example.com,R&D,Engineer,alice@example.com\n" +
+		",alice@example.com,R&D,Lead,\n" +
+		"Nobody,not-an-email,,,\n")
+
+	var dry domain.UserImportReport
+	srv.Upload(t, "/api/admin/users/import?dryRun=true", admin.Token, nil, "people.csv", csv).Decode(t, &dry)
+	if !dry.DryRun || dry.Created != 1 || dry.Updated != 1 || dry.Failed != 1 {
+		t.Fatalf("dry run = %+v", dry)
+	}
+	if _, err := srv.Users.GetByEmail(ctx, "carol@example.com"); err == nil {
+		t.Fatal("dry run created a user")
+	}
+
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/users/import?invite=maybe", admin.Token, []any{})
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/users/import", admin.Token, map[string]string{"email": "x"})
+
+	var report domain.UserImportReport
+	resp := srv.Upload(t, "/api/admin/users/import?invite=true", admin.Token, nil, "people.csv", csv)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("import = %d: %s", resp.Code, resp.Body)
+	}
+	resp.Decode(t, &report)
+	if report.Created != 1 || report.Updated != 1 || report.Failed != 1 || !report.Rows[0].Invited {
+		t.Fatalf("import = %+v", report)
+	}
+	if report.Rows[2].Error == "" {
+		t.Error("the rejected row has no error")
+	}
+
+	var carol struct {
+		ManagerID  *string `json:"managerId"`
+		Department *string `json:"department"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/users/"+report.Rows[0].UserID, admin.Token, nil).Decode(t, &carol)
+	if carol.ManagerID == nil || *carol.ManagerID != alice.User.ID || *carol.Department != "R&D" {
+		t.Errorf("imported user = %+v", carol)
+	}
+
+	if err := srv.JobService.RunDue(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("run jobs: %v", err)
+	}
+	sent := srv.Mail.Sent()
+	if len(sent) != 1 || sent[0].To != "carol@example.com" {
+		t.Fatalf("sent = %+v, want one invitation to carol", sent)
+	}
+	var token string
+	for _, field := range strings.Fields(sent[0].Body) {
+		if link, err := url.Parse(field); err == nil && link.Query().Has("token") {
+			token = link.Query().Get("token")
+		}
+	}
+	if token == "" {
+		t.Fatalf("no invitation link in %q", sent[0].Body)
+	}
+
+	srv.Expect(t, http.StatusUnauthorized, http.MethodGet, "/api/auth/me", token, nil)
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/auth/accept-invitation", "", map[string]string{"token": token, "password": "short"})
+	var accepted struct {
+		Token string `json:"token"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/auth/accept-invitation", "", map[string]string{
+		"token":    token,
+		"password": "password123",
+	}).Decode(t, &accepted)
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/auth/me", accepted.Token, nil)
+	srv.Expect(t, http.StatusOK, http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    "carol@example.com",
+		"password": "password123",
+	})
+	srv.Expect(t, http.StatusUnauthorized, http.MethodPost, "/api/auth/accept-invitation", "", map[string]string{
+		"token":    token,
+		"password": "password456",
+	})
+}
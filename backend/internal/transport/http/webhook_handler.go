@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/dto"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// ListSubscriptions handles GET /api/admin/webhooks (webhooks.manage)
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.webhookService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": subs})
+}
+
+// CreateSubscription handles POST /api/admin/webhooks (webhooks.manage); the
+// response is the only one that includes the signing secret
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req dto.WebhookSubscriptionDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := h.webhookService.CreateSubscription(c.Request.Context(), subscriptionFromDTO(req))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// GetSubscription handles GET /api/admin/webhooks/:id (webhooks.manage)
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	sub, err := h.webhookService.GetSubscription(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// UpdateSubscription handles PUT /api/admin/webhooks/:id (webhooks.manage)
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	var req dto.WebhookSubscriptionDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub := subscriptionFromDTO(req)
+	sub.ID = c.Param("id")
+
+	sub, err := h.webhookService.UpdateSubscription(c.Request.Context(), sub)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubscription handles DELETE /api/admin/webhooks/:id (webhooks.manage)
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/admin/webhook-deliveries?status=&subscriptionId=&limit=
+// (webhooks.manage); status=dead lists the dead letters
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	filter := domain.WebhookDeliveryFilter{
+		Status:         domain.DeliveryStatus(c.Query("status")),
+		SubscriptionID: c.Query("subscriptionId"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// GetDelivery handles GET /api/admin/webhook-deliveries/:id (webhooks.manage)
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	delivery, err := h.webhookService.GetDelivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver handles POST /api/admin/webhook-deliveries/:id/redeliver (webhooks.manage)
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func subscriptionFromDTO(req dto.WebhookSubscriptionDTO) *domain.WebhookSubscription {
+	eventTypes := make([]domain.EventType, len(req.EventTypes))
+	for i, t := range req.EventTypes {
+		eventTypes[i] = domain.EventType(t)
+	}
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return &domain.WebhookSubscription{
+		URL:         req.URL,
+		Secret:      req.Secret,
+		EventTypes:  eventTypes,
+		Description: req.Description,
+		Active:      active,
+	}
+}
+
+// respondWebhookError maps webhook errors to status codes
+func respondWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound),
+		errors.Is(err, domain.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeliveryNotRetryable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
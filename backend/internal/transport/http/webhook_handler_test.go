@@ -0,0 +1,122 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/domain"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/service"
+	"github.com/mnkhmtv/corporate-learning-module/backend/internal/transport/http/apitest"
+)
+
+func TestWebhooks(t *testing.T) {
+	srv := apitest.New(t)
+	alice := srv.Employee(t, "alice")
+	admin := srv.Admin(t, "root")
+	ctx := defaultCtx()
+
+	var (
+		mu       sync.Mutex
+		status   = http.StatusServiceUnavailable
+		received []*http.Request
+	)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r)
+		w.WriteHeader(status)
+	}))
+	defer endpoint.Close()
+
+	srv.Expect(t, http.StatusBadRequest, http.MethodPost, "/api/admin/webhooks", admin.Token, map[string]any{
+		"url":        endpoint.URL,
+		"eventTypes": []string{"request.deleted"},
+	})
+	var sub domain.WebhookSubscription
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/admin/webhooks", admin.Token, map[string]any{
+		"url":         endpoint.URL,
+		"eventTypes":  []string{"request.created"},
+		"description": "HRIS",
+	}).Decode(t, &sub)
+	if sub.Secret == "" || !sub.Active {
+		t.Fatalf("created webhook = %+v, want an active one with its secret", sub)
+	}
+	var stored domain.WebhookSubscription
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/webhooks/"+sub.ID, admin.Token, nil).Decode(t, &stored)
+	if stored.ID != sub.ID || stored.Secret != "" {
+		t.Error("the secret is returned after creation")
+	}
+
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests", alice.Token, requestBody)
+
+	// The endpoint is down: the delivery is retried until it is dead
+	for attempt := range 3 {
+		at := time.Now().Add(time.Duration(attempt) * 24 * time.Hour)
+		if err := srv.WebhookService.DispatchDue(ctx, at); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+	}
+	var deliveries struct {
+		Deliveries []domain.WebhookDelivery `json:"deliveries"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/webhook-deliveries?status=dead", admin.Token, nil).Decode(t, &deliveries)
+	if len(deliveries.Deliveries) != 1 || len(received) != 3 {
+		t.Fatalf("dead deliveries = %+v after %d attempts", deliveries.Deliveries, len(received))
+	}
+	dead := deliveries.Deliveries[0]
+	if dead.EventType != domain.EventRequestCreated || dead.LastStatusCode == nil || *dead.LastStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("dead delivery = %+v", dead)
+	}
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, "/api/admin/webhook-deliveries?status=lost", admin.Token, nil)
+	srv.Expect(t, http.StatusBadRequest, http.MethodGet, "/api/admin/webhook-deliveries?limit=-1", admin.Token, nil)
+
+	// Once the endpoint is back, an admin redelivers the dead letter
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+	deliveryPath := "/api/admin/webhook-deliveries/" + dead.ID
+	srv.Expect(t, http.StatusOK, http.MethodPost, deliveryPath+"/redeliver", admin.Token, nil)
+	if err := srv.WebhookService.DispatchDue(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	var delivery domain.WebhookDelivery
+	srv.Expect(t, http.StatusOK, http.MethodGet, deliveryPath, admin.Token, nil).Decode(t, &delivery)
+	if delivery.Status != domain.DeliveryDelivered {
+		t.Errorf("redelivered delivery = %+v", delivery)
+	}
+	srv.Expect(t, http.StatusConflict, http.MethodPost, deliveryPath+"/redeliver", admin.Token, nil)
+
+	last := received[len(received)-1]
+	if last.Header.Get(service.WebhookEventHeader) != "request.created" || last.Header.Get(service.WebhookSignatureHeader) == "" {
+		t.Errorf("webhook headers = %v", last.Header)
+	}
+
+	// Paused subscriptions are kept but receive nothing
+	srv.Expect(t, http.StatusOK, http.MethodPut, "/api/admin/webhooks/"+sub.ID, admin.Token, map[string]any{
+		"url":    endpoint.URL,
+		"active": false,
+	}).Decode(t, &sub)
+	if sub.Active || len(sub.EventTypes) != 0 {
+		t.Errorf("paused webhook = %+v", sub)
+	}
+	srv.Expect(t, http.StatusCreated, http.MethodPost, "/api/requests", alice.Token, requestBody)
+	if err := srv.WebhookService.DispatchDue(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(received) != 4 {
+		t.Errorf("paused webhook received %d requests, want 4", len(received))
+	}
+
+	srv.Expect(t, http.StatusNoContent, http.MethodDelete, "/api/admin/webhooks/"+sub.ID, admin.Token, nil)
+	var list struct {
+		Webhooks []domain.WebhookSubscription `json:"webhooks"`
+	}
+	srv.Expect(t, http.StatusOK, http.MethodGet, "/api/admin/webhooks", admin.Token, nil).Decode(t, &list)
+	if len(list.Webhooks) != 0 {
+		t.Errorf("webhooks after delete = %+v", list.Webhooks)
+	}
+	srv.Expect(t, http.StatusNotFound, http.MethodGet, deliveryPath, admin.Token, nil)
+}